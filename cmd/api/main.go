@@ -41,7 +41,11 @@ func gracefulShutdown(fiberServer *server.FiberServer, done chan bool) {
 
 func main() {
 
-	server := server.New()
+	server, err := server.New()
+	if err != nil {
+		server.Shutdown()
+		log.Fatalf("[SERVER] %v", err)
+	}
 
 	server.RegisterFiberRoutes()
 
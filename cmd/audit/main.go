@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"aviator/internal/cache"
+	"aviator/internal/database"
+	"aviator/internal/game"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	_ "github.com/joho/godotenv/autoload"
+)
+
+// audit compares the Redis balances with PostgreSQL. It only reads balances
+// unless --fix is given, which copies the Redis balance into users.balance.
+func main() {
+	fix := flag.Bool("fix", false, "update the database balance of each mismatched user to the Redis balance")
+	flag.Parse()
+
+	dbURL := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable&search_path=%s",
+		getEnv("BLUEPRINT_DB_USERNAME", "postgres"),
+		getEnv("BLUEPRINT_DB_PASSWORD", "postgres"),
+		getEnv("BLUEPRINT_DB_HOST", "localhost"),
+		getEnv("BLUEPRINT_DB_PORT", "5432"),
+		getEnv("BLUEPRINT_DB_DATABASE", "crashdb"),
+		getEnv("BLUEPRINT_DB_SCHEMA", "public"),
+	)
+	conn, err := sql.Open("pgx", dbURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	db := database.FromDB(conn)
+	defer db.Close()
+
+	redisCache := cache.New()
+	if redisCache == nil {
+		log.Fatal("Failed to connect to Redis")
+	}
+	client := redisCache.GetClient()
+	ctx := context.Background()
+
+	redisBalances, err := game.LoadRedisBalances(ctx, client)
+	if err != nil {
+		log.Fatalf("Failed to load Redis balances: %v", err)
+	}
+	dbBalances, err := db.UserBalances(ctx)
+	if err != nil {
+		log.Fatalf("Failed to load database balances: %v", err)
+	}
+	ledgerBalances, err := db.LedgerBalances(ctx)
+	if err != nil {
+		log.Fatalf("Failed to load transaction ledger: %v", err)
+	}
+
+	discrepancies, summary := game.FindBalanceDiscrepancies(redisBalances, dbBalances, ledgerBalances)
+	for _, d := range discrepancies {
+		log.Printf("[AUDIT] %s: redis %.2f, %s %.2f (difference %+.2f)", d.UserID, d.RedisBalance, d.Source, d.Balance, d.Difference)
+	}
+
+	if *fix {
+		fixBalances(ctx, db, discrepancies)
+	}
+
+	if err := game.RecordBalanceAudit(ctx, client, summary, time.Now()); err != nil {
+		log.Printf("[AUDIT] Failed to record the audit result: %v", err)
+	}
+
+	out, _ := json.MarshalIndent(summary, "", "  ")
+	fmt.Println(string(out))
+	if summary.DiscrepanciesFound > 0 && !*fix {
+		os.Exit(1)
+	}
+}
+
+// fixBalances copies the Redis balance of every database discrepancy into
+// users.balance. Ledger discrepancies cannot be fixed by rewriting history.
+func fixBalances(ctx context.Context, db database.Service, discrepancies []game.BalanceDiscrepancy) {
+	for _, d := range discrepancies {
+		if d.Source != game.DiscrepancySourceDatabase {
+			continue
+		}
+
+		err := db.SetUserBalance(ctx, d.UserID, d.RedisBalance)
+		switch {
+		case errors.Is(err, database.ErrUserNotFound):
+			log.Printf("[AUDIT] Skipped %s: no users row to update", d.UserID)
+		case err != nil:
+			log.Printf("[AUDIT] Failed to fix %s: %v", d.UserID, err)
+		default:
+			log.Printf("[AUDIT] Set the database balance of %s to %.2f", d.UserID, d.RedisBalance)
+		}
+	}
+}
+
+func getEnv(key, defaultVal string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+	return defaultVal
+}
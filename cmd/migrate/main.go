@@ -19,6 +19,13 @@ func main() {
 	}
 
 	command := os.Args[1]
+	migrationsPath := getEnv("MIGRATIONS_PATH", "./migrations")
+
+	// validate only reads the migration files, so it runs without a database
+	if command == "validate" {
+		validateMigrations(migrationsPath)
+		return
+	}
 
 	dbURL := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable&search_path=%s",
 		getEnv("BLUEPRINT_DB_USERNAME", "postgres"),
@@ -35,13 +42,14 @@ func main() {
 	}
 	defer db.Close()
 
-	migrationsPath := getEnv("MIGRATIONS_PATH", "./migrations")
-
 	switch command {
 	case "up":
 		log.Println("Running migrations...")
 		if err := database.RunMigrations(db, migrationsPath); err != nil {
-			log.Fatalf("Migration failed: %v", err)
+			log.Printf("Migration failed: %v", err)
+			reportDirtyState(db)
+			db.Close()
+			os.Exit(1)
 		}
 		log.Println("Migrations completed successfully")
 
@@ -76,6 +84,43 @@ func main() {
 	}
 }
 
+// reportDirtyState explains how to recover after a failed migration left the
+// database dirty
+func reportDirtyState(db *sql.DB) {
+	version, dirty, err := database.GetDirtyVersion(db)
+	if err != nil {
+		log.Printf("Could not read the migration state: %v", err)
+		return
+	}
+	if !dirty {
+		log.Printf("Database is clean at version %d; fix the error above and run migrate up again", version)
+		return
+	}
+
+	log.Printf("Database is DIRTY at version %d", version)
+	log.Printf("Undo whatever part of migration %d was applied, then mark the previous version as current:", version)
+	if version <= 1 {
+		log.Printf("   DELETE FROM %s;", database.MIGRATIONS_TABLE)
+	} else {
+		log.Printf("   UPDATE %s SET version = %d, dirty = false;", database.MIGRATIONS_TABLE, version-1)
+	}
+	log.Printf("and run migrate up again")
+}
+
+func validateMigrations(migrationsPath string) {
+	errs := database.ValidateMigrationFiles(migrationsPath)
+	if len(errs) == 0 {
+		log.Printf("All migrations in %s are valid", migrationsPath)
+		return
+	}
+
+	for _, err := range errs {
+		log.Printf("   - %v", err)
+	}
+	log.Printf("Found %d problem(s) in %s", len(errs), migrationsPath)
+	os.Exit(1)
+}
+
 func createMigration(name string) {
 	files, err := os.ReadDir("./migrations")
 	if err != nil {
@@ -115,6 +160,7 @@ func printUsage() {
 	fmt.Println("  migrate down            Rollback the last migration")
 	fmt.Println("  migrate version         Show current migration version")
 	fmt.Println("  migrate create <name>   Create a new migration file")
+	fmt.Println("  migrate validate        Check the migration files without a database")
 	fmt.Println()
 	fmt.Println("Environment Variables:")
 	fmt.Println("  BLUEPRINT_DB_HOST       Database host (default: localhost)")
@@ -3,12 +3,16 @@ package database
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
+	"math"
 	"os"
+	"slices"
 	"strconv"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	_ "github.com/joho/godotenv/autoload"
 )
@@ -19,13 +23,242 @@ type Service interface {
 	// The keys and values in the map are service-specific.
 	Health() map[string]string
 
+	// Stats returns the connection pool statistics.
+	Stats() sql.DBStats
+
+	// QueryStats returns the totals of every query run by the service.
+	QueryStats() QueryStats
+
+	// SlowQueries returns the last queries slower than SLOW_QUERY_THRESHOLD_MS, newest first.
+	SlowQueries() []SlowQuery
+
+	// Ping verifies that the database can be reached.
+	Ping(ctx context.Context) error
+
+	// MigrationVersion returns the schema version recorded by the last
+	// migration and whether it failed part way.
+	MigrationVersion(ctx context.Context) (version uint, dirty bool, err error)
+
+	// RecordTransaction appends a balance change to the transactions audit trail.
+	RecordTransaction(ctx context.Context, tx Transaction) error
+
+	// RecordReferral stores that referrerID referred userID.
+	RecordReferral(ctx context.Context, userID, referrerID string) error
+
+	// CreateUser inserts a new active user.
+	// It returns ErrUsernameTaken if the username is already in use.
+	CreateUser(ctx context.Context, id, username, email string) (*User, error)
+
+	// GetUser returns the user with the given ID or ErrUserNotFound.
+	GetUser(ctx context.Context, id string) (*User, error)
+
+	// UpdateLastSeen stamps the user's last activity with the current time.
+	UpdateLastSeen(ctx context.Context, id string) error
+
+	// ListUsers returns a page of users, newest first, optionally filtered by status.
+	ListUsers(ctx context.Context, status string, page, limit int) ([]User, error)
+
+	// AllUsers returns every user, newest first, optionally filtered by status.
+	AllUsers(ctx context.Context, status string) ([]User, error)
+
+	// GetUserStats aggregates each user's bets placed in [from, to).
+	GetUserStats(ctx context.Context, from, to time.Time) ([]UserStats, error)
+
+	// UserBetStats aggregates one user's bets placed in [from, to).
+	UserBetStats(ctx context.Context, userID string, from, to time.Time) (*UserStats, error)
+
+	// SaveRound inserts a round or updates it with its outcome.
+	SaveRound(ctx context.Context, round Round) error
+
+	// GetRound returns the round with the given ID or ErrRoundNotFound.
+	GetRound(ctx context.Context, id string) (*Round, error)
+
+	// RecentCrashPoints returns the final crash multipliers of up to limit
+	// crashed rounds, newest first.
+	RecentCrashPoints(ctx context.Context, limit int) ([]float64, error)
+
+	// RecentBaseCrashPoints is RecentCrashPoints without any bonus event,
+	// as the provably fair draw produced them.
+	RecentBaseCrashPoints(ctx context.Context, limit int) ([]float64, error)
+
+	// CrashStats summarizes the crash points of rounds started since the given time.
+	CrashStats(ctx context.Context, since time.Time) (*CrashStats, error)
+
+	// RecordSettlement appends a settled bet of any game to game_settlements.
+	RecordSettlement(ctx context.Context, settlement Settlement) error
+
+	// HouseProfit totals the bets of every game settled since the given time, per game type.
+	HouseProfit(ctx context.Context, since time.Time) ([]GameProfit, error)
+
+	// SaveBets inserts a round's settled bets. Bets already stored are left unchanged.
+	SaveBets(ctx context.Context, bets []Bet) error
+
+	// GetRoundBets returns a round's bets in the order they were placed.
+	GetRoundBets(ctx context.Context, roundID string) ([]Bet, error)
+
+	// UserRecentBets returns up to limit of a user's bets, newest first.
+	UserRecentBets(ctx context.Context, userID string, limit int) ([]Bet, error)
+
+	// UserBalances returns the balance column of every user, keyed by user ID.
+	UserBalances(ctx context.Context) (map[string]float64, error)
+
+	// LedgerBalances returns the net of each user's transactions, keyed by user ID.
+	LedgerBalances(ctx context.Context) (map[string]float64, error)
+
+	// UserTransactions returns up to limit of a user's transactions of the
+	// given types, newest first.
+	UserTransactions(ctx context.Context, userID string, types []string, limit int) ([]Transaction, error)
+
+	// SetUserBalance overwrites a user's balance column.
+	// It returns ErrUserNotFound if the user does not exist.
+	SetUserBalance(ctx context.Context, id string, balance float64) error
+
 	// Close terminates the database connection.
 	// It returns an error if the connection cannot be closed.
 	Close() error
 }
 
+// Transaction is a single balance change recorded in the transactions table.
+type Transaction struct {
+	ID            string // Assigned by the database
+	UserID        string
+	Type          string
+	Amount        float64
+	BalanceBefore float64
+	BalanceAfter  float64
+	ReferenceID   string
+	Description   string
+	CreatedAt     time.Time // Assigned by the database
+}
+
+// User statuses
+const (
+	UserStatusActive    = "active"
+	UserStatusSuspended = "suspended"
+	UserStatusBanned    = "banned"
+)
+
+var (
+	ErrUserNotFound  = errors.New("user not found")
+	ErrUsernameTaken = errors.New("username already taken")
+	ErrRoundNotFound = errors.New("round not found")
+)
+
+// User is a player record from the users table.
+type User struct {
+	ID         string     `json:"id"`
+	Username   string     `json:"username"`
+	Email      string     `json:"email,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt *time.Time `json:"last_seen_at"`
+	Status     string     `json:"status"`
+}
+
+// UserStats aggregates a user's bets over a period.
+type UserStats struct {
+	UserID       string
+	GamesPlayed  int
+	TotalWagered float64
+	TotalPayout  float64
+	BiggestWin   float64 // Largest profit on a single winning bet
+}
+
+// Round is a crash round from the game_rounds table.
+type Round struct {
+	ID                    string
+	ServerSeed            string
+	HashCommitment        string
+	CommitmentPublishedAt time.Time
+	CommitmentSignature   string // HMAC of the commitment, round ID and publication time
+	ClientSeed            string
+	CrashMultiplier       float64 // Provably fair crash point
+	BonusMultiplier       float64 // Added by a bonus event
+	Nonce                 int
+	Status                string
+	StartedAt             time.Time
+	CrashedAt             *time.Time
+	Verified              bool // Seed matches the commitment and reproduces the crash multiplier
+}
+
+// CrashStats summarizes the final crash points of crashed rounds.
+type CrashStats struct {
+	TotalRounds          int       `json:"total_rounds"`
+	MedianCrashPoint     float64   `json:"median_crash_point"`
+	PctUnder2x           float64   `json:"pct_under_2x"`
+	Pct2xTo5x            float64   `json:"pct_2x_to_5x"`
+	PctOver10x           float64   `json:"pct_over_10x"`
+	HighestEver          float64   `json:"highest_ever"`
+	StreakNoCrashUnder2x int       `json:"streak_no_crash_under_2x"` // Latest rounds in a row that reached 2x
+	Last10CrashPoints    []float64 `json:"last_10_crash_points"`     // Oldest first
+}
+
+// GameProfit is what players wagered on one game and were paid back.
+type GameProfit struct {
+	GameType string
+	Wagered  float64
+	PaidOut  float64
+}
+
+// Settlement is one settled bet of any game, from the game_settlements table.
+type Settlement struct {
+	UserID    string
+	GameType  string
+	Wager     float64
+	Payout    float64
+	SettledAt time.Time
+}
+
+// Bet results
+const (
+	BetResultWin  = "WIN"
+	BetResultLoss = "LOSS"
+)
+
+// Bet is a settled Aviator bet from the bets table.
+type Bet struct {
+	ID                string
+	UserID            string
+	RoundID           string
+	Amount            float64
+	AutoCashout       float64 // 0 when the bet had no auto-cashout
+	CashoutMultiplier float64 // 0 for a lost bet
+	Payout            float64
+	PlacedAt          time.Time
+	CashedOutAt       *time.Time
+	Result            string
+}
+
 type service struct {
-	db *sql.DB
+	db      *sql.DB
+	queries *QueryLogger
+	pool    PoolConfig
+}
+
+// PoolConfig limits the connection pool of the database service
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// poolConfigFromEnv reads the pool limits from DB_MAX_OPEN_CONNS,
+// DB_MAX_IDLE_CONNS, DB_CONN_MAX_LIFETIME_SECONDS and DB_CONN_MAX_IDLE_TIME_SECONDS
+func poolConfigFromEnv() PoolConfig {
+	return PoolConfig{
+		MaxOpenConns:    getEnvAsInt("DB_MAX_OPEN_CONNS", 25),
+		MaxIdleConns:    getEnvAsInt("DB_MAX_IDLE_CONNS", 5),
+		ConnMaxLifetime: time.Duration(getEnvAsInt("DB_CONN_MAX_LIFETIME_SECONDS", 300)) * time.Second,
+		ConnMaxIdleTime: time.Duration(getEnvAsInt("DB_CONN_MAX_IDLE_TIME_SECONDS", 60)) * time.Second,
+	}
+}
+
+// apply sets the pool limits on a connection
+func (p PoolConfig) apply(db *sql.DB) {
+	db.SetMaxOpenConns(p.MaxOpenConns)
+	db.SetMaxIdleConns(p.MaxIdleConns)
+	db.SetConnMaxLifetime(p.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(p.ConnMaxIdleTime)
 }
 
 var (
@@ -48,20 +281,34 @@ func New() Service {
 	if err != nil {
 		log.Fatal(err)
 	}
+	pool := poolConfigFromEnv()
+	pool.apply(db)
 	
 	// Run migrations
-	migrationsPath := getEnv("MIGRATIONS_PATH", "./migrations")
-	if err := RunMigrations(db, migrationsPath); err != nil {
+	if err := RunMigrations(db, MigrationsPath()); err != nil {
 		log.Printf("[DB] Migration warning: %v", err)
 		// Don't fail on migration errors in case they're already applied
 	}
 	
 	dbInstance = &service{
-		db: db,
+		db:      db,
+		queries: NewQueryLogger(db, slowQueryThresholdFromEnv()),
+		pool:    pool,
 	}
 	return dbInstance
 }
 
+// FromDB wraps an open connection without running migrations, for tools
+// that must not change the schema.
+func FromDB(db *sql.DB) Service {
+	return &service{db: db, queries: NewQueryLogger(db, slowQueryThresholdFromEnv())}
+}
+
+// MigrationsPath returns the directory holding the migration files
+func MigrationsPath() string {
+	return getEnv("MIGRATIONS_PATH", "./migrations")
+}
+
 func getEnv(key, defaultVal string) string {
 	if val := os.Getenv(key); val != "" {
 		return val
@@ -69,6 +316,15 @@ func getEnv(key, defaultVal string) string {
 	return defaultVal
 }
 
+func getEnvAsInt(key string, defaultVal int) int {
+	if val := os.Getenv(key); val != "" {
+		if intVal, err := strconv.Atoi(val); err == nil {
+			return intVal
+		}
+	}
+	return defaultVal
+}
+
 // Health checks the health of the database connection by pinging the database.
 // It returns a map with keys indicating various health statistics.
 func (s *service) Health() map[string]string {
@@ -97,11 +353,16 @@ func (s *service) Health() map[string]string {
 	stats["idle"] = strconv.Itoa(dbStats.Idle)
 	stats["wait_count"] = strconv.FormatInt(dbStats.WaitCount, 10)
 	stats["wait_duration"] = dbStats.WaitDuration.String()
+	stats["wait_duration_ms"] = strconv.FormatInt(dbStats.WaitDuration.Milliseconds(), 10)
 	stats["max_idle_closed"] = strconv.FormatInt(dbStats.MaxIdleClosed, 10)
 	stats["max_lifetime_closed"] = strconv.FormatInt(dbStats.MaxLifetimeClosed, 10)
+	stats["max_open_connections"] = strconv.Itoa(dbStats.MaxOpenConnections)
+	stats["max_idle_connections"] = strconv.Itoa(s.pool.MaxIdleConns)
+	stats["conn_max_lifetime"] = s.pool.ConnMaxLifetime.String()
+	stats["conn_max_idle_time"] = s.pool.ConnMaxIdleTime.String()
 
 	// Evaluate stats to provide a health message
-	if dbStats.OpenConnections > 40 { // Assuming 50 is the max for this example
+	if dbStats.MaxOpenConnections > 0 && dbStats.OpenConnections >= dbStats.MaxOpenConnections*4/5 {
 		stats["message"] = "The database is experiencing heavy load."
 	}
 
@@ -120,6 +381,470 @@ func (s *service) Health() map[string]string {
 	return stats
 }
 
+// Stats returns the statistics of the connection pool.
+func (s *service) Stats() sql.DBStats {
+	return s.db.Stats()
+}
+
+// QueryStats reads the counters of the query logger.
+func (s *service) QueryStats() QueryStats {
+	return s.queries.Stats()
+}
+
+// SlowQueries reads the ring buffer of the query logger.
+func (s *service) SlowQueries() []SlowQuery {
+	return s.queries.SlowQueries()
+}
+
+// Ping checks the connection without Health's statistics, and without
+// terminating the program when the database is down.
+func (s *service) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+// MigrationVersion reads the migrations table, see GetDirtyVersion.
+func (s *service) MigrationVersion(ctx context.Context) (uint, bool, error) {
+	return GetDirtyVersion(s.db)
+}
+
+// RecordTransaction inserts a row into the transactions table.
+func (s *service) RecordTransaction(ctx context.Context, tx Transaction) error {
+	_, err := s.queries.ExecContext(ctx, "RecordTransaction",
+		`INSERT INTO transactions (user_id, type, amount, balance_before, balance_after, reference_id, description)
+		 VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, ''))`,
+		tx.UserID, tx.Type, tx.Amount, tx.BalanceBefore, tx.BalanceAfter, tx.ReferenceID, tx.Description)
+	return err
+}
+
+// RecordReferral inserts a row into the referrals table.
+// A user that already has a referrer is left unchanged.
+func (s *service) RecordReferral(ctx context.Context, userID, referrerID string) error {
+	_, err := s.queries.ExecContext(ctx, "RecordReferral",
+		`INSERT INTO referrals (user_id, referrer_id) VALUES ($1, $2)
+		 ON CONFLICT (user_id) DO NOTHING`,
+		userID, referrerID)
+	return err
+}
+
+// CreateUser inserts a row into the users table.
+func (s *service) CreateUser(ctx context.Context, id, username, email string) (*User, error) {
+	user := &User{ID: id, Username: username, Email: email}
+	err := s.queries.QueryRowContext(ctx, "CreateUser",
+		`INSERT INTO users (id, username, email) VALUES ($1, $2, NULLIF($3, ''))
+		 RETURNING created_at, status`,
+		id, username, email).Scan(&user.CreatedAt, &user.Status)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "users_username_key" {
+		return nil, ErrUsernameTaken
+	}
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+const userColumns = `id, username, COALESCE(email, ''), created_at, last_seen_at, status`
+
+func scanUser(row interface{ Scan(...any) error }) (*User, error) {
+	var user User
+	var lastSeen sql.NullTime
+	if err := row.Scan(&user.ID, &user.Username, &user.Email, &user.CreatedAt, &lastSeen, &user.Status); err != nil {
+		return nil, err
+	}
+	if lastSeen.Valid {
+		user.LastSeenAt = &lastSeen.Time
+	}
+	return &user, nil
+}
+
+// GetUser loads a single user by ID.
+func (s *service) GetUser(ctx context.Context, id string) (*User, error) {
+	user, err := scanUser(s.queries.QueryRowContext(ctx, "GetUser", `SELECT `+userColumns+` FROM users WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUserNotFound
+	}
+	return user, err
+}
+
+// UpdateLastSeen sets last_seen_at to now. Unknown users are ignored.
+func (s *service) UpdateLastSeen(ctx context.Context, id string) error {
+	_, err := s.queries.ExecContext(ctx, "UpdateLastSeen", `UPDATE users SET last_seen_at = NOW() WHERE id = $1`, id)
+	return err
+}
+
+// ListUsers returns page (starting at 1) of at most limit users.
+// An empty status lists users of every status.
+func (s *service) ListUsers(ctx context.Context, status string, page, limit int) ([]User, error) {
+	return s.queryUsers(ctx, "ListUsers",
+		`SELECT `+userColumns+` FROM users
+		 WHERE $1 = '' OR status = $1
+		 ORDER BY created_at DESC, id
+		 LIMIT $2 OFFSET $3`,
+		status, limit, (page-1)*limit)
+}
+
+// AllUsers lists every user. An empty status lists users of every status.
+func (s *service) AllUsers(ctx context.Context, status string) ([]User, error) {
+	return s.queryUsers(ctx, "AllUsers",
+		`SELECT `+userColumns+` FROM users
+		 WHERE $1 = '' OR status = $1
+		 ORDER BY created_at DESC, id`,
+		status)
+}
+
+// queryUsers runs a query selecting userColumns
+func (s *service) queryUsers(ctx context.Context, operation, query string, args ...interface{}) ([]User, error) {
+	users := []User{}
+	err := s.queries.QueryContext(ctx, operation, query, func(rows *sql.Rows) error {
+		user, err := scanUser(rows)
+		if err != nil {
+			return err
+		}
+		users = append(users, *user)
+		return nil
+	}, args...)
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// GetUserStats sums the bets table per user for bets placed in [from, to).
+func (s *service) GetUserStats(ctx context.Context, from, to time.Time) ([]UserStats, error) {
+	var stats []UserStats
+	err := s.queries.QueryContext(ctx, "GetUserStats",
+		`SELECT user_id, COUNT(*), COALESCE(SUM(amount), 0), COALESCE(SUM(payout), 0),
+		        COALESCE(MAX(profit) FILTER (WHERE result = 'WIN'), 0)
+		 FROM bets
+		 WHERE placed_at >= $1 AND placed_at < $2
+		 GROUP BY user_id
+		 ORDER BY user_id`,
+		func(rows *sql.Rows) error {
+			var st UserStats
+			if err := rows.Scan(&st.UserID, &st.GamesPlayed, &st.TotalWagered, &st.TotalPayout, &st.BiggestWin); err != nil {
+				return err
+			}
+			stats = append(stats, st)
+			return nil
+		}, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (s *service) UserBetStats(ctx context.Context, userID string, from, to time.Time) (*UserStats, error) {
+	stats := &UserStats{UserID: userID}
+	err := s.queries.QueryRowContext(ctx, "UserBetStats",
+		`SELECT COUNT(*), COALESCE(SUM(amount), 0), COALESCE(SUM(payout), 0),
+		        COALESCE(MAX(profit) FILTER (WHERE result = 'WIN'), 0)
+		 FROM bets
+		 WHERE user_id = $1 AND placed_at >= $2 AND placed_at < $3`,
+		userID, from, to).Scan(&stats.GamesPlayed, &stats.TotalWagered, &stats.TotalPayout, &stats.BiggestWin)
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// SaveRound upserts a row into the game_rounds table. A crashed round is
+// never moved back to an earlier status.
+func (s *service) SaveRound(ctx context.Context, round Round) error {
+	_, err := s.queries.ExecContext(ctx, "SaveRound",
+		`INSERT INTO game_rounds (id, server_seed, hash_commitment, commitment_published_at, commitment_signature, client_seed,
+		                          crash_multiplier, bonus_multiplier, nonce, started_at, crashed_at, status, verified)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		 ON CONFLICT (id) DO UPDATE SET
+		     crashed_at = EXCLUDED.crashed_at,
+		     status = EXCLUDED.status,
+		     verified = EXCLUDED.verified
+		 WHERE game_rounds.status <> 'CRASHED'`,
+		round.ID, round.ServerSeed, round.HashCommitment, round.CommitmentPublishedAt, round.CommitmentSignature, round.ClientSeed,
+		round.CrashMultiplier, round.BonusMultiplier, round.Nonce, round.StartedAt, round.CrashedAt, round.Status, round.Verified)
+	return err
+}
+
+// GetRound loads a single round by ID.
+func (s *service) GetRound(ctx context.Context, id string) (*Round, error) {
+	var round Round
+	var publishedAt, crashedAt sql.NullTime
+	var signature sql.NullString
+	err := s.queries.QueryRowContext(ctx, "GetRound",
+		`SELECT id, server_seed, hash_commitment, commitment_published_at, commitment_signature, client_seed,
+		        crash_multiplier, bonus_multiplier, nonce, status, started_at, crashed_at, verified
+		 FROM game_rounds WHERE id = $1`, id).
+		Scan(&round.ID, &round.ServerSeed, &round.HashCommitment, &publishedAt, &signature, &round.ClientSeed,
+			&round.CrashMultiplier, &round.BonusMultiplier, &round.Nonce, &round.Status, &round.StartedAt, &crashedAt, &round.Verified)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrRoundNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	round.CommitmentPublishedAt = publishedAt.Time // Unset for rounds stored before commitments
+	round.CommitmentSignature = signature.String   // Unset for rounds stored before signatures
+	if crashedAt.Valid {
+		round.CrashedAt = &crashedAt.Time
+	}
+	return &round, nil
+}
+
+// CrashStats computes the statistics in a single pass over the crashed rounds.
+// Ranges include their lower bound, so a crash at exactly 2x counts as 2x to 5x.
+func (s *service) CrashStats(ctx context.Context, since time.Time) (*CrashStats, error) {
+	stats := &CrashStats{Last10CrashPoints: []float64{}}
+	err := s.queries.QueryRowContext(ctx, "CrashStats",
+		`WITH crashed AS (
+		     SELECT crash_multiplier + bonus_multiplier AS crash_point, started_at
+		     FROM game_rounds
+		     WHERE status = 'CRASHED' AND started_at >= $1
+		 )
+		 SELECT COUNT(*),
+		        COALESCE(PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY crash_point), 0),
+		        COALESCE(100.0 * COUNT(*) FILTER (WHERE crash_point < 2) / NULLIF(COUNT(*), 0), 0),
+		        COALESCE(100.0 * COUNT(*) FILTER (WHERE crash_point >= 2 AND crash_point < 5) / NULLIF(COUNT(*), 0), 0),
+		        COALESCE(100.0 * COUNT(*) FILTER (WHERE crash_point >= 10) / NULLIF(COUNT(*), 0), 0),
+		        COALESCE(MAX(crash_point), 0),
+		        COUNT(*) FILTER (WHERE started_at > COALESCE(
+		            (SELECT MAX(started_at) FROM crashed WHERE crash_point < 2), '-infinity'))
+		 FROM crashed`, since).Scan(&stats.TotalRounds, &stats.MedianCrashPoint, &stats.PctUnder2x,
+		&stats.Pct2xTo5x, &stats.PctOver10x, &stats.HighestEver, &stats.StreakNoCrashUnder2x)
+	if err != nil {
+		return nil, err
+	}
+	stats.PctUnder2x = math.Round(stats.PctUnder2x*100) / 100
+	stats.Pct2xTo5x = math.Round(stats.Pct2xTo5x*100) / 100
+	stats.PctOver10x = math.Round(stats.PctOver10x*100) / 100
+
+	err = s.queries.QueryContext(ctx, "CrashStats",
+		`SELECT crash_multiplier + bonus_multiplier FROM game_rounds
+		 WHERE status = 'CRASHED' AND started_at >= $1
+		 ORDER BY started_at DESC
+		 LIMIT 10`,
+		func(rows *sql.Rows) error {
+			var crashPoint float64
+			if err := rows.Scan(&crashPoint); err != nil {
+				return err
+			}
+			stats.Last10CrashPoints = append(stats.Last10CrashPoints, crashPoint)
+			return nil
+		}, since)
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(stats.Last10CrashPoints)
+	return stats, nil
+}
+
+// RecentCrashPoints includes any bonus event in each crash multiplier.
+func (s *service) RecentCrashPoints(ctx context.Context, limit int) ([]float64, error) {
+	return s.recentCrashPoints(ctx, "RecentCrashPoints", "crash_multiplier + bonus_multiplier", limit)
+}
+
+// RecentBaseCrashPoints leaves bonus events out of each crash multiplier.
+func (s *service) RecentBaseCrashPoints(ctx context.Context, limit int) ([]float64, error) {
+	return s.recentCrashPoints(ctx, "RecentBaseCrashPoints", "crash_multiplier", limit)
+}
+
+// recentCrashPoints selects column from the latest crashed rounds, newest first.
+func (s *service) recentCrashPoints(ctx context.Context, operation, column string, limit int) ([]float64, error) {
+	var crashPoints []float64
+	err := s.queries.QueryContext(ctx, operation,
+		`SELECT `+column+` FROM game_rounds
+		 WHERE status = 'CRASHED'
+		 ORDER BY started_at DESC
+		 LIMIT $1`,
+		func(rows *sql.Rows) error {
+			var crashPoint float64
+			if err := rows.Scan(&crashPoint); err != nil {
+				return err
+			}
+			crashPoints = append(crashPoints, crashPoint)
+			return nil
+		}, limit)
+	if err != nil {
+		return nil, err
+	}
+	return crashPoints, nil
+}
+
+// RecordSettlement inserts a row into the game_settlements table.
+func (s *service) RecordSettlement(ctx context.Context, settlement Settlement) error {
+	_, err := s.queries.ExecContext(ctx, "RecordSettlement",
+		`INSERT INTO game_settlements (user_id, game_type, wager, payout, settled_at)
+		 VALUES ($1, $2, $3, $4, $5)`,
+		settlement.UserID, settlement.GameType, settlement.Wager, settlement.Payout, settlement.SettledAt)
+	return err
+}
+
+// HouseProfit sums the wager and payout of settled bets by game type.
+func (s *service) HouseProfit(ctx context.Context, since time.Time) ([]GameProfit, error) {
+	profits := []GameProfit{}
+	err := s.queries.QueryContext(ctx, "HouseProfit",
+		`SELECT game_type, COALESCE(SUM(wager), 0), COALESCE(SUM(payout), 0)
+		 FROM game_settlements
+		 WHERE settled_at >= $1
+		 GROUP BY game_type`,
+		func(rows *sql.Rows) error {
+			var profit GameProfit
+			if err := rows.Scan(&profit.GameType, &profit.Wagered, &profit.PaidOut); err != nil {
+				return err
+			}
+			profits = append(profits, profit)
+			return nil
+		}, since)
+	if err != nil {
+		return nil, err
+	}
+	return profits, nil
+}
+
+const insertBet = `INSERT INTO bets (id, user_id, round_id, amount, auto_cashout, cashout_multiplier, payout,
+                   placed_at, cashed_out_at, result, profit)
+ VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+ ON CONFLICT (id) DO NOTHING`
+
+// SaveBets inserts the bets in one transaction, timed as a single query.
+func (s *service) SaveBets(ctx context.Context, bets []Bet) error {
+	start := time.Now()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, bet := range bets {
+		var autoCashout, cashoutMultiplier *float64
+		if bet.AutoCashout > 0 {
+			autoCashout = &bet.AutoCashout
+		}
+		if bet.CashoutMultiplier > 0 {
+			cashoutMultiplier = &bet.CashoutMultiplier
+		}
+
+		_, err := tx.ExecContext(ctx, insertBet,
+			bet.ID, bet.UserID, bet.RoundID, bet.Amount, autoCashout, cashoutMultiplier, bet.Payout,
+			bet.PlacedAt, bet.CashedOutAt, bet.Result, bet.Payout-bet.Amount)
+		if err != nil {
+			return err
+		}
+	}
+	err = tx.Commit()
+	s.queries.Observe("SaveBets", insertBet, time.Since(start), int64(len(bets)))
+	return err
+}
+
+// GetRoundBets loads the bets placed in a round.
+func (s *service) GetRoundBets(ctx context.Context, roundID string) ([]Bet, error) {
+	return s.queryBets(ctx, "GetRoundBets",
+		`SELECT id, user_id, round_id, amount, COALESCE(auto_cashout, 0), COALESCE(cashout_multiplier, 0),
+		        COALESCE(payout, 0), placed_at, cashed_out_at, result
+		 FROM bets WHERE round_id = $1
+		 ORDER BY placed_at, id`, roundID)
+}
+
+func (s *service) UserRecentBets(ctx context.Context, userID string, limit int) ([]Bet, error) {
+	return s.queryBets(ctx, "UserRecentBets",
+		`SELECT id, user_id, round_id, amount, COALESCE(auto_cashout, 0), COALESCE(cashout_multiplier, 0),
+		        COALESCE(payout, 0), placed_at, cashed_out_at, result
+		 FROM bets WHERE user_id = $1
+		 ORDER BY placed_at DESC, id DESC
+		 LIMIT $2`, userID, limit)
+}
+
+// queryBets runs a query selecting the columns of Bet in field order
+func (s *service) queryBets(ctx context.Context, operation, query string, args ...interface{}) ([]Bet, error) {
+	var bets []Bet
+	err := s.queries.QueryContext(ctx, operation, query, func(rows *sql.Rows) error {
+		var bet Bet
+		var cashedOutAt sql.NullTime
+		if err := rows.Scan(&bet.ID, &bet.UserID, &bet.RoundID, &bet.Amount, &bet.AutoCashout, &bet.CashoutMultiplier,
+			&bet.Payout, &bet.PlacedAt, &cashedOutAt, &bet.Result); err != nil {
+			return err
+		}
+		if cashedOutAt.Valid {
+			bet.CashedOutAt = &cashedOutAt.Time
+		}
+		bets = append(bets, bet)
+		return nil
+	}, args...)
+	if err != nil {
+		return nil, err
+	}
+	return bets, nil
+}
+
+// UserBalances loads the balance of every user.
+func (s *service) UserBalances(ctx context.Context) (map[string]float64, error) {
+	return s.balancesByUser(ctx, "UserBalances", `SELECT id, balance FROM users`)
+}
+
+// LedgerBalances sums the transactions of every user. Amounts are stored
+// unsigned, so bets and withdrawals are subtracted.
+func (s *service) LedgerBalances(ctx context.Context) (map[string]float64, error) {
+	return s.balancesByUser(ctx, "LedgerBalances",
+		`SELECT user_id, SUM(CASE WHEN type IN ('BET', 'WITHDRAWAL') THEN -amount ELSE amount END)
+		 FROM transactions
+		 GROUP BY user_id`)
+}
+
+// UserTransactions lists a user's transactions whose type is in types.
+func (s *service) UserTransactions(ctx context.Context, userID string, types []string, limit int) ([]Transaction, error) {
+	transactions := []Transaction{}
+	err := s.queries.QueryContext(ctx, "UserTransactions",
+		`SELECT id, user_id, type, amount, balance_before, balance_after,
+		        COALESCE(reference_id, ''), COALESCE(description, ''), created_at
+		 FROM transactions
+		 WHERE user_id = $1 AND type = ANY($2)
+		 ORDER BY created_at DESC
+		 LIMIT $3`,
+		func(rows *sql.Rows) error {
+			var tx Transaction
+			if err := rows.Scan(&tx.ID, &tx.UserID, &tx.Type, &tx.Amount, &tx.BalanceBefore, &tx.BalanceAfter,
+				&tx.ReferenceID, &tx.Description, &tx.CreatedAt); err != nil {
+				return err
+			}
+			transactions = append(transactions, tx)
+			return nil
+		}, userID, types, limit)
+	if err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// balancesByUser runs a query returning (user ID, amount) rows
+func (s *service) balancesByUser(ctx context.Context, operation, query string) (map[string]float64, error) {
+	balances := make(map[string]float64)
+	err := s.queries.QueryContext(ctx, operation, query, func(rows *sql.Rows) error {
+		var userID string
+		var balance float64
+		if err := rows.Scan(&userID, &balance); err != nil {
+			return err
+		}
+		balances[userID] = balance
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return balances, nil
+}
+
+// SetUserBalance updates a user's balance column.
+func (s *service) SetUserBalance(ctx context.Context, id string, balance float64) error {
+	result, err := s.queries.ExecContext(ctx, "SetUserBalance",
+		`UPDATE users SET balance = $2, updated_at = NOW() WHERE id = $1`, id, balance)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
 // Close closes the database connection.
 // It logs a message indicating the disconnection from the specific database.
 // If the connection is successfully closed, it returns nil.
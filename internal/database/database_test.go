@@ -2,7 +2,12 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -57,22 +62,37 @@ func mustStartPostgresContainer() (func(context.Context, ...testcontainers.Termi
 	return dbContainer.Terminate, err
 }
 
+// postgresAvailable is set once TestMain has started the Postgres container
+var postgresAvailable bool
+
+// requirePostgres skips a test that needs the Postgres container when it is not running
+func requirePostgres(t *testing.T) {
+	t.Helper()
+	if !postgresAvailable {
+		t.Skip("Postgres is not available")
+	}
+}
+
 func TestMain(m *testing.M) {
 	// Skip integration tests if SKIP_INTEGRATION env var is set
 	if os.Getenv("SKIP_INTEGRATION") != "" {
-		os.Exit(0)
+		os.Exit(m.Run())
 	}
 
 	// Skip if Docker is not available
 	if os.Getenv("CI") == "" && !isDockerAvailable() {
-		os.Exit(0)
+		os.Exit(m.Run())
 	}
 
+	// Tests run from this package's directory
+	os.Setenv("MIGRATIONS_PATH", "../../migrations")
+
 	teardown, err := mustStartPostgresContainer()
 	if err != nil {
 		// Don't fail, just skip tests if container can't start
-		os.Exit(0)
+		os.Exit(m.Run())
 	}
+	postgresAvailable = true
 
 	code := m.Run()
 
@@ -98,6 +118,7 @@ func isDockerAvailable() bool {
 }
 
 func TestNew(t *testing.T) {
+	requirePostgres(t)
 	srv := New()
 	if srv == nil {
 		t.Fatal("New() returned nil")
@@ -105,6 +126,7 @@ func TestNew(t *testing.T) {
 }
 
 func TestHealth(t *testing.T) {
+	requirePostgres(t)
 	srv := New()
 
 	stats := srv.Health()
@@ -120,9 +142,453 @@ func TestHealth(t *testing.T) {
 	if stats["message"] != "It's healthy" {
 		t.Fatalf("expected message to be 'It's healthy', got %s", stats["message"])
 	}
+
+	if stats["max_open_connections"] != "25" || stats["max_idle_connections"] != "5" ||
+		stats["conn_max_lifetime"] != "5m0s" || stats["conn_max_idle_time"] != "1m0s" {
+		t.Errorf("expected the default pool limits, got %v", stats)
+	}
+	for _, key := range []string{"open_connections", "in_use", "idle", "wait_count", "wait_duration_ms"} {
+		if stats[key] == "" {
+			t.Errorf("expected %s in the health stats", key)
+		}
+	}
+}
+
+func TestPoolConfigFromEnv(t *testing.T) {
+	if got := poolConfigFromEnv(); got != (PoolConfig{MaxOpenConns: 25, MaxIdleConns: 5, ConnMaxLifetime: 5 * time.Minute, ConnMaxIdleTime: time.Minute}) {
+		t.Errorf("default pool config = %+v", got)
+	}
+
+	t.Setenv("DB_MAX_OPEN_CONNS", "40")
+	t.Setenv("DB_MAX_IDLE_CONNS", "10")
+	t.Setenv("DB_CONN_MAX_LIFETIME_SECONDS", "600")
+	t.Setenv("DB_CONN_MAX_IDLE_TIME_SECONDS", "invalid")
+	pool := poolConfigFromEnv()
+	if pool != (PoolConfig{MaxOpenConns: 40, MaxIdleConns: 10, ConnMaxLifetime: 10 * time.Minute, ConnMaxIdleTime: time.Minute}) {
+		t.Errorf("configured pool config = %+v", pool)
+	}
+
+	// Opening does not connect, so the limits can be checked without PostgreSQL
+	db, err := sql.Open("pgx", "postgres://localhost:1/none")
+	if err != nil {
+		t.Fatalf("sql.Open() error: %v", err)
+	}
+	defer db.Close()
+	pool.apply(db)
+	if max := (&service{db: db, pool: pool}).Stats().MaxOpenConnections; max != 40 {
+		t.Errorf("Stats().MaxOpenConnections = %d, want 40", max)
+	}
+}
+
+func TestUserCRUD(t *testing.T) {
+	requirePostgres(t)
+	srv := New()
+	ctx := context.Background()
+
+	created, err := srv.CreateUser(ctx, "crud-user", "crud-name", "crud@example.com")
+	if err != nil {
+		t.Fatalf("CreateUser() error: %v", err)
+	}
+	if created.Status != UserStatusActive || created.CreatedAt.IsZero() {
+		t.Errorf("CreateUser() = %+v, want an active user with created_at", created)
+	}
+
+	user, err := srv.GetUser(ctx, "crud-user")
+	if err != nil {
+		t.Fatalf("GetUser() error: %v", err)
+	}
+	if user.Username != "crud-name" || user.Email != "crud@example.com" || user.LastSeenAt != nil {
+		t.Errorf("GetUser() = %+v, want crud-name never seen", user)
+	}
+
+	if err := srv.UpdateLastSeen(ctx, "crud-user"); err != nil {
+		t.Fatalf("UpdateLastSeen() error: %v", err)
+	}
+	if user, _ := srv.GetUser(ctx, "crud-user"); user.LastSeenAt == nil {
+		t.Error("UpdateLastSeen() should set last_seen_at")
+	}
+
+	if _, err := srv.GetUser(ctx, "missing-user"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("GetUser() of a missing user error = %v, want %v", err, ErrUserNotFound)
+	}
+
+	users, err := srv.ListUsers(ctx, UserStatusActive, 1, 10)
+	if err != nil {
+		t.Fatalf("ListUsers() error: %v", err)
+	}
+	found := false
+	for _, u := range users {
+		found = found || u.ID == "crud-user"
+	}
+	if !found {
+		t.Errorf("ListUsers() = %+v, want crud-user listed", users)
+	}
+	if users, _ := srv.ListUsers(ctx, UserStatusBanned, 1, 10); len(users) != 0 {
+		t.Errorf("ListUsers(banned) = %+v, want none", users)
+	}
+	if all, err := srv.AllUsers(ctx, ""); err != nil || len(all) < len(users) {
+		t.Errorf("AllUsers() = %d users, %v; want at least the %d active", len(all), err, len(users))
+	}
+}
+
+func TestCreateUser_UniqueUsername(t *testing.T) {
+	requirePostgres(t)
+	srv := New()
+	ctx := context.Background()
+
+	if _, err := srv.CreateUser(ctx, "unique-1", "unique-name", ""); err != nil {
+		t.Fatalf("CreateUser() error: %v", err)
+	}
+	if _, err := srv.CreateUser(ctx, "unique-2", "unique-name", ""); !errors.Is(err, ErrUsernameTaken) {
+		t.Errorf("CreateUser() with a taken username error = %v, want %v", err, ErrUsernameTaken)
+	}
+}
+
+func TestSaveRound(t *testing.T) {
+	requirePostgres(t)
+	srv := New()
+	ctx := context.Background()
+
+	round := Round{
+		ID:                    "R-save",
+		ServerSeed:            "seed",
+		HashCommitment:        "commitment",
+		CommitmentPublishedAt: time.Now(),
+		CommitmentSignature:   "signature",
+		ClientSeed:            "client",
+		CrashMultiplier:       2.5,
+		Nonce:                 1,
+		Status:                "BETTING",
+		StartedAt:             time.Now(),
+	}
+	if err := srv.SaveRound(ctx, round); err != nil {
+		t.Fatalf("SaveRound() error: %v", err)
+	}
+
+	crashedAt := time.Now()
+	round.Status, round.CrashedAt, round.Verified = "CRASHED", &crashedAt, true
+	if err := srv.SaveRound(ctx, round); err != nil {
+		t.Fatalf("SaveRound() of the crash error: %v", err)
+	}
+	round.Status, round.CrashedAt, round.Verified = "RUNNING", nil, false
+	if err := srv.SaveRound(ctx, round); err != nil {
+		t.Fatalf("SaveRound() of a stale update error: %v", err)
+	}
+
+	var status string
+	var verified bool
+	err := srv.(*service).db.QueryRowContext(ctx,
+		`SELECT status, verified FROM game_rounds WHERE id = $1`, round.ID).Scan(&status, &verified)
+	if err != nil {
+		t.Fatalf("loading round error: %v", err)
+	}
+	if status != "CRASHED" || !verified {
+		t.Errorf("round status = %s, verified = %v; want a verified crashed round", status, verified)
+	}
+
+	loaded, err := srv.GetRound(ctx, round.ID)
+	if err != nil {
+		t.Fatalf("GetRound() error: %v", err)
+	}
+	if loaded.ServerSeed != "seed" || loaded.CommitmentSignature != "signature" || loaded.CrashMultiplier != 2.5 || loaded.CrashedAt == nil {
+		t.Errorf("GetRound() = %+v, want the crashed round", loaded)
+	}
+	if _, err := srv.GetRound(ctx, "R-missing"); !errors.Is(err, ErrRoundNotFound) {
+		t.Errorf("GetRound() of an unknown round error = %v, want %v", err, ErrRoundNotFound)
+	}
+
+	crashPoints, err := srv.RecentCrashPoints(ctx, 10)
+	if err != nil {
+		t.Fatalf("RecentCrashPoints() error: %v", err)
+	}
+	if len(crashPoints) == 0 || crashPoints[0] != 2.5 {
+		t.Errorf("RecentCrashPoints() = %v, want the crashed round first", crashPoints)
+	}
+}
+
+func TestCrashStats(t *testing.T) {
+	requirePostgres(t)
+	srv := New()
+	ctx := context.Background()
+
+	// Rounds start an hour from now so other tests' rounds fall outside the period
+	since := time.Now().Add(time.Hour).Truncate(time.Second)
+	crashPoints := []float64{1.00, 1.50, 3.00, 12.00, 2.00, 1.20, 5.00, 7.50, 1.99, 4.00,
+		25.00, 1.10, 2.50, 6.00, 1.80, 2.00, 3.50, 10.00, 2.20, 7.50}
+	for i, crashPoint := range crashPoints {
+		round := Round{ID: fmt.Sprintf("R-stats-%d", i), ServerSeed: "seed", HashCommitment: "commitment", ClientSeed: "client",
+			CrashMultiplier: crashPoint, Nonce: i, Status: "CRASHED", StartedAt: since.Add(time.Duration(i) * time.Minute)}
+		if i == len(crashPoints)-1 {
+			round.BonusMultiplier = 0.5
+		}
+		if err := srv.SaveRound(ctx, round); err != nil {
+			t.Fatalf("SaveRound() error: %v", err)
+		}
+	}
+	running := Round{ID: "R-stats-running", ServerSeed: "seed", HashCommitment: "commitment", ClientSeed: "client",
+		CrashMultiplier: 1.5, Nonce: 99, Status: "RUNNING", StartedAt: since.Add(time.Hour)}
+	if err := srv.SaveRound(ctx, running); err != nil {
+		t.Fatalf("SaveRound() error: %v", err)
+	}
+
+	stats, err := srv.CrashStats(ctx, since)
+	if err != nil {
+		t.Fatalf("CrashStats() error: %v", err)
+	}
+	want := CrashStats{
+		TotalRounds:          20,
+		MedianCrashPoint:     2.75,
+		PctUnder2x:           30,
+		Pct2xTo5x:            35,
+		PctOver10x:           15,
+		HighestEver:          25,
+		StreakNoCrashUnder2x: 5,
+		Last10CrashPoints:    []float64{25, 1.10, 2.50, 6.00, 1.80, 2.00, 3.50, 10.00, 2.20, 8.00},
+	}
+	if !reflect.DeepEqual(*stats, want) {
+		t.Errorf("CrashStats() = %+v, want %+v", *stats, want)
+	}
+
+	empty, err := srv.CrashStats(ctx, since.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("CrashStats() of an empty period error: %v", err)
+	}
+	if empty.TotalRounds != 0 || empty.MedianCrashPoint != 0 || len(empty.Last10CrashPoints) != 0 {
+		t.Errorf("CrashStats() of an empty period = %+v, want zeros", *empty)
+	}
+}
+
+func TestSaveBets(t *testing.T) {
+	requirePostgres(t)
+	srv := New()
+	ctx := context.Background()
+
+	if _, err := srv.CreateUser(ctx, "bets-user", "bets-name", ""); err != nil {
+		t.Fatalf("CreateUser() error: %v", err)
+	}
+	round := Round{ID: "R-bets", ServerSeed: "seed", HashCommitment: "commitment", ClientSeed: "client",
+		CrashMultiplier: 3, Nonce: 2, Status: "CRASHED", StartedAt: time.Now()}
+	if err := srv.SaveRound(ctx, round); err != nil {
+		t.Fatalf("SaveRound() error: %v", err)
+	}
+
+	placedAt := time.Now().Truncate(time.Millisecond)
+	cashedOutAt := placedAt.Add(8 * time.Second)
+	bets := []Bet{
+		{ID: "B-win", UserID: "bets-user", RoundID: round.ID, Amount: 10, AutoCashout: 2, CashoutMultiplier: 2,
+			Payout: 20, PlacedAt: placedAt, CashedOutAt: &cashedOutAt, Result: BetResultWin},
+		{ID: "B-loss", UserID: "bets-user", RoundID: round.ID, Amount: 5, PlacedAt: placedAt.Add(time.Second), Result: BetResultLoss},
+	}
+	if err := srv.SaveBets(ctx, bets); err != nil {
+		t.Fatalf("SaveBets() error: %v", err)
+	}
+	if err := srv.SaveBets(ctx, bets[:1]); err != nil {
+		t.Fatalf("SaveBets() of a stored bet error: %v", err)
+	}
+
+	loaded, err := srv.GetRoundBets(ctx, round.ID)
+	if err != nil {
+		t.Fatalf("GetRoundBets() error: %v", err)
+	}
+	if len(loaded) != 2 {
+		t.Fatalf("GetRoundBets() returned %d bets, want 2", len(loaded))
+	}
+	if win := loaded[0]; win.ID != "B-win" || win.CashoutMultiplier != 2 || win.Payout != 20 || win.CashedOutAt == nil {
+		t.Errorf("GetRoundBets()[0] = %+v, want the winning bet", win)
+	}
+	if loss := loaded[1]; loss.ID != "B-loss" || loss.AutoCashout != 0 || loss.CashoutMultiplier != 0 || loss.CashedOutAt != nil {
+		t.Errorf("GetRoundBets()[1] = %+v, want the lost bet", loss)
+	}
+
+	recent, err := srv.UserRecentBets(ctx, "bets-user", 1)
+	if err != nil {
+		t.Fatalf("UserRecentBets() error: %v", err)
+	}
+	if len(recent) != 1 || recent[0].ID != "B-loss" {
+		t.Errorf("UserRecentBets() = %+v, want the latest bet", recent)
+	}
+
+	stats, err := srv.UserBetStats(ctx, "bets-user", placedAt.Add(-time.Minute), placedAt.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("UserBetStats() error: %v", err)
+	}
+	if stats.GamesPlayed != 2 || stats.TotalWagered != 15 || stats.TotalPayout != 20 {
+		t.Errorf("UserBetStats() = %+v, want 2 bets wagering 15 and paying 20", stats)
+	}
+}
+
+func TestHouseProfit(t *testing.T) {
+	requirePostgres(t)
+	srv := New()
+	ctx := context.Background()
+
+	// Settled in the future so other tests' settlements fall outside the period
+	since := time.Now().Add(time.Hour).Truncate(time.Second)
+	settlements := []Settlement{
+		{UserID: "profit-user", GameType: "aviator", Wager: 10, Payout: 25, SettledAt: since},
+		{UserID: "profit-user", GameType: "dice", Wager: 20, Payout: 0, SettledAt: since.Add(time.Minute)},
+		{UserID: "unregistered", GameType: "dice", Wager: 5, Payout: 9.9, SettledAt: since.Add(time.Minute)},
+		{UserID: "profit-user", GameType: "mines", Wager: 50, Payout: 0, SettledAt: since.Add(-2 * time.Hour)},
+	}
+	for _, settlement := range settlements {
+		if err := srv.RecordSettlement(ctx, settlement); err != nil {
+			t.Fatalf("RecordSettlement() error: %v", err)
+		}
+	}
+
+	profits, err := srv.HouseProfit(ctx, since)
+	if err != nil {
+		t.Fatalf("HouseProfit() error: %v", err)
+	}
+	byGame := make(map[string]GameProfit)
+	for _, profit := range profits {
+		byGame[profit.GameType] = profit
+	}
+	if len(byGame) != 2 {
+		t.Errorf("HouseProfit() = %+v, want aviator and dice only", profits)
+	}
+	if aviator := byGame["aviator"]; aviator.Wagered != 10 || aviator.PaidOut != 25 {
+		t.Errorf("aviator profit = %+v, want 10 wagered and 25 paid out", aviator)
+	}
+	if dice := byGame["dice"]; dice.Wagered != 25 || dice.PaidOut != 9.9 {
+		t.Errorf("dice profit = %+v, want 25 wagered and 9.9 paid out", dice)
+	}
+}
+
+func TestBalances(t *testing.T) {
+	requirePostgres(t)
+	srv := New()
+	ctx := context.Background()
+
+	if _, err := srv.CreateUser(ctx, "balance-user", "balance-name", ""); err != nil {
+		t.Fatalf("CreateUser() error: %v", err)
+	}
+	for _, tx := range []Transaction{
+		{UserID: "balance-user", Type: "DEPOSIT", Amount: 100, BalanceAfter: 100},
+		{UserID: "balance-user", Type: "BET", Amount: 30, BalanceBefore: 100, BalanceAfter: 70},
+		{UserID: "balance-user", Type: "WIN", Amount: 45, BalanceBefore: 70, BalanceAfter: 115},
+	} {
+		if err := srv.RecordTransaction(ctx, tx); err != nil {
+			t.Fatalf("RecordTransaction() error: %v", err)
+		}
+	}
+
+	ledger, err := srv.LedgerBalances(ctx)
+	if err != nil {
+		t.Fatalf("LedgerBalances() error: %v", err)
+	}
+	if ledger["balance-user"] != 115 {
+		t.Errorf("LedgerBalances() = %v, want 115 for balance-user", ledger)
+	}
+
+	if err := srv.SetUserBalance(ctx, "balance-user", 115); err != nil {
+		t.Fatalf("SetUserBalance() error: %v", err)
+	}
+	balances, err := srv.UserBalances(ctx)
+	if err != nil {
+		t.Fatalf("UserBalances() error: %v", err)
+	}
+	if balances["balance-user"] != 115 {
+		t.Errorf("UserBalances() = %v, want 115 for balance-user", balances)
+	}
+	if err := srv.SetUserBalance(ctx, "missing-user", 1); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("SetUserBalance() of a missing user error = %v, want %v", err, ErrUserNotFound)
+	}
+}
+
+func TestUserTransactions(t *testing.T) {
+	requirePostgres(t)
+	srv := New()
+	ctx := context.Background()
+
+	if _, err := srv.CreateUser(ctx, "wallet-user", "wallet-name", ""); err != nil {
+		t.Fatalf("CreateUser() error: %v", err)
+	}
+	for _, tx := range []Transaction{
+		{UserID: "wallet-user", Type: "DEPOSIT", Amount: 100, BalanceAfter: 100},
+		{UserID: "wallet-user", Type: "BET", Amount: 30, BalanceBefore: 100, BalanceAfter: 70},
+		{UserID: "wallet-user", Type: "WITHDRAWAL", Amount: 20, BalanceBefore: 70, BalanceAfter: 50, Description: "Simulated withdrawal"},
+	} {
+		if err := srv.RecordTransaction(ctx, tx); err != nil {
+			t.Fatalf("RecordTransaction() error: %v", err)
+		}
+	}
+
+	transactions, err := srv.UserTransactions(ctx, "wallet-user", []string{"DEPOSIT", "WITHDRAWAL"}, 10)
+	if err != nil {
+		t.Fatalf("UserTransactions() error: %v", err)
+	}
+	if len(transactions) != 2 {
+		t.Fatalf("UserTransactions() = %+v, want the deposit and the withdrawal", transactions)
+	}
+	for _, tx := range transactions {
+		if tx.ID == "" || tx.CreatedAt.IsZero() || tx.Type == "BET" {
+			t.Errorf("transaction = %+v, want a stored deposit or withdrawal", tx)
+		}
+	}
+
+	if transactions, _ := srv.UserTransactions(ctx, "wallet-user", []string{"DEPOSIT"}, 10); len(transactions) != 1 {
+		t.Errorf("UserTransactions() of deposits = %+v, want 1", transactions)
+	}
+}
+
+func TestUserRecentBets_Indexed(t *testing.T) {
+	requirePostgres(t)
+	srv := New()
+	ctx := context.Background()
+	db := srv.(*service).db
+
+	for i := 0; i < 10; i++ {
+		id := fmt.Sprintf("history-user-%d", i)
+		if _, err := srv.CreateUser(ctx, id, id, ""); err != nil {
+			t.Fatalf("CreateUser() error: %v", err)
+		}
+	}
+	round := Round{ID: "R-history", ServerSeed: "seed", HashCommitment: "commitment", ClientSeed: "client",
+		CrashMultiplier: 2, Nonce: 3, Status: "CRASHED", StartedAt: time.Now()}
+	if err := srv.SaveRound(ctx, round); err != nil {
+		t.Fatalf("SaveRound() error: %v", err)
+	}
+
+	// 10,000 bets spread over the users, games and the last week
+	_, err := db.ExecContext(ctx,
+		`INSERT INTO bets (id, user_id, round_id, game_type, amount, payout, placed_at, result, profit)
+		 SELECT 'B-history-' || n, 'history-user-' || (n % 10), $1,
+		        (ARRAY['aviator', 'mines', 'plinko', 'dice'])[n % 4 + 1],
+		        10, 0, NOW() - n * INTERVAL '1 minute', 'LOSS', -10
+		 FROM generate_series(1, 10000) AS n`, round.ID)
+	if err != nil {
+		t.Fatalf("inserting bets error: %v", err)
+	}
+	if _, err := db.ExecContext(ctx, `ANALYZE bets`); err != nil {
+		t.Fatalf("ANALYZE error: %v", err)
+	}
+
+	start := time.Now()
+	bets, err := srv.UserRecentBets(ctx, "history-user-3", 50)
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("UserRecentBets() took %v, want under 100ms", elapsed)
+	}
+	if err != nil {
+		t.Fatalf("UserRecentBets() error: %v", err)
+	}
+	if len(bets) != 50 || bets[0].PlacedAt.Before(bets[49].PlacedAt) {
+		t.Errorf("UserRecentBets() returned %d bets, want the latest 50 newest first", len(bets))
+	}
+
+	plan, err := ExplainQuery(ctx, db,
+		`SELECT id FROM bets WHERE user_id = $1 AND game_type = $2 ORDER BY placed_at DESC LIMIT 50`,
+		"history-user-3", "mines")
+	if err != nil {
+		t.Fatalf("ExplainQuery() error: %v", err)
+	}
+	if !strings.Contains(plan, "idx_bets_user_game") || !strings.Contains(plan, "Execution Time") {
+		t.Errorf("ExplainQuery() plan does not use idx_bets_user_game:\n%s", plan)
+	}
 }
 
 func TestClose(t *testing.T) {
+	requirePostgres(t)
 	srv := New()
 
 	if srv.Close() != nil {
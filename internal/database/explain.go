@@ -0,0 +1,31 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+)
+
+// ExplainQuery runs query under EXPLAIN ANALYZE and returns the plan as
+// text, one line per plan row. The query is executed, so it should not
+// change data; it is meant for checking query performance in tests.
+func ExplainQuery(ctx context.Context, db *sql.DB, query string, args ...interface{}) (string, error) {
+	rows, err := db.QueryContext(ctx, "EXPLAIN ANALYZE "+query, args...)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	var plan []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return "", err
+		}
+		plan = append(plan, line)
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+	return strings.Join(plan, "\n"), nil
+}
@@ -2,14 +2,19 @@ package database
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
+// MIGRATIONS_TABLE is where golang-migrate records the applied version
+const MIGRATIONS_TABLE = "schema_migrations"
+
 func RunMigrations(db *sql.DB, migrationsPath string) error {
 	driver, err := postgres.WithInstance(db, &postgres.Config{})
 	if err != nil {
@@ -25,8 +30,9 @@ func RunMigrations(db *sql.DB, migrationsPath string) error {
 		return fmt.Errorf("could not create migrate instance: %w", err)
 	}
 
-	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
-		return fmt.Errorf("migration failed: %w", err)
+	if err := m.Up(); err != nil {
+		version, _, _ := m.Version()
+		return upError(err, version)
 	}
 
 	version, dirty, err := m.Version()
@@ -43,6 +49,41 @@ func RunMigrations(db *sql.DB, migrationsPath string) error {
 	return nil
 }
 
+// upError maps an error from migrate.Up to the error RunMigrations returns.
+// version is the version recorded after the failure, which is the failed
+// migration when golang-migrate got as far as running it.
+func upError(err error, version uint) error {
+	var dirtyErr migrate.ErrDirty
+	switch {
+	case errors.Is(err, migrate.ErrNoChange):
+		log.Println("Already up to date")
+		return nil
+	case errors.As(err, &dirtyErr):
+		return fmt.Errorf("migration %d failed: %w", dirtyErr.Version, err)
+	default:
+		return fmt.Errorf("migration %d failed: %w", version, err)
+	}
+}
+
+// GetDirtyVersion reads the migration table directly, so it works even when
+// golang-migrate refuses to run because the database is dirty. A database
+// that has never been migrated is reported as version 0 and clean.
+func GetDirtyVersion(db *sql.DB) (version uint, dirty bool, err error) {
+	var recorded int64
+	err = db.QueryRow("SELECT version, dirty FROM "+MIGRATIONS_TABLE+" LIMIT 1").Scan(&recorded, &dirty)
+
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return 0, false, nil
+	case errors.As(err, &pgErr) && pgErr.Code == "42P01": // undefined_table
+		return 0, false, nil
+	case err != nil:
+		return 0, false, fmt.Errorf("could not read %s: %w", MIGRATIONS_TABLE, err)
+	}
+	return uint(recorded), dirty, nil
+}
+
 func RollbackMigration(db *sql.DB, migrationsPath string) error {
 	driver, err := postgres.WithInstance(db, &postgres.Config{})
 	if err != nil {
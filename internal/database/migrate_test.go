@@ -0,0 +1,158 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// fakeMigrationTable is a database/sql driver that answers every query with
+// rows of (version, dirty), or with err
+type fakeMigrationTable struct {
+	rows [][]driver.Value
+	err  error
+}
+
+func (f fakeMigrationTable) Connect(context.Context) (driver.Conn, error) { return f, nil }
+func (f fakeMigrationTable) Driver() driver.Driver                        { return f }
+func (f fakeMigrationTable) Open(string) (driver.Conn, error)             { return f, nil }
+func (f fakeMigrationTable) Close() error                                 { return nil }
+
+func (f fakeMigrationTable) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare not supported")
+}
+
+func (f fakeMigrationTable) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions not supported")
+}
+
+func (f fakeMigrationTable) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &fakeRows{rows: f.rows}, nil
+}
+
+type fakeRows struct {
+	rows [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return []string{"version", "dirty"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestGetDirtyVersion(t *testing.T) {
+	connErr := errors.New("connection reset")
+	tests := []struct {
+		name        string
+		table       fakeMigrationTable
+		wantVersion uint
+		wantDirty   bool
+		wantErr     error
+	}{
+		{
+			name:        "dirty",
+			table:       fakeMigrationTable{rows: [][]driver.Value{{int64(5), true}}},
+			wantVersion: 5,
+			wantDirty:   true,
+		},
+		{
+			name:        "clean",
+			table:       fakeMigrationTable{rows: [][]driver.Value{{int64(8), false}}},
+			wantVersion: 8,
+		},
+		{
+			name:  "never migrated",
+			table: fakeMigrationTable{},
+		},
+		{
+			name:  "no migration table",
+			table: fakeMigrationTable{err: &pgconn.PgError{Code: "42P01", Message: `relation "schema_migrations" does not exist`}},
+		},
+		{
+			name:    "other database error",
+			table:   fakeMigrationTable{err: connErr},
+			wantErr: connErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := sql.OpenDB(tt.table)
+			defer db.Close()
+
+			version, dirty, err := GetDirtyVersion(db)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetDirtyVersion() error = %v, want %v", err, tt.wantErr)
+			}
+			if version != tt.wantVersion || dirty != tt.wantDirty {
+				t.Errorf("GetDirtyVersion() = %d, %v; want %d, %v", version, dirty, tt.wantVersion, tt.wantDirty)
+			}
+		})
+	}
+}
+
+func TestUpError(t *testing.T) {
+	syntaxErr := errors.New("syntax error at or near \"TABEL\"")
+	tests := []struct {
+		name    string
+		err     error
+		version uint
+		want    string
+	}{
+		{
+			name:    "no change",
+			err:     migrate.ErrNoChange,
+			version: 8,
+		},
+		{
+			name:    "failed migration",
+			err:     syntaxErr,
+			version: 3,
+			want:    `migration 3 failed: syntax error at or near "TABEL"`,
+		},
+		{
+			name: "already dirty",
+			err:  migrate.ErrDirty{Version: 5},
+			want: "migration 5 failed: Dirty database version 5. Fix and force version.",
+		},
+		{
+			name:    "locked",
+			err:     migrate.ErrLocked,
+			version: 7,
+			want:    "migration 7 failed: database locked",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := upError(tt.err, tt.version)
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("upError() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.want {
+				t.Fatalf("upError() = %v, want %q", err, tt.want)
+			}
+			if !errors.Is(err, tt.err) && !errors.As(err, new(migrate.ErrDirty)) {
+				t.Errorf("upError() = %v does not wrap %v", err, tt.err)
+			}
+		})
+	}
+}
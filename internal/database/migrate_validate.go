@@ -0,0 +1,278 @@
+package database
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MIGRATION_HEADER starts every up migration written by `migrate create`
+	MIGRATION_HEADER = "-- Migration:"
+
+	// LEGACY_MIGRATION_VERSION is the last migration written before up files
+	// carried a header. Applied migrations must not be edited, so these are
+	// accepted without one.
+	LEGACY_MIGRATION_VERSION = 7
+)
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// ValidationError is a problem found in one migration file. File is empty for
+// problems with the set of migrations as a whole.
+type ValidationError struct {
+	File    string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	if e.File == "" {
+		return e.Message
+	}
+	return e.File + ": " + e.Message
+}
+
+// migrationPair is the up and down file of one migration version
+type migrationPair struct {
+	name     string
+	up, down string
+}
+
+// ValidateMigrationFiles checks the migrations directory without connecting to
+// a database: every version needs an up and a down file, versions must run
+// from 1 without gaps, and each file must be non-empty, lexically sound SQL.
+// Up files after LEGACY_MIGRATION_VERSION must start with the header written
+// by `migrate create`.
+func ValidateMigrationFiles(migrationsPath string) []ValidationError {
+	entries, err := os.ReadDir(migrationsPath)
+	if err != nil {
+		return []ValidationError{{File: migrationsPath, Message: fmt.Sprintf("cannot read directory: %v", err)}}
+	}
+
+	var errs []ValidationError
+	versions := make(map[int]*migrationPair)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".sql") {
+			continue
+		}
+
+		match := migrationFileName.FindStringSubmatch(name)
+		if match == nil {
+			errs = append(errs, ValidationError{File: name, Message: "name does not match <version>_<name>.up.sql or <version>_<name>.down.sql"})
+			continue
+		}
+		version, _ := strconv.Atoi(match[1])
+
+		pair, exists := versions[version]
+		if !exists {
+			pair = &migrationPair{name: match[2]}
+			versions[version] = pair
+		} else if pair.name != match[2] {
+			errs = append(errs, ValidationError{File: name, Message: fmt.Sprintf("version %d is also used by %s", version, pair.name)})
+			continue
+		}
+		if match[3] == "up" {
+			pair.up = name
+		} else {
+			pair.down = name
+		}
+
+		needsHeader := match[3] == "up" && version > LEGACY_MIGRATION_VERSION
+		errs = append(errs, validateMigrationFile(filepath.Join(migrationsPath, name), needsHeader)...)
+	}
+
+	if len(versions) == 0 && len(errs) == 0 {
+		return []ValidationError{{File: migrationsPath, Message: "contains no migration files"}}
+	}
+
+	numbers := make([]int, 0, len(versions))
+	for version := range versions {
+		numbers = append(numbers, version)
+	}
+	sort.Ints(numbers)
+
+	expected := 1
+	for _, version := range numbers {
+		for ; expected < version; expected++ {
+			errs = append(errs, ValidationError{Message: fmt.Sprintf("migration %06d is missing", expected)})
+		}
+		expected = version + 1
+
+		pair := versions[version]
+		switch {
+		case pair.down == "":
+			errs = append(errs, ValidationError{File: pair.up, Message: "has no matching .down.sql file"})
+		case pair.up == "":
+			errs = append(errs, ValidationError{File: pair.down, Message: "has no matching .up.sql file"})
+		}
+	}
+
+	return errs
+}
+
+// LatestMigrationVersion returns the highest version in the migrations
+// directory: the version a fully migrated database is at
+func LatestMigrationVersion(migrationsPath string) (uint, error) {
+	entries, err := os.ReadDir(migrationsPath)
+	if err != nil {
+		return 0, err
+	}
+
+	var latest uint
+	for _, entry := range entries {
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		if version, err := strconv.ParseUint(match[1], 10, 64); err == nil && uint(version) > latest {
+			latest = uint(version)
+		}
+	}
+	if latest == 0 {
+		return 0, fmt.Errorf("%s contains no migration files", migrationsPath)
+	}
+	return latest, nil
+}
+
+// validateMigrationFile checks the contents of one migration file
+func validateMigrationFile(path string, needsHeader bool) []ValidationError {
+	name := filepath.Base(path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []ValidationError{{File: name, Message: fmt.Sprintf("cannot read file: %v", err)}}
+	}
+
+	sql := string(data)
+	if strings.TrimSpace(sql) == "" {
+		return []ValidationError{{File: name, Message: "is empty"}}
+	}
+
+	var errs []ValidationError
+	if needsHeader && !strings.HasPrefix(sql, MIGRATION_HEADER) {
+		errs = append(errs, ValidationError{File: name, Message: fmt.Sprintf("does not start with a %q header", MIGRATION_HEADER)})
+	}
+	if problem := checkSQLSyntax(sql); problem != "" {
+		errs = append(errs, ValidationError{File: name, Message: problem})
+	}
+	return errs
+}
+
+// checkSQLSyntax performs the lexical checks that catch most broken files
+// without a SQL parser: strings, quoted identifiers, comments and dollar quotes
+// must be closed, parentheses balanced, and the last statement terminated. It
+// returns a description of the first problem, or "" if none was found.
+func checkSQLSyntax(sql string) string {
+	depth := 0
+	var code strings.Builder // The SQL with comments and quoted text removed
+
+	for i := 0; i < len(sql); i++ {
+		switch c := sql[i]; {
+		case strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				i = len(sql)
+			} else {
+				i += end
+			}
+		case strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				return "unterminated block comment"
+			}
+			i += end + 3
+		case c == '\'' || c == '"':
+			end := closingQuote(sql, i+1, c)
+			if end < 0 {
+				return fmt.Sprintf("unterminated %s on line %d", quoteKind(c), lineOf(sql, i))
+			}
+			code.WriteByte('x')
+			i = end
+		case c == '$':
+			tag := dollarQuoteTag(sql[i:])
+			if tag == "" {
+				code.WriteByte(c)
+				continue
+			}
+			end := strings.Index(sql[i+len(tag):], tag)
+			if end < 0 {
+				return fmt.Sprintf("unterminated %s quote on line %d", tag, lineOf(sql, i))
+			}
+			code.WriteByte('x')
+			i += len(tag) + end + len(tag) - 1
+		case c == '(':
+			depth++
+			code.WriteByte(c)
+		case c == ')':
+			depth--
+			if depth < 0 {
+				return fmt.Sprintf("unbalanced ')' on line %d", lineOf(sql, i))
+			}
+			code.WriteByte(c)
+		default:
+			code.WriteByte(c)
+		}
+	}
+
+	statements := strings.TrimSpace(code.String())
+	switch {
+	case depth > 0:
+		return "unbalanced '('"
+	case statements == "":
+		return "contains no SQL statements"
+	case !strings.HasSuffix(statements, ";"):
+		return "last statement is not terminated with ';'"
+	}
+	return ""
+}
+
+// closingQuote returns the index of the quote that closes a string or quoted
+// identifier opened just before start, treating doubled quotes as escapes
+func closingQuote(sql string, start int, quote byte) int {
+	for i := start; i < len(sql); i++ {
+		if sql[i] != quote {
+			continue
+		}
+		if i+1 < len(sql) && sql[i+1] == quote {
+			i++
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
+// dollarQuoteTag returns the $tag$ opening a dollar-quoted string at the start
+// of sql, or "" if there is none
+func dollarQuoteTag(sql string) string {
+	end := strings.IndexByte(sql[1:], '$')
+	if end < 0 {
+		return ""
+	}
+	tag := sql[:end+2]
+	for _, r := range tag[1 : len(tag)-1] {
+		if r != '_' && (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return ""
+		}
+	}
+	if len(tag) > 2 && tag[1] >= '0' && tag[1] <= '9' {
+		return "" // $1 is a parameter, not a tag
+	}
+	return tag
+}
+
+func quoteKind(quote byte) string {
+	if quote == '"' {
+		return "quoted identifier"
+	}
+	return "string"
+}
+
+// lineOf returns the 1-based line number of offset in sql
+func lineOf(sql string, offset int) int {
+	return strings.Count(sql[:offset], "\n") + 1
+}
@@ -0,0 +1,181 @@
+package database
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+const (
+	validUpSQL   = "-- Migration: create_things\n\nCREATE TABLE things (id INT PRIMARY KEY, name TEXT DEFAULT 'it''s');\n"
+	validDownSQL = "-- Rollback: create_things\n\nDROP TABLE IF EXISTS things;\n"
+)
+
+// writeMigrations creates a migrations directory holding files
+func writeMigrations(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+// withLegacyMigrations adds migrations 1 through LEGACY_MIGRATION_VERSION,
+// written without a header, to files
+func withLegacyMigrations(files map[string]string) map[string]string {
+	for version := 1; version <= LEGACY_MIGRATION_VERSION; version++ {
+		files[fmt.Sprintf("%06d_legacy.up.sql", version)] = "CREATE TABLE things (id INT);\n"
+		files[fmt.Sprintf("%06d_legacy.down.sql", version)] = validDownSQL
+	}
+	return files
+}
+
+func TestValidateMigrationFiles_RepoMigrations(t *testing.T) {
+	for _, err := range ValidateMigrationFiles("../../migrations") {
+		t.Errorf("unexpected validation error: %v", err)
+	}
+}
+
+func TestValidateMigrationFiles(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []string
+	}{
+		{
+			name: "valid",
+			files: map[string]string{
+				"000001_create_things.up.sql":   validUpSQL,
+				"000001_create_things.down.sql": validDownSQL,
+				"000002_add_function.up.sql": "-- Migration: add_function\n\n" +
+					"CREATE FUNCTION touch() RETURNS TRIGGER AS $$\nBEGIN\n    NEW.name = ')';\n    RETURN NEW;\nEND;\n$$ LANGUAGE plpgsql;\n",
+				"000002_add_function.down.sql": "DROP FUNCTION IF EXISTS touch(); /* no ( here */\n",
+				"README.md":                    "not a migration",
+			},
+		},
+		{
+			name: "missing down file",
+			files: map[string]string{
+				"000001_create_things.up.sql": validUpSQL,
+			},
+			want: []string{"000001_create_things.up.sql: has no matching .down.sql file"},
+		},
+		{
+			name: "missing up file",
+			files: map[string]string{
+				"000001_create_things.down.sql": validDownSQL,
+			},
+			want: []string{"000001_create_things.down.sql: has no matching .up.sql file"},
+		},
+		{
+			name: "numbering gaps",
+			files: map[string]string{
+				"000002_create_things.up.sql":   validUpSQL,
+				"000002_create_things.down.sql": validDownSQL,
+				"000004_more_things.up.sql":     validUpSQL,
+				"000004_more_things.down.sql":   validDownSQL,
+			},
+			want: []string{"migration 000001 is missing", "migration 000003 is missing"},
+		},
+		{
+			name: "duplicate version",
+			files: map[string]string{
+				"000001_create_things.up.sql":   validUpSQL,
+				"000001_create_things.down.sql": validDownSQL,
+				"000001_other_things.up.sql":    validUpSQL,
+			},
+			want: []string{"000001_other_things.up.sql: version 1 is also used by create_things"},
+		},
+		{
+			name: "bad file name",
+			files: map[string]string{
+				"create_things.sql": validUpSQL,
+			},
+			want: []string{"create_things.sql: name does not match <version>_<name>.up.sql or <version>_<name>.down.sql"},
+		},
+		{
+			name: "empty and template files",
+			files: map[string]string{
+				"000001_create_things.up.sql":   "-- Migration: create_things\n-- Created: now\n\n-- Add your SQL here\n",
+				"000001_create_things.down.sql": "\n  \n",
+			},
+			want: []string{
+				"000001_create_things.down.sql: is empty",
+				"000001_create_things.up.sql: contains no SQL statements",
+			},
+		},
+		{
+			name: "missing header after the legacy migrations",
+			files: withLegacyMigrations(map[string]string{
+				"000008_create_things.up.sql":   "CREATE TABLE things (id INT);\n",
+				"000008_create_things.down.sql": validDownSQL,
+			}),
+			want: []string{`000008_create_things.up.sql: does not start with a "-- Migration:" header`},
+		},
+		{
+			name: "syntax problems",
+			files: map[string]string{
+				"000001_unbalanced.up.sql":     "-- Migration: unbalanced\nCREATE TABLE things (id INT;\n",
+				"000001_unbalanced.down.sql":   "DROP TABLE things);\n",
+				"000002_unterminated.up.sql":   "-- Migration: unterminated\nINSERT INTO things VALUES (1, 'oops);\n",
+				"000002_unterminated.down.sql": "DROP TABLE things\n",
+				"000003_dollar_quote.up.sql":   "-- Migration: dollar_quote\nCREATE FUNCTION f() RETURNS INT AS $body$ SELECT 1;\n",
+				"000003_dollar_quote.down.sql": "/* DROP FUNCTION f();\n",
+				"000004_quoted_ident.up.sql":   "-- Migration: quoted_ident\nCREATE TABLE \"things (id INT);\n",
+				"000004_quoted_ident.down.sql": validDownSQL,
+			},
+			want: []string{
+				"000001_unbalanced.down.sql: unbalanced ')' on line 1",
+				"000001_unbalanced.up.sql: unbalanced '('",
+				"000002_unterminated.down.sql: last statement is not terminated with ';'",
+				"000002_unterminated.up.sql: unterminated string on line 2",
+				"000003_dollar_quote.down.sql: unterminated block comment",
+				"000003_dollar_quote.up.sql: unterminated $body$ quote on line 2",
+				"000004_quoted_ident.up.sql: unterminated quoted identifier on line 2",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, err := range ValidateMigrationFiles(writeMigrations(t, tt.files)) {
+				got = append(got, err.Error())
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ValidateMigrationFiles() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateMigrationFiles_NoMigrations(t *testing.T) {
+	dir := t.TempDir()
+	for _, path := range []string{dir, filepath.Join(dir, "missing")} {
+		if errs := ValidateMigrationFiles(path); len(errs) != 1 || errs[0].File != path {
+			t.Errorf("ValidateMigrationFiles(%s) = %v, want one error for the directory", path, errs)
+		}
+	}
+}
+
+func TestLatestMigrationVersion(t *testing.T) {
+	dir := writeMigrations(t, map[string]string{
+		"000001_create_things.up.sql":   validUpSQL,
+		"000001_create_things.down.sql": validDownSQL,
+		"000012_add_more.up.sql":        validUpSQL,
+		"notes.txt":                     "999999",
+	})
+	if version, err := LatestMigrationVersion(dir); err != nil || version != 12 {
+		t.Errorf("LatestMigrationVersion() = %d, %v, want 12", version, err)
+	}
+
+	if _, err := LatestMigrationVersion(t.TempDir()); err == nil {
+		t.Error("expected an error for a directory without migrations")
+	}
+}
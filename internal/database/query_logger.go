@@ -0,0 +1,184 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	SLOW_QUERY_THRESHOLD_MS = 100 // Default, overridden by SLOW_QUERY_THRESHOLD_MS
+	SLOW_QUERY_LOG_SIZE     = 10
+	QUERY_PREVIEW_LENGTH    = 120
+)
+
+// SlowQuery is a query that took longer than the slow query threshold
+type SlowQuery struct {
+	Operation    string    `json:"operation"`
+	DurationMs   float64   `json:"duration_ms"`
+	QueryPreview string    `json:"query_preview"`
+	RowsReturned int64     `json:"rows_returned"` // Rows affected for statements
+	At           time.Time `json:"at"`
+}
+
+// QueryStats summarizes every query run since startup
+type QueryStats struct {
+	TotalQueries          int64   `json:"total_queries"`
+	AvgDurationMs         float64 `json:"avg_duration_ms"`
+	MaxDurationMs         float64 `json:"max_duration_ms"`
+	QueriesAboveThreshold int64   `json:"queries_above_threshold"`
+}
+
+// QueryLogger runs the service's queries on a connection, timing each one.
+// Queries slower than the threshold are logged and kept in a ring buffer of
+// the last SLOW_QUERY_LOG_SIZE.
+type QueryLogger struct {
+	db        *sql.DB
+	threshold time.Duration
+
+	total      atomic.Int64
+	totalNanos atomic.Int64
+	maxNanos   atomic.Int64
+	slow       atomic.Int64
+
+	slowest []SlowQuery
+	head    int // Oldest entry once the buffer is full
+	mu      sync.Mutex
+}
+
+func NewQueryLogger(db *sql.DB, threshold time.Duration) *QueryLogger {
+	return &QueryLogger{
+		db:        db,
+		threshold: threshold,
+		slowest:   make([]SlowQuery, 0, SLOW_QUERY_LOG_SIZE),
+	}
+}
+
+// slowQueryThresholdFromEnv reads SLOW_QUERY_THRESHOLD_MS
+func slowQueryThresholdFromEnv() time.Duration {
+	return time.Duration(getEnvAsInt("SLOW_QUERY_THRESHOLD_MS", SLOW_QUERY_THRESHOLD_MS)) * time.Millisecond
+}
+
+// Threshold returns the duration above which a query counts as slow
+func (l *QueryLogger) Threshold() time.Duration {
+	return l.threshold
+}
+
+// ExecContext runs a statement, recording the rows it affected
+func (l *QueryLogger) ExecContext(ctx context.Context, operation, query string, args ...interface{}) (sql.Result, error) {
+	start := time.Now()
+	result, err := l.db.ExecContext(ctx, query, args...)
+	var rows int64
+	if err == nil {
+		rows, _ = result.RowsAffected()
+	}
+	l.Observe(operation, query, time.Since(start), rows)
+	return result, err
+}
+
+// QueryRowContext runs a query expected to return at most one row
+func (l *QueryLogger) QueryRowContext(ctx context.Context, operation, query string, args ...interface{}) *sql.Row {
+	start := time.Now()
+	row := l.db.QueryRowContext(ctx, query, args...)
+	l.Observe(operation, query, time.Since(start), 1)
+	return row
+}
+
+// QueryContext runs a query and calls scan for each row. The recorded
+// duration includes reading every row.
+func (l *QueryLogger) QueryContext(ctx context.Context, operation, query string, scan func(*sql.Rows) error, args ...interface{}) error {
+	start := time.Now()
+	var returned int64
+	defer func() { l.Observe(operation, query, time.Since(start), returned) }()
+
+	rows, err := l.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		returned++
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// Observe records a query that took elapsed, logging it if it was slow
+func (l *QueryLogger) Observe(operation, query string, elapsed time.Duration, rows int64) {
+	l.total.Add(1)
+	l.totalNanos.Add(int64(elapsed))
+	for {
+		max := l.maxNanos.Load()
+		if int64(elapsed) <= max || l.maxNanos.CompareAndSwap(max, int64(elapsed)) {
+			break
+		}
+	}
+	if elapsed <= l.threshold {
+		return
+	}
+	l.slow.Add(1)
+
+	entry := SlowQuery{
+		Operation:    operation,
+		DurationMs:   durationMs(elapsed),
+		QueryPreview: queryPreview(query),
+		RowsReturned: rows,
+		At:           time.Now(),
+	}
+	log.Printf("[DB] Slow query: operation=%s duration_ms=%.1f rows_returned=%d query=%q",
+		entry.Operation, entry.DurationMs, entry.RowsReturned, entry.QueryPreview)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.slowest) < cap(l.slowest) {
+		l.slowest = append(l.slowest, entry)
+	} else {
+		l.slowest[l.head] = entry
+		l.head = (l.head + 1) % len(l.slowest)
+	}
+}
+
+// SlowQueries returns the last slow queries, newest first
+func (l *QueryLogger) SlowQueries() []SlowQuery {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	queries := make([]SlowQuery, 0, len(l.slowest))
+	for i := len(l.slowest) - 1; i >= 0; i-- {
+		queries = append(queries, l.slowest[(l.head+i)%len(l.slowest)])
+	}
+	return queries
+}
+
+// Stats returns the totals of every query recorded
+func (l *QueryLogger) Stats() QueryStats {
+	stats := QueryStats{
+		TotalQueries:          l.total.Load(),
+		MaxDurationMs:         durationMs(time.Duration(l.maxNanos.Load())),
+		QueriesAboveThreshold: l.slow.Load(),
+	}
+	if stats.TotalQueries > 0 {
+		stats.AvgDurationMs = durationMs(time.Duration(l.totalNanos.Load() / stats.TotalQueries))
+	}
+	return stats
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// queryPreview collapses the whitespace of a query and truncates it
+func queryPreview(query string) string {
+	preview := strings.Join(strings.Fields(query), " ")
+	if len(preview) > QUERY_PREVIEW_LENGTH {
+		preview = preview[:QUERY_PREVIEW_LENGTH] + "..."
+	}
+	return preview
+}
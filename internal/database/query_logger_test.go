@@ -0,0 +1,124 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// slowDriver is a database/sql driver whose queries take latency when they
+// mention slowTable, and return three rows of 2.5
+type slowDriver struct {
+	slowTable string
+	latency   time.Duration
+}
+
+func (d slowDriver) Open(name string) (driver.Conn, error) { return slowConn{d}, nil }
+
+type slowConn struct{ slowDriver }
+
+func (c slowConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("prepare not supported")
+}
+
+func (c slowConn) Close() error { return nil }
+
+func (c slowConn) Begin() (driver.Tx, error) { return nil, fmt.Errorf("transactions not supported") }
+
+func (c slowConn) wait(query string) {
+	if strings.Contains(query, c.slowTable) {
+		time.Sleep(c.latency)
+	}
+}
+
+func (c slowConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.wait(query)
+	return &slowRows{left: 3}, nil
+}
+
+func (c slowConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.wait(query)
+	return driver.RowsAffected(1), nil
+}
+
+type slowRows struct{ left int }
+
+func (r *slowRows) Columns() []string { return []string{"value"} }
+
+func (r *slowRows) Close() error { return nil }
+
+func (r *slowRows) Next(dest []driver.Value) error {
+	if r.left == 0 {
+		return io.EOF
+	}
+	r.left--
+	dest[0] = 2.5
+	return nil
+}
+
+func init() {
+	sql.Register("slowdb", slowDriver{slowTable: "game_rounds", latency: 30 * time.Millisecond})
+}
+
+func TestQueryLogger_SlowQueries(t *testing.T) {
+	db, err := sql.Open("slowdb", "")
+	if err != nil {
+		t.Fatalf("sql.Open() error: %v", err)
+	}
+	defer db.Close()
+	srv := &service{db: db, queries: NewQueryLogger(db, 20*time.Millisecond)}
+	ctx := context.Background()
+
+	crashPoints, err := srv.RecentCrashPoints(ctx, 10)
+	if err != nil || len(crashPoints) != 3 {
+		t.Fatalf("RecentCrashPoints() = %v, %v; want 3 crash points", crashPoints, err)
+	}
+	if err := srv.UpdateLastSeen(ctx, "user1"); err != nil {
+		t.Fatalf("UpdateLastSeen() error: %v", err)
+	}
+
+	slow := srv.SlowQueries()
+	if len(slow) != 1 {
+		t.Fatalf("SlowQueries() = %+v, want only the game_rounds query", slow)
+	}
+	if q := slow[0]; q.Operation != "RecentCrashPoints" || q.RowsReturned != 3 || q.DurationMs < 30 ||
+		!strings.HasPrefix(q.QueryPreview, "SELECT crash_multiplier + bonus_multiplier FROM game_rounds WHERE") {
+		t.Errorf("SlowQueries()[0] = %+v, want RecentCrashPoints returning 3 rows in at least 30ms", q)
+	}
+
+	stats := srv.QueryStats()
+	if stats.TotalQueries != 2 || stats.QueriesAboveThreshold != 1 || stats.MaxDurationMs < 30 ||
+		stats.AvgDurationMs < 15 || stats.AvgDurationMs > stats.MaxDurationMs {
+		t.Errorf("QueryStats() = %+v, want 2 queries with 1 above the threshold", stats)
+	}
+}
+
+func TestQueryLogger_RingBuffer(t *testing.T) {
+	logger := NewQueryLogger(nil, time.Millisecond)
+	if slow := logger.SlowQueries(); len(slow) != 0 {
+		t.Errorf("SlowQueries() of a new logger = %+v, want none", slow)
+	}
+
+	for i := 1; i <= SLOW_QUERY_LOG_SIZE+2; i++ {
+		logger.Observe(fmt.Sprintf("op%d", i), "SELECT 1", time.Duration(i)*time.Second, 0)
+	}
+	logger.Observe("fast", "SELECT 1", time.Microsecond, 0)
+
+	slow := logger.SlowQueries()
+	if len(slow) != SLOW_QUERY_LOG_SIZE || slow[0].Operation != "op12" || slow[len(slow)-1].Operation != "op3" {
+		t.Errorf("SlowQueries() = %+v, want op12 down to op3", slow)
+	}
+	if stats := logger.Stats(); stats.TotalQueries != 13 || stats.QueriesAboveThreshold != 12 || stats.MaxDurationMs != 12000 {
+		t.Errorf("Stats() = %+v, want 13 queries, 12 slow, 12s at most", stats)
+	}
+
+	long := strings.Repeat("SELECT  1,\n\t", 50)
+	if preview := queryPreview(long); len(preview) != QUERY_PREVIEW_LENGTH+3 || strings.ContainsAny(preview, "\n\t") {
+		t.Errorf("queryPreview() = %q, want one line truncated to %d characters", preview, QUERY_PREVIEW_LENGTH)
+	}
+}
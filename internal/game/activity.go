@@ -0,0 +1,185 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// Per-minute activity counters, suffixed with the Unix minute
+	REDIS_KEY_MINES_CLICKS = "mines:clicks:"
+	REDIS_KEY_DICE_ROLLS   = "dice:rolls:"
+	REDIS_KEY_PLINKO_DROPS = "plinko:drops:"
+
+	REDIS_KEY_MINES_ACTIVE_STATS  = "mines:stats:active"
+	REDIS_KEY_DICE_ACTIVE_STATS   = "dice:stats:active"
+	REDIS_KEY_PLINKO_ACTIVE_STATS = "plinko:stats:active"
+
+	ACTIVITY_COUNTER_TTL   = 120 * time.Second
+	ACTIVE_STATS_CACHE_TTL = 5 * time.Second
+)
+
+// MinesActiveStats shows how busy Mines is right now
+type MinesActiveStats struct {
+	ActiveGames            int     `json:"active_games"`
+	TilesClickedLastMinute int     `json:"tiles_clicked_last_minute"`
+	AvgMineCount           float64 `json:"avg_mine_count"` // Over active games
+	TotalWageredActive     Amount  `json:"total_wagered_active"`
+}
+
+// DiceActiveStats shows how busy Dice is right now
+type DiceActiveStats struct {
+	RollsLastMinute int `json:"rolls_last_minute"`
+}
+
+// PlinkoActiveStats shows how busy Plinko is right now
+type PlinkoActiveStats struct {
+	BallsDroppedLastMinute int `json:"balls_dropped_last_minute"`
+}
+
+// recordActivity counts one event in the current minute's counter
+func recordActivity(ctx context.Context, client *redis.Client, prefix string) {
+	key := prefix + strconv.FormatInt(time.Now().Unix()/60, 10)
+	pipe := client.TxPipeline()
+	pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, ACTIVITY_COUNTER_TTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("[ACTIVITY] Failed to count %s: %v", key, err)
+	}
+}
+
+// activityLastMinute estimates the events of the last 60 seconds from the
+// current and previous minute counters, weighting the previous minute by the
+// share of it still inside the window
+func activityLastMinute(ctx context.Context, client *redis.Client, prefix string) (int, error) {
+	now := time.Now()
+	minute := now.Unix() / 60
+	counts, err := client.MGet(ctx, prefix+strconv.FormatInt(minute-1, 10), prefix+strconv.FormatInt(minute, 10)).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	var previous, current float64
+	if s, ok := counts[0].(string); ok {
+		previous, _ = strconv.ParseFloat(s, 64)
+	}
+	if s, ok := counts[1].(string); ok {
+		current, _ = strconv.ParseFloat(s, 64)
+	}
+	elapsed := float64(now.Unix()%60) / 60
+	return int(current + previous*(1-elapsed)), nil
+}
+
+// cachedActiveStats returns the stats cached at key, computing and caching
+// them for ACTIVE_STATS_CACHE_TTL when missing
+func cachedActiveStats[T any](ctx context.Context, client *redis.Client, key string, compute func() (T, error)) (T, error) {
+	var stats T
+	if data, err := client.Get(ctx, key).Bytes(); err == nil && json.Unmarshal(data, &stats) == nil {
+		return stats, nil
+	}
+
+	stats, err := compute()
+	if err != nil {
+		return stats, err
+	}
+	data, _ := json.Marshal(stats)
+	client.Set(ctx, key, data, ACTIVE_STATS_CACHE_TTL)
+	return stats, nil
+}
+
+// activeStats scans the stored games for the active ones
+func (m *MinesEngine) activeStats(ctx context.Context) (MinesActiveStats, error) {
+	return cachedActiveStats(ctx, m.redisClient, REDIS_KEY_MINES_ACTIVE_STATS, func() (MinesActiveStats, error) {
+		stats, err := m.scanActiveGames(ctx)
+		if err != nil {
+			return stats, err
+		}
+
+		clicks, err := activityLastMinute(ctx, m.redisClient, REDIS_KEY_MINES_CLICKS)
+		stats.TilesClickedLastMinute = clicks
+		return stats, err
+	})
+}
+
+// scanActiveGames summarises the stored games that are still in progress
+func (m *MinesEngine) scanActiveGames(ctx context.Context) (MinesActiveStats, error) {
+	var stats MinesActiveStats
+	var mines int
+	iter := m.redisClient.Scan(ctx, 0, REDIS_KEY_MINES_GAME+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		gameJSON, err := m.redisClient.Get(ctx, iter.Val()).Result()
+		if err != nil {
+			continue // Expired since the scan found it
+		}
+		gameState, err := decodeMinesGame(gameJSON)
+		if err != nil || gameState.Status != "ACTIVE" {
+			continue
+		}
+		stats.ActiveGames++
+		mines += gameState.MineCount
+		stats.TotalWageredActive = stats.TotalWageredActive.Add(gameState.BetAmount)
+	}
+	if err := iter.Err(); err != nil {
+		return stats, err
+	}
+	if stats.ActiveGames > 0 {
+		stats.AvgMineCount = float64(mines) / float64(stats.ActiveGames)
+	}
+	return stats, nil
+}
+
+// activeGamesByUser counts the games still in progress of each user
+func (m *MinesEngine) activeGamesByUser(ctx context.Context) (map[string]int, error) {
+	counts := make(map[string]int)
+	iter := m.redisClient.Scan(ctx, 0, REDIS_KEY_MINES_GAME+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		gameJSON, err := m.redisClient.Get(ctx, iter.Val()).Result()
+		if err != nil {
+			continue // Expired since the scan found it
+		}
+		gameState, err := decodeMinesGame(gameJSON)
+		if err != nil || gameState.Status != "ACTIVE" {
+			continue
+		}
+		counts[gameState.UserID]++
+	}
+	return counts, iter.Err()
+}
+
+func (d *DiceEngine) activeStats(ctx context.Context) (DiceActiveStats, error) {
+	return cachedActiveStats(ctx, d.redisClient, REDIS_KEY_DICE_ACTIVE_STATS, func() (DiceActiveStats, error) {
+		rolls, err := activityLastMinute(ctx, d.redisClient, REDIS_KEY_DICE_ROLLS)
+		return DiceActiveStats{RollsLastMinute: rolls}, err
+	})
+}
+
+func (p *PlinkoEngine) activeStats(ctx context.Context) (PlinkoActiveStats, error) {
+	return cachedActiveStats(ctx, p.redisClient, REDIS_KEY_PLINKO_ACTIVE_STATS, func() (PlinkoActiveStats, error) {
+		drops, err := activityLastMinute(ctx, p.redisClient, REDIS_KEY_PLINKO_DROPS)
+		return PlinkoActiveStats{BallsDroppedLastMinute: drops}, err
+	})
+}
+
+// ActiveStats collects the activity stats of every registered engine that
+// keeps them, keyed by game type. Engines whose stats fail are left out.
+func (gf *GameFactory) ActiveStats(ctx context.Context) map[GameType]interface{} {
+	all := make(map[GameType]interface{})
+	for _, gameType := range []GameType{GameTypeMines, GameTypeDice, GameTypePlinko} {
+		engine, exists := gf.GetEngine(gameType)
+		if !exists {
+			continue
+		}
+		stats, err := engine.ProcessAction(ctx, "active_count", nil)
+		if err != nil {
+			log.Printf("[ACTIVITY] Failed to load %s stats: %v", gameType, err)
+			continue
+		}
+		all[gameType] = stats
+	}
+	return all
+}
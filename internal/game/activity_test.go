@@ -0,0 +1,81 @@
+package game
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestMinesEngine_ActiveStats(t *testing.T) {
+	engine, client := newTestMinesEngine(t)
+	ctx := context.Background()
+
+	var gameIDs []string
+	for i, mineCount := range []int{1, 3, 5, 7} {
+		resp, _ := engine.PlaceBet(ctx, MinesBetRequest{UserID: "user1", Amount: float64(10 * (i + 1)), MineCount: mineCount})
+		bet := resp.(MinesBetResponse)
+		if !bet.Success {
+			t.Fatalf("PlaceBet() = %+v", bet)
+		}
+		gameIDs = append(gameIDs, bet.GameID)
+	}
+	engine.ProcessAction(ctx, "forfeit", MinesForfeitRequest{UserID: "user1", GameID: gameIDs[3]})
+
+	gameState, _ := engine.loadGame(ctx, gameIDs[0])
+	safeTile := 0
+	if gameState.MinePositions[0] == 0 {
+		safeTile = 1
+	}
+	click, _ := engine.ProcessAction(ctx, "click", MinesClickRequest{UserID: "user1", GameID: gameIDs[0], TileID: safeTile})
+	if !click.(MinesClickResponse).Success {
+		t.Fatalf("click = %+v", click)
+	}
+
+	resp, err := engine.ProcessAction(ctx, "active_count", nil)
+	if err != nil {
+		t.Fatalf("active_count error: %v", err)
+	}
+	stats := resp.(MinesActiveStats)
+	if stats.ActiveGames != 3 || stats.AvgMineCount != 3 || stats.TotalWageredActive != amountOf(60) || stats.TilesClickedLastMinute != 1 {
+		t.Errorf("stats = %+v, want 3 active games averaging 3 mines with 60 wagered and 1 click", stats)
+	}
+
+	// Served from the cache until it expires
+	engine.PlaceBet(ctx, MinesBetRequest{UserID: "user1", Amount: 10, MineCount: 1})
+	resp, _ = engine.ProcessAction(ctx, "active_count", nil)
+	if cached := resp.(MinesActiveStats); cached.ActiveGames != 3 {
+		t.Errorf("cached active_games = %d, want 3", cached.ActiveGames)
+	}
+	if ttl := client.TTL(ctx, REDIS_KEY_MINES_ACTIVE_STATS).Val(); ttl <= 0 || ttl > ACTIVE_STATS_CACHE_TTL {
+		t.Errorf("stats cache TTL = %v, want at most %v", ttl, ACTIVE_STATS_CACHE_TTL)
+	}
+
+	client.Del(ctx, REDIS_KEY_MINES_ACTIVE_STATS)
+	resp, _ = engine.ProcessAction(ctx, "active_count", nil)
+	if fresh := resp.(MinesActiveStats); fresh.ActiveGames != 4 {
+		t.Errorf("active_games after the cache expired = %d, want 4", fresh.ActiveGames)
+	}
+}
+
+func TestActivityLastMinute(t *testing.T) {
+	_, client := newTestMinesEngine(t)
+	ctx := context.Background()
+
+	minute := time.Now().Unix() / 60
+	client.Set(ctx, REDIS_KEY_DICE_ROLLS+strconv.FormatInt(minute-2, 10), 1000, 0) // Outside the window
+	client.Set(ctx, REDIS_KEY_DICE_ROLLS+strconv.FormatInt(minute-1, 10), 60, 0)
+	recordActivity(ctx, client, REDIS_KEY_DICE_ROLLS)
+
+	if ttl := client.TTL(ctx, REDIS_KEY_DICE_ROLLS+strconv.FormatInt(minute, 10)).Val(); ttl <= 0 || ttl > ACTIVITY_COUNTER_TTL {
+		t.Errorf("counter TTL = %v, want at most %v", ttl, ACTIVITY_COUNTER_TTL)
+	}
+
+	count, err := activityLastMinute(ctx, client, REDIS_KEY_DICE_ROLLS)
+	if err != nil {
+		t.Fatalf("activityLastMinute() error: %v", err)
+	}
+	if count < 1 || count > 61 {
+		t.Errorf("activityLastMinute() = %d, want the current roll plus part of the previous minute's 60", count)
+	}
+}
@@ -0,0 +1,123 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+const (
+	// AMOUNT_DECIMALS is the number of decimal places money is tracked to,
+	// matching the DECIMAL(20,2) columns in PostgreSQL
+	AMOUNT_DECIMALS = 2
+	AMOUNT_SCALE    = 100
+
+	// amountEpsilon absorbs float64 representation error, e.g. 1.15 * 100 = 114.99999999999999
+	amountEpsilon = 1e-6
+)
+
+var (
+	ErrAmountPrecision = fmt.Errorf("amount must have at most %d decimal places", AMOUNT_DECIMALS)
+	ErrAmountInvalid   = errors.New("amount is not a finite number")
+	ErrAmountNegative  = errors.New("amount cannot go below zero")
+)
+
+// Amount is a monetary value stored as an integer number of minor units
+// (cents), so sums and differences are exact. It is encoded in JSON as a
+// plain number for compatibility with clients that send float64 amounts.
+type Amount int64
+
+// NewAmount converts a float to an Amount, rejecting values with more than
+// AMOUNT_DECIMALS decimal places
+func NewAmount(value float64) (Amount, error) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, ErrAmountInvalid
+	}
+
+	scaled := value * AMOUNT_SCALE
+	if math.Abs(scaled) >= math.MaxInt64 {
+		return 0, ErrAmountInvalid
+	}
+
+	rounded := math.Round(scaled)
+	if math.Abs(scaled-rounded) > amountEpsilon*math.Max(1, math.Abs(scaled)) {
+		return 0, ErrAmountPrecision
+	}
+	return Amount(rounded), nil
+}
+
+// Add returns a + b
+func (a Amount) Add(b Amount) Amount {
+	return a + b
+}
+
+// Sub returns a - b, or ErrAmountNegative if b is larger than a
+func (a Amount) Sub(b Amount) (Amount, error) {
+	if b > a {
+		return 0, ErrAmountNegative
+	}
+	return a - b, nil
+}
+
+// Mul scales the amount by a multiplier, rounding down to the nearest minor
+// unit so payouts never exceed the exact product
+func (a Amount) Mul(multiplier float64) Amount {
+	product := float64(a) * multiplier
+	if product >= 0 {
+		return Amount(math.Floor(product + amountEpsilon))
+	}
+	return Amount(math.Ceil(product - amountEpsilon))
+}
+
+// Float64 returns the amount in major units
+func (a Amount) Float64() float64 {
+	return float64(a) / AMOUNT_SCALE
+}
+
+// String formats the amount with exactly AMOUNT_DECIMALS decimal places
+func (a Amount) String() string {
+	sign := ""
+	units := int64(a)
+	if units < 0 {
+		sign = "-"
+		units = -units
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, units/AMOUNT_SCALE, units%AMOUNT_SCALE)
+}
+
+// MarshalJSON encodes the amount as a JSON number
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON decodes a JSON number, enforcing decimal precision
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	value, err := strconv.ParseFloat(string(data), 64)
+	if err != nil {
+		return fmt.Errorf("invalid amount %s: %w", data, err)
+	}
+
+	amount, err := NewAmount(value)
+	if err != nil {
+		return err
+	}
+	*a = amount
+	return nil
+}
+
+// validateBetAmount checks a bet amount against the table limits and the
+// supported precision. It returns a message for the player, or "" if valid.
+func validateBetAmount(amount float64) string {
+	if amount < MIN_BET_AMOUNT || amount > MAX_BET_AMOUNT {
+		return fmt.Sprintf("Bet must be between %.2f and %.2f", MIN_BET_AMOUNT, MAX_BET_AMOUNT)
+	}
+	if _, err := NewAmount(amount); err != nil {
+		return fmt.Sprintf("Bet must have at most %d decimal places", AMOUNT_DECIMALS)
+	}
+	return ""
+}
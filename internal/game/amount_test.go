@@ -0,0 +1,84 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// amountOf converts a float literal known to have at most two decimals
+func amountOf(value float64) Amount {
+	amount, err := NewAmount(value)
+	if err != nil {
+		panic(err)
+	}
+	return amount
+}
+
+func TestAmount_ExactArithmetic(t *testing.T) {
+	sum := amountOf(0.1).Add(amountOf(0.2))
+	if sum != amountOf(0.3) {
+		t.Errorf("0.1 + 0.2 = %s, want 0.30", sum)
+	}
+	if sum.Float64() != 0.3 {
+		t.Errorf("Float64() = %v, want 0.3", sum.Float64())
+	}
+
+	diff, err := amountOf(1.00).Sub(amountOf(0.99))
+	if err != nil || diff != amountOf(0.01) {
+		t.Errorf("1.00 - 0.99 = %s, %v; want 0.01", diff, err)
+	}
+	if _, err := amountOf(1).Sub(amountOf(2)); !errors.Is(err, ErrAmountNegative) {
+		t.Errorf("Sub() below zero error = %v, want ErrAmountNegative", err)
+	}
+}
+
+func TestAmount_Mul(t *testing.T) {
+	tests := []struct {
+		amount     float64
+		multiplier float64
+		want       string
+	}{
+		{10.00, 1.15, "11.50"}, // 1000 * 1.15 = 1149.9999999999998 in float64
+		{10.00, 2.456, "24.56"},
+		{0.01, 0.5, "0.00"}, // Payouts round down
+		{100.00, 0, "0.00"},
+	}
+
+	for _, tt := range tests {
+		if got := amountOf(tt.amount).Mul(tt.multiplier).String(); got != tt.want {
+			t.Errorf("%.2f * %v = %s, want %s", tt.amount, tt.multiplier, got, tt.want)
+		}
+	}
+}
+
+func TestNewAmount_Precision(t *testing.T) {
+	if _, err := NewAmount(10.005); !errors.Is(err, ErrAmountPrecision) {
+		t.Errorf("NewAmount(10.005) error = %v, want ErrAmountPrecision", err)
+	}
+	if amount, err := NewAmount(19.99); err != nil || amount != 1999 {
+		t.Errorf("NewAmount(19.99) = %d, %v; want 1999", amount, err)
+	}
+	if amount := amountOf(-2.5); amount.String() != "-2.50" {
+		t.Errorf("String() = %s, want -2.50", amount)
+	}
+}
+
+func TestAmount_JSON(t *testing.T) {
+	data, _ := json.Marshal(struct {
+		Amount Amount `json:"amount"`
+	}{amountOf(12.3)})
+	if string(data) != `{"amount":12.30}` {
+		t.Errorf("Marshal() = %s", data)
+	}
+
+	var decoded struct {
+		Amount Amount `json:"amount"`
+	}
+	if err := json.Unmarshal([]byte(`{"amount": 0.3}`), &decoded); err != nil || decoded.Amount != 30 {
+		t.Errorf("Unmarshal() = %d, %v; want 30", decoded.Amount, err)
+	}
+	if err := json.Unmarshal([]byte(`{"amount": 1.001}`), &decoded); !errors.Is(err, ErrAmountPrecision) {
+		t.Errorf("Unmarshal(1.001) error = %v, want ErrAmountPrecision", err)
+	}
+}
@@ -0,0 +1,270 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	REDIS_KEY_ANOMALY_WINS        = "anomaly:wins:"
+	REDIS_KEY_ANOMALY_PROFIT      = "anomaly:profit:"
+	REDIS_KEY_ANOMALY_FLAGS       = "anomaly:flags:"
+	REDIS_KEY_ANOMALY_SEVERITY    = "anomaly:severity:"
+	REDIS_KEY_ANOMALY_FLAGGED     = "anomaly:flagged"
+	REDIS_KEY_ANOMALY_HISTORY     = "anomaly:history:"
+	REDIS_KEY_ANOMALY_FREQUENCY   = "anomaly:frequency:"
+	REDIS_KEY_ANOMALY_GAME_PROFIT = "anomaly:game_profit:"
+
+	ANOMALY_WIN_RATE_WINDOW     = 100  // Bets considered for the win rate
+	ANOMALY_MAX_WIN_RATE        = 0.60 // Win rate above which a user is flagged
+	ANOMALY_PROFIT_MEDIAN_RATIO = 10.0 // Hourly profit above this many medians is flagged
+	ANOMALY_MIN_PROFIT_SAMPLE   = 5    // Players needed in a game type before the median is trusted
+	ANOMALY_MAX_BETS_PER_MINUTE = 100
+	ANOMALY_ADVISORY_RATIO      = 0.9 // Fraction of a threshold at which bets are marked suspicious
+
+	ANOMALY_HOUR_LAYOUT = "2006010215"
+	ANOMALY_STATS_TTL   = 2 * time.Hour
+	ANOMALY_HISTORY_TTL = 24 * time.Hour
+)
+
+// Anomaly flag types
+const (
+	AnomalyHighWinRate   = "high_win_rate"
+	AnomalyUnusualProfit = "unusual_profit"
+	AnomalyHighFrequency = "high_frequency"
+)
+
+// GameOutcome is a settled bet as seen by the anomaly detector
+type GameOutcome struct {
+	UserID   string
+	GameType GameType
+	Wager    Amount
+	Payout   Amount
+}
+
+// Win reports whether the bet returned more than was wagered
+func (o GameOutcome) Win() bool {
+	return o.Payout > o.Wager
+}
+
+// AnomalyFlag is a single rule a user has tripped. Severity is the observed
+// value divided by the threshold, so anything above 1 was flagged.
+type AnomalyFlag struct {
+	Type     string  `json:"type"`
+	Severity float64 `json:"severity"`
+}
+
+// FlaggedUser lists the anomaly flags raised for one user
+type FlaggedUser struct {
+	UserID   string        `json:"user_id"`
+	Flags    []AnomalyFlag `json:"flags"`
+	Severity float64       `json:"severity"` // Highest flag severity
+}
+
+// anomalyStats is a user's recent activity, gathered after each outcome
+type anomalyStats struct {
+	RecentWins       []bool // Most recent first, at most ANOMALY_WIN_RATE_WINDOW
+	HourProfit       float64
+	MedianProfit     float64 // Median hourly profit of players of the same game type
+	ProfitSampleSize int64
+	BetsLastMinute   int64
+}
+
+// AnomalyDetector tracks per-user win rates, profit and bet frequency in Redis
+// and flags users whose activity looks suspicious for manual review
+type AnomalyDetector struct {
+	redisClient *redis.Client
+}
+
+func NewAnomalyDetector(redisClient *redis.Client) *AnomalyDetector {
+	return &AnomalyDetector{redisClient: redisClient}
+}
+
+// Record adds an outcome to the user's statistics and flags any rule it trips.
+// It reports whether the user is close to a threshold; the result is advisory.
+func (a *AnomalyDetector) Record(ctx context.Context, outcome GameOutcome) bool {
+	suspicious, err := a.recordAt(ctx, outcome, time.Now())
+	if err != nil {
+		log.Printf("[ANOMALY] Failed to record outcome for %s: %v", outcome.UserID, err)
+	}
+	return suspicious
+}
+
+func (a *AnomalyDetector) recordAt(ctx context.Context, outcome GameOutcome, now time.Time) (bool, error) {
+	stats, err := a.updateStats(ctx, outcome, now)
+	if err != nil {
+		return false, err
+	}
+
+	flags, suspicious := evaluateAnomalies(stats)
+	if len(flags) == 0 {
+		return suspicious, nil
+	}
+
+	pipe := a.redisClient.TxPipeline()
+	for _, flag := range flags {
+		pipe.SAdd(ctx, REDIS_KEY_ANOMALY_FLAGS+outcome.UserID, flag.Type)
+		pipe.HSet(ctx, REDIS_KEY_ANOMALY_SEVERITY+outcome.UserID, flag.Type, flag.Severity)
+	}
+	pipe.SAdd(ctx, REDIS_KEY_ANOMALY_FLAGGED, outcome.UserID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return true, err
+	}
+
+	log.Printf("[ANOMALY] User %s flagged: %v", outcome.UserID, flags)
+	return true, nil
+}
+
+// updateStats records the outcome and reads back the statistics the rules need
+func (a *AnomalyDetector) updateStats(ctx context.Context, outcome GameOutcome, now time.Time) (anomalyStats, error) {
+	userID := outcome.UserID
+	hour := now.UTC().Format(ANOMALY_HOUR_LAYOUT)
+	profit := (outcome.Payout - outcome.Wager).Float64()
+
+	winsKey := REDIS_KEY_ANOMALY_WINS + userID + ":" + hour
+	profitKey := REDIS_KEY_ANOMALY_PROFIT + userID + ":" + hour
+	gameProfitKey := REDIS_KEY_ANOMALY_GAME_PROFIT + string(outcome.GameType) + ":" + hour
+	historyKey := REDIS_KEY_ANOMALY_HISTORY + userID
+	frequencyKey := REDIS_KEY_ANOMALY_FREQUENCY + userID + ":" + strconv.FormatInt(now.Unix()/60, 10)
+
+	win := "0"
+	if outcome.Win() {
+		win = "1"
+	}
+
+	pipe := a.redisClient.TxPipeline()
+	if outcome.Win() {
+		pipe.Incr(ctx, winsKey)
+		pipe.Expire(ctx, winsKey, ANOMALY_STATS_TTL)
+	}
+	pipe.IncrByFloat(ctx, profitKey, profit)
+	pipe.Expire(ctx, profitKey, ANOMALY_STATS_TTL)
+	userProfit := pipe.ZIncrBy(ctx, gameProfitKey, profit, userID)
+	pipe.Expire(ctx, gameProfitKey, ANOMALY_STATS_TTL)
+	pipe.LPush(ctx, historyKey, win)
+	pipe.LTrim(ctx, historyKey, 0, ANOMALY_WIN_RATE_WINDOW-1)
+	pipe.Expire(ctx, historyKey, ANOMALY_HISTORY_TTL)
+	history := pipe.LRange(ctx, historyKey, 0, -1)
+	frequency := pipe.Incr(ctx, frequencyKey)
+	pipe.Expire(ctx, frequencyKey, 2*time.Minute)
+	sampleSize := pipe.ZCard(ctx, gameProfitKey)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return anomalyStats{}, err
+	}
+
+	stats := anomalyStats{
+		HourProfit:       userProfit.Val(),
+		ProfitSampleSize: sampleSize.Val(),
+		BetsLastMinute:   frequency.Val(),
+	}
+	for _, entry := range history.Val() {
+		stats.RecentWins = append(stats.RecentWins, entry == "1")
+	}
+
+	// The median is the middle score, or the mean of the two middle scores
+	n := stats.ProfitSampleSize
+	middle, err := a.redisClient.ZRangeWithScores(ctx, gameProfitKey, (n-1)/2, n/2).Result()
+	if err != nil {
+		return anomalyStats{}, err
+	}
+	for _, z := range middle {
+		stats.MedianProfit += z.Score / float64(len(middle))
+	}
+
+	return stats, nil
+}
+
+// evaluateAnomalies applies each detection rule to a user's statistics. It
+// returns the rules that were tripped and whether any rule is close to tripping.
+func evaluateAnomalies(stats anomalyStats) ([]AnomalyFlag, bool) {
+	var flags []AnomalyFlag
+	suspicious := false
+
+	check := func(flagType string, value, threshold float64) {
+		ratio := value / threshold
+		if ratio > 1 {
+			flags = append(flags, AnomalyFlag{Type: flagType, Severity: ratio})
+		}
+		if ratio >= ANOMALY_ADVISORY_RATIO {
+			suspicious = true
+		}
+	}
+
+	if len(stats.RecentWins) >= ANOMALY_WIN_RATE_WINDOW {
+		wins := 0
+		for _, win := range stats.RecentWins {
+			if win {
+				wins++
+			}
+		}
+		check(AnomalyHighWinRate, float64(wins)/float64(len(stats.RecentWins)), ANOMALY_MAX_WIN_RATE)
+	}
+
+	if stats.ProfitSampleSize >= ANOMALY_MIN_PROFIT_SAMPLE && stats.MedianProfit > 0 {
+		check(AnomalyUnusualProfit, stats.HourProfit, ANOMALY_PROFIT_MEDIAN_RATIO*stats.MedianProfit)
+	}
+
+	check(AnomalyHighFrequency, float64(stats.BetsLastMinute), ANOMALY_MAX_BETS_PER_MINUTE)
+
+	return flags, suspicious
+}
+
+// FlaggedUsers returns every flagged user, most severe first
+func (a *AnomalyDetector) FlaggedUsers(ctx context.Context) ([]FlaggedUser, error) {
+	userIDs, err := a.redisClient.SMembers(ctx, REDIS_KEY_ANOMALY_FLAGGED).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]FlaggedUser, 0, len(userIDs))
+	for _, userID := range userIDs {
+		flagTypes, err := a.redisClient.SMembers(ctx, REDIS_KEY_ANOMALY_FLAGS+userID).Result()
+		if err != nil {
+			return nil, err
+		}
+		severities, err := a.redisClient.HGetAll(ctx, REDIS_KEY_ANOMALY_SEVERITY+userID).Result()
+		if err != nil {
+			return nil, err
+		}
+		if len(flagTypes) == 0 {
+			continue
+		}
+
+		sort.Strings(flagTypes)
+		user := FlaggedUser{UserID: userID, Flags: make([]AnomalyFlag, 0, len(flagTypes))}
+		for _, flagType := range flagTypes {
+			severity, _ := strconv.ParseFloat(severities[flagType], 64)
+			user.Flags = append(user.Flags, AnomalyFlag{Type: flagType, Severity: severity})
+			if severity > user.Severity {
+				user.Severity = severity
+			}
+		}
+		users = append(users, user)
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		if users[i].Severity != users[j].Severity {
+			return users[i].Severity > users[j].Severity
+		}
+		return users[i].UserID < users[j].UserID
+	})
+	return users, nil
+}
+
+// ClearFlags removes every flag raised for a user and reports whether any existed
+func (a *AnomalyDetector) ClearFlags(ctx context.Context, userID string) (bool, error) {
+	pipe := a.redisClient.TxPipeline()
+	deleted := pipe.Del(ctx, REDIS_KEY_ANOMALY_FLAGS+userID)
+	pipe.Del(ctx, REDIS_KEY_ANOMALY_SEVERITY+userID)
+	pipe.SRem(ctx, REDIS_KEY_ANOMALY_FLAGGED, userID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, fmt.Errorf("clear anomaly flags for %s: %w", userID, err)
+	}
+	return deleted.Val() > 0, nil
+}
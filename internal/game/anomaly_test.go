@@ -0,0 +1,199 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// syntheticHistory returns n outcomes, the first wins of them wins
+func syntheticHistory(n, wins int) []bool {
+	history := make([]bool, n)
+	for i := 0; i < wins; i++ {
+		history[i] = true
+	}
+	return history
+}
+
+func hasFlag(flags []AnomalyFlag, flagType string) bool {
+	for _, flag := range flags {
+		if flag.Type == flagType {
+			return true
+		}
+	}
+	return false
+}
+
+func TestEvaluateAnomalies(t *testing.T) {
+	t.Run("high win rate", func(t *testing.T) {
+		flags, suspicious := evaluateAnomalies(anomalyStats{RecentWins: syntheticHistory(100, 75)})
+		if !hasFlag(flags, AnomalyHighWinRate) || !suspicious {
+			t.Fatalf("75%% win rate should be flagged, got %v", flags)
+		}
+		if math.Abs(flags[0].Severity-1.25) > 1e-9 {
+			t.Errorf("severity = %v, want 1.25", flags[0].Severity)
+		}
+	})
+
+	t.Run("win rate needs a full window", func(t *testing.T) {
+		flags, suspicious := evaluateAnomalies(anomalyStats{RecentWins: syntheticHistory(20, 20)})
+		if len(flags) != 0 || suspicious {
+			t.Errorf("20 straight wins should not be judged yet, got %v", flags)
+		}
+	})
+
+	t.Run("normal win rate", func(t *testing.T) {
+		flags, suspicious := evaluateAnomalies(anomalyStats{RecentWins: syntheticHistory(100, 50)})
+		if len(flags) != 0 || suspicious {
+			t.Errorf("50%% win rate should not be flagged, got %v", flags)
+		}
+	})
+
+	t.Run("win rate approaching the limit is advisory", func(t *testing.T) {
+		flags, suspicious := evaluateAnomalies(anomalyStats{RecentWins: syntheticHistory(100, 56)})
+		if len(flags) != 0 || !suspicious {
+			t.Errorf("56%% win rate should be suspicious but not flagged, got %v", flags)
+		}
+	})
+
+	t.Run("unusual profit", func(t *testing.T) {
+		flags, _ := evaluateAnomalies(anomalyStats{HourProfit: 500, MedianProfit: 20, ProfitSampleSize: 10})
+		if !hasFlag(flags, AnomalyUnusualProfit) {
+			t.Errorf("25x median profit should be flagged, got %v", flags)
+		}
+	})
+
+	t.Run("profit ignored without enough players", func(t *testing.T) {
+		flags, _ := evaluateAnomalies(anomalyStats{HourProfit: 500, MedianProfit: 20, ProfitSampleSize: 2})
+		if hasFlag(flags, AnomalyUnusualProfit) {
+			t.Error("profit should not be judged against a tiny sample")
+		}
+	})
+
+	t.Run("profit ignored when the median is a loss", func(t *testing.T) {
+		flags, _ := evaluateAnomalies(anomalyStats{HourProfit: 500, MedianProfit: -10, ProfitSampleSize: 10})
+		if hasFlag(flags, AnomalyUnusualProfit) {
+			t.Error("profit should not be compared to a negative median")
+		}
+	})
+
+	t.Run("high frequency", func(t *testing.T) {
+		flags, _ := evaluateAnomalies(anomalyStats{BetsLastMinute: 150})
+		if !hasFlag(flags, AnomalyHighFrequency) {
+			t.Errorf("150 bets per minute should be flagged, got %v", flags)
+		}
+		if flags, _ := evaluateAnomalies(anomalyStats{BetsLastMinute: 100}); len(flags) != 0 {
+			t.Errorf("100 bets per minute is the limit, got %v", flags)
+		}
+	})
+}
+
+func newTestAnomalyDetector(t *testing.T) (*AnomalyDetector, *redis.Client) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewAnomalyDetector(client), client
+}
+
+func TestAnomalyDetector_HighWinRate(t *testing.T) {
+	detector, client := newTestAnomalyDetector(t)
+	ctx := context.Background()
+	start := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	// 100 bets spread over minutes so the frequency rule stays quiet, 70 of them won
+	for i := 0; i < 100; i++ {
+		outcome := GameOutcome{UserID: "lucky", GameType: GameTypeDice, Wager: 1000}
+		if i%10 < 7 {
+			outcome.Payout = 2000
+		}
+		if _, err := detector.recordAt(ctx, outcome, start.Add(time.Duration(i)*time.Minute)); err != nil {
+			t.Fatalf("recordAt() error: %v", err)
+		}
+	}
+
+	flags, _ := client.SMembers(ctx, REDIS_KEY_ANOMALY_FLAGS+"lucky").Result()
+	if len(flags) != 1 || flags[0] != AnomalyHighWinRate {
+		t.Fatalf("flags = %v, want [%s]", flags, AnomalyHighWinRate)
+	}
+	if wins, _ := client.Get(ctx, REDIS_KEY_ANOMALY_WINS+"lucky:2026010110").Int(); wins != 42 {
+		t.Errorf("wins in the first hour = %d, want 42", wins)
+	}
+
+	users, err := detector.FlaggedUsers(ctx)
+	if err != nil {
+		t.Fatalf("FlaggedUsers() error: %v", err)
+	}
+	if len(users) != 1 || users[0].UserID != "lucky" {
+		t.Fatalf("FlaggedUsers() = %+v, want lucky", users)
+	}
+	if math.Abs(users[0].Severity-0.7/ANOMALY_MAX_WIN_RATE) > 1e-9 {
+		t.Errorf("severity = %v, want %v", users[0].Severity, 0.7/ANOMALY_MAX_WIN_RATE)
+	}
+
+	cleared, err := detector.ClearFlags(ctx, "lucky")
+	if err != nil || !cleared {
+		t.Fatalf("ClearFlags() = %v, %v; want true", cleared, err)
+	}
+	if users, _ := detector.FlaggedUsers(ctx); len(users) != 0 {
+		t.Errorf("FlaggedUsers() after clear = %+v, want none", users)
+	}
+	if cleared, _ := detector.ClearFlags(ctx, "lucky"); cleared {
+		t.Error("clearing twice should report nothing to clear")
+	}
+}
+
+func TestAnomalyDetector_UnusualProfit(t *testing.T) {
+	detector, client := newTestAnomalyDetector(t)
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	// Five regular players each win 10.00 this hour
+	for i := 0; i < 5; i++ {
+		outcome := GameOutcome{UserID: fmt.Sprintf("regular-%d", i), GameType: GameTypePlinko, Wager: 1000, Payout: 2000}
+		if _, err := detector.recordAt(ctx, outcome, now); err != nil {
+			t.Fatalf("recordAt() error: %v", err)
+		}
+	}
+
+	outcome := GameOutcome{UserID: "whale", GameType: GameTypePlinko, Wager: 1000, Payout: 51000}
+	suspicious, err := detector.recordAt(ctx, outcome, now)
+	if err != nil {
+		t.Fatalf("recordAt() error: %v", err)
+	}
+	if !suspicious {
+		t.Error("flagged outcome should be marked suspicious")
+	}
+
+	if flagged, _ := client.SIsMember(ctx, REDIS_KEY_ANOMALY_FLAGS+"whale", AnomalyUnusualProfit).Result(); !flagged {
+		t.Error("500.00 profit against a 10.00 median should be flagged")
+	}
+	if profit, _ := client.Get(ctx, REDIS_KEY_ANOMALY_PROFIT+"whale:2026010110").Float64(); profit != 500 {
+		t.Errorf("hourly profit = %.2f, want 500.00", profit)
+	}
+	if flagged, _ := client.Exists(ctx, REDIS_KEY_ANOMALY_FLAGS+"regular-0").Result(); flagged != 0 {
+		t.Error("regular players should not be flagged")
+	}
+}
+
+func TestAnomalyDetector_HighFrequency(t *testing.T) {
+	detector, client := newTestAnomalyDetector(t)
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	for i := 0; i <= ANOMALY_MAX_BETS_PER_MINUTE; i++ {
+		outcome := GameOutcome{UserID: "bot", GameType: GameTypeDice, Wager: 100}
+		if _, err := detector.recordAt(ctx, outcome, now.Add(time.Duration(i)*100*time.Millisecond)); err != nil {
+			t.Fatalf("recordAt() error: %v", err)
+		}
+	}
+
+	if flagged, _ := client.SIsMember(ctx, REDIS_KEY_ANOMALY_FLAGS+"bot", AnomalyHighFrequency).Result(); !flagged {
+		t.Error("101 bets in one minute should be flagged")
+	}
+}
@@ -0,0 +1,106 @@
+package game
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	REDIS_KEY_AUTO_CASHOUT_TARGETS = "crash:autocashout_targets" // Bets per auto-cashout target, across rounds
+
+	MAX_AUTO_CASHOUT_JITTER_MS = 500
+)
+
+// AutoCashoutTarget is how many bets chose one auto-cashout target
+type AutoCashoutTarget struct {
+	Target   float64 `json:"target"`
+	Count    int64   `json:"count"`
+	SharePct float64 `json:"share_pct"`
+}
+
+// AutoCashoutDistribution shows which auto-cashout targets players choose, to
+// tune jitter recommendations. Round targets are whole multipliers like 2x.
+type AutoCashoutDistribution struct {
+	Total               int64               `json:"total"`
+	RoundTargetSharePct float64             `json:"round_target_share_pct"`
+	Targets             []AutoCashoutTarget `json:"targets"` // Ascending
+}
+
+// multiplierCurve is the multiplier after elapsed seconds, before
+// calculateMultiplier truncates it to two decimals
+func multiplierCurve(elapsed float64) float64 {
+	return 1.0 + (elapsed / 1.5) + (elapsed * elapsed * 0.005)
+}
+
+// multiplierElapsed is the inverse of multiplierCurve: the seconds after which
+// a round reaches mult
+func multiplierElapsed(mult float64) float64 {
+	const a, b = 0.005, 1 / 1.5
+	c := 1 - mult
+	return (-b + math.Sqrt(b*b-4*a*c)) / (2 * a)
+}
+
+// autoCashoutJitter returns how far past target a bet's auto-cashout
+// triggers: the multiplier gained over a delay of up to maxMs. The delay is
+// derived from the round's hash commitment and the bet ID, so it is fixed for
+// the bet and can be checked by the player.
+func autoCashoutJitter(hashCommitment, betID string, target float64, maxMs int) float64 {
+	if maxMs <= 0 || target <= 0 {
+		return 0
+	}
+	sum := sha256.Sum256([]byte(hashCommitment + ":" + betID))
+	fraction := float64(binary.BigEndian.Uint64(sum[:8])) / float64(math.MaxUint64)
+	delay := fraction * float64(maxMs) / 1000
+
+	elapsed := multiplierElapsed(target)
+	return multiplierCurve(elapsed+delay) - multiplierCurve(elapsed)
+}
+
+// recordAutoCashoutTarget counts a bet towards the auto-cashout distribution
+func (m *Manager) recordAutoCashoutTarget(ctx context.Context, target float64) {
+	field := strconv.FormatFloat(target, 'f', 2, 64)
+	if err := m.redisClient.HIncrBy(ctx, REDIS_KEY_AUTO_CASHOUT_TARGETS, field, 1).Err(); err != nil {
+		log.Printf("[BET] Failed to record auto-cashout target %s: %v", field, err)
+	}
+}
+
+// GetAutoCashoutDistribution returns how often each auto-cashout target was chosen
+func GetAutoCashoutDistribution(ctx context.Context, redisClient *redis.Client) (AutoCashoutDistribution, error) {
+	counts, err := redisClient.HGetAll(ctx, REDIS_KEY_AUTO_CASHOUT_TARGETS).Result()
+	if err != nil {
+		return AutoCashoutDistribution{}, err
+	}
+
+	dist := AutoCashoutDistribution{Targets: []AutoCashoutTarget{}}
+	var roundTargets int64
+	for field, value := range counts {
+		target, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			continue
+		}
+		count, _ := strconv.ParseInt(value, 10, 64)
+		dist.Targets = append(dist.Targets, AutoCashoutTarget{Target: target, Count: count})
+		dist.Total += count
+		if target == math.Trunc(target) {
+			roundTargets += count
+		}
+	}
+	sort.Slice(dist.Targets, func(i, j int) bool {
+		return dist.Targets[i].Target < dist.Targets[j].Target
+	})
+
+	if dist.Total > 0 {
+		for i := range dist.Targets {
+			dist.Targets[i].SharePct = float64(dist.Targets[i].Count) / float64(dist.Total) * 100
+		}
+		dist.RoundTargetSharePct = float64(roundTargets) / float64(dist.Total) * 100
+	}
+	return dist, nil
+}
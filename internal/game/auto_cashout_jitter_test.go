@@ -0,0 +1,72 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestMultiplierElapsed(t *testing.T) {
+	for _, mult := range []float64{1.01, 2, 5, 10, 100} {
+		if got := multiplierCurve(multiplierElapsed(mult)); math.Abs(got-mult) > 1e-9 {
+			t.Errorf("multiplierCurve(multiplierElapsed(%v)) = %v", mult, got)
+		}
+	}
+}
+
+func TestManager_AutoCashoutJitter(t *testing.T) {
+	m, client := newTestManager(t)
+	ctx := context.Background()
+	m.setTestRound("R-jitter", "BETTING")
+	m.currentRound.HashCommitment = "commitment"
+
+	maxJitter := multiplierCurve(multiplierElapsed(2)+0.5) - 2
+	jitters := make(map[float64]bool)
+	for i := 0; i < 5; i++ {
+		userID := fmt.Sprintf("user%d", i)
+		client.Set(ctx, REDIS_KEY_USER_BALANCE+userID, 100.0, 0)
+		resp, err := m.processBet(ctx, BetRequest{UserID: userID, Amount: amountOf(10), AutoCashout: 2, AutoCashoutJitterMs: MAX_AUTO_CASHOUT_JITTER_MS})
+		if err != nil || !resp.Success {
+			t.Fatalf("processBet() = %+v, %v", resp, err)
+		}
+
+		bet := m.loadActiveBets("R-jitter")[resp.BetID]
+		if bet.AutoCashoutJitter <= 0 || bet.AutoCashoutJitter > maxJitter {
+			t.Errorf("jitter = %v, want within (0, %v]", bet.AutoCashoutJitter, maxJitter)
+		}
+		if again := autoCashoutJitter("commitment", resp.BetID, 2, MAX_AUTO_CASHOUT_JITTER_MS); again != bet.AutoCashoutJitter {
+			t.Errorf("jitter of %s = %v, then %v; want it deterministic", resp.BetID, bet.AutoCashoutJitter, again)
+		}
+		jitters[bet.AutoCashoutJitter] = true
+	}
+	if len(jitters) < 2 {
+		t.Errorf("jitters = %v, want different effective targets for the same 2x target", jitters)
+	}
+
+	if triggered := m.triggeredAutoCashouts("R-jitter", 2); len(triggered) != 0 {
+		t.Errorf("triggered at 2x = %v, want none before the jitter", triggered)
+	}
+	if triggered := m.triggeredAutoCashouts("R-jitter", 2+maxJitter); len(triggered) != 5 {
+		t.Errorf("triggered at %vx = %v, want all 5", 2+maxJitter, triggered)
+	}
+
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"plain", 100.0, 0)
+	plain := placeTestBet(t, m, "plain", 10, 3)
+	if bet := m.loadActiveBets("R-jitter")[plain]; bet.AutoCashoutJitter != 0 {
+		t.Errorf("jitter without auto_cashout_jitter_ms = %v, want 0", bet.AutoCashoutJitter)
+	}
+
+	resp, _ := m.processBet(ctx, BetRequest{UserID: "plain", Amount: amountOf(10), AutoCashout: 2, AutoCashoutJitterMs: MAX_AUTO_CASHOUT_JITTER_MS + 1})
+	if resp.Success {
+		t.Error("bet with a jitter above the maximum was accepted")
+	}
+
+	dist, err := GetAutoCashoutDistribution(ctx, client)
+	if err != nil {
+		t.Fatalf("GetAutoCashoutDistribution() error: %v", err)
+	}
+	if dist.Total != 6 || len(dist.Targets) != 2 || dist.Targets[0].Target != 2 || dist.Targets[0].Count != 5 || dist.RoundTargetSharePct != 100 {
+		t.Errorf("distribution = %+v, want five bets at 2x and one at 3x", dist)
+	}
+}
@@ -0,0 +1,140 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	CREDIT_FLUSH_INTERVAL = 50 * time.Millisecond
+
+	// CREDIT_MAX_FLUSH_ATTEMPTS is how many flushes a queued credit may fail
+	// before it is moved to the dead-letter list
+	CREDIT_MAX_FLUSH_ATTEMPTS = 5
+
+	REDIS_KEY_CREDITS_DEAD_LETTER = "balance:credits:dead_letter" // Credits that could not be applied, for manual repair
+)
+
+// DeadLetterCredit is a queued credit given up on after CREDIT_MAX_FLUSH_ATTEMPTS
+type DeadLetterCredit struct {
+	UserID   string    `json:"user_id"`
+	Amount   float64   `json:"amount"`
+	Attempts int       `json:"attempts"`
+	FailedAt time.Time `json:"failed_at"`
+}
+
+// BatchCreditBalances credits several users in a single Redis round trip.
+// Credits that fail inside the pipeline are retried one by one.
+func (m *Manager) BatchCreditBalances(ctx context.Context, credits map[string]float64) error {
+	failed := m.batchCredit(ctx, credits)
+	if len(failed) == 0 {
+		return nil
+	}
+
+	errs := make([]error, 0, len(failed))
+	for userID, amount := range failed {
+		errs = append(errs, fmt.Errorf("credit %.2f to %s failed", amount, userID))
+	}
+	return errors.Join(errs...)
+}
+
+// batchCredit applies credits and returns the ones that still failed after a retry
+func (m *Manager) batchCredit(ctx context.Context, credits map[string]float64) map[string]float64 {
+	if len(credits) == 0 {
+		return nil
+	}
+
+	pipe := m.redisClient.Pipeline()
+	for userID, amount := range credits {
+		pipe.IncrByFloat(ctx, REDIS_KEY_USER_BALANCE+userID, amount)
+	}
+	cmds, _ := pipe.Exec(ctx)
+
+	// Exec reports the first error; find and retry every credit that did not apply
+	failed := make(map[string]float64)
+	for userID, amount := range credits {
+		failed[userID] = amount
+	}
+	for _, cmd := range cmds {
+		if cmd.Err() == nil {
+			userID := cmd.Args()[1].(string)[len(REDIS_KEY_USER_BALANCE):]
+			m.notifyCredit(userID, cmd.(*redis.FloatCmd).Val(), failed[userID])
+			delete(failed, userID)
+		}
+	}
+
+	for userID, amount := range failed {
+		balance, err := m.redisClient.IncrByFloat(ctx, REDIS_KEY_USER_BALANCE+userID, amount).Result()
+		if err != nil {
+			log.Printf("[BALANCE] Failed to credit %.2f to %s: %v", amount, userID, err)
+			continue
+		}
+		m.notifyCredit(userID, balance, amount)
+		delete(failed, userID)
+	}
+
+	return failed
+}
+
+// notifyCredit reports a batched auto-cashout credit to the user
+func (m *Manager) notifyCredit(userID string, balance, amount float64) {
+	delta, _ := NewAmount(amount)
+	m.hub.NotifyBalance(userID, balance, delta, BalanceReasonCashout)
+}
+
+// queueCredit defers a balance credit until the next FlushPendingCredits
+func (m *Manager) queueCredit(userID string, amount float64) {
+	m.pendingMu.Lock()
+	defer m.pendingMu.Unlock()
+	m.pendingCredits[userID] += amount
+}
+
+// FlushPendingCredits writes all queued credits in one pipeline. Credits that
+// could not be applied stay queued for the next flush, until they have failed
+// CREDIT_MAX_FLUSH_ATTEMPTS times and are moved to the dead-letter list.
+func (m *Manager) FlushPendingCredits() {
+	m.pendingMu.Lock()
+	credits := m.pendingCredits
+	m.pendingCredits = make(map[string]float64)
+	m.pendingMu.Unlock()
+
+	failed := m.batchCredit(m.ctx, credits)
+
+	m.pendingMu.Lock()
+	var exhausted []DeadLetterCredit
+	for userID := range credits {
+		amount, stillFailing := failed[userID]
+		if !stillFailing {
+			delete(m.creditAttempts, userID)
+			continue
+		}
+		m.creditAttempts[userID]++
+		if attempts := m.creditAttempts[userID]; attempts >= CREDIT_MAX_FLUSH_ATTEMPTS {
+			delete(m.creditAttempts, userID)
+			exhausted = append(exhausted, DeadLetterCredit{UserID: userID, Amount: amount, Attempts: attempts, FailedAt: time.Now()})
+			continue
+		}
+		m.pendingCredits[userID] += amount
+	}
+	m.pendingMu.Unlock()
+
+	for _, credit := range exhausted {
+		m.deadLetterCredit(credit)
+	}
+}
+
+// deadLetterCredit stores a credit that could not be applied and logs it once
+func (m *Manager) deadLetterCredit(credit DeadLetterCredit) {
+	entry, _ := json.Marshal(credit)
+	if err := m.redisClient.RPush(m.ctx, REDIS_KEY_CREDITS_DEAD_LETTER, entry).Err(); err != nil {
+		log.Printf("[BALANCE] Gave up crediting %.2f to %s after %d attempts and could not store it: %v", credit.Amount, credit.UserID, credit.Attempts, err)
+		return
+	}
+	log.Printf("[BALANCE] Gave up crediting %.2f to %s after %d attempts; moved to %s", credit.Amount, credit.UserID, credit.Attempts, REDIS_KEY_CREDITS_DEAD_LETTER)
+}
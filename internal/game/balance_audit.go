@@ -0,0 +1,148 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Balances live in Redis while the game runs. The balance audit compares
+// them with the users table and the transactions ledger in PostgreSQL.
+const (
+	REDIS_KEY_BALANCE_AUDIT = "crash:audit:balance"
+	BALANCE_AUDIT_TOLERANCE = 0.01
+
+	AuditStatusOK            = "ok"
+	AuditStatusDiscrepancies = "discrepancies"
+
+	DiscrepancySourceDatabase = "database" // users.balance
+	DiscrepancySourceLedger   = "ledger"   // Net of the user's transactions
+)
+
+// BalanceDiscrepancy is a user whose Redis balance differs from a PostgreSQL
+// balance by more than BALANCE_AUDIT_TOLERANCE
+type BalanceDiscrepancy struct {
+	UserID       string  `json:"user_id"`
+	Source       string  `json:"source"`
+	RedisBalance float64 `json:"redis_balance"`
+	Balance      float64 `json:"balance"`
+	Difference   float64 `json:"difference"` // RedisBalance - Balance
+}
+
+type BalanceAuditSummary struct {
+	UsersChecked       int     `json:"users_checked"`
+	DiscrepanciesFound int     `json:"discrepancies_found"`
+	MaxDiscrepancy     float64 `json:"max_discrepancy"`
+	TotalDiscrepancy   float64 `json:"total_discrepancy"`
+}
+
+// BalanceAuditStatus is the outcome of the last audit run
+type BalanceAuditStatus struct {
+	LastAuditAt time.Time           `json:"last_audit_at"`
+	AuditStatus string              `json:"audit_status"`
+	Summary     BalanceAuditSummary `json:"summary"`
+}
+
+// FindBalanceDiscrepancies compares every user's Redis balance with their
+// database balance and ledger balance. A user missing from one of the maps
+// has a balance of 0 there. Discrepancies are sorted by user ID.
+func FindBalanceDiscrepancies(redisBalances, dbBalances, ledgerBalances map[string]float64) ([]BalanceDiscrepancy, BalanceAuditSummary) {
+	users := make(map[string]bool)
+	for _, balances := range []map[string]float64{redisBalances, dbBalances, ledgerBalances} {
+		for userID := range balances {
+			users[userID] = true
+		}
+	}
+	userIDs := make([]string, 0, len(users))
+	for userID := range users {
+		userIDs = append(userIDs, userID)
+	}
+	sort.Strings(userIDs)
+
+	var discrepancies []BalanceDiscrepancy
+	summary := BalanceAuditSummary{UsersChecked: len(userIDs)}
+	for _, userID := range userIDs {
+		redisBalance := redisBalances[userID]
+		for _, other := range []struct {
+			source  string
+			balance float64
+		}{
+			{DiscrepancySourceDatabase, dbBalances[userID]},
+			{DiscrepancySourceLedger, ledgerBalances[userID]},
+		} {
+			// Balances are stored in cents, so round away float noise first
+			difference := math.Round((redisBalance-other.balance)*100) / 100
+			if math.Abs(difference) <= BALANCE_AUDIT_TOLERANCE {
+				continue
+			}
+
+			discrepancies = append(discrepancies, BalanceDiscrepancy{
+				UserID:       userID,
+				Source:       other.source,
+				RedisBalance: redisBalance,
+				Balance:      other.balance,
+				Difference:   difference,
+			})
+			summary.MaxDiscrepancy = math.Max(summary.MaxDiscrepancy, math.Abs(difference))
+			summary.TotalDiscrepancy += math.Abs(difference)
+		}
+	}
+	summary.DiscrepanciesFound = len(discrepancies)
+	summary.TotalDiscrepancy = math.Round(summary.TotalDiscrepancy*100) / 100
+
+	return discrepancies, summary
+}
+
+// LoadRedisBalances reads every crash:balance:* key
+func LoadRedisBalances(ctx context.Context, client *redis.Client) (map[string]float64, error) {
+	balances := make(map[string]float64)
+	iter := client.Scan(ctx, 0, REDIS_KEY_USER_BALANCE+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		raw, err := client.Get(ctx, iter.Val()).Result()
+		if err == redis.Nil {
+			continue // Deleted since the scan
+		}
+		if err != nil {
+			return nil, fmt.Errorf("LoadRedisBalances: Get %s: %w", iter.Val(), err)
+		}
+		balance, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("LoadRedisBalances: %s holds %q: %w", iter.Val(), raw, err)
+		}
+		balances[iter.Val()[len(REDIS_KEY_USER_BALANCE):]] = balance
+	}
+	return balances, iter.Err()
+}
+
+// RecordBalanceAudit stores the outcome of an audit for the admin health check
+func RecordBalanceAudit(ctx context.Context, client *redis.Client, summary BalanceAuditSummary, at time.Time) error {
+	status := BalanceAuditStatus{LastAuditAt: at, AuditStatus: AuditStatusOK, Summary: summary}
+	if summary.DiscrepanciesFound > 0 {
+		status.AuditStatus = AuditStatusDiscrepancies
+	}
+	data, _ := json.Marshal(status)
+	return client.Set(ctx, REDIS_KEY_BALANCE_AUDIT, data, 0).Err()
+}
+
+// LastBalanceAudit returns the outcome of the last audit, or nil if no audit has run
+func LastBalanceAudit(ctx context.Context, client *redis.Client) (*BalanceAuditStatus, error) {
+	data, err := client.Get(ctx, REDIS_KEY_BALANCE_AUDIT).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var status BalanceAuditStatus
+	if err := json.Unmarshal(data, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
@@ -0,0 +1,90 @@
+package game
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFindBalanceDiscrepancies(t *testing.T) {
+	redisBalances := map[string]float64{
+		"match":     100,
+		"rounding":  100.01, // Exactly at the tolerance
+		"db-behind": 150.5,
+		"no-ledger": 20,
+		"redis":     7,
+	}
+	dbBalances := map[string]float64{
+		"match":     100,
+		"rounding":  100,
+		"db-behind": 100,
+		"no-ledger": 20,
+		"db-only":   3,
+	}
+	ledgerBalances := map[string]float64{
+		"match":     100.004,
+		"rounding":  100.00000001,
+		"db-behind": 150.5,
+		"redis":     7,
+	}
+
+	discrepancies, summary := FindBalanceDiscrepancies(redisBalances, dbBalances, ledgerBalances)
+
+	want := []BalanceDiscrepancy{
+		{UserID: "db-behind", Source: DiscrepancySourceDatabase, RedisBalance: 150.5, Balance: 100, Difference: 50.5},
+		{UserID: "db-only", Source: DiscrepancySourceDatabase, RedisBalance: 0, Balance: 3, Difference: -3},
+		{UserID: "no-ledger", Source: DiscrepancySourceLedger, RedisBalance: 20, Balance: 0, Difference: 20},
+		{UserID: "redis", Source: DiscrepancySourceDatabase, RedisBalance: 7, Balance: 0, Difference: 7},
+	}
+	if len(discrepancies) != len(want) {
+		t.Fatalf("FindBalanceDiscrepancies() = %+v, want %+v", discrepancies, want)
+	}
+	for i := range want {
+		if discrepancies[i] != want[i] {
+			t.Errorf("discrepancy %d = %+v, want %+v", i, discrepancies[i], want[i])
+		}
+	}
+
+	wantSummary := BalanceAuditSummary{UsersChecked: 6, DiscrepanciesFound: 4, MaxDiscrepancy: 50.5, TotalDiscrepancy: 80.5}
+	if summary != wantSummary {
+		t.Errorf("summary = %+v, want %+v", summary, wantSummary)
+	}
+}
+
+func TestFindBalanceDiscrepancies_None(t *testing.T) {
+	balances := map[string]float64{"user1": 10, "user2": 0.1 + 0.2}
+	ledger := map[string]float64{"user1": 10, "user2": 0.3}
+
+	discrepancies, summary := FindBalanceDiscrepancies(balances, balances, ledger)
+	if len(discrepancies) != 0 || summary != (BalanceAuditSummary{UsersChecked: 2}) {
+		t.Errorf("FindBalanceDiscrepancies() = %+v, %+v; want none", discrepancies, summary)
+	}
+}
+
+func TestBalanceAuditRedis(t *testing.T) {
+	m, client := newTestManager(t)
+	ctx := context.Background()
+
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"user1", 12.5, 0)
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"user2", "0", 0)
+	balances, err := LoadRedisBalances(ctx, m.redisClient)
+	if err != nil {
+		t.Fatalf("LoadRedisBalances() error: %v", err)
+	}
+	if len(balances) != 2 || balances["user1"] != 12.5 || balances["user2"] != 0 {
+		t.Errorf("LoadRedisBalances() = %v, want user1 and user2", balances)
+	}
+
+	if status, err := LastBalanceAudit(ctx, client); status != nil || err != nil {
+		t.Errorf("LastBalanceAudit() before any audit = %+v, %v; want nil", status, err)
+	}
+
+	at := time.Now().UTC().Truncate(time.Second)
+	if err := RecordBalanceAudit(ctx, client, BalanceAuditSummary{UsersChecked: 2}, at); err != nil {
+		t.Fatalf("RecordBalanceAudit() error: %v", err)
+	}
+	status, err := LastBalanceAudit(ctx, client)
+	if err != nil || status == nil || status.AuditStatus != AuditStatusOK || !status.LastAuditAt.Equal(at) || status.Summary.UsersChecked != 2 {
+		t.Errorf("LastBalanceAudit() = %+v, %v; want an ok audit at %v", status, err, at)
+	}
+}
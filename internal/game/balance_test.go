@@ -0,0 +1,127 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestManager_BatchCreditBalances(t *testing.T) {
+	m, client := newTestManager(t)
+	ctx := context.Background()
+
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"user1", 100.0, 0)
+
+	err := m.BatchCreditBalances(ctx, map[string]float64{
+		"user1": 25.5,
+		"user2": 10, // No balance yet
+	})
+	if err != nil {
+		t.Fatalf("BatchCreditBalances() error = %v", err)
+	}
+
+	for userID, want := range map[string]float64{"user1": 125.5, "user2": 10} {
+		if got, _ := client.Get(ctx, REDIS_KEY_USER_BALANCE+userID).Float64(); got != want {
+			t.Errorf("%s balance = %.2f, want %.2f", userID, got, want)
+		}
+	}
+}
+
+func TestManager_BatchCreditBalances_PartialFailure(t *testing.T) {
+	m, client := newTestManager(t)
+	ctx := context.Background()
+
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"good", 100.0, 0)
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"corrupt", "not-a-number", 0)
+
+	err := m.BatchCreditBalances(ctx, map[string]float64{"good": 50, "corrupt": 50})
+	if err == nil {
+		t.Fatal("expected an error for the corrupt balance")
+	}
+
+	if got, _ := client.Get(ctx, REDIS_KEY_USER_BALANCE+"good").Float64(); got != 150 {
+		t.Errorf("good balance = %.2f, want 150.00 (credited exactly once)", got)
+	}
+}
+
+func TestManager_FlushPendingCredits(t *testing.T) {
+	m, client := newTestManager(t)
+	ctx := context.Background()
+
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"corrupt", "not-a-number", 0)
+
+	m.queueCredit("user1", 10)
+	m.queueCredit("user1", 5)
+	m.queueCredit("corrupt", 20)
+	m.FlushPendingCredits()
+
+	if got, _ := client.Get(ctx, REDIS_KEY_USER_BALANCE+"user1").Float64(); got != 15 {
+		t.Errorf("user1 balance = %.2f, want 15.00", got)
+	}
+	if m.pendingCredits["corrupt"] != 20 {
+		t.Errorf("failed credit should stay queued, pending = %v", m.pendingCredits)
+	}
+	if _, ok := m.pendingCredits["user1"]; ok {
+		t.Error("applied credit should be removed from the queue")
+	}
+
+	for i := 1; i < CREDIT_MAX_FLUSH_ATTEMPTS; i++ {
+		m.FlushPendingCredits()
+	}
+	if _, ok := m.pendingCredits["corrupt"]; ok {
+		t.Errorf("exhausted credit should leave the queue, pending = %v", m.pendingCredits)
+	}
+	m.FlushPendingCredits()
+
+	entries, _ := client.LRange(ctx, REDIS_KEY_CREDITS_DEAD_LETTER, 0, -1).Result()
+	if len(entries) != 1 {
+		t.Fatalf("dead-letter list = %v, want the exhausted credit once", entries)
+	}
+	var credit DeadLetterCredit
+	if err := json.Unmarshal([]byte(entries[0]), &credit); err != nil || credit.UserID != "corrupt" ||
+		credit.Amount != 20 || credit.Attempts != CREDIT_MAX_FLUSH_ATTEMPTS {
+		t.Errorf("dead-letter credit = %+v, %v; want 20.00 to corrupt after %d attempts", credit, err, CREDIT_MAX_FLUSH_ATTEMPTS)
+	}
+}
+
+func batchCredits(n int) map[string]float64 {
+	credits := make(map[string]float64, n)
+	for i := 0; i < n; i++ {
+		credits[fmt.Sprintf("user%d", i)] = 12.5
+	}
+	return credits
+}
+
+func BenchmarkCredit_Individual(b *testing.B) {
+	_, client := newTestManager(b)
+	ctx := context.Background()
+	credits := batchCredits(500)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var wg sync.WaitGroup
+		for userID, amount := range credits {
+			wg.Add(1)
+			go func(userID string, amount float64) {
+				defer wg.Done()
+				client.IncrByFloat(ctx, REDIS_KEY_USER_BALANCE+userID, amount)
+			}(userID, amount)
+		}
+		wg.Wait()
+	}
+}
+
+func BenchmarkCredit_Pipeline(b *testing.B) {
+	m, _ := newTestManager(b)
+	ctx := context.Background()
+	credits := batchCredits(500)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := m.BatchCreditBalances(ctx, credits); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+// gameBenchmarks are the hot-path benchmarks of every game engine. Run them
+// with make bench; the comparison of auto-cashout approaches lives with the
+// other manager benchmarks as BenchmarkAutoCashout_*.
+var gameBenchmarks = map[string]func(*testing.B){
+	"MinesPlaceBet":           BenchmarkMinesPlaceBet,
+	"DiceRoll":                BenchmarkDiceRoll,
+	"PlinkoDropBall":          BenchmarkPlinkoDropBall,
+	"CrashMultiplierCalc":     BenchmarkCrashMultiplierCalc,
+	"HashChain10k":            BenchmarkHashChain10k,
+	"HubBroadcast1000Clients": BenchmarkHubBroadcast1000Clients,
+	"ProcessBet":              BenchmarkProcessBet,
+}
+
+// BENCH_BALANCE covers every bet a benchmark places
+const BENCH_BALANCE = 1e12
+
+// discardConn is a connection that drops every message, so fan-out
+// benchmarks don't keep what they send
+type discardConn struct{}
+
+func (discardConn) WriteMessage(messageType int, data []byte) error { return nil }
+
+func (discardConn) SetWriteDeadline(t time.Time) error { return nil }
+
+func (discardConn) Close() error { return nil }
+
+func BenchmarkMinesPlaceBet(b *testing.B) {
+	client := newTestRedis(b)
+	client.Set(context.Background(), REDIS_KEY_USER_BALANCE+"user1", BENCH_BALANCE, 0)
+	engine := NewMinesEngine(client, nil)
+	engine.sessions.max = math.MaxInt64 // Every bet leaves an active game behind
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		engine.PlaceBet(ctx, MinesBetRequest{UserID: "user1", Amount: 1, MineCount: 3})
+	}
+}
+
+func BenchmarkDiceRoll(b *testing.B) {
+	client := newTestRedis(b)
+	client.Set(context.Background(), REDIS_KEY_USER_BALANCE+"user1", BENCH_BALANCE, 0)
+	engine := NewDiceEngine(client, nil)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		engine.PlaceBet(ctx, DiceRollRequest{UserID: "user1", Amount: 1, Target: 50, IsOver: true})
+	}
+}
+
+func BenchmarkPlinkoDropBall(b *testing.B) {
+	client := newTestRedis(b)
+	client.Set(context.Background(), REDIS_KEY_USER_BALANCE+"user1", BENCH_BALANCE, 0)
+	engine := NewPlinkoEngine(client, nil)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		engine.PlaceBet(ctx, PlinkoDropRequest{UserID: "user1", Amount: 1, Risk: PlinkoRiskMedium, Rows: 16})
+	}
+}
+
+func BenchmarkCrashMultiplierCalc(b *testing.B) {
+	elapsed := []float64{0, 0.1, 1, 5, 15, 30, 60}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		calculateMultiplier(elapsed[i%len(elapsed)])
+	}
+}
+
+func BenchmarkHashChain10k(b *testing.B) {
+	serverSeed := "benchmark_server_seed"
+	clientSeed := "benchmark_client_seed"
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for nonce := 1; nonce <= 10000; nonce++ {
+			HashAndMapToMultiplier(serverSeed, clientSeed, nonce)
+		}
+	}
+}
+
+// BenchmarkHubBroadcast1000Clients marshals a message once and hands it to
+// 1000 clients, as the hub does with every round update
+func BenchmarkHubBroadcast1000Clients(b *testing.B) {
+	hub := NewHub()
+	for i := 0; i < 1000; i++ {
+		conn := &discardConn{}
+		client := &Client{conn: conn, userID: fmt.Sprintf("user%d", i), gameType: GameTypeAviator}
+		hub.clients[client] = true
+		hub.connToClient[conn] = client
+	}
+	message := map[string]interface{}{
+		"type": "multiplier_update",
+		"data": map[string]interface{}{"multiplier": 2.5, "round_id": "R-bench"},
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		hub.BroadcastToGameType(GameTypeAviator, message)
+	}
+}
+
+// BenchmarkProcessBet places bets directly, without the bet queue in front of
+// processBet
+func BenchmarkProcessBet(b *testing.B) {
+	m, client := newTestManager(b)
+	go m.hub.Run()
+	m.setTestRound("R-bench", "BETTING")
+	m.maxBetsPerRound = math.MaxInt
+	client.Set(context.Background(), REDIS_KEY_USER_BALANCE+"user1", BENCH_BALANCE, 0)
+	ctx := context.Background()
+	req := BetRequest{UserID: "user1", Amount: amountOf(1), AutoCashout: 2}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.processBet(ctx, req)
+	}
+}
+
+// TestBenchmarkOutputFormat runs every benchmark with zero iterations, so a
+// benchmark whose setup panics or fails is caught by go test, not only by
+// make bench
+func TestBenchmarkOutputFormat(t *testing.T) {
+	for name, bench := range gameBenchmarks {
+		t.Run(name, func(t *testing.T) {
+			result := testing.Benchmark(func(b *testing.B) {
+				n := b.N
+				b.N = 0
+				bench(b)
+				b.N = n
+			})
+			if result.N == 0 {
+				t.Errorf("Benchmark%s failed on a zero-iteration run", name)
+			}
+		})
+	}
+}
@@ -0,0 +1,342 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	REDIS_KEY_BET_SLIP = "betslip:"
+	BET_SLIP_TTL       = 30 * time.Second
+	BET_SLIP_MAX_BETS  = 10
+)
+
+var ErrBetSlipNotFound = errors.New("bet slip not found or expired")
+
+// BetSlipBet is one staged bet. Params holds the game-specific fields of the
+// engine's request, e.g. {"target": 50, "is_over": true} for Dice.
+type BetSlipBet struct {
+	GameType GameType        `json:"game_type"`
+	Amount   Amount          `json:"amount"`
+	Params   json.RawMessage `json:"params,omitempty"`
+}
+
+type BetSlipRequest struct {
+	UserID string       `json:"user_id"`
+	Bets   []BetSlipBet `json:"bets"`
+}
+
+type BetSlipValidation struct {
+	Index    int      `json:"index"`
+	GameType GameType `json:"game_type"`
+	Valid    bool     `json:"valid"`
+	Message  string   `json:"message,omitempty"`
+}
+
+type BetSlipResponse struct {
+	Success           bool                `json:"success"`
+	Message           string              `json:"message"`
+	SlipID            string              `json:"slip_id,omitempty"`
+	TotalAmount       Amount              `json:"total_amount"`
+	ValidationResults []BetSlipValidation `json:"validation_results"`
+	ExpiresInSeconds  int                 `json:"expires_in_seconds,omitempty"`
+}
+
+type BetSlipResult struct {
+	GameType GameType    `json:"game_type"`
+	Success  bool        `json:"success"`
+	Response interface{} `json:"response"`
+}
+
+type BetSlipConfirmResponse struct {
+	Success  bool            `json:"success"`
+	Message  string          `json:"message"`
+	Refunded Amount          `json:"refunded,omitempty"`
+	Results  []BetSlipResult `json:"results"`
+}
+
+// betSlip is the staged slip stored in Redis until it is confirmed or expires
+type betSlip struct {
+	SlipID      string       `json:"slip_id"`
+	UserID      string       `json:"user_id"`
+	Bets        []BetSlipBet `json:"bets"`
+	TotalAmount Amount       `json:"total_amount"`
+	CreatedAt   time.Time    `json:"created_at"`
+}
+
+// BetSlipService validates staged bets and places them together on confirmation
+type BetSlipService struct {
+	redisClient *redis.Client
+	manager     *Manager
+	factory     *GameFactory
+}
+
+func NewBetSlipService(redisClient *redis.Client, manager *Manager, factory *GameFactory) *BetSlipService {
+	return &BetSlipService{
+		redisClient: redisClient,
+		manager:     manager,
+		factory:     factory,
+	}
+}
+
+// Create validates every bet and the user's balance without placing anything.
+// The slip is stored only when all bets are valid.
+func (s *BetSlipService) Create(ctx context.Context, req BetSlipRequest) (BetSlipResponse, error) {
+	if len(req.Bets) == 0 || len(req.Bets) > BET_SLIP_MAX_BETS {
+		return BetSlipResponse{
+			Success: false,
+			Message: fmt.Sprintf("A bet slip must contain between 1 and %d bets", BET_SLIP_MAX_BETS),
+		}, nil
+	}
+
+	resp := BetSlipResponse{ValidationResults: make([]BetSlipValidation, 0, len(req.Bets))}
+	allValid := true
+	for i, bet := range req.Bets {
+		_, message := s.buildRequest(ctx, req.UserID, bet)
+		resp.ValidationResults = append(resp.ValidationResults, BetSlipValidation{
+			Index:    i,
+			GameType: bet.GameType,
+			Valid:    message == "",
+			Message:  message,
+		})
+		if message != "" {
+			allValid = false
+		}
+		resp.TotalAmount += bet.Amount
+	}
+
+	if !allValid {
+		resp.Message = "One or more bets are invalid"
+		return resp, nil
+	}
+
+	balance, err := s.redisClient.Get(ctx, REDIS_KEY_USER_BALANCE+req.UserID).Float64()
+	if err != nil || balance < resp.TotalAmount.Float64() {
+		resp.Message = "Insufficient balance"
+		return resp, nil
+	}
+
+	slip := betSlip{
+		SlipID:      fmt.Sprintf("SLIP-%s-%d", req.UserID, time.Now().UnixNano()),
+		UserID:      req.UserID,
+		Bets:        req.Bets,
+		TotalAmount: resp.TotalAmount,
+		CreatedAt:   time.Now(),
+	}
+	slipJSON, _ := json.Marshal(slip)
+	if err := s.redisClient.Set(ctx, REDIS_KEY_BET_SLIP+slip.SlipID, slipJSON, BET_SLIP_TTL).Err(); err != nil {
+		return BetSlipResponse{}, err
+	}
+
+	resp.Success = true
+	resp.Message = "Bet slip created"
+	resp.SlipID = slip.SlipID
+	resp.ExpiresInSeconds = int(BET_SLIP_TTL.Seconds())
+	return resp, nil
+}
+
+// Confirm reserves the slip total and places each bet in order. If any bet
+// fails, every placed bet is reversed and the user's balance is restored,
+// except a Mines game the player has already settled.
+func (s *BetSlipService) Confirm(ctx context.Context, slipID string) (BetSlipConfirmResponse, error) {
+	// GETDEL claims the slip so it can only be confirmed once
+	slipJSON, err := s.redisClient.GetDel(ctx, REDIS_KEY_BET_SLIP+slipID).Result()
+	if err == redis.Nil {
+		return BetSlipConfirmResponse{}, ErrBetSlipNotFound
+	}
+	if err != nil {
+		return BetSlipConfirmResponse{}, err
+	}
+
+	var slip betSlip
+	if err := json.Unmarshal([]byte(slipJSON), &slip); err != nil {
+		return BetSlipConfirmResponse{}, err
+	}
+
+	// Reserve the whole slip up front
+	balanceKey := REDIS_KEY_USER_BALANCE + slip.UserID
+	newBalance, err := s.redisClient.IncrByFloat(ctx, balanceKey, -slip.TotalAmount.Float64()).Result()
+	if err != nil || newBalance < 0 {
+		s.redisClient.IncrByFloat(ctx, balanceKey, slip.TotalAmount.Float64()) // Rollback
+		return BetSlipConfirmResponse{
+			Success: false,
+			Message: "Insufficient balance",
+		}, nil
+	}
+
+	resp := BetSlipConfirmResponse{Results: make([]BetSlipResult, 0, len(slip.Bets))}
+	reserved := slip.TotalAmount
+	var undos []func() Amount
+	failed := false
+
+	for _, bet := range slip.Bets {
+		req, message := s.buildRequest(ctx, slip.UserID, bet)
+		if message != "" {
+			resp.Results = append(resp.Results, BetSlipResult{GameType: bet.GameType, Success: false, Response: message})
+			failed = true
+			break
+		}
+
+		// Release this bet's reservation so the engine can deduct it as usual
+		s.redisClient.IncrByFloat(ctx, balanceKey, bet.Amount.Float64())
+		reserved -= bet.Amount
+
+		betResp, success, undo := s.placeBet(ctx, bet, req)
+		resp.Results = append(resp.Results, BetSlipResult{GameType: bet.GameType, Success: success, Response: betResp})
+		if !success {
+			failed = true
+			break
+		}
+		undos = append(undos, undo)
+	}
+
+	if !failed {
+		resp.Success = true
+		resp.Message = "All bets placed"
+		return resp, nil
+	}
+
+	// Reverse the bets already placed and return whatever is still reserved
+	refund := reserved
+	for _, undo := range undos {
+		refund += undo()
+	}
+	if refund != 0 {
+		if err := s.redisClient.IncrByFloat(ctx, balanceKey, refund.Float64()).Err(); err != nil {
+			log.Printf("[BETSLIP] Failed to refund %s to %s for slip %s: %v", refund, slip.UserID, slipID, err)
+		}
+	}
+
+	log.Printf("[BETSLIP] Slip %s for %s failed, refunded %s", slipID, slip.UserID, refund)
+
+	resp.Message = "A bet failed, all bets were refunded"
+	resp.Refunded = refund
+	return resp, nil
+}
+
+// buildRequest converts a staged bet into its engine's request type and
+// validates it. It returns a message for the player, or "" if the bet is valid.
+func (s *BetSlipService) buildRequest(ctx context.Context, userID string, bet BetSlipBet) (interface{}, string) {
+	var req interface{}
+	var message string
+
+	switch bet.GameType {
+	case GameTypeAviator:
+		var betReq BetRequest
+		if err := decodeBetSlipParams(bet.Params, &betReq); err != nil {
+			return nil, "Invalid params"
+		}
+		betReq.UserID, betReq.Amount = userID, bet.Amount
+		req, message = betReq, s.manager.ValidateBet(betReq)
+
+	case GameTypeDice:
+		var rollReq DiceRollRequest
+		if err := decodeBetSlipParams(bet.Params, &rollReq); err != nil {
+			return nil, "Invalid params"
+		}
+		rollReq.UserID, rollReq.Amount = userID, bet.Amount.Float64()
+		req, message = rollReq, validateDiceRoll(rollReq)
+
+	case GameTypePlinko:
+		var dropReq PlinkoDropRequest
+		if err := decodeBetSlipParams(bet.Params, &dropReq); err != nil {
+			return nil, "Invalid params"
+		}
+		dropReq.UserID, dropReq.Amount = userID, bet.Amount.Float64()
+		req, message = dropReq, validatePlinkoDrop(dropReq)
+		if message == "" && dropReq.Mode == PlinkoModeDeferred {
+			message = "Deferred drops cannot be placed in a bet slip"
+		}
+
+	case GameTypeMines:
+		var minesReq MinesBetRequest
+		if err := decodeBetSlipParams(bet.Params, &minesReq); err != nil {
+			return nil, "Invalid params"
+		}
+		minesReq.UserID, minesReq.Amount = userID, bet.Amount.Float64()
+		message = validateMinesBet(&minesReq)
+		req = minesReq
+
+	default:
+		return nil, "Unknown game type"
+	}
+
+	if message != "" {
+		return nil, message
+	}
+
+	if bet.GameType != GameTypeAviator {
+		if _, exists := s.factory.GetEngine(bet.GameType); !exists {
+			return nil, "Unknown game type"
+		}
+		if s.factory.IsDisabled(ctx, bet.GameType) {
+			return nil, "Game is under maintenance"
+		}
+	}
+
+	return req, ""
+}
+
+// placeBet dispatches a request to its engine. undo reverses a successful bet
+// and returns the amount to credit back to the user.
+func (s *BetSlipService) placeBet(ctx context.Context, bet BetSlipBet, req interface{}) (interface{}, bool, func() Amount) {
+	if bet.GameType == GameTypeAviator {
+		betResp, err := s.manager.PlaceBet(req.(BetRequest))
+		if err != nil {
+			log.Printf("[BETSLIP] Aviator bet failed: %v", err)
+			return "Transaction failed", false, nil
+		}
+		return betResp, betResp.Success, func() Amount {
+			if !s.manager.cancelBet(betResp.BetID) {
+				log.Printf("[BETSLIP] Aviator bet %s could not be cancelled", betResp.BetID)
+				return 0
+			}
+			return bet.Amount
+		}
+	}
+
+	engine, _ := s.factory.GetEngine(bet.GameType)
+	resp, err := engine.PlaceBet(ctx, req)
+	if err != nil {
+		return err.Error(), false, nil
+	}
+
+	switch r := resp.(type) {
+	case DiceRollResponse:
+		return r, r.Success, func() Amount { return bet.Amount - r.Payout }
+	case PlinkoDropResponse:
+		if r.ServerSeedHash != "" {
+			// A deferred drop is refunded only if it can no longer be revealed
+			return r, r.Success, func() Amount {
+				deleted, _ := s.redisClient.Del(ctx, REDIS_KEY_PLINKO_PENDING+r.GameID).Result()
+				s.redisClient.ZRem(ctx, REDIS_KEY_PLINKO_PENDING_DUE, r.GameID)
+				if deleted == 0 {
+					return 0
+				}
+				return bet.Amount
+			}
+		}
+		return r, r.Success, func() Amount { return bet.Amount - r.Payout }
+	case MinesBetResponse:
+		// A game the player has already settled keeps its result
+		return r, r.Success, func() Amount {
+			amount, _ := engine.(*MinesEngine).voidGame(ctx, r.GameID)
+			return amount
+		}
+	}
+
+	return resp, false, nil
+}
+
+func decodeBetSlipParams(params json.RawMessage, req interface{}) error {
+	if len(params) == 0 {
+		return nil
+	}
+	return json.Unmarshal(params, req)
+}
@@ -0,0 +1,255 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestBetSlips(t *testing.T) (*BetSlipService, *Manager, *redis.Client) {
+	t.Helper()
+
+	m, client := newTestManager(t)
+	factory := NewGameFactory(client, nil)
+	factory.RegisterEngine(NewMinesEngine(client, nil))
+	factory.RegisterEngine(NewDiceEngine(client, nil))
+	factory.RegisterEngine(NewPlinkoEngine(client, nil))
+
+	return NewBetSlipService(client, m, factory), m, client
+}
+
+func slipBet(gameType GameType, amount float64, params string) BetSlipBet {
+	return BetSlipBet{GameType: gameType, Amount: amountOf(amount), Params: json.RawMessage(params)}
+}
+
+func TestBetSlip_Create(t *testing.T) {
+	slips, _, client := newTestBetSlips(t)
+	ctx := context.Background()
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"user1", 100.0, 0)
+
+	t.Run("invalid bet is reported and nothing is stored", func(t *testing.T) {
+		resp, err := slips.Create(ctx, BetSlipRequest{UserID: "user1", Bets: []BetSlipBet{
+			slipBet(GameTypeDice, 10, `{"target": 50, "is_over": true}`),
+			slipBet(GameTypeDice, 10, `{"target": 99.5, "is_over": true}`),
+			slipBet(GameType("roulette"), 10, ``),
+		}})
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		if resp.Success || resp.SlipID != "" {
+			t.Fatalf("expected slip to be rejected, got %+v", resp)
+		}
+
+		valid := []bool{true, false, false}
+		for i, result := range resp.ValidationResults {
+			if result.Valid != valid[i] {
+				t.Errorf("bet %d valid = %v, want %v (%s)", i, result.Valid, valid[i], result.Message)
+			}
+		}
+		if keys := client.Keys(ctx, REDIS_KEY_BET_SLIP+"*").Val(); len(keys) != 0 {
+			t.Errorf("expected no stored slips, got %v", keys)
+		}
+	})
+
+	t.Run("deferred plinko drop is rejected", func(t *testing.T) {
+		resp, _ := slips.Create(ctx, BetSlipRequest{UserID: "user1", Bets: []BetSlipBet{
+			slipBet(GameTypePlinko, 10, `{"risk": "high", "rows": 16, "mode": "deferred"}`),
+		}})
+		if resp.Success || len(resp.ValidationResults) != 1 || resp.ValidationResults[0].Valid {
+			t.Errorf("expected the deferred drop to be rejected, got %+v", resp)
+		}
+	})
+
+	t.Run("total must be covered by balance", func(t *testing.T) {
+		resp, _ := slips.Create(ctx, BetSlipRequest{UserID: "user1", Bets: []BetSlipBet{
+			slipBet(GameTypePlinko, 60, `{"risk": "low", "rows": 8}`),
+			slipBet(GameTypePlinko, 60, `{"risk": "low", "rows": 8}`),
+		}})
+		if resp.Success || resp.Message != "Insufficient balance" {
+			t.Errorf("expected insufficient balance, got %+v", resp)
+		}
+	})
+
+	t.Run("valid slip is stored with a TTL", func(t *testing.T) {
+		resp, _ := slips.Create(ctx, BetSlipRequest{UserID: "user1", Bets: []BetSlipBet{
+			slipBet(GameTypeDice, 10, `{"target": 50, "is_over": true}`),
+			slipBet(GameTypeMines, 10, `{"mine_count": 3}`),
+		}})
+		if !resp.Success || resp.TotalAmount != amountOf(20) || resp.ExpiresInSeconds != 30 {
+			t.Fatalf("unexpected response %+v", resp)
+		}
+
+		ttl := client.TTL(ctx, REDIS_KEY_BET_SLIP+resp.SlipID).Val()
+		if ttl <= 0 || ttl > BET_SLIP_TTL {
+			t.Errorf("expected TTL <= %v, got %v", BET_SLIP_TTL, ttl)
+		}
+		if balance, _ := client.Get(ctx, REDIS_KEY_USER_BALANCE+"user1").Float64(); balance != 100 {
+			t.Errorf("creating a slip should not touch the balance, got %.2f", balance)
+		}
+	})
+}
+
+func TestBetSlip_Confirm(t *testing.T) {
+	slips, _, client := newTestBetSlips(t)
+	ctx := context.Background()
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"user1", 100.0, 0)
+
+	created, _ := slips.Create(ctx, BetSlipRequest{UserID: "user1", Bets: []BetSlipBet{
+		slipBet(GameTypeDice, 10, `{"target": 50, "is_over": true}`),
+		slipBet(GameTypePlinko, 10, `{"risk": "medium", "rows": 16}`),
+	}})
+
+	resp, err := slips.Confirm(ctx, created.SlipID)
+	if err != nil {
+		t.Fatalf("Confirm() error = %v", err)
+	}
+	if !resp.Success || len(resp.Results) != 2 {
+		t.Fatalf("expected both bets to be placed, got %+v", resp)
+	}
+
+	payouts := resp.Results[0].Response.(DiceRollResponse).Payout + resp.Results[1].Response.(PlinkoDropResponse).Payout
+	want := 80 + payouts.Float64()
+	if balance, _ := client.Get(ctx, REDIS_KEY_USER_BALANCE+"user1").Float64(); math.Abs(balance-want) > 1e-9 {
+		t.Errorf("balance = %.2f, want %.2f", balance, want)
+	}
+
+	if _, err := slips.Confirm(ctx, created.SlipID); !errors.Is(err, ErrBetSlipNotFound) {
+		t.Errorf("second Confirm() error = %v, want %v", err, ErrBetSlipNotFound)
+	}
+}
+
+func TestBetSlip_PartialFailureRefundsEverything(t *testing.T) {
+	slips, m, client := newTestBetSlips(t)
+	ctx := context.Background()
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"user1", 100.0, 0)
+
+	m.setTestRound("R-slip", "BETTING")
+	go func() {
+		for req := range m.betChannel {
+			resp, err := m.processBet(ctx, req)
+			req.ResponseChan <- BetResult{Response: resp, Err: err}
+		}
+	}()
+
+	created, _ := slips.Create(ctx, BetSlipRequest{UserID: "user1", Bets: []BetSlipBet{
+		slipBet(GameTypeAviator, 10, `{"auto_cashout": 2}`),
+		slipBet(GameTypeDice, 10, `{"target": 50, "is_over": false}`),
+		slipBet(GameTypePlinko, 10, `{"risk": "high", "rows": 16}`),
+		slipBet(GameTypeMines, 10, `{"mine_count": 3}`),
+	}})
+	if !created.Success {
+		t.Fatalf("Create() failed: %+v", created)
+	}
+
+	// Mines goes into maintenance between staging and confirming
+	client.Set(ctx, REDIS_KEY_GAME_MAINTENANCE+string(GameTypeMines), "1", 0)
+
+	resp, err := slips.Confirm(ctx, created.SlipID)
+	if err != nil {
+		t.Fatalf("Confirm() error = %v", err)
+	}
+	if resp.Success {
+		t.Fatal("expected confirmation to fail")
+	}
+	if len(resp.Results) != 4 || resp.Results[3].Success {
+		t.Fatalf("expected the mines bet to fail last, got %+v", resp.Results)
+	}
+	for i := 0; i < 3; i++ {
+		if !resp.Results[i].Success {
+			t.Errorf("bet %d should have been placed before the failure: %+v", i, resp.Results[i])
+		}
+	}
+
+	if balance, _ := client.Get(ctx, REDIS_KEY_USER_BALANCE+"user1").Float64(); math.Abs(balance-100) > 1e-9 {
+		t.Errorf("balance = %.2f, want a full refund to 100.00", balance)
+	}
+	if count := client.HLen(ctx, REDIS_KEY_ACTIVE_BETS+"R-slip").Val(); count != 0 {
+		t.Errorf("aviator bet should be cancelled, %d active bets remain", count)
+	}
+	if count := client.ZCard(ctx, REDIS_KEY_AUTO_CASHOUT+"R-slip").Val(); count != 0 {
+		t.Errorf("aviator auto-cashout should be removed, %d entries remain", count)
+	}
+}
+
+func TestBetSlip_DeferredDropUndoCannotBeRevealed(t *testing.T) {
+	slips, _, client := newTestBetSlips(t)
+	ctx := context.Background()
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"user1", 100.0, 0)
+
+	bet := slipBet(GameTypePlinko, 10, ``)
+	req := PlinkoDropRequest{UserID: "user1", Amount: 10, Risk: PlinkoRiskHigh, Rows: 16, Mode: PlinkoModeDeferred}
+	resp, ok, undo := slips.placeBet(ctx, bet, req)
+	if !ok {
+		t.Fatalf("deferred drop failed: %+v", resp)
+	}
+	gameID := resp.(PlinkoDropResponse).GameID
+
+	// Rolling the slip back refunds the stake and withdraws the drop
+	if refund := undo(); refund != amountOf(10) {
+		t.Errorf("undo() = %s, want the 10.00 stake", refund)
+	}
+	engine, _ := slips.factory.GetEngine(GameTypePlinko)
+	if _, err := engine.ProcessAction(ctx, "reveal", PlinkoRevealRequest{GameID: gameID}); err != ErrGameNotFound {
+		t.Errorf("reveal after rollback: %v, want ErrGameNotFound", err)
+	}
+	if refund := undo(); refund != 0 {
+		t.Errorf("second undo() = %s, want nothing refunded", refund)
+	}
+}
+
+func TestBetSlip_MinesUndoReleasesGame(t *testing.T) {
+	slips, _, client := newTestBetSlips(t)
+	ctx := context.Background()
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"user1", 100.0, 0)
+	activeBefore, _ := client.Get(ctx, REDIS_KEY_MINES_ACTIVE_COUNT).Int()
+
+	bet := slipBet(GameTypeMines, 10, ``)
+	req := MinesBetRequest{UserID: "user1", Amount: 10, MineCount: 3}
+	resp, ok, undo := slips.placeBet(ctx, bet, req)
+	if !ok {
+		t.Fatalf("mines bet failed: %+v", resp)
+	}
+	gameID := resp.(MinesBetResponse).GameID
+
+	if refund := undo(); refund != amountOf(10) {
+		t.Errorf("undo() = %s, want the 10.00 stake", refund)
+	}
+	if active, _ := client.Get(ctx, REDIS_KEY_MINES_ACTIVE_COUNT).Int(); active != activeBefore {
+		t.Errorf("%s = %d after undo, want %d", REDIS_KEY_MINES_ACTIVE_COUNT, active, activeBefore)
+	}
+	if wagered, _ := client.Get(ctx, REDIS_KEY_USER_WAGERED+"user1").Float64(); wagered != 0 {
+		t.Errorf("wagered = %.2f after undo, want 0", wagered)
+	}
+	if client.Exists(ctx, REDIS_KEY_MINES_GAME+gameID).Val() != 0 || client.ZCard(ctx, REDIS_KEY_MINES_HISTORY+"user1").Val() != 0 {
+		t.Error("undone game should leave no state or history")
+	}
+	if refund := undo(); refund != 0 {
+		t.Errorf("second undo() = %s, want nothing refunded", refund)
+	}
+
+	t.Run("settled game is not undone", func(t *testing.T) {
+		resp, ok, undo := slips.placeBet(ctx, bet, req)
+		if !ok {
+			t.Fatalf("mines bet failed: %+v", resp)
+		}
+		gameID := resp.(MinesBetResponse).GameID
+
+		engine, _ := slips.factory.GetEngine(GameTypeMines)
+		mines := engine.(*MinesEngine)
+		mines.updateGameStateAtomic(ctx, REDIS_KEY_MINES_GAME+gameID, func(gameState *MinesGameState) error {
+			gameState.Status = "BUSTED"
+			return nil
+		}, nil)
+
+		if refund := undo(); refund != 0 {
+			t.Errorf("undo() of a busted game = %s, want nothing refunded", refund)
+		}
+		if client.Exists(ctx, REDIS_KEY_MINES_GAME+gameID).Val() == 0 {
+			t.Error("busted game should keep its state")
+		}
+	})
+}
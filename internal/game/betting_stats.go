@@ -0,0 +1,77 @@
+package game
+
+import (
+	"slices"
+	"sort"
+)
+
+// BettingStats summarises a round's bets when betting closes, so players see
+// how busy the round is. Auto cashout targets are listed without the users
+// who set them, and the largest bettor is masked.
+type BettingStats struct {
+	ActiveBettors          int       `json:"active_bettors"`
+	TotalWagered           Amount    `json:"total_wagered"`
+	LargestBet             Amount    `json:"largest_bet"`
+	LargestBetUserIDMasked string    `json:"largest_bet_user_id_masked,omitempty"`
+	AutoCashoutTargets     []float64 `json:"auto_cashout_targets"` // Distinct, ascending
+}
+
+// CalculateBettingStats aggregates the active bets of a round. Ties for the
+// largest bet go to the earliest bet.
+func CalculateBettingStats(bets map[string]ActiveBet) BettingStats {
+	ordered := make([]ActiveBet, 0, len(bets))
+	for _, bet := range bets {
+		ordered = append(ordered, bet)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		if !ordered[i].PlacedAt.Equal(ordered[j].PlacedAt) {
+			return ordered[i].PlacedAt.Before(ordered[j].PlacedAt)
+		}
+		return ordered[i].BetID < ordered[j].BetID
+	})
+
+	stats := BettingStats{AutoCashoutTargets: []float64{}}
+	bettors := make(map[string]bool)
+	var largest *ActiveBet
+	for i, bet := range ordered {
+		bettors[bet.UserID] = true
+		stats.TotalWagered = stats.TotalWagered.Add(bet.Amount)
+		if largest == nil || bet.Amount > largest.Amount {
+			largest = &ordered[i]
+		}
+		if bet.AutoCashout > 0 && !slices.Contains(stats.AutoCashoutTargets, bet.AutoCashout) {
+			stats.AutoCashoutTargets = append(stats.AutoCashoutTargets, bet.AutoCashout)
+		}
+	}
+	slices.Sort(stats.AutoCashoutTargets)
+
+	stats.ActiveBettors = len(bettors)
+	if largest != nil {
+		stats.LargestBet = largest.Amount
+		stats.LargestBetUserIDMasked = maskUserIDPrefix(largest.UserID)
+	}
+	return stats
+}
+
+// maskUserIDPrefix shows only the first three characters of a user ID
+func maskUserIDPrefix(userID string) string {
+	runes := []rune(userID)
+	if len(runes) > 3 {
+		runes = runes[:3]
+	}
+	return string(runes) + "***"
+}
+
+// broadcastBettingStats announces the aggregates of a round whose betting has closed
+func (m *Manager) broadcastBettingStats(roundID string, bets map[string]ActiveBet) {
+	stats := CalculateBettingStats(bets)
+	m.hub.Broadcast(map[string]interface{}{
+		"type":                       "betting_stats",
+		"round_id":                   roundID,
+		"active_bettors":             stats.ActiveBettors,
+		"total_wagered":              stats.TotalWagered,
+		"largest_bet":                stats.LargestBet,
+		"largest_bet_user_id_masked": stats.LargestBetUserIDMasked,
+		"auto_cashout_targets":       stats.AutoCashoutTargets,
+	})
+}
@@ -0,0 +1,108 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestManager_BettingStatsBroadcast(t *testing.T) {
+	m, client := newTestManager(t)
+	m.bettingTime = 300 * time.Millisecond
+	m.countdownInterval = 100 * time.Millisecond
+	go m.hub.Run()
+
+	conn := &mockConn{}
+	m.hub.RegisterClient(conn, "observer")
+	waitForClients(t, m.hub, 1)
+
+	bets := []struct {
+		userID      string
+		amount      float64
+		autoCashout float64
+	}{
+		{"alice", 10, 2.0},
+		{"bobby", 25, 5.0},
+		{"carol", 5, 2.0},
+		{"dave99", 100, 0},
+		{"erin", 40, 10.0},
+	}
+	for _, bet := range bets {
+		client.Set(context.Background(), REDIS_KEY_USER_BALANCE+bet.userID, 1000.0, 0)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		m.runRound()
+		close(done)
+	}()
+	t.Cleanup(func() {
+		m.Stop()
+		<-done
+	})
+
+	for m.GetCurrentRound() == nil {
+		time.Sleep(5 * time.Millisecond)
+	}
+	for _, bet := range bets {
+		resp, err := m.PlaceBet(BetRequest{UserID: bet.userID, Amount: amountOf(bet.amount), AutoCashout: bet.autoCashout})
+		if err != nil || !resp.Success {
+			t.Fatalf("PlaceBet(%s) = %+v, %v", bet.userID, resp, err)
+		}
+	}
+
+	stats := waitForMessageType(t, conn, "betting_stats")
+	if stats["round_id"] != m.GetCurrentRound().RoundID {
+		t.Errorf("betting_stats round_id = %v, want the current round", stats["round_id"])
+	}
+	want := map[string]interface{}{
+		"active_bettors":             5.0,
+		"total_wagered":              180.0,
+		"largest_bet":                100.0,
+		"largest_bet_user_id_masked": "dav***",
+		"auto_cashout_targets":       "[2 5 10]",
+	}
+	for field, value := range want {
+		got := stats[field]
+		if field == "auto_cashout_targets" {
+			got = fmt.Sprint(got)
+		}
+		if got != value {
+			t.Errorf("betting_stats %s = %v, want %v", field, got, value)
+		}
+	}
+}
+
+// waitForMessageType waits for the first hub message of the given type
+func waitForMessageType(t *testing.T, conn *mockConn, msgType string) map[string]interface{} {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for seen := 0; time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		conn.mu.Lock()
+		messages := conn.messages[seen:]
+		seen = len(conn.messages)
+		conn.mu.Unlock()
+
+		for _, data := range messages {
+			var msg map[string]interface{}
+			if json.Unmarshal(data, &msg) == nil && msg["type"] == msgType {
+				return msg
+			}
+		}
+	}
+	t.Fatalf("no %s message received", msgType)
+	return nil
+}
+
+func TestCalculateBettingStats_Empty(t *testing.T) {
+	stats := CalculateBettingStats(nil)
+	if stats.ActiveBettors != 0 || stats.TotalWagered != 0 || stats.LargestBetUserIDMasked != "" || len(stats.AutoCashoutTargets) != 0 {
+		t.Errorf("CalculateBettingStats(nil) = %+v, want empty stats", stats)
+	}
+	if stats.AutoCashoutTargets == nil {
+		t.Error("auto cashout targets should encode as an empty list")
+	}
+}
@@ -0,0 +1,143 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"aviator/internal/database"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	REDIS_KEY_BET_CANCELLED = "crash:cancelled:" // Set once a user has cancelled a bet in a round
+
+	BET_CANCEL_WINDOW              = 3 * time.Second // After placing a bet, how long it can be cancelled
+	BET_CANCELLED_TRANSACTION_TYPE = "BET_CANCELLED"
+	BET_CANCELLED_MESSAGE_TYPE     = "bet_cancelled" // Broadcast to everyone when a bet is cancelled
+)
+
+type CancelBetRequest struct {
+	UserID string `json:"user_id"`
+	BetID  string `json:"bet_id"`
+}
+
+type CancelBetResponse struct {
+	Success        bool    `json:"success"`
+	Message        string  `json:"message"`
+	RefundedAmount Amount  `json:"refunded_amount,omitempty"`
+	Balance        float64 `json:"balance,omitempty"`
+	Currency
+}
+
+// SetTransactionRecorder records bet cancellations to the audit trail.
+// Without one they are only logged.
+func (m *Manager) SetTransactionRecorder(recorder TransactionRecorder) {
+	m.transactions = recorder
+}
+
+// CancelBet removes a player's bet from the current round and refunds its
+// amount. It is allowed during the betting phase within BET_CANCEL_WINDOW of
+// placing the bet, once per user and round. Errors are reported as by PlaceBet.
+func (m *Manager) CancelBet(ctx context.Context, req CancelBetRequest) (CancelBetResponse, error) {
+	resp, err := m.cancelPlayerBet(ctx, req)
+	resp.Currency = DisplayCurrency()
+	return resp, err
+}
+
+func (m *Manager) cancelPlayerBet(ctx context.Context, req CancelBetRequest) (CancelBetResponse, error) {
+	roundID, bet, resp, err := m.claimCancellation(ctx, req)
+	if err != nil || !resp.Success {
+		return resp, err
+	}
+
+	balance, err := CreditBalance(ctx, m.redisClient, req.UserID, bet.Amount.Float64())
+	if err != nil {
+		return CancelBetResponse{}, fmt.Errorf("cancelPlayerBet: refund %s of bet %s: %w", bet.Amount, req.BetID, err)
+	}
+	m.hub.NotifyBalance(req.UserID, balance, bet.Amount, BalanceReasonRefund)
+	recordWager(ctx, m.redisClient, req.UserID, -bet.Amount) // A cancelled bet was never wagered
+	m.recordCancellation(ctx, req, bet.Amount, balance)
+
+	m.hub.Broadcast(map[string]interface{}{
+		"type":           BET_CANCELLED_MESSAGE_TYPE,
+		"user_id_masked": maskUserID(req.UserID),
+		"round_id":       roundID,
+	})
+
+	log.Printf("[BET] User %s cancelled %s (ID: %s)", req.UserID, bet.Amount, req.BetID)
+	return CancelBetResponse{
+		Success:        true,
+		Message:        "Bet cancelled",
+		RefundedAmount: bet.Amount,
+		Balance:        balance,
+	}, nil
+}
+
+// claimCancellation takes the bet out of the current round. Only this part
+// holds the state lock, which keeps the round from starting before the bet
+// is gone; the refund, audit record and broadcast run after it is released.
+// A successful claim is reported with resp.Success set.
+func (m *Manager) claimCancellation(ctx context.Context, req CancelBetRequest) (string, ActiveBet, CancelBetResponse, error) {
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+	if m.currentRound == nil || m.currentRound.Status != "BETTING" {
+		return "", ActiveBet{}, CancelBetResponse{Message: "Betting is closed"}, nil
+	}
+	roundID := m.currentRound.RoundID
+
+	betKey := REDIS_KEY_ACTIVE_BETS + roundID
+	betJSON, err := m.redisClient.HGet(ctx, betKey, req.BetID).Result()
+	if err == redis.Nil {
+		return "", ActiveBet{}, CancelBetResponse{Message: "Bet not found"}, nil
+	}
+	if err != nil {
+		return "", ActiveBet{}, CancelBetResponse{}, fmt.Errorf("cancelPlayerBet: HGet: %w", err)
+	}
+	var bet ActiveBet
+	if err := json.Unmarshal([]byte(betJSON), &bet); err != nil {
+		return "", ActiveBet{}, CancelBetResponse{}, fmt.Errorf("cancelPlayerBet: decode bet %s: %w", req.BetID, err)
+	}
+	if bet.UserID != req.UserID {
+		return "", ActiveBet{}, CancelBetResponse{Message: "Bet not found"}, nil
+	}
+	if time.Since(bet.PlacedAt) > BET_CANCEL_WINDOW {
+		return "", ActiveBet{}, CancelBetResponse{Message: fmt.Sprintf("Bets can only be cancelled within %d seconds", int(BET_CANCEL_WINDOW/time.Second))}, nil
+	}
+
+	cancelledKey := REDIS_KEY_BET_CANCELLED + roundID + ":" + req.UserID
+	claimed, err := m.redisClient.SetNX(ctx, cancelledKey, req.BetID, 10*time.Minute).Result()
+	if err != nil {
+		return "", ActiveBet{}, CancelBetResponse{}, fmt.Errorf("cancelPlayerBet: SetNX: %w", err)
+	}
+	if !claimed {
+		return "", ActiveBet{}, CancelBetResponse{Message: "Only one bet can be cancelled per round"}, nil
+	}
+
+	if !m.removeBet(ctx, roundID, req.BetID, bet.UserID) {
+		m.redisClient.Del(ctx, cancelledKey)
+		return "", ActiveBet{}, CancelBetResponse{Message: "Bet not found"}, nil
+	}
+	return roundID, bet, CancelBetResponse{Success: true}, nil
+}
+
+func (m *Manager) recordCancellation(ctx context.Context, req CancelBetRequest, refund Amount, balance float64) {
+	if m.transactions == nil {
+		return
+	}
+	tx := database.Transaction{
+		UserID:        req.UserID,
+		Type:          BET_CANCELLED_TRANSACTION_TYPE,
+		Amount:        refund.Float64(),
+		BalanceBefore: balance - refund.Float64(),
+		BalanceAfter:  balance,
+		ReferenceID:   req.BetID,
+		Description:   "Cancelled Aviator bet",
+	}
+	if err := m.transactions.RecordTransaction(ctx, tx); err != nil {
+		log.Printf("[BET] Failed to record cancellation of %s for %s: %v", req.BetID, req.UserID, err)
+	}
+}
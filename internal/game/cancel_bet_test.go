@@ -0,0 +1,85 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestManager_CancelBet(t *testing.T) {
+	m, client := newTestManager(t)
+	ledger := &recordingLedger{}
+	m.SetTransactionRecorder(ledger)
+	ctx := context.Background()
+	m.setTestRound("R-cancel", "BETTING")
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"user1", 100.0, 0)
+	referrals := NewReferralService(client, m.hub, &referralLedger{referrals: make(map[string]string)})
+	if err := referrals.Register(ctx, "user1", "referrer"); err != nil {
+		t.Fatalf("Register() error: %v", err)
+	}
+
+	betID := placeTestBet(t, m, "user1", 10, 2)
+	if resp, _ := m.CancelBet(ctx, CancelBetRequest{UserID: "user2", BetID: betID}); resp.Success {
+		t.Errorf("CancelBet() of another user's bet = %+v, want it rejected", resp)
+	}
+
+	resp, err := m.CancelBet(ctx, CancelBetRequest{UserID: "user1", BetID: betID})
+	if err != nil || !resp.Success || resp.RefundedAmount != amountOf(10) || resp.Balance != 100 {
+		t.Fatalf("CancelBet() = %+v, %v; want 10.00 refunded to a balance of 100", resp, err)
+	}
+	if balance, _ := client.Get(ctx, REDIS_KEY_USER_BALANCE+"user1").Float64(); balance != 100 {
+		t.Errorf("balance = %v, want 100 restored", balance)
+	}
+	if wagered, _ := client.Get(ctx, REDIS_KEY_USER_WAGERED+"user1").Float64(); wagered != 0 {
+		t.Errorf("wagered = %v, want the cancelled bet reversed", wagered)
+	}
+	if bonus, _ := client.Get(ctx, REDIS_KEY_USER_BALANCE+"referrer").Float64(); bonus != 0 {
+		t.Errorf("referrer balance = %v, want no bonus for a cancelled bet", bonus)
+	}
+	if _, exists := m.loadActiveBets("R-cancel")[betID]; exists {
+		t.Error("cancelled bet is still an active bet")
+	}
+	if triggered := m.triggeredAutoCashouts("R-cancel", 2); len(triggered) != 0 {
+		t.Errorf("triggered auto-cashouts = %v, want the cancelled bet removed", triggered)
+	}
+	if len(ledger.txs) != 1 || ledger.txs[0].Type != BET_CANCELLED_TRANSACTION_TYPE || ledger.txs[0].ReferenceID != betID ||
+		ledger.txs[0].BalanceBefore != 90 || ledger.txs[0].BalanceAfter != 100 {
+		t.Errorf("transactions = %+v, want the cancellation from 90 to 100", ledger.txs)
+	}
+
+	var broadcast map[string]interface{}
+	for len(m.hub.broadcast) > 0 {
+		if msg := (<-m.hub.broadcast).(map[string]interface{}); msg["type"] == BET_CANCELLED_MESSAGE_TYPE {
+			broadcast = msg
+		}
+	}
+	if broadcast == nil || broadcast["round_id"] != "R-cancel" || broadcast["user_id_masked"] != "u***1" {
+		t.Errorf("bet_cancelled broadcast = %v, want the masked user and round", broadcast)
+	}
+
+	// One cancellation per user and round
+	again := placeTestBet(t, m, "user1", 10, 0)
+	if resp, _ := m.CancelBet(ctx, CancelBetRequest{UserID: "user1", BetID: again}); resp.Success {
+		t.Errorf("second CancelBet() = %+v, want it rejected", resp)
+	}
+	if _, exists := m.loadActiveBets("R-cancel")[again]; !exists {
+		t.Error("rejected cancellation removed the bet")
+	}
+
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"user2", 100.0, 0)
+	late := placeTestBet(t, m, "user2", 10, 0)
+	bet := m.loadActiveBets("R-cancel")[late]
+	bet.PlacedAt = time.Now().Add(-BET_CANCEL_WINDOW - time.Second)
+	betJSON, _ := json.Marshal(bet)
+	client.HSet(ctx, REDIS_KEY_ACTIVE_BETS+"R-cancel", late, betJSON)
+	if resp, _ := m.CancelBet(ctx, CancelBetRequest{UserID: "user2", BetID: late}); resp.Success {
+		t.Errorf("CancelBet() after the window = %+v, want it rejected", resp)
+	}
+
+	early := placeTestBet(t, m, "user2", 10, 0)
+	m.setTestRound("R-cancel", "RUNNING")
+	if resp, _ := m.CancelBet(ctx, CancelBetRequest{UserID: "user2", BetID: early}); resp.Success || resp.Message != "Betting is closed" {
+		t.Errorf("CancelBet() while running = %+v, want betting closed", resp)
+	}
+}
@@ -0,0 +1,178 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// Sessions in progress across all instances
+	REDIS_KEY_MINES_ACTIVE_COUNT  = "mines:active_count"
+	REDIS_KEY_DICE_ACTIVE_COUNT   = "dice:active_count"
+	REDIS_KEY_PLINKO_ACTIVE_COUNT = "plinko:active_count"
+
+	MINES_MAX_CONCURRENT_GAMES  = 10000
+	DICE_MAX_CONCURRENT_ROLLS   = 10000 // Rolls and drops only last one request
+	PLINKO_MAX_CONCURRENT_DROPS = 10000
+
+	CAPACITY_ALERT_PCT              = 80.0
+	CAPACITY_ALERT_INTERVAL         = 60 * time.Second
+	MINES_ACTIVE_RECONCILE_INTERVAL = 60 * time.Second
+)
+
+var ErrAtCapacity = errors.New("Server at capacity, try again shortly")
+
+// CapacityInfo shows how close a game is to its concurrent session limit
+type CapacityInfo struct {
+	GameType       GameType `json:"game_type"`
+	ActiveSessions int64    `json:"active_sessions"`
+	MaxSessions    int64    `json:"max_sessions"`
+	UtilizationPct float64  `json:"utilization_pct"`
+}
+
+// sessionLimiter caps the sessions of one game type in progress at once,
+// counted in Redis so the limit holds across instances
+type sessionLimiter struct {
+	redisClient *redis.Client
+	gameType    GameType
+	key         string
+	max         int64
+
+	lastAlert atomic.Int64 // Unix nanoseconds of the last utilization warning
+}
+
+func newSessionLimiter(redisClient *redis.Client, gameType GameType, key string, max int) *sessionLimiter {
+	return &sessionLimiter{redisClient: redisClient, gameType: gameType, key: key, max: int64(max)}
+}
+
+// acquire counts n new sessions, or returns ErrAtCapacity if they would
+// exceed the limit
+func (l *sessionLimiter) acquire(ctx context.Context, n int) error {
+	count, err := l.redisClient.IncrBy(ctx, l.key, int64(n)).Result()
+	if err != nil {
+		return err
+	}
+	if count > l.max {
+		l.redisClient.DecrBy(ctx, l.key, int64(n))
+		log.Printf("[CAPACITY] Rejected %d %s sessions, %d of %d in progress", n, l.gameType, count-int64(n), l.max)
+		return ErrAtCapacity
+	}
+
+	l.warnIfBusy(count)
+	return nil
+}
+
+// release counts n sessions as ended
+func (l *sessionLimiter) release(ctx context.Context, n int) {
+	count, err := l.redisClient.DecrBy(ctx, l.key, int64(n)).Result()
+	if err != nil {
+		log.Printf("[CAPACITY] Failed to release %s sessions: %v", l.gameType, err)
+		return
+	}
+	if count < 0 {
+		l.redisClient.Set(ctx, l.key, 0, 0) // Released more than acquired, e.g. after a reconcile
+	}
+}
+
+// reconcile corrects the counter when it has drifted from the number of
+// sessions actually in progress, e.g. after games expired unfinished
+func (l *sessionLimiter) reconcile(ctx context.Context, actual int) {
+	count, err := l.redisClient.Get(ctx, l.key).Int64()
+	if err != nil && err != redis.Nil {
+		log.Printf("[CAPACITY] Failed to read %s sessions: %v", l.gameType, err)
+		return
+	}
+	if count == int64(actual) {
+		return
+	}
+
+	log.Printf("[CAPACITY] %s session counter drifted to %d, %d in progress", l.gameType, count, actual)
+	if err := l.redisClient.Set(ctx, l.key, actual, 0).Err(); err != nil {
+		log.Printf("[CAPACITY] Failed to reconcile %s sessions: %v", l.gameType, err)
+	}
+}
+
+// warnIfBusy logs a warning, at most once per CAPACITY_ALERT_INTERVAL, while
+// utilization is above CAPACITY_ALERT_PCT
+func (l *sessionLimiter) warnIfBusy(count int64) {
+	utilization := utilizationPct(count, l.max)
+	if utilization <= CAPACITY_ALERT_PCT {
+		return
+	}
+
+	now := time.Now().UnixNano()
+	last := l.lastAlert.Load()
+	if now-last < int64(CAPACITY_ALERT_INTERVAL) || !l.lastAlert.CompareAndSwap(last, now) {
+		return
+	}
+	log.Printf("[CAPACITY] %s at %.1f%% of capacity: %d of %d sessions", l.gameType, utilization, count, l.max)
+}
+
+// capacity reports the sessions in progress against the limit
+func (l *sessionLimiter) capacity(ctx context.Context) (CapacityInfo, error) {
+	count, err := l.redisClient.Get(ctx, l.key).Int64()
+	if err != nil && err != redis.Nil {
+		return CapacityInfo{}, err
+	}
+	return CapacityInfo{
+		GameType:       l.gameType,
+		ActiveSessions: count,
+		MaxSessions:    l.max,
+		UtilizationPct: utilizationPct(count, l.max),
+	}, nil
+}
+
+func utilizationPct(count, max int64) float64 {
+	if max <= 0 {
+		return 0
+	}
+	return math.Round(float64(count)/float64(max)*10000) / 100
+}
+
+// Capacity returns the session limits of every registered engine that has
+// one. Engines whose counter fails are left out.
+func (gf *GameFactory) Capacity(ctx context.Context) []CapacityInfo {
+	all := make([]CapacityInfo, 0, 3)
+	for _, gameType := range []GameType{GameTypeMines, GameTypeDice, GameTypePlinko} {
+		engine, exists := gf.GetEngine(gameType)
+		if !exists {
+			continue
+		}
+		info, err := engine.ProcessAction(ctx, "capacity", nil)
+		if err != nil {
+			log.Printf("[CAPACITY] Failed to load %s capacity: %v", gameType, err)
+			continue
+		}
+		all = append(all, info.(CapacityInfo))
+	}
+	return all
+}
+
+// reconcileActiveGames keeps the Mines session counter in line with the
+// active games stored, until ctx ends or the engine stops
+func (m *MinesEngine) reconcileActiveGames(ctx context.Context) {
+	ticker := time.NewTicker(MINES_ACTIVE_RECONCILE_INTERVAL)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.stopChan:
+			return
+		case <-ticker.C:
+			stats, err := m.scanActiveGames(ctx)
+			if err != nil {
+				log.Printf("[CAPACITY] Failed to scan Mines games: %v", err)
+				continue
+			}
+			m.sessions.reconcile(ctx, stats.ActiveGames)
+		}
+	}
+}
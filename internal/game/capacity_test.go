@@ -0,0 +1,104 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestMinesEngine_SessionLimit(t *testing.T) {
+	engine, client := newTestMinesEngine(t)
+	engine.sessions.max = 3
+	ctx := context.Background()
+
+	bet := func() (MinesBetResponse, error) {
+		resp, err := engine.PlaceBet(ctx, MinesBetRequest{UserID: "user1", Amount: 10, MineCount: 1})
+		if err != nil {
+			return MinesBetResponse{}, err
+		}
+		return resp.(MinesBetResponse), nil
+	}
+
+	var gameIDs []string
+	for i := 0; i < 3; i++ {
+		resp, err := bet()
+		if err != nil || !resp.Success {
+			t.Fatalf("bet %d = %+v, %v; want success", i+1, resp, err)
+		}
+		gameIDs = append(gameIDs, resp.GameID)
+	}
+
+	before, _ := client.Get(ctx, REDIS_KEY_USER_BALANCE+"user1").Float64()
+	if _, err := bet(); !errors.Is(err, ErrAtCapacity) {
+		t.Fatalf("bet over the limit: err = %v, want ErrAtCapacity", err)
+	}
+	if balance, _ := client.Get(ctx, REDIS_KEY_USER_BALANCE+"user1").Float64(); balance != before {
+		t.Errorf("balance = %v after a rejected bet, want %v", balance, before)
+	}
+
+	info, err := engine.ProcessAction(ctx, "capacity", nil)
+	if err != nil {
+		t.Fatalf("capacity error: %v", err)
+	}
+	if got := info.(CapacityInfo); got.ActiveSessions != 3 || got.MaxSessions != 3 || got.UtilizationPct != 100 {
+		t.Errorf("capacity = %+v, want 3 of 3 sessions", got)
+	}
+
+	t.Run("cashout frees a session", func(t *testing.T) {
+		gameState, _ := engine.loadGame(ctx, gameIDs[0])
+		tile := 0
+		if gameState.MinePositions[0] == tile {
+			tile = 1
+		}
+		engine.ProcessAction(ctx, "click", MinesClickRequest{UserID: "user1", GameID: gameIDs[0], TileID: tile})
+		if resp, err := engine.ProcessAction(ctx, "cashout", MinesCashoutRequest{UserID: "user1", GameID: gameIDs[0]}); err != nil || !resp.(MinesCashoutResponse).Success {
+			t.Fatalf("cashout = %+v, %v; want success", resp, err)
+		}
+		if resp, err := bet(); err != nil || !resp.Success {
+			t.Fatalf("bet after cashout = %+v, %v; want success", resp, err)
+		}
+	})
+
+	t.Run("bust frees a session", func(t *testing.T) {
+		gameState, _ := engine.loadGame(ctx, gameIDs[1])
+		engine.ProcessAction(ctx, "click", MinesClickRequest{UserID: "user1", GameID: gameIDs[1], TileID: gameState.MinePositions[0]})
+		if resp, err := bet(); err != nil || !resp.Success {
+			t.Fatalf("bet after bust = %+v, %v; want success", resp, err)
+		}
+	})
+
+	t.Run("reconcile corrects drift", func(t *testing.T) {
+		client.Set(ctx, REDIS_KEY_MINES_ACTIVE_COUNT, 50, 0)
+		stats, err := engine.scanActiveGames(ctx)
+		if err != nil {
+			t.Fatalf("scan error: %v", err)
+		}
+		engine.sessions.reconcile(ctx, stats.ActiveGames)
+
+		if count, _ := client.Get(ctx, REDIS_KEY_MINES_ACTIVE_COUNT).Int(); count != 3 {
+			t.Errorf("active count = %d, want the 3 active games", count)
+		}
+	})
+}
+
+func TestDiceEngine_SessionLimit(t *testing.T) {
+	t.Setenv("DICE_MAX_CONCURRENT_ROLLS", "5")
+	client := newTestRedis(t)
+	ctx := context.Background()
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"user1", 100.0, 0)
+
+	engine := NewDiceEngine(client, nil)
+	roll := DiceRollRequest{UserID: "user1", Amount: 1, Target: 50, IsOver: true}
+
+	if resp, err := engine.PlaceBet(ctx, roll); err != nil || !resp.(DiceRollResponse).Success {
+		t.Fatalf("roll = %+v, %v; want success", resp, err)
+	}
+	if count, _ := client.Get(ctx, REDIS_KEY_DICE_ACTIVE_COUNT).Int(); count != 0 {
+		t.Errorf("active count = %d after the roll, want 0", count)
+	}
+
+	client.Set(ctx, REDIS_KEY_DICE_ACTIVE_COUNT, 5, 0)
+	if _, err := engine.PlaceBet(ctx, roll); !errors.Is(err, ErrAtCapacity) {
+		t.Errorf("roll at capacity: err = %v, want ErrAtCapacity", err)
+	}
+}
@@ -0,0 +1,131 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	REDIS_KEY_CHAT_HISTORY = "chat:history:"
+	CHAT_HISTORY_SIZE      = 50
+	CHAT_MAX_LENGTH        = 100                    // Characters, not bytes, so emoji count once
+	CHAT_RATE_INTERVAL     = 500 * time.Millisecond // At most 2 messages per second
+)
+
+var (
+	ErrChatEmpty       = errors.New("message is empty")
+	ErrChatTooLong     = fmt.Errorf("message exceeds %d characters", CHAT_MAX_LENGTH)
+	ErrChatRateLimited = errors.New("sending messages too fast")
+	ErrChatBlocked     = errors.New("message contains blocked words")
+)
+
+type ChatMessage struct {
+	UserID    string    `json:"user_id"`
+	Message   string    `json:"message"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ChatService validates chat messages, broadcasts them to players of the same
+// game and keeps a short history in Redis
+type ChatService struct {
+	redisClient  *redis.Client
+	hub          *Hub
+	blockedWords []string
+}
+
+func NewChatService(redisClient *redis.Client, hub *Hub, blockedWords []string) *ChatService {
+	return &ChatService{
+		redisClient:  redisClient,
+		hub:          hub,
+		blockedWords: blockedWords,
+	}
+}
+
+// ParseBlockedWords splits a comma-separated list such as CHAT_BLOCKED_WORDS
+func ParseBlockedWords(value string) []string {
+	var words []string
+	for _, word := range strings.Split(value, ",") {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if word != "" {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// Post validates a message from userID and broadcasts it to clients of gameType
+func (c *ChatService) Post(ctx context.Context, userID string, gameType GameType, text string) (*ChatMessage, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, ErrChatEmpty
+	}
+	if utf8.RuneCountInString(text) > CHAT_MAX_LENGTH {
+		return nil, ErrChatTooLong
+	}
+	if c.isBlocked(text) {
+		return nil, ErrChatBlocked
+	}
+	if !c.hub.AllowChat(userID) {
+		return nil, ErrChatRateLimited
+	}
+
+	msg := ChatMessage{
+		UserID:    userID,
+		Message:   text,
+		Timestamp: time.Now(),
+	}
+
+	historyKey := REDIS_KEY_CHAT_HISTORY + string(gameType)
+	msgJSON, _ := json.Marshal(msg)
+	pipe := c.redisClient.TxPipeline()
+	pipe.LPush(ctx, historyKey, msgJSON)
+	pipe.LTrim(ctx, historyKey, 0, CHAT_HISTORY_SIZE-1)
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("[CHAT] Failed to store message: %v", err)
+	}
+
+	c.hub.BroadcastToGameType(gameType, map[string]interface{}{
+		"type":      "chat",
+		"user_id":   msg.UserID,
+		"message":   msg.Message,
+		"timestamp": msg.Timestamp,
+	})
+
+	return &msg, nil
+}
+
+// History returns the stored messages for a game type, oldest first
+func (c *ChatService) History(ctx context.Context, gameType GameType) ([]ChatMessage, error) {
+	entries, err := c.redisClient.LRange(ctx, REDIS_KEY_CHAT_HISTORY+string(gameType), 0, CHAT_HISTORY_SIZE-1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]ChatMessage, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		var msg ChatMessage
+		if err := json.Unmarshal([]byte(entries[i]), &msg); err != nil {
+			continue
+		}
+		history = append(history, msg)
+	}
+	return history, nil
+}
+
+func (c *ChatService) isBlocked(text string) bool {
+	lower := strings.ToLower(text)
+	for _, word := range c.blockedWords {
+		if strings.Contains(lower, word) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestChat(t *testing.T, blockedWords []string) (*ChatService, *Hub) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	hub := NewHub()
+	go hub.Run()
+
+	return NewChatService(client, hub, blockedWords), hub
+}
+
+func TestParseBlockedWords(t *testing.T) {
+	got := ParseBlockedWords(" Spam, ,scam ,")
+	want := []string{"spam", "scam"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseBlockedWords() = %v, want %v", got, want)
+	}
+	if words := ParseBlockedWords(""); len(words) != 0 {
+		t.Errorf("ParseBlockedWords(\"\") = %v, want empty", words)
+	}
+}
+
+func TestChatService_Post(t *testing.T) {
+	chat, hub := newTestChat(t, []string{"scam"})
+	ctx := context.Background()
+
+	aviator := &mockConn{}
+	mines := &mockConn{}
+	hub.RegisterClient(aviator, "user1")
+	hub.RegisterGameClient(mines, "user2", GameTypeMines)
+	waitForClients(t, hub, 2)
+
+	tests := []struct {
+		name    string
+		userID  string
+		message string
+		wantErr error
+	}{
+		{"emoji", "user1", "🚀🚀🚀", nil},
+		{"empty", "user3", "   ", ErrChatEmpty},
+		{"too long", "user3", strings.Repeat("a", CHAT_MAX_LENGTH+1), ErrChatTooLong},
+		{"blocked word", "user3", "total SCAM", ErrChatBlocked},
+		{"rate limited", "user1", "again", ErrChatRateLimited},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := chat.Post(ctx, tt.userID, GameTypeAviator, tt.message)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Post() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// 100 emoji are within the limit even though they exceed 100 bytes
+	if _, err := chat.Post(ctx, "user4", GameTypeAviator, strings.Repeat("🚀", CHAT_MAX_LENGTH)); err != nil {
+		t.Errorf("Post() with %d emoji error = %v", CHAT_MAX_LENGTH, err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if aviator.count() != 2 {
+		t.Errorf("aviator client received %d messages, want 2", aviator.count())
+	}
+	if mines.count() != 0 {
+		t.Errorf("mines client received %d messages, want 0", mines.count())
+	}
+}
+
+func TestChatService_History(t *testing.T) {
+	chat, _ := newTestChat(t, nil)
+	ctx := context.Background()
+
+	for i := 0; i < CHAT_HISTORY_SIZE+5; i++ {
+		if _, err := chat.Post(ctx, fmt.Sprintf("user%d", i), GameTypeAviator, fmt.Sprintf("msg %d", i)); err != nil {
+			t.Fatalf("Post() error = %v", err)
+		}
+	}
+
+	history, err := chat.History(ctx, GameTypeAviator)
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	if len(history) != CHAT_HISTORY_SIZE {
+		t.Fatalf("History() returned %d messages, want %d", len(history), CHAT_HISTORY_SIZE)
+	}
+	if history[0].Message != "msg 5" || history[len(history)-1].Message != fmt.Sprintf("msg %d", CHAT_HISTORY_SIZE+4) {
+		t.Errorf("History() should be oldest first, got %q ... %q", history[0].Message, history[len(history)-1].Message)
+	}
+
+	if other, _ := chat.History(ctx, GameTypeDice); len(other) != 0 {
+		t.Errorf("History() for dice returned %d messages, want 0", len(other))
+	}
+}
@@ -0,0 +1,43 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Players may choose the client seed of a Mines, Dice or Plinko bet. Bets
+// without one use a generated seed.
+const (
+	CLIENT_SEED_MIN_LENGTH = 8
+	CLIENT_SEED_MAX_LENGTH = 128
+)
+
+// ValidateClientSeed checks a player-chosen client seed. It returns a message
+// for the player, or "" if the seed is valid. An empty seed is valid and
+// means the server generates one.
+func ValidateClientSeed(seed string) string {
+	if seed == "" {
+		return ""
+	}
+	if len(seed) < CLIENT_SEED_MIN_LENGTH || len(seed) > CLIENT_SEED_MAX_LENGTH {
+		return fmt.Sprintf("Client seed must be between %d and %d characters", CLIENT_SEED_MIN_LENGTH, CLIENT_SEED_MAX_LENGTH)
+	}
+	for _, r := range seed {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return "Client seed must be alphanumeric"
+		}
+	}
+	if strings.Trim(seed, "0") == "" {
+		return "Client seed cannot be all zeros"
+	}
+	return ""
+}
+
+// clientSeedOrGenerate returns the player's validated seed, or a new one if
+// they did not choose one
+func clientSeedOrGenerate(seed string) string {
+	if seed == "" {
+		return GenerateSeed()
+	}
+	return seed
+}
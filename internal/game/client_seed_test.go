@@ -0,0 +1,30 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateClientSeed(t *testing.T) {
+	tests := []struct {
+		seed  string
+		valid bool
+	}{
+		{"", true},
+		{"abcd1234", true},
+		{strings.Repeat("A9", 64), true},
+		{"abc123", false},
+		{strings.Repeat("a", 129), false},
+		{"lucky-seed", false},
+		{"lucky seed", false},
+		{"séedséed", false},
+		{"00000000", false},
+		{"00000001", true},
+	}
+
+	for _, tt := range tests {
+		if got := ValidateClientSeed(tt.seed); (got == "") != tt.valid {
+			t.Errorf("ValidateClientSeed(%q) = %q, want valid %v", tt.seed, got, tt.valid)
+		}
+	}
+}
@@ -0,0 +1,299 @@
+package game
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"aviator/internal/database"
+)
+
+const (
+	REDIS_KEY_COMMITMENTS = "crash:commitments"
+
+	COMMITMENT_LOG_SIZE          = 1000
+	COMMITMENT_LOG_DEFAULT_LIMIT = 100
+)
+
+// RoundCommitment is a public log entry for a round's server seed commitment.
+// The multipliers are filled in once the round has crashed and the seed once
+// the reveal delay has passed. CrashMultiplier and BaseMultiplier are the
+// provably fair crash point; FinalMultiplier adds any bonus event to it.
+type RoundCommitment struct {
+	RoundID         string     `json:"round_id"`
+	HashCommitment  string     `json:"hash_commitment"`
+	PublishedAt     time.Time  `json:"published_at"`
+	ServerSeed      string     `json:"server_seed,omitempty"`
+	CrashMultiplier float64    `json:"crash_multiplier,omitempty"`
+	BaseMultiplier  float64    `json:"base_multiplier,omitempty"`
+	FinalMultiplier float64    `json:"final_multiplier,omitempty"`
+	CrashedAt       *time.Time `json:"crashed_at,omitempty"`
+	Verified        bool       `json:"verified"`
+}
+
+// RoundRecorder persists rounds, their commitments and their settled bets
+type RoundRecorder interface {
+	SaveRound(ctx context.Context, round database.Round) error
+	SaveBets(ctx context.Context, bets []database.Bet) error
+}
+
+// verifyRoundState checks a crashed round's seed against its commitment and
+// its crash multiplier against the seeds
+func verifyRoundState(round *RoundState) bool {
+	return CommitRoundSeed(round.ServerSeed) == round.HashCommitment &&
+		VerifyRound(round.ServerSeed, round.ClientSeed, round.Nonce, round.BaseMultiplier())
+}
+
+// commitmentScore orders the log by publication time
+func commitmentScore(publishedAt time.Time) float64 {
+	return float64(publishedAt.UnixMilli())
+}
+
+// publishCommitment adds a round's commitment to the public log before bets open
+func (m *Manager) publishCommitment(round *RoundState) {
+	data, _ := json.Marshal(RoundCommitment{
+		RoundID:        round.RoundID,
+		HashCommitment: round.HashCommitment,
+		PublishedAt:    round.CommitmentPublishedAt,
+	})
+
+	pipe := m.redisClient.TxPipeline()
+	pipe.ZAdd(m.ctx, REDIS_KEY_COMMITMENTS, redis.Z{Score: commitmentScore(round.CommitmentPublishedAt), Member: data})
+	pipe.ZRemRangeByRank(m.ctx, REDIS_KEY_COMMITMENTS, 0, -COMMITMENT_LOG_SIZE-1)
+	if _, err := pipe.Exec(m.ctx); err != nil {
+		log.Printf("[FAIR] Failed to publish commitment for %s: %v", round.RoundID, err)
+	}
+
+	m.signCommitment(round)
+	m.saveRound(round, false)
+}
+
+// revealCommitment replaces a crashed round's log entry with its revealed seed
+func (m *Manager) revealCommitment(round *RoundState) {
+	verified := verifyRoundState(round)
+	m.completeSignedCommitment(round)
+	score := strconv.FormatFloat(commitmentScore(round.CommitmentPublishedAt), 'f', -1, 64)
+
+	entries, err := m.redisClient.ZRangeByScore(m.ctx, REDIS_KEY_COMMITMENTS, &redis.ZRangeBy{Min: score, Max: score}).Result()
+	if err != nil {
+		log.Printf("[FAIR] Failed to load commitment for %s: %v", round.RoundID, err)
+		return
+	}
+
+	for _, entry := range entries {
+		var commitment RoundCommitment
+		if json.Unmarshal([]byte(entry), &commitment) != nil || commitment.RoundID != round.RoundID {
+			continue
+		}
+
+		crashedAt := round.CrashTime
+		commitment.ServerSeed = round.ServerSeed
+		commitment.CrashMultiplier = round.BaseMultiplier()
+		commitment.BaseMultiplier = round.BaseMultiplier()
+		commitment.FinalMultiplier = round.CrashMultiplier
+		commitment.CrashedAt = &crashedAt
+		commitment.Verified = verified
+		data, _ := json.Marshal(commitment)
+
+		pipe := m.redisClient.TxPipeline()
+		pipe.ZRem(m.ctx, REDIS_KEY_COMMITMENTS, entry)
+		pipe.ZAdd(m.ctx, REDIS_KEY_COMMITMENTS, redis.Z{Score: commitmentScore(round.CommitmentPublishedAt), Member: data})
+		if _, err := pipe.Exec(m.ctx); err != nil {
+			log.Printf("[FAIR] Failed to reveal commitment for %s: %v", round.RoundID, err)
+		}
+		break
+	}
+
+	m.saveRound(round, verified)
+}
+
+// revealSeed broadcasts a crashed round's server seed once the reveal delay
+// has passed, or straight away when there is no delay
+func (m *Manager) revealSeed(roundID, serverSeed string) {
+	reveal := func() {
+		m.hub.Broadcast(map[string]interface{}{
+			"type":        "seed_reveal",
+			"round_id":    roundID,
+			"server_seed": serverSeed,
+		})
+	}
+
+	if m.seedRevealDelay <= 0 {
+		reveal()
+		return
+	}
+	time.AfterFunc(m.seedRevealDelay, reveal)
+}
+
+// saveRound stores the round in PostgreSQL without holding up the game loop
+func (m *Manager) saveRound(round *RoundState, verified bool) {
+	if m.rounds == nil {
+		return
+	}
+
+	record := database.Round{
+		ID:                    round.RoundID,
+		ServerSeed:            round.ServerSeed,
+		HashCommitment:        round.HashCommitment,
+		CommitmentPublishedAt: round.CommitmentPublishedAt,
+		CommitmentSignature:   SignCommitment(m.operatorSecret, round.HashCommitment, round.RoundID, round.CommitmentPublishedAt),
+		ClientSeed:            round.ClientSeed,
+		CrashMultiplier:       round.BaseMultiplier(),
+		BonusMultiplier:       round.BonusMultiplier,
+		Nonce:                 round.Nonce,
+		Status:                round.Status,
+		StartedAt:             round.StartTime,
+		Verified:              verified,
+	}
+	if !round.CrashTime.IsZero() {
+		crashedAt := round.CrashTime
+		record.CrashedAt = &crashedAt
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := m.rounds.SaveRound(ctx, record); err != nil {
+			log.Printf("[FAIR] Failed to save round %s: %v", record.ID, err)
+		}
+	}()
+}
+
+// saveBets stores a crashed round's bets in PostgreSQL without holding up the
+// game loop
+func (m *Manager) saveBets(roundID string, bets map[string]ActiveBet) {
+	if m.rounds == nil || len(bets) == 0 {
+		return
+	}
+
+	records := make([]database.Bet, 0, len(bets))
+	for betID, bet := range bets {
+		record := database.Bet{
+			ID:          betID,
+			UserID:      bet.UserID,
+			RoundID:     roundID,
+			Amount:      bet.Amount.Float64(),
+			AutoCashout: bet.AutoCashout,
+			PlacedAt:    bet.PlacedAt,
+			Result:      database.BetResultLoss,
+		}
+		if bet.CashedOut {
+			cashedOutAt := bet.CashedOutAt
+			record.CashoutMultiplier = bet.CashoutMultiplier
+			record.Payout = bet.Amount.Mul(bet.CashoutMultiplier).Float64()
+			record.CashedOutAt = &cashedOutAt
+			record.Result = database.BetResultWin
+		}
+		records = append(records, record)
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := m.rounds.SaveBets(ctx, records); err != nil {
+			log.Printf("[ROUND END] Failed to save %d bets of round %s: %v", len(records), roundID, err)
+		}
+	}()
+}
+
+// RecentCommitments returns up to limit commitments, newest first. Seeds are
+// withheld until the reveal delay after their round's crash has passed.
+func (m *Manager) RecentCommitments(ctx context.Context, limit int) ([]RoundCommitment, error) {
+	entries, err := m.redisClient.ZRevRange(ctx, REDIS_KEY_COMMITMENTS, 0, int64(limit)-1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	commitments := make([]RoundCommitment, 0, len(entries))
+	for _, entry := range entries {
+		var commitment RoundCommitment
+		if err := json.Unmarshal([]byte(entry), &commitment); err != nil {
+			continue
+		}
+		if commitment.CrashedAt != nil && time.Since(*commitment.CrashedAt) < m.seedRevealDelay {
+			commitment.ServerSeed = ""
+		}
+		commitments = append(commitments, commitment)
+	}
+	return commitments, nil
+}
+
+// RoundVerification recomputes a stored round's crash multiplier from its
+// revealed seeds, with each step spelled out for players checking by hand
+type RoundVerification struct {
+	RoundID            string   `json:"round_id"`
+	ServerSeed         string   `json:"server_seed"`
+	ClientSeed         string   `json:"client_seed"`
+	Nonce              int      `json:"nonce"`
+	HashCommitment     string   `json:"hash_commitment"`
+	ClaimedMultiplier  float64  `json:"claimed_multiplier"`
+	ComputedMultiplier float64  `json:"computed_multiplier"`
+	BaseMultiplier     float64  `json:"base_multiplier"`  // Same as ClaimedMultiplier
+	FinalMultiplier    float64  `json:"final_multiplier"` // BaseMultiplier plus any bonus event
+	Verified           bool     `json:"verified"`         // Commitment and multiplier both match
+	CommitmentMatches  bool     `json:"commitment_matches"`
+	VerificationSteps  []string `json:"verification_steps"`
+}
+
+// VerifyStoredRound checks a round loaded from PostgreSQL. It returns false
+// while the round's seed is still secret: before the crash and until the
+// reveal delay has passed.
+func (m *Manager) VerifyStoredRound(round database.Round) (*RoundVerification, bool) {
+	if round.CrashedAt == nil || time.Since(*round.CrashedAt) < m.seedRevealDelay {
+		return nil, false
+	}
+
+	seedHash := CommitRoundSeed(round.ServerSeed)
+	computed := HashAndMapToMultiplier(round.ServerSeed, round.ClientSeed, round.Nonce)
+	verification := &RoundVerification{
+		RoundID:            round.ID,
+		ServerSeed:         round.ServerSeed,
+		ClientSeed:         round.ClientSeed,
+		Nonce:              round.Nonce,
+		HashCommitment:     round.HashCommitment,
+		ClaimedMultiplier:  round.CrashMultiplier,
+		ComputedMultiplier: computed,
+		BaseMultiplier:     round.CrashMultiplier,
+		FinalMultiplier:    math.Round((round.CrashMultiplier+round.BonusMultiplier)*100) / 100,
+		CommitmentMatches:  seedHash == round.HashCommitment,
+	}
+	multiplierMatches := VerifyRound(round.ServerSeed, round.ClientSeed, round.Nonce, round.CrashMultiplier)
+	verification.Verified = verification.CommitmentMatches && multiplierMatches
+
+	h := hmac.New(sha256.New, []byte(round.ServerSeed))
+	h.Write([]byte(fmt.Sprintf("%s:%d", round.ClientSeed, round.Nonce)))
+	digest := hex.EncodeToString(h.Sum(nil))
+
+	verification.VerificationSteps = []string{
+		fmt.Sprintf("HMAC-SHA256(key = \"%s\", message = server_seed) = %s, which %s the hash_commitment published before betting opened",
+			ROUND_COMMITMENT_KEY, seedHash, matchesWord(verification.CommitmentMatches)),
+		fmt.Sprintf("HMAC-SHA256(key = server_seed, message = \"%s:%d\") = %s", round.ClientSeed, round.Nonce, digest),
+		fmt.Sprintf("The first 16 hex characters %s divided by 2^64 give r; r below %.2f crashes instantly at %.2fx",
+			digest[:16], HOUSE_EDGE, MIN_MULTIPLIER),
+		fmt.Sprintf("Otherwise the multiplier is (100 - %.0f) / (100 - r * 100), rounded down to 2 decimals: %.2fx",
+			HOUSE_EDGE*100, computed),
+		fmt.Sprintf("The round claimed %.2fx, which the computed multiplier %s", round.CrashMultiplier, matchesWord(multiplierMatches)),
+	}
+	if round.BonusMultiplier > 0 {
+		verification.VerificationSteps = append(verification.VerificationSteps,
+			fmt.Sprintf("A bonus event added %.2fx after the fair crash point was fixed, so the round crashed at %.2fx",
+				round.BonusMultiplier, verification.FinalMultiplier))
+	}
+	return verification, true
+}
+
+func matchesWord(matches bool) string {
+	if matches {
+		return "matches"
+	}
+	return "does not match"
+}
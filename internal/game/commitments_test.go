@@ -0,0 +1,205 @@
+package game
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"aviator/internal/database"
+)
+
+// roundLog receives every round saved by the manager
+type roundLog chan database.Round
+
+func (l roundLog) SaveRound(ctx context.Context, round database.Round) error {
+	l <- round
+	return nil
+}
+
+func (l roundLog) SaveBets(ctx context.Context, bets []database.Bet) error { return nil }
+
+func TestManager_CommitmentLog(t *testing.T) {
+	m, _ := newTestManager(t)
+	saved := make(roundLog, 2)
+	m.rounds = saved
+	ctx := context.Background()
+
+	round := m.startNewRound()
+
+	commitments, err := m.RecentCommitments(ctx, 10)
+	if err != nil {
+		t.Fatalf("RecentCommitments() error: %v", err)
+	}
+	if len(commitments) != 1 {
+		t.Fatalf("RecentCommitments() returned %d entries, want 1", len(commitments))
+	}
+	if c := commitments[0]; c.RoundID != round.RoundID || c.HashCommitment != round.HashCommitment || c.ServerSeed != "" || c.Verified {
+		t.Errorf("published commitment = %+v, want an unrevealed entry for %s", c, round.RoundID)
+	}
+
+	m.stateMutex.Lock()
+	m.crashRound(round.RoundID, nil)
+	m.stateMutex.Unlock()
+
+	commitments, _ = m.RecentCommitments(ctx, 10)
+	if len(commitments) != 1 {
+		t.Fatalf("RecentCommitments() returned %d entries after the crash, want 1", len(commitments))
+	}
+	if c := commitments[0]; c.ServerSeed != round.ServerSeed || c.CrashMultiplier != round.CrashMultiplier || !c.Verified {
+		t.Errorf("revealed commitment = %+v, want the verified seed of %s", c, round.RoundID)
+	}
+
+	for _, want := range []struct {
+		status   string
+		verified bool
+	}{{"BETTING", false}, {"CRASHED", true}} {
+		select {
+		case r := <-saved:
+			if r.ID != round.RoundID || r.Status != want.status || r.Verified != want.verified {
+				t.Errorf("saved round = %+v, want %s with verified %v", r, want.status, want.verified)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("round was not saved as %s", want.status)
+		}
+	}
+}
+
+func TestManager_RecentCommitmentsNewestFirst(t *testing.T) {
+	m, _ := newTestManager(t)
+
+	var roundIDs []string
+	for i := 0; i < 3; i++ {
+		round := m.startNewRound()
+		roundIDs = append(roundIDs, round.RoundID)
+		m.stateMutex.Lock()
+		m.crashRound(round.RoundID, nil)
+		m.stateMutex.Unlock()
+		time.Sleep(2 * time.Millisecond) // Commitments are ordered by millisecond
+	}
+
+	commitments, err := m.RecentCommitments(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("RecentCommitments() error: %v", err)
+	}
+	if len(commitments) != 2 || commitments[0].RoundID != roundIDs[2] || commitments[1].RoundID != roundIDs[1] {
+		t.Errorf("RecentCommitments() = %+v, want the last two rounds newest first", commitments)
+	}
+}
+
+func TestManager_SeedRevealDelay(t *testing.T) {
+	m, _ := newTestManager(t)
+	m.seedRevealDelay = 200 * time.Millisecond
+	ctx := context.Background()
+
+	round := m.startNewRound()
+	<-m.hub.broadcast // round_start
+
+	m.stateMutex.Lock()
+	m.crashRound(round.RoundID, nil)
+	m.stateMutex.Unlock()
+	crashedAt := time.Now()
+
+	crash := (<-m.hub.broadcast).(map[string]interface{})
+	if crash["type"] != "crash" || crash["round_id"] != round.RoundID {
+		t.Fatalf("first message = %v, want the crash of %s", crash, round.RoundID)
+	}
+	if _, ok := crash["server_seed"]; ok {
+		t.Error("crash message should not carry the server seed")
+	}
+
+	commitments, _ := m.RecentCommitments(ctx, 1)
+	if len(commitments) != 1 || commitments[0].ServerSeed != "" || commitments[0].CrashedAt == nil {
+		t.Errorf("commitment before the reveal = %+v, want a crashed round without its seed", commitments)
+	}
+
+	select {
+	case message := <-m.hub.broadcast:
+		reveal := message.(map[string]interface{})
+		if reveal["type"] != "seed_reveal" || reveal["round_id"] != round.RoundID || reveal["server_seed"] != round.ServerSeed {
+			t.Errorf("second message = %v, want the seed reveal of %s", reveal, round.RoundID)
+		}
+		if elapsed := time.Since(crashedAt); elapsed < 150*time.Millisecond {
+			t.Errorf("seed revealed %v after the crash, want about %v", elapsed, m.seedRevealDelay)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("seed was never revealed")
+	}
+
+	commitments, _ = m.RecentCommitments(ctx, 1)
+	if len(commitments) != 1 || commitments[0].ServerSeed != round.ServerSeed {
+		t.Errorf("commitment after the reveal = %+v, want the seed of %s", commitments, round.RoundID)
+	}
+}
+
+func TestManager_SeedHiddenUntilReveal(t *testing.T) {
+	m, _ := newTestManager(t)
+	m.seedRevealDelay = time.Hour
+	m.countdownInterval = 10 * time.Millisecond
+	m.nextRoundDelay = 20 * time.Millisecond
+	ctx := context.Background()
+
+	round := m.startNewRound()
+	m.stateMutex.Lock()
+	m.crashRound(round.RoundID, nil)
+	m.stateMutex.Unlock()
+	m.runNextRoundCountdown(m.peekNextCommitment(round.RoundID))
+	m.startNewRound()
+
+	// Everything public before the reveal: broadcasts and the commitment log
+	var published []string
+	for len(m.hub.broadcast) > 0 {
+		for _, value := range (<-m.hub.broadcast).(map[string]interface{}) {
+			if s, ok := value.(string); ok {
+				published = append(published, s)
+			}
+		}
+	}
+	commitments, _ := m.RecentCommitments(ctx, 10)
+	for _, c := range commitments {
+		published = append(published, c.HashCommitment, c.ServerSeed)
+	}
+
+	for _, value := range published {
+		if value == round.ServerSeed || HashCommitment(value) == round.ServerSeed {
+			t.Fatalf("%q was published before the reveal and gives away the seed of %s", value, round.RoundID)
+		}
+	}
+}
+
+func TestManager_VerifyStoredRound(t *testing.T) {
+	m, _ := newTestManager(t)
+
+	crashedAt := time.Now().Add(-time.Second)
+	round := database.Round{
+		ID:              "R-stored",
+		ServerSeed:      "test_server_seed_123",
+		HashCommitment:  CommitRoundSeed("test_server_seed_123"),
+		ClientSeed:      "test_client_seed_456",
+		Nonce:           1,
+		CrashMultiplier: HashAndMapToMultiplier("test_server_seed_123", "test_client_seed_456", 1),
+		CrashedAt:       &crashedAt,
+	}
+
+	verification, revealed := m.VerifyStoredRound(round)
+	if !revealed || !verification.Verified || !verification.CommitmentMatches {
+		t.Fatalf("VerifyStoredRound() = %+v, %v; want a verified round", verification, revealed)
+	}
+	if len(verification.VerificationSteps) != 5 {
+		t.Errorf("got %d verification steps, want 5", len(verification.VerificationSteps))
+	}
+
+	tampered := round
+	tampered.CrashMultiplier += 1
+	if verification, _ := m.VerifyStoredRound(tampered); verification.Verified || !verification.CommitmentMatches {
+		t.Errorf("tampered multiplier: verification = %+v, want only the commitment to match", verification)
+	}
+
+	m.seedRevealDelay = time.Minute
+	if _, revealed := m.VerifyStoredRound(round); revealed {
+		t.Error("round verified before its reveal delay passed")
+	}
+	round.CrashedAt = nil
+	if _, revealed := m.VerifyStoredRound(round); revealed {
+		t.Error("round verified before it crashed")
+	}
+}
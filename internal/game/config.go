@@ -0,0 +1,254 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+func getEnvAsInt(key string, defaultVal int) int {
+	if val := os.Getenv(key); val != "" {
+		if intVal, err := strconv.Atoi(val); err == nil {
+			return intVal
+		}
+	}
+	return defaultVal
+}
+
+func getEnvAsFloat(key string, defaultVal float64) float64 {
+	if val := os.Getenv(key); val != "" {
+		if floatVal, err := strconv.ParseFloat(val, 64); err == nil {
+			return floatVal
+		}
+	}
+	return defaultVal
+}
+
+const HOUSE_EDGE_CACHE_TTL = 60 * time.Second
+
+// timeNow is replaced in tests to pin the schedule to a time slot
+var timeNow = time.Now
+
+// ScheduleEntry sets the house edge during a daily slot, in UTC. Hours run
+// from StartHour up to but excluding EndHour (24 for midnight). An entry with
+// no days applies every day and one with no game types applies to every game.
+type ScheduleEntry struct {
+	GameTypes []GameType `json:"game_types,omitempty"`
+	DayOfWeek []string   `json:"day_of_week"` // e.g. "saturday"
+	StartHour int        `json:"start_hour"`
+	EndHour   int        `json:"end_hour"`
+	HouseEdge float64    `json:"house_edge"` // e.g. 0.005 for 0.5%
+}
+
+// matches reports whether the entry covers gameType at t
+func (e ScheduleEntry) matches(gameType GameType, t time.Time) bool {
+	t = t.UTC()
+	if t.Hour() < e.StartHour || t.Hour() >= e.EndHour {
+		return false
+	}
+
+	if len(e.GameTypes) > 0 && !slices.Contains(e.GameTypes, gameType) {
+		return false
+	}
+
+	if len(e.DayOfWeek) == 0 {
+		return true
+	}
+	day := strings.ToLower(t.Weekday().String())
+	return slices.ContainsFunc(e.DayOfWeek, func(d string) bool { return strings.ToLower(d) == day })
+}
+
+func (e ScheduleEntry) validate() error {
+	if e.StartHour < 0 || e.EndHour > 24 || e.StartHour >= e.EndHour {
+		return fmt.Errorf("hours %d-%d must satisfy 0 <= start_hour < end_hour <= 24", e.StartHour, e.EndHour)
+	}
+	if e.HouseEdge < 0 || e.HouseEdge >= 1 {
+		return fmt.Errorf("house edge %v must be in [0, 1)", e.HouseEdge)
+	}
+	for _, day := range e.DayOfWeek {
+		if !slices.ContainsFunc(weekdays, func(d string) bool { return strings.EqualFold(d, day) }) {
+			return fmt.Errorf("unknown day %q", day)
+		}
+	}
+	return nil
+}
+
+var weekdays = []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"}
+
+// ScheduledGameTypes are the games whose payouts follow the scheduled house
+// edge. Aviator crash points are committed before betting opens and Plinko
+// pays from fixed tables, so both always use their default edge.
+var ScheduledGameTypes = []GameType{GameTypeDice, GameTypeMines}
+
+// DefaultHouseEdge is a game's house edge outside any scheduled promotion
+func DefaultHouseEdge(gameType GameType) float64 {
+	switch gameType {
+	case GameTypeAviator:
+		return HOUSE_EDGE
+	case GameTypeMines:
+		return MINES_HOUSE_EDGE
+	case GameTypeDice:
+		return DICE_HOUSE_EDGE
+	case GameTypePlinko:
+		return calculatePlinkoRTP().HouseEdgePct / 100
+	}
+	return 0
+}
+
+// HouseEdgeSchedule resolves the house edge of each game from a list of
+// scheduled promotions. The first matching entry wins.
+type HouseEdgeSchedule struct {
+	entries []ScheduleEntry
+
+	mu     sync.Mutex
+	cached map[GameType]cachedHouseEdge
+}
+
+type cachedHouseEdge struct {
+	edge    float64
+	expires time.Time
+}
+
+// NewHouseEdgeSchedule validates the entries and builds a schedule
+func NewHouseEdgeSchedule(entries []ScheduleEntry) (*HouseEdgeSchedule, error) {
+	for i, entry := range entries {
+		if err := entry.validate(); err != nil {
+			return nil, fmt.Errorf("schedule entry %d: %w", i, err)
+		}
+	}
+	return &HouseEdgeSchedule{
+		entries: entries,
+		cached:  make(map[GameType]cachedHouseEdge),
+	}, nil
+}
+
+// LoadHouseEdgeSchedule reads a JSON array of ScheduleEntry from path
+func LoadHouseEdgeSchedule(path string) (*HouseEdgeSchedule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []ScheduleEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return NewHouseEdgeSchedule(entries)
+}
+
+// loadHouseEdgeScheduleFromEnv loads HOUSE_EDGE_SCHEDULE_PATH, falling back to
+// an empty schedule when it is unset or invalid
+func loadHouseEdgeScheduleFromEnv() *HouseEdgeSchedule {
+	empty, _ := NewHouseEdgeSchedule(nil)
+
+	path := os.Getenv("HOUSE_EDGE_SCHEDULE_PATH")
+	if path == "" {
+		return empty
+	}
+
+	schedule, err := LoadHouseEdgeSchedule(path)
+	if err != nil {
+		log.Printf("[CONFIG] Ignoring house edge schedule: %v", err)
+		return empty
+	}
+	log.Printf("[CONFIG] Loaded %d house edge schedule entries from %s", len(schedule.entries), path)
+	return schedule
+}
+
+var houseEdgeSchedule = loadHouseEdgeScheduleFromEnv()
+
+// GetCurrentHouseEdge returns the house edge a new bet on gameType is settled with
+func GetCurrentHouseEdge(gameType GameType) float64 {
+	return houseEdgeSchedule.HouseEdge(gameType, timeNow())
+}
+
+// HouseEdge returns the edge for gameType at now, caching it for HOUSE_EDGE_CACHE_TTL
+func (s *HouseEdgeSchedule) HouseEdge(gameType GameType, now time.Time) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if cached, ok := s.cached[gameType]; ok && now.Before(cached.expires) {
+		return cached.edge
+	}
+
+	edge := s.lookup(gameType, now)
+	s.cached[gameType] = cachedHouseEdge{edge: edge, expires: now.Add(HOUSE_EDGE_CACHE_TTL)}
+	return edge
+}
+
+// lookup resolves the edge for gameType at t without the cache
+func (s *HouseEdgeSchedule) lookup(gameType GameType, t time.Time) float64 {
+	if slices.Contains(ScheduledGameTypes, gameType) {
+		for _, entry := range s.entries {
+			if entry.matches(gameType, t) {
+				return entry.HouseEdge
+			}
+		}
+	}
+	return DefaultHouseEdge(gameType)
+}
+
+// NextChange returns the next hour within a week at which any game's house
+// edge changes, or false if the schedule never changes it
+func (s *HouseEdgeSchedule) NextChange(now time.Time) (time.Time, bool) {
+	current := make(map[GameType]float64, len(ScheduledGameTypes))
+	for _, gameType := range ScheduledGameTypes {
+		current[gameType] = s.lookup(gameType, now)
+	}
+
+	t := now.UTC().Truncate(time.Hour)
+	for i := 0; i < 7*24; i++ {
+		t = t.Add(time.Hour)
+		for _, gameType := range ScheduledGameTypes {
+			if s.lookup(gameType, t) != current[gameType] {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// houseEdgesAt returns every scheduled game's edge at t, bypassing the cache
+func (s *HouseEdgeSchedule) houseEdgesAt(t time.Time) map[GameType]float64 {
+	edges := make(map[GameType]float64, len(ScheduledGameTypes))
+	for _, gameType := range ScheduledGameTypes {
+		edges[gameType] = s.lookup(gameType, t)
+	}
+	return edges
+}
+
+// ScheduledChange is the next point at which a promotion starts or ends
+type ScheduledChange struct {
+	At         time.Time            `json:"at"`
+	HouseEdges map[GameType]float64 `json:"house_edges"`
+}
+
+// Promotions describes the house edge every game is currently played at
+type Promotions struct {
+	HouseEdges map[GameType]float64 `json:"house_edges"`
+	NextChange *ScheduledChange     `json:"next_change"` // nil if the schedule is empty
+}
+
+// Promotions returns the edges at now and the next scheduled change
+func (s *HouseEdgeSchedule) Promotions(now time.Time) Promotions {
+	promotions := Promotions{HouseEdges: make(map[GameType]float64)}
+	for _, gameType := range []GameType{GameTypeAviator, GameTypeMines, GameTypePlinko, GameTypeDice} {
+		promotions.HouseEdges[gameType] = s.HouseEdge(gameType, now)
+	}
+
+	if at, ok := s.NextChange(now); ok {
+		promotions.NextChange = &ScheduledChange{At: at, HouseEdges: s.houseEdgesAt(at)}
+	}
+	return promotions
+}
+
+// CurrentPromotions returns the promotions of the schedule loaded from HOUSE_EDGE_SCHEDULE_PATH
+func CurrentPromotions() Promotions {
+	return houseEdgeSchedule.Promotions(timeNow())
+}
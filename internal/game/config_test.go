@@ -0,0 +1,124 @@
+package game
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// useHouseEdgeSchedule installs a schedule and pins timeNow for one test
+func useHouseEdgeSchedule(t *testing.T, entries []ScheduleEntry, now *time.Time) {
+	t.Helper()
+	schedule, err := NewHouseEdgeSchedule(entries)
+	if err != nil {
+		t.Fatalf("NewHouseEdgeSchedule() error = %v", err)
+	}
+
+	prevSchedule, prevNow := houseEdgeSchedule, timeNow
+	houseEdgeSchedule = schedule
+	timeNow = func() time.Time { return *now }
+	t.Cleanup(func() {
+		houseEdgeSchedule, timeNow = prevSchedule, prevNow
+	})
+}
+
+func TestGetCurrentHouseEdge(t *testing.T) {
+	// Saturday 2026-10-17, just before noon UTC
+	now := time.Date(2026, 10, 17, 11, 59, 30, 0, time.UTC)
+	useHouseEdgeSchedule(t, []ScheduleEntry{
+		{DayOfWeek: []string{"saturday", "sunday"}, StartHour: 12, EndHour: 14, HouseEdge: 0.005},
+		{GameTypes: []GameType{GameTypeMines}, StartHour: 12, EndHour: 24, HouseEdge: 0.02},
+	}, &now)
+
+	if got := GetCurrentHouseEdge(GameTypeDice); got != DICE_HOUSE_EDGE {
+		t.Errorf("dice edge before the promotion = %v, want %v", got, DICE_HOUSE_EDGE)
+	}
+
+	// The cached edge holds until it expires
+	now = now.Add(45 * time.Second)
+	if got := GetCurrentHouseEdge(GameTypeDice); got != DICE_HOUSE_EDGE {
+		t.Errorf("cached dice edge = %v, want %v", got, DICE_HOUSE_EDGE)
+	}
+
+	now = now.Add(HOUSE_EDGE_CACHE_TTL)
+	if got := GetCurrentHouseEdge(GameTypeDice); got != 0.005 {
+		t.Errorf("dice edge during the promotion = %v, want 0.005", got)
+	}
+	if got := GetCurrentHouseEdge(GameTypeMines); got != 0.005 {
+		t.Errorf("mines edge during overlapping promotions = %v, want the first entry's 0.005", got)
+	}
+	if got := GetCurrentHouseEdge(GameTypeAviator); got != HOUSE_EDGE {
+		t.Errorf("aviator edge = %v, want the fixed %v", got, HOUSE_EDGE)
+	}
+
+	promotions := CurrentPromotions()
+	if promotions.NextChange == nil {
+		t.Fatal("expected a next change")
+	}
+	wantAt := time.Date(2026, 10, 17, 14, 0, 0, 0, time.UTC)
+	if !promotions.NextChange.At.Equal(wantAt) {
+		t.Errorf("next change at %v, want %v", promotions.NextChange.At, wantAt)
+	}
+	if got := promotions.NextChange.HouseEdges[GameTypeMines]; got != 0.02 {
+		t.Errorf("mines edge after the next change = %v, want 0.02", got)
+	}
+	if got := promotions.NextChange.HouseEdges[GameTypeDice]; got != DICE_HOUSE_EDGE {
+		t.Errorf("dice edge after the next change = %v, want %v", got, DICE_HOUSE_EDGE)
+	}
+}
+
+func TestMinesEngine_ScheduledHouseEdge(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	useHouseEdgeSchedule(t, []ScheduleEntry{{StartHour: 12, EndHour: 13, HouseEdge: 0}}, &now)
+
+	engine, _ := newTestMinesEngine(t)
+	ctx := context.Background()
+	resp, _ := engine.PlaceBet(ctx, MinesBetRequest{UserID: "user1", Amount: 10, MineCount: 3})
+	gameState, err := engine.loadGame(ctx, resp.(MinesBetResponse).GameID)
+	if err != nil {
+		t.Fatalf("failed to load game: %v", err)
+	}
+
+	// The edge is fixed at bet time, so the promotion ending mid-game does not apply
+	now = now.Add(2 * time.Hour)
+	safeTile := 0
+	for slices.Contains(gameState.MinePositions, safeTile) {
+		safeTile++
+	}
+	engine.ProcessAction(ctx, "click", MinesClickRequest{UserID: "user1", GameID: gameState.GameID, TileID: safeTile})
+
+	clicked, _ := engine.loadGame(ctx, gameState.GameID)
+	want := engine.payoutWithEdge(amountOf(10), 3, 1, MINES_GRID_SIZE, 0)
+	if clicked.CurrentPayout != want {
+		t.Errorf("payout with no house edge = %v, want %v", clicked.CurrentPayout, want)
+	}
+	if standard := engine.calculatePayout(amountOf(10), 3, 1, MINES_GRID_SIZE); standard >= want {
+		t.Errorf("standard payout %v should be below the promotional %v", standard, want)
+	}
+}
+
+func TestLoadHouseEdgeSchedule(t *testing.T) {
+	dir := t.TempDir()
+
+	valid := filepath.Join(dir, "valid.json")
+	os.WriteFile(valid, []byte(`[{"day_of_week": ["Friday"], "start_hour": 18, "end_hour": 24, "house_edge": 0.005}]`), 0o644)
+	if _, err := LoadHouseEdgeSchedule(valid); err != nil {
+		t.Errorf("LoadHouseEdgeSchedule(valid) error = %v", err)
+	}
+
+	for name, contents := range map[string]string{
+		"bad hours": `[{"start_hour": 14, "end_hour": 12, "house_edge": 0.005}]`,
+		"bad day":   `[{"day_of_week": ["caturday"], "start_hour": 0, "end_hour": 24, "house_edge": 0.005}]`,
+		"bad edge":  `[{"start_hour": 0, "end_hour": 24, "house_edge": 1.5}]`,
+		"not json":  `{`,
+	} {
+		path := filepath.Join(dir, name+".json")
+		os.WriteFile(path, []byte(contents), 0o644)
+		if _, err := LoadHouseEdgeSchedule(path); err == nil {
+			t.Errorf("LoadHouseEdgeSchedule(%s) succeeded, want an error", name)
+		}
+	}
+}
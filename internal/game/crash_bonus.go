@@ -0,0 +1,85 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// REDIS_KEY_CRASH_BONUS_EVENT holds the active CrashBonusEvent until it expires
+	REDIS_KEY_CRASH_BONUS_EVENT = "crash:bonus_event"
+
+	CRASH_BONUS_MAX_MULTIPLIER   = 10.0
+	CRASH_BONUS_MAX_DURATION_MIN = 24 * 60
+)
+
+var ErrInvalidBonusEvent = errors.New("bonus_multiplier must be above 0 and at most 10, duration_minutes between 1 and 1440")
+
+// CrashBonusEvent adds BonusMultiplier to the crash point of every round that
+// starts before ActiveUntil. The provably fair crash point is unchanged; the
+// bonus is added on top and disclosed in the round's records.
+type CrashBonusEvent struct {
+	BonusMultiplier float64   `json:"bonus_multiplier"`
+	ActiveUntil     time.Time `json:"active_until"`
+}
+
+// StartBonusEvent starts a bonus event, replacing any active one
+func (m *Manager) StartBonusEvent(ctx context.Context, bonus float64, duration time.Duration) (*CrashBonusEvent, error) {
+	if bonus <= 0 || bonus > CRASH_BONUS_MAX_MULTIPLIER || duration < time.Minute || duration > CRASH_BONUS_MAX_DURATION_MIN*time.Minute {
+		return nil, ErrInvalidBonusEvent
+	}
+
+	event := &CrashBonusEvent{
+		BonusMultiplier: math.Round(bonus*100) / 100,
+		ActiveUntil:     time.Now().Add(duration).UTC(),
+	}
+	data, _ := json.Marshal(event)
+	if err := m.redisClient.Set(ctx, REDIS_KEY_CRASH_BONUS_EVENT, data, duration).Err(); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[BONUS] +%.2fx on every crash point until %s", event.BonusMultiplier, event.ActiveUntil.Format(time.RFC3339))
+	return event, nil
+}
+
+// ActiveBonusEvent returns the running bonus event, or nil if there is none
+func (m *Manager) ActiveBonusEvent(ctx context.Context) (*CrashBonusEvent, error) {
+	data, err := m.redisClient.Get(ctx, REDIS_KEY_CRASH_BONUS_EVENT).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var event CrashBonusEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// roundBonus returns the bonus event for a new round. A failed lookup is
+// logged and the round runs without a bonus.
+func (m *Manager) roundBonus() *CrashBonusEvent {
+	event, err := m.ActiveBonusEvent(m.ctx)
+	if err != nil {
+		log.Printf("[BONUS] Failed to load bonus event: %v", err)
+		return nil
+	}
+	return event
+}
+
+// BaseMultiplier is the provably fair crash point, before any bonus event
+func (r *RoundState) BaseMultiplier() float64 {
+	if r.BonusMultiplier == 0 {
+		return r.CrashMultiplier
+	}
+	return math.Round((r.CrashMultiplier-r.BonusMultiplier)*100) / 100
+}
@@ -0,0 +1,105 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestManager_CrashBonusEvent(t *testing.T) {
+	m, client := newTestManager(t)
+	ctx := context.Background()
+
+	if _, err := m.StartBonusEvent(ctx, 0.5, 30*time.Minute); err != nil {
+		t.Fatalf("StartBonusEvent() error: %v", err)
+	}
+	if ttl := client.TTL(ctx, REDIS_KEY_CRASH_BONUS_EVENT).Val(); ttl <= 0 || ttl > 30*time.Minute {
+		t.Errorf("bonus event TTL = %v, want it to expire with the event", ttl)
+	}
+
+	round := m.startNewRound()
+	base := HashAndMapToMultiplier(round.ServerSeed, round.ClientSeed, round.Nonce)
+	if round.BaseMultiplier() != base || round.BonusMultiplier != 0.5 {
+		t.Errorf("round base = %v, bonus = %v; want %v and 0.5", round.BaseMultiplier(), round.BonusMultiplier, base)
+	}
+	if want := base + 0.5; round.CrashMultiplier < want-0.001 || round.CrashMultiplier > want+0.001 {
+		t.Errorf("crash multiplier = %v, want %v", round.CrashMultiplier, want)
+	}
+
+	var bonusMsg map[string]interface{}
+	for len(m.hub.broadcast) > 0 {
+		if msg := (<-m.hub.broadcast).(map[string]interface{}); msg["type"] == "bonus_event" {
+			bonusMsg = msg
+		}
+	}
+	if bonusMsg == nil || bonusMsg["active"] != true || bonusMsg["extra_multiplier"] != 0.5 || bonusMsg["expires_at"] == nil {
+		t.Errorf("bonus_event broadcast = %v, want the active event", bonusMsg)
+	}
+
+	// The bonus sits on top of the provably fair crash point, which still verifies
+	m.stateMutex.Lock()
+	m.crashRound(round.RoundID, nil)
+	m.stateMutex.Unlock()
+
+	commitments, _ := m.RecentCommitments(ctx, 1)
+	if c := commitments[0]; !c.Verified || c.BaseMultiplier != base || c.CrashMultiplier != base || c.FinalMultiplier != round.CrashMultiplier {
+		t.Errorf("revealed commitment = %+v, want base %v and final %v", c, base, round.CrashMultiplier)
+	}
+
+	client.Del(ctx, REDIS_KEY_CRASH_BONUS_EVENT)
+	if next := m.startNewRound(); next.BonusMultiplier != 0 || next.CrashMultiplier != next.BaseMultiplier() {
+		t.Errorf("round after the event = %+v, want no bonus", next)
+	}
+}
+
+func TestManager_SideBetsIgnoreBonus(t *testing.T) {
+	m, client := newTestManager(t)
+	ctx := context.Background()
+	m.SetCrashPoint(9.5)
+	if _, err := m.StartBonusEvent(ctx, 1, 30*time.Minute); err != nil {
+		t.Fatalf("StartBonusEvent() error: %v", err)
+	}
+
+	round := m.startNewRound()
+	for user, prediction := range map[string]string{"user1": SideBet5xTo10x, "user2": SideBetOver10x} {
+		client.Set(ctx, REDIS_KEY_USER_BALANCE+user, 100.0, 0)
+		if resp, err := m.processSideBet(ctx, SideBetRequest{UserID: user, Amount: amountOf(10), Prediction: prediction}); err != nil || !resp.Success {
+			t.Fatalf("processSideBet(%s) = %+v, %v", prediction, resp, err)
+		}
+	}
+
+	// The round crashes at 10.5x, but side bets settle on the 9.5x base
+	m.stateMutex.Lock()
+	m.crashRound(round.RoundID, nil)
+	m.stateMutex.Unlock()
+
+	for user, want := range map[string]float64{"user1": 170, "user2": 90} {
+		if got, _ := client.Get(ctx, REDIS_KEY_USER_BALANCE+user).Float64(); got != want {
+			t.Errorf("%s balance = %v, want %v", user, got, want)
+		}
+	}
+}
+
+func TestManager_StartBonusEventValidation(t *testing.T) {
+	m, _ := newTestManager(t)
+
+	for _, tt := range []struct {
+		bonus    float64
+		duration time.Duration
+	}{
+		{0, time.Hour},
+		{-1, time.Hour},
+		{CRASH_BONUS_MAX_MULTIPLIER + 1, time.Hour},
+		{0.5, 0},
+		{0.5, 25 * time.Hour},
+	} {
+		if _, err := m.StartBonusEvent(context.Background(), tt.bonus, tt.duration); !errors.Is(err, ErrInvalidBonusEvent) {
+			t.Errorf("StartBonusEvent(%v, %v) error = %v, want %v", tt.bonus, tt.duration, err, ErrInvalidBonusEvent)
+		}
+	}
+
+	if event, err := m.ActiveBonusEvent(context.Background()); event != nil || err != nil {
+		t.Errorf("ActiveBonusEvent() = %v, %v; want none", event, err)
+	}
+}
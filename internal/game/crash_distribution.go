@@ -0,0 +1,302 @@
+package game
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+const (
+	// REDIS_KEY_CRASH_DISTRIBUTION caches an analysis, suffixed with its number of rounds
+	REDIS_KEY_CRASH_DISTRIBUTION   = "aviator:distribution:"
+	CRASH_DISTRIBUTION_CACHE_TTL   = 5 * time.Minute
+	CRASH_DISTRIBUTION_ROUNDS      = 1000
+	CRASH_DISTRIBUTION_MAX_ROUNDS  = 10000
+	CRASH_DISTRIBUTION_MIN_SAMPLE  = 100 // Fewer rounds can only WARN
+	CRASH_DISTRIBUTION_WARN_PVALUE = 0.05
+	CRASH_DISTRIBUTION_FAIL_PVALUE = 0.01
+)
+
+// Fairness scores of a crash point distribution
+const (
+	FairnessPass = "PASS"
+	FairnessWarn = "WARN"
+	FairnessFail = "FAIL"
+)
+
+// CrashDistributionPercentiles are crash points by nearest rank
+type CrashDistributionPercentiles struct {
+	P10 float64 `json:"p10"`
+	P25 float64 `json:"p25"`
+	P50 float64 `json:"p50"`
+	P75 float64 `json:"p75"`
+	P90 float64 `json:"p90"`
+	P95 float64 `json:"p95"`
+	P99 float64 `json:"p99"`
+}
+
+// CrashEdgeComparison is the house edge the crash points imply, measured as
+// in SimulateCrashOutcomes, against HOUSE_EDGE
+type CrashEdgeComparison struct {
+	Theoretical float64 `json:"theoretical"`
+	Observed    float64 `json:"observed"`
+	Difference  float64 `json:"difference"`
+}
+
+// UniformityTest is a Kolmogorov-Smirnov test that the rounds which did not
+// crash instantly follow HashAndMapToMultiplier, on a uniform scale
+type UniformityTest struct {
+	KSStatistic float64 `json:"ks_statistic"`
+	PValue      float64 `json:"p_value"`
+	Passed      bool    `json:"passed"`
+}
+
+// CrashDistribution describes the crash points of recent rounds and how well
+// they fit the distribution HashAndMapToMultiplier draws from. Kurtosis is
+// excess kurtosis, 0 for a normal distribution.
+type CrashDistribution struct {
+	SampleSize                int                          `json:"sample_size"`
+	Min                       float64                      `json:"min"`
+	Max                       float64                      `json:"max"`
+	Mean                      float64                      `json:"mean"`
+	StdDev                    float64                      `json:"std_dev"`
+	Skewness                  float64                      `json:"skewness"`
+	Kurtosis                  float64                      `json:"kurtosis"`
+	Percentiles               CrashDistributionPercentiles `json:"percentiles"`
+	TheoreticalVsObservedEdge CrashEdgeComparison          `json:"theoretical_vs_observed_edge"`
+	ChiSquaredStat            float64                      `json:"chi_squared_stat"`
+	ChiSquaredPValue          float64                      `json:"chi_squared_p_value"`
+	UniformDistributionTest   UniformityTest               `json:"uniform_distribution_test"`
+	FairnessScore             string                       `json:"fairness_score"`
+}
+
+// crashSurvival is the chance HashAndMapToMultiplier returns at least
+// multiplier: every round reaches 1x, then (1 - HOUSE_EDGE) / multiplier
+func crashSurvival(multiplier float64) float64 {
+	if multiplier <= MIN_MULTIPLIER {
+		return 1
+	}
+	return (1 - HOUSE_EDGE) / multiplier
+}
+
+// AnalyzeCrashDistribution summarizes crash points and tests them against
+// the theoretical distribution. The score is FAIL when the chi-squared
+// p-value is below 0.01, WARN below 0.05 or with too few rounds to tell.
+func AnalyzeCrashDistribution(crashPoints []float64) CrashDistribution {
+	dist := CrashDistribution{
+		SampleSize:    len(crashPoints),
+		FairnessScore: FairnessWarn,
+		TheoreticalVsObservedEdge: CrashEdgeComparison{
+			Theoretical: HOUSE_EDGE,
+		},
+	}
+	if len(crashPoints) == 0 {
+		return dist
+	}
+
+	sorted := slices.Clone(crashPoints)
+	slices.Sort(sorted)
+	dist.Min = sorted[0]
+	dist.Max = sorted[len(sorted)-1]
+	dist.Percentiles = CrashDistributionPercentiles{
+		P10: crashPercentile(sorted, 0.1),
+		P25: crashPercentile(sorted, 0.25),
+		P50: crashPercentile(sorted, 0.5),
+		P75: crashPercentile(sorted, 0.75),
+		P90: crashPercentile(sorted, 0.9),
+		P95: crashPercentile(sorted, 0.95),
+		P99: crashPercentile(sorted, 0.99),
+	}
+
+	mean, stdDev, skewness, kurtosis := moments(sorted)
+	dist.Mean = roundTo(mean, 4)
+	dist.StdDev = roundTo(stdDev, 4)
+	dist.Skewness = roundTo(skewness, 4)
+	dist.Kurtosis = roundTo(kurtosis, 4)
+
+	reached, _ := slices.BinarySearch(sorted, CRASH_OUTCOMES_EDGE_TARGET)
+	returned := CRASH_OUTCOMES_EDGE_TARGET * float64(len(sorted)-reached) / float64(len(sorted))
+	dist.TheoreticalVsObservedEdge.Observed = roundTo(1-returned, 6)
+	dist.TheoreticalVsObservedEdge.Difference = roundTo(dist.TheoreticalVsObservedEdge.Observed-HOUSE_EDGE, 6)
+
+	stat, df := crashChiSquared(sorted)
+	dist.ChiSquaredStat = roundTo(stat, 4)
+	dist.ChiSquaredPValue = roundTo(chiSquaredPValue(stat, df), 6)
+	dist.UniformDistributionTest = uniformityTest(sorted[reached:])
+
+	switch {
+	case dist.SampleSize < CRASH_DISTRIBUTION_MIN_SAMPLE:
+		dist.FairnessScore = FairnessWarn
+	case dist.ChiSquaredPValue < CRASH_DISTRIBUTION_FAIL_PVALUE:
+		dist.FairnessScore = FairnessFail
+	case dist.ChiSquaredPValue < CRASH_DISTRIBUTION_WARN_PVALUE:
+		dist.FairnessScore = FairnessWarn
+	default:
+		dist.FairnessScore = FairnessPass
+	}
+	return dist
+}
+
+// moments returns the mean, sample standard deviation, skewness and excess
+// kurtosis of values
+func moments(values []float64) (mean, stdDev, skewness, kurtosis float64) {
+	n := float64(len(values))
+	for _, v := range values {
+		mean += v
+	}
+	mean /= n
+
+	var m2, m3, m4 float64
+	for _, v := range values {
+		d := v - mean
+		m2 += d * d
+		m3 += d * d * d
+		m4 += d * d * d * d
+	}
+	if len(values) > 1 {
+		stdDev = math.Sqrt(m2 / (n - 1))
+	}
+	if m2 > 0 {
+		m2, m3, m4 = m2/n, m3/n, m4/n
+		skewness = m3 / math.Pow(m2, 1.5)
+		kurtosis = m4/(m2*m2) - 3
+	}
+	return mean, stdDev, skewness, kurtosis
+}
+
+// crashChiSquared compares how many sorted crash points fall in each of
+// crashOutcomeBuckets with the count crashSurvival predicts. It returns the
+// statistic and its degrees of freedom.
+func crashChiSquared(sorted []float64) (float64, int) {
+	n := float64(len(sorted))
+	stat := 0.0
+	for i, lower := range crashOutcomeBuckets {
+		from, _ := slices.BinarySearch(sorted, lower)
+		to := len(sorted)
+		expected := crashSurvival(lower)
+		if i+1 < len(crashOutcomeBuckets) {
+			upper := crashOutcomeBuckets[i+1]
+			to, _ = slices.BinarySearch(sorted, upper)
+			expected -= crashSurvival(upper)
+		}
+		expected *= n
+		d := float64(to-from) - expected
+		stat += d * d / expected
+	}
+	return stat, len(crashOutcomeBuckets) - 1
+}
+
+// chiSquaredPValue is the chance of a chi-squared statistic at least stat
+// with df degrees of freedom: the upper regularized gamma Q(df/2, stat/2)
+func chiSquaredPValue(stat float64, df int) float64 {
+	if stat <= 0 {
+		return 1
+	}
+	return upperRegularizedGamma(float64(df)/2, stat/2)
+}
+
+// upperRegularizedGamma computes Q(a, x) by its series below a + 1 and its
+// continued fraction above, as in Numerical Recipes
+func upperRegularizedGamma(a, x float64) float64 {
+	const (
+		maxIterations = 500
+		epsilon       = 1e-14
+		tiny          = 1e-300
+	)
+	lgamma, _ := math.Lgamma(a)
+	prefix := math.Exp(-x + a*math.Log(x) - lgamma)
+
+	if x < a+1 {
+		term := 1 / a
+		sum := term
+		for n := 1; n < maxIterations; n++ {
+			term *= x / (a + float64(n))
+			sum += term
+			if math.Abs(term) < math.Abs(sum)*epsilon {
+				break
+			}
+		}
+		return math.Max(0, 1-sum*prefix)
+	}
+
+	// Lentz's method
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for n := 1; n < maxIterations; n++ {
+		an := -float64(n) * (float64(n) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < epsilon {
+			break
+		}
+	}
+	return h * prefix
+}
+
+// uniformityTest runs a Kolmogorov-Smirnov test on sorted crash points, all
+// above the instant crash. A round that reached the lowest such crash point
+// reaches x with chance lowest/x, so lowest/x is uniform on (0, 1]. Crash
+// points are cut to cents, so both sides of each step of that CDF are checked.
+func uniformityTest(sorted []float64) UniformityTest {
+	if len(sorted) == 0 {
+		return UniformityTest{PValue: 1, Passed: true}
+	}
+
+	// u = lowest/x falls as x rises, so walking sorted backwards visits u ascending
+	const lowest = MIN_MULTIPLIER + 0.01
+	n := float64(len(sorted))
+	d := 0.0
+	for i := 0; i < len(sorted); {
+		x := sorted[len(sorted)-1-i]
+		j := i
+		for j < len(sorted) && sorted[len(sorted)-1-j] == x {
+			j++
+		}
+		atStep := lowest / x
+		belowStep := lowest / (x + 0.01)
+		d = math.Max(d, math.Max(math.Abs(float64(j)/n-atStep), math.Abs(float64(i)/n-belowStep)))
+		i = j
+	}
+
+	pValue := kolmogorovPValue((math.Sqrt(n) + 0.12 + 0.11/math.Sqrt(n)) * d)
+	return UniformityTest{
+		KSStatistic: roundTo(d, 6),
+		PValue:      roundTo(pValue, 6),
+		Passed:      pValue >= CRASH_DISTRIBUTION_WARN_PVALUE,
+	}
+}
+
+// kolmogorovPValue is the chance the Kolmogorov distribution exceeds lambda
+func kolmogorovPValue(lambda float64) float64 {
+	if lambda < 0.2 {
+		return 1
+	}
+	sum := 0.0
+	sign := 1.0
+	for k := 1; k <= 100; k++ {
+		term := sign * math.Exp(-2*float64(k*k)*lambda*lambda)
+		sum += term
+		if math.Abs(term) < 1e-12 {
+			break
+		}
+		sign = -sign
+	}
+	return math.Min(1, math.Max(0, 2*sum))
+}
+
+func roundTo(value float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(value*scale) / scale
+}
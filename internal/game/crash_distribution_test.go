@@ -0,0 +1,100 @@
+package game
+
+import (
+	"math"
+	"testing"
+)
+
+// bucketSample returns crash points with the given counts in each of
+// crashOutcomeBuckets, each at the bucket's lower bound
+func bucketSample(counts []int) []float64 {
+	var sample []float64
+	for i, count := range counts {
+		for range count {
+			sample = append(sample, crashOutcomeBuckets[i])
+		}
+	}
+	return sample
+}
+
+func TestCrashChiSquared(t *testing.T) {
+	// 1000 rounds in exactly the theoretical proportions: 34%, 16.5%, 16.5%, 13.2%, 9.9% and 9.9%
+	expected := []int{340, 165, 165, 132, 99, 99}
+	stat, df := crashChiSquared(bucketSample(expected))
+	if math.Abs(stat) > 1e-9 || df != 5 {
+		t.Errorf("crashChiSquared() of the expected counts = %v, %d; want 0 with 5 degrees of freedom", stat, df)
+	}
+
+	// Move 30 instant-ish crashes to 10x+: 30²/340 + 30²/99
+	shifted := []int{310, 165, 165, 132, 99, 129}
+	stat, _ = crashChiSquared(bucketSample(shifted))
+	if want := 900.0/340 + 900.0/99; math.Abs(stat-want) > 1e-9 {
+		t.Errorf("crashChiSquared() = %v, want %v", stat, want)
+	}
+
+	dist := AnalyzeCrashDistribution(bucketSample(shifted))
+	if dist.ChiSquaredStat != 11.7380 || math.Abs(dist.ChiSquaredPValue-0.0386) > 0.0005 || dist.FairnessScore != FairnessWarn {
+		t.Errorf("AnalyzeCrashDistribution() = %+v, want 11.738 with p of 0.0386 and WARN", dist)
+	}
+}
+
+func TestChiSquaredPValue(t *testing.T) {
+	// Critical values from chi-squared tables
+	tests := []struct {
+		stat float64
+		df   int
+		want float64
+	}{
+		{11.0705, 5, 0.05},
+		{15.0863, 5, 0.01},
+		{3.8415, 1, 0.05},
+		{1.6103, 5, 0.90},
+		{0, 5, 1},
+	}
+	for _, tt := range tests {
+		if got := chiSquaredPValue(tt.stat, tt.df); math.Abs(got-tt.want) > 1e-4 {
+			t.Errorf("chiSquaredPValue(%v, %d) = %v, want %v", tt.stat, tt.df, got, tt.want)
+		}
+	}
+}
+
+func TestMoments(t *testing.T) {
+	mean, stdDev, skewness, kurtosis := moments([]float64{1, 2, 3, 4})
+	if mean != 2.5 || math.Abs(stdDev-1.290994) > 1e-6 || math.Abs(skewness) > 1e-12 || math.Abs(kurtosis+1.36) > 1e-12 {
+		t.Errorf("moments() = %v, %v, %v, %v; want 2.5, 1.291, 0, -1.36", mean, stdDev, skewness, kurtosis)
+	}
+}
+
+func TestAnalyzeCrashDistribution(t *testing.T) {
+	crashPoints := make([]float64, 10000)
+	for i := range crashPoints {
+		crashPoints[i] = HashAndMapToMultiplier("distribution-server", "distribution-client", i+1)
+	}
+
+	dist := AnalyzeCrashDistribution(crashPoints)
+	if dist.SampleSize != 10000 || dist.Min != 1 || dist.FairnessScore != FairnessPass || !dist.UniformDistributionTest.Passed {
+		t.Errorf("AnalyzeCrashDistribution() of real crash points = %+v, want PASS", dist)
+	}
+	if p50 := dist.Percentiles.P50; p50 < 1.9 || p50 > 2.06 {
+		t.Errorf("median = %v, want about 1.98", p50)
+	}
+	if edge := dist.TheoreticalVsObservedEdge; edge.Theoretical != HOUSE_EDGE || math.Abs(edge.Difference) > 0.02 {
+		t.Errorf("edge = %+v, want close to %v", edge, HOUSE_EDGE)
+	}
+
+	// Rounds that never crash below 2x cannot come from HashAndMapToMultiplier
+	rigged := make([]float64, len(crashPoints))
+	for i, crashPoint := range crashPoints {
+		rigged[i] = max(crashPoint, 2)
+	}
+	if dist := AnalyzeCrashDistribution(rigged); dist.FairnessScore != FairnessFail || dist.UniformDistributionTest.Passed {
+		t.Errorf("AnalyzeCrashDistribution() of rigged crash points = %+v, want FAIL", dist)
+	}
+
+	if dist := AnalyzeCrashDistribution(crashPoints[:50]); dist.FairnessScore != FairnessWarn {
+		t.Errorf("AnalyzeCrashDistribution() of 50 rounds = %s, want WARN", dist.FairnessScore)
+	}
+	if dist := AnalyzeCrashDistribution(nil); dist.SampleSize != 0 || dist.FairnessScore != FairnessWarn {
+		t.Errorf("AnalyzeCrashDistribution() of no rounds = %+v, want WARN", dist)
+	}
+}
@@ -0,0 +1,56 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+const MAX_EARLY_EXIT_FEE_PCT = 5.0
+
+// validateEarlyExitFee returns a message for the player if pct is out of range
+func validateEarlyExitFee(pct float64) string {
+	if pct < 0 || pct > MAX_EARLY_EXIT_FEE_PCT {
+		return fmt.Sprintf("Early exit fee must be between 0 and %.1f%%", MAX_EARLY_EXIT_FEE_PCT)
+	}
+	return ""
+}
+
+// earlyExitFee is the fee of pct percent on a bet of amount
+func earlyExitFee(amount Amount, pct float64) Amount {
+	return amount.Mul(pct / 100)
+}
+
+// earlyExitFeePaid reports whether the bet being cashed out belongs to the
+// user and paid the early exit fee. Any other cashout goes through the queue.
+func (m *Manager) earlyExitFeePaid(ctx context.Context, req CashoutRequest) bool {
+	round := m.GetCurrentRound()
+	if round == nil {
+		return false
+	}
+
+	betJSON, err := m.redisClient.HGet(ctx, REDIS_KEY_ACTIVE_BETS+round.RoundID, req.BetID).Result()
+	if err != nil {
+		return false
+	}
+	var bet ActiveBet
+	if json.Unmarshal([]byte(betJSON), &bet) != nil {
+		return false
+	}
+	return bet.UserID == req.UserID && bet.EarlyExitFeePaid
+}
+
+// cashoutEarlyExit cashes out a bet in the caller's goroutine instead of the
+// game loop, so it is not subject to CASHOUT_TIMEOUT. The round cannot crash
+// or tick auto-cashouts while the state lock is held.
+func (m *Manager) cashoutEarlyExit(ctx context.Context, req CashoutRequest) (CashoutResponse, error) {
+	m.earlyExitMu.Lock()
+	defer m.earlyExitMu.Unlock()
+
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+	if m.currentRound == nil || m.currentRound.Status != "RUNNING" {
+		return CashoutResponse{Message: "Cannot cashout now"}, nil
+	}
+	return m.settleCashout(ctx, req, m.currentRound.RoundID, m.currentRound.CurrentMultiplier)
+}
@@ -0,0 +1,58 @@
+package game
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestManager_EarlyExitFee(t *testing.T) {
+	m, client := newTestManager(t)
+	ctx := context.Background()
+	m.setTestRound("R-early", "BETTING")
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"user1", 100.0, 0)
+
+	resp, err := m.processBet(ctx, BetRequest{UserID: "user1", Amount: amountOf(10), EarlyExitFeePct: 2.5})
+	if err != nil || !resp.Success {
+		t.Fatalf("processBet() = %+v, %v", resp, err)
+	}
+	if resp.EarlyExitFeeCharged != 0.25 || resp.Balance != 89.75 {
+		t.Errorf("processBet() charged %v leaving %v, want 0.25 leaving 89.75", resp.EarlyExitFeeCharged, resp.Balance)
+	}
+	if bet := m.loadActiveBets("R-early")[resp.BetID]; !bet.EarlyExitFeePaid || bet.Amount != amountOf(10) {
+		t.Errorf("stored bet = %+v, want a 10.00 bet that paid the fee", bet)
+	}
+	plain := placeTestBet(t, m, "user1", 10, 0)
+
+	for _, pct := range []float64{-1, MAX_EARLY_EXIT_FEE_PCT + 0.1} {
+		if resp, _ := m.processBet(ctx, BetRequest{UserID: "user1", Amount: amountOf(10), EarlyExitFeePct: pct}); resp.Success {
+			t.Errorf("processBet() with a %v%% fee succeeded, want it rejected", pct)
+		}
+	}
+
+	// No game loop is reading the cashout channel, so only the bet that paid
+	// the fee can cash out
+	m.stateMutex.Lock()
+	m.currentRound.Status = "RUNNING"
+	m.currentRound.CurrentMultiplier = 2
+	m.stateMutex.Unlock()
+
+	start := time.Now()
+	cashout, err := m.Cashout(CashoutRequest{UserID: "user1", BetID: resp.BetID})
+	if err != nil || !cashout.Success || cashout.Payout != amountOf(20) {
+		t.Fatalf("Cashout() of the fee-paid bet = %+v, %v; want a 20.00 payout", cashout, err)
+	}
+	if elapsed := time.Since(start); elapsed >= CASHOUT_TIMEOUT {
+		t.Errorf("Cashout() of the fee-paid bet took %v, want it to skip the queue", elapsed)
+	}
+	if balance, _ := client.Get(ctx, REDIS_KEY_USER_BALANCE+"user1").Float64(); balance != 99.75 {
+		t.Errorf("balance = %v, want 99.75 with the fee kept", balance)
+	}
+	if again, _ := m.Cashout(CashoutRequest{UserID: "user1", BetID: resp.BetID}); again.Success {
+		t.Errorf("second Cashout() = %+v, want it rejected", again)
+	}
+
+	if queued, _ := m.Cashout(CashoutRequest{UserID: "user1", BetID: plain}); queued.Message != "Cashout timeout" {
+		t.Errorf("Cashout() of a bet without the fee = %+v, want it to wait on the queue", queued)
+	}
+}
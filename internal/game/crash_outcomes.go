@@ -0,0 +1,164 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"slices"
+	"time"
+)
+
+const (
+	// REDIS_KEY_CRASH_OUTCOMES caches a simulation, suffixed with its size
+	REDIS_KEY_CRASH_OUTCOMES   = "aviator:outcomes:"
+	CRASH_OUTCOMES_CACHE_TTL   = 60 * time.Second
+	CRASH_OUTCOMES_MAX         = 1000000
+	CRASH_OUTCOMES_TIMEOUT     = 5 * time.Second
+	CRASH_OUTCOMES_CHECK_EVERY = 10000 // Rounds simulated between checks for the timeout
+
+	// Every cashout target returns 1 - HOUSE_EDGE on average. The lowest
+	// target is reached most often, so it measures the edge with the least noise.
+	CRASH_OUTCOMES_EDGE_TARGET = 1.01
+)
+
+var (
+	ErrInvalidSimulationCount = fmt.Errorf("simulations must be between 1 and %d", CRASH_OUTCOMES_MAX)
+	ErrSimulationTimeout      = errors.New("simulation timed out, try fewer simulations")
+)
+
+// crashOutcomeBuckets are the lower bounds of the distribution buckets; the
+// last is open-ended
+var crashOutcomeBuckets = []float64{1, 1.5, 2, 3, 5, 10}
+
+// CrashOutcomeBucket counts the simulated crash points within one range
+type CrashOutcomeBucket struct {
+	BucketLabel string  `json:"bucket_label"`
+	Count       int     `json:"count"`
+	Pct         float64 `json:"pct"`
+}
+
+// CrashOutcomePercentiles are crash points by nearest rank
+type CrashOutcomePercentiles struct {
+	P50  float64 `json:"p50"`
+	P75  float64 `json:"p75"`
+	P90  float64 `json:"p90"`
+	P95  float64 `json:"p95"`
+	P99  float64 `json:"p99"`
+	P999 float64 `json:"p999"`
+}
+
+// CrashOutcomeStats summarizes simulated crash points. No bets are involved.
+type CrashOutcomeStats struct {
+	Simulations       int                     `json:"simulations"`
+	Min               float64                 `json:"min"`
+	Max               float64                 `json:"max"`
+	Mean              float64                 `json:"mean"`
+	Median            float64                 `json:"median"`
+	Percentiles       CrashOutcomePercentiles `json:"percentiles"`
+	Distribution      []CrashOutcomeBucket    `json:"distribution"`
+	Under2xPct        float64                 `json:"under_2x_pct"`
+	HouseEdgeObserved float64                 `json:"house_edge_observed"`
+}
+
+// SimulateCrashOutcomes draws crash points with HashAndMapToMultiplier from a
+// random server seed, as real rounds do, and summarizes them. It gives up
+// with ErrSimulationTimeout after CRASH_OUTCOMES_TIMEOUT.
+func SimulateCrashOutcomes(ctx context.Context, simulations int) (*CrashOutcomeStats, error) {
+	if simulations < 1 || simulations > CRASH_OUTCOMES_MAX {
+		return nil, ErrInvalidSimulationCount
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, CRASH_OUTCOMES_TIMEOUT)
+	defer cancel()
+
+	type result struct {
+		stats *CrashOutcomeStats
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		stats, err := simulateCrashOutcomes(ctx, GenerateSeed(), GenerateSeed(), simulations)
+		done <- result{stats, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.stats, r.err
+	case <-ctx.Done():
+		return nil, ErrSimulationTimeout
+	}
+}
+
+func simulateCrashOutcomes(ctx context.Context, serverSeed, clientSeed string, simulations int) (*CrashOutcomeStats, error) {
+	crashPoints := make([]float64, simulations)
+	sum := new(big.Float)
+	reachedEdgeTarget := 0
+	for i := range crashPoints {
+		if i%CRASH_OUTCOMES_CHECK_EVERY == 0 && ctx.Err() != nil {
+			return nil, ErrSimulationTimeout
+		}
+		crashPoint := HashAndMapToMultiplier(serverSeed, clientSeed, i+1)
+		crashPoints[i] = crashPoint
+		sum.Add(sum, big.NewFloat(crashPoint))
+		if crashPoint >= CRASH_OUTCOMES_EDGE_TARGET {
+			reachedEdgeTarget++
+		}
+	}
+	slices.Sort(crashPoints)
+
+	n := big.NewFloat(float64(simulations))
+	mean, _ := new(big.Float).Quo(sum, n).Float64()
+	// Return per unit bet of always cashing out at CRASH_OUTCOMES_EDGE_TARGET
+	returned := new(big.Float).Mul(big.NewFloat(CRASH_OUTCOMES_EDGE_TARGET), big.NewFloat(float64(reachedEdgeTarget)))
+	returnedFraction, _ := returned.Quo(returned, n).Float64()
+
+	stats := &CrashOutcomeStats{
+		Simulations: simulations,
+		Min:         crashPoints[0],
+		Max:         crashPoints[simulations-1],
+		Mean:        math.Round(mean*100) / 100,
+		Median:      crashPercentile(crashPoints, 0.5),
+		Percentiles: CrashOutcomePercentiles{
+			P50:  crashPercentile(crashPoints, 0.5),
+			P75:  crashPercentile(crashPoints, 0.75),
+			P90:  crashPercentile(crashPoints, 0.9),
+			P95:  crashPercentile(crashPoints, 0.95),
+			P99:  crashPercentile(crashPoints, 0.99),
+			P999: crashPercentile(crashPoints, 0.999),
+		},
+		Distribution:      crashOutcomeDistribution(crashPoints),
+		HouseEdgeObserved: math.Round((1-returnedFraction)*1e6) / 1e6,
+	}
+	under2x, _ := slices.BinarySearch(crashPoints, 2)
+	stats.Under2xPct = outcomePct(under2x, simulations)
+	return stats, nil
+}
+
+// crashPercentile returns the nearest-rank percentile p of sorted crash points
+func crashPercentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p * float64(len(sorted))))
+	return sorted[max(rank, 1)-1]
+}
+
+// crashOutcomeDistribution counts sorted crash points into crashOutcomeBuckets
+func crashOutcomeDistribution(sorted []float64) []CrashOutcomeBucket {
+	buckets := make([]CrashOutcomeBucket, len(crashOutcomeBuckets))
+	for i, lower := range crashOutcomeBuckets {
+		from, _ := slices.BinarySearch(sorted, lower)
+		to := len(sorted)
+		label := fmt.Sprintf("%gx+", lower)
+		if i+1 < len(crashOutcomeBuckets) {
+			upper := crashOutcomeBuckets[i+1]
+			to, _ = slices.BinarySearch(sorted, upper)
+			label = fmt.Sprintf("%gx-%gx", lower, upper)
+		}
+		buckets[i] = CrashOutcomeBucket{BucketLabel: label, Count: to - from, Pct: outcomePct(to-from, len(sorted))}
+	}
+	return buckets
+}
+
+func outcomePct(count, total int) float64 {
+	return math.Round(float64(count)/float64(total)*10000) / 100
+}
@@ -0,0 +1,60 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestSimulateCrashOutcomes(t *testing.T) {
+	stats, err := SimulateCrashOutcomes(context.Background(), 100000)
+	if err != nil {
+		t.Fatalf("simulation error: %v", err)
+	}
+
+	if math.Abs(stats.HouseEdgeObserved-HOUSE_EDGE) > 0.005 {
+		t.Errorf("observed house edge = %v, want within 0.5%% of %v", stats.HouseEdgeObserved, HOUSE_EDGE)
+	}
+	if stats.Simulations != 100000 || stats.Min != MIN_MULTIPLIER || stats.Max < stats.Percentiles.P999 {
+		t.Errorf("stats = %+v, want 100000 simulations from 1x", stats)
+	}
+	p := stats.Percentiles
+	if stats.Median != p.P50 || !(p.P50 <= p.P75 && p.P75 <= p.P90 && p.P90 <= p.P95 && p.P95 <= p.P99 && p.P99 <= p.P999) {
+		t.Errorf("percentiles = %+v (median %v), want them in order", p, stats.Median)
+	}
+
+	// Half of all rounds crash below (1 - HOUSE_EDGE) * 2x
+	if math.Abs(stats.Under2xPct-50.5) > 1 {
+		t.Errorf("under 2x = %v%%, want about 50.5%%", stats.Under2xPct)
+	}
+
+	wantLabels := []string{"1x-1.5x", "1.5x-2x", "2x-3x", "3x-5x", "5x-10x", "10x+"}
+	total := 0
+	for i, bucket := range stats.Distribution {
+		if bucket.BucketLabel != wantLabels[i] {
+			t.Errorf("bucket %d label = %q, want %q", i, bucket.BucketLabel, wantLabels[i])
+		}
+		total += bucket.Count
+	}
+	if len(stats.Distribution) != len(wantLabels) || total != stats.Simulations {
+		t.Errorf("distribution = %+v, want %d buckets counting every round", stats.Distribution, len(wantLabels))
+	}
+	if under2x := stats.Distribution[0].Pct + stats.Distribution[1].Pct; math.Abs(under2x-stats.Under2xPct) > 0.02 {
+		t.Errorf("buckets below 2x hold %v%%, want %v%%", under2x, stats.Under2xPct)
+	}
+}
+
+func TestSimulateCrashOutcomes_Limits(t *testing.T) {
+	for _, n := range []int{0, CRASH_OUTCOMES_MAX + 1} {
+		if _, err := SimulateCrashOutcomes(context.Background(), n); !errors.Is(err, ErrInvalidSimulationCount) {
+			t.Errorf("%d simulations: err = %v, want ErrInvalidSimulationCount", n, err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := SimulateCrashOutcomes(ctx, 1000); !errors.Is(err, ErrSimulationTimeout) {
+		t.Errorf("cancelled simulation: err = %v, want ErrSimulationTimeout", err)
+	}
+}
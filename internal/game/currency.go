@@ -0,0 +1,90 @@
+package game
+
+import (
+	"os"
+	"strconv"
+)
+
+const (
+	CURRENCY_CODE         = "credits"
+	CURRENCY_DECIMALS     = 2
+	CURRENCY_MAX_DECIMALS = 8 // Enough for BTC
+)
+
+// Currency is how an operator displays the single internal currency unit.
+// Game logic never reads it; it is only attached to responses.
+type Currency struct {
+	CurrencySymbol string `json:"currency_symbol"`
+	CurrencyCode   string `json:"currency_code"`
+}
+
+// FormattedAmount is an amount ready for display
+type FormattedAmount struct {
+	Value     float64 `json:"value"`
+	Symbol    string  `json:"symbol"`
+	Code      string  `json:"code"`
+	Formatted string  `json:"formatted"` // e.g. "$123.45" or "0.00001234 BTC"
+}
+
+// DisplayCurrency returns the currency set by CURRENCY_SYMBOL and CURRENCY_CODE
+func DisplayCurrency() Currency {
+	code := os.Getenv("CURRENCY_CODE")
+	if code == "" {
+		code = CURRENCY_CODE
+	}
+	return Currency{CurrencySymbol: os.Getenv("CURRENCY_SYMBOL"), CurrencyCode: code}
+}
+
+// currencyDecimals returns CURRENCY_DECIMALS, limited to 0-CURRENCY_MAX_DECIMALS
+func currencyDecimals() int {
+	decimals := getEnvAsInt("CURRENCY_DECIMALS", CURRENCY_DECIMALS)
+	return min(max(decimals, 0), CURRENCY_MAX_DECIMALS)
+}
+
+// FormatAmount formats amount in the display currency, prefixed with its
+// symbol or, when there is none, followed by its code
+func FormatAmount(amount float64) FormattedAmount {
+	currency := DisplayCurrency()
+	value := strconv.FormatFloat(amount, 'f', currencyDecimals(), 64)
+
+	formatted := value + " " + currency.CurrencyCode
+	if currency.CurrencySymbol != "" {
+		formatted = currency.CurrencySymbol + value
+	}
+	return FormattedAmount{
+		Value:     amount,
+		Symbol:    currency.CurrencySymbol,
+		Code:      currency.CurrencyCode,
+		Formatted: formatted,
+	}
+}
+
+// withCurrency sets the display currency of the game engine responses that
+// carry a balance or payout. It runs before signResult, so the currency is
+// signed too.
+func withCurrency(resp interface{}) interface{} {
+	switch r := resp.(type) {
+	case MinesCashoutResponse:
+		r.Currency = DisplayCurrency()
+		return r
+	case MinesCashoutAllResponse:
+		r.Currency = DisplayCurrency()
+		return r
+	case MinesPreselectResponse:
+		r.Currency = DisplayCurrency()
+		return r
+	case MinesMultiGameResponse:
+		r.Currency = DisplayCurrency()
+		return r
+	case DiceRollResponse:
+		r.Currency = DisplayCurrency()
+		return r
+	case DiceParlayResponse:
+		r.Currency = DisplayCurrency()
+		return r
+	case PlinkoDropResponse:
+		r.Currency = DisplayCurrency()
+		return r
+	}
+	return resp
+}
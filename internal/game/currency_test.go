@@ -0,0 +1,69 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestFormatAmount(t *testing.T) {
+	tests := []struct {
+		name     string
+		symbol   string
+		code     string
+		decimals string
+		amount   float64
+		want     string
+	}{
+		{name: "dollars", symbol: "$", code: "USD", decimals: "2", amount: 1234.5, want: "$1234.50"},
+		{name: "bitcoin", code: "BTC", decimals: "8", amount: 0.00001234, want: "0.00001234 BTC"},
+		{name: "defaults", amount: 12, want: "12.00 credits"},
+		{name: "decimals capped", symbol: "€", decimals: "12", amount: 1, want: "€1.00000000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CURRENCY_SYMBOL", tt.symbol)
+			t.Setenv("CURRENCY_CODE", tt.code)
+			t.Setenv("CURRENCY_DECIMALS", tt.decimals)
+
+			got := FormatAmount(tt.amount)
+			if got.Formatted != tt.want {
+				t.Errorf("FormatAmount(%v) = %q, want %q", tt.amount, got.Formatted, tt.want)
+			}
+			if got.Value != tt.amount || got.Symbol != tt.symbol {
+				t.Errorf("FormatAmount(%v) = %+v", tt.amount, got)
+			}
+		})
+	}
+}
+
+func TestDiceEngine_SignsCurrency(t *testing.T) {
+	t.Setenv("CURRENCY_SYMBOL", "$")
+	t.Setenv("CURRENCY_CODE", "USD")
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	client.Set(context.Background(), REDIS_KEY_USER_BALANCE+"user1", 100.0, 0)
+
+	engine := NewDiceEngine(client, nil)
+	engine.signingKey = "signing-key"
+	resp, _ := engine.PlaceBet(context.Background(), DiceRollRequest{UserID: "user1", Amount: 10, Target: 50, IsOver: true})
+	roll := resp.(DiceRollResponse)
+	if roll.CurrencySymbol != "$" || roll.CurrencyCode != "USD" {
+		t.Errorf("currency = %q %q, want $ USD", roll.CurrencySymbol, roll.CurrencyCode)
+	}
+
+	data, _ := json.Marshal(roll)
+	var fields map[string]interface{}
+	json.Unmarshal(data, &fields)
+	if fields["currency_code"] != "USD" {
+		t.Errorf("response %s has no currency_code", data)
+	}
+	if !VerifyResultSignature(string(data), roll.ResultSignature, "signing-key") {
+		t.Errorf("response %s is not signed with its currency", data)
+	}
+}
@@ -9,41 +9,75 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"math"
 	"math/big"
 	"time"
 
 	"github.com/redis/go-redis/v9"
+
+	"aviator/internal/notifications"
+)
+
+const (
+	REDIS_KEY_DICE_GAME    = "dice:game:"
+	REDIS_KEY_DICE_HISTORY = "dice:history:"
+	DICE_MIN_VALUE         = 0.00
+	DICE_MAX_VALUE         = 100.00
+	DICE_HOUSE_EDGE        = 0.01 // 1%
+
+	DICE_EXACT_FACES      = 6
+	DICE_EXACT_MULTIPLIER = 5.82 // 6x less a 3% house edge
+	DICE_MIN_RANGE_WIDTH  = 1.00
 )
 
+// Dice modes, also the ProcessAction names of the variants
 const (
-	REDIS_KEY_DICE_GAME = "dice:game:"
-	DICE_MIN_VALUE      = 0.00
-	DICE_MAX_VALUE      = 100.00
+	DiceModeOverUnder = "over_under"
+	DiceModeExact     = "exact"
+	DiceModeRange     = "range"
 )
 
 // DiceGameState represents a completed Dice game
 type DiceGameState struct {
 	GameID     string    `json:"game_id"`
 	UserID     string    `json:"user_id"`
-	BetAmount  float64   `json:"bet_amount"`
-	Target     float64   `json:"target"`
-	IsOver     bool      `json:"is_over"` // true = roll over, false = roll under
+	BetAmount  Amount    `json:"bet_amount"`
+	Mode       string    `json:"mode"`
+	Target     float64   `json:"target"`              // Number for exact bets, range start for range bets
+	TargetTo   float64   `json:"target_to,omitempty"` // Range end for range bets
+	IsOver     bool      `json:"is_over"`             // true = roll over, false = roll under
 	ServerSeed string    `json:"server_seed"`
 	ClientSeed string    `json:"client_seed"`
 	Nonce      int       `json:"nonce"`
 	RollResult float64   `json:"roll_result"`
 	Win        bool      `json:"win"`
 	Multiplier float64   `json:"multiplier"`
-	Payout     float64   `json:"payout"`
+	Payout     Amount    `json:"payout"`
 	CreatedAt  time.Time `json:"created_at"`
 }
 
 // DiceRollRequest represents a dice roll request
 type DiceRollRequest struct {
+	UserID     string  `json:"user_id"`
+	Amount     float64 `json:"amount"`
+	Target     float64 `json:"target"`
+	IsOver     bool    `json:"is_over"`
+	ClientSeed string  `json:"client_seed,omitempty"` // Generated when empty
+}
+
+// DiceExactRequest bets on a six-sided die showing Number
+type DiceExactRequest struct {
+	UserID string  `json:"user_id"`
+	Amount float64 `json:"amount"`
+	Number int     `json:"number"`
+}
+
+// DiceRangeRequest bets on the roll landing in [From, To)
+type DiceRangeRequest struct {
 	UserID string  `json:"user_id"`
 	Amount float64 `json:"amount"`
-	Target float64 `json:"target"`
-	IsOver bool    `json:"is_over"`
+	From   float64 `json:"from"`
+	To     float64 `json:"to"`
 }
 
 // DiceRollResponse represents the response to a dice roll
@@ -54,11 +88,15 @@ type DiceRollResponse struct {
 	RollResult float64 `json:"roll_result,omitempty"`
 	Win        bool    `json:"win,omitempty"`
 	Multiplier float64 `json:"multiplier,omitempty"`
-	Payout     float64 `json:"payout,omitempty"`
+	Payout     Amount  `json:"payout,omitempty"`
 	Balance    float64 `json:"balance,omitempty"`
 	ServerSeed string  `json:"server_seed,omitempty"`
 	ClientSeed string  `json:"client_seed,omitempty"`
 	Nonce      int     `json:"nonce,omitempty"`
+	Suspicious bool    `json:"suspicious,omitempty"`
+	Currency
+
+	ResultSignature string `json:"result_signature,omitempty"` // See SignResponse
 }
 
 // DiceEngine implements the GameEngine interface for Dice game
@@ -67,6 +105,10 @@ type DiceEngine struct {
 	hub         *Hub
 	ctx         context.Context
 	nonce       int
+	anomaly     *AnomalyDetector
+	signingKey  string
+	sessions    *sessionLimiter // Rolls in progress
+	maxPayout   float64         // Zero disables the parlay payout check
 }
 
 // NewDiceEngine creates a new Dice game engine
@@ -76,6 +118,10 @@ func NewDiceEngine(redisClient *redis.Client, hub *Hub) *DiceEngine {
 		hub:         hub,
 		ctx:         context.Background(),
 		nonce:       0,
+		anomaly:     NewAnomalyDetector(redisClient),
+		signingKey:  ResultSigningKey(),
+		sessions:    newSessionLimiter(redisClient, GameTypeDice, REDIS_KEY_DICE_ACTIVE_COUNT, getEnvAsInt("DICE_MAX_CONCURRENT_ROLLS", DICE_MAX_CONCURRENT_ROLLS)),
+		maxPayout:   getEnvAsFloat("MAX_PAYOUT", MAX_PAYOUT),
 	}
 }
 
@@ -102,47 +148,109 @@ func (d *DiceEngine) GetState() interface{} {
 	return map[string]string{"status": "ready"}
 }
 
+// GameInfo returns the Dice metadata for game discovery
+func (d *DiceEngine) GameInfo() GameMetadata {
+	return GameMetadata{
+		GameType:     GameTypeDice,
+		MinBet:       MIN_BET_AMOUNT,
+		MaxBet:       MAX_BET_AMOUNT,
+		Description:  "Roll a number from 0 to 100 and bet on it landing over or under a target",
+		HouseEdgePct: DICE_HOUSE_EDGE * 100,
+		Endpoints: []GameEndpoint{
+			{Method: "POST", Path: "/api/v1/dice/roll", Description: "Roll the dice"},
+			{Method: "POST", Path: "/api/v1/dice/exact", Description: "Bet on a six-sided die showing a number"},
+			{Method: "POST", Path: "/api/v1/dice/range", Description: "Bet on the roll landing in a range"},
+			{Method: "POST", Path: "/api/v1/dice/parlay", Description: "Bet on 2 to 5 over/under rolls all winning"},
+			{Method: "GET", Path: "/api/v1/dice/parlay/odds-preview", Description: "Preview the payout and win probability of a parlay"},
+		},
+	}
+}
+
+// HealthCheck performs a synthetic roll to verify the provably fair functions
+func (d *DiceEngine) HealthCheck(ctx context.Context) (HealthStatus, error) {
+	start := time.Now()
+
+	var err error
+	roll := d.generateRoll("health-server-seed", "health-client-seed", 1)
+	if roll < DICE_MIN_VALUE || roll > DICE_MAX_VALUE {
+		err = fmt.Errorf("synthetic roll %.2f out of range", roll)
+	} else if again := d.generateRoll("health-server-seed", "health-client-seed", 1); again != roll {
+		err = errors.New("synthetic roll is not deterministic")
+	}
+
+	return newHealthStatus(start, err)
+}
+
 // PlaceBet handles a dice roll (instant result)
 func (d *DiceEngine) PlaceBet(ctx context.Context, req interface{}) (interface{}, error) {
+	resp, err := d.placeBet(ctx, req)
+	return signResult(withCurrency(resp), d.signingKey), err
+}
+
+func (d *DiceEngine) placeBet(ctx context.Context, req interface{}) (interface{}, error) {
 	rollReq, ok := req.(DiceRollRequest)
 	if !ok {
 		return nil, errors.New("invalid request type")
 	}
 
-	// Validate bet amount
-	if rollReq.Amount < MIN_BET_AMOUNT || rollReq.Amount > MAX_BET_AMOUNT {
+	if message := validateDiceRoll(rollReq); message != "" {
 		return DiceRollResponse{
 			Success: false,
-			Message: fmt.Sprintf("Bet must be between %.2f and %.2f", MIN_BET_AMOUNT, MAX_BET_AMOUNT),
+			Message: message,
 		}, nil
 	}
 
-	// Validate target
-	if rollReq.Target < DICE_MIN_VALUE || rollReq.Target > DICE_MAX_VALUE {
-		return DiceRollResponse{
-			Success: false,
-			Message: fmt.Sprintf("Target must be between %.2f and %.2f", DICE_MIN_VALUE, DICE_MAX_VALUE),
-		}, nil
-	}
+	direction := map[bool]string{true: "over", false: "under"}[rollReq.IsOver]
+	return d.play(ctx, diceBet{
+		UserID:     rollReq.UserID,
+		Amount:     rollReq.Amount,
+		Mode:       DiceModeOverUnder,
+		Target:     rollReq.Target,
+		IsOver:     rollReq.IsOver,
+		ClientSeed: rollReq.ClientSeed,
+		Multiplier: overUnderMultiplier(rollReq.Target, rollReq.IsOver, GetCurrentHouseEdge(GameTypeDice)),
+		Describe:   fmt.Sprintf("%s %.2f", direction, rollReq.Target),
+		Outcome: func(fraction float64) (float64, bool) {
+			roll := rollFromFraction(fraction)
+			if rollReq.IsOver {
+				return roll, roll > rollReq.Target
+			}
+			return roll, roll < rollReq.Target
+		},
+	})
+}
 
-	// Validate target range (must allow for possible win)
-	if rollReq.IsOver && rollReq.Target >= 99.00 {
-		return DiceRollResponse{
-			Success: false,
-			Message: "Target too high for 'over' bet",
-		}, nil
+// diceBet describes how one dice variant is paid out
+type diceBet struct {
+	UserID     string
+	Amount     float64
+	Mode       string
+	Target     float64
+	TargetTo   float64
+	IsOver     bool
+	ClientSeed string // Validated player seed, or "" to generate one
+	Multiplier float64
+	Describe   string // Bet summary for the log
+
+	// Outcome maps the provably fair fraction in [0, 1) to the reported roll and whether it won
+	Outcome func(fraction float64) (float64, bool)
+}
+
+// play settles a validated bet: it takes the stake, rolls and pays out any win
+func (d *DiceEngine) play(ctx context.Context, bet diceBet) (DiceRollResponse, error) {
+	if err := checkMaintenance(ctx, d.redisClient, GameTypeDice); err != nil {
+		return DiceRollResponse{}, err
 	}
-	if !rollReq.IsOver && rollReq.Target <= 1.00 {
-		return DiceRollResponse{
-			Success: false,
-			Message: "Target too low for 'under' bet",
-		}, nil
+
+	if err := d.sessions.acquire(ctx, 1); err != nil {
+		return DiceRollResponse{}, err
 	}
+	defer d.sessions.release(ctx, 1)
 
 	// Check user balance
-	balanceKey := REDIS_KEY_USER_BALANCE + rollReq.UserID
+	balanceKey := REDIS_KEY_USER_BALANCE + bet.UserID
 	balance, err := d.redisClient.Get(ctx, balanceKey).Float64()
-	if err != nil || balance < rollReq.Amount {
+	if err != nil || balance < bet.Amount {
 		return DiceRollResponse{
 			Success: false,
 			Message: "Insufficient balance",
@@ -151,78 +259,90 @@ func (d *DiceEngine) PlaceBet(ctx context.Context, req interface{}) (interface{}
 	}
 
 	// Deduct balance
-	newBalance, err := d.redisClient.IncrByFloat(ctx, balanceKey, -rollReq.Amount).Result()
+	newBalance, err := d.redisClient.IncrByFloat(ctx, balanceKey, -bet.Amount).Result()
 	if err != nil || newBalance < 0 {
-		d.redisClient.IncrByFloat(ctx, balanceKey, rollReq.Amount) // Rollback
+		d.redisClient.IncrByFloat(ctx, balanceKey, bet.Amount) // Rollback
 		return DiceRollResponse{
 			Success: false,
 			Message: "Transaction failed",
 		}, nil
 	}
+	betAmount, _ := NewAmount(bet.Amount) // Precision checked by validateBetAmount
+	d.hub.NotifyBalance(bet.UserID, newBalance, -betAmount, BalanceReasonBet)
+	recordWager(ctx, d.redisClient, bet.UserID, betAmount)
 
 	// Generate provably fair result
 	d.nonce++
 	serverSeed := GenerateSeed()
-	clientSeed := GenerateSeed()
-	rollResult := d.generateRoll(serverSeed, clientSeed, d.nonce)
-
-	// Determine win
-	win := false
-	if rollReq.IsOver {
-		win = rollResult > rollReq.Target
-	} else {
-		win = rollResult < rollReq.Target
-	}
+	clientSeed := clientSeedOrGenerate(bet.ClientSeed)
+	rollResult, win := bet.Outcome(rollFraction(serverSeed, clientSeed, d.nonce))
 
-	// Calculate multiplier and payout
-	multiplier := d.calculateMultiplier(rollReq.Target, rollReq.IsOver)
-	payout := 0.0
+	// Calculate payout
+	var payout Amount
 	if win {
-		payout = rollReq.Amount * multiplier
+		payout = betAmount.Mul(bet.Multiplier)
 	}
 
 	// Credit payout if won
 	finalBalance := newBalance
 	if win {
-		finalBalance, err = d.redisClient.IncrByFloat(ctx, balanceKey, payout).Result()
+		finalBalance, err = d.redisClient.IncrByFloat(ctx, balanceKey, payout.Float64()).Result()
 		if err != nil {
 			return DiceRollResponse{
 				Success: false,
 				Message: "Failed to credit payout",
 			}, nil
 		}
+		d.hub.NotifyBalance(bet.UserID, finalBalance, payout, BalanceReasonPayout)
 	}
 
 	// Create game state
-	gameID := fmt.Sprintf("DICE-%s-%d", rollReq.UserID, time.Now().UnixNano())
+	playedAt := time.Now()
+	gameID := fmt.Sprintf("DICE-%s-%d", bet.UserID, playedAt.UnixNano())
 	gameState := DiceGameState{
 		GameID:     gameID,
-		UserID:     rollReq.UserID,
-		BetAmount:  rollReq.Amount,
-		Target:     rollReq.Target,
-		IsOver:     rollReq.IsOver,
+		UserID:     bet.UserID,
+		BetAmount:  betAmount,
+		Mode:       bet.Mode,
+		Target:     bet.Target,
+		TargetTo:   bet.TargetTo,
+		IsOver:     bet.IsOver,
 		ServerSeed: serverSeed,
 		ClientSeed: clientSeed,
 		Nonce:      d.nonce,
 		RollResult: rollResult,
 		Win:        win,
-		Multiplier: multiplier,
+		Multiplier: bet.Multiplier,
 		Payout:     payout,
-		CreatedAt:  time.Now(),
+		CreatedAt:  playedAt,
 	}
 
 	// Store game state in Redis
 	gameKey := REDIS_KEY_DICE_GAME + gameID
 	gameJSON, _ := json.Marshal(gameState)
 	d.redisClient.Set(ctx, gameKey, string(gameJSON), 1*time.Hour)
+	recordActivity(ctx, d.redisClient, REDIS_KEY_DICE_ROLLS)
+	if err := recordGameHistory(ctx, d.redisClient, REDIS_KEY_DICE_HISTORY+bet.UserID, gameID, playedAt); err != nil {
+		log.Printf("[DICE] Failed to record history for %s: %v", bet.UserID, err)
+	}
+
+	outcome := GameOutcome{
+		UserID:   bet.UserID,
+		GameType: GameTypeDice,
+		Wager:    betAmount,
+		Payout:   payout,
+	}
+	suspicious := d.anomaly.Record(ctx, outcome)
+	recordHouseProfit(ctx, d.redisClient, outcome)
+	d.hub.GameSettled(notifications.EventDiceRoll, outcome)
+	d.hub.BetSettled(ctx, outcome.UserID)
 
 	winStatus := "lost"
 	if win {
 		winStatus = "won"
 	}
-	log.Printf("[DICE] User %s rolled %.2f (%s %.2f), %s, payout %.2f",
-		rollReq.UserID, rollResult, map[bool]string{true: "over", false: "under"}[rollReq.IsOver],
-		rollReq.Target, winStatus, payout)
+	log.Printf("[DICE] User %s rolled %.2f (%s), %s, payout %s",
+		bet.UserID, rollResult, bet.Describe, winStatus, payout)
 
 	return DiceRollResponse{
 		Success:    true,
@@ -230,21 +350,241 @@ func (d *DiceEngine) PlaceBet(ctx context.Context, req interface{}) (interface{}
 		GameID:     gameID,
 		RollResult: rollResult,
 		Win:        win,
-		Multiplier: multiplier,
+		Multiplier: bet.Multiplier,
 		Payout:     payout,
 		Balance:    finalBalance,
 		ServerSeed: serverSeed,
 		ClientSeed: clientSeed,
 		Nonce:      d.nonce,
+		Suspicious: suspicious,
 	}, nil
 }
 
+// validateDiceRoll checks a roll without touching balances. It returns a
+// message for the player, or "" if the roll is valid.
+func validateDiceRoll(rollReq DiceRollRequest) string {
+	// Validate bet amount
+	if message := validateBetAmount(rollReq.Amount); message != "" {
+		return message
+	}
+
+	if message := validateDiceTarget(rollReq.Target, rollReq.IsOver); message != "" {
+		return message
+	}
+
+	return ValidateClientSeed(rollReq.ClientSeed)
+}
+
+// validateDiceTarget checks the target of an over/under bet
+func validateDiceTarget(target float64, isOver bool) string {
+	if target < DICE_MIN_VALUE || target > DICE_MAX_VALUE {
+		return fmt.Sprintf("Target must be between %.2f and %.2f", DICE_MIN_VALUE, DICE_MAX_VALUE)
+	}
+
+	// Validate target range (must allow for possible win)
+	if isOver && target >= 99.00 {
+		return "Target too high for 'over' bet"
+	}
+	if !isOver && target <= 1.00 {
+		return "Target too low for 'under' bet"
+	}
+	return ""
+}
+
+// ProcessAction handles the dice variants that PlaceBet does not and the
+// player's game history
 func (d *DiceEngine) ProcessAction(ctx context.Context, action string, req interface{}) (interface{}, error) {
-	return nil, errors.New("no actions available for Dice")
+	switch action {
+	case DiceModeExact:
+		exactReq, ok := req.(DiceExactRequest)
+		if !ok {
+			return nil, errors.New("invalid request type")
+		}
+		resp, err := d.rollExact(ctx, exactReq)
+		return signResult(withCurrency(resp), d.signingKey), err
+	case DiceModeRange:
+		rangeReq, ok := req.(DiceRangeRequest)
+		if !ok {
+			return nil, errors.New("invalid request type")
+		}
+		resp, err := d.rollRange(ctx, rangeReq)
+		return signResult(withCurrency(resp), d.signingKey), err
+	case DiceModeParlay:
+		parlayReq, ok := req.(DiceParlayRequest)
+		if !ok {
+			return nil, errors.New("invalid request type")
+		}
+		resp, err := d.rollParlay(ctx, parlayReq)
+		return signResult(withCurrency(resp), d.signingKey), err
+	case "parlay_odds":
+		legs, ok := req.([]DiceParlayLeg)
+		if !ok {
+			return nil, errors.New("invalid request type")
+		}
+		if message := validateDiceParlayLegs(legs); message != "" {
+			return nil, errors.New(message)
+		}
+		odds := NewDiceParlayOdds(legs, GetCurrentHouseEdge(GameTypeDice))
+		if message := checkParlayPayout(odds.TotalMultiplier, 0, d.maxPayout); message != "" {
+			return nil, errors.New(message)
+		}
+		return odds, nil
+	case "history":
+		historyReq, ok := req.(GameHistoryRequest)
+		if !ok {
+			return nil, errors.New("invalid request type")
+		}
+		return d.history(ctx, historyReq)
+	case "active_count":
+		return d.activeStats(ctx)
+	case "capacity":
+		return d.sessions.capacity(ctx)
+	default:
+		return nil, errors.New("unknown action")
+	}
+}
+
+// DiceHistoryEntry is one game in a player's Dice history
+type DiceHistoryEntry struct {
+	GameID     string    `json:"game_id"`
+	Mode       string    `json:"mode"`
+	RollResult float64   `json:"roll_result"`
+	Target     float64   `json:"target"`
+	TargetTo   float64   `json:"target_to,omitempty"`
+	IsOver     bool      `json:"is_over"`
+	Win        bool      `json:"win"`
+	Multiplier float64   `json:"multiplier"`
+	Payout     Amount    `json:"payout"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// history returns a page of the user's recent Dice games, newest first
+func (d *DiceEngine) history(ctx context.Context, req GameHistoryRequest) ([]DiceHistoryEntry, error) {
+	games, err := loadGameHistory(ctx, d.redisClient, REDIS_KEY_DICE_HISTORY+req.UserID, REDIS_KEY_DICE_GAME, req.Page, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]DiceHistoryEntry, 0, len(games))
+	for _, gameJSON := range games {
+		var gameState DiceGameState
+		if err := json.Unmarshal([]byte(gameJSON), &gameState); err != nil {
+			continue
+		}
+		entries = append(entries, DiceHistoryEntry{
+			GameID:     gameState.GameID,
+			Mode:       gameState.Mode,
+			RollResult: gameState.RollResult,
+			Target:     gameState.Target,
+			TargetTo:   gameState.TargetTo,
+			IsOver:     gameState.IsOver,
+			Win:        gameState.Win,
+			Multiplier: gameState.Multiplier,
+			Payout:     gameState.Payout,
+			CreatedAt:  gameState.CreatedAt,
+		})
+	}
+	return entries, nil
+}
+
+// rollExact pays out when a six-sided die shows the chosen number
+func (d *DiceEngine) rollExact(ctx context.Context, req DiceExactRequest) (DiceRollResponse, error) {
+	if message := validateBetAmount(req.Amount); message != "" {
+		return DiceRollResponse{Success: false, Message: message}, nil
+	}
+	if req.Number < 1 || req.Number > DICE_EXACT_FACES {
+		return DiceRollResponse{
+			Success: false,
+			Message: fmt.Sprintf("Number must be between 1 and %d", DICE_EXACT_FACES),
+		}, nil
+	}
+
+	return d.play(ctx, diceBet{
+		UserID:     req.UserID,
+		Amount:     req.Amount,
+		Mode:       DiceModeExact,
+		Target:     float64(req.Number),
+		Multiplier: DICE_EXACT_MULTIPLIER,
+		Describe:   fmt.Sprintf("exactly %d", req.Number),
+		Outcome: func(fraction float64) (float64, bool) {
+			face := dieFace(fraction)
+			return float64(face), face == req.Number
+		},
+	})
+}
+
+// rollRange pays out when the roll lands in [from, to)
+func (d *DiceEngine) rollRange(ctx context.Context, req DiceRangeRequest) (DiceRollResponse, error) {
+	if message := validateDiceRange(req); message != "" {
+		return DiceRollResponse{Success: false, Message: message}, nil
+	}
+
+	return d.play(ctx, diceBet{
+		UserID:     req.UserID,
+		Amount:     req.Amount,
+		Mode:       DiceModeRange,
+		Target:     req.From,
+		TargetTo:   req.To,
+		Multiplier: rangeMultiplierWithEdge(req.From, req.To, GetCurrentHouseEdge(GameTypeDice)),
+		Describe:   fmt.Sprintf("between %.2f and %.2f", req.From, req.To),
+		Outcome: func(fraction float64) (float64, bool) {
+			roll := rollFromFraction(fraction)
+			return roll, roll >= req.From && roll < req.To
+		},
+	})
+}
+
+// validateDiceRange returns a message for the player, or "" if the range bet is valid
+func validateDiceRange(req DiceRangeRequest) string {
+	if message := validateBetAmount(req.Amount); message != "" {
+		return message
+	}
+	if req.From < DICE_MIN_VALUE || req.To > DICE_MAX_VALUE {
+		return fmt.Sprintf("Range must be between %.2f and %.2f", DICE_MIN_VALUE, DICE_MAX_VALUE)
+	}
+	if req.To-req.From < DICE_MIN_RANGE_WIDTH {
+		return fmt.Sprintf("Range must be at least %.2f wide", DICE_MIN_RANGE_WIDTH)
+	}
+	return ""
+}
+
+// rangeMultiplier pays the inverse of the range's win chance less the house edge
+func rangeMultiplier(from, to float64) float64 {
+	return rangeMultiplierWithEdge(from, to, DICE_HOUSE_EDGE)
+}
+
+// rangeMultiplierWithEdge is rangeMultiplier under a scheduled house edge
+func rangeMultiplierWithEdge(from, to, edge float64) float64 {
+	multiplier := 100.0 / (to - from) * (1.0 - edge)
+
+	// Round down to 2 decimal places, ignoring float error just below a whole cent
+	return math.Floor(multiplier*100+1e-9) / 100.0
+}
+
+// dieFace maps a fraction in [0, 1) uniformly onto the faces 1 to 6
+func dieFace(fraction float64) int {
+	face := int(fraction*DICE_EXACT_FACES) + 1
+	if face > DICE_EXACT_FACES {
+		face = DICE_EXACT_FACES // fraction can round up to exactly 1
+	}
+	return face
 }
 
 // generateRoll generates a dice roll result using provably fair algorithm
 func (d *DiceEngine) generateRoll(serverSeed, clientSeed string, nonce int) float64 {
+	return rollFromFraction(rollFraction(serverSeed, clientSeed, nonce))
+}
+
+// rollFromFraction scales a fraction in [0, 1) to a roll from 0 to 100
+func rollFromFraction(fraction float64) float64 {
+	result := fraction * 100.0
+
+	// Round to 2 decimal places
+	return float64(int(result*100)) / 100.0
+}
+
+// rollFraction returns the provably fair fraction in [0, 1) behind a roll
+func rollFraction(serverSeed, clientSeed string, nonce int) float64 {
 	data := fmt.Sprintf("%s:%d", clientSeed, nonce)
 	h := hmac.New(sha256.New, []byte(serverSeed))
 	h.Write([]byte(data))
@@ -256,31 +596,26 @@ func (d *DiceEngine) generateRoll(serverSeed, clientSeed string, nonce int) floa
 	bigInt := new(big.Int)
 	bigInt.SetString(hexValue, 16)
 
-	// Convert to float between 0 and 100
+	// Convert to float between 0 and 1
 	const MAX_VALUE_F64 = 18446744073709551616.0
-	result := (float64(bigInt.Uint64()) / MAX_VALUE_F64) * 100.0
-
-	// Round to 2 decimal places
-	return float64(int(result*100)) / 100.0
+	return float64(bigInt.Uint64()) / MAX_VALUE_F64
 }
 
 // calculateMultiplier calculates the payout multiplier based on win probability
 func (d *DiceEngine) calculateMultiplier(target float64, isOver bool) float64 {
-	// Calculate win probability
-	var winChance float64
-	if isOver {
-		winChance = (100.0 - target) / 100.0
-	} else {
-		winChance = target / 100.0
-	}
+	return overUnderMultiplier(target, isOver, DICE_HOUSE_EDGE)
+}
+
+// overUnderMultiplier is calculateMultiplier under a scheduled house edge
+func overUnderMultiplier(target float64, isOver bool, edge float64) float64 {
+	winChance := overUnderWinChance(target, isOver)
 
 	// Prevent division by zero
 	if winChance <= 0.01 {
 		winChance = 0.01
 	}
 
-	// House edge: 1%
-	houseEdge := 0.99
+	houseEdge := 1.0 - edge
 
 	// Multiplier = (1 / winChance) * houseEdge
 	multiplier := (1.0 / winChance) * houseEdge
@@ -288,3 +623,11 @@ func (d *DiceEngine) calculateMultiplier(target float64, isOver bool) float64 {
 	// Round to 2 decimal places
 	return float64(int(multiplier*100)) / 100.0
 }
+
+// overUnderWinChance is the probability of an over/under bet winning
+func overUnderWinChance(target float64, isOver bool) float64 {
+	if isOver {
+		return (100.0 - target) / 100.0
+	}
+	return target / 100.0
+}
@@ -0,0 +1,160 @@
+//go:build integration
+
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDiceEngineIntegration(t *testing.T) {
+	engine := NewDiceEngine(testRedis, nil)
+
+	t.Run("roll settles balance", func(t *testing.T) {
+		setTestBalance(t, "dice-roll", 100)
+
+		resp, err := engine.PlaceBet(context.Background(), DiceRollRequest{UserID: "dice-roll", Amount: 10, Target: 50, IsOver: true})
+		if err != nil {
+			t.Fatalf("PlaceBet returned error: %v", err)
+		}
+		rollResp := resp.(DiceRollResponse)
+		if !rollResp.Success {
+			t.Fatalf("Roll failed: %s", rollResp.Message)
+		}
+		if rollResp.Win != (rollResp.RollResult > 50) {
+			t.Errorf("Win %v does not match roll %.2f over 50", rollResp.Win, rollResp.RollResult)
+		}
+
+		expected := 90 + rollResp.Payout.Float64()
+		if balance := getTestBalance(t, "dice-roll"); balance != expected {
+			t.Errorf("Expected balance %.2f, got %.2f", expected, balance)
+		}
+		if exists := testRedis.Exists(context.Background(), REDIS_KEY_DICE_GAME+rollResp.GameID).Val(); exists != 1 {
+			t.Error("Expected game to be stored in Redis")
+		}
+	})
+
+	t.Run("exact roll pays 5.82x on the chosen face", func(t *testing.T) {
+		setTestBalance(t, "dice-exact", 1000)
+
+		wins := 0
+		for i := 0; i < 30; i++ {
+			resp, err := engine.ProcessAction(context.Background(), DiceModeExact, DiceExactRequest{UserID: "dice-exact", Amount: 10, Number: 3})
+			if err != nil {
+				t.Fatalf("ProcessAction returned error: %v", err)
+			}
+			rollResp := resp.(DiceRollResponse)
+			if !rollResp.Success {
+				t.Fatalf("Exact roll failed: %s", rollResp.Message)
+			}
+			if rollResp.RollResult < 1 || rollResp.RollResult > 6 || rollResp.RollResult != float64(int(rollResp.RollResult)) {
+				t.Fatalf("Roll %.2f is not a die face", rollResp.RollResult)
+			}
+			if rollResp.Win != (rollResp.RollResult == 3) {
+				t.Errorf("Win %v does not match face %.0f", rollResp.Win, rollResp.RollResult)
+			}
+			if rollResp.Win {
+				wins++
+				if rollResp.Payout != amountOf(58.20) {
+					t.Errorf("Expected payout 58.20, got %s", rollResp.Payout)
+				}
+			}
+		}
+
+		expected := 1000 - 30*10 + float64(wins)*58.20
+		if balance := getTestBalance(t, "dice-exact"); math.Abs(balance-expected) > 1e-6 {
+			t.Errorf("Expected balance %.2f, got %.2f", expected, balance)
+		}
+
+		resp, _ := engine.ProcessAction(context.Background(), DiceModeExact, DiceExactRequest{UserID: "dice-exact", Amount: 10, Number: 7})
+		if resp.(DiceRollResponse).Success {
+			t.Error("Expected number 7 to be rejected")
+		}
+	})
+
+	t.Run("range roll wins inside the range", func(t *testing.T) {
+		setTestBalance(t, "dice-range", 1000)
+
+		for i := 0; i < 20; i++ {
+			resp, err := engine.ProcessAction(context.Background(), DiceModeRange, DiceRangeRequest{UserID: "dice-range", Amount: 10, From: 20, To: 40})
+			if err != nil {
+				t.Fatalf("ProcessAction returned error: %v", err)
+			}
+			rollResp := resp.(DiceRollResponse)
+			if !rollResp.Success {
+				t.Fatalf("Range roll failed: %s", rollResp.Message)
+			}
+			if rollResp.Multiplier != 4.95 {
+				t.Errorf("Expected multiplier 4.95, got %.2f", rollResp.Multiplier)
+			}
+			if inside := rollResp.RollResult >= 20 && rollResp.RollResult < 40; rollResp.Win != inside {
+				t.Errorf("Win %v does not match roll %.2f in [20, 40)", rollResp.Win, rollResp.RollResult)
+			}
+		}
+
+		resp, _ := engine.ProcessAction(context.Background(), DiceModeRange, DiceRangeRequest{UserID: "dice-range", Amount: 10, From: 20, To: 20.5})
+		if resp.(DiceRollResponse).Success {
+			t.Error("Expected a range narrower than 1 to be rejected")
+		}
+	})
+
+	t.Run("insufficient balance is rejected", func(t *testing.T) {
+		setTestBalance(t, "dice-broke", 5)
+
+		resp, _ := engine.PlaceBet(context.Background(), DiceRollRequest{UserID: "dice-broke", Amount: 10, Target: 50, IsOver: true})
+		if resp.(DiceRollResponse).Success {
+			t.Error("Expected roll to fail")
+		}
+		if balance := getTestBalance(t, "dice-broke"); balance != 5 {
+			t.Errorf("Expected balance 5, got %.2f", balance)
+		}
+	})
+
+	t.Run("win notifies balance subscribers", func(t *testing.T) {
+		hub := NewHub()
+		go hub.Run()
+		conn := &mockConn{}
+		hub.RegisterClient(conn, "dice-notify")
+		waitForClients(t, hub, 1)
+		hub.SubscribeBalance(conn, "dice-notify")
+
+		notifyEngine := NewDiceEngine(testRedis, hub)
+		setTestBalance(t, "dice-notify", 1000)
+
+		// Rolling over 2 wins 98% of the time; retry until a win
+		var rollResp DiceRollResponse
+		for i := 0; i < 20 && !rollResp.Win; i++ {
+			resp, err := notifyEngine.PlaceBet(context.Background(), DiceRollRequest{UserID: "dice-notify", Amount: 10, Target: 2, IsOver: true})
+			if err != nil {
+				t.Fatalf("PlaceBet returned error: %v", err)
+			}
+			rollResp = resp.(DiceRollResponse)
+		}
+		if !rollResp.Win {
+			t.Fatal("Expected a win within 20 rolls")
+		}
+		// Bet and payout updates are delivered concurrently, so look for the payout
+		deadline := time.Now().Add(time.Second)
+		var payout BalanceUpdateMessage
+		for payout.Reason != BalanceReasonPayout && time.Now().Before(deadline) {
+			conn.mu.Lock()
+			for _, message := range conn.messages {
+				var update BalanceUpdateMessage
+				if json.Unmarshal(message, &update) == nil && update.Reason == BalanceReasonPayout {
+					payout = update
+				}
+			}
+			conn.mu.Unlock()
+			time.Sleep(time.Millisecond)
+		}
+		if payout.Type != "balance_update" || payout.Reason != BalanceReasonPayout {
+			t.Fatalf("Expected payout balance_update, got %+v", payout)
+		}
+		if payout.Delta != rollResp.Payout || payout.Balance != rollResp.Balance {
+			t.Errorf("Expected delta %s and balance %.2f, got %+v", rollResp.Payout, rollResp.Balance, payout)
+		}
+	})
+}
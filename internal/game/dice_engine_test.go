@@ -148,3 +148,75 @@ func TestDiceEngine_WinLogic(t *testing.T) {
 		}
 	})
 }
+
+func TestDieFace(t *testing.T) {
+	tests := []struct {
+		fraction float64
+		want     int
+	}{
+		{0, 1},
+		{1.0/6 - 1e-9, 1},
+		{1.0 / 6, 2},
+		{0.5, 4},
+		{5.0 / 6, 6},
+		{0.999999, 6},
+		{1, 6}, // The fraction can round up to exactly 1
+	}
+	for _, tt := range tests {
+		if got := dieFace(tt.fraction); got != tt.want {
+			t.Errorf("dieFace(%v) = %d, want %d", tt.fraction, got, tt.want)
+		}
+	}
+
+	counts := make([]int, DICE_EXACT_FACES+1)
+	for nonce := 0; nonce < 6000; nonce++ {
+		counts[dieFace(rollFraction("server", "client", nonce))]++
+	}
+	for face := 1; face <= DICE_EXACT_FACES; face++ {
+		if counts[face] < 850 || counts[face] > 1150 {
+			t.Errorf("face %d came up %d times in 6000 rolls, want about 1000", face, counts[face])
+		}
+	}
+}
+
+func TestRangeMultiplier(t *testing.T) {
+	tests := []struct {
+		from, to float64
+		want     float64
+	}{
+		{20, 40, 4.95},
+		{0, 50, 1.98},
+		{0, 100, 0.99},
+		{10, 11, 99},
+		{33.33, 66.66, 2.97},
+	}
+	for _, tt := range tests {
+		if got := rangeMultiplier(tt.from, tt.to); got != tt.want {
+			t.Errorf("rangeMultiplier(%v, %v) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestValidateDiceRange(t *testing.T) {
+	tests := []struct {
+		name  string
+		req   DiceRangeRequest
+		valid bool
+	}{
+		{"normal range", DiceRangeRequest{Amount: 10, From: 20, To: 40}, true},
+		{"minimum width", DiceRangeRequest{Amount: 10, From: 20, To: 21}, true},
+		{"full range", DiceRangeRequest{Amount: 10, From: 0, To: 100}, true},
+		{"too narrow", DiceRangeRequest{Amount: 10, From: 20, To: 20.99}, false},
+		{"reversed", DiceRangeRequest{Amount: 10, From: 40, To: 20}, false},
+		{"below zero", DiceRangeRequest{Amount: 10, From: -1, To: 20}, false},
+		{"above 100", DiceRangeRequest{Amount: 10, From: 90, To: 101}, false},
+		{"bad amount", DiceRangeRequest{Amount: 0, From: 20, To: 40}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if message := validateDiceRange(tt.req); (message == "") != tt.valid {
+				t.Errorf("validateDiceRange(%+v) = %q, want valid = %v", tt.req, message, tt.valid)
+			}
+		})
+	}
+}
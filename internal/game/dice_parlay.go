@@ -0,0 +1,294 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"aviator/internal/notifications"
+)
+
+const (
+	REDIS_KEY_DICE_PARLAY = "dice:parlay:"
+	DICE_PARLAY_MIN_LEGS  = 2
+	DICE_PARLAY_MAX_LEGS  = 5
+	DICE_PARLAY_LEG_EDGE  = 0.05 // Taken again on every leg, on top of the dice house edge
+
+	DiceModeParlay = "parlay"
+)
+
+// DiceParlayLeg is one over/under roll of a parlay
+type DiceParlayLeg struct {
+	Target float64 `json:"target"`
+	IsOver bool    `json:"is_over"`
+}
+
+// DiceParlayRequest bets once on every leg winning
+type DiceParlayRequest struct {
+	UserID     string          `json:"user_id"`
+	Amount     float64         `json:"amount"`
+	Legs       []DiceParlayLeg `json:"legs"`
+	ClientSeed string          `json:"client_seed,omitempty"` // Generated when empty
+}
+
+// DiceParlayLegResult is the roll of one leg
+type DiceParlayLegResult struct {
+	Roll       float64 `json:"roll"`
+	Win        bool    `json:"win"`
+	Multiplier float64 `json:"multiplier"`
+	Nonce      int     `json:"nonce"`
+}
+
+// DiceParlayResponse is the result of a parlay. Legs after the first losing
+// one are still rolled, so every nonce of the game can be verified.
+type DiceParlayResponse struct {
+	Success         bool                  `json:"success"`
+	Message         string                `json:"message"`
+	GameID          string                `json:"game_id,omitempty"`
+	LegsResults     []DiceParlayLegResult `json:"legs_results,omitempty"`
+	AllWon          bool                  `json:"all_won"`
+	TotalMultiplier float64               `json:"total_multiplier,omitempty"`
+	Payout          Amount                `json:"payout"`
+	Balance         float64               `json:"balance,omitempty"`
+	ServerSeed      string                `json:"server_seed,omitempty"`
+	ClientSeed      string                `json:"client_seed,omitempty"`
+	Suspicious      bool                  `json:"suspicious,omitempty"`
+	Currency
+
+	ResultSignature string `json:"result_signature,omitempty"` // See SignResponse
+}
+
+// DiceParlayGameState is a completed parlay, stored under REDIS_KEY_DICE_PARLAY
+type DiceParlayGameState struct {
+	GameID          string                `json:"game_id"`
+	UserID          string                `json:"user_id"`
+	BetAmount       Amount                `json:"bet_amount"`
+	Legs            []DiceParlayLeg       `json:"legs"`
+	LegsResults     []DiceParlayLegResult `json:"legs_results"`
+	AllWon          bool                  `json:"all_won"`
+	TotalMultiplier float64               `json:"total_multiplier"`
+	Payout          Amount                `json:"payout"`
+	ServerSeed      string                `json:"server_seed"`
+	ClientSeed      string                `json:"client_seed"`
+	CreatedAt       time.Time             `json:"created_at"`
+}
+
+// DiceParlayOdds is the theoretical return of a parlay
+type DiceParlayOdds struct {
+	Legs            int     `json:"legs"`
+	TotalMultiplier float64 `json:"total_multiplier"`
+	WinProbability  float64 `json:"win_probability"`
+}
+
+// validateDiceParlayLegs returns a message for the player, or "" if every leg
+// is a valid over/under bet
+func validateDiceParlayLegs(legs []DiceParlayLeg) string {
+	if len(legs) < DICE_PARLAY_MIN_LEGS || len(legs) > DICE_PARLAY_MAX_LEGS {
+		return fmt.Sprintf("A parlay must have between %d and %d legs", DICE_PARLAY_MIN_LEGS, DICE_PARLAY_MAX_LEGS)
+	}
+	for i, leg := range legs {
+		if message := validateDiceTarget(leg.Target, leg.IsOver); message != "" {
+			return fmt.Sprintf("Leg %d: %s", i+1, message)
+		}
+	}
+	return ""
+}
+
+// validateDiceParlay checks a parlay without touching balances
+func validateDiceParlay(req DiceParlayRequest) string {
+	if message := validateBetAmount(req.Amount); message != "" {
+		return message
+	}
+	if message := validateDiceParlayLegs(req.Legs); message != "" {
+		return message
+	}
+	return ValidateClientSeed(req.ClientSeed)
+}
+
+// parlayMultiplier is the product of the leg multipliers, less
+// DICE_PARLAY_LEG_EDGE per leg
+func parlayMultiplier(legs []DiceParlayLeg, edge float64) float64 {
+	multiplier := 1.0
+	for _, leg := range legs {
+		multiplier *= overUnderMultiplier(leg.Target, leg.IsOver, edge) * (1.0 - DICE_PARLAY_LEG_EDGE)
+	}
+
+	// Round down to 2 decimal places, ignoring float error just below a whole cent
+	return math.Floor(multiplier*100+1e-9) / 100.0
+}
+
+// checkParlayPayout returns a message for the player, or "" if neither the
+// multiplier nor what amount would win at it exceeds maxPayout. A zero
+// maxPayout disables the check.
+func checkParlayPayout(multiplier, amount, maxPayout float64) string {
+	if maxPayout <= 0 {
+		return ""
+	}
+	if multiplier > maxPayout {
+		return fmt.Sprintf("Parlay multiplier exceeds the maximum payout of %.2f", maxPayout)
+	}
+	betAmount, _ := NewAmount(amount)
+	if betAmount.Mul(multiplier).Float64() > maxPayout {
+		return fmt.Sprintf("Bet could pay more than the maximum payout of %.2f", maxPayout)
+	}
+	return ""
+}
+
+// NewDiceParlayOdds returns the payout multiplier and win probability of a
+// validated set of legs under the given house edge
+func NewDiceParlayOdds(legs []DiceParlayLeg, edge float64) DiceParlayOdds {
+	probability := 1.0
+	for _, leg := range legs {
+		probability *= overUnderWinChance(leg.Target, leg.IsOver)
+	}
+	return DiceParlayOdds{
+		Legs:            len(legs),
+		TotalMultiplier: parlayMultiplier(legs, edge),
+		WinProbability:  probability,
+	}
+}
+
+// rollParlayLegs rolls each leg with its own nonce, counting up from
+// firstNonce, and reports whether all of them won
+func rollParlayLegs(legs []DiceParlayLeg, serverSeed, clientSeed string, firstNonce int, edge float64) ([]DiceParlayLegResult, bool) {
+	results := make([]DiceParlayLegResult, len(legs))
+	allWon := true
+	for i, leg := range legs {
+		nonce := firstNonce + i
+		roll := rollFromFraction(rollFraction(serverSeed, clientSeed, nonce))
+		win := roll < leg.Target
+		if leg.IsOver {
+			win = roll > leg.Target
+		}
+		results[i] = DiceParlayLegResult{
+			Roll:       roll,
+			Win:        win,
+			Multiplier: overUnderMultiplier(leg.Target, leg.IsOver, edge),
+			Nonce:      nonce,
+		}
+		allWon = allWon && win
+	}
+	return results, allWon
+}
+
+// rollParlay takes the stake once, rolls every leg and pays out only if all
+// of them won
+func (d *DiceEngine) rollParlay(ctx context.Context, req DiceParlayRequest) (DiceParlayResponse, error) {
+	if message := validateDiceParlay(req); message != "" {
+		return DiceParlayResponse{Success: false, Message: message}, nil
+	}
+	edge := GetCurrentHouseEdge(GameTypeDice)
+	totalMultiplier := parlayMultiplier(req.Legs, edge)
+	if message := checkParlayPayout(totalMultiplier, req.Amount, d.maxPayout); message != "" {
+		return DiceParlayResponse{Success: false, Message: message}, nil
+	}
+
+	if err := checkMaintenance(ctx, d.redisClient, GameTypeDice); err != nil {
+		return DiceParlayResponse{}, err
+	}
+
+	if err := d.sessions.acquire(ctx, 1); err != nil {
+		return DiceParlayResponse{}, err
+	}
+	defer d.sessions.release(ctx, 1)
+
+	// Check user balance
+	balanceKey := REDIS_KEY_USER_BALANCE + req.UserID
+	balance, err := d.redisClient.Get(ctx, balanceKey).Float64()
+	if err != nil || balance < req.Amount {
+		return DiceParlayResponse{
+			Success: false,
+			Message: "Insufficient balance",
+			Balance: balance,
+		}, nil
+	}
+
+	// Deduct balance
+	newBalance, err := d.redisClient.IncrByFloat(ctx, balanceKey, -req.Amount).Result()
+	if err != nil || newBalance < 0 {
+		d.redisClient.IncrByFloat(ctx, balanceKey, req.Amount) // Rollback
+		return DiceParlayResponse{
+			Success: false,
+			Message: "Transaction failed",
+		}, nil
+	}
+	betAmount, _ := NewAmount(req.Amount) // Precision checked by validateBetAmount
+	d.hub.NotifyBalance(req.UserID, newBalance, -betAmount, BalanceReasonBet)
+	recordWager(ctx, d.redisClient, req.UserID, betAmount)
+
+	// Generate provably fair results, one nonce per leg
+	firstNonce := d.nonce + 1
+	d.nonce += len(req.Legs)
+	serverSeed := GenerateSeed()
+	clientSeed := clientSeedOrGenerate(req.ClientSeed)
+	results, allWon := rollParlayLegs(req.Legs, serverSeed, clientSeed, firstNonce, edge)
+
+	var payout Amount
+	finalBalance := newBalance
+	if allWon {
+		payout = betAmount.Mul(totalMultiplier)
+		finalBalance, err = d.redisClient.IncrByFloat(ctx, balanceKey, payout.Float64()).Result()
+		if err != nil {
+			return DiceParlayResponse{
+				Success: false,
+				Message: "Failed to credit payout",
+			}, nil
+		}
+		d.hub.NotifyBalance(req.UserID, finalBalance, payout, BalanceReasonPayout)
+	}
+
+	playedAt := time.Now()
+	gameID := fmt.Sprintf("DICEPARLAY-%s-%d", req.UserID, playedAt.UnixNano())
+	gameState := DiceParlayGameState{
+		GameID:          gameID,
+		UserID:          req.UserID,
+		BetAmount:       betAmount,
+		Legs:            req.Legs,
+		LegsResults:     results,
+		AllWon:          allWon,
+		TotalMultiplier: totalMultiplier,
+		Payout:          payout,
+		ServerSeed:      serverSeed,
+		ClientSeed:      clientSeed,
+		CreatedAt:       playedAt,
+	}
+	gameJSON, _ := json.Marshal(gameState)
+	d.redisClient.Set(ctx, REDIS_KEY_DICE_PARLAY+gameID, string(gameJSON), 1*time.Hour)
+	recordActivity(ctx, d.redisClient, REDIS_KEY_DICE_ROLLS)
+
+	outcome := GameOutcome{
+		UserID:   req.UserID,
+		GameType: GameTypeDice,
+		Wager:    betAmount,
+		Payout:   payout,
+	}
+	suspicious := d.anomaly.Record(ctx, outcome)
+	recordHouseProfit(ctx, d.redisClient, outcome)
+	d.hub.GameSettled(notifications.EventDiceRoll, outcome)
+	d.hub.BetSettled(ctx, outcome.UserID)
+
+	rolls := make([]string, len(results))
+	for i, result := range results {
+		rolls[i] = fmt.Sprintf("%.2f", result.Roll)
+	}
+	log.Printf("[DICE] User %s rolled a %d-leg parlay (%s), all won: %v, payout %s",
+		req.UserID, len(req.Legs), strings.Join(rolls, ", "), allWon, payout)
+
+	return DiceParlayResponse{
+		Success:         true,
+		Message:         "Parlay rolled successfully",
+		GameID:          gameID,
+		LegsResults:     results,
+		AllWon:          allWon,
+		TotalMultiplier: totalMultiplier,
+		Payout:          payout,
+		Balance:         finalBalance,
+		ServerSeed:      serverSeed,
+		ClientSeed:      clientSeed,
+		Suspicious:      suspicious,
+	}, nil
+}
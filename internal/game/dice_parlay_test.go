@@ -0,0 +1,172 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// alwaysWins is a leg no roll from 0 to 99.99 can lose
+var alwaysWins = DiceParlayLeg{Target: 100, IsOver: false}
+
+func TestParlayOdds(t *testing.T) {
+	legs := []DiceParlayLeg{{Target: 50, IsOver: true}, {Target: 50, IsOver: false}}
+	odds := NewDiceParlayOdds(legs, DICE_HOUSE_EDGE)
+
+	// 1.98 * 1.98 * 0.95^2 = 3.538...
+	if odds.Legs != 2 || odds.TotalMultiplier != 3.53 || math.Abs(odds.WinProbability-0.25) > 1e-9 {
+		t.Errorf("NewDiceParlayOdds() = %+v, want 3.53x at 25%%", odds)
+	}
+
+	legs = append(legs, DiceParlayLeg{Target: 25, IsOver: false})
+	if odds := NewDiceParlayOdds(legs, DICE_HOUSE_EDGE); odds.TotalMultiplier != 13.31 || math.Abs(odds.WinProbability-0.0625) > 1e-9 {
+		t.Errorf("NewDiceParlayOdds() of 3 legs = %+v, want 13.31x at 6.25%%", odds)
+	}
+}
+
+func TestValidateDiceParlay(t *testing.T) {
+	tests := []struct {
+		name string
+		legs []DiceParlayLeg
+		ok   bool
+	}{
+		{"one leg", []DiceParlayLeg{alwaysWins}, false},
+		{"two legs", []DiceParlayLeg{alwaysWins, alwaysWins}, true},
+		{"five legs", []DiceParlayLeg{alwaysWins, alwaysWins, alwaysWins, alwaysWins, alwaysWins}, true},
+		{"six legs", []DiceParlayLeg{alwaysWins, alwaysWins, alwaysWins, alwaysWins, alwaysWins, alwaysWins}, false},
+		{"over 99", []DiceParlayLeg{alwaysWins, {Target: 99, IsOver: true}}, false},
+	}
+
+	for _, tt := range tests {
+		message := validateDiceParlay(DiceParlayRequest{UserID: "user1", Amount: 10, Legs: tt.legs})
+		if (message == "") != tt.ok {
+			t.Errorf("%s: validateDiceParlay() = %q, want valid %v", tt.name, message, tt.ok)
+		}
+	}
+}
+
+func TestRollParlayLegs_ThreeLegsAllWin(t *testing.T) {
+	legs := []DiceParlayLeg{alwaysWins, alwaysWins, alwaysWins}
+	results, allWon := rollParlayLegs(legs, "server-seed", "client-seed", 7, DICE_HOUSE_EDGE)
+
+	if !allWon || len(results) != 3 {
+		t.Fatalf("rollParlayLegs() = %+v, %v; want 3 winning legs", results, allWon)
+	}
+	for i, result := range results {
+		want := rollFromFraction(rollFraction("server-seed", "client-seed", 7+i))
+		if result.Nonce != 7+i || result.Roll != want || !result.Win || result.Multiplier != 0.99 {
+			t.Errorf("leg %d = %+v, want a winning %.2f at nonce %d", i+1, result, want, 7+i)
+		}
+	}
+}
+
+func TestRollParlayLegs_FirstLegBust(t *testing.T) {
+	firstRoll := rollFromFraction(rollFraction("server-seed", "client-seed", 1))
+	legs := []DiceParlayLeg{{Target: firstRoll, IsOver: true}, alwaysWins, alwaysWins}
+	results, allWon := rollParlayLegs(legs, "server-seed", "client-seed", 1, DICE_HOUSE_EDGE)
+
+	if allWon || results[0].Win {
+		t.Fatalf("rollParlayLegs() = %+v, %v; want the first leg to lose", results, allWon)
+	}
+	if !results[1].Win || !results[2].Win {
+		t.Errorf("rollParlayLegs() = %+v, want the later legs still rolled", results)
+	}
+}
+
+func TestDiceEngine_Parlay(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	ctx := context.Background()
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"user1", 100.0, 0)
+
+	engine := NewDiceEngine(client, nil)
+	engine.signingKey = "signing-key"
+	resp, err := engine.ProcessAction(ctx, DiceModeParlay, DiceParlayRequest{
+		UserID:     "user1",
+		Amount:     10,
+		Legs:       []DiceParlayLeg{alwaysWins, alwaysWins},
+		ClientSeed: "parlayseed",
+	})
+	if err != nil {
+		t.Fatalf("ProcessAction(parlay) error: %v", err)
+	}
+	parlay := resp.(DiceParlayResponse)
+
+	// 0.99 * 0.99 * 0.95^2 = 0.8845...
+	if !parlay.Success || !parlay.AllWon || parlay.TotalMultiplier != 0.88 || parlay.Payout != amountOf(8.8) {
+		t.Fatalf("parlay = %+v, want both legs won paying 8.80", parlay)
+	}
+	if parlay.Balance != 98.8 {
+		t.Errorf("balance = %v, want 98.8 after staking 10 once", parlay.Balance)
+	}
+	if parlay.LegsResults[1].Nonce != parlay.LegsResults[0].Nonce+1 {
+		t.Errorf("legs = %+v, want consecutive nonces", parlay.LegsResults)
+	}
+
+	data, _ := json.Marshal(parlay)
+	if !VerifyResultSignature(string(data), parlay.ResultSignature, "signing-key") {
+		t.Errorf("parlay response %s is not signed", data)
+	}
+
+	stored, err := client.Get(ctx, REDIS_KEY_DICE_PARLAY+parlay.GameID).Result()
+	if err != nil {
+		t.Fatalf("parlay %s not stored: %v", parlay.GameID, err)
+	}
+	var state DiceParlayGameState
+	json.Unmarshal([]byte(stored), &state)
+	if state.ClientSeed != "parlayseed" || state.ServerSeed != parlay.ServerSeed || len(state.LegsResults) != 2 {
+		t.Errorf("stored parlay = %+v, want both legs and the seeds", state)
+	}
+
+	resp, _ = engine.ProcessAction(ctx, DiceModeParlay, DiceParlayRequest{UserID: "user1", Amount: 10, Legs: []DiceParlayLeg{alwaysWins}})
+	if parlay := resp.(DiceParlayResponse); parlay.Success {
+		t.Errorf("single leg parlay = %+v, want it rejected", parlay)
+	}
+}
+
+func TestDiceEngine_ParlayMaxPayout(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	ctx := context.Background()
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"user1", 1000.0, 0)
+
+	// 3.53x, so 100.00 could win 353.00
+	legs := []DiceParlayLeg{{Target: 50, IsOver: true}, {Target: 50, IsOver: false}}
+	engine := NewDiceEngine(client, nil)
+
+	engine.maxPayout = 353
+	resp, _ := engine.ProcessAction(ctx, DiceModeParlay, DiceParlayRequest{UserID: "user1", Amount: 100, Legs: legs})
+	if parlay := resp.(DiceParlayResponse); !parlay.Success {
+		t.Errorf("parlay paying exactly the maximum = %+v, want it accepted", parlay)
+	}
+
+	engine.maxPayout = 352.99
+	resp, _ = engine.ProcessAction(ctx, DiceModeParlay, DiceParlayRequest{UserID: "user1", Amount: 100, Legs: legs})
+	if parlay := resp.(DiceParlayResponse); parlay.Success {
+		t.Errorf("parlay paying over the maximum = %+v, want it rejected", parlay)
+	}
+	if balance, _ := client.Get(ctx, REDIS_KEY_USER_BALANCE+"user1").Float64(); balance == 1000 {
+		t.Fatal("accepted parlay took no stake")
+	}
+
+	engine.maxPayout = 3.53
+	if _, err := engine.ProcessAction(ctx, "parlay_odds", legs); err != nil {
+		t.Errorf("preview at the maximum multiplier: %v, want the odds", err)
+	}
+	engine.maxPayout = 3.52
+	if _, err := engine.ProcessAction(ctx, "parlay_odds", legs); err == nil {
+		t.Error("preview over the maximum multiplier succeeded")
+	}
+	engine.maxPayout = MAX_PAYOUT
+	// Five legs under 1.01 multiply to billions
+	longShot := []DiceParlayLeg{{Target: 1.01}, {Target: 1.01}, {Target: 1.01}, {Target: 1.01}, {Target: 1.01}}
+	if _, err := engine.ProcessAction(ctx, "parlay_odds", longShot); err == nil {
+		t.Errorf("preview of five long shots (%.0fx) succeeded", parlayMultiplier(longShot, DICE_HOUSE_EDGE))
+	}
+}
@@ -0,0 +1,59 @@
+package game
+
+// DiceRollResultV2 is the outcome of a roll in the v2 response schema
+type DiceRollResultV2 struct {
+	Value  float64 `json:"value"`
+	Win    bool    `json:"win"`
+	Payout Amount  `json:"payout"`
+}
+
+// DiceRollMetaV2 holds what a player needs to verify a roll. Verified is true
+// when the seeds and nonce reproduce the roll.
+type DiceRollMetaV2 struct {
+	ServerSeed string `json:"server_seed"`
+	ClientSeed string `json:"client_seed"`
+	Nonce      int    `json:"nonce"`
+	Verified   bool   `json:"verified"`
+}
+
+// DiceRollResponseV2 is DiceRollResponse in the v2 schema, with the outcome
+// and its proof grouped. Rejected rolls have neither. It is not signed; the
+// signature of a roll covers the v1 response.
+type DiceRollResponseV2 struct {
+	Success    bool              `json:"success"`
+	Message    string            `json:"message"`
+	GameID     string            `json:"game_id,omitempty"`
+	Result     *DiceRollResultV2 `json:"result,omitempty"`
+	Meta       *DiceRollMetaV2   `json:"meta,omitempty"`
+	Balance    float64           `json:"balance,omitempty"`
+	Suspicious bool              `json:"suspicious,omitempty"`
+	Currency
+}
+
+// NewDiceRollResponseV2 restructures a v1 roll response
+func NewDiceRollResponseV2(resp DiceRollResponse) DiceRollResponseV2 {
+	v2 := DiceRollResponseV2{
+		Success:    resp.Success,
+		Message:    resp.Message,
+		GameID:     resp.GameID,
+		Balance:    resp.Balance,
+		Suspicious: resp.Suspicious,
+		Currency:   resp.Currency,
+	}
+	if !resp.Success {
+		return v2
+	}
+
+	v2.Result = &DiceRollResultV2{
+		Value:  resp.RollResult,
+		Win:    resp.Win,
+		Payout: resp.Payout,
+	}
+	v2.Meta = &DiceRollMetaV2{
+		ServerSeed: resp.ServerSeed,
+		ClientSeed: resp.ClientSeed,
+		Nonce:      resp.Nonce,
+		Verified:   rollFromFraction(rollFraction(resp.ServerSeed, resp.ClientSeed, resp.Nonce)) == resp.RollResult,
+	}
+	return v2
+}
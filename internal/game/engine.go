@@ -23,6 +23,8 @@ type GameEngine interface {
 	GetState() interface{}
 	PlaceBet(ctx context.Context, req interface{}) (interface{}, error)
 	ProcessAction(ctx context.Context, action string, req interface{}) (interface{}, error)
+	HealthCheck(ctx context.Context) (HealthStatus, error)
+	GameInfo() GameMetadata
 }
 
 type GameFactory struct {
@@ -0,0 +1,108 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	REDIS_KEY_SSE_BUFFER = "sse:buffer:" // Recent events of an SSE client, oldest first
+	REDIS_KEY_SSE_SEQ    = "sse:seq:"    // ID of the last event sent to an SSE client
+
+	SSE_BUFFER_SIZE = 100
+	SSE_BUFFER_TTL  = 60 * time.Second // How long a client may stay away and still catch up
+)
+
+// SSEEvent is a hub message as sent to an SSE client. IDs increase by one
+// per client.
+type SSEEvent struct {
+	ID   int64           `json:"id"`
+	Data json.RawMessage `json:"data"`
+}
+
+// EventBuffer keeps the last events sent to an SSE client, so a client that
+// reconnects with Last-Event-ID gets the events it missed. It expires
+// SSE_BUFFER_TTL after the last event or Touch.
+type EventBuffer struct {
+	redisClient *redis.Client
+	clientID    string
+
+	mu     sync.Mutex
+	lastID int64
+}
+
+// NewEventBuffer opens the buffer of clientID, continuing its event IDs if it
+// has not expired
+func NewEventBuffer(ctx context.Context, redisClient *redis.Client, clientID string) (*EventBuffer, error) {
+	lastID, err := redisClient.Get(ctx, REDIS_KEY_SSE_SEQ+clientID).Int64()
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+	return &EventBuffer{redisClient: redisClient, clientID: clientID, lastID: lastID}, nil
+}
+
+// LastID returns the ID of the last event appended
+func (b *EventBuffer) LastID() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.lastID
+}
+
+// Append stores data as the client's next event, dropping the oldest beyond
+// SSE_BUFFER_SIZE
+func (b *EventBuffer) Append(ctx context.Context, data []byte) (SSEEvent, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	event := SSEEvent{ID: b.lastID + 1, Data: data}
+	entry, err := json.Marshal(event)
+	if err != nil {
+		return SSEEvent{}, err
+	}
+
+	bufferKey := REDIS_KEY_SSE_BUFFER + b.clientID
+	pipe := b.redisClient.TxPipeline()
+	pipe.RPush(ctx, bufferKey, entry)
+	pipe.LTrim(ctx, bufferKey, -SSE_BUFFER_SIZE, -1)
+	pipe.Expire(ctx, bufferKey, SSE_BUFFER_TTL)
+	pipe.Set(ctx, REDIS_KEY_SSE_SEQ+b.clientID, event.ID, SSE_BUFFER_TTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return SSEEvent{}, err
+	}
+
+	b.lastID = event.ID
+	return event, nil
+}
+
+// Since returns the buffered events after lastID, oldest first
+func (b *EventBuffer) Since(ctx context.Context, lastID int64) ([]SSEEvent, error) {
+	entries, err := b.redisClient.LRange(ctx, REDIS_KEY_SSE_BUFFER+b.clientID, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var events []SSEEvent
+	for _, entry := range entries {
+		var event SSEEvent
+		if err := json.Unmarshal([]byte(entry), &event); err != nil {
+			continue
+		}
+		if event.ID > lastID {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// Touch keeps the buffer of a connected client that has had no events lately
+func (b *EventBuffer) Touch(ctx context.Context) error {
+	pipe := b.redisClient.TxPipeline()
+	pipe.Expire(ctx, REDIS_KEY_SSE_BUFFER+b.clientID, SSE_BUFFER_TTL)
+	pipe.Expire(ctx, REDIS_KEY_SSE_SEQ+b.clientID, SSE_BUFFER_TTL)
+	_, err := pipe.Exec(ctx)
+	return err
+}
@@ -0,0 +1,44 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestEventBuffer(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	ctx := context.Background()
+
+	buffer, _ := NewEventBuffer(ctx, client, "client-1")
+	for i := 1; i <= SSE_BUFFER_SIZE+20; i++ {
+		if _, err := buffer.Append(ctx, []byte(fmt.Sprintf(`{"n":%d}`, i))); err != nil {
+			t.Fatalf("Append() error: %v", err)
+		}
+	}
+
+	events, _ := buffer.Since(ctx, 0)
+	if len(events) != SSE_BUFFER_SIZE || events[0].ID != 21 || events[len(events)-1].ID != SSE_BUFFER_SIZE+20 {
+		t.Fatalf("Since(0) = %d events from %d, want the last %d", len(events), events[0].ID, SSE_BUFFER_SIZE)
+	}
+	if events, _ := buffer.Since(ctx, 118); len(events) != 2 || string(events[0].Data) != `{"n":119}` {
+		t.Errorf("Since(118) = %+v, want events 119 and 120", events)
+	}
+
+	// A reconnecting client continues its IDs
+	reopened, _ := NewEventBuffer(ctx, client, "client-1")
+	if event, _ := reopened.Append(ctx, []byte(`{}`)); event.ID != SSE_BUFFER_SIZE+21 {
+		t.Errorf("next ID = %d, want %d", event.ID, SSE_BUFFER_SIZE+21)
+	}
+
+	mr.FastForward(SSE_BUFFER_TTL)
+	expired, _ := NewEventBuffer(ctx, client, "client-1")
+	if events, _ := expired.Since(ctx, 0); expired.LastID() != 0 || len(events) != 0 {
+		t.Errorf("after the TTL: last ID %d and %d events, want an empty buffer", expired.LastID(), len(events))
+	}
+}
@@ -0,0 +1,197 @@
+package game
+
+import (
+	"context"
+	"log"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	MAX_HOUSE_EXPOSURE      = 1000000.0 // Total risk exposure above which admins are alerted
+	EXPOSURE_CHECK_INTERVAL = 10 * time.Second
+)
+
+// LiveSession is a game in progress. PotentialPayout is the most it can still
+// win, capped at MAX_PAYOUT; RiskExposure is what the house would lose if it
+// did.
+type LiveSession struct {
+	SessionID           string   `json:"session_id"`
+	GameType            GameType `json:"game_type"`
+	UserID              string   `json:"user_id"`
+	BetAmount           Amount   `json:"bet_amount"`
+	PotentialPayout     Amount   `json:"potential_payout"`
+	StartedAtAgoSeconds int64    `json:"started_at_ago_seconds"`
+	RiskExposure        Amount   `json:"risk_exposure"`
+}
+
+func newLiveSession(gameType GameType, sessionID, userID string, bet, potential Amount, startedAt, now time.Time) LiveSession {
+	potential = min(potential, Amount(getEnvAsFloat("MAX_PAYOUT", MAX_PAYOUT)*AMOUNT_SCALE))
+	return LiveSession{
+		SessionID:           sessionID,
+		GameType:            gameType,
+		UserID:              userID,
+		BetAmount:           bet,
+		PotentialPayout:     potential,
+		StartedAtAgoSeconds: int64(now.Sub(startedAt).Seconds()),
+		RiskExposure:        potential - bet,
+	}
+}
+
+// LiveSessions lists the bets of the current round that have not cashed out.
+// The crash point is already drawn, so a bet can win at most its stake times
+// the crash point, or times its auto-cashout target if that is lower.
+func (m *Manager) LiveSessions(ctx context.Context) ([]LiveSession, error) {
+	round := m.GetCurrentRound()
+	if round == nil {
+		return nil, nil
+	}
+
+	now := time.Now()
+	var sessions []LiveSession
+	for _, bet := range m.loadActiveBets(round.RoundID) {
+		if bet.CashedOut {
+			continue
+		}
+		multiplier := round.CrashMultiplier
+		if bet.AutoCashout > 0 {
+			multiplier = math.Min(multiplier, bet.triggerMultiplier())
+		}
+		sessions = append(sessions, newLiveSession(GameTypeAviator, bet.BetID, bet.UserID, bet.Amount, bet.Amount.Mul(multiplier), bet.PlacedAt, now))
+	}
+	return sessions, ctx.Err()
+}
+
+// liveSessions lists the active Mines games. A game can win at most the
+// payout of revealing every safe tile.
+func (m *MinesEngine) liveSessions(ctx context.Context) ([]LiveSession, error) {
+	now := time.Now()
+	var sessions []LiveSession
+	iter := m.redisClient.Scan(ctx, 0, REDIS_KEY_MINES_GAME+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		gameJSON, err := m.redisClient.Get(ctx, iter.Val()).Result()
+		if err != nil {
+			continue // Expired since the scan found it
+		}
+		gameState, err := decodeMinesGame(gameJSON)
+		if err != nil || gameState.Status != "ACTIVE" {
+			continue
+		}
+		safeTiles := gameState.GridSize - gameState.MineCount
+		potential := m.payoutWithEdge(gameState.BetAmount, gameState.MineCount, safeTiles, gameState.GridSize, gameState.houseEdge())
+		sessions = append(sessions, newLiveSession(GameTypeMines, gameState.GameID, gameState.UserID, gameState.BetAmount, potential, gameState.CreatedAt, now))
+	}
+	return sessions, iter.Err()
+}
+
+// CollectLiveSessions lists the Aviator bets and, when the engine is
+// registered, the Mines games in progress
+func CollectLiveSessions(ctx context.Context, manager *Manager, factory *GameFactory) ([]LiveSession, error) {
+	sessions, err := manager.LiveSessions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if engine, exists := factory.GetEngine(GameTypeMines); exists {
+		mines, err := engine.ProcessAction(ctx, "live_sessions", nil)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, mines.([]LiveSession)...)
+	}
+	return sessions, nil
+}
+
+// TotalExposure sums the risk exposure of sessions
+func TotalExposure(sessions []LiveSession) Amount {
+	var total Amount
+	for _, session := range sessions {
+		total = total.Add(session.RiskExposure)
+	}
+	return total
+}
+
+// ExposureAlert is sent to admin-subscribed clients when the total house
+// exposure rises above the limit
+type ExposureAlert struct {
+	Type     string `json:"type"`
+	Message  string `json:"message"`
+	Exposure Amount `json:"exposure"`
+}
+
+// ExposureMonitor periodically totals the house exposure of live sessions
+// and alerts admins once each time it rises above the limit
+type ExposureMonitor struct {
+	manager     *Manager
+	factory     *GameFactory
+	hub         *Hub
+	maxExposure Amount
+	interval    time.Duration
+
+	mu       sync.Mutex
+	alerting bool // Above the limit at the last check
+
+	stopChan chan struct{}
+	stopOnce sync.Once
+}
+
+// NewExposureMonitor creates the monitor using MAX_HOUSE_EXPOSURE from the
+// environment
+func NewExposureMonitor(manager *Manager, factory *GameFactory, hub *Hub) *ExposureMonitor {
+	maxExposure, _ := NewAmount(getEnvAsFloat("MAX_HOUSE_EXPOSURE", MAX_HOUSE_EXPOSURE))
+	return &ExposureMonitor{
+		manager:     manager,
+		factory:     factory,
+		hub:         hub,
+		maxExposure: maxExposure,
+		interval:    EXPOSURE_CHECK_INTERVAL,
+		stopChan:    make(chan struct{}),
+	}
+}
+
+// Start checks the exposure on every interval until Stop is called
+func (e *ExposureMonitor) Start() {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	log.Printf("[EXPOSURE] Alerting admins above a house exposure of %s", e.maxExposure)
+
+	for {
+		select {
+		case <-e.stopChan:
+			return
+		case <-ticker.C:
+			if _, err := e.CheckOnce(context.Background()); err != nil {
+				log.Printf("[EXPOSURE] Check failed: %v", err)
+			}
+		}
+	}
+}
+
+// Stop ends the background loop
+func (e *ExposureMonitor) Stop() {
+	e.stopOnce.Do(func() { close(e.stopChan) })
+}
+
+// CheckOnce totals the current exposure, alerting admins if it has risen
+// above the limit since the last check
+func (e *ExposureMonitor) CheckOnce(ctx context.Context) (Amount, error) {
+	sessions, err := CollectLiveSessions(ctx, e.manager, e.factory)
+	if err != nil {
+		return 0, err
+	}
+	exposure := TotalExposure(sessions)
+
+	e.mu.Lock()
+	above := exposure > e.maxExposure
+	alert := above && !e.alerting
+	e.alerting = above
+	e.mu.Unlock()
+
+	if alert {
+		log.Printf("[EXPOSURE] House exposure of %s is above %s", exposure, e.maxExposure)
+		e.hub.BroadcastToAdmins(ExposureAlert{Type: "admin_alert", Message: "High exposure", Exposure: exposure})
+	}
+	return exposure, nil
+}
@@ -0,0 +1,69 @@
+package game
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestExposureMonitor(t *testing.T) {
+	m, client := newTestManager(t)
+	go m.hub.Run()
+	ctx := context.Background()
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"user1", 1000.0, 0)
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"user2", 1000.0, 0)
+
+	m.setTestRound("R-exposure", "BETTING")
+	m.stateMutex.Lock()
+	m.currentRound.CrashMultiplier = 5
+	m.stateMutex.Unlock()
+	placeTestBet(t, m, "user1", 10, 2) // Cashes out at 2x: pays 20
+	placeTestBet(t, m, "user2", 10, 0) // Rides to the 5x crash: pays 50
+
+	factory := NewGameFactory(client, m.hub)
+	mines := NewMinesEngine(client, nil)
+	factory.RegisterEngine(mines)
+	if resp, err := mines.PlaceBet(ctx, MinesBetRequest{UserID: "user1", Amount: 10, MineCount: 24}); err != nil || !resp.(MinesBetResponse).Success {
+		t.Fatalf("Mines bet = %+v, %v; want success", resp, err)
+	}
+
+	sessions, err := CollectLiveSessions(ctx, m, factory)
+	if err != nil || len(sessions) != 3 {
+		t.Fatalf("CollectLiveSessions() = %+v, %v; want 3 sessions", sessions, err)
+	}
+	// 10 + 40 on Aviator, and 232.50 for the one safe tile of a 24 mine game at 24.25x
+	if total := TotalExposure(sessions); total != amountOf(282.5) {
+		t.Errorf("total exposure = %s, want 282.50", total)
+	}
+
+	admin, player := &mockConn{}, &mockConn{}
+	m.hub.RegisterClient(admin, "admin")
+	m.hub.RegisterClient(player, "user1")
+	waitForClients(t, m.hub, 2)
+	m.hub.SubscribeAdmin(admin)
+
+	monitor := NewExposureMonitor(m, factory, m.hub)
+	monitor.maxExposure = amountOf(300)
+	if _, err := monitor.CheckOnce(ctx); err != nil {
+		t.Fatalf("CheckOnce() error: %v", err)
+	}
+
+	monitor.maxExposure = amountOf(250)
+	for i := 0; i < 2; i++ {
+		if exposure, err := monitor.CheckOnce(ctx); err != nil || exposure != amountOf(282.5) {
+			t.Fatalf("CheckOnce() = %s, %v; want 282.50", exposure, err)
+		}
+	}
+	alert := waitForMessages(t, admin, 1)[0]
+	if alert["type"] != "admin_alert" || alert["message"] != "High exposure" || alert["exposure"] != 282.5 {
+		t.Errorf("alert = %v, want High exposure of 282.50", alert)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if admin.count() != 1 {
+		t.Errorf("admin received %d alerts, want one while the exposure stays above the limit", admin.count())
+	}
+	if player.count() != 0 {
+		t.Errorf("player received %d messages, want no admin alerts", player.count())
+	}
+}
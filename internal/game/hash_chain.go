@@ -0,0 +1,137 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+)
+
+const (
+	REDIS_KEY_CHAIN_TERMINAL_SEED = "crash:chain:terminal_seed"
+	REDIS_KEY_CHAIN_LENGTH        = "crash:chain:length"
+	REDIS_KEY_CHAIN_CURRENT_SEED  = "crash:chain:current_seed"
+	REDIS_KEY_CHAIN_ROUND_COUNT   = "crash:chain:round_count"
+	REDIS_KEY_CHAIN_HISTORY       = "crash:chain:history"
+
+	HASH_CHAIN_LENGTH       = 10000
+	HASH_CHAIN_HISTORY_SIZE = 1000
+)
+
+var (
+	ErrRoundNotInChain   = errors.New("round not found in chain history")
+	ErrInvalidChainRange = errors.New("from_round is after to_round")
+)
+
+// hashChainSeed hashes seed the given number of times
+func hashChainSeed(seed string, steps int) string {
+	for i := 0; i < steps; i++ {
+		seed = HashCommitment(seed)
+	}
+	return seed
+}
+
+// nextChainSeed returns the server seed for the next round.
+//
+// Seeds are drawn from a reverse hash chain: a random terminal seed is hashed
+// chainLength times and rounds consume the chain from the far end, so round k
+// uses hash^(length-k)(terminal). Each revealed seed is therefore the hash of
+// the next round's seed, which lets players verify the whole sequence without
+// being able to derive any seed that has not been played yet. A new chain is
+// started when the current one is exhausted.
+func (m *Manager) nextChainSeed() string {
+	count, err := m.redisClient.Incr(m.ctx, REDIS_KEY_CHAIN_ROUND_COUNT).Result()
+	if err != nil {
+		log.Printf("[FAIR] Hash chain unavailable, generating a fresh seed: %v", err)
+		return GenerateSeed()
+	}
+
+	terminal, terr := m.redisClient.Get(m.ctx, REDIS_KEY_CHAIN_TERMINAL_SEED).Result()
+	length, lerr := m.redisClient.Get(m.ctx, REDIS_KEY_CHAIN_LENGTH).Int64()
+	if terr != nil || lerr != nil || count > length {
+		terminal = GenerateSeed()
+		length = int64(m.chainLength)
+		count = 1
+
+		pipe := m.redisClient.TxPipeline()
+		pipe.Set(m.ctx, REDIS_KEY_CHAIN_TERMINAL_SEED, terminal, 0)
+		pipe.Set(m.ctx, REDIS_KEY_CHAIN_LENGTH, length, 0)
+		pipe.Set(m.ctx, REDIS_KEY_CHAIN_ROUND_COUNT, count, 0)
+		if _, err := pipe.Exec(m.ctx); err != nil {
+			log.Printf("[FAIR] Failed to store new hash chain: %v", err)
+		}
+		log.Printf("[FAIR] Started a new hash chain of %d rounds", length)
+	}
+
+	seed := hashChainSeed(terminal, int(length-count))
+	m.redisClient.Set(m.ctx, REDIS_KEY_CHAIN_CURRENT_SEED, seed, 0)
+	return seed
+}
+
+// recordChainRound appends a crashed round's revealed seed to the chain history
+func (m *Manager) recordChainRound(round *RoundState) {
+	data, _ := json.Marshal(RoundRecord{
+		RoundID:         round.RoundID,
+		ServerSeed:      round.ServerSeed,
+		HashCommitment:  round.HashCommitment,
+		ClientSeed:      round.ClientSeed,
+		Nonce:           round.Nonce,
+		CrashMultiplier: round.BaseMultiplier(),
+		CrashedAt:       round.CrashTime,
+	})
+
+	pipe := m.redisClient.Pipeline()
+	pipe.RPush(m.ctx, REDIS_KEY_CHAIN_HISTORY, data)
+	pipe.LTrim(m.ctx, REDIS_KEY_CHAIN_HISTORY, -HASH_CHAIN_HISTORY_SIZE, -1)
+	if _, err := pipe.Exec(m.ctx); err != nil {
+		log.Printf("[FAIR] Failed to record round %s in chain history: %v", round.RoundID, err)
+	}
+}
+
+// ChainRounds returns the revealed rounds from fromRound to toRound inclusive,
+// oldest first. Empty bounds default to the oldest and newest recorded rounds.
+func (m *Manager) ChainRounds(ctx context.Context, fromRound, toRound string) ([]RoundRecord, error) {
+	items, err := m.redisClient.LRange(ctx, REDIS_KEY_CHAIN_HISTORY, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	rounds := make([]RoundRecord, 0, len(items))
+	for _, item := range items {
+		var record RoundRecord
+		if err := json.Unmarshal([]byte(item), &record); err != nil {
+			continue
+		}
+		rounds = append(rounds, record)
+	}
+
+	if len(rounds) == 0 && fromRound == "" && toRound == "" {
+		return rounds, nil
+	}
+
+	from, to := 0, len(rounds)-1
+	if fromRound != "" {
+		if from = findRoundRecord(rounds, fromRound); from < 0 {
+			return nil, ErrRoundNotInChain
+		}
+	}
+	if toRound != "" {
+		if to = findRoundRecord(rounds, toRound); to < 0 {
+			return nil, ErrRoundNotInChain
+		}
+	}
+	if from > to {
+		return nil, ErrInvalidChainRange
+	}
+
+	return rounds[from : to+1], nil
+}
+
+func findRoundRecord(rounds []RoundRecord, roundID string) int {
+	for i, round := range rounds {
+		if round.RoundID == roundID {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,46 @@
+package game
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	HEALTH_STATUS_OK        = "ok"
+	HEALTH_STATUS_UNHEALTHY = "unhealthy"
+)
+
+type HealthStatus struct {
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// newHealthStatus builds the result of a check that started at start
+func newHealthStatus(start time.Time, err error) (HealthStatus, error) {
+	status := HealthStatus{
+		Status:    HEALTH_STATUS_OK,
+		LatencyMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		status.Status = HEALTH_STATUS_UNHEALTHY
+		status.Error = err.Error()
+	}
+	return status, err
+}
+
+// HealthCheckAll runs the health check of every registered engine
+func (gf *GameFactory) HealthCheckAll(ctx context.Context) (map[GameType]HealthStatus, bool) {
+	results := make(map[GameType]HealthStatus, len(gf.engines))
+	healthy := true
+
+	for gameType, engine := range gf.engines {
+		status, err := engine.HealthCheck(ctx)
+		if err != nil {
+			healthy = false
+		}
+		results[gameType] = status
+	}
+
+	return results, healthy
+}
@@ -0,0 +1,49 @@
+package game
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestEngineHealthChecks(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	factory := NewGameFactory(client, nil)
+	factory.RegisterEngine(NewMinesEngine(client, nil))
+	factory.RegisterEngine(NewDiceEngine(client, nil))
+	factory.RegisterEngine(NewPlinkoEngine(client, nil))
+
+	ctx := context.Background()
+
+	t.Run("all engines healthy", func(t *testing.T) {
+		results, healthy := factory.HealthCheckAll(ctx)
+		if !healthy {
+			t.Errorf("expected all engines healthy, got %v", results)
+		}
+		for _, gameType := range []GameType{GameTypeMines, GameTypeDice, GameTypePlinko} {
+			if results[gameType].Status != HEALTH_STATUS_OK {
+				t.Errorf("%s status = %s, want %s", gameType, results[gameType].Status, HEALTH_STATUS_OK)
+			}
+		}
+	})
+
+	t.Run("mines unhealthy without redis", func(t *testing.T) {
+		mr.Close()
+
+		results, healthy := factory.HealthCheckAll(ctx)
+		if healthy {
+			t.Error("expected factory to report unhealthy")
+		}
+		if results[GameTypeMines].Status != HEALTH_STATUS_UNHEALTHY || results[GameTypeMines].Error == "" {
+			t.Errorf("mines = %+v, want unhealthy with error", results[GameTypeMines])
+		}
+		if results[GameTypeDice].Status != HEALTH_STATUS_OK {
+			t.Errorf("dice does not use redis and should stay healthy, got %+v", results[GameTypeDice])
+		}
+	})
+}
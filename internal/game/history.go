@@ -0,0 +1,58 @@
+package game
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	GAME_HISTORY_SIZE          = 100 // Games kept per user and game type
+	GAME_HISTORY_DEFAULT_LIMIT = 20
+	GAME_HISTORY_MAX_LIMIT     = 100
+)
+
+// GameHistoryRequest asks for a page (starting at 1) of a user's recent games
+type GameHistoryRequest struct {
+	UserID string `json:"user_id"`
+	Page   int    `json:"page"`
+	Limit  int    `json:"limit"`
+}
+
+// recordGameHistory adds a game to a user's history sorted set, keeping only
+// the most recent GAME_HISTORY_SIZE games
+func recordGameHistory(ctx context.Context, redisClient *redis.Client, historyKey, gameID string, playedAt time.Time) error {
+	pipe := redisClient.TxPipeline()
+	pipe.ZAdd(ctx, historyKey, redis.Z{Score: float64(playedAt.UnixNano()), Member: gameID})
+	pipe.ZRemRangeByRank(ctx, historyKey, 0, -GAME_HISTORY_SIZE-1)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// loadGameHistory returns the stored state of a page of games, newest first.
+// Games whose state has already expired are skipped.
+func loadGameHistory(ctx context.Context, redisClient *redis.Client, historyKey, gameKeyPrefix string, page, limit int) ([]string, error) {
+	start := int64((page - 1) * limit)
+	gameIDs, err := redisClient.ZRevRange(ctx, historyKey, start, start+int64(limit)-1).Result()
+	if err != nil || len(gameIDs) == 0 {
+		return nil, err
+	}
+
+	keys := make([]string, len(gameIDs))
+	for i, gameID := range gameIDs {
+		keys[i] = gameKeyPrefix + gameID
+	}
+	values, err := redisClient.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	games := make([]string, 0, len(values))
+	for _, value := range values {
+		if data, ok := value.(string); ok {
+			games = append(games, data)
+		}
+	}
+	return games, nil
+}
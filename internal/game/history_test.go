@@ -0,0 +1,50 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestGameHistory_TrimAndPage(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	ctx := context.Background()
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < GAME_HISTORY_SIZE+5; i++ {
+		gameID := fmt.Sprintf("G%03d", i)
+		client.Set(ctx, "test:game:"+gameID, gameID, 0)
+		if err := recordGameHistory(ctx, client, "test:history", gameID, start.Add(time.Duration(i)*time.Second)); err != nil {
+			t.Fatalf("recordGameHistory() error: %v", err)
+		}
+	}
+
+	if size := client.ZCard(ctx, "test:history").Val(); size != GAME_HISTORY_SIZE {
+		t.Errorf("history holds %d games, want %d", size, GAME_HISTORY_SIZE)
+	}
+
+	games, err := loadGameHistory(ctx, client, "test:history", "test:game:", 2, 3)
+	if err != nil {
+		t.Fatalf("loadGameHistory() error: %v", err)
+	}
+	if fmt.Sprint(games) != "[G101 G100 G099]" {
+		t.Errorf("second page = %v, want [G101 G100 G099]", games)
+	}
+
+	// Expired game states are skipped
+	client.Del(ctx, "test:game:G100")
+	games, _ = loadGameHistory(ctx, client, "test:history", "test:game:", 2, 3)
+	if fmt.Sprint(games) != "[G101 G099]" {
+		t.Errorf("second page after expiry = %v, want [G101 G099]", games)
+	}
+
+	if games, err := loadGameHistory(ctx, client, "test:missing", "test:game:", 1, 3); err != nil || len(games) != 0 {
+		t.Errorf("empty history = %v, %v; want none", games, err)
+	}
+}
@@ -0,0 +1,98 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+
+	"github.com/redis/go-redis/v9"
+
+	"aviator/internal/database"
+)
+
+const (
+	// REDIS_KEY_HOUSE_PROFIT_RUNNING is the house's profit over every game settled since it was last reset
+	REDIS_KEY_HOUSE_PROFIT_RUNNING = "admin:profit:running"
+
+	// HOUSE_PROFIT_VARIANCE_ALERT is how far, as a fraction of the theoretical
+	// profit, the actual profit may drift before an alert is raised
+	HOUSE_PROFIT_VARIANCE_ALERT = 0.20
+)
+
+// GameProfitStats is the house's result on one game
+type GameProfitStats struct {
+	TotalWagered   float64 `json:"total_wagered"`
+	TotalPaidOut   float64 `json:"total_paid_out"`
+	GrossProfit    float64 `json:"gross_profit"`
+	GrossProfitPct float64 `json:"gross_profit_pct"` // Of the amount wagered
+}
+
+// HouseProfitReport is the house's result over a period. Variance is the
+// actual gross profit less what the default house edges predict.
+type HouseProfitReport struct {
+	GameProfitStats
+	ByGameType        map[GameType]GameProfitStats `json:"by_game_type"`
+	TheoreticalProfit float64                      `json:"theoretical_profit"`
+	Variance          float64                      `json:"variance"`
+	HighVarianceAlert bool                         `json:"high_variance_alert"`
+	JackpotLiability  float64                      `json:"jackpot_liability"` // No game has a jackpot yet
+}
+
+// NewHouseProfitReport totals the wagers and payouts of each game. Every game
+// type is listed, with zeros when nothing was played.
+func NewHouseProfitReport(profits []database.GameProfit) HouseProfitReport {
+	report := HouseProfitReport{ByGameType: make(map[GameType]GameProfitStats)}
+	for _, gameType := range []GameType{GameTypeAviator, GameTypeMines, GameTypePlinko, GameTypeDice} {
+		report.ByGameType[gameType] = GameProfitStats{}
+	}
+
+	for _, profit := range profits {
+		gameType := GameType(profit.GameType)
+		stats := report.ByGameType[gameType]
+		stats.TotalWagered += profit.Wagered
+		stats.TotalPaidOut += profit.PaidOut
+		report.ByGameType[gameType] = stats
+
+		report.TotalWagered += profit.Wagered
+		report.TotalPaidOut += profit.PaidOut
+		report.TheoreticalProfit += DefaultHouseEdge(gameType) * profit.Wagered
+	}
+
+	for gameType, stats := range report.ByGameType {
+		report.ByGameType[gameType] = stats.withProfit()
+	}
+	report.GameProfitStats = report.GameProfitStats.withProfit()
+	report.TheoreticalProfit = roundCents(report.TheoreticalProfit)
+	report.Variance = roundCents(report.GrossProfit - report.TheoreticalProfit)
+	report.HighVarianceAlert = report.TheoreticalProfit > 0 &&
+		math.Abs(report.Variance) > HOUSE_PROFIT_VARIANCE_ALERT*report.TheoreticalProfit
+	return report
+}
+
+// withProfit fills in the gross profit of the wagered and paid out totals
+func (s GameProfitStats) withProfit() GameProfitStats {
+	s.TotalWagered = roundCents(s.TotalWagered)
+	s.TotalPaidOut = roundCents(s.TotalPaidOut)
+	s.GrossProfit = roundCents(s.TotalWagered - s.TotalPaidOut)
+	if s.TotalWagered > 0 {
+		s.GrossProfitPct = math.Round(s.GrossProfit/s.TotalWagered*10000) / 100
+	}
+	return s
+}
+
+// recordHouseProfit adds a settled game to the running house profit
+func recordHouseProfit(ctx context.Context, client *redis.Client, outcome GameOutcome) {
+	if err := client.IncrByFloat(ctx, REDIS_KEY_HOUSE_PROFIT_RUNNING, (outcome.Wager - outcome.Payout).Float64()).Err(); err != nil {
+		log.Printf("[STATS] Failed to record the house profit of %s: %v", outcome.GameType, err)
+	}
+}
+
+// RunningHouseProfit returns the house profit recorded by every settled game
+func RunningHouseProfit(ctx context.Context, client *redis.Client) (float64, error) {
+	profit, err := client.Get(ctx, REDIS_KEY_HOUSE_PROFIT_RUNNING).Float64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return profit, err
+}
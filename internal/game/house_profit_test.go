@@ -0,0 +1,96 @@
+package game
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"aviator/internal/database"
+)
+
+func TestNewHouseProfitReport(t *testing.T) {
+	report := NewHouseProfitReport([]database.GameProfit{
+		{GameType: "aviator", Wagered: 1000, PaidOut: 950},
+		{GameType: "dice", Wagered: 500, PaidOut: 520},
+		{GameType: "mines", Wagered: 250, PaidOut: 200.25},
+	})
+
+	if report.TotalWagered != 1750 || report.TotalPaidOut != 1670.25 || report.GrossProfit != 79.75 || report.GrossProfitPct != 4.56 {
+		t.Errorf("totals = %+v, want 1750 wagered, 1670.25 paid out, 79.75 profit at 4.56%%", report.GameProfitStats)
+	}
+	if dice := report.ByGameType[GameTypeDice]; dice.GrossProfit != -20 || dice.GrossProfitPct != -4 {
+		t.Errorf("dice = %+v, want a loss of 20 at -4%%", dice)
+	}
+	if plinko, ok := report.ByGameType[GameTypePlinko]; !ok || plinko.TotalWagered != 0 {
+		t.Errorf("plinko = %+v, %v; want it listed with nothing wagered", plinko, ok)
+	}
+
+	// 1% of 1000 + 1% of 500 + 3% of 250
+	if report.TheoreticalProfit != 22.5 || report.Variance != 57.25 || !report.HighVarianceAlert {
+		t.Errorf("report = %+v, want 22.50 theoretical profit and an alert at a variance of 57.25", report)
+	}
+
+	onTarget := NewHouseProfitReport([]database.GameProfit{{GameType: "aviator", Wagered: 1000, PaidOut: 988}})
+	if onTarget.Variance != 2 || onTarget.HighVarianceAlert {
+		t.Errorf("report = %+v, want a variance of 2 without an alert", onTarget)
+	}
+	if empty := NewHouseProfitReport(nil); empty.HighVarianceAlert || len(empty.ByGameType) != 4 {
+		t.Errorf("empty report = %+v, want every game listed without an alert", empty)
+	}
+}
+
+func TestRecordHouseProfit(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	ctx := context.Background()
+
+	if profit, err := RunningHouseProfit(ctx, client); err != nil || profit != 0 {
+		t.Errorf("RunningHouseProfit() before any game = %v, %v; want 0", profit, err)
+	}
+	recordHouseProfit(ctx, client, GameOutcome{GameType: GameTypeMines, Wager: amountOf(10)})
+	recordHouseProfit(ctx, client, GameOutcome{GameType: GameTypeDice, Wager: amountOf(5), Payout: amountOf(9.9)})
+	if profit, err := RunningHouseProfit(ctx, client); err != nil || profit != 5.1 {
+		t.Errorf("RunningHouseProfit() = %v, %v; want 5.1", profit, err)
+	}
+}
+
+// settlementRecorder collects the settlements the hub records
+type settlementRecorder chan database.Settlement
+
+func (r settlementRecorder) RecordSettlement(ctx context.Context, settlement database.Settlement) error {
+	r <- settlement
+	return nil
+}
+
+func TestHub_GameSettledRecordsSettlement(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	ctx := context.Background()
+
+	recorded := make(settlementRecorder, 1)
+	hub := NewHub()
+	hub.SetSettlementRecorder(recorded)
+	engine := NewDiceEngine(client, hub)
+
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"player", 100.0, 0)
+	resp, err := engine.PlaceBet(ctx, DiceRollRequest{UserID: "player", Amount: 10, Target: 50, IsOver: true})
+	if err != nil || !resp.(DiceRollResponse).Success {
+		t.Fatalf("PlaceBet() = %+v, %v", resp, err)
+	}
+	roll := resp.(DiceRollResponse)
+
+	select {
+	case settlement := <-recorded:
+		if settlement.UserID != "player" || settlement.GameType != string(GameTypeDice) ||
+			settlement.Wager != 10 || settlement.Payout != roll.Payout.Float64() {
+			t.Errorf("settlement = %+v, want the dice roll paying %s", settlement, roll.Payout)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("dice roll was not recorded as a settlement")
+	}
+}
@@ -1,34 +1,83 @@
 package game
 
 import (
+	"context"
 	"encoding/json"
 	"log"
 	"sync"
 	"time"
 
 	"github.com/gofiber/contrib/websocket"
+
+	"aviator/internal/database"
+	"aviator/internal/notifications"
 )
 
+// clientConn is the subset of *websocket.Conn the hub writes to
+type clientConn interface {
+	WriteMessage(messageType int, data []byte) error
+	SetWriteDeadline(t time.Time) error
+	Close() error
+}
+
 type Client struct {
-	conn   *websocket.Conn
-	userID string
-	mu     sync.Mutex
+	conn     clientConn
+	userID   string
+	gameType GameType
+	mu       sync.Mutex
+
+	balanceUpdates bool     // Guarded by Hub.mu
+	adminAlerts    bool     // Guarded by Hub.mu
+	subscribeTo    []string // Games to subscribe to once registered
+}
+
+// Reasons reported with a balance_update message
+const (
+	BalanceReasonBet        = "bet"
+	BalanceReasonPayout     = "payout"
+	BalanceReasonCashout    = "cashout"
+	BalanceReasonInterest   = "interest"
+	BalanceReasonReferral   = "referral_bonus"
+	BalanceReasonRefund     = "refund"
+	BalanceReasonDeposit    = "deposit"
+	BalanceReasonWithdrawal = "withdrawal"
+)
+
+// BalanceUpdateMessage is sent to a user after every change to their balance.
+// Delta is negative for bets and positive for credits.
+type BalanceUpdateMessage struct {
+	Type    string  `json:"type"`
+	Balance float64 `json:"balance"`
+	Delta   Amount  `json:"delta"`
+	Reason  string  `json:"reason"`
 }
 
 type Hub struct {
-	clients    map[*Client]bool
-	broadcast  chan interface{}
-	register   chan *Client
-	unregister chan *Client
-	mu         sync.RWMutex
+	clients       map[*Client]bool
+	connToClient  map[clientConn]*Client      // Finds a connection's client without scanning clients
+	subscriptions map[string]map[*Client]bool // gameID -> subscribed clients
+	broadcast     chan interface{}
+	register      chan *Client
+	unregister    chan *Client
+	broadcaster   *RedisBroadcaster              // nil when running as a single instance
+	referrals     *ReferralService               // nil when referral bonuses are disabled
+	webhook       *notifications.OperatorWebhook // nil without OPERATOR_WEBHOOK_URL
+	settlements   SettlementRecorder             // nil when settlements are not persisted
+	mu            sync.RWMutex
+
+	lastChat map[string]time.Time // userID -> time of last accepted chat message
+	chatMu   sync.Mutex
 }
 
 func NewHub() *Hub {
 	return &Hub{
-		clients:    make(map[*Client]bool),
-		broadcast:  make(chan interface{}, 100),
-		register:   make(chan *Client),
-		unregister: make(chan *Client),
+		clients:       make(map[*Client]bool),
+		connToClient:  make(map[clientConn]*Client),
+		subscriptions: make(map[string]map[*Client]bool),
+		broadcast:     make(chan interface{}, 100),
+		register:      make(chan *Client),
+		unregister:    make(chan *Client),
+		lastChat:      make(map[string]time.Time),
 	}
 }
 
@@ -38,6 +87,13 @@ func (h *Hub) Run() {
 		case client := <-h.register:
 			h.mu.Lock()
 			h.clients[client] = true
+			h.connToClient[client.conn] = client
+			for _, gameID := range client.subscribeTo {
+				if h.subscriptions[gameID] == nil {
+					h.subscriptions[gameID] = make(map[*Client]bool)
+				}
+				h.subscriptions[gameID][client] = true
+			}
 			h.mu.Unlock()
 			log.Printf("[WS] Client connected: %s (Total: %d)", client.userID, len(h.clients))
 
@@ -45,12 +101,19 @@ func (h *Hub) Run() {
 			h.mu.Lock()
 			if _, ok := h.clients[client]; ok {
 				delete(h.clients, client)
+				if h.connToClient[client.conn] == client {
+					delete(h.connToClient, client.conn)
+				}
+				h.removeSubscriptions(client)
 				client.conn.Close()
 				log.Printf("[WS] Client disconnected: %s (Total: %d)", client.userID, len(h.clients))
 			}
 			h.mu.Unlock()
 
-		case message := <-h.broadcast:
+		case message, ok := <-h.broadcast:
+			if !ok {
+				return // Closed by the owner; stop instead of spinning
+			}
 			jsonMessage, err := json.Marshal(message)
 			if err != nil {
 				log.Printf("[WS] Marshal error: %v", err)
@@ -66,7 +129,17 @@ func (h *Hub) Run() {
 	}
 }
 
+// Broadcast sends a message to all local clients and, when a RedisBroadcaster
+// is attached, to clients connected to other instances
 func (h *Hub) Broadcast(message interface{}) {
+	h.broadcastLocal(message)
+
+	if h.broadcaster != nil {
+		h.broadcaster.publish("", message)
+	}
+}
+
+func (h *Hub) broadcastLocal(message interface{}) {
 	select {
 	case h.broadcast <- message:
 	default:
@@ -74,6 +147,292 @@ func (h *Hub) Broadcast(message interface{}) {
 	}
 }
 
+// BroadcastToGame sends a message only to clients subscribed to the given
+// game ID, on this and, when a RedisBroadcaster is attached, other instances
+func (h *Hub) BroadcastToGame(gameID string, message interface{}) {
+	jsonMessage, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("[WS] Marshal error: %v", err)
+		return
+	}
+
+	h.sendToGame(gameID, jsonMessage)
+	if h.broadcaster != nil {
+		h.broadcaster.publish(gameID, json.RawMessage(jsonMessage))
+	}
+}
+
+// sendToGame sends an encoded message to the game's local subscribers
+func (h *Hub) sendToGame(gameID string, jsonMessage []byte) {
+	h.mu.RLock()
+	for client := range h.subscriptions[gameID] {
+		go client.send(jsonMessage)
+	}
+	h.mu.RUnlock()
+}
+
+// BroadcastToGameType sends a message to clients connected to the given game type
+func (h *Hub) BroadcastToGameType(gameType GameType, message interface{}) {
+	jsonMessage, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("[WS] Marshal error: %v", err)
+		return
+	}
+
+	h.mu.RLock()
+	for client := range h.clients {
+		if client.gameType == gameType {
+			go client.send(jsonMessage)
+		}
+	}
+	h.mu.RUnlock()
+}
+
+// AllowChat reports whether a user may send a chat message now, recording the
+// attempt when it is allowed
+func (h *Hub) AllowChat(userID string) bool {
+	return h.allowChatAt(userID, time.Now())
+}
+
+func (h *Hub) allowChatAt(userID string, now time.Time) bool {
+	h.chatMu.Lock()
+	defer h.chatMu.Unlock()
+
+	if last, ok := h.lastChat[userID]; ok && now.Sub(last) < CHAT_RATE_INTERVAL {
+		return false
+	}
+	h.lastChat[userID] = now
+	return true
+}
+
+// SubscribeToGame adds the client owning conn to the subscriber set of a game
+func (h *Hub) SubscribeToGame(conn clientConn, gameID string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	client := h.findClient(conn)
+	if client == nil {
+		return false
+	}
+
+	if h.subscriptions[gameID] == nil {
+		h.subscriptions[gameID] = make(map[*Client]bool)
+	}
+	h.subscriptions[gameID][client] = true
+	return true
+}
+
+// UnsubscribeFromGame removes the client owning conn from the subscriber set of a game
+func (h *Hub) UnsubscribeFromGame(conn clientConn, gameID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	client := h.findClient(conn)
+	if client == nil {
+		return
+	}
+
+	if subscribers, ok := h.subscriptions[gameID]; ok {
+		delete(subscribers, client)
+		if len(subscribers) == 0 {
+			delete(h.subscriptions, gameID)
+		}
+	}
+}
+
+// SubscribeBalance enables balance_update messages for the client owning conn.
+// A client may only subscribe to the balance of the user it connected as.
+func (h *Hub) SubscribeBalance(conn clientConn, userID string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	client := h.findClient(conn)
+	if client == nil || client.userID != userID {
+		return false
+	}
+	client.balanceUpdates = true
+	return true
+}
+
+// UnsubscribeBalance stops balance_update messages for the client owning conn
+func (h *Hub) UnsubscribeBalance(conn clientConn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if client := h.findClient(conn); client != nil {
+		client.balanceUpdates = false
+	}
+}
+
+// SubscribeAdmin enables admin alerts for the client owning conn. The caller
+// checks the admin key.
+func (h *Hub) SubscribeAdmin(conn clientConn) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	client := h.findClient(conn)
+	if client == nil {
+		return false
+	}
+	client.adminAlerts = true
+	return true
+}
+
+// BroadcastToAdmins sends a message to the local clients subscribed to admin
+// alerts. It is a no-op on a nil Hub.
+func (h *Hub) BroadcastToAdmins(message interface{}) {
+	if h == nil {
+		return
+	}
+	jsonMessage, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("[WS] Marshal error: %v", err)
+		return
+	}
+
+	h.mu.RLock()
+	for client := range h.clients {
+		if client.adminAlerts {
+			go client.send(jsonMessage)
+		}
+	}
+	h.mu.RUnlock()
+}
+
+// SendToUser sends a message to every local connection of userID
+func (h *Hub) SendToUser(userID string, message interface{}) error {
+	return h.sendToUser(userID, message, func(*Client) bool { return true })
+}
+
+// NotifyBalance sends a balance_update to the connections of userID that
+// subscribed to balance updates. It is a no-op on a nil Hub.
+func (h *Hub) NotifyBalance(userID string, balance float64, delta Amount, reason string) {
+	if h == nil {
+		return
+	}
+
+	message := BalanceUpdateMessage{
+		Type:    "balance_update",
+		Balance: balance,
+		Delta:   delta,
+		Reason:  reason,
+	}
+	err := h.sendToUser(userID, message, func(client *Client) bool { return client.balanceUpdates })
+	if err != nil {
+		log.Printf("[WS] Balance update for %s failed: %v", userID, err)
+	}
+}
+
+// BetSettled reports a settled bet so a referred user's first bet pays their
+// referrer. Bets that are cancelled or rolled back never settle and pay
+// nothing. It is a no-op on a nil Hub or without a referral service.
+func (h *Hub) BetSettled(ctx context.Context, userID string) {
+	if h == nil || h.referrals == nil {
+		return
+	}
+	if _, err := h.referrals.OnBet(ctx, userID); err != nil {
+		log.Printf("[REFERRAL] First bet check for %s failed: %v", userID, err)
+	}
+}
+
+// SetOperatorWebhook reports every settled bet to webhook
+func (h *Hub) SetOperatorWebhook(webhook *notifications.OperatorWebhook) {
+	h.webhook = webhook
+}
+
+// SettlementRecorder persists settled bets for the house profit report
+type SettlementRecorder interface {
+	RecordSettlement(ctx context.Context, settlement database.Settlement) error
+}
+
+// SetSettlementRecorder stores every settled bet with recorder
+func (h *Hub) SetSettlementRecorder(recorder SettlementRecorder) {
+	h.settlements = recorder
+}
+
+// GameSettled reports a settled bet to the operator webhook and the
+// settlement recorder. It is a no-op on a nil Hub or without either.
+func (h *Hub) GameSettled(eventType string, outcome GameOutcome) {
+	if h == nil {
+		return
+	}
+	if h.settlements != nil {
+		h.recordSettlement(outcome)
+	}
+	if h.webhook == nil {
+		return
+	}
+	h.webhook.Send(notifications.GameOutcomeEvent{
+		EventType:      eventType,
+		GameType:       string(outcome.GameType),
+		UserID:         outcome.UserID,
+		Amount:         outcome.Wager.Float64(),
+		Payout:         outcome.Payout.Float64(),
+		NetHouseProfit: (outcome.Wager - outcome.Payout).Float64(),
+		Timestamp:      time.Now(),
+	})
+}
+
+// recordSettlement stores outcome without holding up the game that settled it
+func (h *Hub) recordSettlement(outcome GameOutcome) {
+	settlement := database.Settlement{
+		UserID:    outcome.UserID,
+		GameType:  string(outcome.GameType),
+		Wager:     outcome.Wager.Float64(),
+		Payout:    outcome.Payout.Float64(),
+		SettledAt: time.Now(),
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := h.settlements.RecordSettlement(ctx, settlement); err != nil {
+			log.Printf("[STATS] Failed to record the %s settlement of %s: %v", settlement.GameType, settlement.UserID, err)
+		}
+	}()
+}
+
+func (h *Hub) sendToUser(userID string, message interface{}, include func(*Client) bool) error {
+	jsonMessage, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	h.mu.RLock()
+	for client := range h.clients {
+		if client.userID == userID && include(client) {
+			go client.send(jsonMessage)
+		}
+	}
+	h.mu.RUnlock()
+	return nil
+}
+
+// GetSubscriberCount returns the number of clients subscribed to a game
+func (h *Hub) GetSubscriberCount(gameID string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subscriptions[gameID])
+}
+
+// findClient must be called with h.mu held
+func (h *Hub) findClient(conn clientConn) *Client {
+	return h.connToClient[conn]
+}
+
+// removeSubscriptions must be called with h.mu held
+func (h *Hub) removeSubscriptions(client *Client) {
+	for gameID, subscribers := range h.subscriptions {
+		delete(subscribers, client)
+		if len(subscribers) == 0 {
+			delete(h.subscriptions, gameID)
+		}
+	}
+}
+
+// BroadcastChannelLen returns how many broadcasts are waiting to be sent
+func (h *Hub) BroadcastChannelLen() int {
+	return len(h.broadcast)
+}
 
 func (h *Hub) GetClientCount() int {
 	h.mu.RLock()
@@ -114,22 +473,28 @@ func (c *Client) SendInitialState(state *RoundState) {
 	}
 }
 
-func (h *Hub) RegisterClient(conn *websocket.Conn, userID string) {
+func (h *Hub) RegisterClient(conn clientConn, userID string) {
+	h.RegisterGameClient(conn, userID, GameTypeAviator)
+}
+
+// RegisterGameClient registers a client that is playing the given game type,
+// subscribing it to the updates of gameIDs
+func (h *Hub) RegisterGameClient(conn clientConn, userID string, gameType GameType, gameIDs ...string) {
 	client := &Client{
-		conn:   conn,
-		userID: userID,
+		conn:        conn,
+		userID:      userID,
+		gameType:    gameType,
+		subscribeTo: gameIDs,
 	}
 	h.register <- client
 }
 
-func (h *Hub) UnregisterClient(conn *websocket.Conn) {
+func (h *Hub) UnregisterClient(conn clientConn) {
 	h.mu.RLock()
-	for client := range h.clients {
-		if client.conn == conn {
-			h.mu.RUnlock()
-			h.unregister <- client
-			return
-		}
-	}
+	client := h.findClient(conn)
 	h.mu.RUnlock()
+
+	if client != nil {
+		h.unregister <- client
+	}
 }
@@ -185,3 +185,264 @@ func BenchmarkHub_GetClientCount(b *testing.B) {
 		hub.GetClientCount()
 	}
 }
+
+// mockConn records messages written by the hub
+type mockConn struct {
+	mu       sync.Mutex
+	messages [][]byte
+}
+
+func (m *mockConn) WriteMessage(messageType int, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, data)
+	return nil
+}
+
+func (m *mockConn) SetWriteDeadline(t time.Time) error { return nil }
+
+func (m *mockConn) Close() error { return nil }
+
+func (m *mockConn) count() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.messages)
+}
+
+func (m *mockConn) last() []byte {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.messages[len(m.messages)-1]
+}
+
+func waitForClients(t *testing.T, hub *Hub, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for hub.GetClientCount() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %d clients", n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestHub_BroadcastToGame(t *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+
+	subscriber := &mockConn{}
+	other := &mockConn{}
+	hub.RegisterClient(subscriber, "user1")
+	hub.RegisterClient(other, "user2")
+	waitForClients(t, hub, 2)
+
+	if !hub.SubscribeToGame(subscriber, "MINES-1") {
+		t.Fatal("SubscribeToGame() should find registered client")
+	}
+	if got := hub.GetSubscriberCount("MINES-1"); got != 1 {
+		t.Errorf("GetSubscriberCount() = %d, want 1", got)
+	}
+
+	hub.BroadcastToGame("MINES-1", map[string]string{"type": "mines_update"})
+	time.Sleep(20 * time.Millisecond)
+
+	if subscriber.count() != 1 {
+		t.Errorf("subscriber received %d messages, want 1", subscriber.count())
+	}
+	if other.count() != 0 {
+		t.Errorf("non-subscriber received %d messages, want 0", other.count())
+	}
+
+	t.Run("unsubscribe stops delivery", func(t *testing.T) {
+		hub.UnsubscribeFromGame(subscriber, "MINES-1")
+		hub.BroadcastToGame("MINES-1", map[string]string{"type": "mines_update"})
+		time.Sleep(20 * time.Millisecond)
+
+		if subscriber.count() != 1 {
+			t.Errorf("subscriber received %d messages after unsubscribe, want 1", subscriber.count())
+		}
+		if hub.GetSubscriberCount("MINES-1") != 0 {
+			t.Error("subscription should be removed")
+		}
+	})
+
+	t.Run("unregister removes subscriptions", func(t *testing.T) {
+		hub.SubscribeToGame(other, "MINES-2")
+		hub.UnregisterClient(other)
+		waitForClients(t, hub, 1)
+
+		if hub.GetSubscriberCount("MINES-2") != 0 {
+			t.Error("subscriptions should be removed on unregister")
+		}
+	})
+
+	t.Run("unknown connection cannot subscribe", func(t *testing.T) {
+		if hub.SubscribeToGame(&mockConn{}, "MINES-1") {
+			t.Error("SubscribeToGame() should fail for unregistered connection")
+		}
+	})
+}
+
+func TestHub_AllowChat(t *testing.T) {
+	hub := NewHub()
+	start := time.Now()
+
+	if !hub.allowChatAt("user1", start) {
+		t.Fatal("first message should be allowed")
+	}
+	if hub.allowChatAt("user1", start.Add(100*time.Millisecond)) {
+		t.Error("second message within the interval should be rejected")
+	}
+	if !hub.allowChatAt("user2", start.Add(100*time.Millisecond)) {
+		t.Error("other users should not be limited")
+	}
+	if !hub.allowChatAt("user1", start.Add(CHAT_RATE_INTERVAL)) {
+		t.Error("message after the interval should be allowed")
+	}
+
+	// A rejected message must not extend the window
+	if hub.allowChatAt("user1", start.Add(CHAT_RATE_INTERVAL+100*time.Millisecond)) {
+		t.Error("message within the new interval should be rejected")
+	}
+	if !hub.allowChatAt("user1", start.Add(2*CHAT_RATE_INTERVAL)) {
+		t.Error("message after the second interval should be allowed")
+	}
+}
+
+func TestHub_NotifyBalance(t *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+
+	subscribed := &mockConn{}
+	sameUser := &mockConn{}
+	otherUser := &mockConn{}
+	hub.RegisterClient(subscribed, "user1")
+	hub.RegisterClient(sameUser, "user1")
+	hub.RegisterClient(otherUser, "user2")
+	waitForClients(t, hub, 3)
+
+	if hub.SubscribeBalance(otherUser, "user1") {
+		t.Error("SubscribeBalance() should reject another user's balance")
+	}
+	if !hub.SubscribeBalance(subscribed, "user1") {
+		t.Fatal("SubscribeBalance() should accept the connection's own user")
+	}
+
+	hub.NotifyBalance("user1", 90, -1000, BalanceReasonBet)
+	waitForMessages(t, subscribed, 1)
+
+	if sameUser.count() != 0 || otherUser.count() != 0 {
+		t.Error("unsubscribed connections should not receive balance updates")
+	}
+	want := `{"type":"balance_update","balance":90,"delta":-10.00,"reason":"bet"}`
+	if got := string(subscribed.last()); got != want {
+		t.Errorf("message = %s, want %s", got, want)
+	}
+
+	t.Run("send to user reaches every connection", func(t *testing.T) {
+		if err := hub.SendToUser("user1", map[string]string{"type": "notice"}); err != nil {
+			t.Fatalf("SendToUser() error: %v", err)
+		}
+		waitForMessages(t, subscribed, 2)
+		waitForMessages(t, sameUser, 1)
+
+		if otherUser.count() != 0 {
+			t.Error("other users should not receive the message")
+		}
+	})
+
+	t.Run("unsubscribe stops updates", func(t *testing.T) {
+		hub.UnsubscribeBalance(subscribed)
+		hub.NotifyBalance("user1", 100, 1000, BalanceReasonPayout)
+		time.Sleep(20 * time.Millisecond)
+
+		if subscribed.count() != 2 {
+			t.Errorf("subscriber received %d messages after unsubscribe, want 2", subscribed.count())
+		}
+	})
+
+	t.Run("nil hub is a no-op", func(t *testing.T) {
+		var nilHub *Hub
+		nilHub.NotifyBalance("user1", 100, 1000, BalanceReasonPayout)
+	})
+}
+
+func TestHub_ConnToClientConsistency(t *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+
+	conns := make([]*mockConn, 20)
+	for i := range conns {
+		conns[i] = &mockConn{}
+	}
+
+	for cycle := 0; cycle < 3; cycle++ {
+		for _, conn := range conns {
+			hub.RegisterClient(conn, "user")
+		}
+		waitForClients(t, hub, len(conns))
+
+		// Drop every other connection, then check the maps agree
+		for i := 0; i < len(conns); i += 2 {
+			hub.UnregisterClient(conns[i])
+		}
+		waitForClients(t, hub, len(conns)/2)
+
+		hub.mu.RLock()
+		if len(hub.connToClient) != len(hub.clients) {
+			t.Errorf("cycle %d: %d reverse entries for %d clients", cycle, len(hub.connToClient), len(hub.clients))
+		}
+		for client := range hub.clients {
+			if hub.connToClient[client.conn] != client {
+				t.Errorf("cycle %d: reverse entry of a client points elsewhere", cycle)
+			}
+		}
+		for i, conn := range conns {
+			if _, ok := hub.connToClient[conn]; ok != (i%2 == 1) {
+				t.Errorf("cycle %d: connection %d registered = %v", cycle, i, ok)
+			}
+		}
+		hub.mu.RUnlock()
+
+		for i := 1; i < len(conns); i += 2 {
+			hub.UnregisterClient(conns[i])
+		}
+		waitForClients(t, hub, 0)
+	}
+
+	hub.mu.RLock()
+	defer hub.mu.RUnlock()
+	if len(hub.connToClient) != 0 {
+		t.Errorf("%d reverse entries left after every client unregistered", len(hub.connToClient))
+	}
+}
+
+// BenchmarkHub_FindClient compares the scan findClient used to do with the
+// reverse map lookup, at 10,000 clients
+func BenchmarkHub_FindClient(b *testing.B) {
+	hub := NewHub()
+	conns := make([]*mockConn, 10000)
+	for i := range conns {
+		conns[i] = &mockConn{}
+		client := &Client{conn: conns[i]}
+		hub.clients[client] = true
+		hub.connToClient[conns[i]] = client
+	}
+
+	b.Run("scan", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			conn := conns[i%len(conns)]
+			for client := range hub.clients {
+				if client.conn == conn {
+					break
+				}
+			}
+		}
+	})
+
+	b.Run("reverse map", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			hub.findClient(conns[i%len(conns)])
+		}
+	})
+}
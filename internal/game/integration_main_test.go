@@ -0,0 +1,46 @@
+//go:build integration
+
+package game
+
+import (
+	"context"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// testRedis is shared by the integration suites and backed by miniredis
+var testRedis *redis.Client
+
+func TestMain(m *testing.M) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		log.Fatalf("could not start miniredis: %v", err)
+	}
+	testRedis = redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	code := m.Run()
+
+	testRedis.Close()
+	mr.Close()
+	os.Exit(code)
+}
+
+func setTestBalance(t *testing.T, userID string, balance float64) {
+	t.Helper()
+	if err := testRedis.Set(context.Background(), REDIS_KEY_USER_BALANCE+userID, balance, 0).Err(); err != nil {
+		t.Fatalf("Failed to set balance: %v", err)
+	}
+}
+
+func getTestBalance(t *testing.T, userID string) float64 {
+	t.Helper()
+	balance, err := testRedis.Get(context.Background(), REDIS_KEY_USER_BALANCE+userID).Float64()
+	if err != nil {
+		t.Fatalf("Failed to get balance: %v", err)
+	}
+	return balance
+}
@@ -0,0 +1,271 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"aviator/internal/database"
+)
+
+const (
+	REDIS_KEY_INTEREST_STATS = "interest:stats:"
+	REDIS_KEY_INTEREST_RUN   = "interest:run:" // Claimed by the instance paying an interval's interest
+
+	INTEREST_INTERVAL          = 1 * time.Hour
+	INTEREST_MIN_BALANCE       = 1000.0
+	INTEREST_RATE_PER_HOUR     = 0.001
+	INTEREST_TRANSACTION_TYPE  = "INTEREST"
+	INTEREST_DAY_LAYOUT        = "2006-01-02"
+	interestBalanceScanPattern = REDIS_KEY_USER_BALANCE + "*"
+)
+
+// InterestTier is the hourly rate paid on balances at or above MinBalance
+type InterestTier struct {
+	MinBalance  float64 `json:"min_balance"`
+	RatePerHour float64 `json:"rate_per_hour"`
+}
+
+// interestTiers scales the base rate down for larger balances so whales
+// cannot farm interest. Tiers are ordered from the highest balance down.
+func interestTiers(minBalance, baseRate float64) []InterestTier {
+	return []InterestTier{
+		{MinBalance: 100000, RatePerHour: baseRate * 0.25},
+		{MinBalance: 10000, RatePerHour: baseRate * 0.5},
+		{MinBalance: minBalance, RatePerHour: baseRate},
+	}
+}
+
+// interestScript credits one user's interest atomically.
+// KEYS: balance, stats, self-exclusion. ARGV: min balance, unix time, day, then tier pairs.
+// Returns nil when nothing was credited, otherwise {interest, balance_before, balance_after}.
+var interestScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[3]) == 1 then
+	return nil
+end
+
+local balance = tonumber(redis.call('GET', KEYS[1]))
+if not balance or balance < tonumber(ARGV[1]) then
+	return nil
+end
+
+local rate = 0
+for i = 4, #ARGV, 2 do
+	if balance >= tonumber(ARGV[i]) then
+		rate = tonumber(ARGV[i + 1])
+		break
+	end
+end
+
+local interest = math.floor(balance * rate * 100) / 100
+if interest <= 0 then
+	return nil
+end
+
+local after = redis.call('INCRBYFLOAT', KEYS[1], interest)
+if redis.call('HGET', KEYS[2], 'day') ~= ARGV[3] then
+	redis.call('HSET', KEYS[2], 'day', ARGV[3], 'earned_today', 0)
+end
+redis.call('HINCRBYFLOAT', KEYS[2], 'earned_today', interest)
+redis.call('HINCRBYFLOAT', KEYS[2], 'earned_total', interest)
+redis.call('HSET', KEYS[2], 'last_credited_at', ARGV[2])
+
+return {tostring(interest), tostring(balance), after}
+`)
+
+// TransactionRecorder persists balance changes to the audit trail
+type TransactionRecorder interface {
+	RecordTransaction(ctx context.Context, tx database.Transaction) error
+}
+
+// InterestInfo summarises a user's interest earnings
+type InterestInfo struct {
+	UserID         string     `json:"user_id"`
+	CurrentRatePct float64    `json:"current_rate_pct"`
+	LastCreditedAt *time.Time `json:"last_credited_at"`
+	EarnedToday    float64    `json:"earned_today"`
+	EarnedTotal    float64    `json:"earned_total"`
+}
+
+// BalanceInterestJob periodically pays interest on idle balances
+type BalanceInterestJob struct {
+	redisClient *redis.Client
+	hub         *Hub
+	recorder    TransactionRecorder
+	minBalance  float64
+	tiers       []InterestTier
+	interval    time.Duration
+
+	stopChan chan struct{}
+	stopOnce sync.Once
+}
+
+// NewBalanceInterestJob creates the job using INTEREST_MIN_BALANCE and
+// INTEREST_RATE_PER_HOUR from the environment. hub and recorder may be nil.
+func NewBalanceInterestJob(redisClient *redis.Client, hub *Hub, recorder TransactionRecorder) *BalanceInterestJob {
+	minBalance := getEnvAsFloat("INTEREST_MIN_BALANCE", INTEREST_MIN_BALANCE)
+	rate := getEnvAsFloat("INTEREST_RATE_PER_HOUR", INTEREST_RATE_PER_HOUR)
+
+	return &BalanceInterestJob{
+		redisClient: redisClient,
+		hub:         hub,
+		recorder:    recorder,
+		minBalance:  minBalance,
+		tiers:       interestTiers(minBalance, rate),
+		interval:    INTEREST_INTERVAL,
+		stopChan:    make(chan struct{}),
+	}
+}
+
+// Start runs the job on every interval until Stop is called
+func (j *BalanceInterestJob) Start() {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	log.Printf("[INTEREST] Crediting interest every %s on balances >= %.2f", j.interval, j.minBalance)
+
+	for {
+		select {
+		case <-j.stopChan:
+			return
+		case now := <-ticker.C:
+			credited, err := j.RunOnce(context.Background(), now)
+			if err != nil {
+				log.Printf("[INTEREST] Run failed: %v", err)
+			}
+			if credited > 0 {
+				log.Printf("[INTEREST] Credited interest to %d balances", credited)
+			}
+		}
+	}
+}
+
+// Stop ends the background loop
+func (j *BalanceInterestJob) Stop() {
+	j.stopOnce.Do(func() { close(j.stopChan) })
+}
+
+// RateFor returns the hourly rate paid on the given balance, or 0 below the threshold
+func (j *BalanceInterestJob) RateFor(balance float64) float64 {
+	if balance < j.minBalance {
+		return 0
+	}
+	for _, tier := range j.tiers {
+		if balance >= tier.MinBalance {
+			return tier.RatePerHour
+		}
+	}
+	return 0
+}
+
+// RunOnce credits interest to every eligible balance and returns how many were credited.
+// Every instance runs the job, so only the first to claim the interval
+// containing now pays it; the others credit nothing.
+func (j *BalanceInterestJob) RunOnce(ctx context.Context, now time.Time) (int, error) {
+	period := now.Truncate(j.interval).Unix()
+	claimed, err := j.redisClient.SetNX(ctx, REDIS_KEY_INTEREST_RUN+strconv.FormatInt(period, 10), now.Unix(), 2*j.interval).Result()
+	if err != nil {
+		return 0, fmt.Errorf("claim interest run: %w", err)
+	}
+	if !claimed {
+		return 0, nil
+	}
+
+	credited := 0
+	iter := j.redisClient.Scan(ctx, 0, interestBalanceScanPattern, 100).Iterator()
+	for iter.Next(ctx) {
+		userID := iter.Val()[len(REDIS_KEY_USER_BALANCE):]
+		ok, err := j.creditUser(ctx, userID, now)
+		if err != nil {
+			log.Printf("[INTEREST] Failed to credit %s: %v", userID, err)
+			continue
+		}
+		if ok {
+			credited++
+		}
+	}
+	return credited, iter.Err()
+}
+
+// creditUser runs the interest script for a single user
+func (j *BalanceInterestJob) creditUser(ctx context.Context, userID string, now time.Time) (bool, error) {
+	args := []interface{}{j.minBalance, now.Unix(), now.UTC().Format(INTEREST_DAY_LAYOUT)}
+	for _, tier := range j.tiers {
+		args = append(args, tier.MinBalance, tier.RatePerHour)
+	}
+
+	keys := []string{
+		REDIS_KEY_USER_BALANCE + userID,
+		REDIS_KEY_INTEREST_STATS + userID,
+		REDIS_KEY_SELF_EXCLUSION + userID,
+	}
+	result, err := interestScript.Run(ctx, j.redisClient, keys, args...).StringSlice()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	values := make([]float64, len(result))
+	for i, raw := range result {
+		if values[i], err = strconv.ParseFloat(raw, 64); err != nil {
+			return true, fmt.Errorf("unexpected script result %q: %w", raw, err)
+		}
+	}
+
+	interest, _ := NewAmount(values[0])
+	j.hub.NotifyBalance(userID, values[2], interest, BalanceReasonInterest)
+
+	if j.recorder != nil {
+		tx := database.Transaction{
+			UserID:        userID,
+			Type:          INTEREST_TRANSACTION_TYPE,
+			Amount:        values[0],
+			BalanceBefore: values[1],
+			BalanceAfter:  values[2],
+			Description:   fmt.Sprintf("Hourly interest at %.4f%%", j.RateFor(values[1])*100),
+		}
+		if err := j.recorder.RecordTransaction(ctx, tx); err != nil {
+			log.Printf("[INTEREST] Failed to record transaction for %s: %v", userID, err)
+		}
+	}
+	return true, nil
+}
+
+// GetInterestInfo returns the user's current rate and interest earned so far
+func (j *BalanceInterestJob) GetInterestInfo(ctx context.Context, userID string, now time.Time) (*InterestInfo, error) {
+	info := &InterestInfo{UserID: userID}
+
+	excluded, err := j.redisClient.Exists(ctx, REDIS_KEY_SELF_EXCLUSION+userID).Result()
+	if err != nil {
+		return nil, err
+	}
+	if excluded == 0 {
+		balance, err := j.redisClient.Get(ctx, REDIS_KEY_USER_BALANCE+userID).Float64()
+		if err != nil && err != redis.Nil {
+			return nil, err
+		}
+		info.CurrentRatePct = j.RateFor(balance) * 100
+	}
+
+	stats, err := j.redisClient.HGetAll(ctx, REDIS_KEY_INTEREST_STATS+userID).Result()
+	if err != nil {
+		return nil, err
+	}
+	if ts, err := strconv.ParseInt(stats["last_credited_at"], 10, 64); err == nil {
+		lastCredited := time.Unix(ts, 0).UTC()
+		info.LastCreditedAt = &lastCredited
+	}
+	if stats["day"] == now.UTC().Format(INTEREST_DAY_LAYOUT) {
+		info.EarnedToday, _ = strconv.ParseFloat(stats["earned_today"], 64)
+	}
+	info.EarnedTotal, _ = strconv.ParseFloat(stats["earned_total"], 64)
+
+	return info, nil
+}
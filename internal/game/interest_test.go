@@ -0,0 +1,121 @@
+package game
+
+import (
+	"context"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"aviator/internal/database"
+)
+
+// recordingLedger collects transactions written by the interest job
+type recordingLedger struct {
+	mu  sync.Mutex
+	txs []database.Transaction
+}
+
+func (l *recordingLedger) RecordTransaction(ctx context.Context, tx database.Transaction) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.txs = append(l.txs, tx)
+	return nil
+}
+
+func TestBalanceInterestJob_RunOnce(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	ctx := context.Background()
+
+	ledger := &recordingLedger{}
+	job := NewBalanceInterestJob(client, nil, ledger)
+
+	balances := map[string]float64{
+		"small":    500,
+		"regular":  2000,
+		"large":    20000,
+		"excluded": 5000,
+	}
+	for userID, balance := range balances {
+		client.Set(ctx, REDIS_KEY_USER_BALANCE+userID, balance, 0)
+	}
+	now := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	if _, err := SelfExclude(ctx, client, "excluded", 30, now); err != nil {
+		t.Fatalf("SelfExclude() error: %v", err)
+	}
+
+	credited, err := job.RunOnce(ctx, now)
+	if err != nil {
+		t.Fatalf("RunOnce() error: %v", err)
+	}
+	if credited != 2 {
+		t.Errorf("RunOnce() credited %d balances, want 2", credited)
+	}
+
+	// Another instance running later in the same interval pays nothing
+	if credited, err := job.RunOnce(ctx, now.Add(10*time.Minute)); err != nil || credited != 0 {
+		t.Errorf("second RunOnce() in the interval = %d, %v; want 0", credited, err)
+	}
+
+	want := map[string]float64{
+		"small":    500,        // Below the threshold
+		"regular":  2000 + 2,   // 0.1% of 2000
+		"large":    20000 + 10, // Lower tier rate of 0.05%
+		"excluded": 5000,       // Self-excluded users earn nothing
+	}
+	for userID, expected := range want {
+		got, _ := client.Get(ctx, REDIS_KEY_USER_BALANCE+userID).Float64()
+		if math.Abs(got-expected) > 1e-9 {
+			t.Errorf("%s balance = %.2f, want %.2f", userID, got, expected)
+		}
+	}
+
+	if len(ledger.txs) != 2 {
+		t.Fatalf("recorded %d transactions, want 2", len(ledger.txs))
+	}
+	for _, tx := range ledger.txs {
+		if tx.Type != INTEREST_TRANSACTION_TYPE {
+			t.Errorf("transaction type = %q, want %q", tx.Type, INTEREST_TRANSACTION_TYPE)
+		}
+		if math.Abs(tx.BalanceAfter-tx.BalanceBefore-tx.Amount) > 1e-9 {
+			t.Errorf("transaction %+v does not balance", tx)
+		}
+	}
+
+	t.Run("info reflects earnings", func(t *testing.T) {
+		job.RunOnce(ctx, now.Add(time.Hour))
+
+		info, err := job.GetInterestInfo(ctx, "regular", now.Add(time.Hour))
+		if err != nil {
+			t.Fatalf("GetInterestInfo() error: %v", err)
+		}
+		if math.Abs(info.EarnedToday-4.0) > 1e-9 || math.Abs(info.EarnedTotal-4.0) > 1e-9 {
+			t.Errorf("earned today/total = %.2f/%.2f, want 4.00/4.00", info.EarnedToday, info.EarnedTotal)
+		}
+		if info.CurrentRatePct != 0.1 {
+			t.Errorf("CurrentRatePct = %v, want 0.1", info.CurrentRatePct)
+		}
+		if info.LastCreditedAt == nil || !info.LastCreditedAt.Equal(now.Add(time.Hour)) {
+			t.Errorf("LastCreditedAt = %v, want %v", info.LastCreditedAt, now.Add(time.Hour))
+		}
+	})
+
+	t.Run("earned today resets on a new day", func(t *testing.T) {
+		info, _ := job.GetInterestInfo(ctx, "regular", now.Add(24*time.Hour))
+		if info.EarnedToday != 0 || info.EarnedTotal == 0 {
+			t.Errorf("earned today/total = %.2f/%.2f, want 0 today and total kept", info.EarnedToday, info.EarnedTotal)
+		}
+	})
+
+	t.Run("no interest info for small balances", func(t *testing.T) {
+		info, _ := job.GetInterestInfo(ctx, "small", now)
+		if info.CurrentRatePct != 0 || info.LastCreditedAt != nil || info.EarnedTotal != 0 {
+			t.Errorf("small balance should have no interest, got %+v", info)
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// REDIS_KEY_GAME_MAINTENANCE holds a game type's Maintenance while it is offline
+const REDIS_KEY_GAME_MAINTENANCE = "maintenance:"
+
+var ErrUnknownGameType = errors.New("unknown game type")
+
+// Maintenance describes why a game is offline and when it should be back
+type Maintenance struct {
+	Message    string    `json:"message"`
+	ETAMinutes int       `json:"eta_minutes"`
+	StartedAt  time.Time `json:"started_at"`
+}
+
+// MaintenanceError is returned by PlaceBet while a game is under maintenance.
+// Games already in progress can still be played.
+type MaintenanceError struct {
+	GameType GameType
+	Maintenance
+}
+
+func (e *MaintenanceError) Error() string {
+	return string(e.GameType) + " is under maintenance"
+}
+
+// checkMaintenance returns a *MaintenanceError if gameType is under maintenance
+func checkMaintenance(ctx context.Context, redisClient *redis.Client, gameType GameType) error {
+	data, err := redisClient.Get(ctx, REDIS_KEY_GAME_MAINTENANCE+string(gameType)).Result()
+	if err == redis.Nil {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	maintenanceErr := &MaintenanceError{GameType: gameType}
+	json.Unmarshal([]byte(data), &maintenanceErr.Maintenance) // Bare flags carry no details
+	return maintenanceErr
+}
+
+// SetMaintenance blocks new bets on a game type and tells every client why.
+// It fails if no engine is registered for the game type.
+func (gf *GameFactory) SetMaintenance(ctx context.Context, gameType GameType, maintenance Maintenance) error {
+	if _, exists := gf.engines[gameType]; !exists {
+		return ErrUnknownGameType
+	}
+
+	maintenance.StartedAt = time.Now().UTC()
+	data, _ := json.Marshal(maintenance)
+	if err := gf.redisClient.Set(ctx, REDIS_KEY_GAME_MAINTENANCE+string(gameType), data, 0).Err(); err != nil {
+		return err
+	}
+
+	log.Printf("[FACTORY] %s under maintenance: %s (ETA %d min)", gameType, maintenance.Message, maintenance.ETAMinutes)
+	if gf.hub != nil {
+		gf.hub.Broadcast(map[string]interface{}{
+			"type":        "maintenance",
+			"game_type":   gameType,
+			"message":     maintenance.Message,
+			"eta_minutes": maintenance.ETAMinutes,
+		})
+	}
+	return nil
+}
+
+// EndMaintenance reopens a game type for new bets
+func (gf *GameFactory) EndMaintenance(ctx context.Context, gameType GameType) error {
+	if _, exists := gf.engines[gameType]; !exists {
+		return ErrUnknownGameType
+	}
+
+	if err := gf.redisClient.Del(ctx, REDIS_KEY_GAME_MAINTENANCE+string(gameType)).Err(); err != nil {
+		return err
+	}
+
+	log.Printf("[FACTORY] %s maintenance ended", gameType)
+	if gf.hub != nil {
+		gf.hub.Broadcast(map[string]interface{}{
+			"type":      "maintenance_ended",
+			"game_type": gameType,
+		})
+	}
+	return nil
+}
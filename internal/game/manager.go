@@ -5,23 +5,37 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"math"
+	"os"
+	"runtime/debug"
+	"sort"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/redis/go-redis/v9"
+
+	"aviator/internal/notifications"
 )
 
 const (
 	TICK_INTERVAL  = 100 * time.Millisecond
 	BETTING_TIME   = 5 * time.Second
+	COUNTDOWN_INTERVAL = 1 * time.Second
+	NEXT_ROUND_DELAY   = 3 * time.Second
 	MAX_BET_AMOUNT = 10000.0
 	MIN_BET_AMOUNT = 1.0
+	MAX_PAYOUT     = 10000000.0 // Largest payout a single bet may be able to win
 	CASHOUT_TIMEOUT = 500 * time.Millisecond
+	MAX_BETS_PER_ROUND = 2 // Lets a player pair a safe auto-cashout with a riskier bet
 
 	REDIS_KEY_ROUND_PREFIX = "crash:round:"
 	REDIS_KEY_ACTIVE_BETS  = "crash:bets:active:"
 	REDIS_KEY_USER_BALANCE = "crash:balance:"
 	REDIS_KEY_ROUND_LOCK   = "crash:lock:round"
+	REDIS_KEY_AUTO_CASHOUT = "crash:autocashout:"
+	REDIS_KEY_NEXT_SEED    = "crash:next_seed:"
+	REDIS_KEY_BET_COUNT    = "crash:bet_count:"
 )
 
 type Manager struct {
@@ -32,19 +46,84 @@ type Manager struct {
 	stateMutex     sync.RWMutex
 	betChannel     chan BetRequest
 	cashoutChannel chan CashoutRequest
+	sideBetChannel chan SideBetRequest
 	stopChan       chan struct{}
 	nonce          int
+	prevRoundID    string
+	anomaly        *AnomalyDetector
+	lastSeen       LastSeenRecorder
+	rounds         RoundRecorder
+	transactions   TransactionRecorder
+
+	// Bets a user may place in a single round
+	maxBetsPerRound int
+
+	// Payouts above this are announced to everyone
+	notableThreshold float64
+
+	// Auto-cashout credits waiting for the next batched flush
+	pendingCredits map[string]float64
+	creditAttempts map[string]int // Failed flushes of each pending credit
+	pendingMu      sync.Mutex
+
+	// Phase timings, fixed to the constants outside of tests
+	bettingTime       time.Duration
+	countdownInterval time.Duration
+	nextRoundDelay    time.Duration
+
+	// Delay between a crash and the reveal of its server seed
+	seedRevealDelay time.Duration
+
+	// Key signing each round's commitment
+	operatorSecret string
+
+	// Number of rounds in each server seed hash chain
+	chainLength int
+
+	// Simulated round scheduled or running in place of a real one
+	simulation        *CrashSimulation
+	simulationPending bool
+	simulationMu      sync.Mutex
+
+	// Crash point of every round in place of the fair one; 0 when unset. Only tests set it
+	fixedCrashPoint float64
+
+	// Serializes cashouts of bets that paid the early exit fee
+	earlyExitMu sync.Mutex
+}
+
+// LastSeenRecorder stamps a user's most recent activity
+type LastSeenRecorder interface {
+	UpdateLastSeen(ctx context.Context, userID string) error
 }
 
-func NewManager(hub *Hub, redisClient *redis.Client) *Manager {
+// NewManager creates the Aviator round manager. lastSeen and rounds may be nil.
+func NewManager(hub *Hub, redisClient *redis.Client, lastSeen LastSeenRecorder, rounds RoundRecorder) *Manager {
 	return &Manager{
 		hub:            hub,
 		redisClient:    redisClient,
+		lastSeen:       lastSeen,
+		rounds:         rounds,
+		maxBetsPerRound: getEnvAsInt("MAX_BETS_PER_ROUND", MAX_BETS_PER_ROUND),
+		notableThreshold: getEnvAsFloat("NOTABLE_CASHOUT_THRESHOLD", NOTABLE_CASHOUT_THRESHOLD),
 		ctx:            context.Background(),
 		betChannel:     make(chan BetRequest, 1000),
 		cashoutChannel: make(chan CashoutRequest, 1000),
+		sideBetChannel: make(chan SideBetRequest, 1000),
 		stopChan:       make(chan struct{}),
 		nonce:          0,
+		anomaly:        NewAnomalyDetector(redisClient),
+		pendingCredits: make(map[string]float64),
+		creditAttempts: make(map[string]int),
+
+		bettingTime:       BETTING_TIME,
+		countdownInterval: COUNTDOWN_INTERVAL,
+		nextRoundDelay:    time.Duration(getEnvAsInt("INTER_ROUND_DELAY_MS", int(NEXT_ROUND_DELAY/time.Millisecond))) * time.Millisecond,
+
+		seedRevealDelay: time.Duration(getEnvAsInt("CRASH_SEED_REVEAL_DELAY_MS", 0)) * time.Millisecond,
+		operatorSecret:  operatorSecretOrGenerate(os.Getenv("OPERATOR_SECRET")),
+
+		chainLength: HASH_CHAIN_LENGTH,
 	}
 }
 
@@ -56,6 +135,16 @@ func (m *Manager) Stop() {
 	close(m.stopChan)
 }
 
+// BetChannelLen returns how many bets are queued for the game loop
+func (m *Manager) BetChannelLen() int {
+	return len(m.betChannel)
+}
+
+// CashoutChannelLen returns how many cashouts are queued for the game loop
+func (m *Manager) CashoutChannelLen() int {
+	return len(m.cashoutChannel)
+}
+
 func (m *Manager) GetCurrentRound() *RoundState {
 	m.stateMutex.RLock()
 	defer m.stateMutex.RUnlock()
@@ -66,37 +155,59 @@ func (m *Manager) GetCurrentRound() *RoundState {
 	return &roundCopy
 }
 
-func (m *Manager) PlaceBet(req BetRequest) BetResponse {
-	respChan := make(chan BetResponse, 1)
+// PlaceBet queues a bet for the game loop. A non-nil error is an internal
+// failure; the response then carries no message for the player.
+func (m *Manager) PlaceBet(req BetRequest) (BetResponse, error) {
+	resp, err := m.queueBet(req)
+	resp.Currency = DisplayCurrency()
+	return resp, err
+}
+
+func (m *Manager) queueBet(req BetRequest) (BetResponse, error) {
+	respChan := make(chan BetResult, 1)
 	req.ResponseChan = respChan
 
 	select {
 	case m.betChannel <- req:
 		select {
-		case resp := <-respChan:
-			return resp
+		case result := <-respChan:
+			return result.Response, result.Err
 		case <-time.After(5 * time.Second):
-			return BetResponse{Success: false, Message: "Bet timeout"}
+			return BetResponse{Success: false, Message: "Bet timeout"}, nil
 		}
 	default:
-		return BetResponse{Success: false, Message: "Bet queue full"}
+		return BetResponse{Success: false, Message: "Bet queue full"}, nil
+	}
+}
+
+// Cashout queues a cashout for the game loop, or processes it right away for
+// a bet that paid the early exit fee. Errors are reported as by PlaceBet.
+func (m *Manager) Cashout(req CashoutRequest) (CashoutResponse, error) {
+	var resp CashoutResponse
+	var err error
+	if m.earlyExitFeePaid(m.ctx, req) {
+		resp, err = m.cashoutEarlyExit(m.ctx, req)
+	} else {
+		resp, err = m.queueCashout(req)
 	}
+	resp.Currency = DisplayCurrency()
+	return resp, err
 }
 
-func (m *Manager) Cashout(req CashoutRequest) CashoutResponse {
-	respChan := make(chan CashoutResponse, 1)
+func (m *Manager) queueCashout(req CashoutRequest) (CashoutResponse, error) {
+	respChan := make(chan CashoutResult, 1)
 	req.ResponseChan = respChan
 
 	select {
 	case m.cashoutChannel <- req:
 		select {
-		case resp := <-respChan:
-			return resp
+		case result := <-respChan:
+			return result.Response, result.Err
 		case <-time.After(CASHOUT_TIMEOUT):
-			return CashoutResponse{Success: false, Message: "Cashout timeout"}
+			return CashoutResponse{Success: false, Message: "Cashout timeout"}, nil
 		}
 	default:
-		return CashoutResponse{Success: false, Message: "Cashout queue full"}
+		return CashoutResponse{Success: false, Message: "Cashout queue full"}, nil
 	}
 }
 
@@ -107,66 +218,76 @@ func (m *Manager) gameLoop() {
 			log.Println("[GAME] Game loop stopped")
 			return
 		default:
+			if sim := m.takeSimulation(); sim != nil {
+				m.runSimulation(sim)
+				continue
+			}
 			m.runRound()
 		}
 	}
 }
 
 func (m *Manager) runRound() {
-	m.nonce++
-
-	serverSeed := GenerateSeed()
-	commitment := HashCommitment(serverSeed)
-	clientSeed := GenerateSeed() // In production, aggregate from player inputs
-	crashPoint := HashAndMapToMultiplier(serverSeed, clientSeed, m.nonce)
-
-	roundID := fmt.Sprintf("R%d-%d", time.Now().Unix(), m.nonce)
-
-	m.stateMutex.Lock()
-	m.currentRound = &RoundState{
-		RoundID:           roundID,
-		ServerSeed:        serverSeed,
-		HashCommitment:    commitment,
-		ClientSeed:        clientSeed,
-		CrashMultiplier:   crashPoint,
-		CurrentMultiplier: MIN_MULTIPLIER,
-		Status:            "BETTING",
-		StartTime:         time.Now(),
-		Nonce:             m.nonce,
-	}
-	m.stateMutex.Unlock()
-
-	m.storeRoundInRedis(m.currentRound)
+	round := m.startNewRound()
+	roundID := round.RoundID
+	crashPoint := round.CrashMultiplier
 
-	log.Printf("\n=== ROUND %s ===", roundID)
-	log.Printf("[FAIR] Commitment: %s", commitment[:16]+"...")
-	log.Printf("[FAIR] Crash Point: %.2fx (HIDDEN)", crashPoint)
+	// Keep the game loop alive and give players their stakes back if the round fails
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("[PANIC] Round %s: %v\n%s", roundID, r, debug.Stack())
+			m.recoverFromPanic(roundID)
+		}
+	}()
 
-	m.hub.Broadcast(map[string]interface{}{
-		"type":       "round_start",
-		"status":     "BETTING",
-		"round_id":   roundID,
-		"commitment": commitment,
-		"time_left":  BETTING_TIME.Seconds(),
-	})
+	bettingTimer := time.NewTimer(m.bettingTime)
+	defer bettingTimer.Stop()
+	countdown := time.NewTicker(m.countdownInterval)
+	defer countdown.Stop()
 
-	bettingTimer := time.NewTimer(BETTING_TIME)
+	bettingEnd := time.Now().Add(m.bettingTime)
+	secondsLeft := int(m.bettingTime / m.countdownInterval)
 	bettingLoop := true
 
 	for bettingLoop {
 		select {
 		case <-bettingTimer.C:
 			bettingLoop = false
+		case <-countdown.C:
+			secondsLeft--
+			if secondsLeft > 0 {
+				m.hub.Broadcast(map[string]interface{}{
+					"type":          "betting_countdown",
+					"round_id":      roundID,
+					"seconds_left":  secondsLeft,
+					"next_round_in": time.Until(bettingEnd).Seconds(),
+				})
+			}
 		case bet := <-m.betChannel:
-			m.processBet(bet)
+			resp, err := m.processBet(m.ctx, bet)
+			if bet.ResponseChan != nil {
+				bet.ResponseChan <- BetResult{Response: resp, Err: err}
+			}
+		case sideBet := <-m.sideBetChannel:
+			resp, err := m.processSideBet(m.ctx, sideBet)
+			if sideBet.ResponseChan != nil {
+				sideBet.ResponseChan <- SideBetResult{Response: resp, Err: err}
+			}
 		case <-m.stopChan:
 			return
 		}
 	}
 
+	// Bets are only taken by the betting loop, so the active bets are now final
+	activeBets := m.loadActiveBets(roundID)
+	m.broadcastBettingStats(roundID, activeBets)
+
 	m.stateMutex.Lock()
 	m.currentRound.Status = "RUNNING"
+	m.currentRound.BettingDurationMs = time.Since(m.currentRound.StartTime).Milliseconds()
+	running := *m.currentRound
 	m.stateMutex.Unlock()
+	m.publishRoundHead(&running)
 
 	m.hub.Broadcast(map[string]interface{}{
 		"type":     "round_running",
@@ -176,112 +297,381 @@ func (m *Manager) runRound() {
 
 	ticker := time.NewTicker(TICK_INTERVAL)
 	defer ticker.Stop()
+	flushTicker := time.NewTicker(CREDIT_FLUSH_INTERVAL)
+	defer flushTicker.Stop()
 
 	startTime := time.Now()
-	activeBets := m.loadActiveBets(roundID)
 
 	runningLoop := true
 	for runningLoop {
 		select {
 		case <-ticker.C:
-			m.stateMutex.Lock()
+			runningLoop = !m.tick(roundID, startTime, activeBets)
 
-			elapsed := time.Since(startTime).Seconds()
-			m.currentRound.CurrentMultiplier = calculateMultiplier(elapsed)
-			currentMult := m.currentRound.CurrentMultiplier
+		case <-flushTicker.C:
+			m.FlushPendingCredits()
 
-			if currentMult >= m.currentRound.CrashMultiplier {
-				m.currentRound.Status = "CRASHED"
-				m.currentRound.CurrentMultiplier = m.currentRound.CrashMultiplier
-				m.currentRound.CrashTime = time.Now()
+		case cashout := <-m.cashoutChannel:
+			resp, err := m.processCashout(m.ctx, cashout)
+			if cashout.ResponseChan != nil {
+				cashout.ResponseChan <- CashoutResult{Response: resp, Err: err}
+			}
 
-				m.hub.Broadcast(map[string]interface{}{
-					"type":        "crash",
-					"multiplier":  m.currentRound.CrashMultiplier,
-					"server_seed": m.currentRound.ServerSeed,
-					"round_id":    roundID,
-				})
+		case <-m.stopChan:
+			return
+		}
+	}
 
-				// Process remaining bets as losses
-				m.processRoundEnd(roundID, activeBets)
+	log.Printf("=== ROUND %s ENDED at %.2fx ===\n", roundID, crashPoint)
 
-				m.stateMutex.Unlock()
-				runningLoop = false
-				break
-			}
+	delayStart := time.Now()
+	if !m.runNextRoundCountdown(m.peekNextCommitment(roundID)) {
+		return
+	}
 
-			// Broadcast update
-			m.hub.Broadcast(map[string]interface{}{
-				"type":       "update",
-				"multiplier": currentMult,
-				"round_id":   roundID,
-			})
+	m.stateMutex.Lock()
+	m.currentRound.DelayDurationMs = time.Since(delayStart).Milliseconds()
+	ended := *m.currentRound
+	m.stateMutex.Unlock()
+	m.storeRoundInRedis(&ended)
+}
 
-			// Check auto-cashouts
-			m.processAutoCashouts(roundID, currentMult, activeBets)
+// tick advances the multiplier of a running round and reports whether it crashed
+func (m *Manager) tick(roundID string, startTime time.Time, activeBets map[string]ActiveBet) bool {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
 
-			m.stateMutex.Unlock()
+	elapsed := time.Since(startTime).Seconds()
+	m.currentRound.CurrentMultiplier = calculateMultiplier(elapsed)
+	currentMult := m.currentRound.CurrentMultiplier
 
-		case cashout := <-m.cashoutChannel:
-			m.processCashout(cashout)
+	if currentMult >= m.currentRound.CrashMultiplier {
+		m.crashRound(roundID, activeBets)
+		return true
+	}
 
+	// Broadcast update
+	m.hub.Broadcast(map[string]interface{}{
+		"type":       "update",
+		"multiplier": currentMult,
+		"round_id":   roundID,
+	})
+	m.publishRoundMultiplier(currentMult)
+
+	// Check auto-cashouts
+	m.processAutoCashouts(roundID, currentMult, activeBets)
+	return false
+}
+
+// recoverFromPanic aborts a round whose game loop panicked: every bet that
+// was not cashed out is refunded and the round is marked ABORTED. A round that
+// had already crashed is left alone, since the crash settled its bets.
+func (m *Manager) recoverFromPanic(roundID string) {
+	m.FlushPendingCredits()
+
+	m.stateMutex.RLock()
+	crashed := m.currentRound != nil && m.currentRound.RoundID == roundID && m.currentRound.Status == "CRASHED"
+	m.stateMutex.RUnlock()
+	if crashed {
+		log.Printf("[PANIC] Round %s had already crashed, its bets stand as settled", roundID)
+		return
+	}
+
+	bets := m.loadActiveBets(roundID)
+	keys := []string{REDIS_KEY_ACTIVE_BETS + roundID, REDIS_KEY_AUTO_CASHOUT + roundID}
+	for betID, bet := range bets {
+		keys = append(keys, betCountKey(roundID, bet.UserID))
+		if bet.CashedOut {
+			continue
+		}
+
+		balance, err := CreditBalance(m.ctx, m.redisClient, bet.UserID, bet.Amount.Float64())
+		if err != nil {
+			log.Printf("[PANIC] Failed to refund bet %s of %s to %s: %v", betID, bet.Amount, bet.UserID, err)
+			continue
+		}
+		m.hub.NotifyBalance(bet.UserID, balance, bet.Amount, BalanceReasonRefund)
+		log.Printf("[PANIC] Refunded %s to %s (ID: %s)", bet.Amount, bet.UserID, betID)
+	}
+	m.refundSideBets(roundID)
+	keys = append(keys, REDIS_KEY_SIDE_BETS+roundID)
+	m.redisClient.Del(m.ctx, keys...)
+
+	m.stateMutex.Lock()
+	var round *RoundState
+	if m.currentRound != nil && m.currentRound.RoundID == roundID {
+		m.currentRound.Status = "ABORTED"
+		roundCopy := *m.currentRound
+		round = &roundCopy
+	}
+	m.stateMutex.Unlock()
+	if round != nil {
+		m.storeRoundInRedis(round)
+	}
+
+	if m.hub != nil {
+		m.hub.Broadcast(map[string]interface{}{
+			"type":     "round_aborted",
+			"round_id": roundID,
+			"reason":   "internal_error",
+		})
+	}
+}
+
+// runNextRoundCountdown pauses between rounds, announcing every second left
+// with the next round's commitment. It reports false if the manager stopped.
+func (m *Manager) runNextRoundCountdown(nextCommitment string) bool {
+	nextRoundAt := time.Now().Add(m.nextRoundDelay)
+	for left := m.nextRoundDelay; left > 0; left = time.Until(nextRoundAt) {
+		secondsLeft := int(math.Ceil(float64(left) / float64(m.countdownInterval)))
+		m.hub.Broadcast(map[string]interface{}{
+			"type":                  "next_round_countdown",
+			"seconds_left":          secondsLeft,
+			"next_round_in":         left.Seconds(),
+			"next_round_commitment": nextCommitment,
+		})
+
+		// Wait until the next whole second left, so a delay that is not a
+		// whole number of seconds announces its fraction first
+		timer := time.NewTimer(left - time.Duration(secondsLeft-1)*m.countdownInterval)
+		select {
+		case <-timer.C:
 		case <-m.stopChan:
-			return
+			timer.Stop()
+			return false
 		}
 	}
+	return true
+}
 
-	log.Printf("=== ROUND %s ENDED at %.2fx ===\n", roundID, crashPoint)
+// startNewRound creates the next round in the BETTING phase and announces it.
+// The server seed is the one pre-committed when the previous round crashed.
+func (m *Manager) startNewRound() *RoundState {
+	m.nonce++
+
+	serverSeed, commitment, publishedAt := m.loadNextSeed(m.prevRoundID)
+	clientSeed := GenerateSeed() // In production, aggregate from player inputs
+	crashPoint := HashAndMapToMultiplier(serverSeed, clientSeed, m.nonce)
+	m.stateMutex.RLock()
+	if m.fixedCrashPoint > 0 {
+		crashPoint = m.fixedCrashPoint
+	}
+	m.stateMutex.RUnlock()
+	bonus := m.roundBonus()
+	var bonusMultiplier float64
+	if bonus != nil {
+		bonusMultiplier = bonus.BonusMultiplier
+	}
+
+	roundID := fmt.Sprintf("R%d-%d", time.Now().Unix(), m.nonce)
+
+	m.stateMutex.Lock()
+	m.currentRound = &RoundState{
+		RoundID:               roundID,
+		ServerSeed:            serverSeed,
+		HashCommitment:        commitment,
+		CommitmentPublishedAt: publishedAt,
+		ClientSeed:            clientSeed,
+		CrashMultiplier:       math.Round((crashPoint+bonusMultiplier)*100) / 100,
+		BonusMultiplier:       bonusMultiplier,
+		CurrentMultiplier:     MIN_MULTIPLIER,
+		Status:                "BETTING",
+		StartTime:             time.Now(),
+		Nonce:                 m.nonce,
+	}
+	round := *m.currentRound
+	m.stateMutex.Unlock()
+
+	m.storeRoundInRedis(&round)
+	m.publishCommitment(&round)
+
+	log.Printf("\n=== ROUND %s ===", roundID)
+	log.Printf("[FAIR] Commitment: %s (published %s)", commitment[:16]+"...", publishedAt.Format(time.RFC3339))
+	log.Printf("[FAIR] Crash Point: %.2fx (HIDDEN)", crashPoint)
+	if bonus != nil {
+		log.Printf("[BONUS] Round %s crash point raised by %.2fx", roundID, bonusMultiplier)
+	}
+
+	m.hub.Broadcast(map[string]interface{}{
+		"type":                    "round_start",
+		"status":                  "BETTING",
+		"round_id":                roundID,
+		"commitment":              commitment,
+		"commitment_published_at": publishedAt,
+		"commitment_signature":    SignCommitment(m.operatorSecret, commitment, roundID, publishedAt),
+		"time_left":               m.bettingTime.Seconds(),
+	})
+	if bonus != nil {
+		m.hub.Broadcast(map[string]interface{}{
+			"type":             "bonus_event",
+			"active":           true,
+			"round_id":         roundID,
+			"extra_multiplier": bonus.BonusMultiplier,
+			"expires_at":       bonus.ActiveUntil,
+		})
+	}
+
+	return &round
+}
+
+// crashRound ends the current round, reveals its seed and pre-commits the next
+// round's seed. Callers must hold stateMutex.
+func (m *Manager) crashRound(roundID string, activeBets map[string]ActiveBet) {
+	m.currentRound.Status = "CRASHED"
+	m.currentRound.CurrentMultiplier = m.currentRound.CrashMultiplier
+	m.currentRound.CrashTime = time.Now()
+	m.currentRound.RunningDurationMs = m.currentRound.CrashTime.Sub(m.currentRound.StartTime).Milliseconds() - m.currentRound.BettingDurationMs
+	m.currentRound.DelayDurationMs = m.nextRoundDelay.Milliseconds()
+
+	m.recordChainRound(m.currentRound)
+	m.revealCommitment(m.currentRound)
+	nextCommitment := m.precommitNextSeed(roundID)
+
+	m.hub.Broadcast(map[string]interface{}{
+		"type":                  "crash",
+		"multiplier":            m.currentRound.CrashMultiplier,
+		"round_id":              roundID,
+		"next_round_commitment": nextCommitment,
+		"betting_duration_ms":   m.currentRound.BettingDurationMs,
+		"running_duration_ms":   m.currentRound.RunningDurationMs,
+		"delay_duration_ms":     m.currentRound.DelayDurationMs,
+	})
+	m.revealSeed(roundID, m.currentRound.ServerSeed)
+
+	// Process remaining bets as losses
+	m.processRoundEnd(roundID, m.currentRound.BaseMultiplier(), activeBets)
+
+	m.storeRoundInRedis(m.currentRound)
+}
+
+// nextSeed is the pre-generated server seed for the round after roundID
+type nextSeed struct {
+	ServerSeed  string    `json:"server_seed"`
+	Commitment  string    `json:"commitment"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// precommitNextSeed draws the next round's server seed from the hash chain,
+// stores it in Redis and returns its commitment for broadcast
+func (m *Manager) precommitNextSeed(roundID string) string {
+	seed := m.nextChainSeed()
+	next := nextSeed{
+		ServerSeed:  seed,
+		Commitment:  CommitRoundSeed(seed),
+		PublishedAt: time.Now(),
+	}
+
+	data, _ := json.Marshal(next)
+	m.redisClient.Set(m.ctx, REDIS_KEY_NEXT_SEED+roundID, data, 1*time.Hour)
+	m.prevRoundID = roundID
+
+	return next.Commitment
+}
+
+// peekNextCommitment returns the commitment pre-committed after roundID
+// without consuming its seed
+func (m *Manager) peekNextCommitment(roundID string) string {
+	var next nextSeed
+	data, err := m.redisClient.Get(m.ctx, REDIS_KEY_NEXT_SEED+roundID).Bytes()
+	if err != nil || json.Unmarshal(data, &next) != nil {
+		return ""
+	}
+	return next.Commitment
+}
+
+// loadNextSeed returns the seed pre-committed by the previous round, falling
+// back to a fresh seed for the first round or if Redis lost it
+func (m *Manager) loadNextSeed(prevRoundID string) (string, string, time.Time) {
+	if prevRoundID != "" {
+		key := REDIS_KEY_NEXT_SEED + prevRoundID
+		data, err := m.redisClient.Get(m.ctx, key).Result()
+		if err == nil {
+			var next nextSeed
+			if json.Unmarshal([]byte(data), &next) == nil && CommitRoundSeed(next.ServerSeed) == next.Commitment {
+				m.redisClient.Del(m.ctx, key)
+				return next.ServerSeed, next.Commitment, next.PublishedAt
+			}
+		}
+		log.Printf("[FAIR] Pre-committed seed for %s unavailable, drawing the next chain seed", prevRoundID)
+	}
 
-	// Pause between rounds
-	time.Sleep(3 * time.Second)
+	seed := m.nextChainSeed()
+	return seed, CommitRoundSeed(seed), time.Now()
 }
 
 // calculateMultiplier computes the current multiplier based on elapsed time
 func calculateMultiplier(elapsed float64) float64 {
 	// Exponential growth formula
-	mult := 1.0 + (elapsed / 1.5) + (elapsed * elapsed * 0.005)
+	mult := multiplierCurve(elapsed)
 	return float64(int(mult*100)) / 100.0
 }
 
-// processBet handles a bet request
-func (m *Manager) processBet(req BetRequest) {
-	resp := BetResponse{}
-	defer func() {
-		if req.ResponseChan != nil {
-			req.ResponseChan <- resp
-		}
-	}()
-
+// processBet places a bet in the current round. Rejections are reported in
+// the response; errors are internal failures, after which nothing is charged.
+func (m *Manager) processBet(ctx context.Context, req BetRequest) (BetResponse, error) {
 	// Validate bet amount
-	if req.Amount < MIN_BET_AMOUNT || req.Amount > MAX_BET_AMOUNT {
-		resp.Message = fmt.Sprintf("Bet must be between %.2f and %.2f", MIN_BET_AMOUNT, MAX_BET_AMOUNT)
-		return
+	if req.Amount.Float64() < MIN_BET_AMOUNT || req.Amount.Float64() > MAX_BET_AMOUNT {
+		return BetResponse{Message: fmt.Sprintf("Bet must be between %.2f and %.2f", MIN_BET_AMOUNT, MAX_BET_AMOUNT)}, nil
+	}
+	if req.AutoCashoutJitterMs < 0 || req.AutoCashoutJitterMs > MAX_AUTO_CASHOUT_JITTER_MS {
+		return BetResponse{Message: fmt.Sprintf("Auto-cashout jitter must be between 0 and %d ms", MAX_AUTO_CASHOUT_JITTER_MS)}, nil
+	}
+	if msg := validateEarlyExitFee(req.EarlyExitFeePct); msg != "" {
+		return BetResponse{Message: msg}, nil
 	}
 
 	m.stateMutex.RLock()
 	if m.currentRound == nil || m.currentRound.Status != "BETTING" {
 		m.stateMutex.RUnlock()
-		resp.Message = "Betting is closed"
-		return
+		return BetResponse{Message: "Betting is closed"}, nil
+	}
+	if m.currentRound.Simulated {
+		m.stateMutex.RUnlock()
+		return BetResponse{Message: SIMULATION_BET_REJECTION}, nil
 	}
 	roundID := m.currentRound.RoundID
+	hashCommitment := m.currentRound.HashCommitment
 	m.stateMutex.RUnlock()
 
+	// Claim one of the user's bet slots for this round, released again if the bet fails
+	countKey := betCountKey(roundID, req.UserID)
+	count, err := m.redisClient.Incr(ctx, countKey).Result()
+	if err != nil {
+		return BetResponse{}, fmt.Errorf("processBet: Incr: %w", err)
+	}
+	m.redisClient.Expire(ctx, countKey, 10*time.Minute)
+	if count > int64(m.maxBetsPerRound) {
+		m.redisClient.Decr(ctx, countKey)
+		return BetResponse{Message: "Maximum bets per round reached"}, nil
+	}
+
+	// The early exit fee is charged with the stake and kept whatever the outcome
+	fee := earlyExitFee(req.Amount, req.EarlyExitFeePct)
+	debit := req.Amount.Add(fee)
+
 	// Check user balance (Redis)
 	balanceKey := REDIS_KEY_USER_BALANCE + req.UserID
-	balance, err := m.redisClient.Get(m.ctx, balanceKey).Float64()
-	if err != nil || balance < req.Amount {
-		resp.Message = "Insufficient balance"
-		resp.Balance = balance
-		return
+	balance, err := m.redisClient.Get(ctx, balanceKey).Float64()
+	if err != nil && err != redis.Nil {
+		m.redisClient.Decr(ctx, countKey)
+		return BetResponse{}, fmt.Errorf("processBet: Get balance: %w", err)
+	}
+	if balance < debit.Float64() {
+		m.redisClient.Decr(ctx, countKey)
+		return BetResponse{Message: "Insufficient balance", Balance: balance}, nil
 	}
 
 	// Deduct balance atomically (use negative value with IncrByFloat)
-	newBalance, err := m.redisClient.IncrByFloat(m.ctx, balanceKey, -req.Amount).Result()
-	if err != nil || newBalance < 0 {
-		m.redisClient.IncrByFloat(m.ctx, balanceKey, req.Amount) // Rollback
-		resp.Message = "Transaction failed"
-		return
+	newBalance, err := m.redisClient.IncrByFloat(ctx, balanceKey, -debit.Float64()).Result()
+	if err != nil {
+		m.redisClient.Decr(ctx, countKey)
+		return BetResponse{}, fmt.Errorf("processBet: IncrByFloat: %w", err)
+	}
+	if newBalance < 0 {
+		// Another debit landed between the check and the deduction
+		m.redisClient.IncrByFloat(ctx, balanceKey, debit.Float64()) // Rollback
+		m.redisClient.Decr(ctx, countKey)
+		return BetResponse{Message: "Insufficient balance", Balance: newBalance + debit.Float64()}, nil
 	}
 
 	// Create bet
@@ -293,18 +683,34 @@ func (m *Manager) processBet(req BetRequest) {
 		AutoCashout: req.AutoCashout,
 		PlacedAt:    time.Now(),
 		CashedOut:   false,
+
+		EarlyExitFeePaid: fee > 0,
+	}
+	if req.AutoCashout > 0 {
+		bet.AutoCashoutJitter = autoCashoutJitter(hashCommitment, betID, req.AutoCashout, req.AutoCashoutJitterMs)
 	}
 
 	// Store in Redis
 	betKey := REDIS_KEY_ACTIVE_BETS + roundID
 	betJSON, _ := json.Marshal(bet)
-	m.redisClient.HSet(m.ctx, betKey, betID, betJSON)
-	m.redisClient.Expire(m.ctx, betKey, 10*time.Minute)
+	if err := m.redisClient.HSet(ctx, betKey, betID, betJSON).Err(); err != nil {
+		m.redisClient.IncrByFloat(ctx, balanceKey, debit.Float64()) // Rollback
+		m.redisClient.Decr(ctx, countKey)
+		return BetResponse{}, fmt.Errorf("processBet: HSet: %w", err)
+	}
+	m.redisClient.Expire(ctx, betKey, 10*time.Minute)
+
+	// Index auto-cashout targets by multiplier so each tick only fetches triggered bets
+	if req.AutoCashout > 0 {
+		autoKey := REDIS_KEY_AUTO_CASHOUT + roundID
+		m.redisClient.ZAdd(ctx, autoKey, redis.Z{Score: bet.triggerMultiplier(), Member: betID})
+		m.redisClient.Expire(ctx, autoKey, 10*time.Minute)
+		m.recordAutoCashoutTarget(ctx, req.AutoCashout)
+	}
 
-	resp.Success = true
-	resp.BetID = betID
-	resp.Balance = newBalance
-	resp.Message = "Bet placed successfully"
+	m.hub.NotifyBalance(req.UserID, newBalance, -debit, BalanceReasonBet)
+	recordWager(ctx, m.redisClient, req.UserID, req.Amount)
+	m.touchLastSeen(req.UserID)
 
 	// Broadcast bet placed
 	m.hub.Broadcast(map[string]interface{}{
@@ -316,65 +722,205 @@ func (m *Manager) processBet(req BetRequest) {
 		},
 	})
 
-	log.Printf("[BET] User %s placed %.2f (ID: %s)", req.UserID, req.Amount, betID)
+	log.Printf("[BET] User %s placed %s (ID: %s)", req.UserID, req.Amount, betID)
+	return BetResponse{
+		Success: true,
+		Message: "Bet placed successfully",
+		BetID:   betID,
+		Balance: newBalance,
+
+		EarlyExitFeeCharged: fee.Float64(),
+	}, nil
 }
 
-// processCashout handles a cashout request
-func (m *Manager) processCashout(req CashoutRequest) {
-	resp := CashoutResponse{}
-	defer func() {
-		if req.ResponseChan != nil {
-			req.ResponseChan <- resp
+// ValidateBet checks an Aviator bet against the current round without placing it.
+// It returns a message for the player, or "" if the bet is valid.
+func (m *Manager) ValidateBet(req BetRequest) string {
+	if req.Amount.Float64() < MIN_BET_AMOUNT || req.Amount.Float64() > MAX_BET_AMOUNT {
+		return fmt.Sprintf("Bet must be between %.2f and %.2f", MIN_BET_AMOUNT, MAX_BET_AMOUNT)
+	}
+	if req.AutoCashoutJitterMs < 0 || req.AutoCashoutJitterMs > MAX_AUTO_CASHOUT_JITTER_MS {
+		return fmt.Sprintf("Auto-cashout jitter must be between 0 and %d ms", MAX_AUTO_CASHOUT_JITTER_MS)
+	}
+	if msg := validateEarlyExitFee(req.EarlyExitFeePct); msg != "" {
+		return msg
+	}
+
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+	if m.currentRound == nil || m.currentRound.Status != "BETTING" {
+		return "Betting is closed"
+	}
+	return ""
+}
+
+// cancelBet removes a bet from the current round. It only succeeds during the
+// betting phase, before the running loop has loaded the round's bets.
+func (m *Manager) cancelBet(betID string) bool {
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+	if m.currentRound == nil || m.currentRound.Status != "BETTING" {
+		return false
+	}
+
+	roundID := m.currentRound.RoundID
+	betJSON, err := m.redisClient.HGet(m.ctx, REDIS_KEY_ACTIVE_BETS+roundID, betID).Result()
+	if err != nil {
+		return false
+	}
+	var bet ActiveBet
+	json.Unmarshal([]byte(betJSON), &bet)
+	return m.removeBet(m.ctx, roundID, betID, bet.UserID)
+}
+
+// removeBet deletes a bet of roundID and releases its bet slot. It reports
+// false if the bet was already gone.
+func (m *Manager) removeBet(ctx context.Context, roundID, betID, userID string) bool {
+	removed, err := m.redisClient.HDel(ctx, REDIS_KEY_ACTIVE_BETS+roundID, betID).Result()
+	if err != nil || removed == 0 {
+		return false
+	}
+	m.redisClient.ZRem(ctx, REDIS_KEY_AUTO_CASHOUT+roundID, betID)
+	if userID != "" {
+		m.redisClient.Decr(ctx, betCountKey(roundID, userID))
+	}
+	return true
+}
+
+// betCountKey counts the bets a user has placed in a round
+func betCountKey(roundID, userID string) string {
+	return REDIS_KEY_BET_COUNT + roundID + ":" + userID
+}
+
+// GetUserBets returns the user's bets in the current round, oldest first
+func (m *Manager) GetUserBets(ctx context.Context, userID string) (string, []ActiveBet, error) {
+	round := m.GetCurrentRound()
+	if round == nil {
+		return "", nil, nil
+	}
+
+	betsJSON, err := m.redisClient.HVals(ctx, REDIS_KEY_ACTIVE_BETS+round.RoundID).Result()
+	if err != nil {
+		return round.RoundID, nil, err
+	}
+
+	bets := []ActiveBet{}
+	for _, betJSON := range betsJSON {
+		var bet ActiveBet
+		if json.Unmarshal([]byte(betJSON), &bet) == nil && bet.UserID == userID {
+			bets = append(bets, bet)
+		}
+	}
+	sort.Slice(bets, func(i, j int) bool { return bets[i].PlacedAt.Before(bets[j].PlacedAt) })
+	return round.RoundID, bets, nil
+}
+
+// ActiveBetCounts counts each user's bets in the current round that have not
+// cashed out
+func (m *Manager) ActiveBetCounts(ctx context.Context) (map[string]int, error) {
+	counts := make(map[string]int)
+	round := m.GetCurrentRound()
+	if round == nil {
+		return counts, nil
+	}
+
+	betsJSON, err := m.redisClient.HVals(ctx, REDIS_KEY_ACTIVE_BETS+round.RoundID).Result()
+	if err != nil {
+		return counts, err
+	}
+	for _, betJSON := range betsJSON {
+		var bet ActiveBet
+		if json.Unmarshal([]byte(betJSON), &bet) == nil && !bet.CashedOut {
+			counts[bet.UserID]++
+		}
+	}
+	return counts, nil
+}
+
+// touchLastSeen records the user's activity without holding up the game loop
+func (m *Manager) touchLastSeen(userID string) {
+	if m.lastSeen == nil {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := m.lastSeen.UpdateLastSeen(ctx, userID); err != nil {
+			log.Printf("[BET] Failed to update last seen for %s: %v", userID, err)
 		}
 	}()
+}
 
+// processCashout cashes out a bet at the current multiplier. Errors are
+// internal failures, reported as by processBet.
+func (m *Manager) processCashout(ctx context.Context, req CashoutRequest) (CashoutResponse, error) {
 	m.stateMutex.RLock()
 	if m.currentRound == nil || m.currentRound.Status != "RUNNING" {
 		m.stateMutex.RUnlock()
-		resp.Message = "Cannot cashout now"
-		return
+		return CashoutResponse{Message: "Cannot cashout now"}, nil
 	}
 	currentMult := m.currentRound.CurrentMultiplier
 	roundID := m.currentRound.RoundID
 	m.stateMutex.RUnlock()
 
+	return m.settleCashout(ctx, req, roundID, currentMult)
+}
+
+// settleCashout cashes out a bet of a running round at currentMult
+func (m *Manager) settleCashout(ctx context.Context, req CashoutRequest, roundID string, currentMult float64) (CashoutResponse, error) {
 	// Get bet from Redis
 	betKey := REDIS_KEY_ACTIVE_BETS + roundID
-	betJSON, err := m.redisClient.HGet(m.ctx, betKey, req.BetID).Result()
+	betJSON, err := m.redisClient.HGet(ctx, betKey, req.BetID).Result()
+	if err == redis.Nil {
+		return CashoutResponse{Message: "Bet not found"}, nil
+	}
 	if err != nil {
-		resp.Message = "Bet not found"
-		return
+		return CashoutResponse{}, fmt.Errorf("settleCashout: HGet: %w", err)
 	}
 
 	var bet ActiveBet
-	json.Unmarshal([]byte(betJSON), &bet)
+	if err := json.Unmarshal([]byte(betJSON), &bet); err != nil {
+		return CashoutResponse{}, fmt.Errorf("settleCashout: decode bet %s: %w", req.BetID, err)
+	}
+
+	// A player with several bets cashes each out separately, and only their own
+	if bet.UserID != req.UserID {
+		return CashoutResponse{Message: "Bet not found"}, nil
+	}
 
 	if bet.CashedOut {
-		resp.Message = "Already cashed out"
-		return
+		return CashoutResponse{Message: "Already cashed out"}, nil
 	}
 
 	// Calculate payout
-	payout := bet.Amount * currentMult
+	payout := bet.Amount.Mul(currentMult)
 
 	// Credit user balance
 	balanceKey := REDIS_KEY_USER_BALANCE + req.UserID
-	newBalance, err := m.redisClient.IncrByFloat(m.ctx, balanceKey, payout).Result()
+	newBalance, err := m.redisClient.IncrByFloat(ctx, balanceKey, payout.Float64()).Result()
 	if err != nil {
-		resp.Message = "Failed to credit balance"
-		return
+		return CashoutResponse{}, fmt.Errorf("settleCashout: IncrByFloat: %w", err)
 	}
 
 	// Mark as cashed out
 	bet.CashedOut = true
+	bet.CashoutMultiplier = currentMult
+	bet.CashedOutAt = time.Now()
 	betJSONBytes, _ := json.Marshal(bet)
-	m.redisClient.HSet(m.ctx, betKey, req.BetID, string(betJSONBytes))
-
-	resp.Success = true
-	resp.Multiplier = currentMult
-	resp.Payout = payout
-	resp.Balance = newBalance
-	resp.Message = fmt.Sprintf("Cashed out at %.2fx", currentMult)
+	m.redisClient.HSet(ctx, betKey, req.BetID, string(betJSONBytes))
+	m.redisClient.ZRem(ctx, REDIS_KEY_AUTO_CASHOUT+roundID, req.BetID)
+
+	outcome := GameOutcome{
+		UserID:   req.UserID,
+		GameType: GameTypeAviator,
+		Wager:    bet.Amount,
+		Payout:   payout,
+	}
+	suspicious := m.anomaly.Record(ctx, outcome)
+	recordHouseProfit(ctx, m.redisClient, outcome)
+	m.hub.GameSettled(notifications.EventAviatorCashout, outcome)
+	m.hub.BetSettled(ctx, outcome.UserID)
+	m.hub.NotifyBalance(req.UserID, newBalance, payout, BalanceReasonCashout)
 
 	// Broadcast cashout
 	m.hub.Broadcast(map[string]interface{}{
@@ -387,42 +933,154 @@ func (m *Manager) processCashout(req CashoutRequest) {
 		},
 	})
 
-	log.Printf("[CASHOUT] User %s cashed out at %.2fx (Payout: %.2f)", req.UserID, currentMult, payout)
+	notableCashout(ctx, m.redisClient, m.hub, m.notableThreshold, req.UserID, payout, currentMult, GameTypeAviator)
+
+	log.Printf("[CASHOUT] User %s cashed out at %.2fx (Payout: %s)", req.UserID, currentMult, payout)
+	return CashoutResponse{
+		Success:    true,
+		Message:    fmt.Sprintf("Cashed out at %.2fx", currentMult),
+		Multiplier: currentMult,
+		Payout:     payout,
+		Balance:    newBalance,
+		Suspicious: suspicious,
+	}, nil
 }
 
-// processAutoCashouts checks and processes auto-cashout targets
+// processAutoCashouts cashes out bets whose auto-cashout target has been reached
 func (m *Manager) processAutoCashouts(roundID string, currentMult float64, bets map[string]ActiveBet) {
-	for betID, bet := range bets {
-		if !bet.CashedOut && bet.AutoCashout > 0 && currentMult >= bet.AutoCashout {
-			go m.processCashout(CashoutRequest{
-				UserID:  bet.UserID,
-				BetID:   betID,
-				RoundID: roundID,
-			})
+	autoKey := REDIS_KEY_AUTO_CASHOUT + roundID
+
+	for _, betID := range m.triggeredAutoCashouts(roundID, currentMult) {
+		// ZREM claims the bet so it is cashed out exactly once
+		removed, err := m.redisClient.ZRem(m.ctx, autoKey, betID).Result()
+		if err != nil || removed == 0 {
+			continue
 		}
+
+		if _, exists := bets[betID]; !exists {
+			log.Printf("[AUTO CASHOUT] Bet %s not found in round %s", betID, roundID)
+			continue
+		}
+
+		m.autoCashout(roundID, betID, currentMult)
+	}
+}
+
+// autoCashout settles a triggered bet. The credit is queued and written in the
+// next batched flush rather than with its own Redis call.
+func (m *Manager) autoCashout(roundID, betID string, currentMult float64) {
+	betKey := REDIS_KEY_ACTIVE_BETS + roundID
+	betJSON, err := m.redisClient.HGet(m.ctx, betKey, betID).Result()
+	if err != nil {
+		return
+	}
+
+	var bet ActiveBet
+	json.Unmarshal([]byte(betJSON), &bet)
+	if bet.CashedOut {
+		return
+	}
+
+	payout := bet.Amount.Mul(currentMult)
+	m.queueCredit(bet.UserID, payout.Float64())
+	outcome := GameOutcome{
+		UserID:   bet.UserID,
+		GameType: GameTypeAviator,
+		Wager:    bet.Amount,
+		Payout:   payout,
+	}
+	m.anomaly.Record(m.ctx, outcome)
+	recordHouseProfit(m.ctx, m.redisClient, outcome)
+	m.hub.GameSettled(notifications.EventAviatorCashout, outcome)
+	m.hub.BetSettled(m.ctx, outcome.UserID)
+
+	bet.CashedOut = true
+	bet.CashoutMultiplier = currentMult
+	bet.CashedOutAt = time.Now()
+	betJSONBytes, _ := json.Marshal(bet)
+	m.redisClient.HSet(m.ctx, betKey, betID, string(betJSONBytes))
+
+	m.hub.Broadcast(map[string]interface{}{
+		"type": "cashout",
+		"data": CashoutMessage{
+			UserID:     bet.UserID,
+			BetID:      betID,
+			Multiplier: currentMult,
+			Payout:     payout,
+		},
+	})
+
+	notableCashout(m.ctx, m.redisClient, m.hub, m.notableThreshold, bet.UserID, payout, currentMult, GameTypeAviator)
+
+	log.Printf("[AUTO CASHOUT] User %s cashed out at %.2fx (Payout: %s)", bet.UserID, currentMult, payout)
+}
+
+// triggeredAutoCashouts returns the IDs of bets whose auto-cashout trigger
+// (target plus jitter) is at or below currentMult
+func (m *Manager) triggeredAutoCashouts(roundID string, currentMult float64) []string {
+	autoKey := REDIS_KEY_AUTO_CASHOUT + roundID
+
+	betIDs, err := m.redisClient.ZRangeByScore(m.ctx, autoKey, &redis.ZRangeBy{
+		Min: "0",
+		Max: strconv.FormatFloat(currentMult, 'f', -1, 64),
+	}).Result()
+	if err != nil {
+		return nil
 	}
+
+	return betIDs
 }
 
-// processRoundEnd handles end-of-round cleanup
-func (m *Manager) processRoundEnd(roundID string, bets map[string]ActiveBet) {
+// processRoundEnd settles the bets and side bets of a round that crashed at
+// crashPoint before any bonus event
+func (m *Manager) processRoundEnd(roundID string, crashPoint float64, bets map[string]ActiveBet) {
 	log.Printf("[ROUND END] Processing %d remaining bets", len(bets))
 
-	for _, bet := range bets {
+	// Settle auto-cashouts still waiting for a flush
+	m.FlushPendingCredits()
+
+	// The bets were loaded when the round started; reload them so bets cashed
+	// out during the round are not settled as losses
+	if current := m.loadActiveBets(roundID); len(current) > 0 {
+		bets = current
+	}
+
+	// Each of a user's bets is settled on its own
+	keys := []string{REDIS_KEY_ACTIVE_BETS + roundID, REDIS_KEY_AUTO_CASHOUT + roundID}
+	counted := make(map[string]bool)
+	for betID, bet := range bets {
+		if !counted[bet.UserID] {
+			counted[bet.UserID] = true
+			keys = append(keys, betCountKey(roundID, bet.UserID))
+		}
 		if !bet.CashedOut {
-			log.Printf("[LOSS] User %s lost %.2f", bet.UserID, bet.Amount)
+			log.Printf("[LOSS] User %s lost %s (ID: %s)", bet.UserID, bet.Amount, betID)
+			outcome := GameOutcome{
+				UserID:   bet.UserID,
+				GameType: GameTypeAviator,
+				Wager:    bet.Amount,
+			}
+			m.anomaly.Record(m.ctx, outcome)
+			recordHouseProfit(m.ctx, m.redisClient, outcome)
+			m.hub.GameSettled(notifications.EventAviatorCrash, outcome)
+			m.hub.BetSettled(m.ctx, outcome.UserID)
 		}
 	}
 
-	// Clear Redis active bets
-	betKey := REDIS_KEY_ACTIVE_BETS + roundID
-	m.redisClient.Del(m.ctx, betKey)
+	m.saveBets(roundID, bets)
+	m.settleSideBets(roundID, crashPoint)
+	keys = append(keys, REDIS_KEY_SIDE_BETS+roundID)
+
+	// Clear Redis active bets, side bets, auto-cashout index and bet counts
+	m.redisClient.Del(m.ctx, keys...)
 }
 
-// storeRoundInRedis stores round data in Redis
+// storeRoundInRedis stores round data in Redis and publishes its head
 func (m *Manager) storeRoundInRedis(round *RoundState) {
 	key := REDIS_KEY_ROUND_PREFIX + round.RoundID
 	data, _ := json.Marshal(round)
 	m.redisClient.Set(m.ctx, key, data, 1*time.Hour)
+	m.publishRoundHead(round)
 }
 
 // loadActiveBets loads active bets from Redis
@@ -444,3 +1102,54 @@ func (m *Manager) loadActiveBets(roundID string) map[string]ActiveBet {
 
 	return bets
 }
+
+// ActiveRoundInfo is an operator view of the current round
+type ActiveRoundInfo struct {
+	RoundID                 string  `json:"round_id"`
+	Status                  string  `json:"status"`
+	CurrentMultiplier       float64 `json:"current_multiplier"`
+	CrashMultiplierHash     string  `json:"crash_multiplier_hash"`
+	ActiveBetsCount         int64   `json:"active_bets_count"`
+	TotalWageredThisRound   Amount  `json:"total_wagered_this_round"`
+	TimeSinceStartMs        int64   `json:"time_since_start_ms"`
+	BettingPhaseRemainingMs int64   `json:"betting_phase_remaining_ms"`
+}
+
+// GetActiveRoundInfo returns monitoring data for the current round.
+// The crash point itself is never exposed, only a short hash prefix.
+func (m *Manager) GetActiveRoundInfo(ctx context.Context) *ActiveRoundInfo {
+	round := m.GetCurrentRound()
+	if round == nil {
+		return nil
+	}
+
+	now := time.Now()
+	remaining := round.StartTime.Add(m.bettingTime).Sub(now)
+	if remaining < 0 || round.Status != "BETTING" {
+		remaining = 0
+	}
+
+	info := &ActiveRoundInfo{
+		RoundID:                 round.RoundID,
+		Status:                  round.Status,
+		CurrentMultiplier:       round.CurrentMultiplier,
+		CrashMultiplierHash:     HashCommitment(fmt.Sprintf("%.2f", round.CrashMultiplier))[:8],
+		TimeSinceStartMs:        now.Sub(round.StartTime).Milliseconds(),
+		BettingPhaseRemainingMs: remaining.Milliseconds(),
+	}
+
+	betKey := REDIS_KEY_ACTIVE_BETS + round.RoundID
+	info.ActiveBetsCount, _ = m.redisClient.HLen(ctx, betKey).Result()
+
+	betsJSON, err := m.redisClient.HVals(ctx, betKey).Result()
+	if err == nil {
+		for _, betJSON := range betsJSON {
+			var bet ActiveBet
+			if json.Unmarshal([]byte(betJSON), &bet) == nil {
+				info.TotalWageredThisRound += bet.Amount
+			}
+		}
+	}
+
+	return info
+}
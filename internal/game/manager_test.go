@@ -0,0 +1,641 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestManager(tb testing.TB) (*Manager, *redis.Client) {
+	tb.Helper()
+
+	client := newTestRedis(tb)
+	return NewManager(NewHub(), client, nil, nil), client
+}
+
+func (m *Manager) setTestRound(roundID, status string) {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+	m.currentRound = &RoundState{
+		RoundID:           roundID,
+		CrashMultiplier:   MAX_MULTIPLIER,
+		CurrentMultiplier: MIN_MULTIPLIER,
+		Status:            status,
+		StartTime:         time.Now(),
+	}
+}
+
+func placeTestBet(t *testing.T, m *Manager, userID string, amount, autoCashout float64) string {
+	t.Helper()
+
+	resp, err := m.processBet(context.Background(), BetRequest{UserID: userID, Amount: amountOf(amount), AutoCashout: autoCashout})
+	if err != nil || !resp.Success {
+		t.Fatalf("processBet() = %+v, %v", resp, err)
+	}
+	return resp.BetID
+}
+
+func TestManager_AutoCashoutSortedSet(t *testing.T) {
+	m, client := newTestManager(t)
+	ctx := context.Background()
+	m.setTestRound("R-auto", "BETTING")
+
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"user1", 1000.0, 0)
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"user2", 1000.0, 0)
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"user3", 1000.0, 0)
+
+	lowBet := placeTestBet(t, m, "user1", 10, 1.5)
+	highBet := placeTestBet(t, m, "user2", 10, 5.0)
+	placeTestBet(t, m, "user3", 10, 0) // Manual cashout only
+
+	autoKey := REDIS_KEY_AUTO_CASHOUT + "R-auto"
+	if count := client.ZCard(ctx, autoKey).Val(); count != 2 {
+		t.Fatalf("expected 2 auto-cashout entries, got %d", count)
+	}
+
+	t.Run("only triggered bets are returned", func(t *testing.T) {
+		triggered := m.triggeredAutoCashouts("R-auto", 2.0)
+		if len(triggered) != 1 || triggered[0] != lowBet {
+			t.Errorf("expected [%s], got %v", lowBet, triggered)
+		}
+	})
+
+	t.Run("triggered bets are cashed out once", func(t *testing.T) {
+		m.setTestRound("R-auto", "RUNNING")
+		m.currentRound.CurrentMultiplier = 2.0
+		bets := m.loadActiveBets("R-auto")
+
+		m.processAutoCashouts("R-auto", 2.0, bets)
+		m.processAutoCashouts("R-auto", 2.0, bets)
+
+		if balance, _ := client.Get(ctx, REDIS_KEY_USER_BALANCE+"user1").Float64(); balance != 990.0 {
+			t.Errorf("auto-cashout credit should wait for a flush, got balance %.2f", balance)
+		}
+		m.FlushPendingCredits()
+
+		balance, _ := client.Get(ctx, REDIS_KEY_USER_BALANCE+"user1").Float64()
+		if balance != 1010.0 {
+			t.Errorf("expected balance 1010.00 after one 2.00x cashout, got %.2f", balance)
+		}
+
+		remaining := client.ZRange(ctx, autoKey, 0, -1).Val()
+		if len(remaining) != 1 || remaining[0] != highBet {
+			t.Errorf("expected only %s to remain, got %v", highBet, remaining)
+		}
+	})
+
+	t.Run("round end clears the index", func(t *testing.T) {
+		m.processRoundEnd("R-auto", MAX_MULTIPLIER, m.loadActiveBets("R-auto"))
+		if client.Exists(ctx, autoKey).Val() != 0 {
+			t.Error("auto-cashout index should be deleted at round end")
+		}
+	})
+}
+
+// seedAutoCashoutBets stores n active bets with auto-cashout targets spread over 1.01x-11x
+func seedAutoCashoutBets(b *testing.B, client *redis.Client, roundID string, n int) {
+	b.Helper()
+	ctx := context.Background()
+
+	for i := 0; i < n; i++ {
+		betID := fmt.Sprintf("BET-%d", i)
+		autoCashout := 1.01 + float64(i%1000)/100.0
+		betJSON, _ := json.Marshal(ActiveBet{
+			BetID:       betID,
+			UserID:      fmt.Sprintf("user%d", i),
+			Amount:      amountOf(10),
+			AutoCashout: autoCashout,
+		})
+		client.HSet(ctx, REDIS_KEY_ACTIVE_BETS+roundID, betID, betJSON)
+		client.ZAdd(ctx, REDIS_KEY_AUTO_CASHOUT+roundID, redis.Z{Score: autoCashout, Member: betID})
+	}
+}
+
+func BenchmarkAutoCashout_HGetAll(b *testing.B) {
+	m, client := newTestManager(b)
+	seedAutoCashoutBets(b, client, "R-bench", 1000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		triggered := 0
+		for _, bet := range m.loadActiveBets("R-bench") {
+			if !bet.CashedOut && bet.AutoCashout > 0 && 1.5 >= bet.AutoCashout {
+				triggered++
+			}
+		}
+	}
+}
+
+func BenchmarkAutoCashout_SortedSet(b *testing.B) {
+	m, client := newTestManager(b)
+	seedAutoCashoutBets(b, client, "R-bench", 1000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = m.triggeredAutoCashouts("R-bench", 1.5)
+	}
+}
+
+func TestManager_SeedPrecommitment(t *testing.T) {
+	m, client := newTestManager(t)
+	go m.hub.Run()
+
+	conn := &mockConn{}
+	m.hub.RegisterClient(conn, "observer")
+	waitForClients(t, m.hub, 1)
+
+	first := m.startNewRound()
+	if first.CommitmentPublishedAt.IsZero() {
+		t.Error("first round should record when its commitment was published")
+	}
+
+	m.stateMutex.Lock()
+	m.crashRound(first.RoundID, nil)
+	m.stateMutex.Unlock()
+
+	if client.Exists(context.Background(), REDIS_KEY_NEXT_SEED+first.RoundID).Val() != 1 {
+		t.Fatal("next round seed should be stored in Redis")
+	}
+
+	second := m.startNewRound()
+
+	var crash, roundStart map[string]interface{}
+	for _, msg := range waitForMessages(t, conn, 4) {
+		switch {
+		case msg["type"] == "crash":
+			crash = msg
+		case msg["type"] == "round_start" && msg["round_id"] == second.RoundID:
+			roundStart = msg
+		}
+	}
+	if crash == nil || roundStart == nil {
+		t.Fatal("expected crash and round_start messages")
+	}
+
+	if crash["next_round_commitment"] != roundStart["commitment"] {
+		t.Errorf("crash next_round_commitment %v does not match round_start commitment %v",
+			crash["next_round_commitment"], roundStart["commitment"])
+	}
+	if second.HashCommitment != CommitRoundSeed(second.ServerSeed) {
+		t.Error("round commitment must commit to its server seed")
+	}
+	if second.CommitmentPublishedAt.After(second.StartTime) {
+		t.Error("commitment should be published before the round starts")
+	}
+	if client.Exists(context.Background(), REDIS_KEY_NEXT_SEED+first.RoundID).Val() != 0 {
+		t.Error("consumed seed should be removed from Redis")
+	}
+}
+
+// waitForMessages waits for n hub messages and decodes them
+func waitForMessages(t *testing.T, conn *mockConn, n int) []map[string]interface{} {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for conn.count() < n && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if conn.count() < n {
+		t.Fatalf("received %d messages, want %d", conn.count(), n)
+	}
+
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+
+	messages := make([]map[string]interface{}, 0, n)
+	for _, data := range conn.messages[:n] {
+		var msg map[string]interface{}
+		if err := json.Unmarshal(data, &msg); err != nil {
+			t.Fatalf("could not unmarshal message: %v", err)
+		}
+		messages = append(messages, msg)
+	}
+	return messages
+}
+
+func TestManager_RoundCountdowns(t *testing.T) {
+	m, _ := newTestManager(t)
+	m.bettingTime = 500 * time.Millisecond
+	m.countdownInterval = 100 * time.Millisecond
+	m.nextRoundDelay = 300 * time.Millisecond
+
+	// The hub is not running, so broadcasts stay queued in order
+	done := make(chan struct{})
+	go func() {
+		m.runRound()
+		close(done)
+	}()
+
+	var sequence []string
+	collect := func(message interface{}) {
+		msg := message.(map[string]interface{})
+		msgType := msg["type"].(string)
+		switch msgType {
+		case "update":
+			return
+		case "round_running":
+			// Crash on the next tick instead of waiting for the real crash point
+			m.stateMutex.Lock()
+			m.currentRound.CrashMultiplier = MIN_MULTIPLIER
+			m.stateMutex.Unlock()
+		case "betting_countdown", "next_round_countdown":
+			if msg["next_round_in"].(float64) < 0 {
+				t.Errorf("%s next_round_in should not be negative", msgType)
+			}
+			msgType = fmt.Sprintf("%s:%d", msgType, msg["seconds_left"])
+		}
+		sequence = append(sequence, msgType)
+	}
+
+	timeout := time.After(5 * time.Second)
+	for running := true; running; {
+		select {
+		case message := <-m.hub.broadcast:
+			collect(message)
+		case <-done:
+			running = false
+		case <-timeout:
+			t.Fatal("timed out waiting for round to finish")
+		}
+	}
+	for len(m.hub.broadcast) > 0 {
+		collect(<-m.hub.broadcast)
+	}
+
+	want := []string{
+		"round_start",
+		"betting_countdown:4",
+		"betting_countdown:3",
+		"betting_countdown:2",
+		"betting_countdown:1",
+		"betting_stats",
+		"round_running",
+		"crash",
+		"seed_reveal",
+		"next_round_countdown:3",
+		"next_round_countdown:2",
+		"next_round_countdown:1",
+	}
+	if fmt.Sprint(sequence) != fmt.Sprint(want) {
+		t.Errorf("broadcast sequence = %v, want %v", sequence, want)
+	}
+}
+
+func TestManager_InterRoundDelay(t *testing.T) {
+	m, client := newTestManager(t)
+	m.bettingTime = 200 * time.Millisecond
+	m.nextRoundDelay = 1 * time.Second
+
+	done := make(chan struct{})
+	go func() {
+		m.runRound()
+		close(done)
+	}()
+
+	var crash map[string]interface{}
+	var countdowns []map[string]interface{}
+	var countdownAt time.Time
+	timeout := time.After(5 * time.Second)
+	for running := true; running; {
+		select {
+		case message := <-m.hub.broadcast:
+			msg := message.(map[string]interface{})
+			switch msg["type"] {
+			case "round_running":
+				m.stateMutex.Lock()
+				m.currentRound.CrashMultiplier = MIN_MULTIPLIER
+				m.stateMutex.Unlock()
+			case "crash":
+				crash = msg
+			case "next_round_countdown":
+				countdowns = append(countdowns, msg)
+				countdownAt = time.Now()
+			}
+		case <-done:
+			running = false
+		case <-timeout:
+			t.Fatal("timed out waiting for round to finish")
+		}
+	}
+	endedAt := time.Now()
+
+	if len(countdowns) != 1 || countdowns[0]["seconds_left"] != 1 {
+		t.Fatalf("next_round_countdown messages = %v, want one with 1 second left", countdowns)
+	}
+	if commitment := countdowns[0]["next_round_commitment"]; commitment == "" || commitment != crash["next_round_commitment"] {
+		t.Errorf("countdown commitment = %v, want the crash's next_round_commitment %v", commitment, crash["next_round_commitment"])
+	}
+	if before := endedAt.Sub(countdownAt); before < 900*time.Millisecond || before > 1500*time.Millisecond {
+		t.Errorf("countdown sent %v before the next round, want about 1s", before)
+	}
+
+	if crash["betting_duration_ms"].(int64) < 200 || crash["running_duration_ms"].(int64) < 0 || crash["delay_duration_ms"] != int64(1000) {
+		t.Errorf("crash phase durations = %v/%v/%v, want betting of at least 200ms and a planned 1000ms delay",
+			crash["betting_duration_ms"], crash["running_duration_ms"], crash["delay_duration_ms"])
+	}
+
+	var stored RoundState
+	data, _ := client.Get(context.Background(), REDIS_KEY_ROUND_PREFIX+m.GetCurrentRound().RoundID).Bytes()
+	if err := json.Unmarshal(data, &stored); err != nil {
+		t.Fatalf("could not unmarshal stored round: %v", err)
+	}
+	if stored.BettingDurationMs < 200 || stored.DelayDurationMs < 1000 {
+		t.Errorf("stored round durations = %d/%d/%d, want the measured phases", stored.BettingDurationMs, stored.RunningDurationMs, stored.DelayDurationMs)
+	}
+}
+
+func TestManager_HashChain(t *testing.T) {
+	m, client := newTestManager(t)
+	m.chainLength = 11 // Ten played rounds plus the pre-committed next seed
+	ctx := context.Background()
+
+	playRound := func() {
+		round := m.startNewRound()
+		m.stateMutex.Lock()
+		m.crashRound(round.RoundID, nil)
+		m.stateMutex.Unlock()
+	}
+	for i := 0; i < 10; i++ {
+		playRound()
+	}
+
+	rounds, err := m.ChainRounds(ctx, "", "")
+	if err != nil {
+		t.Fatalf("ChainRounds() error: %v", err)
+	}
+	if len(rounds) != 10 {
+		t.Fatalf("ChainRounds() returned %d rounds, want 10", len(rounds))
+	}
+	if valid, brokenAt := VerifyHashChain(rounds); !valid {
+		t.Fatalf("chain broken at round %d", brokenAt)
+	}
+	if head := client.Get(ctx, REDIS_KEY_CHAIN_CURRENT_SEED).Val(); HashCommitment(head) != rounds[9].ServerSeed {
+		t.Error("chain head should be the pre-committed seed of the next round")
+	}
+
+	t.Run("range selects rounds", func(t *testing.T) {
+		subset, err := m.ChainRounds(ctx, rounds[2].RoundID, rounds[5].RoundID)
+		if err != nil || len(subset) != 4 || subset[0].RoundID != rounds[2].RoundID {
+			t.Errorf("ChainRounds(range) = %d rounds, %v", len(subset), err)
+		}
+		if _, err := m.ChainRounds(ctx, rounds[5].RoundID, rounds[2].RoundID); err != ErrInvalidChainRange {
+			t.Errorf("reversed range error = %v, want ErrInvalidChainRange", err)
+		}
+		if _, err := m.ChainRounds(ctx, "missing", ""); err != ErrRoundNotInChain {
+			t.Errorf("unknown round error = %v, want ErrRoundNotInChain", err)
+		}
+	})
+
+	t.Run("exhausted chain starts a new one", func(t *testing.T) {
+		playRound()
+		playRound()
+		rounds, _ := m.ChainRounds(ctx, "", "")
+		if valid, brokenAt := VerifyHashChain(rounds); valid || brokenAt != 11 {
+			t.Errorf("VerifyHashChain() = %v, %d; want a break at the new chain", valid, brokenAt)
+		}
+	})
+}
+
+func TestManager_MaxBetsPerRound(t *testing.T) {
+	m, client := newTestManager(t)
+	ctx := context.Background()
+	m.setTestRound("R-multi", "BETTING")
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"user1", 100.0, 0)
+
+	safeBet := placeTestBet(t, m, "user1", 10, 1.5)
+	riskyBet := placeTestBet(t, m, "user1", 10, 0)
+
+	if resp, _ := m.processBet(ctx, BetRequest{UserID: "user1", Amount: amountOf(10)}); resp.Success || resp.Message != "Maximum bets per round reached" {
+		t.Fatalf("third bet = %+v, want it rejected", resp)
+	}
+	if balance, _ := client.Get(ctx, REDIS_KEY_USER_BALANCE+"user1").Float64(); balance != 80.0 {
+		t.Errorf("balance = %.2f, want 80.00 after two bets", balance)
+	}
+
+	roundID, bets, err := m.GetUserBets(ctx, "user1")
+	if err != nil || roundID != "R-multi" {
+		t.Fatalf("GetUserBets() = %q, %v", roundID, err)
+	}
+	if len(bets) != 2 || bets[0].BetID != safeBet || bets[1].BetID != riskyBet {
+		t.Errorf("GetUserBets() = %+v, want [%s %s]", bets, safeBet, riskyBet)
+	}
+
+	// Cancelling a bet frees its slot
+	if !m.cancelBet(riskyBet) {
+		t.Fatal("cancelBet() failed")
+	}
+	placeTestBet(t, m, "user1", 10, 0)
+
+	// Other players have their own limit
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"user2", 100.0, 0)
+	placeTestBet(t, m, "user2", 10, 0)
+
+	m.processRoundEnd("R-multi", MAX_MULTIPLIER, m.loadActiveBets("R-multi"))
+	if exists := client.Exists(ctx, betCountKey("R-multi", "user1")).Val(); exists != 0 {
+		t.Error("bet counts should be cleared at round end")
+	}
+}
+
+func TestManager_ProcessBetRejections(t *testing.T) {
+	m, client := newTestManager(t)
+	ctx := context.Background()
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"user1", 50.0, 0)
+
+	tests := []struct {
+		name   string
+		status string
+		amount float64
+		want   string
+	}{
+		{"amount below minimum", "BETTING", MIN_BET_AMOUNT / 2, fmt.Sprintf("Bet must be between %.2f and %.2f", MIN_BET_AMOUNT, MAX_BET_AMOUNT)},
+		{"amount above maximum", "BETTING", MAX_BET_AMOUNT * 2, fmt.Sprintf("Bet must be between %.2f and %.2f", MIN_BET_AMOUNT, MAX_BET_AMOUNT)},
+		{"round running", "RUNNING", 10, "Betting is closed"},
+		{"insufficient balance", "BETTING", 60, "Insufficient balance"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m.setTestRound("R-reject", tt.status)
+			resp, err := m.processBet(ctx, BetRequest{UserID: "user1", Amount: amountOf(tt.amount)})
+			if err != nil {
+				t.Fatalf("processBet() error = %v, want a rejection", err)
+			}
+			if resp.Success || resp.Message != tt.want {
+				t.Errorf("processBet() = %+v, want message %q", resp, tt.want)
+			}
+		})
+	}
+
+	if balance, _ := client.Get(ctx, REDIS_KEY_USER_BALANCE+"user1").Float64(); balance != 50.0 {
+		t.Errorf("balance = %.2f, want 50.00 untouched", balance)
+	}
+	if count, _ := client.Get(ctx, betCountKey("R-reject", "user1")).Int(); count != 0 {
+		t.Errorf("bet count = %d, want the slot released", count)
+	}
+}
+
+func TestManager_ProcessBetRedisFailure(t *testing.T) {
+	m, client := newTestManager(t)
+	m.setTestRound("R-down", "BETTING")
+	client.Close()
+
+	resp, err := m.processBet(context.Background(), BetRequest{UserID: "user1", Amount: amountOf(10)})
+	if !errors.Is(err, redis.ErrClosed) {
+		t.Errorf("processBet() error = %v, want it to wrap %v", err, redis.ErrClosed)
+	}
+	if resp.Success {
+		t.Errorf("processBet() = %+v, want a failure", resp)
+	}
+}
+
+func TestManager_ProcessCashoutRejections(t *testing.T) {
+	m, client := newTestManager(t)
+	ctx := context.Background()
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"user1", 50.0, 0)
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"user2", 50.0, 0)
+
+	m.setTestRound("R-cashout", "BETTING")
+	betID := placeTestBet(t, m, "user1", 10, 0)
+	if resp, _ := m.processCashout(ctx, CashoutRequest{UserID: "user1", BetID: betID}); resp.Message != "Cannot cashout now" {
+		t.Errorf("cashout while betting = %+v, want it rejected", resp)
+	}
+
+	m.setTestRound("R-cashout", "RUNNING")
+	for name, req := range map[string]CashoutRequest{
+		"unknown bet":   {UserID: "user1", BetID: "BET-missing"},
+		"another's bet": {UserID: "user2", BetID: betID},
+	} {
+		resp, err := m.processCashout(ctx, req)
+		if err != nil || resp.Success || resp.Message != "Bet not found" {
+			t.Errorf("%s: processCashout() = %+v, %v, want Bet not found", name, resp, err)
+		}
+	}
+
+	if resp, err := m.processCashout(ctx, CashoutRequest{UserID: "user1", BetID: betID}); err != nil || !resp.Success {
+		t.Fatalf("processCashout() = %+v, %v", resp, err)
+	}
+	if resp, _ := m.processCashout(ctx, CashoutRequest{UserID: "user1", BetID: betID}); resp.Message != "Already cashed out" {
+		t.Errorf("second cashout = %+v, want it rejected", resp)
+	}
+}
+
+func TestManager_PanicRefundsBets(t *testing.T) {
+	m, client := newTestManager(t)
+	m.bettingTime = time.Minute
+	ctx := context.Background()
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"user1", 100.0, 0)
+
+	// Replying on a closed channel panics once the bet has been taken
+	respChan := make(chan BetResult)
+	close(respChan)
+	m.betChannel <- BetRequest{UserID: "user1", Amount: amountOf(25), ResponseChan: respChan}
+
+	done := make(chan struct{})
+	go func() {
+		m.runRound()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("runRound did not recover from the panic")
+	}
+
+	if balance, _ := client.Get(ctx, REDIS_KEY_USER_BALANCE+"user1").Float64(); balance != 100.0 {
+		t.Errorf("balance = %.2f, want the 25.00 bet refunded", balance)
+	}
+
+	round := m.GetCurrentRound()
+	if round.Status != "ABORTED" {
+		t.Errorf("round status = %s, want ABORTED", round.Status)
+	}
+	var stored RoundState
+	json.Unmarshal([]byte(client.Get(ctx, REDIS_KEY_ROUND_PREFIX+round.RoundID).Val()), &stored)
+	if stored.Status != "ABORTED" {
+		t.Errorf("stored round status = %s, want ABORTED", stored.Status)
+	}
+	if client.Exists(ctx, REDIS_KEY_ACTIVE_BETS+round.RoundID).Val() != 0 {
+		t.Error("active bets should be cleared")
+	}
+
+	aborted := false
+	for len(m.hub.broadcast) > 0 {
+		msg, ok := (<-m.hub.broadcast).(map[string]interface{})
+		if ok && msg["type"] == "round_aborted" && msg["reason"] == "internal_error" {
+			aborted = true
+		}
+	}
+	if !aborted {
+		t.Error("expected a round_aborted broadcast")
+	}
+}
+
+func TestManager_PanicAfterCrashKeepsLosses(t *testing.T) {
+	m, client := newTestManager(t)
+	m.bettingTime = 50 * time.Millisecond
+	m.SetCrashPoint(MIN_MULTIPLIER)
+	ctx := context.Background()
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"user1", 100.0, 0)
+
+	// A detector without a Redis client panics while settling the loss
+	m.anomaly = &AnomalyDetector{}
+	m.betChannel <- BetRequest{UserID: "user1", Amount: amountOf(25), ResponseChan: make(chan BetResult, 1)}
+
+	done := make(chan struct{})
+	go func() {
+		m.runRound()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("runRound did not recover from the panic")
+	}
+
+	if balance, _ := client.Get(ctx, REDIS_KEY_USER_BALANCE+"user1").Float64(); balance != 75.0 {
+		t.Errorf("balance = %.2f, want the 25.00 bet lost", balance)
+	}
+	if round := m.GetCurrentRound(); round.Status != "CRASHED" {
+		t.Errorf("round status = %s, want CRASHED", round.Status)
+	}
+	for len(m.hub.broadcast) > 0 {
+		if msg, ok := (<-m.hub.broadcast).(map[string]interface{}); ok && msg["type"] == "round_aborted" {
+			t.Error("a crashed round should not be aborted")
+		}
+	}
+}
+
+// SetCrashPoint makes every round started from now on crash at mult instead
+// of its provably fair crash point, for deterministic tests. A mult of 0
+// restores fair crash points. It is only built into tests, so a server
+// binary cannot rig a round.
+func (m *Manager) SetCrashPoint(mult float64) {
+	if mult != 0 && mult < MIN_MULTIPLIER {
+		mult = MIN_MULTIPLIER
+	}
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+	m.fixedCrashPoint = mult
+}
+
+func TestManager_SetCrashPoint(t *testing.T) {
+	m, _ := newTestManager(t)
+
+	m.SetCrashPoint(2.5)
+	for i := 0; i < 3; i++ {
+		if round := m.startNewRound(); round.CrashMultiplier != 2.5 {
+			t.Fatalf("round %d crash point = %v, want 2.5", i+1, round.CrashMultiplier)
+		}
+	}
+
+	m.SetCrashPoint(0)
+	round := m.startNewRound()
+	if want := HashAndMapToMultiplier(round.ServerSeed, round.ClientSeed, round.Nonce); round.CrashMultiplier != math.Round(want*100)/100 {
+		t.Errorf("crash point = %v after clearing, want the fair %v", round.CrashMultiplier, want)
+	}
+}
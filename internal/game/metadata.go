@@ -0,0 +1,60 @@
+package game
+
+import (
+	"context"
+	"sort"
+)
+
+type GameEndpoint struct {
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	Description string `json:"description"`
+}
+
+// GameMetadata describes a game for the discovery endpoint
+type GameMetadata struct {
+	GameType     GameType       `json:"game_type"`
+	Status       string         `json:"status"` // available, maintenance
+	Maintenance  bool           `json:"maintenance"`
+	MinBet       float64        `json:"min_bet"`
+	MaxBet       float64        `json:"max_bet"`
+	Description  string         `json:"description"`
+	Endpoints    []GameEndpoint `json:"endpoints"`
+	HouseEdgePct float64        `json:"house_edge_pct"`
+}
+
+// GameInfos returns the metadata of every registered engine, sorted by game type
+func (gf *GameFactory) GameInfos(ctx context.Context) []GameMetadata {
+	infos := make([]GameMetadata, 0, len(gf.engines))
+	for gameType := range gf.engines {
+		info, _ := gf.GameInfo(ctx, gameType)
+		infos = append(infos, info)
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].GameType < infos[j].GameType
+	})
+	return infos
+}
+
+// GameInfo returns the metadata of one engine, including its maintenance flag
+func (gf *GameFactory) GameInfo(ctx context.Context, gameType GameType) (GameMetadata, bool) {
+	engine, exists := gf.engines[gameType]
+	if !exists {
+		return GameMetadata{}, false
+	}
+
+	info := engine.GameInfo()
+	info.Maintenance = gf.IsDisabled(ctx, gameType)
+	info.Status = "available"
+	if info.Maintenance {
+		info.Status = "maintenance"
+	}
+	return info, true
+}
+
+// IsDisabled reports whether a game type has been put under maintenance
+func (gf *GameFactory) IsDisabled(ctx context.Context, gameType GameType) bool {
+	disabled, err := gf.redisClient.Exists(ctx, REDIS_KEY_GAME_MAINTENANCE+string(gameType)).Result()
+	return err == nil && disabled > 0
+}
@@ -0,0 +1,115 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"time"
+)
+
+const (
+	REDIS_KEY_MINES_CASHOUT_ALL_LIMIT = "mines:cashout_all:"
+	MINES_CASHOUT_ALL_INTERVAL        = 5 * time.Second
+)
+
+var ErrCashoutAllRateLimited = errors.New("Cash out all can only be used once every 5 seconds")
+
+type MinesCashoutAllRequest struct {
+	UserID string `json:"user_id"`
+}
+
+// MinesCashoutAllResult is the outcome for one game. Games without a revealed
+// tile cannot be cashed out and are skipped.
+type MinesCashoutAllResult struct {
+	GameID  string `json:"game_id"`
+	Success bool   `json:"success"`
+	Skipped bool   `json:"skipped,omitempty"`
+	Message string `json:"message"`
+	Payout  Amount `json:"payout"`
+}
+
+type MinesCashoutAllResponse struct {
+	Success        bool                    `json:"success"`
+	Message        string                  `json:"message"`
+	GamesCashedOut int                     `json:"games_cashed_out"`
+	TotalPayout    Amount                  `json:"total_payout"`
+	Balance        float64                 `json:"balance"`
+	Results        []MinesCashoutAllResult `json:"results"`
+	Currency
+}
+
+// handleCashoutAll cashes out every active game of a user, one at a time,
+// and reports the games it skipped alongside those it cashed out
+func (m *MinesEngine) handleCashoutAll(ctx context.Context, req interface{}) (interface{}, error) {
+	cashoutReq, ok := req.(MinesCashoutAllRequest)
+	if !ok {
+		return nil, errors.New("invalid request type")
+	}
+
+	allowed, err := m.redisClient.SetNX(ctx, REDIS_KEY_MINES_CASHOUT_ALL_LIMIT+cashoutReq.UserID, time.Now().Unix(), MINES_CASHOUT_ALL_INTERVAL).Result()
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrCashoutAllRateLimited
+	}
+
+	games, err := m.activeGamesOf(ctx, cashoutReq.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := MinesCashoutAllResponse{
+		Success: true,
+		Results: make([]MinesCashoutAllResult, 0, len(games)),
+	}
+	for _, gameState := range games {
+		result := MinesCashoutAllResult{GameID: gameState.GameID}
+		if len(gameState.RevealedTiles) == 0 {
+			result.Skipped = true
+			result.Message = "Must reveal at least one tile before cashing out"
+			resp.Results = append(resp.Results, result)
+			continue
+		}
+
+		cashout := m.cashoutGame(ctx, cashoutReq.UserID, gameState.GameID, 0)
+		result.Success = cashout.Success
+		result.Message = cashout.Message
+		result.Payout = cashout.Payout
+		resp.Results = append(resp.Results, result)
+		if cashout.Success {
+			resp.GamesCashedOut++
+			resp.TotalPayout = resp.TotalPayout.Add(cashout.Payout)
+		}
+	}
+
+	resp.Balance, _ = m.redisClient.Get(ctx, REDIS_KEY_USER_BALANCE+cashoutReq.UserID).Float64()
+	resp.Message = "No active games"
+	if len(games) > 0 {
+		resp.Message = "Cashed out active games"
+		log.Printf("[MINES] User %s cashed out %d of %d active games for %s", cashoutReq.UserID, resp.GamesCashedOut, len(games), resp.TotalPayout)
+	}
+	return resp, nil
+}
+
+// activeGamesOf returns a user's active games, oldest first
+func (m *MinesEngine) activeGamesOf(ctx context.Context, userID string) ([]*MinesGameState, error) {
+	var games []*MinesGameState
+	iter := m.redisClient.Scan(ctx, 0, REDIS_KEY_MINES_GAME+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		gameJSON, err := m.redisClient.Get(ctx, iter.Val()).Result()
+		if err != nil {
+			continue // Expired since the scan found it
+		}
+		gameState, err := decodeMinesGame(gameJSON)
+		if err != nil {
+			continue
+		}
+		if gameState.UserID == userID && gameState.Status == "ACTIVE" {
+			games = append(games, gameState)
+		}
+	}
+	slices.SortFunc(games, func(a, b *MinesGameState) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return games, iter.Err()
+}
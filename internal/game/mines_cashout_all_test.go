@@ -0,0 +1,70 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestMinesEngine_CashoutAll(t *testing.T) {
+	engine, client := newTestMinesEngine(t)
+	ctx := context.Background()
+
+	var gameIDs []string
+	for i := 0; i < 3; i++ {
+		resp, _ := engine.PlaceBet(ctx, MinesBetRequest{UserID: "user1", Amount: 10, MineCount: 1})
+		gameIDs = append(gameIDs, resp.(MinesBetResponse).GameID)
+	}
+	for _, gameID := range gameIDs[:2] {
+		gameState, _ := engine.loadGame(ctx, gameID)
+		tile := 0
+		if gameState.MinePositions[0] == tile {
+			tile = 1
+		}
+		if resp, _ := engine.ProcessAction(ctx, "click", MinesClickRequest{UserID: "user1", GameID: gameID, TileID: tile}); !resp.(MinesClickResponse).Success {
+			t.Fatalf("click on %s failed: %+v", gameID, resp)
+		}
+	}
+	// Another user's game is left alone
+	other, _ := engine.PlaceBet(ctx, MinesBetRequest{UserID: "user1", Amount: 10, MineCount: 1})
+	otherState, _ := engine.loadGame(ctx, other.(MinesBetResponse).GameID)
+	otherState.UserID = "user2"
+	engine.saveGame(ctx, otherState)
+
+	resp, err := engine.ProcessAction(ctx, "cashout_all", MinesCashoutAllRequest{UserID: "user1"})
+	if err != nil {
+		t.Fatalf("cashout all error: %v", err)
+	}
+	all := resp.(MinesCashoutAllResponse)
+	if all.GamesCashedOut != 2 || len(all.Results) != 3 {
+		t.Fatalf("cashout all = %+v, want 2 of 3 games cashed out", all)
+	}
+
+	var total Amount
+	for i, result := range all.Results {
+		if result.GameID != gameIDs[i] {
+			t.Errorf("results[%d] is game %s, want %s", i, result.GameID, gameIDs[i])
+		}
+		skipped := i == 2
+		if result.Skipped != skipped || result.Success == skipped {
+			t.Errorf("results[%d] = %+v, want skipped %v", i, result, skipped)
+		}
+		total += result.Payout
+	}
+	if all.TotalPayout != total || total == 0 {
+		t.Errorf("total payout = %s, want the sum %s", all.TotalPayout, total)
+	}
+	if balance, _ := client.Get(ctx, REDIS_KEY_USER_BALANCE+"user1").Float64(); all.Balance != balance || amountOf(balance) != amountOf(1000-40).Add(total) {
+		t.Errorf("balance = %v (stored %v), want 960 plus %s", all.Balance, balance, total)
+	}
+	if skipped, _ := engine.loadGame(ctx, gameIDs[2]); skipped.Status != "ACTIVE" {
+		t.Errorf("skipped game status = %s, want ACTIVE", skipped.Status)
+	}
+	if otherGame, _ := engine.loadGame(ctx, otherState.GameID); otherGame.Status != "ACTIVE" {
+		t.Errorf("other user's game status = %s, want ACTIVE", otherGame.Status)
+	}
+
+	if _, err := engine.ProcessAction(ctx, "cashout_all", MinesCashoutAllRequest{UserID: "user1"}); !errors.Is(err, ErrCashoutAllRateLimited) {
+		t.Errorf("second cashout all: err = %v, want ErrCashoutAllRateLimited", err)
+	}
+}
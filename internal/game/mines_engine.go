@@ -2,47 +2,97 @@ package game
 
 import (
 	"context"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
-	"math/big"
+	"math"
+	"runtime/debug"
+	"sync"
 	"time"
 
 	"github.com/redis/go-redis/v9"
+
+	"aviator/internal/notifications"
 )
 
 const (
-	MINES_GRID_SIZE        = 25 // 5x5 grid
+	MINES_GRID_SIZE        = 25 // Default 5x5 grid
 	MINES_MIN_COUNT        = 1
-	MINES_MAX_COUNT        = 24
+	MINES_MAX_COUNT        = MINES_GRID_SIZE - 1 // Max for the default grid
 	REDIS_KEY_MINES_GAME   = "mines:game:"
 	REDIS_KEY_MINES_BALANCE = "mines:balance:"
+	REDIS_KEY_MINES_HISTORY = "mines:history:"
+	MINES_HOUSE_EDGE       = 0.03 // 3%
+	MINES_UPDATE_RETRIES   = 3
+
+	MINES_AUTO_COMPLETE_DELAY_MS = 200   // Pause between automatic clicks
+	MINES_AUTO_COMPLETE_FEE      = 0.005 // 0.5% of the final payout
+	MINES_FORFEIT_PENALTY_PCT    = 10.0  // Percent of the bet kept when a game is forfeited
 )
 
+var (
+	ErrGameNotFound      = errors.New("game not found")
+	ErrGameStateConflict = errors.New("game was modified concurrently")
+	ErrNotGameOwner      = errors.New("game does not belong to user")
+)
+
+// minesRejection rejects an action from inside a state update with a message for the player
+type minesRejection string
+
+func (r minesRejection) Error() string {
+	return string(r)
+}
+
+// Supported grid sizes: 3x3, 4x4 and 5x5
+var minesGridSizes = map[int]bool{9: true, 16: true, 25: true}
+
+// minesMaxCount returns the maximum mine count for a grid (at least one safe tile)
+func minesMaxCount(gridSize int) int {
+	return gridSize - 1
+}
+
 type MinesGameState struct {
 	GameID       string    `json:"game_id"`
 	UserID       string    `json:"user_id"`
-	BetAmount    float64   `json:"bet_amount"`
+	BetAmount    Amount    `json:"bet_amount"`
 	MineCount    int       `json:"mine_count"`
+	GridSize     int       `json:"grid_size"`
+	GridShape    MinesGridShape `json:"grid_shape"` // Rows*Cols is GridSize
 	ServerSeed   string    `json:"-"` // Hidden until game ends
 	ClientSeed   string    `json:"client_seed"`
 	Nonce        int       `json:"nonce"`
 	MinePositions []int    `json:"-"` // Hidden until game ends
 	RevealedTiles []int    `json:"revealed_tiles"`
-	CurrentPayout float64  `json:"current_payout"`
-	Status       string    `json:"status"` // ACTIVE, CASHED_OUT, BUSTED
+	BustedTile    *int     `json:"busted_tile,omitempty"` // The mine that ended the game
+	CurrentPayout Amount   `json:"current_payout"`
+	Status       string    `json:"status"` // ACTIVE, CASHED_OUT, BUSTED, ABORTED, FORFEITED
 	CreatedAt    time.Time `json:"created_at"`
 	EndedAt      time.Time `json:"ended_at,omitempty"`
+	Version      int       `json:"version"` // Incremented on every update
+	HouseEdge    *float64  `json:"house_edge,omitempty"` // Fixed at bet time; nil means MINES_HOUSE_EDGE
+}
+
+// houseEdge returns the edge the game was started with
+func (g *MinesGameState) houseEdge() float64 {
+	if g.HouseEdge == nil {
+		return MINES_HOUSE_EDGE
+	}
+	return *g.HouseEdge
+}
+
+// payoutMultiplier returns the payout as a multiple of the bet, to two decimals
+func (g *MinesGameState) payoutMultiplier() float64 {
+	return math.Round(float64(g.CurrentPayout)/float64(g.BetAmount)*100) / 100
 }
 
 type MinesBetRequest struct {
-	UserID    string  `json:"user_id"`
-	Amount    float64 `json:"amount"`
-	MineCount int     `json:"mine_count"`
+	UserID     string  `json:"user_id"`
+	Amount     float64 `json:"amount"`
+	MineCount  int     `json:"mine_count"`
+	GridSize   int     `json:"grid_size,omitempty"`   // 9, 16 or 25 (default)
+	GridShape  MinesGridShape `json:"grid_shape,omitempty"` // Square when omitted; sets the grid size
+	ClientSeed string  `json:"client_seed,omitempty"` // Generated when empty
 }
 
 type MinesBetResponse struct {
@@ -50,13 +100,17 @@ type MinesBetResponse struct {
 	Message       string  `json:"message"`
 	GameID        string  `json:"game_id,omitempty"`
 	Balance       float64 `json:"balance,omitempty"`
-	CurrentPayout float64 `json:"current_payout"`
+	CurrentPayout Amount  `json:"current_payout"`
+	ClientSeed    string  `json:"client_seed,omitempty"`
+
+	ResultSignature string `json:"result_signature,omitempty"` // See SignResponse
 }
 
 type MinesClickRequest struct {
-	UserID string `json:"user_id"`
-	GameID string `json:"game_id"`
-	TileID int    `json:"tile_id"`
+	UserID      string `json:"user_id"`
+	GameID      string `json:"game_id"`
+	TileID      int    `json:"tile_id"`
+	DisplayMode string `json:"display_mode,omitempty"` // probability, multiplier (default) or payout
 }
 
 type MinesClickResponse struct {
@@ -64,20 +118,69 @@ type MinesClickResponse struct {
 	Message       string  `json:"message"`
 	TileID        int     `json:"tile_id"`
 	IsMine        bool    `json:"is_mine"`
-	CurrentPayout float64 `json:"current_payout"`
+	CurrentPayout Amount  `json:"current_payout"`
 	GameStatus    string  `json:"game_status"`
 	Balance       float64 `json:"balance,omitempty"`
+	Suspicious    bool    `json:"suspicious,omitempty"`
+	DisplayMode   string  `json:"display_mode,omitempty"`
+
+	// Set after a safe reveal in probability mode
+	TileProbabilities []MinesTileProbability `json:"tile_probabilities,omitempty"`
+
+	ResultSignature string `json:"result_signature,omitempty"` // See SignResponse
 }
 
 type MinesCashoutRequest struct {
 	UserID string `json:"user_id"`
 	GameID string `json:"game_id"`
 }
+
+type MinesStateRequest struct {
+	GameID string `json:"game_id"`
+}
+
+// MinesActiveGameRequest asks for the game a user is currently playing
+type MinesActiveGameRequest struct {
+	UserID string `json:"user_id"`
+}
+
 type MinesCashoutResponse struct {
 	Success bool    `json:"success"`
 	Message string  `json:"message"`
-	Payout  float64 `json:"payout"`
+	Payout  Amount  `json:"payout"`
+	Fee     Amount  `json:"fee,omitempty"`
 	Balance float64 `json:"balance"`
+	Currency
+
+	Suspicious bool `json:"suspicious,omitempty"`
+}
+
+// MinesForfeitRequest abandons an active game before any tile is revealed
+type MinesForfeitRequest struct {
+	UserID string `json:"user_id"`
+	GameID string `json:"game_id"`
+}
+
+type MinesForfeitResponse struct {
+	Success       bool    `json:"success"`
+	Message       string  `json:"message"`
+	ForfeitRefund Amount  `json:"forfeit_refund"`
+	PenaltyPct    float64 `json:"penalty_pct"`
+	Balance       float64 `json:"balance"`
+}
+
+type MinesAutoCompleteRequest struct {
+	UserID string `json:"user_id"`
+	GameID string `json:"game_id"`
+}
+
+type MinesAutoCompleteResponse struct {
+	Success       bool                 `json:"success"`
+	Message       string               `json:"message"`
+	TilesRevealed []MinesClickResponse `json:"tiles_revealed"`
+	FinalPayout   Amount               `json:"final_payout"`
+	Fee           Amount               `json:"fee"`
+	Balance       float64              `json:"balance"`
 }
 
 type MinesEngine struct {
@@ -85,6 +188,20 @@ type MinesEngine struct {
 	hub         *Hub
 	ctx         context.Context
 	nonce       int
+	anomaly     *AnomalyDetector
+
+	autoCompleteDelay time.Duration
+	autoCompleteFee   float64
+	forfeitPenaltyPct float64
+	notableThreshold  float64 // Payouts above this are announced to everyone
+
+	// Key bet and click responses are signed with
+	signingKey string
+
+	sessions *sessionLimiter // Active games
+
+	stopChan chan struct{}
+	stopOnce sync.Once
 }
 
 func NewMinesEngine(redisClient *redis.Client, hub *Hub) *MinesEngine {
@@ -93,6 +210,17 @@ func NewMinesEngine(redisClient *redis.Client, hub *Hub) *MinesEngine {
 		hub:         hub,
 		ctx:         context.Background(),
 		nonce:       0,
+		anomaly:     NewAnomalyDetector(redisClient),
+
+		autoCompleteDelay: time.Duration(getEnvAsInt("MINES_AUTO_COMPLETE_DELAY_MS", MINES_AUTO_COMPLETE_DELAY_MS)) * time.Millisecond,
+		autoCompleteFee:   getEnvAsFloat("MINES_AUTO_COMPLETE_FEE", MINES_AUTO_COMPLETE_FEE),
+		forfeitPenaltyPct: getEnvAsFloat("MINES_FORFEIT_PENALTY_PCT", MINES_FORFEIT_PENALTY_PCT),
+		notableThreshold:  getEnvAsFloat("NOTABLE_CASHOUT_THRESHOLD", NOTABLE_CASHOUT_THRESHOLD),
+
+		signingKey: ResultSigningKey(),
+
+		sessions: newSessionLimiter(redisClient, GameTypeMines, REDIS_KEY_MINES_ACTIVE_COUNT, getEnvAsInt("MINES_MAX_CONCURRENT_GAMES", MINES_MAX_CONCURRENT_GAMES)),
+		stopChan: make(chan struct{}),
 	}
 }
 
@@ -101,40 +229,80 @@ func (m *MinesEngine) GetType() GameType {
 }
 func (m *MinesEngine) Start(ctx context.Context) error {
 	m.ctx = ctx
+	go m.reconcileActiveGames(ctx)
 	log.Println("[MINES] Engine started")
 	return nil
 }
 
 func (m *MinesEngine) Stop() error {
+	m.stopOnce.Do(func() { close(m.stopChan) })
 	log.Println("[MINES] Engine stopped")
 	return nil
 }
 func (m *MinesEngine) GetState() interface{} {
 	return map[string]string{"status": "ready"}
 }
+
+func (m *MinesEngine) GameInfo() GameMetadata {
+	return GameMetadata{
+		GameType:     GameTypeMines,
+		MinBet:       MIN_BET_AMOUNT,
+		MaxBet:       MAX_BET_AMOUNT,
+		Description:  "Reveal safe tiles on a 3x3, 4x4 or 5x5 grid and cash out before hitting a mine",
+		HouseEdgePct: MINES_HOUSE_EDGE * 100,
+		Endpoints: []GameEndpoint{
+			{Method: "POST", Path: "/api/v1/mines/bet", Description: "Start a game"},
+			{Method: "POST", Path: "/api/v1/mines/click", Description: "Reveal a tile"},
+			{Method: "POST", Path: "/api/v1/mines/cashout", Description: "Cash out the current payout"},
+			{Method: "POST", Path: "/api/v1/mines/cashout-all", Description: "Cash out every active game"},
+			{Method: "POST", Path: "/api/v1/mines/multi-game", Description: "Play several games with server-chosen tiles"},
+			{Method: "GET", Path: "/api/v1/mines/game/:gameID/state", Description: "Public state of a game"},
+			{Method: "GET", Path: "/api/v1/mines/grid/:gameID", Description: "Tiles of a game in the rows of its grid"},
+			{Method: "GET", Path: "/api/v1/mines/odds-table", Description: "Payout table by mine count"},
+		},
+	}
+}
+
+// HealthCheck verifies that Redis, where all game state lives, is reachable
+func (m *MinesEngine) HealthCheck(ctx context.Context) (HealthStatus, error) {
+	start := time.Now()
+	err := m.redisClient.Get(ctx, "mines:health").Err()
+	if err == redis.Nil {
+		err = nil // The key is never written; reaching Redis is enough
+	}
+	return newHealthStatus(start, err)
+}
 func (m *MinesEngine) PlaceBet(ctx context.Context, req interface{}) (interface{}, error) {
+	resp, err := m.placeBet(ctx, req)
+	return signResult(resp, m.signingKey), err
+}
+
+func (m *MinesEngine) placeBet(ctx context.Context, req interface{}) (interface{}, error) {
 	betReq, ok := req.(MinesBetRequest)
 	if !ok {
 		return nil, errors.New("invalid request type")
 	}
 
-	if betReq.MineCount < MINES_MIN_COUNT || betReq.MineCount > MINES_MAX_COUNT {
+	if message := validateMinesBet(&betReq); message != "" {
 		return MinesBetResponse{
 			Success: false,
-			Message: fmt.Sprintf("Mine count must be between %d and %d", MINES_MIN_COUNT, MINES_MAX_COUNT),
+			Message: message,
 		}, nil
 	}
 
-	if betReq.Amount < MIN_BET_AMOUNT || betReq.Amount > MAX_BET_AMOUNT {
-		return MinesBetResponse{
-			Success: false,
-			Message: fmt.Sprintf("Bet must be between %.2f and %.2f", MIN_BET_AMOUNT, MAX_BET_AMOUNT),
-		}, nil
+	if err := checkMaintenance(ctx, m.redisClient, GameTypeMines); err != nil {
+		return nil, err
+	}
+
+	// The game counts as active until it is settled
+	if err := m.sessions.acquire(ctx, 1); err != nil {
+		return nil, err
 	}
 
 	balanceKey := REDIS_KEY_USER_BALANCE + betReq.UserID
 	balance, err := m.redisClient.Get(ctx, balanceKey).Float64()
 	if err != nil || balance < betReq.Amount {
+		m.sessions.release(ctx, 1)
 		return MinesBetResponse{
 			Success: false,
 			Message: "Insufficient balance",
@@ -145,127 +313,215 @@ func (m *MinesEngine) PlaceBet(ctx context.Context, req interface{}) (interface{
 	newBalance, err := m.redisClient.IncrByFloat(ctx, balanceKey, -betReq.Amount).Result()
 	if err != nil || newBalance < 0 {
 		m.redisClient.IncrByFloat(ctx, balanceKey, betReq.Amount) // Rollback
+		m.sessions.release(ctx, 1)
 		return MinesBetResponse{
 			Success: false,
 			Message: "Transaction failed",
 		}, nil
 	}
+	betAmount, _ := NewAmount(betReq.Amount) // Precision checked by validateMinesBet
+	m.hub.NotifyBalance(betReq.UserID, newBalance, -betAmount, BalanceReasonBet)
+	recordWager(ctx, m.redisClient, betReq.UserID, betAmount)
 
 	// Generate provably fair mine positions
 	m.nonce++
 	serverSeed := GenerateSeed()
-	clientSeed := GenerateSeed()
-	minePositions := m.generateMinePositions(serverSeed, clientSeed, m.nonce, betReq.MineCount)
+	clientSeed := clientSeedOrGenerate(betReq.ClientSeed)
+	minePositions := m.generateMinePositions(serverSeed, clientSeed, m.nonce, betReq.MineCount, betReq.GridSize)
 
 	// Create game state
 	gameID := fmt.Sprintf("MINES-%s-%d", betReq.UserID, time.Now().UnixNano())
+	houseEdge := GetCurrentHouseEdge(GameTypeMines)
 	gameState := MinesGameState{
 		GameID:        gameID,
 		UserID:        betReq.UserID,
-		BetAmount:     betReq.Amount,
+		BetAmount:     betAmount,
 		MineCount:     betReq.MineCount,
+		GridSize:      betReq.GridSize,
+		GridShape:     betReq.GridShape,
 		ServerSeed:    serverSeed,
 		ClientSeed:    clientSeed,
 		Nonce:         m.nonce,
 		MinePositions: minePositions,
 		RevealedTiles: []int{},
-		CurrentPayout: betReq.Amount,
+		CurrentPayout: betAmount,
 		Status:        "ACTIVE",
 		CreatedAt:     time.Now(),
+		HouseEdge:     &houseEdge,
 	}
 
 	// Store game state in Redis
-	gameKey := REDIS_KEY_MINES_GAME + gameID
-	gameJSON, _ := json.Marshal(gameState)
-	m.redisClient.Set(ctx, gameKey, gameJSON, 1*time.Hour)
+	m.saveGame(ctx, &gameState)
+	if err := recordGameHistory(ctx, m.redisClient, REDIS_KEY_MINES_HISTORY+betReq.UserID, gameID, gameState.CreatedAt); err != nil {
+		log.Printf("[MINES] Failed to record history for %s: %v", betReq.UserID, err)
+	}
 
-	log.Printf("[MINES] Game %s started for user %s with %d mines", gameID, betReq.UserID, betReq.MineCount)
+	log.Printf("[MINES] Game %s started for user %s with %d mines on a %dx%d grid", gameID, betReq.UserID, betReq.MineCount, betReq.GridShape.Rows, betReq.GridShape.Cols)
 
 	return MinesBetResponse{
 		Success:       true,
 		Message:       "Game started",
 		GameID:        gameID,
 		Balance:       newBalance,
-		CurrentPayout: betReq.Amount,
+		CurrentPayout: betAmount,
+		ClientSeed:    clientSeed,
 	}, nil
 }
 
+// validateMinesBet checks a bet without touching balances, applying the default
+// grid size and shape. It returns a message for the player, or "" if the bet is valid.
+func validateMinesBet(betReq *MinesBetRequest) string {
+	if betReq.GridShape != (MinesGridShape{}) {
+		if msg := validateMinesGridShape(betReq.GridShape, betReq.GridSize); msg != "" {
+			return msg
+		}
+		betReq.GridSize = betReq.GridShape.Size()
+	}
+	if betReq.GridSize == 0 {
+		betReq.GridSize = MINES_GRID_SIZE
+	}
+	if betReq.GridShape == (MinesGridShape{}) && minesGridSizes[betReq.GridSize] {
+		betReq.GridShape = squareMinesGridShape(betReq.GridSize)
+	}
+	if msg := ValidateMinesLayout(betReq.GridSize, betReq.MineCount); msg != "" {
+		return msg
+	}
+	if msg := ValidateClientSeed(betReq.ClientSeed); msg != "" {
+		return msg
+	}
+
+	return validateBetAmount(betReq.Amount)
+}
+
+// ValidateMinesLayout checks a grid size and mine count. It returns a message
+// for the player, or "" if the layout is valid.
+func ValidateMinesLayout(gridSize, mineCount int) string {
+	if !minesGridSizes[gridSize] {
+		return "Grid size must be 9, 16, or 25"
+	}
+
+	maxCount := minesMaxCount(gridSize)
+	if mineCount < MINES_MIN_COUNT || mineCount > maxCount {
+		return fmt.Sprintf("Mine count must be between %d and %d", MINES_MIN_COUNT, maxCount)
+	}
+	return ""
+}
+
+// validateMinesGridShape checks a grid shape against the grid size it was
+// requested with, if any. It returns a message for the player, or "" if the
+// shape is valid.
+func validateMinesGridShape(shape MinesGridShape, gridSize int) string {
+	if shape.Rows < 1 || shape.Cols < 1 || !minesGridSizes[shape.Size()] {
+		return "Grid shape must have 9, 16, or 25 tiles"
+	}
+	if gridSize != 0 && gridSize != shape.Size() {
+		return "Grid size must match grid shape"
+	}
+	return ""
+}
+
 func (m *MinesEngine) ProcessAction(ctx context.Context, action string, req interface{}) (interface{}, error) {
 	switch action {
 	case "click":
-		return m.handleTileClick(ctx, req)
+		resp, err := m.handleTileClick(ctx, req)
+		return signResult(resp, m.signingKey), err
 	case "cashout":
-		return m.handleCashout(ctx, req)
+		resp, err := m.handleCashout(ctx, req)
+		return withCurrency(resp), err
+	case "cashout_all":
+		resp, err := m.handleCashoutAll(ctx, req)
+		return withCurrency(resp), err
+	case "state":
+		return m.handleGetState(ctx, req)
+	case "grid":
+		return m.handleGrid(ctx, req)
+	case "auto_complete":
+		return m.handleAutoComplete(ctx, req)
+	case "preselect":
+		resp, err := m.handlePreselect(ctx, req)
+		return signResult(withCurrency(resp), m.signingKey), err
+	case "multi_game":
+		resp, err := m.handleMultiGame(ctx, req)
+		return signResult(withCurrency(resp), m.signingKey), err
+	case "probabilities":
+		return m.handleProbabilities(ctx, req)
+	case "forfeit":
+		return m.handleForfeit(ctx, req)
+	case "leaderboard":
+		return m.handleLeaderboard(ctx, req)
+	case "active_game":
+		return m.handleActiveGame(ctx, req)
+	case "active_count":
+		return m.activeStats(ctx)
+	case "active_by_user":
+		return m.activeGamesByUser(ctx)
+	case "live_sessions":
+		return m.liveSessions(ctx)
+	case "capacity":
+		return m.sessions.capacity(ctx)
+	case "history":
+		historyReq, ok := req.(GameHistoryRequest)
+		if !ok {
+			return nil, errors.New("invalid request type")
+		}
+		return m.history(ctx, historyReq)
 	default:
 		return nil, errors.New("unknown action")
 	}
 }
 
 // handleTileClick processes a tile click
-func (m *MinesEngine) handleTileClick(ctx context.Context, req interface{}) (interface{}, error) {
+func (m *MinesEngine) handleTileClick(ctx context.Context, req interface{}) (resp interface{}, err error) {
 	clickReq, ok := req.(MinesClickRequest)
 	if !ok {
 		return nil, errors.New("invalid request type")
 	}
-
-	// Load game state
-	gameKey := REDIS_KEY_MINES_GAME + clickReq.GameID
-	gameJSON, err := m.redisClient.Get(ctx, gameKey).Result()
-	if err != nil {
+	if !validMinesDisplayMode(clickReq.DisplayMode) {
 		return MinesClickResponse{
 			Success: false,
-			Message: "Game not found",
+			Message: "Display mode must be probability, multiplier or payout",
 		}, nil
 	}
+	if clickReq.DisplayMode == "" {
+		clickReq.DisplayMode = MinesDisplayMultiplier
+	}
 
-	var gameState MinesGameState
-	json.Unmarshal([]byte(gameJSON), &gameState)
+	// A failed click must not leave the stake stuck in an unfinished game
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("[PANIC] Mines game %s: %v\n%s", clickReq.GameID, r, debug.Stack())
+			resp, err = m.abortGame(ctx, clickReq.GameID), nil
+		}
+	}()
 
-	if gameState.Status != "ACTIVE" {
-		return MinesClickResponse{
-			Success: false,
-			Message: "Game is not active",
-		}, nil
-	}
+	var isMine bool
+	gameState, err := m.updateGameStateAtomic(ctx, REDIS_KEY_MINES_GAME+clickReq.GameID, func(gameState *MinesGameState) error {
+		if gameState.UserID != clickReq.UserID {
+			return ErrNotGameOwner
+		}
+		if gameState.Status != "ACTIVE" {
+			return minesRejection("Game is not active")
+		}
+		if err := checkMinesTile(gameState, clickReq.TileID); err != nil {
+			return err
+		}
 
-	// Validate tile ID
-	if clickReq.TileID < 0 || clickReq.TileID >= MINES_GRID_SIZE {
+		isMine = m.revealTile(gameState, clickReq.TileID)
+		return nil
+	}, nil)
+	if err != nil {
 		return MinesClickResponse{
 			Success: false,
-			Message: "Invalid tile ID",
+			Message: minesErrorMessage(err),
 		}, nil
 	}
 
-	// Check if tile already revealed
-	for _, revealed := range gameState.RevealedTiles {
-		if revealed == clickReq.TileID {
-			return MinesClickResponse{
-				Success: false,
-				Message: "Tile already revealed",
-			}, nil
-		}
-	}
-
-	// Check if tile is a mine
-	isMine := false
-	for _, minePos := range gameState.MinePositions {
-		if minePos == clickReq.TileID {
-			isMine = true
-			break
-		}
-	}
+	recordActivity(ctx, m.redisClient, REDIS_KEY_MINES_CLICKS)
+	m.broadcastGameUpdate(gameState)
 
 	if isMine {
-		// Player hit a mine - game over
-		gameState.Status = "BUSTED"
-		gameState.EndedAt = time.Now()
-		gameState.CurrentPayout = 0
-
-		// Update game state
-		gameJSON, _ := json.Marshal(gameState)
-		m.redisClient.Set(ctx, gameKey, gameJSON, 1*time.Hour)
-
 		log.Printf("[MINES] User %s hit a mine at tile %d", clickReq.UserID, clickReq.TileID)
+		suspicious := m.settleBust(ctx, gameState)
 
 		return MinesClickResponse{
 			Success:       true,
@@ -274,27 +530,147 @@ func (m *MinesEngine) handleTileClick(ctx context.Context, req interface{}) (int
 			IsMine:        true,
 			CurrentPayout: 0,
 			GameStatus:    "BUSTED",
+			Suspicious:    suspicious,
+			DisplayMode:   clickReq.DisplayMode,
 		}, nil
 	}
 
-	// Safe tile - update payout
-	gameState.RevealedTiles = append(gameState.RevealedTiles, clickReq.TileID)
-	gameState.CurrentPayout = m.calculatePayout(gameState.BetAmount, gameState.MineCount, len(gameState.RevealedTiles))
-
-	// Update game state
-	updatedGameJSON, _ := json.Marshal(gameState)
-	m.redisClient.Set(ctx, gameKey, string(updatedGameJSON), 1*time.Hour)
+	log.Printf("[MINES] User %s revealed safe tile %d, payout: %s", clickReq.UserID, clickReq.TileID, gameState.CurrentPayout)
 
-	log.Printf("[MINES] User %s revealed safe tile %d, payout: %.2f", clickReq.UserID, clickReq.TileID, gameState.CurrentPayout)
-
-	return MinesClickResponse{
+	clickResp := MinesClickResponse{
 		Success:       true,
 		Message:       "Safe tile!",
 		TileID:        clickReq.TileID,
 		IsMine:        false,
 		CurrentPayout: gameState.CurrentPayout,
 		GameStatus:    "ACTIVE",
-	}, nil
+		DisplayMode:   clickReq.DisplayMode,
+	}
+	if clickReq.DisplayMode == MinesDisplayProbability {
+		clickResp.TileProbabilities = MinesTileProbabilities(gameState)
+	}
+	return clickResp, nil
+}
+
+// checkMinesTile rejects a tile that is off the grid or already revealed
+func checkMinesTile(gameState *MinesGameState, tileID int) error {
+	if tileID < 0 || tileID >= gameState.GridShape.Size() {
+		return minesRejection("Invalid tile ID")
+	}
+	for _, revealed := range gameState.RevealedTiles {
+		if revealed == tileID {
+			return minesRejection("Tile already revealed")
+		}
+	}
+	return nil
+}
+
+// revealTile reveals a tile checked by checkMinesTile. A mine busts the game;
+// a safe tile raises the payout. It reports whether the tile was a mine.
+func (m *MinesEngine) revealTile(gameState *MinesGameState, tileID int) bool {
+	for _, minePos := range gameState.MinePositions {
+		if minePos == tileID {
+			gameState.Status = "BUSTED"
+			gameState.EndedAt = time.Now()
+			gameState.CurrentPayout = 0
+			gameState.BustedTile = &tileID
+			return true
+		}
+	}
+
+	gameState.RevealedTiles = append(gameState.RevealedTiles, tileID)
+	gameState.CurrentPayout = m.payoutWithEdge(gameState.BetAmount, gameState.MineCount, len(gameState.RevealedTiles), gameState.GridSize, gameState.houseEdge())
+	return false
+}
+
+// settleBust records a busted game and reports whether the player looks suspicious
+func (m *MinesEngine) settleBust(ctx context.Context, gameState *MinesGameState) bool {
+	m.sessions.release(ctx, 1)
+	m.recordLeaderboards(ctx, gameState)
+
+	outcome := GameOutcome{
+		UserID:   gameState.UserID,
+		GameType: GameTypeMines,
+		Wager:    gameState.BetAmount,
+	}
+	suspicious := m.anomaly.Record(ctx, outcome)
+	recordHouseProfit(ctx, m.redisClient, outcome)
+	m.hub.GameSettled(notifications.EventMinesBust, outcome)
+	m.hub.BetSettled(ctx, outcome.UserID)
+	return suspicious
+}
+
+// abortGame ends a game that is still active and refunds its bet. A game that
+// has already busted, cashed out or been forfeited keeps its result.
+func (m *MinesEngine) abortGame(ctx context.Context, gameID string) MinesClickResponse {
+	var refundCmd *redis.FloatCmd
+	var settledStatus string
+	gameState, err := m.updateGameStateAtomic(ctx, REDIS_KEY_MINES_GAME+gameID, func(gameState *MinesGameState) error {
+		if gameState.Status != "ACTIVE" {
+			settledStatus = gameState.Status
+			return minesRejection("Game already settled")
+		}
+		gameState.Status = "ABORTED"
+		gameState.EndedAt = time.Now()
+		gameState.CurrentPayout = 0
+		return nil
+	}, func(pipe redis.Pipeliner, gameState *MinesGameState) {
+		refundCmd = pipe.IncrByFloat(ctx, REDIS_KEY_USER_BALANCE+gameState.UserID, gameState.BetAmount.Float64())
+	})
+	if settledStatus != "" {
+		log.Printf("[PANIC] Mines game %s was already %s, keeping its result", gameID, settledStatus)
+		return MinesClickResponse{
+			Success:    false,
+			Message:    "Game already settled",
+			GameStatus: settledStatus,
+		}
+	}
+	if err != nil {
+		log.Printf("[PANIC] Failed to abort Mines game %s: %v", gameID, err)
+		return MinesClickResponse{
+			Success: false,
+			Message: "Internal error",
+		}
+	}
+
+	m.sessions.release(ctx, 1)
+	m.broadcastGameUpdate(gameState)
+	m.hub.NotifyBalance(gameState.UserID, refundCmd.Val(), gameState.BetAmount, BalanceReasonRefund)
+	log.Printf("[PANIC] Aborted Mines game %s and refunded %s to %s", gameID, gameState.BetAmount, gameState.UserID)
+
+	return MinesClickResponse{
+		Success:    false,
+		Message:    "Game aborted, bet refunded",
+		GameStatus: "ABORTED",
+		Balance:    refundCmd.Val(),
+	}
+}
+
+// voidGame removes a game that is still active as if it had never been
+// placed: its session slot, wager and history entry are released and the bet
+// amount is returned for the caller to refund. A game that has already been
+// settled, and so reported to the leaderboards and webhook, is not voided.
+func (m *MinesEngine) voidGame(ctx context.Context, gameID string) (Amount, bool) {
+	gameKey := REDIS_KEY_MINES_GAME + gameID
+	gameState, err := m.updateGameStateAtomic(ctx, gameKey, func(gameState *MinesGameState) error {
+		if gameState.Status != "ACTIVE" {
+			return minesRejection("Game already settled")
+		}
+		gameState.Status = "VOIDED"
+		return nil
+	}, func(pipe redis.Pipeliner, gameState *MinesGameState) {
+		pipe.Del(ctx, gameKey)
+		pipe.ZRem(ctx, REDIS_KEY_MINES_HISTORY+gameState.UserID, gameID)
+		pipe.IncrByFloat(ctx, REDIS_KEY_USER_WAGERED+gameState.UserID, -gameState.BetAmount.Float64())
+	})
+	if err != nil {
+		log.Printf("[MINES] Game %s could not be voided: %v", gameID, err)
+		return 0, false
+	}
+
+	m.sessions.release(ctx, 1)
+	log.Printf("[MINES] Voided game %s of %s", gameID, gameState.UserID)
+	return gameState.BetAmount, true
 }
 
 // handleCashout processes a cashout request
@@ -304,104 +680,403 @@ func (m *MinesEngine) handleCashout(ctx context.Context, req interface{}) (inter
 		return nil, errors.New("invalid request type")
 	}
 
-	// Load game state
-	gameKey := REDIS_KEY_MINES_GAME + cashoutReq.GameID
-	gameJSON, err := m.redisClient.Get(ctx, gameKey).Result()
+	return m.cashoutGame(ctx, cashoutReq.UserID, cashoutReq.GameID, 0), nil
+}
+
+// cashoutGame ends an active game and credits its payout minus feeRate
+func (m *MinesEngine) cashoutGame(ctx context.Context, userID, gameID string, feeRate float64) MinesCashoutResponse {
+	var fee Amount
+	var creditCmd *redis.FloatCmd
+	gameState, err := m.updateGameStateAtomic(ctx, REDIS_KEY_MINES_GAME+gameID, func(gameState *MinesGameState) error {
+		if gameState.UserID != userID {
+			return ErrNotGameOwner
+		}
+
+		// Validate game status
+		if gameState.Status != "ACTIVE" {
+			return minesRejection("Game is not active")
+		}
+
+		// Must have revealed at least one tile
+		if len(gameState.RevealedTiles) == 0 {
+			return minesRejection("Must reveal at least one tile before cashing out")
+		}
+
+		// Update game status
+		fee = gameState.CurrentPayout.Mul(feeRate)
+		gameState.CurrentPayout -= fee
+		gameState.Status = "CASHED_OUT"
+		gameState.EndedAt = time.Now()
+		return nil
+	}, func(pipe redis.Pipeliner, gameState *MinesGameState) {
+		// Credit user balance in the same transaction as the status change
+		balanceKey := REDIS_KEY_USER_BALANCE + gameState.UserID
+		creditCmd = pipe.IncrByFloat(ctx, balanceKey, gameState.CurrentPayout.Float64())
+	})
 	if err != nil {
 		return MinesCashoutResponse{
+			Success: false,
+			Message: minesErrorMessage(err),
+		}
+	}
+
+	m.sessions.release(ctx, 1)
+	m.broadcastGameUpdate(gameState)
+	m.hub.NotifyBalance(gameState.UserID, creditCmd.Val(), gameState.CurrentPayout, BalanceReasonCashout)
+
+	log.Printf("[MINES] User %s cashed out for %s", gameState.UserID, gameState.CurrentPayout)
+	m.recordLeaderboards(ctx, gameState)
+	notableCashout(ctx, m.redisClient, m.hub, m.notableThreshold, gameState.UserID, gameState.CurrentPayout, gameState.payoutMultiplier(), GameTypeMines)
+
+	outcome := GameOutcome{
+		UserID:   gameState.UserID,
+		GameType: GameTypeMines,
+		Wager:    gameState.BetAmount,
+		Payout:   gameState.CurrentPayout,
+	}
+	suspicious := m.anomaly.Record(ctx, outcome)
+	recordHouseProfit(ctx, m.redisClient, outcome)
+	m.hub.GameSettled(notifications.EventMinesCashout, outcome)
+	m.hub.BetSettled(ctx, outcome.UserID)
+
+	return MinesCashoutResponse{
+		Success: true,
+		Message: "Cashed out successfully",
+		Payout:  gameState.CurrentPayout,
+		Fee:     fee,
+		Balance: creditCmd.Val(),
+
+		Suspicious: suspicious,
+	}
+}
+
+// handleForfeit abandons an active game before any tile is revealed and
+// refunds the bet minus the forfeit penalty
+func (m *MinesEngine) handleForfeit(ctx context.Context, req interface{}) (interface{}, error) {
+	forfeitReq, ok := req.(MinesForfeitRequest)
+	if !ok {
+		return nil, errors.New("invalid request type")
+	}
+
+	var refund Amount
+	var refundCmd *redis.FloatCmd
+	gameState, err := m.updateGameStateAtomic(ctx, REDIS_KEY_MINES_GAME+forfeitReq.GameID, func(gameState *MinesGameState) error {
+		if gameState.UserID != forfeitReq.UserID {
+			return ErrGameNotFound
+		}
+		if gameState.Status != "ACTIVE" {
+			return minesRejection("Game is not active")
+		}
+		if len(gameState.RevealedTiles) > 0 {
+			return minesRejection("Cannot forfeit after revealing tiles; use cashout instead")
+		}
+
+		refund = gameState.BetAmount.Mul(1 - m.forfeitPenaltyPct/100)
+		gameState.Status = "FORFEITED"
+		gameState.EndedAt = time.Now()
+		gameState.CurrentPayout = refund
+		return nil
+	}, func(pipe redis.Pipeliner, gameState *MinesGameState) {
+		refundCmd = pipe.IncrByFloat(ctx, REDIS_KEY_USER_BALANCE+gameState.UserID, refund.Float64())
+	})
+	if err != nil {
+		return MinesForfeitResponse{
+			Success: false,
+			Message: minesErrorMessage(err),
+		}, nil
+	}
+
+	m.sessions.release(ctx, 1)
+	m.broadcastGameUpdate(gameState)
+	m.hub.NotifyBalance(gameState.UserID, refundCmd.Val(), refund, BalanceReasonRefund)
+	log.Printf("[MINES] User %s forfeited game %s, refunded %s", gameState.UserID, gameState.GameID, refund)
+
+	return MinesForfeitResponse{
+		Success:       true,
+		Message:       "Game forfeited",
+		ForfeitRefund: refund,
+		PenaltyPct:    m.forfeitPenaltyPct,
+		Balance:       refundCmd.Val(),
+	}, nil
+}
+
+// handleAutoComplete reveals every remaining safe tile, pausing between clicks,
+// and then cashes out with the auto-complete fee deducted
+func (m *MinesEngine) handleAutoComplete(ctx context.Context, req interface{}) (interface{}, error) {
+	autoReq, ok := req.(MinesAutoCompleteRequest)
+	if !ok {
+		return nil, errors.New("invalid request type")
+	}
+
+	gameState, err := m.loadGame(ctx, autoReq.GameID)
+	if err != nil {
+		return MinesAutoCompleteResponse{
 			Success: false,
 			Message: "Game not found",
 		}, nil
 	}
 
-	var gameState MinesGameState
-	json.Unmarshal([]byte(gameJSON), &gameState)
+	if gameState.UserID != autoReq.UserID {
+		return MinesAutoCompleteResponse{
+			Success: false,
+			Message: "Game does not belong to user",
+		}, nil
+	}
 
-	// Validate game status
 	if gameState.Status != "ACTIVE" {
-		return MinesCashoutResponse{
+		return MinesAutoCompleteResponse{
 			Success: false,
 			Message: "Game is not active",
 		}, nil
 	}
 
-	// Must have revealed at least one tile
 	if len(gameState.RevealedTiles) == 0 {
-		return MinesCashoutResponse{
+		return MinesAutoCompleteResponse{
 			Success: false,
-			Message: "Must reveal at least one tile before cashing out",
+			Message: "Must reveal at least one tile before auto-completing",
 		}, nil
 	}
 
-	// Update game status
-	gameState.Status = "CASHED_OUT"
-	gameState.EndedAt = time.Now()
+	skip := make(map[int]bool)
+	for _, tile := range gameState.MinePositions {
+		skip[tile] = true
+	}
+	for _, tile := range gameState.RevealedTiles {
+		skip[tile] = true
+	}
+
+	resp := MinesAutoCompleteResponse{TilesRevealed: []MinesClickResponse{}}
+	for tile := 0; tile < gameState.GridSize; tile++ {
+		if skip[tile] {
+			continue
+		}
+
+		if len(resp.TilesRevealed) > 0 {
+			select {
+			case <-time.After(m.autoCompleteDelay):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+
+		clickResp, _ := m.handleTileClick(ctx, MinesClickRequest{
+			UserID: autoReq.UserID,
+			GameID: autoReq.GameID,
+			TileID: tile,
+		})
+		click := clickResp.(MinesClickResponse)
+		if !click.Success {
+			// The game changed underneath us, e.g. the player cashed out
+			resp.Message = click.Message
+			return resp, nil
+		}
+		resp.TilesRevealed = append(resp.TilesRevealed, click)
+	}
+
+	cashout := m.cashoutGame(ctx, autoReq.UserID, autoReq.GameID, m.autoCompleteFee)
+	if !cashout.Success {
+		resp.Message = cashout.Message
+		return resp, nil
+	}
+
+	log.Printf("[MINES] User %s auto-completed %s, revealing %d tiles (fee %s)", autoReq.UserID, autoReq.GameID, len(resp.TilesRevealed), cashout.Fee)
 
-	// Credit user balance
-	balanceKey := REDIS_KEY_USER_BALANCE + cashoutReq.UserID
-	newBalance, err := m.redisClient.IncrByFloat(ctx, balanceKey, gameState.CurrentPayout).Result()
+	resp.Success = true
+	resp.Message = "Game auto-completed"
+	resp.FinalPayout = cashout.Payout
+	resp.Fee = cashout.Fee
+	resp.Balance = cashout.Balance
+	return resp, nil
+}
+
+// handleGetState returns the public state of a Mines game
+func (m *MinesEngine) handleGetState(ctx context.Context, req interface{}) (interface{}, error) {
+	stateReq, ok := req.(MinesStateRequest)
+	if !ok {
+		return nil, errors.New("invalid request type")
+	}
+
+	gameState, err := m.loadGame(ctx, stateReq.GameID)
 	if err != nil {
-		return MinesCashoutResponse{
-			Success: false,
-			Message: "Failed to credit balance",
-		}, nil
+		return nil, err
 	}
 
-	// Update game state
-	gameJSONBytes, _ := json.Marshal(gameState)
-	m.redisClient.Set(ctx, gameKey, string(gameJSONBytes), 1*time.Hour)
+	return *gameState, nil
+}
 
-	log.Printf("[MINES] User %s cashed out for %.2f", cashoutReq.UserID, gameState.CurrentPayout)
+// handleActiveGame returns the public state of a user's active game, or nil
+// when they have none
+func (m *MinesEngine) handleActiveGame(ctx context.Context, req interface{}) (interface{}, error) {
+	activeReq, ok := req.(MinesActiveGameRequest)
+	if !ok {
+		return nil, errors.New("invalid request type")
+	}
 
-	return MinesCashoutResponse{
-		Success: true,
-		Message: "Cashed out successfully",
-		Payout:  gameState.CurrentPayout,
-		Balance: newBalance,
-	}, nil
+	games, err := m.activeGamesOf(ctx, activeReq.UserID)
+	if err != nil || len(games) == 0 {
+		return nil, err
+	}
+	return games[0], nil
 }
 
-// generateMinePositions generates mine positions using provably fair algorithm
-func (m *MinesEngine) generateMinePositions(serverSeed, clientSeed string, nonce, mineCount int) []int {
-	positions := make([]int, 0, mineCount)
-	used := make(map[int]bool)
-
-	// Use the hash to generate mine positions
-	for i := 0; len(positions) < mineCount && i < 100; i++ {
-		// Create a new hash for each position
-		posHash := hmac.New(sha256.New, []byte(serverSeed))
-		posHash.Write([]byte(fmt.Sprintf("%s:%d:%d", clientSeed, nonce, i)))
-		posHashBytes := posHash.Sum(nil)
-		posHashHex := hex.EncodeToString(posHashBytes)
-
-		// Take first 8 hex characters
-		hexValue := posHashHex[:8]
-		bigInt := new(big.Int)
-		bigInt.SetString(hexValue, 16)
-
-		// Map to grid position
-		position := int(bigInt.Uint64() % uint64(MINES_GRID_SIZE))
-
-		if !used[position] {
-			positions = append(positions, position)
-			used[position] = true
+// minesStoredGame is the Redis representation of a game. It keeps the seed and
+// mine positions that MinesGameState hides from API responses.
+type minesStoredGame struct {
+	MinesGameState
+	ServerSeed    string `json:"server_seed"`
+	MinePositions []int  `json:"mine_positions"`
+}
+
+// saveGame persists the full game state, including hidden fields
+func (m *MinesEngine) saveGame(ctx context.Context, gameState *MinesGameState) {
+	m.redisClient.Set(ctx, REDIS_KEY_MINES_GAME+gameState.GameID, encodeMinesGame(gameState), 1*time.Hour)
+}
+
+// loadGame reads a game saved by saveGame
+func (m *MinesEngine) loadGame(ctx context.Context, gameID string) (*MinesGameState, error) {
+	gameJSON, err := m.redisClient.Get(ctx, REDIS_KEY_MINES_GAME+gameID).Result()
+	if err != nil {
+		return nil, ErrGameNotFound
+	}
+	return decodeMinesGame(gameJSON)
+}
+
+// UpdateGameStateAtomic applies update to the game stored at gameKey using
+// WATCH/MULTI/EXEC, retrying when another request modified the game first
+func (m *MinesEngine) UpdateGameStateAtomic(ctx context.Context, gameKey string, update func(*MinesGameState) error) error {
+	_, err := m.updateGameStateAtomic(ctx, gameKey, update, nil)
+	return err
+}
+
+// updateGameStateAtomic is UpdateGameStateAtomic with optional extra commands
+// queued in the same transaction. It returns the state that was written.
+func (m *MinesEngine) updateGameStateAtomic(ctx context.Context, gameKey string, update func(*MinesGameState) error, queue func(redis.Pipeliner, *MinesGameState)) (*MinesGameState, error) {
+	var gameState *MinesGameState
+
+	txf := func(tx *redis.Tx) error {
+		gameJSON, err := tx.Get(ctx, gameKey).Result()
+		if err == redis.Nil {
+			return ErrGameNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		gameState, err = decodeMinesGame(gameJSON)
+		if err != nil {
+			return err
+		}
+		if err := update(gameState); err != nil {
+			return err
+		}
+		gameState.Version++
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, gameKey, encodeMinesGame(gameState), 1*time.Hour)
+			if queue != nil {
+				queue(pipe, gameState)
+			}
+			return nil
+		})
+		return err
+	}
+
+	for attempt := 0; attempt <= MINES_UPDATE_RETRIES; attempt++ {
+		err := m.redisClient.Watch(ctx, txf, gameKey)
+		if err != redis.TxFailedErr {
+			if err != nil {
+				return nil, err
+			}
+			return gameState, nil
 		}
 	}
 
-	return positions
+	return nil, ErrGameStateConflict
+}
+
+func encodeMinesGame(gameState *MinesGameState) string {
+	stored := minesStoredGame{
+		MinesGameState: *gameState,
+		ServerSeed:     gameState.ServerSeed,
+		MinePositions:  gameState.MinePositions,
+	}
+	gameJSON, _ := json.Marshal(stored)
+	return string(gameJSON)
+}
+
+func decodeMinesGame(gameJSON string) (*MinesGameState, error) {
+	var stored minesStoredGame
+	if err := json.Unmarshal([]byte(gameJSON), &stored); err != nil {
+		return nil, err
+	}
+
+	gameState := stored.MinesGameState
+	gameState.ServerSeed = stored.ServerSeed
+	gameState.MinePositions = stored.MinePositions
+	if gameState.GridSize == 0 {
+		gameState.GridSize = MINES_GRID_SIZE // Games created before grid sizes existed
+	}
+	if gameState.GridShape == (MinesGridShape{}) {
+		gameState.GridShape = squareMinesGridShape(gameState.GridSize) // Games created before grid shapes existed
+	}
+
+	return &gameState, nil
+}
+
+// minesErrorMessage converts a failed state update into a message for the player
+func minesErrorMessage(err error) string {
+	var rejection minesRejection
+	switch {
+	case errors.As(err, &rejection):
+		return string(rejection)
+	case errors.Is(err, ErrGameNotFound):
+		return "Game not found"
+	case errors.Is(err, ErrNotGameOwner):
+		return "Game does not belong to user"
+	case errors.Is(err, ErrGameStateConflict):
+		return "Game is busy, please try again"
+	default:
+		log.Printf("[MINES] State update failed: %v", err)
+		return "Failed to update game"
+	}
+}
+
+func (m *MinesEngine) broadcastGameUpdate(gameState *MinesGameState) {
+	if m.hub == nil {
+		return
+	}
+
+	m.hub.BroadcastToGame(gameState.GameID, map[string]interface{}{
+		"type":           "mines_update",
+		"game_id":        gameState.GameID,
+		"revealed_tiles": gameState.RevealedTiles,
+		"current_payout": gameState.CurrentPayout,
+		"status":         gameState.Status,
+	})
+}
+
+// generateMinePositions generates mine positions using provably fair algorithm
+func (m *MinesEngine) generateMinePositions(serverSeed, clientSeed string, nonce, mineCount, gridSize int) []int {
+	// Fisher-Yates keeps every placement equally likely however many mines there are
+	return FairShuffle(FairSeed(serverSeed, clientSeed, nonce), gridSize)[:mineCount]
 }
 
 // calculatePayout calculates the current payout based on revealed tiles
-func (m *MinesEngine) calculatePayout(betAmount float64, mineCount, revealedCount int) float64 {
+func (m *MinesEngine) calculatePayout(betAmount Amount, mineCount, revealedCount, gridSize int) Amount {
+	return m.payoutWithEdge(betAmount, mineCount, revealedCount, gridSize, MINES_HOUSE_EDGE)
+}
+
+// payoutWithEdge calculates the current payout for a game started under a scheduled house edge
+func (m *MinesEngine) payoutWithEdge(betAmount Amount, mineCount, revealedCount, gridSize int, edge float64) Amount {
 	if revealedCount == 0 {
 		return betAmount
 	}
 
 	// Calculate multiplier based on probability
 	// Formula: multiplier = (totalTiles / safeTiles) ^ revealedCount * houseEdge
-	totalTiles := float64(MINES_GRID_SIZE)
+	totalTiles := float64(gridSize)
 	safeTiles := totalTiles - float64(mineCount)
-	houseEdge := 0.97 // 3% house edge
+	houseEdge := 1.0 - edge
 
 	multiplier := 1.0
 	for i := 0; i < revealedCount; i++ {
@@ -410,6 +1085,5 @@ func (m *MinesEngine) calculatePayout(betAmount float64, mineCount, revealedCoun
 
 	multiplier *= houseEdge
 
-	payout := betAmount * multiplier
-	return float64(int(payout*100)) / 100.0 // Round to 2 decimal places
+	return betAmount.Mul(multiplier)
 }
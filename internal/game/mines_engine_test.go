@@ -1,21 +1,29 @@
 package game
 
 import (
+	"context"
+	"math"
+	"slices"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
 )
 
 func TestMinesEngine_GenerateMinePositions(t *testing.T) {
 	engine := &MinesEngine{}
 
 	t.Run("generates correct number of mines", func(t *testing.T) {
-		positions := engine.generateMinePositions("seed1", "seed2", 1, 5)
+		positions := engine.generateMinePositions("seed1", "seed2", 1, 5, MINES_GRID_SIZE)
 		if len(positions) != 5 {
 			t.Errorf("expected 5 positions, got %d", len(positions))
 		}
 	})
 
 	t.Run("generates unique positions", func(t *testing.T) {
-		positions := engine.generateMinePositions("seed1", "seed2", 1, 10)
+		positions := engine.generateMinePositions("seed1", "seed2", 1, 10, MINES_GRID_SIZE)
 		uniqueMap := make(map[int]bool)
 		for _, pos := range positions {
 			uniqueMap[pos] = true
@@ -26,7 +34,7 @@ func TestMinesEngine_GenerateMinePositions(t *testing.T) {
 	})
 
 	t.Run("positions within grid bounds", func(t *testing.T) {
-		positions := engine.generateMinePositions("seed1", "seed2", 1, 15)
+		positions := engine.generateMinePositions("seed1", "seed2", 1, 15, MINES_GRID_SIZE)
 		for _, pos := range positions {
 			if pos < 0 || pos >= MINES_GRID_SIZE {
 				t.Errorf("position %d out of bounds [0, %d)", pos, MINES_GRID_SIZE)
@@ -35,8 +43,8 @@ func TestMinesEngine_GenerateMinePositions(t *testing.T) {
 	})
 
 	t.Run("deterministic generation", func(t *testing.T) {
-		positions1 := engine.generateMinePositions("seed1", "seed2", 1, 5)
-		positions2 := engine.generateMinePositions("seed1", "seed2", 1, 5)
+		positions1 := engine.generateMinePositions("seed1", "seed2", 1, 5, MINES_GRID_SIZE)
+		positions2 := engine.generateMinePositions("seed1", "seed2", 1, 5, MINES_GRID_SIZE)
 		
 		if len(positions1) != len(positions2) {
 			t.Error("positions should be deterministic")
@@ -55,12 +63,12 @@ func TestMinesEngine_CalculatePayout(t *testing.T) {
 	engine := &MinesEngine{}
 
 	t.Run("payout increases with revealed tiles", func(t *testing.T) {
-		payout0 := engine.calculatePayout(100.0, 3, 0)
-		payout1 := engine.calculatePayout(100.0, 3, 1)
-		payout2 := engine.calculatePayout(100.0, 3, 2)
+		payout0 := engine.calculatePayout(amountOf(100.0), 3, 0, MINES_GRID_SIZE)
+		payout1 := engine.calculatePayout(amountOf(100.0), 3, 1, MINES_GRID_SIZE)
+		payout2 := engine.calculatePayout(amountOf(100.0), 3, 2, MINES_GRID_SIZE)
 
-		if payout0 != 100.0 {
-			t.Errorf("expected initial payout 100.0, got %.2f", payout0)
+		if payout0 != amountOf(100.0) {
+			t.Errorf("expected initial payout 100.00, got %s", payout0)
 		}
 		if payout1 <= payout0 {
 			t.Error("payout should increase with revealed tiles")
@@ -71,8 +79,8 @@ func TestMinesEngine_CalculatePayout(t *testing.T) {
 	})
 
 	t.Run("higher mine count increases multiplier", func(t *testing.T) {
-		payout3Mines := engine.calculatePayout(100.0, 3, 5)
-		payout10Mines := engine.calculatePayout(100.0, 10, 5)
+		payout3Mines := engine.calculatePayout(amountOf(100.0), 3, 5, MINES_GRID_SIZE)
+		payout10Mines := engine.calculatePayout(amountOf(100.0), 10, 5, MINES_GRID_SIZE)
 
 		if payout10Mines <= payout3Mines {
 			t.Error("higher mine count should result in higher payout")
@@ -80,9 +88,69 @@ func TestMinesEngine_CalculatePayout(t *testing.T) {
 	})
 
 	t.Run("zero revealed tiles returns bet amount", func(t *testing.T) {
-		payout := engine.calculatePayout(250.0, 5, 0)
-		if payout != 250.0 {
-			t.Errorf("expected 250.0, got %.2f", payout)
+		payout := engine.calculatePayout(amountOf(250.0), 5, 0, MINES_GRID_SIZE)
+		if payout != amountOf(250.0) {
+			t.Errorf("expected 250.00, got %s", payout)
+		}
+	})
+}
+
+func TestMinesEngine_GridSizes(t *testing.T) {
+	engine := &MinesEngine{}
+
+	gridSizes := []struct {
+		name     string
+		gridSize int
+	}{
+		{"3x3", 9},
+		{"4x4", 16},
+		{"5x5", 25},
+	}
+
+	for _, tt := range gridSizes {
+		t.Run(tt.name, func(t *testing.T) {
+			if !minesGridSizes[tt.gridSize] {
+				t.Fatalf("grid size %d should be supported", tt.gridSize)
+			}
+			if got := minesMaxCount(tt.gridSize); got != tt.gridSize-1 {
+				t.Errorf("minesMaxCount(%d) = %d, want %d", tt.gridSize, got, tt.gridSize-1)
+			}
+
+			for mineCount := MINES_MIN_COUNT; mineCount <= minesMaxCount(tt.gridSize); mineCount++ {
+				for nonce := 1; nonce <= 20; nonce++ {
+					positions := engine.generateMinePositions("seed1", "seed2", nonce, mineCount, tt.gridSize)
+					if len(positions) > mineCount {
+						t.Fatalf("generated %d positions for %d mines", len(positions), mineCount)
+					}
+					for _, pos := range positions {
+						if pos < 0 || pos >= tt.gridSize {
+							t.Fatalf("position %d out of bounds [0, %d)", pos, tt.gridSize)
+						}
+					}
+				}
+			}
+		})
+	}
+
+	t.Run("unsupported grid size", func(t *testing.T) {
+		resp, _ := engine.PlaceBet(context.Background(), MinesBetRequest{UserID: "u", Amount: 10, MineCount: 3, GridSize: 36})
+		if resp.(MinesBetResponse).Success {
+			t.Error("6x6 grid should be rejected")
+		}
+	})
+
+	t.Run("mine count above grid maximum", func(t *testing.T) {
+		resp, _ := engine.PlaceBet(context.Background(), MinesBetRequest{UserID: "u", Amount: 10, MineCount: 9, GridSize: 9})
+		if resp.(MinesBetResponse).Success {
+			t.Error("9 mines on a 3x3 grid should be rejected")
+		}
+	})
+
+	t.Run("smaller grid pays more per tile", func(t *testing.T) {
+		payout3x3 := engine.calculatePayout(amountOf(100.0), 2, 1, 9)
+		payout5x5 := engine.calculatePayout(amountOf(100.0), 2, 1, 25)
+		if payout3x3 <= payout5x5 {
+			t.Errorf("3x3 payout %s should exceed 5x5 payout %s for same mine count", payout3x3, payout5x5)
 		}
 	})
 }
@@ -94,3 +162,377 @@ func TestMinesEngine_GetType(t *testing.T) {
 		t.Errorf("expected GameTypeMines, got %v", engine.GetType())
 	}
 }
+
+// newTestRedis returns a client for a miniredis server closed when tb ends.
+// Tests outside the game package use testutil instead.
+func newTestRedis(tb testing.TB) *redis.Client {
+	tb.Helper()
+
+	mr := miniredis.RunT(tb)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	tb.Cleanup(func() { client.Close() })
+	return client
+}
+
+func newTestMinesEngine(t *testing.T) (*MinesEngine, *redis.Client) {
+	t.Helper()
+
+	client := newTestRedis(t)
+	client.Set(context.Background(), REDIS_KEY_USER_BALANCE+"user1", 1000.0, 0)
+	return NewMinesEngine(client, nil), client
+}
+
+func TestMinesEngine_ConcurrentClicks(t *testing.T) {
+	engine, _ := newTestMinesEngine(t)
+	ctx := context.Background()
+
+	resp, _ := engine.PlaceBet(ctx, MinesBetRequest{UserID: "user1", Amount: 10, MineCount: 1})
+	gameID := resp.(MinesBetResponse).GameID
+	gameState, err := engine.loadGame(ctx, gameID)
+	if err != nil {
+		t.Fatalf("failed to load game: %v", err)
+	}
+
+	// Ten safe tiles, each clicked twice at the same time
+	var safeTiles []int
+	for tile := 0; len(safeTiles) < 10; tile++ {
+		if tile != gameState.MinePositions[0] {
+			safeTiles = append(safeTiles, tile)
+		}
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded := make(map[int]int)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(tileID int) {
+			defer wg.Done()
+			resp, _ := engine.ProcessAction(ctx, "click", MinesClickRequest{UserID: "user1", GameID: gameID, TileID: tileID})
+			if resp.(MinesClickResponse).Success {
+				mu.Lock()
+				succeeded[tileID]++
+				mu.Unlock()
+			}
+		}(safeTiles[i%len(safeTiles)])
+	}
+	wg.Wait()
+
+	final, err := engine.loadGame(ctx, gameID)
+	if err != nil {
+		t.Fatalf("failed to load game: %v", err)
+	}
+
+	seen := make(map[int]bool)
+	for _, tile := range final.RevealedTiles {
+		if seen[tile] {
+			t.Errorf("tile %d revealed twice", tile)
+		}
+		seen[tile] = true
+	}
+	for tile, count := range succeeded {
+		if count > 1 {
+			t.Errorf("tile %d was clicked successfully %d times", tile, count)
+		}
+		if !seen[tile] {
+			t.Errorf("successful click on tile %d is missing from the game state", tile)
+		}
+	}
+	if len(final.RevealedTiles) != len(succeeded) {
+		t.Errorf("revealed %d tiles but %d clicks succeeded", len(final.RevealedTiles), len(succeeded))
+	}
+	if final.Version != len(final.RevealedTiles) {
+		t.Errorf("version = %d, want one update per revealed tile (%d)", final.Version, len(final.RevealedTiles))
+	}
+
+	want := engine.calculatePayout(amountOf(10), 1, len(final.RevealedTiles), MINES_GRID_SIZE)
+	if final.CurrentPayout != want {
+		t.Errorf("payout = %s, want %s for %d tiles", final.CurrentPayout, want, len(final.RevealedTiles))
+	}
+}
+
+func TestMinesEngine_AutoComplete(t *testing.T) {
+	engine, client := newTestMinesEngine(t)
+	engine.autoCompleteDelay = time.Millisecond
+	ctx := context.Background()
+
+	startGame := func(t *testing.T) *MinesGameState {
+		t.Helper()
+		resp, _ := engine.PlaceBet(ctx, MinesBetRequest{UserID: "user1", Amount: 10, MineCount: 5})
+		gameState, err := engine.loadGame(ctx, resp.(MinesBetResponse).GameID)
+		if err != nil {
+			t.Fatalf("failed to load game: %v", err)
+		}
+		return gameState
+	}
+
+	autoComplete := func(userID, gameID string) MinesAutoCompleteResponse {
+		resp, err := engine.ProcessAction(ctx, "auto_complete", MinesAutoCompleteRequest{UserID: userID, GameID: gameID})
+		if err != nil {
+			t.Fatalf("auto_complete returned error: %v", err)
+		}
+		return resp.(MinesAutoCompleteResponse)
+	}
+
+	t.Run("requires a revealed tile", func(t *testing.T) {
+		gameState := startGame(t)
+		if resp := autoComplete("user1", gameState.GameID); resp.Success {
+			t.Error("expected auto-complete without revealed tiles to fail")
+		}
+	})
+
+	t.Run("only the owner can auto-complete", func(t *testing.T) {
+		gameState := startGame(t)
+		if resp := autoComplete("user2", gameState.GameID); resp.Success {
+			t.Error("expected auto-complete by another user to fail")
+		}
+	})
+
+	t.Run("reveals every safe tile and cashes out", func(t *testing.T) {
+		gameState := startGame(t)
+		mines := make(map[int]bool)
+		for _, pos := range gameState.MinePositions {
+			mines[pos] = true
+		}
+
+		firstSafe := 0
+		for mines[firstSafe] {
+			firstSafe++
+		}
+		engine.ProcessAction(ctx, "click", MinesClickRequest{UserID: "user1", GameID: gameState.GameID, TileID: firstSafe})
+		before, _ := client.Get(ctx, REDIS_KEY_USER_BALANCE+"user1").Float64()
+
+		resp := autoComplete("user1", gameState.GameID)
+		if !resp.Success {
+			t.Fatalf("auto-complete failed: %s", resp.Message)
+		}
+
+		for _, click := range resp.TilesRevealed {
+			if mines[click.TileID] || click.IsMine {
+				t.Errorf("auto-complete revealed mine at tile %d", click.TileID)
+			}
+			if click.TileID == firstSafe {
+				t.Errorf("auto-complete revealed tile %d twice", firstSafe)
+			}
+		}
+
+		safeTiles := MINES_GRID_SIZE - len(gameState.MinePositions)
+		if len(resp.TilesRevealed) != safeTiles-1 {
+			t.Errorf("revealed %d tiles, want %d", len(resp.TilesRevealed), safeTiles-1)
+		}
+
+		gross := engine.calculatePayout(amountOf(10), 5, safeTiles, MINES_GRID_SIZE)
+		wantFee := gross.Mul(MINES_AUTO_COMPLETE_FEE)
+		if resp.Fee != wantFee || resp.FinalPayout != gross-wantFee {
+			t.Errorf("payout %s fee %s, want %s fee %s", resp.FinalPayout, resp.Fee, gross-wantFee, wantFee)
+		}
+		if math.Abs(resp.Balance-(before+resp.FinalPayout.Float64())) > 1e-9 {
+			t.Errorf("balance = %.2f, want %.2f", resp.Balance, before+resp.FinalPayout.Float64())
+		}
+
+		final, _ := engine.loadGame(ctx, gameState.GameID)
+		if final.Status != "CASHED_OUT" {
+			t.Errorf("status = %s, want CASHED_OUT", final.Status)
+		}
+	})
+}
+
+func TestMinesEngine_ClickPanic(t *testing.T) {
+	t.Run("active game is refunded", func(t *testing.T) {
+		engine, client := newTestMinesEngine(t)
+		ctx := context.Background()
+
+		resp, _ := engine.PlaceBet(ctx, MinesBetRequest{UserID: "user1", Amount: 10, MineCount: 3})
+		gameID := resp.(MinesBetResponse).GameID
+
+		aborted := engine.abortGame(ctx, gameID)
+		if aborted.Success || aborted.GameStatus != "ABORTED" {
+			t.Errorf("abort = %+v, want the game aborted", aborted)
+		}
+		if balance, _ := client.Get(ctx, REDIS_KEY_USER_BALANCE+"user1").Float64(); balance != 1000.0 {
+			t.Errorf("balance = %.2f, want the 10.00 bet refunded", balance)
+		}
+		if final, _ := engine.loadGame(ctx, gameID); final.Status != "ABORTED" {
+			t.Errorf("status = %s, want ABORTED", final.Status)
+		}
+	})
+
+	t.Run("busted game keeps its result", func(t *testing.T) {
+		engine, client := newTestMinesEngine(t)
+		ctx := context.Background()
+
+		resp, _ := engine.PlaceBet(ctx, MinesBetRequest{UserID: "user1", Amount: 10, MineCount: 3})
+		gameID := resp.(MinesBetResponse).GameID
+		gameState, _ := engine.loadGame(ctx, gameID)
+
+		// A detector without a Redis client panics while recording the loss
+		engine.anomaly = &AnomalyDetector{}
+
+		result, err := engine.ProcessAction(ctx, "click", MinesClickRequest{UserID: "user1", GameID: gameID, TileID: gameState.MinePositions[0]})
+		if err != nil {
+			t.Fatalf("click returned error: %v", err)
+		}
+		if click := result.(MinesClickResponse); click.Success || click.GameStatus != "BUSTED" {
+			t.Errorf("click = %+v, want the bust kept", click)
+		}
+		if balance, _ := client.Get(ctx, REDIS_KEY_USER_BALANCE+"user1").Float64(); balance != 990.0 {
+			t.Errorf("balance = %.2f, want the 10.00 bet lost", balance)
+		}
+		if final, _ := engine.loadGame(ctx, gameID); final.Status != "BUSTED" {
+			t.Errorf("status = %s, want BUSTED", final.Status)
+		}
+	})
+
+	t.Run("forfeited game is not refunded again", func(t *testing.T) {
+		engine, client := newTestMinesEngine(t)
+		ctx := context.Background()
+
+		resp, _ := engine.PlaceBet(ctx, MinesBetRequest{UserID: "user1", Amount: 10, MineCount: 3})
+		gameID := resp.(MinesBetResponse).GameID
+		engine.ProcessAction(ctx, "forfeit", MinesForfeitRequest{UserID: "user1", GameID: gameID})
+		before, _ := client.Get(ctx, REDIS_KEY_USER_BALANCE+"user1").Float64()
+
+		if aborted := engine.abortGame(ctx, gameID); aborted.GameStatus != "FORFEITED" {
+			t.Errorf("abort = %+v, want the forfeit kept", aborted)
+		}
+		if balance, _ := client.Get(ctx, REDIS_KEY_USER_BALANCE+"user1").Float64(); balance != before {
+			t.Errorf("balance = %.2f, want %.2f", balance, before)
+		}
+	})
+}
+
+func TestMinesEngine_OtherUserCannotPlayGame(t *testing.T) {
+	engine, client := newTestMinesEngine(t)
+	ctx := context.Background()
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"user2", 1000.0, 0)
+
+	resp, _ := engine.PlaceBet(ctx, MinesBetRequest{UserID: "user1", Amount: 10, MineCount: 1})
+	gameID := resp.(MinesBetResponse).GameID
+	gameState, _ := engine.loadGame(ctx, gameID)
+	safeTile := (gameState.MinePositions[0] + 1) % MINES_GRID_SIZE
+
+	click, _ := engine.ProcessAction(ctx, "click", MinesClickRequest{UserID: "user2", GameID: gameID, TileID: safeTile})
+	if result := click.(MinesClickResponse); result.Success || result.Message != "Game does not belong to user" {
+		t.Errorf("click by another user = %+v, want a rejection", result)
+	}
+
+	engine.ProcessAction(ctx, "click", MinesClickRequest{UserID: "user1", GameID: gameID, TileID: safeTile})
+	cashout, _ := engine.ProcessAction(ctx, "cashout", MinesCashoutRequest{UserID: "user2", GameID: gameID})
+	if result := cashout.(MinesCashoutResponse); result.Success || result.Message != "Game does not belong to user" {
+		t.Errorf("cashout by another user = %+v, want a rejection", result)
+	}
+	if balance, _ := client.Get(ctx, REDIS_KEY_USER_BALANCE+"user2").Float64(); balance != 1000.0 {
+		t.Errorf("other user's balance = %.2f, want 1000.00", balance)
+	}
+
+	final, _ := engine.loadGame(ctx, gameID)
+	if final.Status != "ACTIVE" || len(final.RevealedTiles) != 1 {
+		t.Errorf("game = %+v, want it still active with the owner's tile revealed", final)
+	}
+	cashout, _ = engine.ProcessAction(ctx, "cashout", MinesCashoutRequest{UserID: "user1", GameID: gameID})
+	if result := cashout.(MinesCashoutResponse); !result.Success || result.Balance != 990.0+result.Payout.Float64() {
+		t.Errorf("owner cashout = %+v, want the payout credited to the owner", result)
+	}
+}
+
+func TestMinesEngine_ActiveGame(t *testing.T) {
+	engine, client := newTestMinesEngine(t)
+	ctx := context.Background()
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"user2", 1000.0, 0)
+
+	playMinesGame(t, engine, "user1", 3, 2, false) // Cashed out
+	resp, _ := engine.PlaceBet(ctx, MinesBetRequest{UserID: "user1", Amount: 10, MineCount: 3})
+	activeID := resp.(MinesBetResponse).GameID
+	engine.PlaceBet(ctx, MinesBetRequest{UserID: "user2", Amount: 10, MineCount: 3})
+
+	active, err := engine.ProcessAction(ctx, "active_game", MinesActiveGameRequest{UserID: "user1"})
+	if err != nil {
+		t.Fatalf("active_game error: %v", err)
+	}
+	if gameState, ok := active.(*MinesGameState); !ok || gameState.GameID != activeID {
+		t.Errorf("active_game = %+v, want game %s", active, activeID)
+	}
+
+	none, err := engine.ProcessAction(ctx, "active_game", MinesActiveGameRequest{UserID: "user3"})
+	if err != nil || none != nil {
+		t.Errorf("active_game for a user without a game = %+v, %v; want nil", none, err)
+	}
+}
+
+func TestMinesEngine_History(t *testing.T) {
+	engine, _ := newTestMinesEngine(t)
+	ctx := context.Background()
+
+	playMinesGame(t, engine, "user1", 3, 0, true)  // Busted
+	playMinesGame(t, engine, "user1", 2, 2, false) // Cashed out
+	engine.PlaceBet(ctx, MinesBetRequest{UserID: "user1", Amount: 10, MineCount: 4})
+
+	resp, err := engine.ProcessAction(ctx, "history", GameHistoryRequest{UserID: "user1", Page: 1, Limit: 10})
+	if err != nil {
+		t.Fatalf("history error: %v", err)
+	}
+	entries := resp.([]MinesHistoryEntry)
+	if len(entries) != 3 {
+		t.Fatalf("history = %d games, want 3", len(entries))
+	}
+
+	if entries[0].Status != "ACTIVE" || entries[0].MinePositions != nil || entries[0].ServerSeed != "" {
+		t.Errorf("active game = %+v, want no seed or mine positions", entries[0])
+	}
+	if entries[1].Status != "CASHED_OUT" || entries[1].TilesRevealed != 2 || len(entries[1].MinePositions) != 2 {
+		t.Errorf("cashed out game = %+v, want 2 tiles revealed and 2 mine positions", entries[1])
+	}
+	if entries[2].Status != "BUSTED" || entries[2].FinalPayout != 0 || len(entries[2].MinePositions) != 3 {
+		t.Errorf("busted game = %+v, want no payout and 3 mine positions", entries[2])
+	}
+	for _, entry := range entries[1:] {
+		want := engine.generateMinePositions(entry.ServerSeed, entry.ClientSeed, entry.Nonce, entry.MineCount, entry.GridSize)
+		if !slices.Equal(entry.MinePositions, want) {
+			t.Errorf("game %s: revealed seed does not reproduce its mine positions", entry.GameID)
+		}
+	}
+}
+
+func TestMinesEngine_Forfeit(t *testing.T) {
+	engine, client := newTestMinesEngine(t)
+	ctx := context.Background()
+	engine.forfeitPenaltyPct = 15
+
+	resp, _ := engine.PlaceBet(ctx, MinesBetRequest{UserID: "user1", Amount: 10.55, MineCount: 3})
+	gameID := resp.(MinesBetResponse).GameID
+
+	if other, _ := engine.ProcessAction(ctx, "forfeit", MinesForfeitRequest{UserID: "user2", GameID: gameID}); other.(MinesForfeitResponse).Success {
+		t.Error("another user forfeited the game")
+	}
+
+	forfeit, err := engine.ProcessAction(ctx, "forfeit", MinesForfeitRequest{UserID: "user1", GameID: gameID})
+	if err != nil {
+		t.Fatalf("forfeit error: %v", err)
+	}
+	// 85% of 10.55 is 8.9675, rounded down to the cent
+	result := forfeit.(MinesForfeitResponse)
+	if !result.Success || result.ForfeitRefund != amountOf(8.96) || result.PenaltyPct != 15 {
+		t.Errorf("forfeit = %+v, want a refund of 8.96 with a 15%% penalty", result)
+	}
+	if balance, _ := client.Get(ctx, REDIS_KEY_USER_BALANCE+"user1").Float64(); math.Abs(balance-998.41) > 1e-9 {
+		t.Errorf("balance after forfeit = %v, want 998.41", balance)
+	}
+	gameState, _ := engine.loadGame(ctx, gameID)
+	if gameState.Status != "FORFEITED" || gameState.EndedAt.IsZero() {
+		t.Errorf("forfeited game = %+v, want FORFEITED with an end time", gameState)
+	}
+	if again, _ := engine.ProcessAction(ctx, "forfeit", MinesForfeitRequest{UserID: "user1", GameID: gameID}); again.(MinesForfeitResponse).Success {
+		t.Error("a forfeited game was forfeited twice")
+	}
+
+	// Once a tile is revealed the player must cash out instead
+	resp, _ = engine.PlaceBet(ctx, MinesBetRequest{UserID: "user1", Amount: 10, MineCount: 1})
+	gameID = resp.(MinesBetResponse).GameID
+	gameState, _ = engine.loadGame(ctx, gameID)
+	engine.ProcessAction(ctx, "click", MinesClickRequest{UserID: "user1", GameID: gameID, TileID: (gameState.MinePositions[0] + 1) % MINES_GRID_SIZE})
+
+	forfeit, _ = engine.ProcessAction(ctx, "forfeit", MinesForfeitRequest{UserID: "user1", GameID: gameID})
+	if result := forfeit.(MinesForfeitResponse); result.Success || result.Message != "Cannot forfeit after revealing tiles; use cashout instead" {
+		t.Errorf("forfeit after a reveal = %+v, want a rejection", result)
+	}
+}
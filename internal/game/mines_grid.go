@@ -0,0 +1,90 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+)
+
+// Contents of a tile on MinesGrid
+const (
+	MinesTileSafe    = "safe"
+	MinesTileMine    = "mine"
+	MinesTileUnknown = "unknown"
+)
+
+// MinesGridShape lays a game's tiles out in rows. Tile IDs run along each
+// row, so the tile at row r and column c is r*cols + c.
+type MinesGridShape struct {
+	Rows int `json:"rows"`
+	Cols int `json:"cols"`
+}
+
+// Size returns the number of tiles on the grid
+func (s MinesGridShape) Size() int {
+	return s.Rows * s.Cols
+}
+
+// squareMinesGridShape returns the square shape of a supported grid size
+func squareMinesGridShape(gridSize int) MinesGridShape {
+	side := int(math.Round(math.Sqrt(float64(gridSize))))
+	return MinesGridShape{Rows: side, Cols: side}
+}
+
+// MinesGridTile is one tile of MinesGrid. IsSafeOrUnknown is "unknown" until
+// the tile is revealed, then "safe", or "mine" for the tile that busted the game.
+type MinesGridTile struct {
+	TileID          int    `json:"tile_id"`
+	Revealed        bool   `json:"revealed"`
+	IsSafeOrUnknown string `json:"is_safe_or_unknown"`
+}
+
+// MinesGridResponse is a game's tiles in the rows of its grid shape
+type MinesGridResponse struct {
+	Grid          [][]MinesGridTile `json:"grid"`
+	MineCount     int               `json:"mine_count"`
+	RevealedCount int               `json:"revealed_count"`
+}
+
+// MinesGrid lays out the tiles of a game by its grid shape. Hidden tiles are
+// never shown as mines, even once the game has ended.
+func MinesGrid(gameState *MinesGameState) MinesGridResponse {
+	shape := gameState.GridShape
+	grid := make([][]MinesGridTile, shape.Rows)
+	for row := range grid {
+		grid[row] = make([]MinesGridTile, shape.Cols)
+		for col := range grid[row] {
+			tile := MinesGridTile{TileID: row*shape.Cols + col, IsSafeOrUnknown: MinesTileUnknown}
+			switch {
+			case slices.Contains(gameState.RevealedTiles, tile.TileID):
+				tile.Revealed = true
+				tile.IsSafeOrUnknown = MinesTileSafe
+			case gameState.BustedTile != nil && *gameState.BustedTile == tile.TileID:
+				tile.Revealed = true
+				tile.IsSafeOrUnknown = MinesTileMine
+			}
+			grid[row][col] = tile
+		}
+	}
+
+	return MinesGridResponse{
+		Grid:          grid,
+		MineCount:     gameState.MineCount,
+		RevealedCount: len(gameState.RevealedTiles),
+	}
+}
+
+// handleGrid returns the grid of a game
+func (m *MinesEngine) handleGrid(ctx context.Context, req interface{}) (interface{}, error) {
+	stateReq, ok := req.(MinesStateRequest)
+	if !ok {
+		return nil, errors.New("invalid request type")
+	}
+
+	gameState, err := m.loadGame(ctx, stateReq.GameID)
+	if err != nil {
+		return nil, err
+	}
+	return MinesGrid(gameState), nil
+}
@@ -0,0 +1,96 @@
+package game
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestValidateMinesBet_GridShape(t *testing.T) {
+	tests := []struct {
+		name     string
+		shape    MinesGridShape
+		gridSize int
+		want     int
+	}{
+		{"omitted", MinesGridShape{}, 0, MINES_GRID_SIZE},
+		{"4x4", MinesGridShape{Rows: 4, Cols: 4}, 0, 16},
+		{"2x8", MinesGridShape{Rows: 2, Cols: 8}, 16, 16},
+		{"3x4", MinesGridShape{Rows: 3, Cols: 4}, 0, 0},
+		{"size mismatch", MinesGridShape{Rows: 3, Cols: 3}, 25, 0},
+		{"no rows", MinesGridShape{Cols: 9}, 0, 0},
+	}
+
+	for _, tt := range tests {
+		req := MinesBetRequest{UserID: "user1", Amount: 10, MineCount: 3, GridSize: tt.gridSize, GridShape: tt.shape}
+		message := validateMinesBet(&req)
+		if tt.want == 0 {
+			if message == "" {
+				t.Errorf("%s: validateMinesBet() accepted %+v", tt.name, tt.shape)
+			}
+			continue
+		}
+		if message != "" || req.GridSize != tt.want || req.GridShape.Size() != tt.want {
+			t.Errorf("%s: validateMinesBet() = %q with %d tiles shaped %+v, want %d tiles", tt.name, message, req.GridSize, req.GridShape, tt.want)
+		}
+	}
+}
+
+func TestMinesEngine_Grid(t *testing.T) {
+	engine, _ := newTestMinesEngine(t)
+	ctx := context.Background()
+
+	resp, _ := engine.PlaceBet(ctx, MinesBetRequest{UserID: "user1", Amount: 10, MineCount: 3, GridShape: MinesGridShape{Rows: 4, Cols: 4}})
+	gameID := resp.(MinesBetResponse).GameID
+	gameState, _ := engine.loadGame(ctx, gameID)
+	safe := 0
+	for slices.Contains(gameState.MinePositions, safe) {
+		safe++
+	}
+
+	if click, _ := engine.ProcessAction(ctx, "click", MinesClickRequest{UserID: "user1", GameID: gameID, TileID: 16}); click.(MinesClickResponse).Success {
+		t.Error("click on tile 16 of a 4x4 grid succeeded")
+	}
+	engine.ProcessAction(ctx, "click", MinesClickRequest{UserID: "user1", GameID: gameID, TileID: safe})
+
+	result, err := engine.ProcessAction(ctx, "grid", MinesStateRequest{GameID: gameID})
+	if err != nil {
+		t.Fatalf("ProcessAction(grid) error: %v", err)
+	}
+	grid := result.(MinesGridResponse)
+	if len(grid.Grid) != 4 || grid.MineCount != 3 || grid.RevealedCount != 1 {
+		t.Fatalf("grid = %+v, want 4 rows with 3 mines and 1 revealed tile", grid)
+	}
+	for row, tiles := range grid.Grid {
+		if len(tiles) != 4 {
+			t.Fatalf("row %d has %d tiles, want 4", row, len(tiles))
+		}
+		for col, tile := range tiles {
+			want := MinesGridTile{TileID: row*4 + col, IsSafeOrUnknown: MinesTileUnknown}
+			if tile.TileID == safe {
+				want.Revealed, want.IsSafeOrUnknown = true, MinesTileSafe
+			}
+			if tile != want {
+				t.Errorf("tile at %d,%d = %+v, want %+v", row, col, tile, want)
+			}
+		}
+	}
+
+	mine := gameState.MinePositions[0]
+	engine.ProcessAction(ctx, "click", MinesClickRequest{UserID: "user1", GameID: gameID, TileID: mine})
+	result, _ = engine.ProcessAction(ctx, "grid", MinesStateRequest{GameID: gameID})
+	for _, tiles := range result.(MinesGridResponse).Grid {
+		for _, tile := range tiles {
+			if tile.TileID == mine && tile.IsSafeOrUnknown != MinesTileMine {
+				t.Errorf("busted tile = %+v, want it shown as a mine", tile)
+			}
+			if tile.TileID != mine && tile.IsSafeOrUnknown == MinesTileMine {
+				t.Errorf("hidden tile %+v shown as a mine", tile)
+			}
+		}
+	}
+
+	if _, err := engine.ProcessAction(ctx, "grid", MinesStateRequest{GameID: "MINES-unknown"}); err != ErrGameNotFound {
+		t.Errorf("grid of an unknown game: %v, want ErrGameNotFound", err)
+	}
+}
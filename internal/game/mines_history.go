@@ -0,0 +1,62 @@
+package game
+
+import (
+	"context"
+	"time"
+)
+
+// MinesHistoryEntry is one game in a player's Mines history. The seed and
+// mine positions are only included once the game is over.
+type MinesHistoryEntry struct {
+	GameID        string    `json:"game_id"`
+	GridSize      int       `json:"grid_size"`
+	MineCount     int       `json:"mine_count"`
+	TilesRevealed int       `json:"tiles_revealed"`
+	FinalPayout   Amount    `json:"final_payout"`
+	Status        string    `json:"status"`
+	CreatedAt     time.Time `json:"created_at"`
+	ClientSeed    string    `json:"client_seed"`
+	Nonce         int       `json:"nonce"`
+	MinePositions []int     `json:"mine_positions,omitempty"`
+	ServerSeed    string    `json:"server_seed,omitempty"`
+}
+
+// minesGameRevealed reports whether a game has ended in a way that makes its
+// mine positions safe to show
+func minesGameRevealed(status string) bool {
+	return status == "CASHED_OUT" || status == "BUSTED" || status == "FORFEITED"
+}
+
+// history returns a page of the user's recent Mines games, newest first.
+// Active games are listed without their seed or mine positions.
+func (m *MinesEngine) history(ctx context.Context, req GameHistoryRequest) ([]MinesHistoryEntry, error) {
+	games, err := loadGameHistory(ctx, m.redisClient, REDIS_KEY_MINES_HISTORY+req.UserID, REDIS_KEY_MINES_GAME, req.Page, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]MinesHistoryEntry, 0, len(games))
+	for _, gameJSON := range games {
+		gameState, err := decodeMinesGame(gameJSON)
+		if err != nil {
+			continue
+		}
+		entry := MinesHistoryEntry{
+			GameID:        gameState.GameID,
+			GridSize:      gameState.GridSize,
+			MineCount:     gameState.MineCount,
+			TilesRevealed: len(gameState.RevealedTiles),
+			FinalPayout:   gameState.CurrentPayout,
+			Status:        gameState.Status,
+			CreatedAt:     gameState.CreatedAt,
+			ClientSeed:    gameState.ClientSeed,
+			Nonce:         gameState.Nonce,
+		}
+		if minesGameRevealed(gameState.Status) {
+			entry.MinePositions = gameState.MinePositions
+			entry.ServerSeed = gameState.ServerSeed
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
@@ -0,0 +1,165 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	REDIS_KEY_MINES_TILES_LEADERBOARD      = "mines:tiles_leaderboard"
+	REDIS_KEY_MINES_TILES_RECORD           = "mines:tiles_record:"
+	REDIS_KEY_MINES_GLOBAL_TILES_RECORD    = "mines:global_tiles_record"
+	REDIS_KEY_MINES_MULTIPLIER_LEADERBOARD = "mines:multiplier_leaderboard"
+	REDIS_KEY_MINES_MULTIPLIER_RECORD      = "mines:multiplier_record:"
+
+	MINES_LEADERBOARD_DEFAULT_LIMIT = 10
+	MINES_LEADERBOARD_MAX_LIMIT     = 100
+)
+
+// Mines leaderboards
+const (
+	MinesLeaderboardTiles      = "tiles"
+	MinesLeaderboardMultiplier = "multiplier"
+)
+
+var ErrUnknownLeaderboard = errors.New("unknown leaderboard")
+
+// MinesLeaderboardRequest asks for the top entries of a Mines leaderboard
+type MinesLeaderboardRequest struct {
+	Board string `json:"board"`
+	Limit int    `json:"limit"`
+}
+
+// MinesLeaderboardEntry is a user's best single game on a leaderboard
+type MinesLeaderboardEntry struct {
+	UserID           string  `json:"user_id"`
+	MaxTilesRevealed int     `json:"max_tiles_revealed,omitempty"`
+	MaxMultiplier    float64 `json:"max_multiplier,omitempty"`
+	MineCount        int     `json:"mine_count_that_game"`
+	Payout           Amount  `json:"payout"`
+}
+
+// minesRecordScript raises a user's leaderboard score only if the new score
+// beats it, storing the game that set it, and tracks the global record.
+// KEYS: leaderboard, user record, global record (optional).
+// ARGV: user ID, score, mine count, payout in cents.
+// Returns {personal record set, global record set}.
+var minesRecordScript = redis.NewScript(`
+local score = tonumber(ARGV[2])
+local best = tonumber(redis.call('ZSCORE', KEYS[1], ARGV[1]))
+if best and best >= score then
+	return {0, 0}
+end
+
+redis.call('ZADD', KEYS[1], score, ARGV[1])
+redis.call('HSET', KEYS[2], 'mine_count', ARGV[3], 'payout', ARGV[4])
+
+if #KEYS < 3 then
+	return {1, 0}
+end
+local global = tonumber(redis.call('GET', KEYS[3]))
+if global and global >= score then
+	return {1, 0}
+end
+redis.call('SET', KEYS[3], ARGV[2])
+return {1, 1}
+`)
+
+// recordLeaderboards updates the tiles and multiplier leaderboards after a
+// game ends and announces a new global tiles record
+func (m *MinesEngine) recordLeaderboards(ctx context.Context, gameState *MinesGameState) {
+	tiles := len(gameState.RevealedTiles)
+	if tiles == 0 {
+		return
+	}
+
+	args := []interface{}{gameState.UserID, tiles, gameState.MineCount, int64(gameState.CurrentPayout)}
+	keys := []string{
+		REDIS_KEY_MINES_TILES_LEADERBOARD,
+		REDIS_KEY_MINES_TILES_RECORD + gameState.UserID,
+		REDIS_KEY_MINES_GLOBAL_TILES_RECORD,
+	}
+	result, err := minesRecordScript.Run(ctx, m.redisClient, keys, args...).Int64Slice()
+	if err != nil {
+		log.Printf("[MINES] Failed to update tiles leaderboard for %s: %v", gameState.UserID, err)
+	} else if result[1] == 1 {
+		log.Printf("[MINES] User %s set a global record of %d tiles", gameState.UserID, tiles)
+		if m.hub != nil {
+			m.hub.Broadcast(map[string]interface{}{
+				"type":           "global_record",
+				"game_type":      GameTypeMines,
+				"record":         MinesLeaderboardTiles,
+				"user_id":        gameState.UserID,
+				"tiles_revealed": tiles,
+				"mine_count":     gameState.MineCount,
+				"payout":         gameState.CurrentPayout,
+			})
+		}
+	}
+
+	if gameState.Status != "CASHED_OUT" {
+		return
+	}
+	args[1] = gameState.payoutMultiplier()
+	keys = []string{
+		REDIS_KEY_MINES_MULTIPLIER_LEADERBOARD,
+		REDIS_KEY_MINES_MULTIPLIER_RECORD + gameState.UserID,
+	}
+	if err := minesRecordScript.Run(ctx, m.redisClient, keys, args...).Err(); err != nil {
+		log.Printf("[MINES] Failed to update multiplier leaderboard for %s: %v", gameState.UserID, err)
+	}
+}
+
+// handleLeaderboard returns the top entries of a leaderboard, best first
+func (m *MinesEngine) handleLeaderboard(ctx context.Context, req interface{}) (interface{}, error) {
+	boardReq, ok := req.(MinesLeaderboardRequest)
+	if !ok {
+		return nil, errors.New("invalid request type")
+	}
+
+	var boardKey, recordKey string
+	switch boardReq.Board {
+	case MinesLeaderboardTiles:
+		boardKey, recordKey = REDIS_KEY_MINES_TILES_LEADERBOARD, REDIS_KEY_MINES_TILES_RECORD
+	case MinesLeaderboardMultiplier:
+		boardKey, recordKey = REDIS_KEY_MINES_MULTIPLIER_LEADERBOARD, REDIS_KEY_MINES_MULTIPLIER_RECORD
+	default:
+		return nil, ErrUnknownLeaderboard
+	}
+
+	scores, err := m.redisClient.ZRevRangeWithScores(ctx, boardKey, 0, int64(boardReq.Limit)-1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	pipe := m.redisClient.Pipeline()
+	records := make([]*redis.MapStringStringCmd, len(scores))
+	for i, z := range scores {
+		records[i] = pipe.HGetAll(ctx, recordKey+z.Member.(string))
+	}
+	if len(scores) > 0 {
+		if _, err := pipe.Exec(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	entries := make([]MinesLeaderboardEntry, 0, len(scores))
+	for i, z := range scores {
+		record := records[i].Val()
+		entry := MinesLeaderboardEntry{UserID: z.Member.(string)}
+		entry.MineCount, _ = strconv.Atoi(record["mine_count"])
+		payout, _ := strconv.ParseInt(record["payout"], 10, 64)
+		entry.Payout = Amount(payout)
+		if boardReq.Board == MinesLeaderboardTiles {
+			entry.MaxTilesRevealed = int(z.Score)
+		} else {
+			entry.MaxMultiplier = z.Score
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
@@ -0,0 +1,101 @@
+package game
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+// playMinesGame reveals tiles safe tiles, then cashes out or hits a mine
+func playMinesGame(t *testing.T, engine *MinesEngine, userID string, mineCount, tiles int, bust bool) {
+	t.Helper()
+	ctx := context.Background()
+
+	resp, _ := engine.PlaceBet(ctx, MinesBetRequest{UserID: userID, Amount: 10, MineCount: mineCount})
+	gameState, err := engine.loadGame(ctx, resp.(MinesBetResponse).GameID)
+	if err != nil {
+		t.Fatalf("failed to load game: %v", err)
+	}
+	mines := make(map[int]bool)
+	for _, pos := range gameState.MinePositions {
+		mines[pos] = true
+	}
+
+	click := func(tile int) {
+		engine.ProcessAction(ctx, "click", MinesClickRequest{UserID: userID, GameID: gameState.GameID, TileID: tile})
+	}
+	for tile, revealed := 0, 0; revealed < tiles; tile++ {
+		if !mines[tile] {
+			click(tile)
+			revealed++
+		}
+	}
+	if bust {
+		click(gameState.MinePositions[0])
+		return
+	}
+	engine.ProcessAction(ctx, "cashout", MinesCashoutRequest{UserID: userID, GameID: gameState.GameID})
+}
+
+func minesLeaderboard(t *testing.T, engine *MinesEngine, board string) []MinesLeaderboardEntry {
+	t.Helper()
+	resp, err := engine.ProcessAction(context.Background(), "leaderboard", MinesLeaderboardRequest{Board: board, Limit: 10})
+	if err != nil {
+		t.Fatalf("leaderboard returned error: %v", err)
+	}
+	return resp.([]MinesLeaderboardEntry)
+}
+
+func TestMinesEngine_TilesLeaderboard(t *testing.T) {
+	engine, client := newTestMinesEngine(t)
+	hub := NewHub()
+	engine.hub = hub
+	client.Set(context.Background(), REDIS_KEY_USER_BALANCE+"user2", 1000.0, 0)
+
+	playMinesGame(t, engine, "user1", 3, 5, false)
+	playMinesGame(t, engine, "user1", 1, 2, false) // Fewer tiles keep the earlier record
+	playMinesGame(t, engine, "user2", 10, 7, true)
+
+	entries := minesLeaderboard(t, engine, MinesLeaderboardTiles)
+	if len(entries) != 2 {
+		t.Fatalf("leaderboard has %d entries, want 2", len(entries))
+	}
+	if e := entries[0]; e.UserID != "user2" || e.MaxTilesRevealed != 7 || e.MineCount != 10 || e.Payout != 0 {
+		t.Errorf("first entry = %+v, want user2's busted 7 tile game", e)
+	}
+	want := engine.calculatePayout(amountOf(10), 3, 5, MINES_GRID_SIZE)
+	if e := entries[1]; e.UserID != "user1" || e.MaxTilesRevealed != 5 || e.MineCount != 3 || e.Payout != want {
+		t.Errorf("second entry = %+v, want user1's 5 tile game paying %s", e, want)
+	}
+
+	records := 0
+	for len(hub.broadcast) > 0 {
+		if msg, ok := (<-hub.broadcast).(map[string]interface{}); ok && msg["type"] == "global_record" {
+			records++
+		}
+	}
+	if records != 2 {
+		t.Errorf("broadcast %d global records, want 2 (user1's 5 tiles, then user2's 7)", records)
+	}
+
+	if _, err := engine.ProcessAction(context.Background(), "leaderboard", MinesLeaderboardRequest{Board: "profit", Limit: 10}); err != ErrUnknownLeaderboard {
+		t.Errorf("unknown board error = %v, want %v", err, ErrUnknownLeaderboard)
+	}
+}
+
+func TestMinesEngine_MultiplierLeaderboard(t *testing.T) {
+	engine, _ := newTestMinesEngine(t)
+
+	playMinesGame(t, engine, "user1", 5, 3, false)
+	playMinesGame(t, engine, "user1", 20, 3, true) // Busted games have no multiplier
+
+	entries := minesLeaderboard(t, engine, MinesLeaderboardMultiplier)
+	if len(entries) != 1 {
+		t.Fatalf("leaderboard has %d entries, want 1", len(entries))
+	}
+	payout := engine.calculatePayout(amountOf(10), 5, 3, MINES_GRID_SIZE)
+	multiplier := math.Round(float64(payout)/float64(amountOf(10))*100) / 100
+	if e := entries[0]; e.MaxMultiplier != multiplier || e.MineCount != 5 || e.Payout != payout {
+		t.Errorf("entry = %+v, want the 5 mine game paying %s", e, payout)
+	}
+}
@@ -0,0 +1,226 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"aviator/internal/notifications"
+)
+
+const MINES_MULTI_GAME_MAX_GAMES = 10
+
+// MinesMultiGame is one game of a multi-game request, played on the default grid
+type MinesMultiGame struct {
+	Amount        float64 `json:"amount"`
+	MineCount     int     `json:"mine_count"`
+	TilesToReveal int     `json:"tiles_to_reveal"`
+}
+
+// MinesMultiGameRequest plays several Mines games back to back in one request
+type MinesMultiGameRequest struct {
+	UserID string           `json:"user_id"`
+	Games  []MinesMultiGame `json:"games"`
+}
+
+type MinesMultiGameResult struct {
+	GameID   string `json:"game_id"`
+	Revealed []int  `json:"revealed"` // In order; the last is the mine when busted
+	Busted   bool   `json:"busted"`
+	Payout   Amount `json:"payout"`
+}
+
+type MinesMultiGameResponse struct {
+	Success      bool                   `json:"success"`
+	Message      string                 `json:"message"`
+	Results      []MinesMultiGameResult `json:"results,omitempty"`
+	TotalPayout  Amount                 `json:"total_payout"`
+	TotalWagered Amount                 `json:"total_wagered"`
+	Balance      float64                `json:"balance"`
+	Currency
+
+	ResultSignature string `json:"result_signature,omitempty"` // See SignResponse
+}
+
+// validateMultiGame checks every game of a multi-game request and returns the
+// total wagered, or a message for the player
+func validateMultiGame(games []MinesMultiGame) (Amount, string) {
+	if len(games) < 1 || len(games) > MINES_MULTI_GAME_MAX_GAMES {
+		return 0, fmt.Sprintf("Play between 1 and %d games", MINES_MULTI_GAME_MAX_GAMES)
+	}
+
+	var total Amount
+	for i, g := range games {
+		betReq := MinesBetRequest{Amount: g.Amount, MineCount: g.MineCount}
+		if message := validateMinesBet(&betReq); message != "" {
+			return 0, fmt.Sprintf("Game %d: %s", i+1, message)
+		}
+		if safeTiles := betReq.GridSize - g.MineCount; g.TilesToReveal < 1 || g.TilesToReveal > safeTiles {
+			return 0, fmt.Sprintf("Game %d: Tiles to reveal must be between 1 and %d", i+1, safeTiles)
+		}
+		amount, _ := NewAmount(g.Amount) // Precision checked by validateMinesBet
+		total = total.Add(amount)
+	}
+	return total, ""
+}
+
+// multiGameTiles returns the tiles a multi-game reveals: the first n of a
+// shuffle of the grid drawn from the game's seeds, independent of where the
+// mines are, so the tiles can be checked like the mines
+func multiGameTiles(serverSeed, clientSeed string, nonce, gridSize, n int) []int {
+	return FairShuffle(FairSeed(serverSeed, clientSeed+":tiles", nonce), gridSize)[:n]
+}
+
+// handleMultiGame plays up to MINES_MULTI_GAME_MAX_GAMES games in a row. All
+// bets are taken up front and all payouts credited together at the end.
+func (m *MinesEngine) handleMultiGame(ctx context.Context, req interface{}) (interface{}, error) {
+	multiReq, ok := req.(MinesMultiGameRequest)
+	if !ok {
+		return nil, errors.New("invalid request type")
+	}
+
+	totalWagered, message := validateMultiGame(multiReq.Games)
+	if message != "" {
+		return MinesMultiGameResponse{
+			Success: false,
+			Message: message,
+		}, nil
+	}
+
+	if err := checkMaintenance(ctx, m.redisClient, GameTypeMines); err != nil {
+		return nil, err
+	}
+
+	// Each game counts as active until playMultiGame settles it
+	if err := m.sessions.acquire(ctx, len(multiReq.Games)); err != nil {
+		return nil, err
+	}
+
+	balanceKey := REDIS_KEY_USER_BALANCE + multiReq.UserID
+	balance, err := m.redisClient.Get(ctx, balanceKey).Float64()
+	if err != nil || balance < totalWagered.Float64() {
+		m.sessions.release(ctx, len(multiReq.Games))
+		return MinesMultiGameResponse{
+			Success: false,
+			Message: "Insufficient balance",
+			Balance: balance,
+		}, nil
+	}
+
+	newBalance, err := m.redisClient.IncrByFloat(ctx, balanceKey, -totalWagered.Float64()).Result()
+	if err != nil || newBalance < 0 {
+		m.redisClient.IncrByFloat(ctx, balanceKey, totalWagered.Float64()) // Rollback
+		m.sessions.release(ctx, len(multiReq.Games))
+		return MinesMultiGameResponse{
+			Success: false,
+			Message: "Transaction failed",
+		}, nil
+	}
+	m.hub.NotifyBalance(multiReq.UserID, newBalance, -totalWagered, BalanceReasonBet)
+
+	resp := MinesMultiGameResponse{
+		Success:      true,
+		Results:      make([]MinesMultiGameResult, 0, len(multiReq.Games)),
+		TotalWagered: totalWagered,
+		Balance:      newBalance,
+	}
+	for i, g := range multiReq.Games {
+		gameState := m.playMultiGame(ctx, multiReq.UserID, i, g)
+		resp.Results = append(resp.Results, MinesMultiGameResult{
+			GameID:   gameState.GameID,
+			Revealed: multiGameRevealed(gameState, g.TilesToReveal),
+			Busted:   gameState.Status == "BUSTED",
+			Payout:   gameState.CurrentPayout,
+		})
+		resp.TotalPayout = resp.TotalPayout.Add(gameState.CurrentPayout)
+	}
+
+	if resp.TotalPayout > 0 {
+		credited, err := m.redisClient.IncrByFloat(ctx, balanceKey, resp.TotalPayout.Float64()).Result()
+		if err != nil {
+			log.Printf("[MINES] Failed to credit multi-game payout %s to %s: %v", resp.TotalPayout, multiReq.UserID, err)
+			return nil, err
+		}
+		resp.Balance = credited
+		m.hub.NotifyBalance(multiReq.UserID, credited, resp.TotalPayout, BalanceReasonCashout)
+	}
+
+	log.Printf("[MINES] User %s played %d games, wagered %s, payout %s", multiReq.UserID, len(multiReq.Games), totalWagered, resp.TotalPayout)
+	resp.Message = fmt.Sprintf("Played %d games", len(resp.Results))
+	return resp, nil
+}
+
+// playMultiGame plays one game of a multi-game to the end, whose bet has
+// already been taken. A game that survives every reveal is cashed out; its
+// payout is left for the caller to credit.
+func (m *MinesEngine) playMultiGame(ctx context.Context, userID string, index int, g MinesMultiGame) *MinesGameState {
+	m.nonce++
+	serverSeed := GenerateSeed()
+	clientSeed := GenerateSeed()
+	betAmount, _ := NewAmount(g.Amount)
+	houseEdge := GetCurrentHouseEdge(GameTypeMines)
+	gameState := &MinesGameState{
+		GameID:        fmt.Sprintf("MINES-%s-%d-%d", userID, time.Now().UnixNano(), index),
+		UserID:        userID,
+		BetAmount:     betAmount,
+		MineCount:     g.MineCount,
+		GridSize:      MINES_GRID_SIZE,
+		GridShape:     squareMinesGridShape(MINES_GRID_SIZE),
+		ServerSeed:    serverSeed,
+		ClientSeed:    clientSeed,
+		Nonce:         m.nonce,
+		MinePositions: m.generateMinePositions(serverSeed, clientSeed, m.nonce, g.MineCount, MINES_GRID_SIZE),
+		RevealedTiles: []int{},
+		CurrentPayout: betAmount,
+		Status:        "ACTIVE",
+		CreatedAt:     time.Now(),
+		HouseEdge:     &houseEdge,
+	}
+	recordWager(ctx, m.redisClient, userID, betAmount)
+
+	for _, tile := range multiGameTiles(serverSeed, clientSeed, gameState.Nonce, gameState.GridSize, g.TilesToReveal) {
+		recordActivity(ctx, m.redisClient, REDIS_KEY_MINES_CLICKS)
+		if m.revealTile(gameState, tile) {
+			break
+		}
+	}
+
+	if gameState.Status == "ACTIVE" {
+		gameState.Status = "CASHED_OUT"
+		gameState.EndedAt = time.Now()
+	}
+	m.saveGame(ctx, gameState)
+	if err := recordGameHistory(ctx, m.redisClient, REDIS_KEY_MINES_HISTORY+userID, gameState.GameID, gameState.CreatedAt); err != nil {
+		log.Printf("[MINES] Failed to record history for %s: %v", userID, err)
+	}
+	m.broadcastGameUpdate(gameState)
+
+	if gameState.Status == "BUSTED" {
+		m.settleBust(ctx, gameState)
+		return gameState
+	}
+
+	m.sessions.release(ctx, 1)
+	m.recordLeaderboards(ctx, gameState)
+	notableCashout(ctx, m.redisClient, m.hub, m.notableThreshold, userID, gameState.CurrentPayout, gameState.payoutMultiplier(), GameTypeMines)
+	outcome := GameOutcome{
+		UserID:   userID,
+		GameType: GameTypeMines,
+		Wager:    gameState.BetAmount,
+		Payout:   gameState.CurrentPayout,
+	}
+	m.anomaly.Record(ctx, outcome)
+	recordHouseProfit(ctx, m.redisClient, outcome)
+	m.hub.GameSettled(notifications.EventMinesCashout, outcome)
+	m.hub.BetSettled(ctx, outcome.UserID)
+	return gameState
+}
+
+// multiGameRevealed returns the tiles a finished multi-game opened, in order,
+// including the mine that busted it
+func multiGameRevealed(gameState *MinesGameState, tilesToReveal int) []int {
+	tiles := multiGameTiles(gameState.ServerSeed, gameState.ClientSeed, gameState.Nonce, gameState.GridSize, tilesToReveal)
+	return tiles[:min(len(gameState.RevealedTiles)+1, len(tiles))]
+}
@@ -0,0 +1,116 @@
+package game
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestMinesEngine_MultiGame(t *testing.T) {
+	engine, client := newTestMinesEngine(t)
+	engine.signingKey = "signing-key"
+	ctx := context.Background()
+
+	multiGame := func(games []MinesMultiGame) MinesMultiGameResponse {
+		resp, err := engine.ProcessAction(ctx, "multi_game", MinesMultiGameRequest{UserID: "user1", Games: games})
+		if err != nil {
+			t.Fatalf("multi-game error: %v", err)
+		}
+		return resp.(MinesMultiGameResponse)
+	}
+
+	t.Run("payouts accumulate and busts pay nothing", func(t *testing.T) {
+		before, _ := client.Get(ctx, REDIS_KEY_USER_BALANCE+"user1").Float64()
+
+		// 24 mines all but certainly bust, 1 mine all but certainly survives
+		var games []MinesMultiGame
+		for i := 0; i < 5; i++ {
+			games = append(games, MinesMultiGame{Amount: 10, MineCount: 24, TilesToReveal: 1})
+			games = append(games, MinesMultiGame{Amount: 5, MineCount: 1, TilesToReveal: 2})
+		}
+		resp := multiGame(games)
+		if !resp.Success || len(resp.Results) != 10 || resp.ResultSignature == "" {
+			t.Fatalf("multi-game = %+v, want 10 signed results", resp)
+		}
+		if resp.TotalWagered != amountOf(75) {
+			t.Errorf("total wagered = %s, want 75.00", resp.TotalWagered)
+		}
+
+		var total Amount
+		busts, cashouts := 0, 0
+		nonces := make(map[int]bool)
+		for i, result := range resp.Results {
+			gameState, err := engine.loadGame(ctx, result.GameID)
+			if err != nil {
+				t.Fatalf("results[%d]: failed to load game %s: %v", i, result.GameID, err)
+			}
+			if nonces[gameState.Nonce] {
+				t.Errorf("results[%d]: nonce %d reused", i, gameState.Nonce)
+			}
+			nonces[gameState.Nonce] = true
+
+			if result.Busted {
+				busts++
+				if result.Payout != 0 || gameState.Status != "BUSTED" {
+					t.Errorf("results[%d] = %+v (%s), want a zero payout", i, result, gameState.Status)
+				}
+				if !slices.Contains(gameState.MinePositions, result.Revealed[len(result.Revealed)-1]) {
+					t.Errorf("results[%d]: last revealed tile %v is not a mine", i, result.Revealed)
+				}
+			} else {
+				cashouts++
+				want := engine.calculatePayout(amountOf(games[i].Amount), games[i].MineCount, games[i].TilesToReveal, MINES_GRID_SIZE)
+				if result.Payout != want || gameState.Status != "CASHED_OUT" || len(result.Revealed) != games[i].TilesToReveal {
+					t.Errorf("results[%d] = %+v (%s), want payout %s", i, result, gameState.Status, want)
+				}
+			}
+			total += result.Payout
+		}
+		if busts == 0 || cashouts == 0 {
+			t.Fatalf("got %d busts and %d cashouts, want both", busts, cashouts)
+		}
+
+		if resp.TotalPayout != total {
+			t.Errorf("total payout = %s, want the sum %s", resp.TotalPayout, total)
+		}
+		want := before - 75 + total.Float64()
+		if balance, _ := client.Get(ctx, REDIS_KEY_USER_BALANCE+"user1").Float64(); resp.Balance != balance || amountOf(balance) != amountOf(want) {
+			t.Errorf("balance = %v (stored %v), want %v", resp.Balance, balance, want)
+		}
+	})
+
+	t.Run("rejects invalid games without charging", func(t *testing.T) {
+		before, _ := client.Get(ctx, REDIS_KEY_USER_BALANCE+"user1").Float64()
+
+		tooMany := make([]MinesMultiGame, MINES_MULTI_GAME_MAX_GAMES+1)
+		for i := range tooMany {
+			tooMany[i] = MinesMultiGame{Amount: 1, MineCount: 3, TilesToReveal: 1}
+		}
+		for name, games := range map[string][]MinesMultiGame{
+			"no games":       nil,
+			"too many":       tooMany,
+			"too many tiles": {{Amount: 1, MineCount: 3, TilesToReveal: 23}},
+			"no tiles":       {{Amount: 1, MineCount: 3}},
+			"bad mines":      {{Amount: 1, MineCount: 25, TilesToReveal: 1}},
+			"over balance":   {{Amount: 1000, MineCount: 3, TilesToReveal: 1}, {Amount: 1000, MineCount: 3, TilesToReveal: 1}},
+		} {
+			if resp := multiGame(games); resp.Success {
+				t.Errorf("%s: multi-game succeeded", name)
+			}
+		}
+
+		if balance, _ := client.Get(ctx, REDIS_KEY_USER_BALANCE+"user1").Float64(); balance != before {
+			t.Errorf("balance = %v, want %v", balance, before)
+		}
+	})
+}
+
+func TestMultiGameTiles(t *testing.T) {
+	tiles := multiGameTiles("server", "client", 1, MINES_GRID_SIZE, 5)
+	if len(tiles) != 5 || !slices.Equal(tiles, multiGameTiles("server", "client", 1, MINES_GRID_SIZE, 5)) {
+		t.Errorf("tiles = %v, want 5 tiles, the same every time", tiles)
+	}
+	if slices.Equal(tiles, FairShuffle(FairSeed("server", "client", 1), MINES_GRID_SIZE)[:5]) {
+		t.Error("tiles follow the mine placement")
+	}
+}
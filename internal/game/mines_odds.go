@@ -0,0 +1,58 @@
+package game
+
+// OddsRow is the outcome of cashing out a 1.00 bet after revealing
+// TilesToReveal safe tiles on the default grid
+type OddsRow struct {
+	MineCount      int     `json:"mine_count"`
+	TilesToReveal  int     `json:"tiles_to_reveal"`
+	Multiplier     float64 `json:"multiplier"`
+	WinProbability float64 `json:"win_probability"`
+	SafeZonePct    float64 `json:"safe_zone_pct"` // WinProbability as a percentage
+	ExpectedValue  float64 `json:"expected_value"`
+}
+
+// MinesOddsTable lists every row of one mine count. BreakEvenTiles is the
+// fewest tiles to reveal for a payout above the bet.
+type MinesOddsTable struct {
+	MineCount      int       `json:"mine_count"`
+	BreakEvenTiles int       `json:"break_even_tiles"`
+	Rows           []OddsRow `json:"rows"`
+}
+
+// ComputeMinesOddsTable returns a row for every number of safe tiles that can
+// be revealed with mineCount mines on the default grid, paid as
+// calculatePayout pays them under houseEdge. The chance of reaching N tiles is
+// the product of (safe tiles left / tiles left) over the first N reveals.
+func ComputeMinesOddsTable(mineCount int, houseEdge float64) []OddsRow {
+	engine := &MinesEngine{}
+	const unitBet = Amount(AMOUNT_SCALE)
+
+	safeTiles := MINES_GRID_SIZE - mineCount
+	rows := make([]OddsRow, 0, max(safeTiles, 0))
+	winProbability := 1.0
+	for tiles := 1; tiles <= safeTiles; tiles++ {
+		winProbability *= float64(safeTiles-tiles+1) / float64(MINES_GRID_SIZE-tiles+1)
+		multiplier := engine.payoutWithEdge(unitBet, mineCount, tiles, MINES_GRID_SIZE, houseEdge).Float64()
+		rows = append(rows, OddsRow{
+			MineCount:      mineCount,
+			TilesToReveal:  tiles,
+			Multiplier:     multiplier,
+			WinProbability: winProbability,
+			SafeZonePct:    roundPct(winProbability * 100),
+			ExpectedValue:  roundPct(winProbability * multiplier),
+		})
+	}
+	return rows
+}
+
+// NewMinesOddsTable computes the odds table of mineCount under houseEdge
+func NewMinesOddsTable(mineCount int, houseEdge float64) MinesOddsTable {
+	table := MinesOddsTable{MineCount: mineCount, Rows: ComputeMinesOddsTable(mineCount, houseEdge)}
+	for _, row := range table.Rows {
+		if row.Multiplier > 1 {
+			table.BreakEvenTiles = row.TilesToReveal
+			break
+		}
+	}
+	return table
+}
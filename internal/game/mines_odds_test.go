@@ -0,0 +1,46 @@
+package game
+
+import (
+	"math"
+	"testing"
+)
+
+func TestComputeMinesOddsTable(t *testing.T) {
+	rows := ComputeMinesOddsTable(1, MINES_HOUSE_EDGE)
+	if len(rows) != MINES_GRID_SIZE-1 {
+		t.Fatalf("1 mine: %d rows, want %d", len(rows), MINES_GRID_SIZE-1)
+	}
+	payout := (&MinesEngine{}).calculatePayout(amountOf(1), 1, 1, MINES_GRID_SIZE)
+	if first := rows[0]; first.TilesToReveal != 1 || first.Multiplier != payout.Float64() || first.WinProbability != 24.0/25 {
+		t.Errorf("1 mine, 1 tile = %+v, want multiplier %s at 24/25", first, payout)
+	}
+	if last := rows[len(rows)-1]; math.Abs(last.WinProbability-0.04) > 1e-9 || last.SafeZonePct != 4 {
+		t.Errorf("1 mine, every safe tile = %+v, want a 4%% chance", last)
+	}
+
+	for mineCount := MINES_MIN_COUNT; mineCount <= MINES_MAX_COUNT; mineCount++ {
+		for _, row := range ComputeMinesOddsTable(mineCount, MINES_HOUSE_EDGE) {
+			if row.ExpectedValue >= 1 {
+				t.Errorf("%d mines, %d tiles: expected value %v, want below 1", mineCount, row.TilesToReveal, row.ExpectedValue)
+			}
+		}
+	}
+}
+
+func TestNewMinesOddsTable_BreakEven(t *testing.T) {
+	tests := []struct {
+		mineCount int
+		want      int
+	}{
+		{1, 1},  // 25/24 * 0.97 = 1.01
+		{24, 1}, // 25 * 0.97
+	}
+	for _, tt := range tests {
+		if got := NewMinesOddsTable(tt.mineCount, MINES_HOUSE_EDGE).BreakEvenTiles; got != tt.want {
+			t.Errorf("%d mines: break even after %d tiles, want %d", tt.mineCount, got, tt.want)
+		}
+	}
+	if got := NewMinesOddsTable(1, 0.05).BreakEvenTiles; got != 2 {
+		t.Errorf("1 mine at a 5%% edge: break even after %d tiles, want 2", got)
+	}
+}
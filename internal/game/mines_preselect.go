@@ -0,0 +1,141 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+)
+
+const MINES_PRESELECT_MAX_TILES = MINES_MAX_COUNT
+
+// MinesPreselectRequest reveals several tiles in one request, in order
+type MinesPreselectRequest struct {
+	UserID string `json:"user_id"`
+	GameID string `json:"game_id"`
+	Tiles  []int  `json:"tiles"`
+}
+
+type MinesPreselectResult struct {
+	TileID       int    `json:"tile_id"`
+	IsMine       bool   `json:"is_mine"`
+	PayoutIfSafe Amount `json:"payout_if_safe"` // Payout had the tile been safe
+}
+
+type MinesPreselectResponse struct {
+	Success     bool                   `json:"success"`
+	Message     string                 `json:"message"`
+	Results     []MinesPreselectResult `json:"results"`
+	FinalStatus string                 `json:"final_status"`
+	FinalPayout Amount                 `json:"final_payout"`
+	Balance     float64                `json:"balance"`
+	Currency
+
+	MinePositionsIfBusted []int `json:"mine_positions_if_busted,omitempty"`
+	Suspicious            bool  `json:"suspicious,omitempty"`
+
+	ResultSignature string `json:"result_signature,omitempty"` // See SignResponse
+}
+
+// validatePreselect checks the tile list before the game is loaded
+func validatePreselect(tiles []int) string {
+	if len(tiles) < 1 || len(tiles) > MINES_PRESELECT_MAX_TILES {
+		return fmt.Sprintf("Select between 1 and %d tiles", MINES_PRESELECT_MAX_TILES)
+	}
+	seen := make(map[int]bool, len(tiles))
+	for _, tile := range tiles {
+		if seen[tile] {
+			return "Tiles must be unique"
+		}
+		seen[tile] = true
+	}
+	return ""
+}
+
+// handlePreselect reveals the selected tiles one after another, as separate
+// clicks would, stopping at the first mine. The game is updated once.
+func (m *MinesEngine) handlePreselect(ctx context.Context, req interface{}) (resp interface{}, err error) {
+	preselectReq, ok := req.(MinesPreselectRequest)
+	if !ok {
+		return nil, errors.New("invalid request type")
+	}
+	if message := validatePreselect(preselectReq.Tiles); message != "" {
+		return MinesPreselectResponse{
+			Success: false,
+			Message: message,
+		}, nil
+	}
+
+	// A failed reveal must not leave the stake stuck in an unfinished game
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("[PANIC] Mines game %s: %v\n%s", preselectReq.GameID, r, debug.Stack())
+			aborted := m.abortGame(ctx, preselectReq.GameID)
+			resp, err = MinesPreselectResponse{
+				Success:     false,
+				Message:     aborted.Message,
+				FinalStatus: aborted.GameStatus,
+				Balance:     aborted.Balance,
+			}, nil
+		}
+	}()
+
+	var results []MinesPreselectResult
+	gameState, err := m.updateGameStateAtomic(ctx, REDIS_KEY_MINES_GAME+preselectReq.GameID, func(gameState *MinesGameState) error {
+		if gameState.UserID != preselectReq.UserID {
+			return ErrNotGameOwner
+		}
+		if gameState.Status != "ACTIVE" {
+			return minesRejection("Game is not active")
+		}
+		for _, tile := range preselectReq.Tiles {
+			if err := checkMinesTile(gameState, tile); err != nil {
+				return err
+			}
+		}
+
+		// Retries start from the stored game again
+		results = results[:0]
+		for _, tile := range preselectReq.Tiles {
+			payoutIfSafe := m.payoutWithEdge(gameState.BetAmount, gameState.MineCount, len(gameState.RevealedTiles)+1, gameState.GridSize, gameState.houseEdge())
+			isMine := m.revealTile(gameState, tile)
+			results = append(results, MinesPreselectResult{TileID: tile, IsMine: isMine, PayoutIfSafe: payoutIfSafe})
+			if isMine {
+				break
+			}
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		return MinesPreselectResponse{
+			Success: false,
+			Message: minesErrorMessage(err),
+		}, nil
+	}
+
+	for range results {
+		recordActivity(ctx, m.redisClient, REDIS_KEY_MINES_CLICKS)
+	}
+	m.broadcastGameUpdate(gameState)
+	balance, _ := m.redisClient.Get(ctx, REDIS_KEY_USER_BALANCE+gameState.UserID).Float64()
+
+	preselectResp := MinesPreselectResponse{
+		Success:     true,
+		Message:     "Safe tiles!",
+		Results:     results,
+		FinalStatus: gameState.Status,
+		FinalPayout: gameState.CurrentPayout,
+		Balance:     balance,
+	}
+	if gameState.Status == "BUSTED" {
+		log.Printf("[MINES] User %s hit a mine at tile %d of %d pre-selected", preselectReq.UserID, results[len(results)-1].TileID, len(preselectReq.Tiles))
+		preselectResp.Message = "You hit a mine!"
+		preselectResp.MinePositionsIfBusted = gameState.MinePositions
+		preselectResp.Suspicious = m.settleBust(ctx, gameState)
+		return preselectResp, nil
+	}
+
+	log.Printf("[MINES] User %s revealed %d pre-selected tiles, payout: %s", preselectReq.UserID, len(results), gameState.CurrentPayout)
+	return preselectResp, nil
+}
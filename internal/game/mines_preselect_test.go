@@ -0,0 +1,130 @@
+package game
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestMinesEngine_Preselect(t *testing.T) {
+	engine, client := newTestMinesEngine(t)
+	engine.signingKey = "signing-key"
+	ctx := context.Background()
+
+	newGame := func(t *testing.T) (string, *MinesGameState) {
+		t.Helper()
+		resp, _ := engine.PlaceBet(ctx, MinesBetRequest{UserID: "user1", Amount: 10, MineCount: 3})
+		gameID := resp.(MinesBetResponse).GameID
+		gameState, err := engine.loadGame(ctx, gameID)
+		if err != nil {
+			t.Fatalf("failed to load game: %v", err)
+		}
+		return gameID, gameState
+	}
+	safeTiles := func(gameState *MinesGameState, n int) []int {
+		var tiles []int
+		for tile := 0; len(tiles) < n; tile++ {
+			if !slices.Contains(gameState.MinePositions, tile) {
+				tiles = append(tiles, tile)
+			}
+		}
+		return tiles
+	}
+	preselect := func(gameID string, tiles []int) MinesPreselectResponse {
+		resp, err := engine.ProcessAction(ctx, "preselect", MinesPreselectRequest{UserID: "user1", GameID: gameID, Tiles: tiles})
+		if err != nil {
+			t.Fatalf("preselect error: %v", err)
+		}
+		return resp.(MinesPreselectResponse)
+	}
+
+	t.Run("safe tiles", func(t *testing.T) {
+		gameID, gameState := newGame(t)
+		tiles := safeTiles(gameState, 3)
+
+		resp := preselect(gameID, tiles)
+		if !resp.Success || resp.FinalStatus != "ACTIVE" || len(resp.Results) != 3 {
+			t.Fatalf("preselect = %+v, want 3 safe reveals", resp)
+		}
+		for i, result := range resp.Results {
+			if result.TileID != tiles[i] || result.IsMine {
+				t.Errorf("results[%d] = %+v, want safe tile %d", i, result, tiles[i])
+			}
+			if want := engine.calculatePayout(amountOf(10), 3, i+1, MINES_GRID_SIZE); result.PayoutIfSafe != want {
+				t.Errorf("results[%d].payout_if_safe = %s, want %s", i, result.PayoutIfSafe, want)
+			}
+			if i > 0 && result.PayoutIfSafe <= resp.Results[i-1].PayoutIfSafe {
+				t.Errorf("payouts do not increase: %+v", resp.Results)
+			}
+		}
+		if resp.FinalPayout != resp.Results[2].PayoutIfSafe || resp.Balance != 990 || resp.MinePositionsIfBusted != nil {
+			t.Errorf("preselect = %+v, want the third payout and balance 990", resp)
+		}
+		if resp.ResultSignature == "" || resp.CurrencyCode == "" {
+			t.Errorf("preselect = %+v, want a signed response with its currency", resp)
+		}
+
+		final, _ := engine.loadGame(ctx, gameID)
+		if !slices.Equal(final.RevealedTiles, tiles) || final.Version != 1 {
+			t.Errorf("game = %+v, want tiles %v saved in one update", final, tiles)
+		}
+	})
+
+	t.Run("stops at the first mine", func(t *testing.T) {
+		gameID, gameState := newGame(t)
+		safe := safeTiles(gameState, 2)
+		tiles := []int{safe[0], gameState.MinePositions[0], safe[1]}
+
+		resp := preselect(gameID, tiles)
+		if !resp.Success || resp.FinalStatus != "BUSTED" || resp.FinalPayout != 0 {
+			t.Fatalf("preselect = %+v, want a bust", resp)
+		}
+		if len(resp.Results) != 2 || resp.Results[0].IsMine || !resp.Results[1].IsMine {
+			t.Errorf("results = %+v, want a safe tile then the mine", resp.Results)
+		}
+		if !slices.Equal(resp.MinePositionsIfBusted, gameState.MinePositions) {
+			t.Errorf("mine_positions_if_busted = %v, want %v", resp.MinePositionsIfBusted, gameState.MinePositions)
+		}
+
+		final, _ := engine.loadGame(ctx, gameID)
+		if final.Status != "BUSTED" || !slices.Equal(final.RevealedTiles, safe[:1]) {
+			t.Errorf("game = %+v, want busted after revealing %d", final, safe[0])
+		}
+	})
+
+	t.Run("rejections", func(t *testing.T) {
+		gameID, gameState := newGame(t)
+		safe := safeTiles(gameState, 2)
+		preselect(gameID, safe[:1])
+
+		tests := []struct {
+			name  string
+			tiles []int
+		}{
+			{name: "no tiles", tiles: nil},
+			{name: "too many tiles", tiles: make([]int, MINES_PRESELECT_MAX_TILES+1)},
+			{name: "duplicates", tiles: []int{safe[1], safe[1]}},
+			{name: "out of bounds", tiles: []int{safe[1], MINES_GRID_SIZE}},
+			{name: "already revealed", tiles: []int{safe[1], safe[0]}},
+		}
+		for _, tt := range tests {
+			if resp := preselect(gameID, tt.tiles); resp.Success {
+				t.Errorf("%s: preselect = %+v, want a rejection", tt.name, resp)
+			}
+		}
+
+		final, _ := engine.loadGame(ctx, gameID)
+		if len(final.RevealedTiles) != 1 {
+			t.Errorf("revealed %v, want rejected selections to reveal nothing", final.RevealedTiles)
+		}
+
+		resp, _ := engine.ProcessAction(ctx, "preselect", MinesPreselectRequest{UserID: "user2", GameID: gameID, Tiles: safe[1:]})
+		if resp.(MinesPreselectResponse).Success {
+			t.Error("preselect by another user succeeded")
+		}
+	})
+
+	if balance, _ := client.Get(ctx, REDIS_KEY_USER_BALANCE+"user1").Float64(); balance != 970 {
+		t.Errorf("balance = %v, want 970 after three bets", balance)
+	}
+}
@@ -0,0 +1,77 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"slices"
+)
+
+// Display modes of the Mines client. Only MinesDisplayProbability changes
+// the click response, which then lists the mine chance of every hidden tile.
+const (
+	MinesDisplayProbability = "probability"
+	MinesDisplayMultiplier  = "multiplier"
+	MinesDisplayPayout      = "payout"
+)
+
+// MinesTileProbability is the chance that an unrevealed tile hides a mine
+type MinesTileProbability struct {
+	TileID          int     `json:"tile_id"`
+	MineProbability float64 `json:"mine_probability"`
+}
+
+type MinesProbabilitiesResponse struct {
+	Success           bool                   `json:"success"`
+	Message           string                 `json:"message,omitempty"`
+	TileProbabilities []MinesTileProbability `json:"tile_probabilities"`
+}
+
+// validMinesDisplayMode reports whether mode is a known display mode. An empty
+// mode means MinesDisplayMultiplier.
+func validMinesDisplayMode(mode string) bool {
+	switch mode {
+	case "", MinesDisplayProbability, MinesDisplayMultiplier, MinesDisplayPayout:
+		return true
+	}
+	return false
+}
+
+// MinesTileProbabilities returns the mine chance of every unrevealed tile.
+// Every hidden tile is equally likely to be a mine, so each has a chance of
+// mine_count / unrevealed tiles. It is informational only.
+func MinesTileProbabilities(gameState *MinesGameState) []MinesTileProbability {
+	hidden := gameState.GridSize - len(gameState.RevealedTiles)
+	if hidden <= 0 {
+		return []MinesTileProbability{}
+	}
+	probability := float64(gameState.MineCount) / float64(hidden)
+
+	probabilities := make([]MinesTileProbability, 0, hidden)
+	for tile := range gameState.GridSize {
+		if !slices.Contains(gameState.RevealedTiles, tile) {
+			probabilities = append(probabilities, MinesTileProbability{TileID: tile, MineProbability: probability})
+		}
+	}
+	return probabilities
+}
+
+// handleProbabilities returns the tile probabilities of an active game
+func (m *MinesEngine) handleProbabilities(ctx context.Context, req interface{}) (interface{}, error) {
+	stateReq, ok := req.(MinesStateRequest)
+	if !ok {
+		return nil, errors.New("invalid request type")
+	}
+
+	gameState, err := m.loadGame(ctx, stateReq.GameID)
+	if err != nil {
+		return nil, err
+	}
+	if gameState.Status != "ACTIVE" {
+		return MinesProbabilitiesResponse{Success: false, Message: "Game is not active"}, nil
+	}
+
+	return MinesProbabilitiesResponse{
+		Success:           true,
+		TileProbabilities: MinesTileProbabilities(gameState),
+	}, nil
+}
@@ -0,0 +1,59 @@
+package game
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMinesEngine_TileProbabilities(t *testing.T) {
+	engine, _ := newTestMinesEngine(t)
+	ctx := context.Background()
+	const mineCount = 3
+
+	resp, _ := engine.PlaceBet(ctx, MinesBetRequest{UserID: "user1", Amount: 10, MineCount: mineCount})
+	gameID := resp.(MinesBetResponse).GameID
+	gameState, _ := engine.loadGame(ctx, gameID)
+	mines := make(map[int]bool)
+	for _, pos := range gameState.MinePositions {
+		mines[pos] = true
+	}
+
+	previous := 0.0
+	revealed := 0
+	for tile := 0; revealed < 10; tile++ {
+		if mines[tile] {
+			continue
+		}
+		click, _ := engine.ProcessAction(ctx, "click", MinesClickRequest{UserID: "user1", GameID: gameID, TileID: tile, DisplayMode: MinesDisplayProbability})
+		revealed++
+
+		result := click.(MinesClickResponse)
+		want := float64(mineCount) / float64(MINES_GRID_SIZE-revealed)
+		if result.DisplayMode != MinesDisplayProbability || len(result.TileProbabilities) != MINES_GRID_SIZE-revealed {
+			t.Fatalf("after %d reveals: %+v, want %d tile probabilities", revealed, result, MINES_GRID_SIZE-revealed)
+		}
+		for _, p := range result.TileProbabilities {
+			if p.MineProbability != want || p.TileID == tile {
+				t.Fatalf("after %d reveals: tile %d probability %v, want %v for hidden tiles only", revealed, p.TileID, p.MineProbability, want)
+			}
+		}
+		// Each safe reveal leaves the same mines among fewer tiles
+		if want <= previous {
+			t.Errorf("after %d reveals: probability %v did not rise from %v", revealed, want, previous)
+		}
+		previous = want
+	}
+
+	probs, err := engine.ProcessAction(ctx, "probabilities", MinesStateRequest{GameID: gameID})
+	if result := probs.(MinesProbabilitiesResponse); err != nil || !result.Success || len(result.TileProbabilities) != 15 || result.TileProbabilities[0].MineProbability != 0.2 {
+		t.Errorf("probabilities after 10 reveals = %+v, %v; want 15 tiles at 0.2", result, err)
+	}
+
+	if click, _ := engine.ProcessAction(ctx, "click", MinesClickRequest{UserID: "user1", GameID: gameID, TileID: 24, DisplayMode: "odds"}); click.(MinesClickResponse).Success {
+		t.Error("click with an unknown display mode succeeded")
+	}
+	engine.ProcessAction(ctx, "click", MinesClickRequest{UserID: "user1", GameID: gameID, TileID: gameState.MinePositions[0]})
+	if probs, _ := engine.ProcessAction(ctx, "probabilities", MinesStateRequest{GameID: gameID}); probs.(MinesProbabilitiesResponse).Success {
+		t.Error("probabilities of a busted game succeeded")
+	}
+}
@@ -0,0 +1,87 @@
+package game
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// MinesHMACStep is one HMAC-SHA256 computation behind a game's mine
+// positions. Step 0 derives the game seed from the server seed; every later
+// step is keyed with that seed and swaps two tiles of the Fisher-Yates shuffle.
+type MinesHMACStep struct {
+	Step int    `json:"step"`
+	Key  string `json:"key"` // "server_seed", or "seed" for the hash of step 0
+	Data string `json:"data"`
+	Hash string `json:"hash"`
+	Swap []int  `json:"swap,omitempty"` // Tiles exchanged by the step
+}
+
+// MinesVerification is the outcome of checking claimed mine positions
+type MinesVerification struct {
+	Valid              bool            `json:"valid"`
+	ComputedPositions  []int           `json:"computed_positions"`
+	ClaimedPositions   []int           `json:"claimed_positions"`
+	Match              bool            `json:"match"` // Claimed in the order the mines were placed
+	HMACInputs         []MinesHMACStep `json:"hmac_inputs"`
+	PositionsGenerated []int           `json:"positions_generated"` // The shuffled grid; the first mine_count tiles are mines
+}
+
+// VerifyMinesPositions reports whether claimedPositions are, in any order, the
+// mines of a default size game played with the given seeds
+func VerifyMinesPositions(serverSeed, clientSeed string, nonce, mineCount int, claimedPositions []int) bool {
+	// generateMinePositions uses no engine state
+	computed := new(MinesEngine).generateMinePositions(serverSeed, clientSeed, nonce, mineCount, MINES_GRID_SIZE)
+	return samePositions(computed, claimedPositions)
+}
+
+// samePositions compares two sets of tiles
+func samePositions(a, b []int) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
+
+// VerifyMines checks claimed mine positions and lists every HMAC step that
+// placed the mines, so players can repeat the computation by hand
+func VerifyMines(serverSeed, clientSeed string, nonce, mineCount, gridSize int, claimedPositions []int) MinesVerification {
+	seed := FairSeed(serverSeed, clientSeed, nonce)
+	steps := []MinesHMACStep{{
+		Step: 0,
+		Key:  "server_seed",
+		Data: fmt.Sprintf("%s:%d", clientSeed, nonce),
+		Hash: hex.EncodeToString(seed),
+	}}
+
+	// Repeats FairShuffle, recording each hash
+	perm := make([]int, gridSize)
+	for i := range perm {
+		perm[i] = i
+	}
+	for i := gridSize - 1; i > 0; i-- {
+		data := "shuffle:" + strconv.Itoa(i)
+		h := hmac.New(sha256.New, seed)
+		h.Write([]byte(data))
+		sum := h.Sum(nil)
+
+		j := int(binary.BigEndian.Uint64(sum[:8]) % uint64(i+1))
+		perm[i], perm[j] = perm[j], perm[i]
+		steps = append(steps, MinesHMACStep{Step: len(steps), Key: "seed", Data: data, Hash: hex.EncodeToString(sum), Swap: []int{i, j}})
+	}
+
+	computed := perm[:mineCount]
+	valid := samePositions(computed, claimedPositions)
+	return MinesVerification{
+		Valid:              valid,
+		ComputedPositions:  computed,
+		ClaimedPositions:   claimedPositions,
+		Match:              valid && slices.Equal(computed, claimedPositions),
+		HMACInputs:         steps,
+		PositionsGenerated: perm,
+	}
+}
@@ -0,0 +1,67 @@
+package game
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestVerifyMinesPositions(t *testing.T) {
+	engine, _ := newTestMinesEngine(t)
+	positions := engine.generateMinePositions("server-seed", "client-seed", 7, 5, MINES_GRID_SIZE)
+
+	if !VerifyMinesPositions("server-seed", "client-seed", 7, 5, positions) {
+		t.Errorf("VerifyMinesPositions() rejected the generated positions %v", positions)
+	}
+
+	reversed := slices.Clone(positions)
+	slices.Reverse(reversed)
+	if !VerifyMinesPositions("server-seed", "client-seed", 7, 5, reversed) {
+		t.Errorf("VerifyMinesPositions() rejected the positions in another order %v", reversed)
+	}
+
+	wrong := slices.Clone(positions)
+	for tile := 0; wrong[0] == positions[0]; tile++ {
+		if !slices.Contains(positions, tile) {
+			wrong[0] = tile
+		}
+	}
+	for name, claimed := range map[string][]int{
+		"a different tile": wrong,
+		"a missing tile":   positions[:4],
+		"a duplicate tile": append(slices.Clone(positions[:4]), positions[0]),
+	} {
+		if VerifyMinesPositions("server-seed", "client-seed", 7, 5, claimed) {
+			t.Errorf("VerifyMinesPositions() accepted %s: %v", name, claimed)
+		}
+	}
+	if VerifyMinesPositions("server-seed", "client-seed", 8, 5, positions) {
+		t.Error("VerifyMinesPositions() accepted the positions of another nonce")
+	}
+}
+
+func TestVerifyMines(t *testing.T) {
+	engine, _ := newTestMinesEngine(t)
+	for _, gridSize := range []int{9, 16, 25} {
+		positions := engine.generateMinePositions("server-seed", "client-seed", 3, 4, gridSize)
+
+		result := VerifyMines("server-seed", "client-seed", 3, 4, gridSize, positions)
+		if !result.Valid || !result.Match || !slices.Equal(result.ComputedPositions, positions) {
+			t.Errorf("VerifyMines(grid %d) = %+v, want the generated positions %v", gridSize, result, positions)
+		}
+		if len(result.HMACInputs) != gridSize || len(result.PositionsGenerated) != gridSize {
+			t.Errorf("VerifyMines(grid %d) has %d steps and %d tiles, want %d", gridSize, len(result.HMACInputs), len(result.PositionsGenerated), gridSize)
+		}
+		if first := result.HMACInputs[0]; first.Data != "client-seed:3" || first.Key != "server_seed" || len(first.Hash) != 64 {
+			t.Errorf("step 0 = %+v, want the game seed", first)
+		}
+		if last := result.HMACInputs[gridSize-1]; last.Data != "shuffle:1" || last.Swap[0] != 1 {
+			t.Errorf("last step = %+v, want the swap of tile 1", last)
+		}
+
+		reordered := slices.Clone(positions)
+		slices.Reverse(reordered)
+		if result := VerifyMines("server-seed", "client-seed", 3, 4, gridSize, reordered); !result.Valid || result.Match {
+			t.Errorf("VerifyMines(grid %d) of reordered positions = valid %v, match %v; want valid without an exact match", gridSize, result.Valid, result.Match)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	REDIS_KEY_NOTABLE_CASHOUTS    = "notable:cashouts"
+	REDIS_KEY_NOTABLE_LEADERBOARD = "notable:leaderboard" // Each user's biggest notable payout
+
+	NOTABLE_CASHOUT_THRESHOLD = 500.0
+	NOTABLE_CASHOUTS_KEPT     = 10
+)
+
+// NotableCashout is a payout above the notable threshold, as shown to every player
+type NotableCashout struct {
+	UserIDMasked string    `json:"user_id_masked"`
+	Amount       float64   `json:"amount"`
+	Multiplier   float64   `json:"multiplier"`
+	GameType     GameType  `json:"game_type"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// maskUserIDEnds shows the first and last three characters of a user ID.
+// IDs too short to hide anything that way fall back to maskUserID.
+func maskUserIDEnds(userID string) string {
+	runes := []rune(userID)
+	if len(runes) <= 6 {
+		return maskUserID(userID)
+	}
+	return string(runes[:3]) + "**" + string(runes[len(runes)-3:])
+}
+
+// BroadcastNotableCashout announces a big cashout to all connected clients
+// without revealing who made it. It is a no-op on a nil Hub.
+func (h *Hub) BroadcastNotableCashout(userID string, payout, multiplier float64, gameType GameType) {
+	if h == nil {
+		return
+	}
+	h.Broadcast(map[string]interface{}{
+		"type":           "notable_cashout",
+		"user_id_masked": maskUserIDEnds(userID),
+		"amount":         payout,
+		"multiplier":     multiplier,
+		"game_type":      gameType,
+	})
+}
+
+// notableCashout announces a payout above threshold and keeps it for
+// GET /api/v1/leaderboard/notable. Smaller payouts are ignored.
+func notableCashout(ctx context.Context, redisClient *redis.Client, hub *Hub, threshold float64, userID string, payout Amount, multiplier float64, gameType GameType) {
+	if payout.Float64() <= threshold {
+		return
+	}
+	hub.BroadcastNotableCashout(userID, payout.Float64(), multiplier, gameType)
+
+	entry, _ := json.Marshal(NotableCashout{
+		UserIDMasked: maskUserIDEnds(userID),
+		Amount:       payout.Float64(),
+		Multiplier:   multiplier,
+		GameType:     gameType,
+		CreatedAt:    time.Now(),
+	})
+	pipe := redisClient.TxPipeline()
+	pipe.LPush(ctx, REDIS_KEY_NOTABLE_CASHOUTS, entry)
+	pipe.LTrim(ctx, REDIS_KEY_NOTABLE_CASHOUTS, 0, NOTABLE_CASHOUTS_KEPT-1)
+	pipe.ZAddGT(ctx, REDIS_KEY_NOTABLE_LEADERBOARD, redis.Z{Score: payout.Float64(), Member: userID})
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("[LEADERBOARD] Failed to record notable cashout for %s: %v", userID, err)
+	}
+}
+
+// NotableCashouts returns the last notable cashouts, newest first
+func NotableCashouts(ctx context.Context, redisClient *redis.Client) ([]NotableCashout, error) {
+	entries, err := redisClient.LRange(ctx, REDIS_KEY_NOTABLE_CASHOUTS, 0, NOTABLE_CASHOUTS_KEPT-1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	cashouts := make([]NotableCashout, 0, len(entries))
+	for _, entry := range entries {
+		var cashout NotableCashout
+		if err := json.Unmarshal([]byte(entry), &cashout); err != nil {
+			continue
+		}
+		cashouts = append(cashouts, cashout)
+	}
+	return cashouts, nil
+}
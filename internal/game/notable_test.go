@@ -0,0 +1,76 @@
+package game
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMaskUserIDEnds(t *testing.T) {
+	tests := map[string]string{
+		"abcdefxyz": "abc**xyz",
+		"user1234":  "use**234",
+		"user1":     "u***1",
+		"ab":        "***",
+	}
+	for userID, want := range tests {
+		if got := maskUserIDEnds(userID); got != want {
+			t.Errorf("maskUserIDEnds(%q) = %q, want %q", userID, got, want)
+		}
+	}
+}
+
+func TestManager_NotableCashout(t *testing.T) {
+	m, client := newTestManager(t)
+	ctx := context.Background()
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"whale_user", 1000.0, 0)
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"small_user", 1000.0, 0)
+
+	m.setTestRound("R-notable", "BETTING")
+	bigBet := placeTestBet(t, m, "whale_user", 100, 0)
+	smallBet := placeTestBet(t, m, "small_user", 10, 0)
+	m.setTestRound("R-notable", "RUNNING")
+	m.currentRound.CurrentMultiplier = 12.5
+
+	if resp, err := m.processCashout(ctx, CashoutRequest{UserID: "small_user", BetID: smallBet}); err != nil || !resp.Success {
+		t.Fatalf("processCashout() = %+v, %v", resp, err)
+	}
+	if resp, err := m.processCashout(ctx, CashoutRequest{UserID: "whale_user", BetID: bigBet}); err != nil || !resp.Success {
+		t.Fatalf("processCashout() = %+v, %v", resp, err)
+	}
+
+	msg := awaitBroadcast(t, m, "notable_cashout")
+	if msg["user_id_masked"] != "wha**ser" || msg["amount"] != 1250.0 || msg["multiplier"] != 12.5 || msg["game_type"] != GameTypeAviator {
+		t.Errorf("notable_cashout = %v, want wha**ser winning 1250 at 12.5x in aviator", msg)
+	}
+
+	cashouts, err := NotableCashouts(ctx, client)
+	if err != nil {
+		t.Fatalf("NotableCashouts() error: %v", err)
+	}
+	if len(cashouts) != 1 || cashouts[0].Amount != 1250 || cashouts[0].GameType != GameTypeAviator {
+		t.Fatalf("NotableCashouts() = %+v, want only the 1250 payout", cashouts)
+	}
+	if score := client.ZScore(ctx, REDIS_KEY_NOTABLE_LEADERBOARD, "whale_user").Val(); score != 1250 {
+		t.Errorf("leaderboard score = %v, want 1250", score)
+	}
+}
+
+func TestNotableCashouts_KeepsLastTen(t *testing.T) {
+	m, client := newTestManager(t)
+	ctx := context.Background()
+
+	for i := 1; i <= NOTABLE_CASHOUTS_KEPT+2; i++ {
+		notableCashout(ctx, client, m.hub, NOTABLE_CASHOUT_THRESHOLD, "whale_user", amountOf(float64(500+i)), 2, GameTypeMines)
+	}
+
+	cashouts, _ := NotableCashouts(ctx, client)
+	if len(cashouts) != NOTABLE_CASHOUTS_KEPT {
+		t.Fatalf("got %d cashouts, want %d", len(cashouts), NOTABLE_CASHOUTS_KEPT)
+	}
+	if cashouts[0].Amount != 512 || cashouts[0].GameType != GameTypeMines {
+		t.Errorf("newest cashout = %+v, want 512 in mines", cashouts[0])
+	}
+	if score := client.ZScore(ctx, REDIS_KEY_NOTABLE_LEADERBOARD, "whale_user").Val(); score != 512 {
+		t.Errorf("leaderboard score = %v, want the best payout 512", score)
+	}
+}
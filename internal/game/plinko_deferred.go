@@ -0,0 +1,187 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// REDIS_KEY_PLINKO_PENDING holds deferred drops until they are revealed
+	REDIS_KEY_PLINKO_PENDING = "plinko:pending:"
+
+	// REDIS_KEY_PLINKO_PENDING_DUE scores each deferred drop by when it is
+	// revealed on the player's behalf
+	REDIS_KEY_PLINKO_PENDING_DUE = "plinko:pending:due"
+
+	// PLINKO_DEFERRED_REVEAL_WINDOW is how long the player has to reveal a
+	// deferred drop. After that it is revealed and paid out for them.
+	PLINKO_DEFERRED_REVEAL_WINDOW  = 24 * time.Hour
+	PLINKO_DEFERRED_SWEEP_INTERVAL = 1 * time.Minute
+
+	PlinkoModeInstant  = "instant"
+	PlinkoModeDeferred = "deferred"
+)
+
+// PlinkoRevealRequest pays out a deferred drop to the user who dropped it
+type PlinkoRevealRequest struct {
+	UserID string `json:"user_id"`
+	GameID string `json:"game_id"`
+}
+
+// deferDrop stores a drop whose bet has been taken without paying it out, so
+// the client can animate the ball before asking for the result. It returns
+// only what commits the server to the result.
+func (p *PlinkoEngine) deferDrop(ctx context.Context, gameState PlinkoGameState, balance float64) PlinkoDropResponse {
+	if err := p.storePendingDrop(ctx, gameState); err != nil {
+		log.Printf("[PLINKO] Failed to store deferred drop %s: %v", gameState.GameID, err)
+		balanceKey := REDIS_KEY_USER_BALANCE + gameState.UserID
+		p.redisClient.IncrByFloat(ctx, balanceKey, gameState.BetAmount.Float64()) // Rollback
+		return PlinkoDropResponse{
+			Success: false,
+			Message: "Transaction failed",
+		}
+	}
+
+	log.Printf("[PLINKO] User %s dropped ball %s, result deferred", gameState.UserID, gameState.GameID)
+
+	return PlinkoDropResponse{
+		Success:        true,
+		Message:        "Ball dropped, reveal it for the result",
+		GameID:         gameState.GameID,
+		Balance:        balance,
+		ServerSeedHash: HashCommitment(gameState.ServerSeed),
+		ClientSeed:     gameState.ClientSeed,
+		Nonce:          gameState.Nonce,
+	}
+}
+
+// storePendingDrop keeps a deferred drop until it is revealed, due for
+// revealing on the player's behalf at the end of its reveal window
+func (p *PlinkoEngine) storePendingDrop(ctx context.Context, gameState PlinkoGameState) error {
+	gameJSON, _ := json.Marshal(gameState)
+	_, err := p.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, REDIS_KEY_PLINKO_PENDING+gameState.GameID, gameJSON, 0)
+		pipe.ZAdd(ctx, REDIS_KEY_PLINKO_PENDING_DUE, redis.Z{
+			Score:  float64(gameState.CreatedAt.Add(PLINKO_DEFERRED_REVEAL_WINDOW).Unix()),
+			Member: gameState.GameID,
+		})
+		return nil
+	})
+	return err
+}
+
+// reveal pays out a deferred drop to the user who dropped it. Each drop can be
+// revealed once; after that it is not found.
+func (p *PlinkoEngine) reveal(ctx context.Context, req PlinkoRevealRequest) (interface{}, error) {
+	return p.revealDrop(ctx, req.GameID, func(gameState PlinkoGameState) error {
+		if gameState.UserID != req.UserID {
+			return ErrNotGameOwner
+		}
+		return nil
+	})
+}
+
+// revealDrop claims a deferred drop and credits its payout in one
+// transaction, so the drop is paid out once on any instance and stays pending
+// if the credit fails. check, if set, can refuse the claim.
+func (p *PlinkoEngine) revealDrop(ctx context.Context, gameID string, check func(PlinkoGameState) error) (interface{}, error) {
+	pendingKey := REDIS_KEY_PLINKO_PENDING + gameID
+	var gameState PlinkoGameState
+	var creditCmd *redis.FloatCmd
+	err := p.redisClient.Watch(ctx, func(tx *redis.Tx) error {
+		gameJSON, err := tx.Get(ctx, pendingKey).Bytes()
+		if errors.Is(err, redis.Nil) {
+			return ErrGameNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(gameJSON, &gameState); err != nil {
+			return err
+		}
+		if check != nil {
+			if err := check(gameState); err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Del(ctx, pendingKey)
+			pipe.ZRem(ctx, REDIS_KEY_PLINKO_PENDING_DUE, gameID)
+			creditCmd = pipe.IncrByFloat(ctx, REDIS_KEY_USER_BALANCE+gameState.UserID, gameState.Payout.Float64())
+			return nil
+		})
+		return err
+	}, pendingKey)
+	if errors.Is(err, redis.TxFailedErr) {
+		return nil, ErrGameNotFound // Revealed or withdrawn by another request
+	}
+	if creditCmd != nil && creditCmd.Err() != nil {
+		// A transaction does not undo the claim when only the credit fails
+		if restoreErr := p.storePendingDrop(ctx, gameState); restoreErr != nil {
+			log.Printf("[PLINKO] Failed to restore deferred drop %s after a failed credit: %v", gameID, restoreErr)
+		}
+		return PlinkoDropResponse{
+			Success: false,
+			Message: "Failed to credit payout",
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p.recordDrop(ctx, gameState, creditCmd.Val()), nil
+}
+
+// settleExpiredDrops reveals deferred drops the player left past their reveal
+// window, until ctx ends or the engine stops
+func (p *PlinkoEngine) settleExpiredDrops(ctx context.Context) {
+	ticker := time.NewTicker(PLINKO_DEFERRED_SWEEP_INTERVAL)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.stopChan:
+			return
+		case <-ticker.C:
+			p.revealExpiredDrops(ctx, time.Now())
+		}
+	}
+}
+
+// revealExpiredDrops reveals and pays out every deferred drop due by now. It
+// returns how many were paid out.
+func (p *PlinkoEngine) revealExpiredDrops(ctx context.Context, now time.Time) int {
+	gameIDs, err := p.redisClient.ZRangeByScore(ctx, REDIS_KEY_PLINKO_PENDING_DUE, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(now.Unix(), 10),
+	}).Result()
+	if err != nil {
+		log.Printf("[PLINKO] Failed to list expired deferred drops: %v", err)
+		return 0
+	}
+
+	settled := 0
+	for _, gameID := range gameIDs {
+		resp, err := p.revealDrop(ctx, gameID, nil)
+		if errors.Is(err, ErrGameNotFound) {
+			continue // Revealed or withdrawn in the meantime
+		}
+		if err != nil {
+			log.Printf("[PLINKO] Failed to reveal expired drop %s: %v", gameID, err)
+			continue
+		}
+		if drop := resp.(PlinkoDropResponse); drop.Success {
+			log.Printf("[PLINKO] Revealed expired drop %s, payout %s", gameID, drop.Payout)
+			settled++
+		}
+	}
+	return settled
+}
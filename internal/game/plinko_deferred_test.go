@@ -0,0 +1,131 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestPlinkoEngine_DeferredDrop(t *testing.T) {
+	client := newTestRedis(t)
+	ctx := context.Background()
+
+	engine := NewPlinkoEngine(client, nil)
+	engine.signingKey = "signing-key"
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"user1", 100.0, 0)
+
+	resp, err := engine.PlaceBet(ctx, PlinkoDropRequest{UserID: "user1", Amount: 10, Risk: PlinkoRiskLow, Rows: 16, Mode: PlinkoModeDeferred})
+	if err != nil {
+		t.Fatalf("PlaceBet(deferred) error: %v", err)
+	}
+	drop := resp.(PlinkoDropResponse)
+	if !drop.Success || drop.GameID == "" || drop.ServerSeedHash == "" || drop.Nonce == 0 {
+		t.Fatalf("deferred drop = %+v, want a game ID and seed commitment", drop)
+	}
+	if drop.Path != nil || drop.Multiplier != 0 || drop.ServerSeed != "" {
+		t.Errorf("deferred drop = %+v, want the result hidden", drop)
+	}
+	if balance, _ := client.Get(ctx, REDIS_KEY_USER_BALANCE+"user1").Float64(); balance != 90 {
+		t.Errorf("balance after a deferred drop = %.2f, want 90.00 with nothing credited", balance)
+	}
+
+	stored, err := client.Get(ctx, REDIS_KEY_PLINKO_PENDING+drop.GameID).Bytes()
+	if err != nil {
+		t.Fatalf("deferred drop %s not stored: %v", drop.GameID, err)
+	}
+	var pending PlinkoGameState
+	json.Unmarshal(stored, &pending)
+	if HashCommitment(pending.ServerSeed) != drop.ServerSeedHash || len(pending.Path) != 16 {
+		t.Errorf("stored drop = %+v, want the committed seed and a 16 row path", pending)
+	}
+
+	if _, err := engine.ProcessAction(ctx, "reveal", PlinkoRevealRequest{UserID: "user2", GameID: drop.GameID}); err != ErrNotGameOwner {
+		t.Errorf("reveal by another user: %v, want ErrNotGameOwner", err)
+	}
+	if client.Exists(ctx, REDIS_KEY_PLINKO_PENDING+drop.GameID).Val() != 1 || client.Exists(ctx, REDIS_KEY_USER_BALANCE+"user2").Val() != 0 {
+		t.Error("a reveal by another user claimed the drop")
+	}
+
+	resp, err = engine.ProcessAction(ctx, "reveal", PlinkoRevealRequest{UserID: "user1", GameID: drop.GameID})
+	if err != nil {
+		t.Fatalf("ProcessAction(reveal) error: %v", err)
+	}
+	revealed := resp.(PlinkoDropResponse)
+	if !revealed.Success || revealed.LandingSlot != pending.LandingSlot || revealed.Payout != pending.Payout || revealed.ServerSeed != pending.ServerSeed {
+		t.Fatalf("revealed drop = %+v, want the stored result %+v", revealed, pending)
+	}
+	if want := 90 + pending.Payout.Float64(); revealed.Balance != want {
+		t.Errorf("balance after reveal = %.2f, want %.2f", revealed.Balance, want)
+	}
+	data, _ := json.Marshal(revealed)
+	if !VerifyResultSignature(string(data), revealed.ResultSignature, "signing-key") {
+		t.Errorf("revealed drop %s is not signed", data)
+	}
+	if verification, err := engine.ProcessAction(ctx, "verify", PlinkoVerifyRequest{GameID: drop.GameID}); err != nil || !verification.(PlinkoVerification).Valid {
+		t.Errorf("verify after reveal = %+v, %v; want a valid drop", verification, err)
+	}
+
+	if _, err := engine.ProcessAction(ctx, "reveal", PlinkoRevealRequest{UserID: "user1", GameID: drop.GameID}); err != ErrGameNotFound {
+		t.Errorf("second reveal: %v, want ErrGameNotFound", err)
+	}
+	if resp, _ := engine.PlaceBet(ctx, PlinkoDropRequest{UserID: "user1", Amount: 10, Risk: PlinkoRiskLow, Rows: 16, Mode: "slow"}); resp.(PlinkoDropResponse).Success {
+		t.Error("drop with an unknown mode succeeded")
+	}
+}
+
+func TestPlinkoEngine_RevealExpiredDrops(t *testing.T) {
+	client := newTestRedis(t)
+	ctx := context.Background()
+
+	engine := NewPlinkoEngine(client, nil)
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"user1", 100.0, 0)
+
+	resp, _ := engine.PlaceBet(ctx, PlinkoDropRequest{UserID: "user1", Amount: 10, Risk: PlinkoRiskLow, Rows: 16, Mode: PlinkoModeDeferred})
+	drop := resp.(PlinkoDropResponse)
+	var pending PlinkoGameState
+	json.Unmarshal([]byte(client.Get(ctx, REDIS_KEY_PLINKO_PENDING+drop.GameID).Val()), &pending)
+
+	if settled := engine.revealExpiredDrops(ctx, time.Now()); settled != 0 {
+		t.Errorf("revealExpiredDrops() within the window = %d, want 0", settled)
+	}
+	if settled := engine.revealExpiredDrops(ctx, time.Now().Add(PLINKO_DEFERRED_REVEAL_WINDOW+time.Second)); settled != 1 {
+		t.Fatalf("revealExpiredDrops() after the window = %d, want 1", settled)
+	}
+	if balance, _ := client.Get(ctx, REDIS_KEY_USER_BALANCE+"user1").Float64(); balance != 90+pending.Payout.Float64() {
+		t.Errorf("balance = %.2f, want the %s payout credited", balance, pending.Payout)
+	}
+	if client.Exists(ctx, REDIS_KEY_PLINKO_PENDING+drop.GameID).Val() != 0 || client.ZCard(ctx, REDIS_KEY_PLINKO_PENDING_DUE).Val() != 0 {
+		t.Error("expired drop is still pending")
+	}
+	if _, err := engine.ProcessAction(ctx, "reveal", PlinkoRevealRequest{UserID: "user1", GameID: drop.GameID}); err != ErrGameNotFound {
+		t.Errorf("reveal after the sweep: %v, want ErrGameNotFound", err)
+	}
+}
+
+func TestPlinkoEngine_RevealFailedCreditKeepsDrop(t *testing.T) {
+	client := newTestRedis(t)
+	ctx := context.Background()
+
+	engine := NewPlinkoEngine(client, nil)
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"user1", 100.0, 0)
+
+	resp, _ := engine.PlaceBet(ctx, PlinkoDropRequest{UserID: "user1", Amount: 10, Risk: PlinkoRiskLow, Rows: 16, Mode: PlinkoModeDeferred})
+	drop := resp.(PlinkoDropResponse)
+
+	// A balance that is not a number makes the credit fail
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"user1", "corrupt", 0)
+	resp, err := engine.ProcessAction(ctx, "reveal", PlinkoRevealRequest{UserID: "user1", GameID: drop.GameID})
+	if err != nil || resp.(PlinkoDropResponse).Success {
+		t.Fatalf("reveal with a failing credit = %+v, %v; want an unsuccessful response", resp, err)
+	}
+	if client.Exists(ctx, REDIS_KEY_PLINKO_PENDING+drop.GameID).Val() != 1 || client.ZCard(ctx, REDIS_KEY_PLINKO_PENDING_DUE).Val() != 1 {
+		t.Fatal("drop was lost when its credit failed")
+	}
+
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"user1", 90.0, 0)
+	resp, err = engine.ProcessAction(ctx, "reveal", PlinkoRevealRequest{UserID: "user1", GameID: drop.GameID})
+	if err != nil || !resp.(PlinkoDropResponse).Success {
+		t.Errorf("reveal after the balance was fixed = %+v, %v; want the drop paid out", resp, err)
+	}
+}
@@ -2,23 +2,49 @@ package game
 
 import (
 	"context"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
-	"math/big"
+	"math"
+	"sync"
 	"time"
 
 	"github.com/redis/go-redis/v9"
+
+	"aviator/internal/notifications"
 )
 
 const (
-	REDIS_KEY_PLINKO_GAME = "plinko:game:"
+	REDIS_KEY_PLINKO_GAME      = "plinko:game:"
+	REDIS_KEY_PLINKO_AUTO_DROP = "plinko:autodrop:"
+	REDIS_KEY_PLINKO_HISTORY   = "plinko:history:"
+
+	PLINKO_GAME_TTL = 1 * time.Hour // How long a settled drop is kept, and can be verified
+
+	PLINKO_AUTO_DROP_MAX_DROPS    = 50
+	PLINKO_AUTO_DROP_MIN_INTERVAL = 200 * time.Millisecond
+	PLINKO_AUTO_DROP_MAX_DURATION = 30 * time.Second
+
+	// Caps on the multiplier ratio of any slot, applied on top of the tables below
+	PLINKO_LOW_MAX_MULTIPLIER    = 16.0
+	PLINKO_MEDIUM_MAX_MULTIPLIER = 110.0
+	PLINKO_HIGH_MAX_MULTIPLIER   = 1000.0
 )
 
+// Reasons an auto-drop run stopped
+const (
+	PlinkoStopCompleted   = "completed"
+	PlinkoStopOnProfit    = "stop_on_profit"
+	PlinkoStopOnLoss      = "stop_on_loss"
+	PlinkoStopTimeLimit   = "time_limit"
+	PlinkoStopDropFailed  = "drop_failed"
+	PlinkoStopMaintenance = "maintenance"
+	PlinkoStopError       = "error"
+)
+
+var ErrAutoDropInProgress = errors.New("auto-drop already in progress")
+
 // PlinkoRisk represents the risk level
 type PlinkoRisk string
 
@@ -48,7 +74,7 @@ var plinkoMultipliers = map[PlinkoRisk][]float64{
 type PlinkoGameState struct {
 	GameID     string     `json:"game_id"`
 	UserID     string     `json:"user_id"`
-	BetAmount  float64    `json:"bet_amount"`
+	BetAmount  Amount     `json:"bet_amount"`
 	Risk       PlinkoRisk `json:"risk"`
 	Rows       int        `json:"rows"`
 	ServerSeed string     `json:"server_seed"`
@@ -57,31 +83,65 @@ type PlinkoGameState struct {
 	Path       []int      `json:"path"`        // 0 = left, 1 = right
 	LandingSlot int       `json:"landing_slot"`
 	Multiplier float64    `json:"multiplier"`
-	Payout     float64    `json:"payout"`
+	Payout     Amount     `json:"payout"`
 	CreatedAt  time.Time  `json:"created_at"`
+
+	ResultSignature string `json:"result_signature,omitempty"` // Signs the stored game; see SignResponse
 }
 
 // PlinkoDropRequest represents a ball drop request
 type PlinkoDropRequest struct {
-	UserID string     `json:"user_id"`
-	Amount float64    `json:"amount"`
-	Risk   PlinkoRisk `json:"risk"`
-	Rows   int        `json:"rows"`
+	UserID     string     `json:"user_id"`
+	Amount     float64    `json:"amount"`
+	Risk       PlinkoRisk `json:"risk"`
+	Rows       int        `json:"rows"`
+	ClientSeed string     `json:"client_seed,omitempty"` // Generated when empty
+	Mode       string     `json:"mode,omitempty"`        // instant (default) or deferred
 }
 
 // PlinkoDropResponse represents the response to a ball drop
 type PlinkoDropResponse struct {
-	Success     bool       `json:"success"`
-	Message     string     `json:"message"`
-	GameID      string     `json:"game_id,omitempty"`
-	Path        []int      `json:"path,omitempty"`
-	LandingSlot int        `json:"landing_slot,omitempty"`
-	Multiplier  float64    `json:"multiplier,omitempty"`
-	Payout      float64    `json:"payout,omitempty"`
-	Balance     float64    `json:"balance,omitempty"`
-	ServerSeed  string     `json:"server_seed,omitempty"`
-	ClientSeed  string     `json:"client_seed,omitempty"`
-	Nonce       int        `json:"nonce,omitempty"`
+	Success         bool    `json:"success"`
+	Message         string  `json:"message"`
+	GameID          string  `json:"game_id,omitempty"`
+	Path            []int   `json:"path,omitempty"`
+	LandingSlot     int     `json:"landing_slot,omitempty"`
+	Multiplier      float64 `json:"multiplier,omitempty"`
+	Payout          Amount  `json:"payout,omitempty"`
+	Balance         float64 `json:"balance,omitempty"`
+	ServerSeed      string  `json:"server_seed,omitempty"`
+	ServerSeedHash  string  `json:"server_seed_hash,omitempty"` // Set instead of the result by a deferred drop
+	ClientSeed      string  `json:"client_seed,omitempty"`
+	Nonce           int     `json:"nonce,omitempty"`
+	Suspicious      bool    `json:"suspicious,omitempty"`
+	EffectiveRTPPct float64 `json:"effective_rtp_pct,omitempty"` // Set when the multiplier cap lowers the table's RTP
+	Currency
+	ResultSignature string  `json:"result_signature,omitempty"`  // See SignResponse
+}
+
+// PlinkoAutoDropRequest drops several balls in a row until a stop condition is hit.
+// StopOnProfit and StopOnLoss are disabled when zero.
+type PlinkoAutoDropRequest struct {
+	UserID        string     `json:"user_id"`
+	AmountPerDrop float64    `json:"amount_per_drop"`
+	Risk          PlinkoRisk `json:"risk"`
+	Rows          int        `json:"rows"`
+	Drops         int        `json:"drops"`
+	IntervalMs    int        `json:"interval_ms"`
+	StopOnProfit  float64    `json:"stop_on_profit"`
+	StopOnLoss    float64    `json:"stop_on_loss"`
+}
+
+// PlinkoAutoDropResponse summarises an auto-drop run
+type PlinkoAutoDropResponse struct {
+	Success        bool                 `json:"success"`
+	Message        string               `json:"message"`
+	DropsCompleted int                  `json:"drops_completed"`
+	TotalWagered   Amount               `json:"total_wagered"`
+	TotalPayout    Amount               `json:"total_payout"`
+	NetProfit      Amount               `json:"net_profit"`
+	StopReason     string               `json:"stop_reason,omitempty"`
+	DropResults    []PlinkoDropResponse `json:"drop_results"`
 }
 
 // PlinkoEngine implements the GameEngine interface for Plinko game
@@ -90,6 +150,23 @@ type PlinkoEngine struct {
 	hub         *Hub
 	ctx         context.Context
 	nonce       int
+	anomaly     *AnomalyDetector
+
+	maxMultipliers map[PlinkoRisk]float64 // Risk levels without an entry are uncapped
+	maxPayout      float64                // Zero disables the payout check
+
+	autoDropMinInterval time.Duration
+	autoDropMaxDuration time.Duration
+
+	probabilities sync.Map // Rows -> *plinkoRowProbabilities, filled by init
+
+	sessions *sessionLimiter // Drops in progress
+
+	// Key drop responses are signed with
+	signingKey string
+
+	stopChan chan struct{}
+	stopOnce sync.Once
 }
 
 // NewPlinkoEngine creates a new Plinko game engine
@@ -99,6 +176,22 @@ func NewPlinkoEngine(redisClient *redis.Client, hub *Hub) *PlinkoEngine {
 		hub:         hub,
 		ctx:         context.Background(),
 		nonce:       0,
+		anomaly:     NewAnomalyDetector(redisClient),
+
+		maxMultipliers: map[PlinkoRisk]float64{
+			PlinkoRiskLow:    getEnvAsFloat("PLINKO_LOW_MAX_MULTIPLIER", PLINKO_LOW_MAX_MULTIPLIER),
+			PlinkoRiskMedium: getEnvAsFloat("PLINKO_MEDIUM_MAX_MULTIPLIER", PLINKO_MEDIUM_MAX_MULTIPLIER),
+			PlinkoRiskHigh:   getEnvAsFloat("PLINKO_HIGH_MAX_MULTIPLIER", PLINKO_HIGH_MAX_MULTIPLIER),
+		},
+		maxPayout: getEnvAsFloat("MAX_PAYOUT", MAX_PAYOUT),
+
+		autoDropMinInterval: PLINKO_AUTO_DROP_MIN_INTERVAL,
+		autoDropMaxDuration: PLINKO_AUTO_DROP_MAX_DURATION,
+
+		signingKey: ResultSigningKey(),
+
+		sessions: newSessionLimiter(redisClient, GameTypePlinko, REDIS_KEY_PLINKO_ACTIVE_COUNT, getEnvAsInt("PLINKO_MAX_CONCURRENT_DROPS", PLINKO_MAX_CONCURRENT_DROPS)),
+		stopChan: make(chan struct{}),
 	}
 }
 
@@ -110,12 +203,15 @@ func (p *PlinkoEngine) GetType() GameType {
 // Start initializes the Plinko engine
 func (p *PlinkoEngine) Start(ctx context.Context) error {
 	p.ctx = ctx
+	p.init()
+	go p.settleExpiredDrops(ctx)
 	log.Println("[PLINKO] Engine started")
 	return nil
 }
 
 // Stop gracefully stops the Plinko engine
 func (p *PlinkoEngine) Stop() error {
+	p.stopOnce.Do(func() { close(p.stopChan) })
 	log.Println("[PLINKO] Engine stopped")
 	return nil
 }
@@ -125,34 +221,73 @@ func (p *PlinkoEngine) GetState() interface{} {
 	return map[string]string{"status": "ready"}
 }
 
-// PlaceBet handles a ball drop for Plinko (instant result)
+// GameInfo returns the Plinko metadata for game discovery
+func (p *PlinkoEngine) GameInfo() GameMetadata {
+	return GameMetadata{
+		GameType:     GameTypePlinko,
+		MinBet:       MIN_BET_AMOUNT,
+		MaxBet:       MAX_BET_AMOUNT,
+		Description:  "Drop a ball through 8, 12 or 16 rows of pegs into a multiplier slot",
+		HouseEdgePct: calculatePlinkoRTP().HouseEdgePct,
+		Endpoints: []GameEndpoint{
+			{Method: "POST", Path: "/api/v1/plinko/drop", Description: "Drop a ball"},
+			{Method: "POST", Path: "/api/v1/plinko/auto-drop", Description: "Drop balls repeatedly until a stop condition"},
+			{Method: "GET", Path: "/api/v1/plinko/config", Description: "Multiplier tables with their expected value"},
+			{Method: "GET", Path: "/api/v1/plinko/game/:gameID/verify", Description: "Replay a drop from its seeds"},
+			{Method: "POST", Path: "/api/v1/plinko/game/:gameID/reveal", Description: "Reveal and pay out a deferred drop"},
+		},
+	}
+}
+
+// HealthCheck verifies that a multiplier table is loaded for every risk level
+func (p *PlinkoEngine) HealthCheck(ctx context.Context) (HealthStatus, error) {
+	start := time.Now()
+
+	var err error
+	for _, risk := range []PlinkoRisk{PlinkoRiskLow, PlinkoRiskMedium, PlinkoRiskHigh} {
+		if len(plinkoMultipliers[risk]) != 17 {
+			err = fmt.Errorf("multiplier table for %s risk has %d slots, want 17", risk, len(plinkoMultipliers[risk]))
+			break
+		}
+	}
+
+	return newHealthStatus(start, err)
+}
+
+// PlaceBet handles a ball drop for Plinko. An instant drop pays out at once;
+// a deferred one waits for the "reveal" action.
 func (p *PlinkoEngine) PlaceBet(ctx context.Context, req interface{}) (interface{}, error) {
+	resp, err := p.placeBet(ctx, req)
+	return signResult(withCurrency(resp), p.signingKey), err
+}
+
+func (p *PlinkoEngine) placeBet(ctx context.Context, req interface{}) (interface{}, error) {
 	dropReq, ok := req.(PlinkoDropRequest)
 	if !ok {
 		return nil, errors.New("invalid request type")
 	}
 
-	// Validate bet amount
-	if dropReq.Amount < MIN_BET_AMOUNT || dropReq.Amount > MAX_BET_AMOUNT {
+	if message := validatePlinkoDrop(dropReq); message != "" {
 		return PlinkoDropResponse{
 			Success: false,
-			Message: fmt.Sprintf("Bet must be between %.2f and %.2f", MIN_BET_AMOUNT, MAX_BET_AMOUNT),
+			Message: message,
 		}, nil
 	}
 
-	// Validate rows (8, 12, or 16)
-	if dropReq.Rows != 8 && dropReq.Rows != 12 && dropReq.Rows != 16 {
-		return PlinkoDropResponse{
-			Success: false,
-			Message: "Rows must be 8, 12, or 16",
-		}, nil
+	if err := checkMaintenance(ctx, p.redisClient, GameTypePlinko); err != nil {
+		return nil, err
 	}
 
-	// Validate risk level
-	if dropReq.Risk != PlinkoRiskLow && dropReq.Risk != PlinkoRiskMedium && dropReq.Risk != PlinkoRiskHigh {
+	if err := p.sessions.acquire(ctx, 1); err != nil {
+		return nil, err
+	}
+	defer p.sessions.release(ctx, 1)
+
+	// Reject bets whose best slot would pay more than the table allows
+	if p.maxPayout > 0 && dropReq.Amount*p.maxTableMultiplier(dropReq.Risk, dropReq.Rows) > p.maxPayout {
 		return PlinkoDropResponse{
 			Success: false,
-			Message: "Risk must be low, medium, or high",
+			Message: fmt.Sprintf("Bet could pay more than the maximum payout of %.2f", p.maxPayout),
 		}, nil
 	}
 
@@ -176,30 +311,23 @@ func (p *PlinkoEngine) PlaceBet(ctx context.Context, req interface{}) (interface
 			Message: "Transaction failed",
 		}, nil
 	}
+	betAmount, _ := NewAmount(dropReq.Amount) // Precision checked by validatePlinkoDrop
+	p.hub.NotifyBalance(dropReq.UserID, newBalance, -betAmount, BalanceReasonBet)
+	recordWager(ctx, p.redisClient, dropReq.UserID, betAmount)
 
 	// Generate provably fair result
 	p.nonce++
 	serverSeed := GenerateSeed()
-	clientSeed := GenerateSeed()
+	clientSeed := clientSeedOrGenerate(dropReq.ClientSeed)
 	path, landingSlot := p.generatePath(serverSeed, clientSeed, p.nonce, dropReq.Rows)
 	multiplier := p.getMultiplier(dropReq.Risk, landingSlot, dropReq.Rows)
-	payout := dropReq.Amount * multiplier
-
-	// Credit payout
-	finalBalance, err := p.redisClient.IncrByFloat(ctx, balanceKey, payout).Result()
-	if err != nil {
-		return PlinkoDropResponse{
-			Success: false,
-			Message: "Failed to credit payout",
-		}, nil
-	}
 
 	// Create game state
-	gameID := fmt.Sprintf("PLINKO-%s-%d", dropReq.UserID, time.Now().UnixNano())
+	playedAt := time.Now()
 	gameState := PlinkoGameState{
-		GameID:      gameID,
+		GameID:      fmt.Sprintf("PLINKO-%s-%d", dropReq.UserID, playedAt.UnixNano()),
 		UserID:      dropReq.UserID,
-		BetAmount:   dropReq.Amount,
+		BetAmount:   betAmount,
 		Risk:        dropReq.Risk,
 		Rows:        dropReq.Rows,
 		ServerSeed:  serverSeed,
@@ -208,58 +336,312 @@ func (p *PlinkoEngine) PlaceBet(ctx context.Context, req interface{}) (interface
 		Path:        path,
 		LandingSlot: landingSlot,
 		Multiplier:  multiplier,
-		Payout:      payout,
-		CreatedAt:   time.Now(),
+		Payout:      betAmount.Mul(multiplier),
+		CreatedAt:   playedAt,
 	}
 
+	if dropReq.Mode == PlinkoModeDeferred {
+		return p.deferDrop(ctx, gameState, newBalance), nil
+	}
+	return p.settleDrop(ctx, gameState), nil
+}
+
+// settleDrop credits the payout of a drop whose bet has been taken, then
+// stores and records the game
+func (p *PlinkoEngine) settleDrop(ctx context.Context, gameState PlinkoGameState) PlinkoDropResponse {
+	// Credit payout
+	balanceKey := REDIS_KEY_USER_BALANCE + gameState.UserID
+	finalBalance, err := p.redisClient.IncrByFloat(ctx, balanceKey, gameState.Payout.Float64()).Result()
+	if err != nil {
+		return PlinkoDropResponse{
+			Success: false,
+			Message: "Failed to credit payout",
+		}
+	}
+	return p.recordDrop(ctx, gameState, finalBalance)
+}
+
+// recordDrop stores and reports a drop whose payout has been credited,
+// leaving finalBalance
+func (p *PlinkoEngine) recordDrop(ctx context.Context, gameState PlinkoGameState, finalBalance float64) PlinkoDropResponse {
+	p.hub.NotifyBalance(gameState.UserID, finalBalance, gameState.Payout, BalanceReasonPayout)
+
+	gameState.ResultSignature = SignResponse(gameState, p.signingKey)
+
 	// Store game state in Redis
-	gameKey := REDIS_KEY_PLINKO_GAME + gameID
+	gameKey := REDIS_KEY_PLINKO_GAME + gameState.GameID
 	gameJSON, _ := json.Marshal(gameState)
-	p.redisClient.Set(ctx, gameKey, string(gameJSON), 1*time.Hour)
+	p.redisClient.Set(ctx, gameKey, string(gameJSON), PLINKO_GAME_TTL)
+	recordActivity(ctx, p.redisClient, REDIS_KEY_PLINKO_DROPS)
+	if err := recordGameHistory(ctx, p.redisClient, REDIS_KEY_PLINKO_HISTORY+gameState.UserID, gameState.GameID, gameState.CreatedAt); err != nil {
+		log.Printf("[PLINKO] Failed to record history for %s: %v", gameState.UserID, err)
+	}
+
+	outcome := GameOutcome{
+		UserID:   gameState.UserID,
+		GameType: GameTypePlinko,
+		Wager:    gameState.BetAmount,
+		Payout:   gameState.Payout,
+	}
+	suspicious := p.anomaly.Record(ctx, outcome)
+	recordHouseProfit(ctx, p.redisClient, outcome)
+	p.hub.GameSettled(notifications.EventPlinkoDrop, outcome)
+	p.hub.BetSettled(ctx, outcome.UserID)
 
-	log.Printf("[PLINKO] User %s dropped ball, landed at slot %d, multiplier %.2fx, payout %.2f",
-		dropReq.UserID, landingSlot, multiplier, payout)
+	log.Printf("[PLINKO] User %s dropped ball, landed at slot %d, multiplier %.2fx, payout %s",
+		gameState.UserID, gameState.LandingSlot, gameState.Multiplier, gameState.Payout)
+
+	var effectiveRTP float64
+	if rtp, capped := p.effectiveRTPPct(gameState.Risk, gameState.Rows); capped {
+		effectiveRTP = rtp
+	}
 
 	return PlinkoDropResponse{
-		Success:     true,
-		Message:     "Ball dropped successfully",
-		GameID:      gameID,
-		Path:        path,
-		LandingSlot: landingSlot,
-		Multiplier:  multiplier,
-		Payout:      payout,
-		Balance:     finalBalance,
-		ServerSeed:  serverSeed,
-		ClientSeed:  clientSeed,
-		Nonce:       p.nonce,
-	}, nil
+		Success:         true,
+		Message:         "Ball dropped successfully",
+		GameID:          gameState.GameID,
+		Path:            gameState.Path,
+		LandingSlot:     gameState.LandingSlot,
+		Multiplier:      gameState.Multiplier,
+		Payout:          gameState.Payout,
+		Balance:         finalBalance,
+		ServerSeed:      gameState.ServerSeed,
+		ClientSeed:      gameState.ClientSeed,
+		Nonce:           gameState.Nonce,
+		Suspicious:      suspicious,
+		EffectiveRTPPct: effectiveRTP,
+	}
 }
 
-// ProcessAction handles game-specific actions (not applicable for Plinko)
+// validatePlinkoDrop checks a drop without touching balances. It returns a
+// message for the player, or "" if the drop is valid.
+func validatePlinkoDrop(dropReq PlinkoDropRequest) string {
+	// Validate bet amount
+	if message := validateBetAmount(dropReq.Amount); message != "" {
+		return message
+	}
+
+	// Validate rows (8, 12, or 16)
+	if dropReq.Rows != 8 && dropReq.Rows != 12 && dropReq.Rows != 16 {
+		return "Rows must be 8, 12, or 16"
+	}
+
+	// Validate risk level
+	if dropReq.Risk != PlinkoRiskLow && dropReq.Risk != PlinkoRiskMedium && dropReq.Risk != PlinkoRiskHigh {
+		return "Risk must be low, medium, or high"
+	}
+
+	if dropReq.Mode != "" && dropReq.Mode != PlinkoModeInstant && dropReq.Mode != PlinkoModeDeferred {
+		return "Mode must be instant or deferred"
+	}
+
+	return ValidateClientSeed(dropReq.ClientSeed)
+}
+
+// ProcessAction handles game-specific actions
 func (p *PlinkoEngine) ProcessAction(ctx context.Context, action string, req interface{}) (interface{}, error) {
-	return nil, errors.New("no actions available for Plinko")
+	switch action {
+	case "auto_drop":
+		return p.handleAutoDrop(ctx, req)
+	case "history":
+		historyReq, ok := req.(GameHistoryRequest)
+		if !ok {
+			return nil, errors.New("invalid request type")
+		}
+		return p.history(ctx, historyReq)
+	case "stats":
+		statsReq, ok := req.(PlinkoStatsRequest)
+		if !ok {
+			return nil, errors.New("invalid request type")
+		}
+		return p.stats(ctx, statsReq.UserID)
+	case "verify":
+		verifyReq, ok := req.(PlinkoVerifyRequest)
+		if !ok {
+			return nil, errors.New("invalid request type")
+		}
+		return p.verify(ctx, verifyReq)
+	case "reveal":
+		revealReq, ok := req.(PlinkoRevealRequest)
+		if !ok {
+			return nil, errors.New("invalid request type")
+		}
+		resp, err := p.reveal(ctx, revealReq)
+		return signResult(withCurrency(resp), p.signingKey), err
+	case "active_count":
+		return p.activeStats(ctx)
+	case "capacity":
+		return p.sessions.capacity(ctx)
+	default:
+		return nil, errors.New("unknown action")
+	}
+}
+
+// handleAutoDrop drops balls sequentially until the requested count, a
+// profit/loss limit or the time limit is reached. Only one auto-drop may run
+// per user; a second concurrent run returns ErrAutoDropInProgress. An error
+// after the first drop stops the run and is returned with the drops so far.
+func (p *PlinkoEngine) handleAutoDrop(ctx context.Context, req interface{}) (interface{}, error) {
+	autoReq, ok := req.(PlinkoAutoDropRequest)
+	if !ok {
+		return nil, errors.New("invalid request type")
+	}
+
+	p.applyAutoDropPreferences(ctx, &autoReq)
+	dropReq := PlinkoDropRequest{
+		UserID: autoReq.UserID,
+		Amount: autoReq.AmountPerDrop,
+		Risk:   autoReq.Risk,
+		Rows:   autoReq.Rows,
+	}
+	if message := validatePlinkoDrop(dropReq); message != "" {
+		return PlinkoAutoDropResponse{Success: false, Message: message}, nil
+	}
+	if autoReq.Drops < 1 {
+		return PlinkoAutoDropResponse{Success: false, Message: "Drops must be at least 1"}, nil
+	}
+	if autoReq.StopOnProfit < 0 || autoReq.StopOnLoss < 0 {
+		return PlinkoAutoDropResponse{Success: false, Message: "Stop conditions cannot be negative"}, nil
+	}
+
+	drops := autoReq.Drops
+	if drops > PLINKO_AUTO_DROP_MAX_DROPS {
+		drops = PLINKO_AUTO_DROP_MAX_DROPS
+	}
+	interval := time.Duration(autoReq.IntervalMs) * time.Millisecond
+	if interval < p.autoDropMinInterval {
+		interval = p.autoDropMinInterval
+	}
+
+	// Claim the per-user auto-drop slot; the TTL frees it if we crash mid-run
+	lockKey := REDIS_KEY_PLINKO_AUTO_DROP + autoReq.UserID
+	state, _ := json.Marshal(autoReq)
+	acquired, err := p.redisClient.SetNX(ctx, lockKey, state, p.autoDropMaxDuration+5*time.Second).Result()
+	if err != nil {
+		return nil, err
+	}
+	if !acquired {
+		return nil, ErrAutoDropInProgress
+	}
+	defer p.redisClient.Del(context.Background(), lockKey)
+
+	betAmount, _ := NewAmount(dropReq.Amount)
+	resp := PlinkoAutoDropResponse{DropResults: []PlinkoDropResponse{}}
+	deadline := time.Now().Add(p.autoDropMaxDuration)
+	var runErr error
+
+	for resp.DropsCompleted < drops {
+		if resp.DropsCompleted > 0 {
+			if time.Now().Add(interval).After(deadline) {
+				resp.StopReason = PlinkoStopTimeLimit
+				break
+			}
+			select {
+			case <-time.After(interval):
+			case <-ctx.Done():
+				runErr = ctx.Err()
+			}
+			if runErr != nil {
+				break
+			}
+		}
+
+		result, err := p.PlaceBet(ctx, dropReq)
+		var maintenance *MaintenanceError
+		if errors.As(err, &maintenance) && resp.DropsCompleted > 0 {
+			resp.StopReason = PlinkoStopMaintenance
+			resp.Message = "Game under maintenance"
+			break
+		}
+		if err != nil && resp.DropsCompleted == 0 {
+			return nil, err
+		}
+		if err != nil {
+			runErr = err
+			break
+		}
+		drop := result.(PlinkoDropResponse)
+		if !drop.Success {
+			resp.StopReason = PlinkoStopDropFailed
+			resp.Message = drop.Message
+			break
+		}
+
+		resp.DropsCompleted++
+		resp.TotalWagered += betAmount
+		resp.TotalPayout += drop.Payout
+		resp.NetProfit = resp.TotalPayout - resp.TotalWagered
+		resp.DropResults = append(resp.DropResults, drop)
+
+		if autoReq.StopOnProfit > 0 && resp.NetProfit.Float64() > autoReq.StopOnProfit {
+			resp.StopReason = PlinkoStopOnProfit
+			break
+		}
+		if autoReq.StopOnLoss > 0 && -resp.NetProfit.Float64() > autoReq.StopOnLoss {
+			resp.StopReason = PlinkoStopOnLoss
+			break
+		}
+	}
+
+	if resp.DropsCompleted == 0 {
+		return resp, nil
+	}
+	if runErr != nil {
+		log.Printf("[PLINKO] User %s auto-drop stopped after %d balls, net %s: %v",
+			autoReq.UserID, resp.DropsCompleted, resp.NetProfit, runErr)
+		resp.StopReason = PlinkoStopError
+		resp.Message = runErr.Error()
+		return resp, runErr
+	}
+	if resp.StopReason == "" {
+		resp.StopReason = PlinkoStopCompleted
+	}
+
+	log.Printf("[PLINKO] User %s auto-dropped %d balls, net %s (%s)",
+		autoReq.UserID, resp.DropsCompleted, resp.NetProfit, resp.StopReason)
+
+	resp.Success = true
+	if resp.Message == "" {
+		resp.Message = "Auto-drop finished"
+	}
+	return resp, nil
+}
+
+// applyAutoDropPreferences fills the amount, risk and rows an auto-drop
+// leaves out with the user's saved Plinko defaults
+func (p *PlinkoEngine) applyAutoDropPreferences(ctx context.Context, autoReq *PlinkoAutoDropRequest) {
+	prefs, err := LoadPreferences(ctx, p.redisClient, autoReq.UserID)
+	if err != nil {
+		log.Printf("[PLINKO] Failed to load preferences of %s: %v", autoReq.UserID, err)
+		return
+	}
+	if prefs == nil || prefs.Plinko == nil {
+		return
+	}
+
+	if autoReq.AmountPerDrop == 0 {
+		autoReq.AmountPerDrop = prefs.Plinko.DefaultAmount
+	}
+	if autoReq.Risk == "" {
+		autoReq.Risk = prefs.Plinko.DefaultRisk
+	}
+	if autoReq.Rows == 0 {
+		autoReq.Rows = prefs.Plinko.DefaultRows
+	}
 }
 
 // generatePath generates the ball's path using provably fair algorithm
 func (p *PlinkoEngine) generatePath(serverSeed, clientSeed string, nonce, rows int) ([]int, int) {
 	path := make([]int, rows)
 	position := 0
+	seed := FairSeed(serverSeed, clientSeed, nonce)
 
 	for i := 0; i < rows; i++ {
-		// Generate hash for this step
-		data := fmt.Sprintf("%s:%d:%d", clientSeed, nonce, i)
-		h := hmac.New(sha256.New, []byte(serverSeed))
-		h.Write([]byte(data))
-		hashBytes := h.Sum(nil)
-		hashHex := hex.EncodeToString(hashBytes)
-
-		// Take first 8 hex characters
-		hexValue := hashHex[:8]
-		bigInt := new(big.Int)
-		bigInt.SetString(hexValue, 16)
-
 		// Determine direction: 0 = left, 1 = right
-		direction := int(bigInt.Uint64() % 2)
+		direction := 0
+		if FairBernoulli(seed, i) {
+			direction = 1
+		}
 		path[i] = direction
 
 		// Update position
@@ -271,8 +653,42 @@ func (p *PlinkoEngine) generatePath(serverSeed, clientSeed string, nonce, rows i
 	return path, position
 }
 
-// getMultiplier returns the multiplier for a given landing slot
+// getMultiplier returns the multiplier for a given landing slot, clamped to
+// the risk level's maximum multiplier
 func (p *PlinkoEngine) getMultiplier(risk PlinkoRisk, landingSlot, rows int) float64 {
+	multiplier := p.tableMultiplier(risk, landingSlot, rows)
+	if maxMultiplier, ok := p.maxMultipliers[risk]; ok && multiplier > maxMultiplier {
+		return maxMultiplier
+	}
+	return multiplier
+}
+
+// maxTableMultiplier returns the best multiplier a risk/rows configuration pays
+func (p *PlinkoEngine) maxTableMultiplier(risk PlinkoRisk, rows int) float64 {
+	best := 0.0
+	for slot := 0; slot <= rows; slot++ {
+		best = math.Max(best, p.getMultiplier(risk, slot, rows))
+	}
+	return best
+}
+
+// effectiveRTPPct returns the RTP of a risk/rows configuration after the
+// multiplier cap, and whether the cap lowered any slot
+func (p *PlinkoEngine) effectiveRTPPct(risk PlinkoRisk, rows int) (float64, bool) {
+	rtp := 0.0
+	capped := false
+	for slot := 0; slot <= rows; slot++ {
+		multiplier := p.getMultiplier(risk, slot, rows)
+		if multiplier < p.tableMultiplier(risk, slot, rows) {
+			capped = true
+		}
+		rtp += p.GetSlotProbability(rows, slot) * multiplier
+	}
+	return roundPct(rtp * 100), capped
+}
+
+// tableMultiplier returns the uncapped multiplier for a given landing slot
+func (p *PlinkoEngine) tableMultiplier(risk PlinkoRisk, landingSlot, rows int) float64 {
 	multipliers, exists := plinkoMultipliers[risk]
 	if !exists {
 		return 1.0
@@ -301,3 +717,61 @@ func (p *PlinkoEngine) getMultiplier(risk PlinkoRisk, landingSlot, rows int) flo
 
 	return baseMultiplier
 }
+
+// EVResult is the expected value of one Plinko risk/rows configuration
+type EVResult struct {
+	Risk                PlinkoRisk `json:"risk"`
+	Rows                int        `json:"rows"`
+	Multipliers         []float64  `json:"multipliers"`           // Indexed by landing slot
+	Probabilities       []float64  `json:"probabilities"`         // Chance of landing in each slot
+	LeftHalfProbability float64    `json:"left_half_probability"` // Chance of landing in slots 0..rows/2-1
+	ExpectedValue       float64    `json:"expected_value"`
+	RTPPct              float64    `json:"rtp_pct"`
+}
+
+// ComputePlinkoEV returns the multipliers a risk/rows configuration pays and
+// their expected value per unit staked. A ball lands in slot i with the
+// binomial probability C(rows, i) * 0.5^rows. The multiplier caps are left
+// out, so the result describes the tables themselves.
+func ComputePlinkoEV(risk PlinkoRisk, rows int) EVResult {
+	return (&PlinkoEngine{}).ComputeEV(risk, rows)
+}
+
+// ComputeEV is ComputePlinkoEV using the engine's precomputed probabilities
+func (p *PlinkoEngine) ComputeEV(risk PlinkoRisk, rows int) EVResult {
+	result := EVResult{
+		Risk:                risk,
+		Rows:                rows,
+		Multipliers:         make([]float64, rows+1),
+		Probabilities:       make([]float64, rows+1),
+		LeftHalfProbability: p.LeftHalfProbability(rows),
+	}
+
+	ev := 0.0
+	for slot := 0; slot <= rows; slot++ {
+		result.Multipliers[slot] = p.tableMultiplier(risk, slot, rows)
+		result.Probabilities[slot] = p.GetSlotProbability(rows, slot)
+		ev += result.Probabilities[slot] * result.Multipliers[slot]
+	}
+	result.ExpectedValue = math.Round(ev*1e6) / 1e6
+	result.RTPPct = roundPct(ev * 100)
+
+	return result
+}
+
+// PlinkoConfig returns the expected value of every risk/rows configuration a
+// drop accepts
+func PlinkoConfig() []EVResult {
+	return (&PlinkoEngine{}).Config()
+}
+
+// Config is PlinkoConfig using the engine's precomputed probabilities
+func (p *PlinkoEngine) Config() []EVResult {
+	config := make([]EVResult, 0, 9)
+	for _, risk := range []PlinkoRisk{PlinkoRiskLow, PlinkoRiskMedium, PlinkoRiskHigh} {
+		for _, rows := range []int{8, 12, 16} {
+			config = append(config, p.ComputeEV(risk, rows))
+		}
+	}
+	return config
+}
@@ -0,0 +1,48 @@
+//go:build integration
+
+package game
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPlinkoEngineIntegration(t *testing.T) {
+	engine := NewPlinkoEngine(testRedis, nil)
+
+	t.Run("drop settles balance", func(t *testing.T) {
+		setTestBalance(t, "plinko-drop", 100)
+
+		resp, err := engine.PlaceBet(context.Background(), PlinkoDropRequest{UserID: "plinko-drop", Amount: 10, Risk: PlinkoRiskMedium, Rows: 16})
+		if err != nil {
+			t.Fatalf("PlaceBet returned error: %v", err)
+		}
+		dropResp := resp.(PlinkoDropResponse)
+		if !dropResp.Success {
+			t.Fatalf("Drop failed: %s", dropResp.Message)
+		}
+		if len(dropResp.Path) != 16 {
+			t.Errorf("Expected path of 16 rows, got %d", len(dropResp.Path))
+		}
+
+		expected := 90 + dropResp.Payout.Float64()
+		if balance := getTestBalance(t, "plinko-drop"); balance != expected {
+			t.Errorf("Expected balance %.2f, got %.2f", expected, balance)
+		}
+		if exists := testRedis.Exists(context.Background(), REDIS_KEY_PLINKO_GAME+dropResp.GameID).Val(); exists != 1 {
+			t.Error("Expected game to be stored in Redis")
+		}
+	})
+
+	t.Run("insufficient balance is rejected", func(t *testing.T) {
+		setTestBalance(t, "plinko-broke", 5)
+
+		resp, _ := engine.PlaceBet(context.Background(), PlinkoDropRequest{UserID: "plinko-broke", Amount: 10, Risk: PlinkoRiskLow, Rows: 8})
+		if resp.(PlinkoDropResponse).Success {
+			t.Error("Expected drop to fail")
+		}
+		if balance := getTestBalance(t, "plinko-broke"); balance != 5 {
+			t.Errorf("Expected balance 5, got %.2f", balance)
+		}
+	})
+}
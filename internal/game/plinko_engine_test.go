@@ -1,7 +1,11 @@
 package game
 
 import (
+	"context"
+	"errors"
+	"math"
 	"testing"
+	"time"
 )
 
 func TestPlinkoEngine_GeneratePath(t *testing.T) {
@@ -101,6 +105,62 @@ func TestPlinkoEngine_GetMultiplier(t *testing.T) {
 	})
 }
 
+func TestPlinkoEngine_MaxMultiplier(t *testing.T) {
+	edge := plinkoMultipliers[PlinkoRiskHigh][0]
+	plinkoMultipliers[PlinkoRiskHigh][0] = 1001.0
+	t.Cleanup(func() { plinkoMultipliers[PlinkoRiskHigh][0] = edge })
+
+	engine := NewPlinkoEngine(nil, nil)
+
+	if multiplier := engine.getMultiplier(PlinkoRiskHigh, 0, 16); multiplier != 1000.0 {
+		t.Errorf("capped multiplier = %v, want 1000", multiplier)
+	}
+	if multiplier := engine.getMultiplier(PlinkoRiskHigh, 16, 16); multiplier != 1000.0 {
+		t.Errorf("uncapped edge multiplier = %v, want 1000", multiplier)
+	}
+
+	rtp, capped := engine.effectiveRTPPct(PlinkoRiskHigh, 16)
+	if !capped {
+		t.Fatal("a 1001x slot should be reported as capped")
+	}
+	if want := (&PlinkoEngine{}).getMultiplier(PlinkoRiskHigh, 0, 16); want != 1001.0 {
+		t.Errorf("uncapped engine multiplier = %v, want 1001", want)
+	}
+	if _, capped := engine.effectiveRTPPct(PlinkoRiskLow, 16); capped {
+		t.Error("low risk table is within its cap")
+	}
+
+	uncappedRTP := 0.0
+	for slot := 0; slot <= 16; slot++ {
+		uncappedRTP += binomialProbability(16, slot) * engine.tableMultiplier(PlinkoRiskHigh, slot, 16)
+	}
+	if rtp >= uncappedRTP*100 {
+		t.Errorf("effective RTP %.4f%% should be below the uncapped %.4f%%", rtp, uncappedRTP*100)
+	}
+}
+
+func TestPlinkoEngine_MaxPayout(t *testing.T) {
+	client := newTestRedis(t)
+	ctx := context.Background()
+
+	engine := NewPlinkoEngine(client, nil)
+	engine.maxPayout = 50000
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"user1", 1000.0, 0)
+
+	resp, _ := engine.PlaceBet(ctx, PlinkoDropRequest{UserID: "user1", Amount: 100, Risk: PlinkoRiskHigh, Rows: 16})
+	if resp.(PlinkoDropResponse).Success {
+		t.Error("100.00 at 1000x exceeds a 50000.00 max payout and should be rejected")
+	}
+	if balance, _ := client.Get(ctx, REDIS_KEY_USER_BALANCE+"user1").Float64(); balance != 1000 {
+		t.Errorf("balance = %.2f, want 1000.00", balance)
+	}
+
+	resp, _ = engine.PlaceBet(ctx, PlinkoDropRequest{UserID: "user1", Amount: 100, Risk: PlinkoRiskLow, Rows: 16})
+	if !resp.(PlinkoDropResponse).Success {
+		t.Errorf("100.00 at 16x fits the max payout, got %q", resp.(PlinkoDropResponse).Message)
+	}
+}
+
 func TestPlinkoEngine_GetType(t *testing.T) {
 	engine := &PlinkoEngine{}
 
@@ -144,3 +204,197 @@ func TestPlinkoEngine_RiskLevels(t *testing.T) {
 		}
 	})
 }
+
+func TestComputePlinkoEV(t *testing.T) {
+	t.Run("built-in tables favour the house", func(t *testing.T) {
+		// The tables in plinkoMultipliers are for 16 rows; 8 and 12 rows are
+		// derived from them and reported by the config endpoint's alerts
+		for risk := range plinkoMultipliers {
+			result := ComputePlinkoEV(risk, 16)
+			if result.ExpectedValue <= 0.90 || result.ExpectedValue >= 1.0 {
+				t.Errorf("%s risk EV = %v, want between 0.90 and 1.0", risk, result.ExpectedValue)
+			}
+		}
+	})
+
+	t.Run("low risk 16 rows", func(t *testing.T) {
+		result := ComputePlinkoEV(PlinkoRiskLow, 16)
+		if len(result.Multipliers) != 17 || len(result.Probabilities) != 17 {
+			t.Fatalf("got %d multipliers and %d probabilities, want 17", len(result.Multipliers), len(result.Probabilities))
+		}
+		if math.Abs(result.ExpectedValue-0.99) > 0.005 {
+			t.Errorf("EV = %v, want about 0.99", result.ExpectedValue)
+		}
+		if result.RTPPct != roundPct(result.ExpectedValue*100) {
+			t.Errorf("RTPPct = %v, want %v", result.RTPPct, roundPct(result.ExpectedValue*100))
+		}
+	})
+
+	t.Run("probabilities are binomial", func(t *testing.T) {
+		for _, rows := range []int{8, 12, 16} {
+			result := ComputePlinkoEV(PlinkoRiskMedium, rows)
+			total := 0.0
+			for _, p := range result.Probabilities {
+				total += p
+			}
+			if math.Abs(total-1) > 1e-9 {
+				t.Errorf("%d rows: probabilities sum to %v, want 1", rows, total)
+			}
+			if want := 1 / math.Pow(2, float64(rows)); result.Probabilities[0] != want {
+				t.Errorf("%d rows: P(slot 0) = %v, want %v", rows, result.Probabilities[0], want)
+			}
+		}
+	})
+
+	t.Run("matches the published RTP", func(t *testing.T) {
+		info, _ := CalculateRTP(GameTypePlinko)
+		for _, entry := range info.Table.([]PlinkoRTPEntry) {
+			if got := ComputePlinkoEV(entry.Risk, entry.Rows).RTPPct; got != entry.RTPPct {
+				t.Errorf("%s/%d: RTPPct = %v, want %v", entry.Risk, entry.Rows, got, entry.RTPPct)
+			}
+		}
+	})
+}
+
+func TestPlinkoEngine_AutoDrop(t *testing.T) {
+	client := newTestRedis(t)
+	ctx := context.Background()
+
+	engine := NewPlinkoEngine(client, nil)
+	engine.autoDropMinInterval = 0
+
+	req := PlinkoAutoDropRequest{
+		UserID:        "user1",
+		AmountPerDrop: 10,
+		Risk:          PlinkoRiskHigh,
+		Rows:          16,
+		Drops:         100,
+		StopOnLoss:    25,
+	}
+
+	t.Run("stop on loss", func(t *testing.T) {
+		// High risk loses 8.00 on most drops, so the loss limit is reached
+		// well before the cap unless an edge slot is hit early; retry then.
+		var resp PlinkoAutoDropResponse
+		for attempt := 0; attempt < 5; attempt++ {
+			client.Set(ctx, REDIS_KEY_USER_BALANCE+"user1", 100000.0, 0)
+			result, err := engine.ProcessAction(ctx, "auto_drop", req)
+			if err != nil {
+				t.Fatalf("auto_drop error: %v", err)
+			}
+			resp = result.(PlinkoAutoDropResponse)
+			if resp.StopReason == PlinkoStopOnLoss {
+				break
+			}
+		}
+
+		if resp.StopReason != PlinkoStopOnLoss {
+			t.Fatalf("stop reason = %q, want %q", resp.StopReason, PlinkoStopOnLoss)
+		}
+		if -resp.NetProfit.Float64() <= req.StopOnLoss {
+			t.Errorf("stopped with loss %s, should exceed %.2f", -resp.NetProfit, req.StopOnLoss)
+		}
+		last := resp.DropResults[len(resp.DropResults)-1]
+		if lossBefore := -(resp.NetProfit - (last.Payout - amountOf(req.AmountPerDrop))); lossBefore.Float64() > req.StopOnLoss {
+			t.Errorf("loss %s already exceeded the limit before the final drop", lossBefore)
+		}
+		if resp.DropsCompleted != len(resp.DropResults) || resp.DropsCompleted > PLINKO_AUTO_DROP_MAX_DROPS {
+			t.Errorf("drops completed = %d with %d results", resp.DropsCompleted, len(resp.DropResults))
+		}
+
+		balance, _ := client.Get(ctx, REDIS_KEY_USER_BALANCE+"user1").Float64()
+		if math.Abs(balance-(100000+resp.NetProfit.Float64())) > 1e-6 {
+			t.Errorf("balance = %.2f, want %.2f", balance, 100000+resp.NetProfit.Float64())
+		}
+		if client.Exists(ctx, REDIS_KEY_PLINKO_AUTO_DROP+"user1").Val() != 0 {
+			t.Error("auto-drop lock should be released")
+		}
+	})
+
+	t.Run("drops are capped", func(t *testing.T) {
+		client.Set(ctx, REDIS_KEY_USER_BALANCE+"user2", 100000.0, 0)
+		capped := PlinkoAutoDropRequest{UserID: "user2", AmountPerDrop: 1, Risk: PlinkoRiskLow, Rows: 8, Drops: 100}
+
+		result, _ := engine.ProcessAction(ctx, "auto_drop", capped)
+		resp := result.(PlinkoAutoDropResponse)
+		if resp.DropsCompleted != PLINKO_AUTO_DROP_MAX_DROPS || resp.StopReason != PlinkoStopCompleted {
+			t.Errorf("completed %d drops (%s), want %d (completed)", resp.DropsCompleted, resp.StopReason, PLINKO_AUTO_DROP_MAX_DROPS)
+		}
+	})
+
+	t.Run("saved preferences fill the request", func(t *testing.T) {
+		client.Set(ctx, REDIS_KEY_USER_BALANCE+"user3", 1000.0, 0)
+		prefs := UserPreferences{Plinko: &PlinkoPreferences{DefaultRisk: PlinkoRiskLow, DefaultRows: 8, DefaultAmount: 2}}
+		if err := SavePreferences(ctx, client, "user3", prefs); err != nil {
+			t.Fatalf("SavePreferences() error: %v", err)
+		}
+
+		result, _ := engine.ProcessAction(ctx, "auto_drop", PlinkoAutoDropRequest{UserID: "user3", Drops: 2})
+		resp := result.(PlinkoAutoDropResponse)
+		if !resp.Success || resp.DropsCompleted != 2 || resp.TotalWagered != amountOf(4) {
+			t.Fatalf("auto_drop = %+v, want 2 drops of 2.00", resp)
+		}
+		if path := resp.DropResults[0].Path; len(path) != 8 {
+			t.Errorf("drop used %d rows, want the saved 8", len(path))
+		}
+	})
+
+	t.Run("error mid-run returns the drops so far", func(t *testing.T) {
+		client.Set(ctx, REDIS_KEY_USER_BALANCE+"user4", 1000.0, 0)
+		slow := PlinkoAutoDropRequest{UserID: "user4", AmountPerDrop: 1, Risk: PlinkoRiskLow, Rows: 8, Drops: 5, IntervalMs: 5000}
+
+		// The run is cancelled while waiting between the first and second drop
+		runCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+		defer cancel()
+		result, err := engine.ProcessAction(runCtx, "auto_drop", slow)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("auto_drop error = %v, want context.DeadlineExceeded", err)
+		}
+		resp, ok := result.(PlinkoAutoDropResponse)
+		if !ok || resp.DropsCompleted != 1 || len(resp.DropResults) != 1 || resp.StopReason != PlinkoStopError {
+			t.Fatalf("auto_drop = %+v, want the first drop with stop reason %q", result, PlinkoStopError)
+		}
+
+		balance, _ := client.Get(ctx, REDIS_KEY_USER_BALANCE+"user4").Float64()
+		if math.Abs(balance-(1000+resp.NetProfit.Float64())) > 1e-6 {
+			t.Errorf("balance = %.2f, want %.2f", balance, 1000+resp.NetProfit.Float64())
+		}
+		if client.Exists(ctx, REDIS_KEY_PLINKO_AUTO_DROP+"user4").Val() != 0 {
+			t.Error("auto-drop lock should be released")
+		}
+	})
+
+	t.Run("concurrent run is rejected", func(t *testing.T) {
+		client.Set(ctx, REDIS_KEY_PLINKO_AUTO_DROP+"user1", "{}", 0)
+		defer client.Del(ctx, REDIS_KEY_PLINKO_AUTO_DROP+"user1")
+
+		if _, err := engine.ProcessAction(ctx, "auto_drop", req); !errors.Is(err, ErrAutoDropInProgress) {
+			t.Errorf("expected ErrAutoDropInProgress, got %v", err)
+		}
+	})
+}
+
+func TestSummarisePlinkoDrops(t *testing.T) {
+	drops := []PlinkoGameState{
+		{LandingSlot: 3, Multiplier: 1.4},
+		{LandingSlot: 5, Multiplier: 1.2},
+		{LandingSlot: 5, Multiplier: 1.2},
+		{LandingSlot: 3, Multiplier: 1.4},
+		{LandingSlot: 0, Multiplier: 16},
+	}
+
+	stats := summarisePlinkoDrops(drops)
+	if stats.TotalDrops != 5 || stats.MostCommonSlot != 3 {
+		t.Errorf("stats = %+v, want 5 drops with slot 3 most common", stats)
+	}
+	if stats.AvgLandingSlot != 3.2 || math.Abs(stats.AvgMultiplier-4.24) > 1e-9 {
+		t.Errorf("averages = %.2f slot, %.2fx; want 3.2 and 4.24x", stats.AvgLandingSlot, stats.AvgMultiplier)
+	}
+	if stats.BestMultiplier != 16 || stats.WorstMultiplier != 1.2 {
+		t.Errorf("best/worst = %.2fx/%.2fx, want 16x/1.2x", stats.BestMultiplier, stats.WorstMultiplier)
+	}
+
+	if empty := summarisePlinkoDrops(nil); empty != (PlinkoDropStats{}) {
+		t.Errorf("stats of no drops = %+v, want zero", empty)
+	}
+}
@@ -0,0 +1,139 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"time"
+)
+
+// PlinkoHistoryEntry is one drop in a player's Plinko history. The path is
+// reduced to its length to keep responses small.
+type PlinkoHistoryEntry struct {
+	GameID      string     `json:"game_id"`
+	Risk        PlinkoRisk `json:"risk"`
+	Rows        int        `json:"rows"`
+	PathLength  int        `json:"path_length"`
+	LandingSlot int        `json:"landing_slot"`
+	Multiplier  float64    `json:"multiplier"`
+	Payout      Amount     `json:"payout"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// PlinkoStatsRequest asks for the statistics of a user's recent drops
+type PlinkoStatsRequest struct {
+	UserID string `json:"user_id"`
+}
+
+// PlinkoDropStats summarises a set of drops
+type PlinkoDropStats struct {
+	TotalDrops      int     `json:"total_drops"`
+	AvgLandingSlot  float64 `json:"avg_landing_slot"`
+	MostCommonSlot  int     `json:"most_common_slot"`
+	AvgMultiplier   float64 `json:"avg_multiplier"`
+	BestMultiplier  float64 `json:"best_multiplier"`
+	WorstMultiplier float64 `json:"worst_multiplier"`
+}
+
+// PlinkoStats summarises a user's recent drops, overall and per risk level
+type PlinkoStats struct {
+	PlinkoDropStats
+	ByRisk map[PlinkoRisk]PlinkoDropStats `json:"by_risk"`
+}
+
+// loadPlinkoHistory decodes a page of the user's recent drops, newest first
+func (p *PlinkoEngine) loadPlinkoHistory(ctx context.Context, userID string, page, limit int) ([]PlinkoGameState, error) {
+	games, err := loadGameHistory(ctx, p.redisClient, REDIS_KEY_PLINKO_HISTORY+userID, REDIS_KEY_PLINKO_GAME, page, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]PlinkoGameState, 0, len(games))
+	for _, gameJSON := range games {
+		var gameState PlinkoGameState
+		if err := json.Unmarshal([]byte(gameJSON), &gameState); err != nil {
+			continue
+		}
+		states = append(states, gameState)
+	}
+	return states, nil
+}
+
+// history returns a page of the user's recent Plinko drops, newest first
+func (p *PlinkoEngine) history(ctx context.Context, req GameHistoryRequest) ([]PlinkoHistoryEntry, error) {
+	states, err := p.loadPlinkoHistory(ctx, req.UserID, req.Page, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]PlinkoHistoryEntry, 0, len(states))
+	for _, gameState := range states {
+		entries = append(entries, PlinkoHistoryEntry{
+			GameID:      gameState.GameID,
+			Risk:        gameState.Risk,
+			Rows:        gameState.Rows,
+			PathLength:  len(gameState.Path),
+			LandingSlot: gameState.LandingSlot,
+			Multiplier:  gameState.Multiplier,
+			Payout:      gameState.Payout,
+			CreatedAt:   gameState.CreatedAt,
+		})
+	}
+	return entries, nil
+}
+
+// stats summarises every drop still in the user's history
+func (p *PlinkoEngine) stats(ctx context.Context, userID string) (*PlinkoStats, error) {
+	states, err := p.loadPlinkoHistory(ctx, userID, 1, GAME_HISTORY_SIZE)
+	if err != nil {
+		return nil, err
+	}
+
+	byRisk := make(map[PlinkoRisk][]PlinkoGameState)
+	for _, gameState := range states {
+		byRisk[gameState.Risk] = append(byRisk[gameState.Risk], gameState)
+	}
+
+	stats := &PlinkoStats{
+		PlinkoDropStats: summarisePlinkoDrops(states),
+		ByRisk:          make(map[PlinkoRisk]PlinkoDropStats, len(byRisk)),
+	}
+	for risk, drops := range byRisk {
+		stats.ByRisk[risk] = summarisePlinkoDrops(drops)
+	}
+	return stats, nil
+}
+
+// summarisePlinkoDrops computes the statistics of a set of drops. Ties for the
+// most common slot go to the lowest slot.
+func summarisePlinkoDrops(drops []PlinkoGameState) PlinkoDropStats {
+	if len(drops) == 0 {
+		return PlinkoDropStats{}
+	}
+
+	stats := PlinkoDropStats{
+		TotalDrops:      len(drops),
+		BestMultiplier:  math.Inf(-1),
+		WorstMultiplier: math.Inf(1),
+	}
+	slotCounts := make(map[int]int)
+	var slotSum int
+	var multiplierSum float64
+	for _, drop := range drops {
+		slotSum += drop.LandingSlot
+		multiplierSum += drop.Multiplier
+		stats.BestMultiplier = math.Max(stats.BestMultiplier, drop.Multiplier)
+		stats.WorstMultiplier = math.Min(stats.WorstMultiplier, drop.Multiplier)
+		slotCounts[drop.LandingSlot]++
+	}
+
+	for slot, count := range slotCounts {
+		best := slotCounts[stats.MostCommonSlot]
+		if count > best || (count == best && slot < stats.MostCommonSlot) {
+			stats.MostCommonSlot = slot
+		}
+	}
+	stats.AvgLandingSlot = float64(slotSum) / float64(len(drops))
+	stats.AvgMultiplier = multiplierSum / float64(len(drops))
+	return stats
+}
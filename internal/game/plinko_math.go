@@ -0,0 +1,84 @@
+package game
+
+import (
+	"log"
+	"math"
+)
+
+const (
+	PLINKO_MIN_ROWS = 8
+	PLINKO_MAX_ROWS = 16
+)
+
+// plinkoRowProbabilities are the landing chances of one row count
+type plinkoRowProbabilities struct {
+	slots    []float64 // Indexed by landing slot
+	leftHalf float64   // Chance of landing in slots 0..rows/2-1
+}
+
+// ComputeBinomialProbabilities returns the chance of a ball landing in each
+// of the rows+1 slots: row `rows` of Pascal's triangle divided by 2^rows
+func ComputeBinomialProbabilities(rows int) []float64 {
+	if rows < 0 {
+		return nil
+	}
+
+	// Each row is built in place from the one above it, right to left
+	coefficients := make([]float64, rows+1)
+	coefficients[0] = 1
+	for row := 1; row <= rows; row++ {
+		for k := row; k > 0; k-- {
+			coefficients[k] += coefficients[k-1]
+		}
+	}
+
+	total := math.Pow(2, float64(rows))
+	for k := range coefficients {
+		coefficients[k] /= total
+	}
+	return coefficients
+}
+
+func newPlinkoRowProbabilities(rows int) *plinkoRowProbabilities {
+	probabilities := &plinkoRowProbabilities{slots: ComputeBinomialProbabilities(rows)}
+	for slot := 0; slot < rows/2; slot++ {
+		probabilities.leftHalf += probabilities.slots[slot]
+	}
+	return probabilities
+}
+
+// init precomputes the landing chances of every row count from
+// PLINKO_MIN_ROWS to PLINKO_MAX_ROWS
+func (p *PlinkoEngine) init() {
+	for rows := PLINKO_MIN_ROWS; rows <= PLINKO_MAX_ROWS; rows++ {
+		p.probabilities.Store(rows, newPlinkoRowProbabilities(rows))
+	}
+	log.Printf("[PLINKO] Precomputed slot probabilities for %d to %d rows", PLINKO_MIN_ROWS, PLINKO_MAX_ROWS)
+}
+
+// rowProbabilities returns the precomputed landing chances of a row count,
+// computing them on demand before Start or for other row counts
+func (p *PlinkoEngine) rowProbabilities(rows int) *plinkoRowProbabilities {
+	if probabilities, ok := p.probabilities.Load(rows); ok {
+		return probabilities.(*plinkoRowProbabilities)
+	}
+	return newPlinkoRowProbabilities(rows)
+}
+
+// GetSlotProbability returns the chance of a ball dropped through rows rows
+// landing in slot, or 0 for a slot that does not exist
+func (p *PlinkoEngine) GetSlotProbability(rows, slot int) float64 {
+	if slot < 0 || slot > rows {
+		return 0
+	}
+	return p.rowProbabilities(rows).slots[slot]
+}
+
+// LeftHalfProbability returns the chance of a ball landing in the left half
+// of the slots, 0..rows/2-1. The right half is its mirror image.
+func (p *PlinkoEngine) LeftHalfProbability(rows int) float64 {
+	if rows < 0 {
+		return 0
+	}
+	return p.rowProbabilities(rows).leftHalf
+}
@@ -0,0 +1,75 @@
+package game
+
+import (
+	"math"
+	"testing"
+)
+
+func TestComputeBinomialProbabilities(t *testing.T) {
+	for rows := PLINKO_MIN_ROWS; rows <= PLINKO_MAX_ROWS; rows++ {
+		probabilities := ComputeBinomialProbabilities(rows)
+		if len(probabilities) != rows+1 {
+			t.Fatalf("%d rows: got %d probabilities, want %d", rows, len(probabilities), rows+1)
+		}
+
+		total := 0.0
+		for slot, probability := range probabilities {
+			total += probability
+			if math.Abs(probability-binomialProbability(rows, slot)) > 1e-12 {
+				t.Errorf("%d rows, slot %d: got %v, want %v", rows, slot, probability, binomialProbability(rows, slot))
+			}
+		}
+		if math.Abs(total-1.0) > 1e-9 {
+			t.Errorf("probabilities for %d rows sum to %v, want 1", rows, total)
+		}
+	}
+
+	if got := ComputeBinomialProbabilities(2); got[0] != 0.25 || got[1] != 0.5 || got[2] != 0.25 {
+		t.Errorf("ComputeBinomialProbabilities(2) = %v, want [0.25 0.5 0.25]", got)
+	}
+}
+
+func TestPlinkoEngine_SlotProbabilities(t *testing.T) {
+	engine := &PlinkoEngine{}
+	engine.init()
+
+	for rows := PLINKO_MIN_ROWS; rows <= PLINKO_MAX_ROWS; rows++ {
+		if _, ok := engine.probabilities.Load(rows); !ok {
+			t.Errorf("probabilities for %d rows were not precomputed", rows)
+		}
+	}
+
+	if got := engine.GetSlotProbability(16, 8); math.Abs(got-binomialProbability(16, 8)) > 1e-12 {
+		t.Errorf("GetSlotProbability(16, 8) = %v, want %v", got, binomialProbability(16, 8))
+	}
+	if got := engine.GetSlotProbability(16, 17); got != 0 {
+		t.Errorf("GetSlotProbability(16, 17) = %v, want 0", got)
+	}
+
+	// The middle slot of an even row count belongs to neither half
+	want := (1 - binomialProbability(16, 8)) / 2
+	if got := engine.LeftHalfProbability(16); math.Abs(got-want) > 1e-12 {
+		t.Errorf("LeftHalfProbability(16) = %v, want %v", got, want)
+	}
+	if got := engine.ComputeEV(PlinkoRiskLow, 12).LeftHalfProbability; got != engine.LeftHalfProbability(12) {
+		t.Errorf("EV left half probability = %v, want %v", got, engine.LeftHalfProbability(12))
+	}
+}
+
+// BenchmarkSlotProbability_Precomputed and BenchmarkSlotProbability_OnDemand
+// compare a precomputed lookup with computing the row on every call
+func BenchmarkSlotProbability_Precomputed(b *testing.B) {
+	engine := &PlinkoEngine{}
+	engine.init()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		engine.GetSlotProbability(16, i%17)
+	}
+}
+
+func BenchmarkSlotProbability_OnDemand(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_ = ComputeBinomialProbabilities(16)[i%17]
+	}
+}
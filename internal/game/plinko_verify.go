@@ -0,0 +1,140 @@
+package game
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrPlinkoGameExpired is returned when verifying a drop that settled more
+// than PLINKO_GAME_TTL ago. Drops are not stored anywhere else, so it can no
+// longer be replayed.
+var ErrPlinkoGameExpired = errors.New("game is too old to verify")
+
+// PlinkoVerifyRequest asks for a stored drop to be replayed from its seeds
+type PlinkoVerifyRequest struct {
+	GameID  string
+	Verbose bool // Include the HMAC computed for every row
+}
+
+// PlinkoPathStep is the HMAC-SHA256 computation that bounced the ball at one
+// row. It is keyed with the game seed, HMAC-SHA256(server_seed, "client_seed:nonce");
+// the ball goes right when the first 64 bits of the output are at least 2^63.
+type PlinkoPathStep struct {
+	Step       int    `json:"step"`
+	Input      string `json:"input"`
+	HMACOutput string `json:"hmac_output"`
+	Direction  string `json:"direction"` // "left" or "right"
+}
+
+// PlinkoVerification compares a drop with the one its seeds produce
+type PlinkoVerification struct {
+	Valid             bool             `json:"valid"`
+	ComputedPath      []int            `json:"computed_path"`
+	StoredPath        []int            `json:"stored_path"`
+	ComputedSlot      int              `json:"computed_slot"`
+	StoredSlot        int              `json:"stored_slot"`
+	MultiplierMatches bool             `json:"multiplier_matches"`
+	SignatureValid    bool             `json:"signature_valid"` // The stored game still carries the signature it was stored with
+	GameSeed          string           `json:"game_seed,omitempty"`
+	StepByStep        []PlinkoPathStep `json:"step_by_step,omitempty"`
+}
+
+// VerifyPlinkoGame replays a drop from its seeds, recording each row's HMAC.
+// It is valid when the replayed path and landing slot match the stored ones.
+func VerifyPlinkoGame(serverSeed, clientSeed string, nonce, rows int, path []int, landingSlot int) PlinkoVerification {
+	seed := FairSeed(serverSeed, clientSeed, nonce)
+
+	// Repeats generatePath, recording each hash
+	computed := make([]int, rows)
+	slot := 0
+	steps := make([]PlinkoPathStep, rows)
+	for i := 0; i < rows; i++ {
+		input := "bernoulli:" + strconv.Itoa(i)
+		h := hmac.New(sha256.New, seed)
+		h.Write([]byte(input))
+		sum := h.Sum(nil)
+
+		direction := "left"
+		if FairBernoulli(seed, i) {
+			computed[i] = 1
+			slot++
+			direction = "right"
+		}
+		steps[i] = PlinkoPathStep{Step: i, Input: input, HMACOutput: hex.EncodeToString(sum), Direction: direction}
+	}
+
+	return PlinkoVerification{
+		Valid:        slices.Equal(computed, path) && slot == landingSlot,
+		ComputedPath: computed,
+		StoredPath:   path,
+		ComputedSlot: slot,
+		StoredSlot:   landingSlot,
+		GameSeed:     hex.EncodeToString(seed),
+		StepByStep:   steps,
+	}
+}
+
+// verify replays a drop still stored in Redis. Drops are kept for
+// PLINKO_GAME_TTL; older ones report ErrPlinkoGameExpired.
+func (p *PlinkoEngine) verify(ctx context.Context, req PlinkoVerifyRequest) (PlinkoVerification, error) {
+	gameJSON, err := p.redisClient.Get(ctx, REDIS_KEY_PLINKO_GAME+req.GameID).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return PlinkoVerification{}, p.missingGameError(ctx, req.GameID)
+	}
+	if err != nil {
+		return PlinkoVerification{}, err
+	}
+	var gameState PlinkoGameState
+	if err := json.Unmarshal(gameJSON, &gameState); err != nil {
+		return PlinkoVerification{}, err
+	}
+
+	verification := VerifyPlinkoGame(gameState.ServerSeed, gameState.ClientSeed, gameState.Nonce,
+		gameState.Rows, gameState.Path, gameState.LandingSlot)
+	verification.MultiplierMatches = p.getMultiplier(gameState.Risk, verification.ComputedSlot, gameState.Rows) == gameState.Multiplier
+	verification.SignatureValid = gameState.ResultSignature != "" &&
+		VerifyResultSignature(string(gameJSON), gameState.ResultSignature, p.signingKey)
+	if !req.Verbose {
+		verification.GameSeed = ""
+		verification.StepByStep = nil
+	}
+	return verification, nil
+}
+
+// missingGameError tells a drop that has expired from one that never settled.
+// Game IDs end with the time the drop was played; a deferred drop still
+// waiting to be revealed has not settled yet.
+func (p *PlinkoEngine) missingGameError(ctx context.Context, gameID string) error {
+	playedAt, ok := plinkoPlayedAt(gameID)
+	if !ok || time.Since(playedAt) < PLINKO_GAME_TTL {
+		return ErrGameNotFound
+	}
+	if pending, _ := p.redisClient.Exists(ctx, REDIS_KEY_PLINKO_PENDING+gameID).Result(); pending > 0 {
+		return ErrGameNotFound
+	}
+	return ErrPlinkoGameExpired
+}
+
+// plinkoPlayedAt returns the time encoded in a game ID of the form
+// PLINKO-<user>-<unix nanoseconds>
+func plinkoPlayedAt(gameID string) (time.Time, bool) {
+	separator := strings.LastIndexByte(gameID, '-')
+	if !strings.HasPrefix(gameID, "PLINKO-") || separator < 0 {
+		return time.Time{}, false
+	}
+	nanos, err := strconv.ParseInt(gameID[separator+1:], 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(0, nanos), true
+}
@@ -0,0 +1,54 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestVerifyPlinkoGame(t *testing.T) {
+	path, slot := new(PlinkoEngine).generatePath("server-seed", "client-seed", 3, 12)
+
+	verification := VerifyPlinkoGame("server-seed", "client-seed", 3, 12, path, slot)
+	if !verification.Valid || !slices.Equal(verification.ComputedPath, path) || verification.ComputedSlot != slot {
+		t.Fatalf("VerifyPlinkoGame() = %+v, want the generated path %v to slot %d", verification, path, slot)
+	}
+	if len(verification.StepByStep) != 12 {
+		t.Fatalf("StepByStep has %d steps, want one per row", len(verification.StepByStep))
+	}
+	for i, step := range verification.StepByStep {
+		want := map[int]string{0: "left", 1: "right"}[path[i]]
+		if step.Step != i || step.Input != fmt.Sprintf("bernoulli:%d", i) || step.Direction != want || len(step.HMACOutput) != 64 {
+			t.Errorf("step %d = %+v, want bernoulli:%d going %s", i, step, i, want)
+		}
+	}
+
+	tampered := slices.Clone(path)
+	tampered[0] = 1 - tampered[0]
+	if verification := VerifyPlinkoGame("server-seed", "client-seed", 3, 12, tampered, slot); verification.Valid {
+		t.Errorf("VerifyPlinkoGame() of a changed path = %+v, want it invalid", verification)
+	}
+}
+
+func TestPlinkoEngine_VerifyMissingGame(t *testing.T) {
+	client := newTestRedis(t)
+	ctx := context.Background()
+	engine := NewPlinkoEngine(client, nil)
+
+	expired := fmt.Sprintf("PLINKO-user-1-%d", time.Now().Add(-PLINKO_GAME_TTL-time.Minute).UnixNano())
+	pending := fmt.Sprintf("PLINKO-user-1-%d", time.Now().Add(-2*PLINKO_GAME_TTL).UnixNano())
+	client.Set(ctx, REDIS_KEY_PLINKO_PENDING+pending, "{}", 0)
+
+	for gameID, want := range map[string]error{
+		"PLINKO-unknown": ErrGameNotFound,
+		fmt.Sprintf("PLINKO-user-1-%d", time.Now().UnixNano()): ErrGameNotFound,
+		pending: ErrGameNotFound, // Not revealed yet
+		expired: ErrPlinkoGameExpired,
+	} {
+		if _, err := engine.verify(ctx, PlinkoVerifyRequest{GameID: gameID}); err != want {
+			t.Errorf("verify(%s) error = %v, want %v", gameID, err, want)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	REDIS_KEY_PREFERENCES = "prefs:"
+
+	// MIN_AUTO_CASHOUT is the lowest default auto-cashout a player may save
+	MIN_AUTO_CASHOUT = 1.01
+)
+
+var ErrInvalidPreferences = errors.New("invalid preferences")
+
+// UserPreferences are a player's saved defaults for each game. Zero values
+// mean no default.
+type UserPreferences struct {
+	Aviator *AviatorPreferences `json:"aviator,omitempty"`
+	Mines   *MinesPreferences   `json:"mines,omitempty"`
+	Plinko  *PlinkoPreferences  `json:"plinko,omitempty"`
+	Dice    *DicePreferences    `json:"dice,omitempty"`
+}
+
+type AviatorPreferences struct {
+	DefaultAmount      float64 `json:"default_amount"`
+	DefaultAutoCashout float64 `json:"default_auto_cashout"`
+}
+
+type MinesPreferences struct {
+	DefaultMineCount int     `json:"default_mine_count"`
+	DefaultAmount    float64 `json:"default_amount"`
+}
+
+type PlinkoPreferences struct {
+	DefaultRisk   PlinkoRisk `json:"default_risk"`
+	DefaultRows   int        `json:"default_rows"`
+	DefaultAmount float64    `json:"default_amount"`
+}
+
+type DicePreferences struct {
+	DefaultTarget float64 `json:"default_target"`
+	DefaultIsOver bool    `json:"default_is_over"`
+}
+
+// Validate checks every default that is set. Mine counts are checked against
+// the default grid.
+func (p UserPreferences) Validate() error {
+	var amounts []float64
+	if p.Aviator != nil {
+		if p.Aviator.DefaultAutoCashout != 0 && p.Aviator.DefaultAutoCashout < MIN_AUTO_CASHOUT {
+			return preferenceError("aviator default_auto_cashout must be at least %.2f", MIN_AUTO_CASHOUT)
+		}
+		amounts = append(amounts, p.Aviator.DefaultAmount)
+	}
+	if p.Mines != nil {
+		if count := p.Mines.DefaultMineCount; count != 0 && (count < MINES_MIN_COUNT || count > MINES_MAX_COUNT) {
+			return preferenceError("mines default_mine_count must be between %d and %d", MINES_MIN_COUNT, MINES_MAX_COUNT)
+		}
+		amounts = append(amounts, p.Mines.DefaultAmount)
+	}
+	if p.Plinko != nil {
+		switch p.Plinko.DefaultRisk {
+		case "", PlinkoRiskLow, PlinkoRiskMedium, PlinkoRiskHigh:
+		default:
+			return preferenceError("plinko default_risk must be low, medium or high")
+		}
+		if rows := p.Plinko.DefaultRows; rows != 0 && rows != 8 && rows != 12 && rows != 16 {
+			return preferenceError("plinko default_rows must be 8, 12 or 16")
+		}
+		amounts = append(amounts, p.Plinko.DefaultAmount)
+	}
+	if p.Dice != nil && p.Dice.DefaultTarget != 0 {
+		if target := p.Dice.DefaultTarget; target < DICE_MIN_VALUE || target > DICE_MAX_VALUE {
+			return preferenceError("dice default_target must be between %.2f and %.2f", DICE_MIN_VALUE, DICE_MAX_VALUE)
+		}
+	}
+
+	for _, amount := range amounts {
+		if amount == 0 {
+			continue
+		}
+		if message := validateBetAmount(amount); message != "" {
+			return preferenceError("default_amount: %s", message)
+		}
+	}
+	return nil
+}
+
+func preferenceError(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidPreferences, fmt.Sprintf(format, args...))
+}
+
+// SavePreferences validates and stores a user's preferences, replacing any
+// saved before. They do not expire.
+func SavePreferences(ctx context.Context, redisClient *redis.Client, userID string, prefs UserPreferences) error {
+	if err := prefs.Validate(); err != nil {
+		return err
+	}
+	data, err := json.Marshal(prefs)
+	if err != nil {
+		return err
+	}
+	return redisClient.Set(ctx, REDIS_KEY_PREFERENCES+userID, data, 0).Err()
+}
+
+// LoadPreferences returns a user's saved preferences, or nil if there are none
+func LoadPreferences(ctx context.Context, redisClient *redis.Client, userID string) (*UserPreferences, error) {
+	data, err := redisClient.Get(ctx, REDIS_KEY_PREFERENCES+userID).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var prefs UserPreferences
+	if err := json.Unmarshal(data, &prefs); err != nil {
+		return nil, fmt.Errorf("decode preferences of %s: %w", userID, err)
+	}
+	return &prefs, nil
+}
@@ -0,0 +1,82 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestUserPreferences_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		prefs   UserPreferences
+		wantErr bool
+	}{
+		{name: "empty", prefs: UserPreferences{}},
+		{name: "full", prefs: UserPreferences{
+			Aviator: &AviatorPreferences{DefaultAmount: 100, DefaultAutoCashout: 2},
+			Mines:   &MinesPreferences{DefaultMineCount: 5, DefaultAmount: 50},
+			Plinko:  &PlinkoPreferences{DefaultRisk: PlinkoRiskMedium, DefaultRows: 16, DefaultAmount: 25},
+			Dice:    &DicePreferences{DefaultTarget: 50, DefaultIsOver: true},
+		}},
+		{name: "auto-cashout below 1.01", prefs: UserPreferences{Aviator: &AviatorPreferences{DefaultAutoCashout: 1.005}}, wantErr: true},
+		{name: "auto-cashout at 1.01", prefs: UserPreferences{Aviator: &AviatorPreferences{DefaultAutoCashout: 1.01}}},
+		{name: "no mines", prefs: UserPreferences{Mines: &MinesPreferences{DefaultMineCount: -1}}, wantErr: true},
+		{name: "too many mines", prefs: UserPreferences{Mines: &MinesPreferences{DefaultMineCount: MINES_MAX_COUNT + 1}}, wantErr: true},
+		{name: "unknown risk", prefs: UserPreferences{Plinko: &PlinkoPreferences{DefaultRisk: "extreme"}}, wantErr: true},
+		{name: "unsupported rows", prefs: UserPreferences{Plinko: &PlinkoPreferences{DefaultRows: 10}}, wantErr: true},
+		{name: "dice target out of range", prefs: UserPreferences{Dice: &DicePreferences{DefaultTarget: 150}}, wantErr: true},
+		{name: "amount above the maximum bet", prefs: UserPreferences{Mines: &MinesPreferences{DefaultAmount: MAX_BET_AMOUNT + 1}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.prefs.Validate()
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidPreferences) {
+				t.Errorf("Validate() error = %v, want ErrInvalidPreferences", err)
+			}
+		})
+	}
+}
+
+func TestSavePreferences(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	ctx := context.Background()
+
+	if prefs, err := LoadPreferences(ctx, client, "user1"); err != nil || prefs != nil {
+		t.Fatalf("LoadPreferences() before saving = %+v, %v; want nil", prefs, err)
+	}
+
+	saved := UserPreferences{
+		Aviator: &AviatorPreferences{DefaultAmount: 100, DefaultAutoCashout: 2},
+		Plinko:  &PlinkoPreferences{DefaultRisk: PlinkoRiskMedium, DefaultRows: 16, DefaultAmount: 25},
+	}
+	if err := SavePreferences(ctx, client, "user1", saved); err != nil {
+		t.Fatalf("SavePreferences() error: %v", err)
+	}
+	if ttl := mr.TTL(REDIS_KEY_PREFERENCES + "user1"); ttl != 0 {
+		t.Errorf("preferences TTL = %v, want none", ttl)
+	}
+
+	loaded, err := LoadPreferences(ctx, client, "user1")
+	if err != nil || loaded == nil || !reflect.DeepEqual(*loaded, saved) {
+		t.Fatalf("LoadPreferences() = %+v, %v; want %+v", loaded, err, saved)
+	}
+
+	invalid := UserPreferences{Aviator: &AviatorPreferences{DefaultAutoCashout: 1}}
+	if err := SavePreferences(ctx, client, "user1", invalid); !errors.Is(err, ErrInvalidPreferences) {
+		t.Fatalf("SavePreferences(invalid) error = %v, want ErrInvalidPreferences", err)
+	}
+	if loaded, _ := LoadPreferences(ctx, client, "user1"); !reflect.DeepEqual(*loaded, saved) {
+		t.Errorf("invalid preferences replaced the saved ones: %+v", loaded)
+	}
+}
@@ -4,15 +4,23 @@ import (
 	"crypto/hmac"
 	"crypto/rand"
 	"crypto/sha256"
+	"encoding/binary"
 	"encoding/hex"
 	"fmt"
 	"math/big"
+	"strconv"
+	"time"
 )
 
 const (
 	MIN_MULTIPLIER = 1.00
 	MAX_MULTIPLIER = 1000000.00
 	HOUSE_EDGE     = 0.01 // 1%
+
+	// ROUND_COMMITMENT_KEY keys the HMAC that commits to a crash round's seed.
+	// It is public: it only keeps the commitment from being SHA256 of the seed,
+	// which the reverse hash chain makes equal to the previous round's seed.
+	ROUND_COMMITMENT_KEY = "aviator:round-commitment"
 )
 
 // HashAndMapToMultiplier generates a provably fair crash multiplier
@@ -56,6 +64,42 @@ func HashAndMapToMultiplier(serverSeed, clientSeed string, nonce int) float64 {
 	return finalMultiplier
 }
 
+// FairSeed derives the per-game seed that FairShuffle and FairBernoulli expand
+func FairSeed(serverSeed, clientSeed string, nonce int) []byte {
+	h := hmac.New(sha256.New, []byte(serverSeed))
+	h.Write([]byte(fmt.Sprintf("%s:%d", clientSeed, nonce)))
+	return h.Sum(nil)
+}
+
+// fairUint64 returns the first 64 bits of HMAC-SHA256(seed, label:index)
+func fairUint64(seed []byte, label string, index int) uint64 {
+	h := hmac.New(sha256.New, seed)
+	h.Write([]byte(label + ":" + strconv.Itoa(index)))
+	return binary.BigEndian.Uint64(h.Sum(nil)[:8])
+}
+
+// FairShuffle returns a provably fair permutation of [0, n) using a
+// Fisher-Yates shuffle driven by one HMAC-SHA256 hash per swap. The modulo
+// bias of reducing 64 bits to at most n choices is below n/2^64.
+func FairShuffle(seed []byte, n int) []int {
+	perm := make([]int, n)
+	for i := range perm {
+		perm[i] = i
+	}
+
+	for i := n - 1; i > 0; i-- {
+		j := int(fairUint64(seed, "shuffle", i) % uint64(i+1))
+		perm[i], perm[j] = perm[j], perm[i]
+	}
+	return perm
+}
+
+// FairBernoulli returns a provably fair coin flip for the given step,
+// true when the full 64-bit hash output falls in the upper half of its range
+func FairBernoulli(seed []byte, step int) bool {
+	return fairUint64(seed, "bernoulli", step) >= 1<<63
+}
+
 // GenerateSeed creates a cryptographically secure random seed
 func GenerateSeed() string {
 	b := make([]byte, 32)
@@ -70,6 +114,13 @@ func HashCommitment(seed string) string {
 	return hex.EncodeToString(h.Sum(nil))
 }
 
+// CommitRoundSeed returns the commitment published for a crash round's seed
+func CommitRoundSeed(seed string) string {
+	h := hmac.New(sha256.New, []byte(ROUND_COMMITMENT_KEY))
+	h.Write([]byte(seed))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
 // VerifyRound allows players to verify the fairness of a round
 func VerifyRound(serverSeed, clientSeed string, nonce int, claimedMultiplier float64) bool {
 	calculatedMultiplier := HashAndMapToMultiplier(serverSeed, clientSeed, nonce)
@@ -80,3 +131,30 @@ func VerifyRound(serverSeed, clientSeed string, nonce int, claimedMultiplier flo
 	}
 	return diff < 0.01
 }
+
+// RoundRecord is the revealed provably fair data of a completed round
+type RoundRecord struct {
+	RoundID         string    `json:"round_id"`
+	ServerSeed      string    `json:"server_seed"`
+	HashCommitment  string    `json:"hash_commitment"`
+	ClientSeed      string    `json:"client_seed"`
+	Nonce           int       `json:"nonce"`
+	CrashMultiplier float64   `json:"crash_multiplier"`
+	CrashedAt       time.Time `json:"crashed_at"`
+}
+
+// VerifyHashChain checks that consecutive rounds form a reverse hash chain:
+// each round's commitment matches its own seed, and the SHA256 of that seed is
+// the seed revealed by the round before it. It returns the index of the first
+// round that breaks the chain, or -1 if the chain is valid.
+func VerifyHashChain(rounds []RoundRecord) (valid bool, brokenAt int) {
+	for i, round := range rounds {
+		if CommitRoundSeed(round.ServerSeed) != round.HashCommitment {
+			return false, i
+		}
+		if i > 0 && HashCommitment(round.ServerSeed) != rounds[i-1].ServerSeed {
+			return false, i
+		}
+	}
+	return true, -1
+}
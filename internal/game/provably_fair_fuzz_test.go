@@ -0,0 +1,91 @@
+package game
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// Run every target with `make fuzz`, or one with
+// go test ./internal/game/ -run '^$' -fuzz=FuzzHashAndMapToMultiplier -fuzztime=30s
+
+func FuzzHashAndMapToMultiplier(f *testing.F) {
+	// Cases from TestHashAndMapToMultiplier and its neighbours
+	f.Add("test_server_seed_123", "test_client_seed_456", 1)
+	f.Add("test_server_seed_123", "test_client_seed_456", 2)
+	f.Add("deterministic_test_seed", "deterministic_client_seed", 42)
+	f.Add("house_edge_test", "client", 0)
+	f.Add("", "", -1)
+
+	f.Fuzz(func(t *testing.T, serverSeed, clientSeed string, nonce int) {
+		got := HashAndMapToMultiplier(serverSeed, clientSeed, nonce)
+		if got < MIN_MULTIPLIER || got > MAX_MULTIPLIER {
+			t.Errorf("HashAndMapToMultiplier(%q, %q, %d) = %v, want within [%v, %v]",
+				serverSeed, clientSeed, nonce, got, MIN_MULTIPLIER, MAX_MULTIPLIER)
+		}
+		if again := HashAndMapToMultiplier(serverSeed, clientSeed, nonce); again != got {
+			t.Errorf("HashAndMapToMultiplier(%q, %q, %d) is not deterministic: %v then %v",
+				serverSeed, clientSeed, nonce, got, again)
+		}
+	})
+}
+
+func FuzzGenerateSeed(f *testing.F) {
+	f.Add(uint8(1))
+	f.Add(uint8(16))
+
+	f.Fuzz(func(t *testing.T, count uint8) {
+		for i := 0; i < int(count%32)+1; i++ {
+			seed := GenerateSeed()
+			if len(seed) != 64 {
+				t.Fatalf("GenerateSeed() = %q, want 64 characters", seed)
+			}
+			if _, err := hex.DecodeString(seed); err != nil {
+				t.Fatalf("GenerateSeed() = %q is not hex: %v", seed, err)
+			}
+		}
+	})
+}
+
+func FuzzVerifyRound(f *testing.F) {
+	f.Add("test_server_seed_123", "test_client_seed_456", 1)
+	f.Add("deterministic_test_seed", "deterministic_client_seed", 42)
+
+	f.Fuzz(func(t *testing.T, serverSeed, clientSeed string, nonce int) {
+		multiplier := HashAndMapToMultiplier(serverSeed, clientSeed, nonce)
+		if !VerifyRound(serverSeed, clientSeed, nonce, multiplier) {
+			t.Errorf("VerifyRound(%q, %q, %d, %v) = false for its own multiplier",
+				serverSeed, clientSeed, nonce, multiplier)
+		}
+	})
+}
+
+func FuzzMinesGeneratePositions(f *testing.F) {
+	f.Add("test_server_seed_123", "test_client_seed_456", 1, 3, 25)
+	f.Add("seed", "client", 0, 8, 9)
+	f.Add("seed", "client", 7, 15, 16)
+
+	sizes := []int{9, 16, 25}
+	engine := &MinesEngine{}
+
+	f.Fuzz(func(t *testing.T, serverSeed, clientSeed string, nonce, mineCount, gridSize int) {
+		// Fold arbitrary inputs onto a supported grid and a valid mine count
+		gridSize = sizes[uint(gridSize)%uint(len(sizes))]
+		mineCount = MINES_MIN_COUNT + int(uint(mineCount)%uint(minesMaxCount(gridSize)))
+
+		positions := engine.generateMinePositions(serverSeed, clientSeed, nonce, mineCount, gridSize)
+		if len(positions) != mineCount {
+			t.Fatalf("got %d positions, want %d", len(positions), mineCount)
+		}
+
+		seen := make(map[int]bool, len(positions))
+		for _, pos := range positions {
+			if pos < 0 || pos >= gridSize {
+				t.Errorf("position %d outside a %d tile grid", pos, gridSize)
+			}
+			if seen[pos] {
+				t.Errorf("position %d appears twice in %v", pos, positions)
+			}
+			seen[pos] = true
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package game
 
 import (
+	"fmt"
 	"testing"
 )
 
@@ -179,6 +180,49 @@ func TestHashAndMapToMultiplier_HouseEdge(t *testing.T) {
 	}
 }
 
+func TestVerifyHashChain(t *testing.T) {
+	// Build a 10-round reverse chain: each round's seed hashes to the previous one
+	terminal := GenerateSeed()
+	rounds := make([]RoundRecord, 10)
+	for i := range rounds {
+		seed := hashChainSeed(terminal, len(rounds)-1-i)
+		rounds[i] = RoundRecord{
+			RoundID:        fmt.Sprintf("R%03d", i+1),
+			ServerSeed:     seed,
+			HashCommitment: CommitRoundSeed(seed),
+		}
+	}
+
+	if valid, brokenAt := VerifyHashChain(rounds); !valid || brokenAt != -1 {
+		t.Fatalf("VerifyHashChain() = %v, %d; want true, -1", valid, brokenAt)
+	}
+
+	t.Run("replaced seed breaks the chain", func(t *testing.T) {
+		tampered := append([]RoundRecord(nil), rounds...)
+		seed := GenerateSeed()
+		tampered[6] = RoundRecord{RoundID: "R007", ServerSeed: seed, HashCommitment: CommitRoundSeed(seed)}
+
+		if valid, brokenAt := VerifyHashChain(tampered); valid || brokenAt != 6 {
+			t.Errorf("VerifyHashChain() = %v, %d; want false, 6", valid, brokenAt)
+		}
+	})
+
+	t.Run("mismatched commitment breaks the chain", func(t *testing.T) {
+		tampered := append([]RoundRecord(nil), rounds...)
+		tampered[3].HashCommitment = CommitRoundSeed("other")
+
+		if valid, brokenAt := VerifyHashChain(tampered); valid || brokenAt != 3 {
+			t.Errorf("VerifyHashChain() = %v, %d; want false, 3", valid, brokenAt)
+		}
+	})
+
+	t.Run("empty chain is valid", func(t *testing.T) {
+		if valid, _ := VerifyHashChain(nil); !valid {
+			t.Error("empty chain should be valid")
+		}
+	})
+}
+
 func BenchmarkHashAndMapToMultiplier(b *testing.B) {
 	serverSeed := "benchmark_server_seed"
 	clientSeed := "benchmark_client_seed"
@@ -203,3 +247,64 @@ func BenchmarkHashCommitment(b *testing.B) {
 		HashCommitment(seed)
 	}
 }
+
+func TestFairShuffle(t *testing.T) {
+	seed := FairSeed("server", "client", 1)
+
+	perm := FairShuffle(seed, MINES_GRID_SIZE)
+	seen := make(map[int]bool)
+	for _, v := range perm {
+		if v < 0 || v >= MINES_GRID_SIZE || seen[v] {
+			t.Fatalf("FairShuffle() = %v is not a permutation", perm)
+		}
+		seen[v] = true
+	}
+
+	if again := FairShuffle(seed, MINES_GRID_SIZE); fmt.Sprint(again) != fmt.Sprint(perm) {
+		t.Error("FairShuffle() should be deterministic for the same seed")
+	}
+}
+
+// TestFairShuffle_MinePlacementUniform checks with a chi-squared test that
+// every tile is equally likely to hold a mine across 10,000 games
+func TestFairShuffle_MinePlacementUniform(t *testing.T) {
+	const (
+		games     = 10000
+		mineCount = 5
+		// Chi-squared critical value for 24 degrees of freedom at p = 0.001
+		criticalValue = 51.18
+	)
+
+	counts := make([]int, MINES_GRID_SIZE)
+	for nonce := 0; nonce < games; nonce++ {
+		for _, tile := range FairShuffle(FairSeed("server", "client", nonce), MINES_GRID_SIZE)[:mineCount] {
+			counts[tile]++
+		}
+	}
+
+	expected := float64(games*mineCount) / MINES_GRID_SIZE
+	chiSquared := 0.0
+	for _, count := range counts {
+		diff := float64(count) - expected
+		chiSquared += diff * diff / expected
+	}
+	if chiSquared > criticalValue {
+		t.Errorf("chi-squared = %.2f exceeds %.2f, placements are not uniform: %v", chiSquared, criticalValue, counts)
+	}
+}
+
+func TestFairBernoulli(t *testing.T) {
+	const trials = 10000
+	seed := FairSeed("server", "client", 1)
+
+	rights := 0
+	for step := 0; step < trials; step++ {
+		if FairBernoulli(seed, step) {
+			rights++
+		}
+	}
+	// Four standard deviations either side of an even split
+	if rights < trials/2-200 || rights > trials/2+200 {
+		t.Errorf("FairBernoulli() returned true %d of %d times, want about half", rights, trials)
+	}
+}
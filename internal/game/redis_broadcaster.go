@@ -0,0 +1,147 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	REDIS_CHANNEL_BROADCAST = "game:broadcast"
+)
+
+// broadcastEnvelope wraps a hub message published to other instances
+type broadcastEnvelope struct {
+	SourceInstanceID string          `json:"source_instance_id"`
+	GameID           string          `json:"game_id,omitempty"` // Set when only the game's subscribers get the message
+	Message          json.RawMessage `json:"message"`
+}
+
+// outboundMessage is a message waiting to be published
+type outboundMessage struct {
+	gameID string
+	data   []byte
+}
+
+// RedisBroadcaster relays hub broadcasts between server instances via Redis Pub/Sub
+type RedisBroadcaster struct {
+	redisClient *redis.Client
+	hub         *Hub
+	instanceID  string
+	outbound    chan outboundMessage
+	ctx         context.Context
+	cancel      context.CancelFunc
+}
+
+// NewRedisBroadcaster creates a broadcaster for the hub. It is attached to
+// the hub once Start succeeds.
+func NewRedisBroadcaster(redisClient *redis.Client, hub *Hub) *RedisBroadcaster {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &RedisBroadcaster{
+		redisClient: redisClient,
+		hub:         hub,
+		instanceID:  GenerateSeed()[:16],
+		outbound:    make(chan outboundMessage, 100),
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+	return b
+}
+
+// InstanceID returns the identifier stamped on messages published by this instance
+func (b *RedisBroadcaster) InstanceID() string {
+	return b.instanceID
+}
+
+// Start subscribes to the broadcast channel, attaches the broadcaster to the
+// hub and begins relaying messages. It returns once the subscription is
+// confirmed. If it fails the hub is left broadcasting to local clients only.
+// Call it before anything broadcasts.
+func (b *RedisBroadcaster) Start() error {
+	pubsub := b.redisClient.Subscribe(b.ctx, REDIS_CHANNEL_BROADCAST)
+	if _, err := pubsub.Receive(b.ctx); err != nil {
+		pubsub.Close()
+		return err
+	}
+
+	b.hub.broadcaster = b
+	go b.listen(pubsub)
+	go b.publishLoop()
+
+	log.Printf("[BROADCAST] Relaying via Redis channel %s (instance %s)", REDIS_CHANNEL_BROADCAST, b.instanceID)
+	return nil
+}
+
+// Stop ends the subscription and publishing goroutines
+func (b *RedisBroadcaster) Stop() {
+	b.cancel()
+}
+
+// publish queues a message for other instances without blocking the caller.
+// With a gameID it only reaches that game's subscribers.
+func (b *RedisBroadcaster) publish(gameID string, message interface{}) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("[BROADCAST] Marshal error: %v", err)
+		return
+	}
+
+	select {
+	case b.outbound <- outboundMessage{gameID: gameID, data: data}:
+	default:
+		log.Println("[BROADCAST] Outbound channel full, dropping message")
+	}
+}
+
+func (b *RedisBroadcaster) publishLoop() {
+	for {
+		select {
+		case <-b.ctx.Done():
+			return
+		case message := <-b.outbound:
+			envelope, _ := json.Marshal(broadcastEnvelope{
+				SourceInstanceID: b.instanceID,
+				GameID:           message.gameID,
+				Message:          message.data,
+			})
+			if err := b.redisClient.Publish(b.ctx, REDIS_CHANNEL_BROADCAST, envelope).Err(); err != nil {
+				log.Printf("[BROADCAST] Publish error: %v", err)
+			}
+		}
+	}
+}
+
+func (b *RedisBroadcaster) listen(pubsub *redis.PubSub) {
+	defer pubsub.Close()
+
+	ch := pubsub.Channel()
+	for {
+		select {
+		case <-b.ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+
+			var envelope broadcastEnvelope
+			if err := json.Unmarshal([]byte(msg.Payload), &envelope); err != nil {
+				log.Printf("[BROADCAST] Invalid message: %v", err)
+				continue
+			}
+
+			// Skip our own publishes, they were already delivered locally
+			if envelope.SourceInstanceID == b.instanceID {
+				continue
+			}
+
+			if envelope.GameID != "" {
+				b.hub.sendToGame(envelope.GameID, envelope.Message)
+				continue
+			}
+			b.hub.broadcastLocal(envelope.Message)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// startBroadcastInstance runs a hub with a started broadcaster and one
+// connected client, as one server instance
+func startBroadcastInstance(t *testing.T, mr *miniredis.Miniredis) (*Hub, *RedisBroadcaster, *mockConn) {
+	t.Helper()
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	hub := NewHub()
+	go hub.Run()
+
+	broadcaster := NewRedisBroadcaster(client, hub)
+	if err := broadcaster.Start(); err != nil {
+		t.Fatalf("Start() error: %v", err)
+	}
+	t.Cleanup(broadcaster.Stop)
+
+	conn := &mockConn{}
+	hub.RegisterClient(conn, "user")
+	waitForClients(t, hub, 1)
+	return hub, broadcaster, conn
+}
+
+func TestRedisBroadcaster_CrossInstance(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	hubA, broadcasterA, connA := startBroadcastInstance(t, mr)
+	_, broadcasterB, connB := startBroadcastInstance(t, mr)
+
+	if broadcasterA.InstanceID() == broadcasterB.InstanceID() {
+		t.Fatal("instances should have distinct IDs")
+	}
+
+	hubA.Broadcast(map[string]string{"type": "round_start"})
+
+	deadline := time.Now().Add(time.Second)
+	for connB.count() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if connB.count() != 1 {
+		t.Fatalf("client on instance B received %d messages, want 1", connB.count())
+	}
+
+	// Give instance A time to see its own publish and make sure it is skipped
+	time.Sleep(50 * time.Millisecond)
+	if connA.count() != 1 {
+		t.Errorf("client on instance A received %d messages, want 1", connA.count())
+	}
+}
+
+func TestRedisBroadcaster_CrossInstanceGame(t *testing.T) {
+	mr := miniredis.RunT(t)
+	hubA, _, connA := startBroadcastInstance(t, mr)
+	hubB, _, connB := startBroadcastInstance(t, mr)
+	_, _, connC := startBroadcastInstance(t, mr)
+	hubA.SubscribeToGame(connA, "game1")
+	hubB.SubscribeToGame(connB, "game1")
+
+	hubA.BroadcastToGame("game1", map[string]string{"type": "tile_revealed"})
+
+	deadline := time.Now().Add(time.Second)
+	for connB.count() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if connB.count() != 1 || string(connB.last()) != `{"type":"tile_revealed"}` {
+		t.Fatalf("subscriber on instance B received %d messages, want the game message", connB.count())
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if connA.count() != 1 {
+		t.Errorf("subscriber on instance A received %d messages, want 1", connA.count())
+	}
+	if connC.count() != 0 {
+		t.Errorf("unsubscribed client received %d messages, want 0", connC.count())
+	}
+}
+
+func TestRedisBroadcaster_StartFailureStaysLocal(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	mr.Close()
+
+	hub := NewHub()
+	go hub.Run()
+	broadcaster := NewRedisBroadcaster(client, hub)
+	if err := broadcaster.Start(); err == nil {
+		t.Fatal("Start() without Redis should fail")
+	}
+	if hub.broadcaster != nil {
+		t.Error("failed broadcaster should not be attached to the hub")
+	}
+
+	conn := &mockConn{}
+	hub.RegisterClient(conn, "user")
+	waitForClients(t, hub, 1)
+	hub.Broadcast(map[string]string{"type": "update"})
+	time.Sleep(20 * time.Millisecond)
+	if conn.count() != 1 {
+		t.Errorf("client received %d messages, want 1", conn.count())
+	}
+}
+
+func TestHub_BroadcastWithoutBroadcaster(t *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+
+	conn := &mockConn{}
+	hub.RegisterClient(conn, "user")
+	waitForClients(t, hub, 1)
+
+	hub.Broadcast(map[string]string{"type": "update"})
+	time.Sleep(20 * time.Millisecond)
+
+	if conn.count() != 1 {
+		t.Errorf("client received %d messages, want 1", conn.count())
+	}
+}
@@ -0,0 +1,207 @@
+package game
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"aviator/internal/database"
+)
+
+const (
+	REDIS_KEY_REFERRAL           = "referral:"
+	REDIS_KEY_REFERRAL_FIRST_BET = "referral:first_bet:"
+	REDIS_KEY_REFERRAL_STATS     = "referral:stats:"
+	REDIS_KEY_REFERRAL_CODE      = "referral:code:"
+
+	REFERRAL_BONUS_AMOUNT     = 10.0
+	REFERRAL_TRANSACTION_TYPE = "REFERRAL_BONUS"
+	REFERRAL_CODE_LENGTH      = 8
+)
+
+var (
+	ErrReferralSelf         = errors.New("users cannot refer themselves")
+	ErrAlreadyReferred      = errors.New("user already has a referrer")
+	ErrReferralCodeNotFound = errors.New("referral code not found")
+)
+
+// ReferralRecorder persists referrals and the bonuses they pay out
+type ReferralRecorder interface {
+	TransactionRecorder
+	RecordReferral(ctx context.Context, userID, referrerID string) error
+}
+
+// ReferralStats summarises the users a referrer has brought in
+type ReferralStats struct {
+	TotalReferrals   int64   `json:"total_referrals"`
+	ActiveReferrals  int64   `json:"active_referrals"` // Referred users who have placed a bet
+	TotalEarned      float64 `json:"total_earned"`
+	PendingEarned    float64 `json:"pending_earned"` // Bonuses still owed for referred users yet to bet
+	ReferralLinkCode string  `json:"referral_link_code"`
+}
+
+// ReferralService pays referrers a bonus when a user they referred places
+// their first bet
+type ReferralService struct {
+	redisClient *redis.Client
+	hub         *Hub
+	recorder    ReferralRecorder
+	bonus       float64
+	secret      []byte
+}
+
+// NewReferralService creates the service using REFERRAL_BONUS_AMOUNT and
+// JWT_SECRET from the environment and attaches it to the hub so engines can
+// report bets. hub and recorder may be nil.
+func NewReferralService(redisClient *redis.Client, hub *Hub, recorder ReferralRecorder) *ReferralService {
+	secret := os.Getenv("JWT_SECRET")
+	if secret == "" {
+		log.Println("[REFERRAL] JWT_SECRET is not set; referral codes are predictable")
+	}
+
+	s := &ReferralService{
+		redisClient: redisClient,
+		hub:         hub,
+		recorder:    recorder,
+		bonus:       getEnvAsFloat("REFERRAL_BONUS_AMOUNT", REFERRAL_BONUS_AMOUNT),
+		secret:      []byte(secret),
+	}
+	if hub != nil {
+		hub.referrals = s
+	}
+	return s
+}
+
+// CreditBalance adds amount to a user's balance and returns the new balance
+func CreditBalance(ctx context.Context, redisClient *redis.Client, userID string, amount float64) (float64, error) {
+	return redisClient.IncrByFloat(ctx, REDIS_KEY_USER_BALANCE+userID, amount).Result()
+}
+
+// Code returns the short referral code for a user
+func (s *ReferralService) Code(userID string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(userID))
+	return hex.EncodeToString(mac.Sum(nil))[:REFERRAL_CODE_LENGTH]
+}
+
+// Register records that referrerID referred userID. A user can only be
+// referred once.
+func (s *ReferralService) Register(ctx context.Context, userID, referrerID string) error {
+	if userID == referrerID {
+		return ErrReferralSelf
+	}
+
+	ok, err := s.redisClient.SetNX(ctx, REDIS_KEY_REFERRAL+userID, referrerID, 0).Result()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrAlreadyReferred
+	}
+
+	pipe := s.redisClient.TxPipeline()
+	pipe.HIncrBy(ctx, REDIS_KEY_REFERRAL_STATS+referrerID, "total_referrals", 1)
+	pipe.Set(ctx, REDIS_KEY_REFERRAL_CODE+s.Code(userID), userID, 0)
+	pipe.Set(ctx, REDIS_KEY_REFERRAL_CODE+s.Code(referrerID), referrerID, 0)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("update referral stats for %s: %w", referrerID, err)
+	}
+
+	if s.recorder != nil {
+		if err := s.recorder.RecordReferral(ctx, userID, referrerID); err != nil {
+			log.Printf("[REFERRAL] Failed to record referral of %s by %s: %v", userID, referrerID, err)
+		}
+	}
+
+	log.Printf("[REFERRAL] %s referred by %s", userID, referrerID)
+	return nil
+}
+
+// OnBet credits the referrer's bonus the first time a referred user bets.
+// It reports whether a bonus was paid.
+func (s *ReferralService) OnBet(ctx context.Context, userID string) (bool, error) {
+	referrerID, err := s.redisClient.Get(ctx, REDIS_KEY_REFERRAL+userID).Result()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	// Only the first bet claims the marker, so the bonus is paid exactly once
+	first, err := s.redisClient.SetNX(ctx, REDIS_KEY_REFERRAL_FIRST_BET+userID, time.Now().Unix(), 0).Result()
+	if err != nil || !first {
+		return false, err
+	}
+
+	balance, err := CreditBalance(ctx, s.redisClient, referrerID, s.bonus)
+	if err != nil {
+		return false, fmt.Errorf("credit referral bonus to %s: %w", referrerID, err)
+	}
+
+	pipe := s.redisClient.TxPipeline()
+	pipe.HIncrBy(ctx, REDIS_KEY_REFERRAL_STATS+referrerID, "active_referrals", 1)
+	pipe.HIncrByFloat(ctx, REDIS_KEY_REFERRAL_STATS+referrerID, "total_earned", s.bonus)
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("[REFERRAL] Failed to update stats for %s: %v", referrerID, err)
+	}
+
+	bonus, _ := NewAmount(s.bonus)
+	s.hub.NotifyBalance(referrerID, balance, bonus, BalanceReasonReferral)
+
+	if s.recorder != nil {
+		tx := database.Transaction{
+			UserID:        referrerID,
+			Type:          REFERRAL_TRANSACTION_TYPE,
+			Amount:        s.bonus,
+			BalanceBefore: balance - s.bonus,
+			BalanceAfter:  balance,
+			ReferenceID:   userID,
+			Description:   fmt.Sprintf("Referral bonus for %s", userID),
+		}
+		if err := s.recorder.RecordTransaction(ctx, tx); err != nil {
+			log.Printf("[REFERRAL] Failed to record transaction for %s: %v", referrerID, err)
+		}
+	}
+
+	log.Printf("[REFERRAL] Credited %.2f to %s for first bet of %s", s.bonus, referrerID, userID)
+	return true, nil
+}
+
+// Stats returns a referrer's totals along with their referral code
+func (s *ReferralService) Stats(ctx context.Context, userID string) (*ReferralStats, error) {
+	code := s.Code(userID)
+	if err := s.redisClient.Set(ctx, REDIS_KEY_REFERRAL_CODE+code, userID, 0).Err(); err != nil {
+		return nil, err
+	}
+
+	values, err := s.redisClient.HGetAll(ctx, REDIS_KEY_REFERRAL_STATS+userID).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &ReferralStats{ReferralLinkCode: code}
+	stats.TotalReferrals, _ = strconv.ParseInt(values["total_referrals"], 10, 64)
+	stats.ActiveReferrals, _ = strconv.ParseInt(values["active_referrals"], 10, 64)
+	stats.TotalEarned, _ = strconv.ParseFloat(values["total_earned"], 64)
+	stats.PendingEarned = float64(stats.TotalReferrals-stats.ActiveReferrals) * s.bonus
+	return stats, nil
+}
+
+// Resolve returns the user a referral code belongs to
+func (s *ReferralService) Resolve(ctx context.Context, code string) (string, error) {
+	userID, err := s.redisClient.Get(ctx, REDIS_KEY_REFERRAL_CODE+code).Result()
+	if err == redis.Nil {
+		return "", ErrReferralCodeNotFound
+	}
+	return userID, err
+}
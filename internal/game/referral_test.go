@@ -0,0 +1,105 @@
+package game
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// referralLedger collects referrals and bonus transactions
+type referralLedger struct {
+	recordingLedger
+	referrals map[string]string
+}
+
+func (l *referralLedger) RecordReferral(ctx context.Context, userID, referrerID string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.referrals[userID] = referrerID
+	return nil
+}
+
+func TestReferralService_BonusCreditedOnce(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	ctx := context.Background()
+
+	ledger := &referralLedger{referrals: make(map[string]string)}
+	hub := NewHub()
+	referrals := NewReferralService(client, hub, ledger)
+	engine := NewDiceEngine(client, hub)
+
+	if err := referrals.Register(ctx, "friend", "referrer"); err != nil {
+		t.Fatalf("Register() error: %v", err)
+	}
+	if err := referrals.Register(ctx, "friend", "someone-else"); err != ErrAlreadyReferred {
+		t.Errorf("second Register() error = %v, want %v", err, ErrAlreadyReferred)
+	}
+	if err := referrals.Register(ctx, "referrer", "referrer"); err != ErrReferralSelf {
+		t.Errorf("self Register() error = %v, want %v", err, ErrReferralSelf)
+	}
+	if err := referrals.Register(ctx, "idle-friend", "referrer"); err != nil {
+		t.Fatalf("Register() error: %v", err)
+	}
+	if ledger.referrals["friend"] != "referrer" {
+		t.Errorf("recorded referrals = %v, want friend referred by referrer", ledger.referrals)
+	}
+
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"friend", 100.0, 0)
+	for i := 0; i < 3; i++ {
+		resp, err := engine.PlaceBet(ctx, DiceRollRequest{UserID: "friend", Amount: 1, Target: 50, IsOver: true})
+		if err != nil || !resp.(DiceRollResponse).Success {
+			t.Fatalf("PlaceBet() = %+v, %v", resp, err)
+		}
+	}
+
+	if balance, _ := client.Get(ctx, REDIS_KEY_USER_BALANCE+"referrer").Float64(); balance != REFERRAL_BONUS_AMOUNT {
+		t.Errorf("referrer balance = %.2f, want %.2f", balance, REFERRAL_BONUS_AMOUNT)
+	}
+	if len(ledger.txs) != 1 {
+		t.Fatalf("recorded %d transactions, want 1", len(ledger.txs))
+	}
+	if tx := ledger.txs[0]; tx.Type != REFERRAL_TRANSACTION_TYPE || tx.UserID != "referrer" || tx.ReferenceID != "friend" {
+		t.Errorf("transaction = %+v, want a referral bonus for referrer", tx)
+	}
+
+	stats, err := referrals.Stats(ctx, "referrer")
+	if err != nil {
+		t.Fatalf("Stats() error: %v", err)
+	}
+	if stats.TotalReferrals != 2 || stats.ActiveReferrals != 1 {
+		t.Errorf("stats = %+v, want 2 referrals with 1 active", stats)
+	}
+	if math.Abs(stats.TotalEarned-REFERRAL_BONUS_AMOUNT) > 1e-9 || math.Abs(stats.PendingEarned-REFERRAL_BONUS_AMOUNT) > 1e-9 {
+		t.Errorf("stats = %+v, want %.2f earned and pending", stats, REFERRAL_BONUS_AMOUNT)
+	}
+
+	if len(stats.ReferralLinkCode) != REFERRAL_CODE_LENGTH {
+		t.Errorf("referral code %q should be %d characters", stats.ReferralLinkCode, REFERRAL_CODE_LENGTH)
+	}
+	if userID, err := referrals.Resolve(ctx, stats.ReferralLinkCode); err != nil || userID != "referrer" {
+		t.Errorf("Resolve() = %q, %v; want referrer", userID, err)
+	}
+	if _, err := referrals.Resolve(ctx, "unknown"); err != ErrReferralCodeNotFound {
+		t.Errorf("Resolve() of an unknown code error = %v, want %v", err, ErrReferralCodeNotFound)
+	}
+}
+
+func TestReferralService_UnreferredBetPaysNothing(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	referrals := NewReferralService(client, nil, nil)
+	paid, err := referrals.OnBet(context.Background(), "loner")
+	if err != nil || paid {
+		t.Errorf("OnBet() = %v, %v; want no bonus", paid, err)
+	}
+	if mr.Exists(REDIS_KEY_REFERRAL_FIRST_BET + "loner") {
+		t.Error("first bet marker should only be set for referred users")
+	}
+}
@@ -0,0 +1,108 @@
+package game
+
+import (
+	"math"
+	"time"
+
+	"aviator/internal/database"
+)
+
+const (
+	REDIS_KEY_REPLAY     = "crash:replay:"
+	REPLAY_TICK_INTERVAL = 100 * time.Millisecond
+	REPLAY_MAX_TICKS     = 500 // 50 seconds of flight
+	REPLAY_CACHE_TTL     = 1 * time.Hour
+)
+
+// TickPoint is the multiplier a given time after takeoff
+type TickPoint struct {
+	ElapsedMs  int64   `json:"elapsed_ms"`
+	Multiplier float64 `json:"multiplier"`
+}
+
+// ReplayBet is a bet in a replayed round. CashoutAtMs is measured from
+// takeoff and is 0 for lost bets.
+type ReplayBet struct {
+	UserIDMasked      string  `json:"user_id_masked"`
+	Amount            float64 `json:"amount"`
+	CashoutMultiplier float64 `json:"cashout_multiplier,omitempty"`
+	CashoutAtMs       int64   `json:"cashout_at_ms,omitempty"`
+	Win               bool    `json:"win"`
+}
+
+// ReplayData is everything a client needs to animate a crashed round again
+type ReplayData struct {
+	RoundID         string      `json:"round_id"`
+	Ticks           []TickPoint `json:"ticks"`
+	CrashAt         float64     `json:"crash_at"`
+	TotalDurationMs int64       `json:"total_duration_ms"`
+	Bets            []ReplayBet `json:"bets"`
+}
+
+// elapsedForMultiplier inverts calculateMultiplier, returning the time after
+// takeoff at which the multiplier first reaches mult
+func elapsedForMultiplier(mult float64) time.Duration {
+	// 0.005t² + t/1.5 + (1 - mult) = 0
+	a, b, c := 0.005, 1/1.5, 1-mult
+	seconds := (-b + math.Sqrt(b*b-4*a*c)) / (2 * a)
+	if seconds < 0 {
+		seconds = 0
+	}
+
+	// Truncation in calculateMultiplier can delay the tick that shows mult
+	elapsed := time.Duration(math.Ceil(seconds*1000)) * time.Millisecond
+	for calculateMultiplier(elapsed.Seconds()) < mult {
+		elapsed += time.Millisecond
+	}
+	return elapsed
+}
+
+// maskUserID hides all but the first and last characters of a user ID
+func maskUserID(userID string) string {
+	runes := []rune(userID)
+	if len(runes) <= 2 {
+		return "***"
+	}
+	return string(runes[0]) + "***" + string(runes[len(runes)-1])
+}
+
+// BuildReplay reconstructs a crashed round's multiplier timeline, sampled
+// every REPLAY_TICK_INTERVAL and capped at REPLAY_MAX_TICKS, along with its
+// bets. The last tick is the crash unless the flight outlasts the cap.
+func BuildReplay(round database.Round, bets []database.Bet) ReplayData {
+	crashAt := math.Round((round.CrashMultiplier+round.BonusMultiplier)*100) / 100
+	duration := elapsedForMultiplier(crashAt)
+
+	var ticks []TickPoint
+	for elapsed := time.Duration(0); elapsed < duration && len(ticks) < REPLAY_MAX_TICKS; elapsed += REPLAY_TICK_INTERVAL {
+		ticks = append(ticks, TickPoint{
+			ElapsedMs:  elapsed.Milliseconds(),
+			Multiplier: calculateMultiplier(elapsed.Seconds()),
+		})
+	}
+	if len(ticks) < REPLAY_MAX_TICKS {
+		ticks = append(ticks, TickPoint{ElapsedMs: duration.Milliseconds(), Multiplier: crashAt})
+	}
+
+	replayBets := make([]ReplayBet, 0, len(bets))
+	for _, bet := range bets {
+		replayBet := ReplayBet{
+			UserIDMasked: maskUserID(bet.UserID),
+			Amount:       bet.Amount,
+			Win:          bet.Result == database.BetResultWin,
+		}
+		if replayBet.Win {
+			replayBet.CashoutMultiplier = bet.CashoutMultiplier
+			replayBet.CashoutAtMs = elapsedForMultiplier(bet.CashoutMultiplier).Milliseconds()
+		}
+		replayBets = append(replayBets, replayBet)
+	}
+
+	return ReplayData{
+		RoundID:         round.ID,
+		Ticks:           ticks,
+		CrashAt:         crashAt,
+		TotalDurationMs: duration.Milliseconds(),
+		Bets:            replayBets,
+	}
+}
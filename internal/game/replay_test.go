@@ -0,0 +1,119 @@
+package game
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"aviator/internal/database"
+)
+
+// betLog receives the bets of every round the manager settles
+type betLog chan []database.Bet
+
+func (l betLog) SaveRound(ctx context.Context, round database.Round) error { return nil }
+
+func (l betLog) SaveBets(ctx context.Context, bets []database.Bet) error {
+	l <- bets
+	return nil
+}
+
+func TestManager_ReplaySettledRound(t *testing.T) {
+	m, client := newTestManager(t)
+	saved := make(betLog, 1)
+	m.rounds = saved
+	ctx := context.Background()
+	m.setTestRound("R-replay", "BETTING")
+
+	for _, user := range []string{"alice", "bob", "carol"} {
+		client.Set(ctx, REDIS_KEY_USER_BALANCE+user, 1000.0, 0)
+	}
+	autoBet := placeTestBet(t, m, "alice", 10, 1.5)
+	manualBet := placeTestBet(t, m, "bob", 20, 0)
+	lostBet := placeTestBet(t, m, "carol", 30, 0)
+
+	m.stateMutex.Lock()
+	m.currentRound.Status = "RUNNING"
+	m.currentRound.CurrentMultiplier = 2.0
+	m.stateMutex.Unlock()
+	bets := m.loadActiveBets("R-replay")
+	m.processAutoCashouts("R-replay", 1.6, bets)
+	if resp, err := m.processCashout(ctx, CashoutRequest{UserID: "bob", BetID: manualBet}); err != nil || !resp.Success {
+		t.Fatalf("processCashout() = %+v, %v", resp, err)
+	}
+	m.processRoundEnd("R-replay", 2.5, bets)
+
+	var records []database.Bet
+	select {
+	case records = <-saved:
+	case <-time.After(time.Second):
+		t.Fatal("bets were not saved")
+	}
+	byID := make(map[string]database.Bet)
+	for _, record := range records {
+		byID[record.ID] = record
+	}
+	if bet := byID[autoBet]; bet.Result != database.BetResultWin || bet.CashoutMultiplier != 1.6 || bet.Payout != 16 || bet.AutoCashout != 1.5 {
+		t.Errorf("auto-cashout bet = %+v, want a win at 1.6x", bet)
+	}
+	if bet := byID[manualBet]; bet.Result != database.BetResultWin || bet.CashoutMultiplier != 2.0 || bet.CashedOutAt == nil {
+		t.Errorf("manual cashout bet = %+v, want a win at 2x", bet)
+	}
+	if bet := byID[lostBet]; bet.Result != database.BetResultLoss || bet.Payout != 0 || bet.CashedOutAt != nil {
+		t.Errorf("lost bet = %+v, want a loss", bet)
+	}
+
+	replay := BuildReplay(database.Round{ID: "R-replay", CrashMultiplier: 2.5, BonusMultiplier: 0.5}, records)
+	if replay.CrashAt != 3.0 {
+		t.Errorf("crash_at = %v, want the crash point plus the bonus", replay.CrashAt)
+	}
+
+	ticks := replay.Ticks
+	if first := ticks[0]; first.ElapsedMs != 0 || first.Multiplier != MIN_MULTIPLIER {
+		t.Errorf("first tick = %+v, want takeoff at 1x", first)
+	}
+	for i := 1; i < len(ticks); i++ {
+		if ticks[i].ElapsedMs <= ticks[i-1].ElapsedMs || ticks[i].Multiplier < ticks[i-1].Multiplier {
+			t.Fatalf("ticks %d and %d are not monotonic: %+v, %+v", i-1, i, ticks[i-1], ticks[i])
+		}
+		if i < len(ticks)-1 && ticks[i].ElapsedMs-ticks[i-1].ElapsedMs != REPLAY_TICK_INTERVAL.Milliseconds() {
+			t.Errorf("tick %d is %dms after the previous one, want %v", i, ticks[i].ElapsedMs-ticks[i-1].ElapsedMs, REPLAY_TICK_INTERVAL)
+		}
+	}
+	if last := ticks[len(ticks)-1]; last.Multiplier != 3.0 || last.ElapsedMs != replay.TotalDurationMs {
+		t.Errorf("last tick = %+v, want the crash at %dms", last, replay.TotalDurationMs)
+	}
+
+	if len(replay.Bets) != 3 {
+		t.Fatalf("replay has %d bets, want 3", len(replay.Bets))
+	}
+	for _, bet := range replay.Bets {
+		switch bet.UserIDMasked {
+		case "a***e", "b***b":
+			reached := calculateMultiplier(float64(bet.CashoutAtMs) / 1000)
+			early := calculateMultiplier(float64(bet.CashoutAtMs-1) / 1000)
+			if !bet.Win || reached < bet.CashoutMultiplier || early >= bet.CashoutMultiplier {
+				t.Errorf("winning bet %+v does not cash out when the multiplier first reaches its target", bet)
+			}
+		case "c***l":
+			if bet.Win || bet.CashoutAtMs != 0 || bet.Amount != 30 {
+				t.Errorf("lost bet = %+v, want no cashout", bet)
+			}
+		default:
+			t.Errorf("unexpected masked user ID %q", bet.UserIDMasked)
+		}
+	}
+}
+
+func TestBuildReplay_TickCap(t *testing.T) {
+	replay := BuildReplay(database.Round{ID: "R-long", CrashMultiplier: 100}, nil)
+	if len(replay.Ticks) != REPLAY_MAX_TICKS {
+		t.Fatalf("replay has %d ticks, want %d", len(replay.Ticks), REPLAY_MAX_TICKS)
+	}
+	if last := replay.Ticks[len(replay.Ticks)-1]; last.ElapsedMs != 49900 || last.Multiplier >= 100 {
+		t.Errorf("last tick = %+v, want the flight cut off before the crash", last)
+	}
+	if replay.TotalDurationMs <= 50000 || replay.Bets == nil {
+		t.Errorf("replay = %d ms with bets %v, want the full duration and an empty bet list", replay.TotalDurationMs, replay.Bets)
+	}
+}
@@ -0,0 +1,85 @@
+package game
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"strconv"
+)
+
+// REDIS_KEY_ROUND_HEAD holds the current round's poll headers as a hash
+const REDIS_KEY_ROUND_HEAD = "round:head:current"
+
+// RoundHead is the little a client polling for new rounds needs
+type RoundHead struct {
+	RoundID    string
+	Status     string
+	Multiplier float64
+	ETag       string // Changes with the round ID and status, not the multiplier
+}
+
+// RoundHeadETag identifies a round phase, so a client only fetches the full
+// state when the round or its status changed
+func RoundHeadETag(roundID, status string) string {
+	sum := sha256.Sum256([]byte(roundID + ":" + status))
+	return `"` + hex.EncodeToString(sum[:])[:16] + `"`
+}
+
+// FormatMultiplier formats a multiplier the way the round headers carry it
+func FormatMultiplier(multiplier float64) string {
+	return strconv.FormatFloat(multiplier, 'f', 2, 64)
+}
+
+func headOf(round *RoundState) RoundHead {
+	return RoundHead{
+		RoundID:    round.RoundID,
+		Status:     round.Status,
+		Multiplier: round.CurrentMultiplier,
+		ETag:       RoundHeadETag(round.RoundID, round.Status),
+	}
+}
+
+// publishRoundHead writes every field of the round's head when its status changes
+func (m *Manager) publishRoundHead(round *RoundState) {
+	head := headOf(round)
+	err := m.redisClient.HSet(m.ctx, REDIS_KEY_ROUND_HEAD,
+		"round_id", head.RoundID,
+		"status", head.Status,
+		"multiplier", FormatMultiplier(head.Multiplier),
+		"etag", head.ETag,
+	).Err()
+	if err != nil {
+		log.Printf("[ROUND] Failed to publish head of %s: %v", round.RoundID, err)
+	}
+}
+
+// publishRoundMultiplier updates only the multiplier on every tick
+func (m *Manager) publishRoundMultiplier(multiplier float64) {
+	m.redisClient.HSet(m.ctx, REDIS_KEY_ROUND_HEAD, "multiplier", FormatMultiplier(multiplier))
+}
+
+// RoundHead returns the current round's head. It comes from memory when this
+// instance runs the round, otherwise from the head published in Redis. ok is
+// false when there is no round.
+func (m *Manager) RoundHead(ctx context.Context) (RoundHead, bool) {
+	m.stateMutex.RLock()
+	if m.currentRound != nil {
+		head := headOf(m.currentRound)
+		m.stateMutex.RUnlock()
+		return head, true
+	}
+	m.stateMutex.RUnlock()
+
+	fields, err := m.redisClient.HGetAll(ctx, REDIS_KEY_ROUND_HEAD).Result()
+	if err != nil || fields["round_id"] == "" {
+		return RoundHead{}, false
+	}
+	multiplier, _ := strconv.ParseFloat(fields["multiplier"], 64)
+	return RoundHead{
+		RoundID:    fields["round_id"],
+		Status:     fields["status"],
+		Multiplier: multiplier,
+		ETag:       fields["etag"],
+	}, true
+}
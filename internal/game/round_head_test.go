@@ -0,0 +1,53 @@
+package game
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestManager_RoundHead(t *testing.T) {
+	m, client := newTestManager(t)
+	ctx := context.Background()
+
+	if _, ok := m.RoundHead(ctx); ok {
+		t.Error("RoundHead() before the first round should report none")
+	}
+
+	round := m.startNewRound()
+	<-m.hub.broadcast
+
+	fields := client.HGetAll(ctx, REDIS_KEY_ROUND_HEAD).Val()
+	betting := RoundHeadETag(round.RoundID, "BETTING")
+	if fields["round_id"] != round.RoundID || fields["status"] != "BETTING" || fields["multiplier"] != "1.00" || fields["etag"] != betting {
+		t.Errorf("published head = %v, want the betting round %s", fields, round.RoundID)
+	}
+
+	// Ticks only touch the multiplier
+	m.stateMutex.Lock()
+	m.currentRound.Status = "RUNNING"
+	m.currentRound.CrashMultiplier = MAX_MULTIPLIER
+	m.stateMutex.Unlock()
+	m.tick(round.RoundID, time.Now().Add(-2*time.Second), nil)
+	fields = client.HGetAll(ctx, REDIS_KEY_ROUND_HEAD).Val()
+	if fields["multiplier"] == "1.00" || fields["status"] != "BETTING" {
+		t.Errorf("head after a tick = %v, want only a new multiplier", fields)
+	}
+
+	head, ok := m.RoundHead(ctx)
+	if !ok || head.Status != "RUNNING" || head.ETag != RoundHeadETag(round.RoundID, "RUNNING") || head.Multiplier <= MIN_MULTIPLIER {
+		t.Errorf("RoundHead() = %+v, want the running round from memory", head)
+	}
+	if head.ETag == betting {
+		t.Error("ETag should change with the status")
+	}
+
+	// Instances that do not run the round read the published head
+	m.stateMutex.Lock()
+	m.currentRound = nil
+	m.stateMutex.Unlock()
+	head, ok = m.RoundHead(ctx)
+	if !ok || head.RoundID != round.RoundID || head.ETag != betting || FormatMultiplier(head.Multiplier) != fields["multiplier"] {
+		t.Errorf("RoundHead() from Redis = %+v, want %v", head, fields)
+	}
+}
@@ -0,0 +1,187 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	REDIS_KEY_RTP = "rtp:"
+	RTP_CACHE_TTL = 5 * time.Minute
+)
+
+// Targets shown in the Dice RTP chart
+var diceRTPTargets = []float64{10, 25, 50, 75, 90}
+
+// RTPInfo describes the theoretical return-to-player of a game configuration
+type RTPInfo struct {
+	GameType     GameType    `json:"game_type"`
+	HouseEdgePct float64     `json:"house_edge_pct"`
+	RTPPct       float64     `json:"rtp_pct"`
+	Notes        string      `json:"notes"`
+	Table        interface{} `json:"table,omitempty"`
+}
+
+// DiceRTPEntry is the RTP of a Dice bet at a given target
+type DiceRTPEntry struct {
+	Target     float64 `json:"target"`
+	IsOver     bool    `json:"is_over"`
+	WinChance  float64 `json:"win_chance"`
+	Multiplier float64 `json:"multiplier"`
+	RTPPct     float64 `json:"rtp_pct"`
+}
+
+// MinesRTPEntry is the RTP of cashing out after revealing a number of tiles
+type MinesRTPEntry struct {
+	MineCount     int     `json:"mine_count"`
+	TilesRevealed int     `json:"tiles_revealed"`
+	WinChance     float64 `json:"win_chance"`
+	Multiplier    float64 `json:"multiplier"`
+	RTPPct        float64 `json:"rtp_pct"`
+}
+
+// PlinkoRTPEntry is the RTP of a Plinko risk/rows configuration
+type PlinkoRTPEntry struct {
+	Risk   PlinkoRisk `json:"risk"`
+	Rows   int        `json:"rows"`
+	RTPPct float64    `json:"rtp_pct"`
+}
+
+// CalculateRTP computes the theoretical RTP for a game type.
+// The computation is purely mathematical and uses no randomness.
+func CalculateRTP(gameType GameType) (*RTPInfo, error) {
+	switch gameType {
+	case GameTypeAviator:
+		return &RTPInfo{
+			GameType:     GameTypeAviator,
+			HouseEdgePct: HOUSE_EDGE * 100,
+			RTPPct:       (1 - HOUSE_EDGE) * 100,
+			Notes:        "RTP is independent of the cashout target",
+		}, nil
+	case GameTypeDice:
+		return calculateDiceRTP(), nil
+	case GameTypeMines:
+		return calculateMinesRTP(), nil
+	case GameTypePlinko:
+		return calculatePlinkoRTP(), nil
+	default:
+		return nil, fmt.Errorf("unknown game type: %s", gameType)
+	}
+}
+
+func calculateDiceRTP() *RTPInfo {
+	engine := &DiceEngine{}
+	table := make([]DiceRTPEntry, 0, len(diceRTPTargets)*2)
+	lowest := math.MaxFloat64
+
+	for _, isOver := range []bool{false, true} {
+		for _, target := range diceRTPTargets {
+			winChance := target / 100.0
+			if isOver {
+				winChance = (100.0 - target) / 100.0
+			}
+			multiplier := engine.calculateMultiplier(target, isOver)
+			rtp := roundPct(winChance * multiplier * 100)
+
+			table = append(table, DiceRTPEntry{
+				Target:     target,
+				IsOver:     isOver,
+				WinChance:  winChance,
+				Multiplier: multiplier,
+				RTPPct:     rtp,
+			})
+			lowest = math.Min(lowest, rtp)
+		}
+	}
+
+	return &RTPInfo{
+		GameType:     GameTypeDice,
+		HouseEdgePct: DICE_HOUSE_EDGE * 100,
+		RTPPct:       lowest,
+		Notes:        "rtp_pct is the lowest RTP across common targets; multipliers are truncated to 2 decimals",
+		Table:        table,
+	}
+}
+
+func calculateMinesRTP() *RTPInfo {
+	engine := &MinesEngine{}
+	table := make([]MinesRTPEntry, 0)
+	lowest := math.MaxFloat64
+
+	// Use a large reference bet so payout truncation does not distort the multiplier
+	const referenceBet = Amount(10000 * AMOUNT_SCALE)
+
+	for mineCount := MINES_MIN_COUNT; mineCount <= MINES_MAX_COUNT; mineCount++ {
+		safeTiles := MINES_GRID_SIZE - mineCount
+		winChance := 1.0
+
+		for revealed := 1; revealed <= safeTiles; revealed++ {
+			winChance *= float64(safeTiles-revealed+1) / float64(MINES_GRID_SIZE-revealed+1)
+			multiplier := float64(engine.calculatePayout(referenceBet, mineCount, revealed, MINES_GRID_SIZE)) / float64(referenceBet)
+			rtp := roundPct(winChance * multiplier * 100)
+
+			table = append(table, MinesRTPEntry{
+				MineCount:     mineCount,
+				TilesRevealed: revealed,
+				WinChance:     winChance,
+				Multiplier:    multiplier,
+				RTPPct:        rtp,
+			})
+			lowest = math.Min(lowest, rtp)
+		}
+	}
+
+	return &RTPInfo{
+		GameType:     GameTypeMines,
+		HouseEdgePct: MINES_HOUSE_EDGE * 100,
+		RTPPct:       lowest,
+		Notes:        "rtp_pct is the lowest RTP across all (mine_count, tiles_revealed) combinations on the default 5x5 grid",
+		Table:        table,
+	}
+}
+
+func calculatePlinkoRTP() *RTPInfo {
+	engine := &PlinkoEngine{}
+	table := make([]PlinkoRTPEntry, 0)
+	lowest := math.MaxFloat64
+
+	for _, risk := range []PlinkoRisk{PlinkoRiskLow, PlinkoRiskMedium, PlinkoRiskHigh} {
+		for _, rows := range []int{8, 12, 16} {
+			rtp := 0.0
+			for slot := 0; slot <= rows; slot++ {
+				rtp += binomialProbability(rows, slot) * engine.getMultiplier(risk, slot, rows)
+			}
+			rtp = roundPct(rtp * 100)
+
+			table = append(table, PlinkoRTPEntry{
+				Risk:   risk,
+				Rows:   rows,
+				RTPPct: rtp,
+			})
+			lowest = math.Min(lowest, rtp)
+		}
+	}
+
+	return &RTPInfo{
+		GameType:     GameTypePlinko,
+		HouseEdgePct: roundPct(100 - lowest),
+		RTPPct:       lowest,
+		Notes:        "rtp_pct is the lowest RTP across risk/rows configurations; slot probabilities follow a binomial distribution",
+		Table:        table,
+	}
+}
+
+// binomialProbability returns P(X = k) for X ~ Binomial(n, 0.5)
+func binomialProbability(n, k int) float64 {
+	coefficient := 1.0
+	for i := 1; i <= k; i++ {
+		coefficient = coefficient * float64(n-k+i) / float64(i)
+	}
+	return coefficient / math.Pow(2, float64(n))
+}
+
+// roundPct rounds a percentage to 4 decimal places
+func roundPct(value float64) float64 {
+	return math.Round(value*10000) / 10000
+}
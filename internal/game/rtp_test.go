@@ -0,0 +1,87 @@
+package game
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCalculateRTP_Aviator(t *testing.T) {
+	info, err := CalculateRTP(GameTypeAviator)
+	if err != nil {
+		t.Fatalf("CalculateRTP() error: %v", err)
+	}
+
+	if info.RTPPct != (1-HOUSE_EDGE)*100 {
+		t.Errorf("RTPPct = %v, want %v", info.RTPPct, (1-HOUSE_EDGE)*100)
+	}
+	if info.HouseEdgePct != HOUSE_EDGE*100 {
+		t.Errorf("HouseEdgePct = %v, want %v", info.HouseEdgePct, HOUSE_EDGE*100)
+	}
+}
+
+func TestCalculateRTP_Deterministic(t *testing.T) {
+	for _, gameType := range []GameType{GameTypeAviator, GameTypeDice, GameTypeMines, GameTypePlinko} {
+		first, err := CalculateRTP(gameType)
+		if err != nil {
+			t.Fatalf("CalculateRTP(%s) error: %v", gameType, err)
+		}
+		second, _ := CalculateRTP(gameType)
+
+		if first.RTPPct != second.RTPPct {
+			t.Errorf("%s RTP should be deterministic: %v != %v", gameType, first.RTPPct, second.RTPPct)
+		}
+	}
+}
+
+func TestCalculateRTP_Dice(t *testing.T) {
+	info, _ := CalculateRTP(GameTypeDice)
+
+	table, ok := info.Table.([]DiceRTPEntry)
+	if !ok {
+		t.Fatalf("expected []DiceRTPEntry table, got %T", info.Table)
+	}
+	if len(table) != len(diceRTPTargets)*2 {
+		t.Errorf("expected %d entries, got %d", len(diceRTPTargets)*2, len(table))
+	}
+	for _, entry := range table {
+		if entry.RTPPct > (1-DICE_HOUSE_EDGE)*100+0.0001 {
+			t.Errorf("target %.0f over=%v: RTP %.4f exceeds theoretical maximum", entry.Target, entry.IsOver, entry.RTPPct)
+		}
+	}
+}
+
+func TestCalculateRTP_Mines(t *testing.T) {
+	info, _ := CalculateRTP(GameTypeMines)
+
+	table, ok := info.Table.([]MinesRTPEntry)
+	if !ok {
+		t.Fatalf("expected []MinesRTPEntry table, got %T", info.Table)
+	}
+	for _, entry := range table {
+		if entry.RTPPct > (1-MINES_HOUSE_EDGE)*100+0.0001 {
+			t.Errorf("%d mines, %d tiles: RTP %.4f exceeds theoretical maximum", entry.MineCount, entry.TilesRevealed, entry.RTPPct)
+		}
+	}
+}
+
+func TestBinomialProbability(t *testing.T) {
+	for _, rows := range []int{8, 12, 16} {
+		total := 0.0
+		for k := 0; k <= rows; k++ {
+			total += binomialProbability(rows, k)
+		}
+		if math.Abs(total-1.0) > 1e-9 {
+			t.Errorf("probabilities for %d rows sum to %v, want 1", rows, total)
+		}
+	}
+
+	if got := binomialProbability(2, 1); got != 0.5 {
+		t.Errorf("binomialProbability(2, 1) = %v, want 0.5", got)
+	}
+}
+
+func TestCalculateRTP_UnknownType(t *testing.T) {
+	if _, err := CalculateRTP(GameType("roulette")); err == nil {
+		t.Error("expected error for unknown game type")
+	}
+}
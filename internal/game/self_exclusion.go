@@ -0,0 +1,58 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// REDIS_KEY_SELF_EXCLUSION holds when a user's self-exclusion ends and
+	// expires at that time. Self-excluded users earn no interest.
+	REDIS_KEY_SELF_EXCLUSION = "user:self_exclusion:"
+
+	SELF_EXCLUSION_MAX_DAYS = 365
+)
+
+var ErrInvalidSelfExclusion = errors.New("days must be between 1 and 365")
+
+// SelfExclude excludes a user for the given number of days and returns when
+// the exclusion ends. An exclusion can be extended but never shortened.
+func SelfExclude(ctx context.Context, client *redis.Client, userID string, days int, now time.Time) (time.Time, error) {
+	if days < 1 || days > SELF_EXCLUSION_MAX_DAYS {
+		return time.Time{}, ErrInvalidSelfExclusion
+	}
+
+	until := now.Add(time.Duration(days) * 24 * time.Hour).UTC()
+	current, err := SelfExclusionUntil(ctx, client, userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if current.After(until) {
+		return current, nil
+	}
+
+	if err := client.Set(ctx, REDIS_KEY_SELF_EXCLUSION+userID, until.Format(time.RFC3339), until.Sub(now)).Err(); err != nil {
+		return time.Time{}, err
+	}
+	return until, nil
+}
+
+// SelfExclusionUntil returns when a user's self-exclusion ends, or the zero
+// time if the user is not excluded
+func SelfExclusionUntil(ctx context.Context, client *redis.Client, userID string) (time.Time, error) {
+	value, err := client.Get(ctx, REDIS_KEY_SELF_EXCLUSION+userID).Result()
+	if errors.Is(err, redis.Nil) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	until, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return until, nil
+}
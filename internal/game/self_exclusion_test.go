@@ -0,0 +1,47 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestSelfExclude(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	for _, days := range []int{0, -1, SELF_EXCLUSION_MAX_DAYS + 1} {
+		if _, err := SelfExclude(ctx, client, "user1", days, now); !errors.Is(err, ErrInvalidSelfExclusion) {
+			t.Errorf("SelfExclude(%d days) error = %v, want ErrInvalidSelfExclusion", days, err)
+		}
+	}
+
+	until, err := SelfExclude(ctx, client, "user1", 30, now)
+	if err != nil {
+		t.Fatalf("SelfExclude() error: %v", err)
+	}
+	if want := now.Add(30 * 24 * time.Hour); !until.Equal(want) {
+		t.Errorf("excluded until %v, want %v", until, want)
+	}
+
+	// A shorter exclusion does not cut the existing one short
+	if got, _ := SelfExclude(ctx, client, "user1", 7, now); !got.Equal(until) {
+		t.Errorf("shorter exclusion returned %v, want %v", got, until)
+	}
+	if got, _ := SelfExclusionUntil(ctx, client, "user1"); !got.Equal(until) {
+		t.Errorf("SelfExclusionUntil() = %v, want %v", got, until)
+	}
+
+	// The key expires when the exclusion ends
+	mr.FastForward(30*24*time.Hour + time.Second)
+	if got, _ := SelfExclusionUntil(ctx, client, "user1"); !got.IsZero() {
+		t.Errorf("SelfExclusionUntil() after expiry = %v, want zero", got)
+	}
+}
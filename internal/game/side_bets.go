@@ -0,0 +1,289 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Side bets predict which range a round's crash point falls in. They are
+// placed during betting and settled when the round crashes, independently of
+// the player's main bets.
+const (
+	REDIS_KEY_SIDE_BETS = "crash:side_bets:"
+
+	SideBetUnder2x = "under_2x"
+	SideBet2xTo5x  = "2x_to_5x"
+	SideBet5xTo10x = "5x_to_10x"
+	SideBetOver10x = "over_10x"
+
+	SIDE_BET_ODDS_SAMPLE = 1000 // Recent rounds the odds are computed from
+)
+
+// SideBetRange pays PayoutMultiplier times the stake when the crash point is
+// in [Min, Max). Max is 0 for the open-ended top range. Every payout is below
+// the inverse of its range's probability, so each prediction keeps a house edge.
+type SideBetRange struct {
+	Prediction       string
+	Min, Max         float64
+	PayoutMultiplier float64
+}
+
+// SideBetRanges lists the predictions from the lowest crash points up
+var SideBetRanges = []SideBetRange{
+	{Prediction: SideBetUnder2x, Min: MIN_MULTIPLIER, Max: 2, PayoutMultiplier: 1.5},
+	{Prediction: SideBet2xTo5x, Min: 2, Max: 5, PayoutMultiplier: 3},
+	{Prediction: SideBet5xTo10x, Min: 5, Max: 10, PayoutMultiplier: 8},
+	{Prediction: SideBetOver10x, Min: 10, PayoutMultiplier: 9.5},
+}
+
+// contains reports whether a crash point falls in the range
+func (r SideBetRange) contains(crashPoint float64) bool {
+	return crashPoint >= r.Min && (r.Max == 0 || crashPoint < r.Max)
+}
+
+// sideBetRange returns the range for a prediction
+func sideBetRange(prediction string) (SideBetRange, bool) {
+	for _, r := range SideBetRanges {
+		if r.Prediction == prediction {
+			return r, true
+		}
+	}
+	return SideBetRange{}, false
+}
+
+// SideBetPayout returns what a side bet on prediction pays for a round that
+// crashed at crashPoint, or 0 if it lost
+func SideBetPayout(amount Amount, prediction string, crashPoint float64) Amount {
+	r, ok := sideBetRange(prediction)
+	if !ok || !r.contains(crashPoint) {
+		return 0
+	}
+	return amount.Mul(r.PayoutMultiplier)
+}
+
+type SideBetRequest struct {
+	UserID       string             `json:"user_id"`
+	Amount       Amount             `json:"amount"`
+	Prediction   string             `json:"prediction"`
+	ResponseChan chan SideBetResult `json:"-"`
+}
+
+type SideBetResponse struct {
+	Success bool    `json:"success"`
+	Message string  `json:"message"`
+	BetID   string  `json:"bet_id,omitempty"`
+	Balance float64 `json:"balance,omitempty"`
+}
+
+// SideBetResult carries the outcome of a queued side bet back to PlaceSideBet
+type SideBetResult struct {
+	Response SideBetResponse
+	Err      error
+}
+
+// SideBet is a side bet stored in the round's side bet hash
+type SideBet struct {
+	BetID      string    `json:"bet_id"`
+	UserID     string    `json:"user_id"`
+	Amount     Amount    `json:"amount"`
+	Prediction string    `json:"prediction"`
+	PlacedAt   time.Time `json:"placed_at"`
+}
+
+// PlaceSideBet queues a side bet for the game loop. Errors are reported as by PlaceBet.
+func (m *Manager) PlaceSideBet(req SideBetRequest) (SideBetResponse, error) {
+	respChan := make(chan SideBetResult, 1)
+	req.ResponseChan = respChan
+
+	select {
+	case m.sideBetChannel <- req:
+		select {
+		case result := <-respChan:
+			return result.Response, result.Err
+		case <-time.After(5 * time.Second):
+			return SideBetResponse{Success: false, Message: "Bet timeout"}, nil
+		}
+	default:
+		return SideBetResponse{Success: false, Message: "Bet queue full"}, nil
+	}
+}
+
+// processSideBet places a side bet in the current round. Rejections and
+// errors are reported as by processBet.
+func (m *Manager) processSideBet(ctx context.Context, req SideBetRequest) (SideBetResponse, error) {
+	if req.Amount.Float64() < MIN_BET_AMOUNT || req.Amount.Float64() > MAX_BET_AMOUNT {
+		return SideBetResponse{Message: fmt.Sprintf("Bet must be between %.2f and %.2f", MIN_BET_AMOUNT, MAX_BET_AMOUNT)}, nil
+	}
+	if _, ok := sideBetRange(req.Prediction); !ok {
+		return SideBetResponse{Message: "Prediction must be under_2x, 2x_to_5x, 5x_to_10x or over_10x"}, nil
+	}
+
+	m.stateMutex.RLock()
+	if m.currentRound == nil || m.currentRound.Status != "BETTING" {
+		m.stateMutex.RUnlock()
+		return SideBetResponse{Message: "Betting is closed"}, nil
+	}
+	if m.currentRound.Simulated {
+		m.stateMutex.RUnlock()
+		return SideBetResponse{Message: SIMULATION_BET_REJECTION}, nil
+	}
+	roundID := m.currentRound.RoundID
+	m.stateMutex.RUnlock()
+
+	balanceKey := REDIS_KEY_USER_BALANCE + req.UserID
+	balance, err := m.redisClient.Get(ctx, balanceKey).Float64()
+	if err != nil && err != redis.Nil {
+		return SideBetResponse{}, fmt.Errorf("processSideBet: Get balance: %w", err)
+	}
+	if balance < req.Amount.Float64() {
+		return SideBetResponse{Message: "Insufficient balance", Balance: balance}, nil
+	}
+
+	newBalance, err := m.redisClient.IncrByFloat(ctx, balanceKey, -req.Amount.Float64()).Result()
+	if err != nil {
+		return SideBetResponse{}, fmt.Errorf("processSideBet: IncrByFloat: %w", err)
+	}
+	if newBalance < 0 {
+		m.redisClient.IncrByFloat(ctx, balanceKey, req.Amount.Float64()) // Rollback
+		return SideBetResponse{Message: "Insufficient balance", Balance: newBalance + req.Amount.Float64()}, nil
+	}
+
+	bet := SideBet{
+		BetID:      fmt.Sprintf("SIDE-%s-%d", req.UserID, time.Now().UnixNano()),
+		UserID:     req.UserID,
+		Amount:     req.Amount,
+		Prediction: req.Prediction,
+		PlacedAt:   time.Now(),
+	}
+	sideBetsKey := REDIS_KEY_SIDE_BETS + roundID
+	betJSON, _ := json.Marshal(bet)
+	if err := m.redisClient.HSet(ctx, sideBetsKey, bet.BetID, betJSON).Err(); err != nil {
+		m.redisClient.IncrByFloat(ctx, balanceKey, req.Amount.Float64()) // Rollback
+		return SideBetResponse{}, fmt.Errorf("processSideBet: HSet: %w", err)
+	}
+	m.redisClient.Expire(ctx, sideBetsKey, 10*time.Minute)
+
+	m.hub.NotifyBalance(req.UserID, newBalance, -req.Amount, BalanceReasonBet)
+	recordWager(ctx, m.redisClient, req.UserID, req.Amount)
+	m.touchLastSeen(req.UserID)
+
+	log.Printf("[SIDE BET] User %s placed %s on %s (ID: %s)", req.UserID, req.Amount, req.Prediction, bet.BetID)
+	return SideBetResponse{
+		Success: true,
+		Message: "Side bet placed successfully",
+		BetID:   bet.BetID,
+		Balance: newBalance,
+	}, nil
+}
+
+// loadSideBets loads a round's side bets from Redis
+func (m *Manager) loadSideBets(roundID string) map[string]SideBet {
+	bets := make(map[string]SideBet)
+	entries, err := m.redisClient.HGetAll(m.ctx, REDIS_KEY_SIDE_BETS+roundID).Result()
+	if err != nil {
+		log.Printf("[SIDE BET] Failed to load side bets of round %s: %v", roundID, err)
+		return bets
+	}
+
+	for betID, betJSON := range entries {
+		var bet SideBet
+		if json.Unmarshal([]byte(betJSON), &bet) == nil {
+			bets[betID] = bet
+		}
+	}
+	return bets
+}
+
+// settleSideBets credits the winning side bets of a round that crashed at
+// crashPoint, its crash point before any bonus event. The caller deletes the
+// side bet hash.
+func (m *Manager) settleSideBets(roundID string, crashPoint float64) {
+	for betID, bet := range m.loadSideBets(roundID) {
+		payout := SideBetPayout(bet.Amount, bet.Prediction, crashPoint)
+		if payout == 0 {
+			log.Printf("[SIDE BET] User %s lost %s on %s (ID: %s)", bet.UserID, bet.Amount, bet.Prediction, betID)
+			continue
+		}
+
+		balance, err := CreditBalance(m.ctx, m.redisClient, bet.UserID, payout.Float64())
+		if err != nil {
+			log.Printf("[SIDE BET] Failed to credit %s to %s (ID: %s): %v", payout, bet.UserID, betID, err)
+			continue
+		}
+		m.hub.NotifyBalance(bet.UserID, balance, payout, BalanceReasonPayout)
+		log.Printf("[SIDE BET] User %s won %s on %s at %.2fx (ID: %s)", bet.UserID, payout, bet.Prediction, crashPoint, betID)
+	}
+}
+
+// refundSideBets returns the stakes of an aborted round's side bets. The
+// caller deletes the side bet hash.
+func (m *Manager) refundSideBets(roundID string) {
+	for betID, bet := range m.loadSideBets(roundID) {
+		balance, err := CreditBalance(m.ctx, m.redisClient, bet.UserID, bet.Amount.Float64())
+		if err != nil {
+			log.Printf("[PANIC] Failed to refund side bet %s of %s to %s: %v", betID, bet.Amount, bet.UserID, err)
+			continue
+		}
+		m.hub.NotifyBalance(bet.UserID, balance, bet.Amount, BalanceReasonRefund)
+		log.Printf("[PANIC] Refunded side bet %s to %s (ID: %s)", bet.Amount, bet.UserID, betID)
+	}
+}
+
+// SideBetOdds describes one prediction. Probability is the share of recent
+// rounds whose crash point fell in the range, or the theoretical share when
+// no rounds have been recorded.
+type SideBetOdds struct {
+	Prediction        string  `json:"prediction"`
+	MinMultiplier     float64 `json:"min_multiplier"`
+	MaxMultiplier     float64 `json:"max_multiplier,omitempty"`
+	PayoutMultiplier  float64 `json:"payout_multiplier"`
+	Probability       float64 `json:"probability"`
+	ExpectedReturnPct float64 `json:"expected_return_pct"`
+}
+
+// theoreticalCrashAtLeast returns the chance that HashAndMapToMultiplier
+// produces a crash point of at least x
+func theoreticalCrashAtLeast(x float64) float64 {
+	if x <= MIN_MULTIPLIER {
+		return 1
+	}
+	return (1 - HOUSE_EDGE) / x
+}
+
+// CalculateSideBetOdds returns the odds of every prediction given the crash
+// points of recent rounds
+func CalculateSideBetOdds(crashPoints []float64) []SideBetOdds {
+	odds := make([]SideBetOdds, 0, len(SideBetRanges))
+	for _, r := range SideBetRanges {
+		var probability float64
+		if len(crashPoints) > 0 {
+			hits := 0
+			for _, crashPoint := range crashPoints {
+				if r.contains(crashPoint) {
+					hits++
+				}
+			}
+			probability = float64(hits) / float64(len(crashPoints))
+		} else {
+			probability = theoreticalCrashAtLeast(r.Min)
+			if r.Max > 0 {
+				probability -= theoreticalCrashAtLeast(r.Max)
+			}
+		}
+
+		odds = append(odds, SideBetOdds{
+			Prediction:        r.Prediction,
+			MinMultiplier:     r.Min,
+			MaxMultiplier:     r.Max,
+			PayoutMultiplier:  r.PayoutMultiplier,
+			Probability:       roundPct(probability),
+			ExpectedReturnPct: roundPct(probability * r.PayoutMultiplier * 100),
+		})
+	}
+	return odds
+}
@@ -0,0 +1,134 @@
+package game
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestSideBetPayout(t *testing.T) {
+	tests := []struct {
+		prediction string
+		crashPoint float64
+		want       float64
+	}{
+		{SideBetUnder2x, 1.00, 15},
+		{SideBetUnder2x, 1.99, 15},
+		{SideBetUnder2x, 2.00, 0},
+		{SideBet2xTo5x, 1.99, 0},
+		{SideBet2xTo5x, 2.00, 30},
+		{SideBet2xTo5x, 4.99, 30},
+		{SideBet2xTo5x, 5.00, 0},
+		{SideBet5xTo10x, 4.99, 0},
+		{SideBet5xTo10x, 5.00, 80},
+		{SideBet5xTo10x, 9.99, 80},
+		{SideBet5xTo10x, 10.00, 0},
+		{SideBetOver10x, 9.99, 0},
+		{SideBetOver10x, 10.00, 95},
+		{SideBetOver10x, MAX_MULTIPLIER, 95},
+		{"over_100x", 150, 0},
+	}
+
+	for _, tt := range tests {
+		if got := SideBetPayout(amountOf(10), tt.prediction, tt.crashPoint); got != amountOf(tt.want) {
+			t.Errorf("SideBetPayout(10, %s, %.2f) = %s, want %.2f", tt.prediction, tt.crashPoint, got, tt.want)
+		}
+	}
+}
+
+func TestSideBetRangesKeepHouseEdge(t *testing.T) {
+	for _, o := range CalculateSideBetOdds(nil) {
+		if o.ExpectedReturnPct >= 100 {
+			t.Errorf("%s pays %.2fx with probability %.4f, an expected return of %.2f%%", o.Prediction, o.PayoutMultiplier, o.Probability, o.ExpectedReturnPct)
+		}
+	}
+}
+
+func TestManager_SideBetSettlement(t *testing.T) {
+	m, client := newTestManager(t)
+	ctx := context.Background()
+	m.setTestRound("R-side", "BETTING")
+
+	predictions := map[string]string{
+		"user1": SideBetUnder2x,
+		"user2": SideBet2xTo5x,
+		"user3": SideBet5xTo10x,
+		"user4": SideBetOver10x,
+	}
+	for user, prediction := range predictions {
+		client.Set(ctx, REDIS_KEY_USER_BALANCE+user, 100.0, 0)
+		resp, err := m.processSideBet(ctx, SideBetRequest{UserID: user, Amount: amountOf(10), Prediction: prediction})
+		if err != nil || !resp.Success || resp.Balance != 90 {
+			t.Fatalf("processSideBet(%s) = %+v, %v", prediction, resp, err)
+		}
+	}
+
+	rejected := []SideBetRequest{
+		{UserID: "user1", Amount: amountOf(10), Prediction: "over_100x"},
+		{UserID: "user1", Amount: amountOf(0.01), Prediction: SideBetUnder2x},
+		{UserID: "user5", Amount: amountOf(10), Prediction: SideBetUnder2x},
+	}
+	for _, req := range rejected {
+		if resp, err := m.processSideBet(ctx, req); err != nil || resp.Success {
+			t.Errorf("processSideBet(%+v) = %+v, %v; want a rejection", req, resp, err)
+		}
+	}
+
+	m.setTestRound("R-side", "RUNNING")
+	if resp, _ := m.processSideBet(ctx, SideBetRequest{UserID: "user1", Amount: amountOf(10), Prediction: SideBetUnder2x}); resp.Success {
+		t.Error("processSideBet() succeeded after betting closed")
+	}
+
+	m.processRoundEnd("R-side", 5.0, nil)
+
+	want := map[string]float64{"user1": 90, "user2": 90, "user3": 170, "user4": 90}
+	for user, balance := range want {
+		if got, _ := client.Get(ctx, REDIS_KEY_USER_BALANCE+user).Float64(); got != balance {
+			t.Errorf("%s (%s) balance = %v, want %v", user, predictions[user], got, balance)
+		}
+	}
+	if client.Exists(ctx, REDIS_KEY_SIDE_BETS+"R-side").Val() != 0 {
+		t.Error("side bets were not deleted after settlement")
+	}
+}
+
+func TestManager_RefundSideBets(t *testing.T) {
+	m, client := newTestManager(t)
+	ctx := context.Background()
+	m.setTestRound("R-refund", "BETTING")
+
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"user1", 100.0, 0)
+	if resp, err := m.processSideBet(ctx, SideBetRequest{UserID: "user1", Amount: amountOf(25), Prediction: SideBetOver10x}); err != nil || !resp.Success {
+		t.Fatalf("processSideBet() = %+v, %v", resp, err)
+	}
+
+	m.refundSideBets("R-refund")
+	if got, _ := client.Get(ctx, REDIS_KEY_USER_BALANCE+"user1").Float64(); got != 100 {
+		t.Errorf("balance after refund = %v, want 100", got)
+	}
+}
+
+func TestCalculateSideBetOdds(t *testing.T) {
+	odds := CalculateSideBetOdds([]float64{1.5, 1.99, 2.0, 4.2, 5.0, 10.0, 1.0, 1.2})
+	want := map[string]float64{SideBetUnder2x: 0.5, SideBet2xTo5x: 0.25, SideBet5xTo10x: 0.125, SideBetOver10x: 0.125}
+	for _, o := range odds {
+		if o.Probability != want[o.Prediction] {
+			t.Errorf("%s probability = %v, want %v", o.Prediction, o.Probability, want[o.Prediction])
+		}
+		if o.ExpectedReturnPct != o.Probability*o.PayoutMultiplier*100 {
+			t.Errorf("%s expected return = %v%%, want %v%%", o.Prediction, o.ExpectedReturnPct, o.Probability*o.PayoutMultiplier*100)
+		}
+	}
+
+	// Without history the odds follow the crash point distribution
+	var total float64
+	for _, o := range CalculateSideBetOdds(nil) {
+		total += o.Probability
+		if o.Prediction == SideBet2xTo5x && math.Abs(o.Probability-0.99*(1.0/2-1.0/5)) > 0.0001 {
+			t.Errorf("theoretical 2x_to_5x probability = %v, want %v", o.Probability, 0.99*(1.0/2-1.0/5))
+		}
+	}
+	if math.Abs(total-1) > 0.0001 {
+		t.Errorf("theoretical probabilities sum to %v, want 1", total)
+	}
+}
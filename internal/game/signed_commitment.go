@@ -0,0 +1,122 @@
+package game
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	REDIS_KEY_SIGNED_COMMITMENT = "crash:commitment:"
+
+	SIGNED_COMMITMENT_TTL = 7 * 24 * time.Hour
+)
+
+var ErrCommitmentNotFound = errors.New("commitment not found or expired")
+
+// SignedCommitment is a round's commitment as published before betting
+// opened. Signature is HMAC-SHA256(OPERATOR_SECRET, commitment + round_id +
+// published_at), so the commitment cannot be swapped after publication
+// without the operator's secret. The seed reveal time and crash multiplier
+// are added once the round has crashed.
+type SignedCommitment struct {
+	RoundID              string     `json:"round_id"`
+	Commitment           string     `json:"commitment"`
+	Signature            string     `json:"signature"`
+	PublishedAt          time.Time  `json:"published_at"`
+	BetsOpenedAt         time.Time  `json:"bets_opened_at"`
+	ServerSeedRevealedAt *time.Time `json:"server_seed_revealed_at,omitempty"`
+	CrashMultiplier      float64    `json:"crash_multiplier,omitempty"` // Provably fair crash point
+}
+
+// SignCommitment returns the hex HMAC-SHA256 of a commitment, its round ID and
+// its publication time in RFC 3339 UTC
+func SignCommitment(secret, commitment, roundID string, publishedAt time.Time) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(commitment + roundID + publishedAt.UTC().Format(time.RFC3339Nano)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// operatorSecretOrGenerate returns the configured signing secret. Without
+// one, signatures only verify until the server restarts.
+func operatorSecretOrGenerate(secret string) string {
+	if secret != "" {
+		return secret
+	}
+	log.Printf("[FAIR] OPERATOR_SECRET is not set; commitment signatures will not survive a restart")
+	return GenerateSeed()
+}
+
+// signCommitment stores the signed commitment of a round whose betting is
+// about to open
+func (m *Manager) signCommitment(round *RoundState) {
+	data, _ := json.Marshal(SignedCommitment{
+		RoundID:      round.RoundID,
+		Commitment:   round.HashCommitment,
+		Signature:    SignCommitment(m.operatorSecret, round.HashCommitment, round.RoundID, round.CommitmentPublishedAt),
+		PublishedAt:  round.CommitmentPublishedAt,
+		BetsOpenedAt: round.StartTime,
+	})
+	if err := m.redisClient.Set(m.ctx, REDIS_KEY_SIGNED_COMMITMENT+round.RoundID, data, SIGNED_COMMITMENT_TTL).Err(); err != nil {
+		log.Printf("[FAIR] Failed to store signed commitment for %s: %v", round.RoundID, err)
+	}
+}
+
+// completeSignedCommitment adds a crashed round's seed reveal time and crash
+// multiplier to its signed commitment
+func (m *Manager) completeSignedCommitment(round *RoundState) {
+	record, err := m.SignedCommitment(m.ctx, round.RoundID)
+	if err != nil {
+		log.Printf("[FAIR] Failed to load signed commitment for %s: %v", round.RoundID, err)
+		return
+	}
+
+	revealedAt := round.CrashTime.Add(m.seedRevealDelay)
+	record.ServerSeedRevealedAt = &revealedAt
+	record.CrashMultiplier = round.BaseMultiplier()
+	data, _ := json.Marshal(record)
+	if err := m.redisClient.Set(m.ctx, REDIS_KEY_SIGNED_COMMITMENT+round.RoundID, data, redis.KeepTTL).Err(); err != nil {
+		log.Printf("[FAIR] Failed to update signed commitment for %s: %v", round.RoundID, err)
+	}
+}
+
+// SignedCommitment returns the signed commitment of a round
+func (m *Manager) SignedCommitment(ctx context.Context, roundID string) (*SignedCommitment, error) {
+	data, err := m.redisClient.Get(ctx, REDIS_KEY_SIGNED_COMMITMENT+roundID).Result()
+	if err == redis.Nil {
+		return nil, ErrCommitmentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var record SignedCommitment
+	if err := json.Unmarshal([]byte(data), &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// VerifySignedCommitment checks a commitment and signature a player kept for
+// a round against the published record. publishedBeforeBets reports whether
+// the valid commitment was published no later than betting opened.
+func (m *Manager) VerifySignedCommitment(ctx context.Context, roundID, commitment, signature string) (valid, publishedBeforeBets bool, err error) {
+	record, err := m.SignedCommitment(ctx, roundID)
+	if errors.Is(err, ErrCommitmentNotFound) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, err
+	}
+
+	expected := SignCommitment(m.operatorSecret, record.Commitment, record.RoundID, record.PublishedAt)
+	valid = record.Commitment == commitment && hmac.Equal([]byte(signature), []byte(expected))
+	return valid, valid && !record.PublishedAt.After(record.BetsOpenedAt), nil
+}
@@ -0,0 +1,105 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestSignCommitment(t *testing.T) {
+	publishedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	signature := SignCommitment("secret", "abc123", "R1-1", publishedAt)
+
+	if len(signature) != 64 {
+		t.Errorf("signature %q is not a hex SHA-256 digest", signature)
+	}
+	if again := SignCommitment("secret", "abc123", "R1-1", publishedAt.In(time.FixedZone("EAT", 3*60*60))); again != signature {
+		t.Error("signature should not depend on the time zone of published_at")
+	}
+	for name, other := range map[string]string{
+		"secret":       SignCommitment("other", "abc123", "R1-1", publishedAt),
+		"commitment":   SignCommitment("secret", "abc124", "R1-1", publishedAt),
+		"round ID":     SignCommitment("secret", "abc123", "R1-2", publishedAt),
+		"published at": SignCommitment("secret", "abc123", "R1-1", publishedAt.Add(time.Millisecond)),
+	} {
+		if other == signature {
+			t.Errorf("changing the %s should change the signature", name)
+		}
+	}
+}
+
+func TestManager_SignedCommitment(t *testing.T) {
+	m, _ := newTestManager(t)
+	m.operatorSecret = "operator-secret"
+	ctx := context.Background()
+
+	round := m.startNewRound()
+	start := (<-m.hub.broadcast).(map[string]interface{})
+
+	record, err := m.SignedCommitment(ctx, round.RoundID)
+	if err != nil {
+		t.Fatalf("SignedCommitment() error: %v", err)
+	}
+	if start["commitment_signature"] != record.Signature {
+		t.Errorf("round_start signature = %v, want %s", start["commitment_signature"], record.Signature)
+	}
+	if record.Commitment != round.HashCommitment || record.ServerSeedRevealedAt != nil || record.CrashMultiplier != 0 {
+		t.Errorf("record before the crash = %+v, want the unrevealed commitment of %s", record, round.RoundID)
+	}
+	if record.Signature != SignCommitment("operator-secret", round.HashCommitment, round.RoundID, round.CommitmentPublishedAt) {
+		t.Errorf("record signature %s does not match the commitment", record.Signature)
+	}
+
+	valid, beforeBets, err := m.VerifySignedCommitment(ctx, round.RoundID, record.Commitment, record.Signature)
+	if err != nil || !valid || !beforeBets {
+		t.Errorf("VerifySignedCommitment() = %v, %v, %v; want a valid commitment published before bets", valid, beforeBets, err)
+	}
+	forged := SignCommitment("guessed-secret", record.Commitment, round.RoundID, record.PublishedAt)
+	for name, claim := range map[string][2]string{
+		"swapped commitment": {HashCommitment("another seed"), record.Signature},
+		"forged signature":   {record.Commitment, forged},
+	} {
+		if valid, beforeBets, _ := m.VerifySignedCommitment(ctx, round.RoundID, claim[0], claim[1]); valid || beforeBets {
+			t.Errorf("%s verified", name)
+		}
+	}
+	if valid, _, err := m.VerifySignedCommitment(ctx, "R-missing", record.Commitment, record.Signature); valid || err != nil {
+		t.Errorf("commitment of an unknown round = %v, %v; want invalid", valid, err)
+	}
+
+	m.stateMutex.Lock()
+	m.crashRound(round.RoundID, nil)
+	crashedAt := m.currentRound.CrashTime
+	m.stateMutex.Unlock()
+
+	record, _ = m.SignedCommitment(ctx, round.RoundID)
+	if record.ServerSeedRevealedAt == nil || !record.ServerSeedRevealedAt.Equal(crashedAt) || record.CrashMultiplier != round.BaseMultiplier() {
+		t.Errorf("record after the crash = %+v, want the reveal time and crash multiplier", record)
+	}
+	if valid, _, _ := m.VerifySignedCommitment(ctx, round.RoundID, record.Commitment, record.Signature); !valid {
+		t.Error("completing the record should not invalidate its signature")
+	}
+}
+
+func TestManager_SignedCommitmentPublishedLate(t *testing.T) {
+	m, client := newTestManager(t)
+	ctx := context.Background()
+
+	betsOpenedAt := time.Now()
+	publishedAt := betsOpenedAt.Add(time.Second)
+	record := SignedCommitment{
+		RoundID:      "R-late",
+		Commitment:   HashCommitment("seed"),
+		Signature:    SignCommitment(m.operatorSecret, HashCommitment("seed"), "R-late", publishedAt),
+		PublishedAt:  publishedAt,
+		BetsOpenedAt: betsOpenedAt,
+	}
+	data, _ := json.Marshal(record)
+	client.Set(ctx, REDIS_KEY_SIGNED_COMMITMENT+"R-late", data, 0)
+
+	valid, beforeBets, err := m.VerifySignedCommitment(ctx, "R-late", record.Commitment, record.Signature)
+	if err != nil || !valid || beforeBets {
+		t.Errorf("VerifySignedCommitment() = %v, %v, %v; want valid but not published before bets", valid, beforeBets, err)
+	}
+}
@@ -0,0 +1,102 @@
+package game
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"os"
+	"sync"
+)
+
+const RESULT_SIGNATURE_FIELD = "result_signature"
+
+// resultSigningKey is loaded once so every engine signs with the same key.
+// Without RESULT_SIGNING_KEY a random key is used, and signatures only verify
+// until the server restarts.
+var resultSigningKey = sync.OnceValue(func() string {
+	if key := os.Getenv("RESULT_SIGNING_KEY"); key != "" {
+		return key
+	}
+	log.Printf("[FAIR] RESULT_SIGNING_KEY is not set; result signatures will not survive a restart")
+	return GenerateSeed()
+})
+
+// ResultSigningKey returns the key game results are signed with
+func ResultSigningKey() string {
+	return resultSigningKey()
+}
+
+// SigningKeyHash identifies a signing key without revealing it
+func SigningKeyHash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// SignResponse returns the hex HMAC-SHA256 of a response's JSON encoding
+// without its result_signature. The JSON is canonicalised first, with object
+// keys sorted, so the signature does not depend on field order or spacing.
+func SignResponse(v interface{}, key string) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return signResultJSON(data, key)
+}
+
+// VerifyResultSignature checks a response exactly as the client received it
+func VerifyResultSignature(responseJSON, signature, key string) bool {
+	expected := signResultJSON([]byte(responseJSON), key)
+	return expected != "" && hmac.Equal([]byte(signature), []byte(expected))
+}
+
+func signResultJSON(data []byte, key string) string {
+	canonical, err := canonicalResultJSON(data)
+	if err != nil {
+		return ""
+	}
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write(canonical)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// canonicalResultJSON re-encodes a JSON object with sorted keys and without
+// its signature. Numbers are re-encoded from float64, as JavaScript would, so
+// 19.80 and 19.8 sign the same.
+func canonicalResultJSON(data []byte) ([]byte, error) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	delete(fields, RESULT_SIGNATURE_FIELD)
+	return json.Marshal(fields)
+}
+
+// signResult sets the signature of the game responses that carry one
+func signResult(resp interface{}, key string) interface{} {
+	switch r := resp.(type) {
+	case MinesBetResponse:
+		r.ResultSignature = SignResponse(r, key)
+		return r
+	case MinesClickResponse:
+		r.ResultSignature = SignResponse(r, key)
+		return r
+	case MinesPreselectResponse:
+		r.ResultSignature = SignResponse(r, key)
+		return r
+	case MinesMultiGameResponse:
+		r.ResultSignature = SignResponse(r, key)
+		return r
+	case DiceRollResponse:
+		r.ResultSignature = SignResponse(r, key)
+		return r
+	case DiceParlayResponse:
+		r.ResultSignature = SignResponse(r, key)
+		return r
+	case PlinkoDropResponse:
+		r.ResultSignature = SignResponse(r, key)
+		return r
+	}
+	return resp
+}
@@ -0,0 +1,71 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestVerifyResultSignature(t *testing.T) {
+	resp := DiceRollResponse{
+		Success:    true,
+		Message:    "You won!",
+		GameID:     "DICE-user1-1",
+		RollResult: 73.42,
+		Win:        true,
+		Multiplier: 1.98,
+		Payout:     amountOf(19.8),
+		ServerSeed: "server",
+		ClientSeed: "client",
+		Nonce:      1,
+	}
+	resp.ResultSignature = SignResponse(resp, "signing-key")
+	data, _ := json.Marshal(resp)
+
+	if !VerifyResultSignature(string(data), resp.ResultSignature, "signing-key") {
+		t.Fatalf("signature of %s does not verify", data)
+	}
+	if VerifyResultSignature(string(data), resp.ResultSignature, "other-key") {
+		t.Error("signature verified with the wrong key")
+	}
+
+	// Field order and spacing are not part of the signature
+	var fields map[string]interface{}
+	json.Unmarshal(data, &fields)
+	indented, _ := json.MarshalIndent(fields, "", "  ")
+	if !VerifyResultSignature(string(indented), resp.ResultSignature, "signing-key") {
+		t.Error("re-encoded response should still verify")
+	}
+
+	tampered := strings.Replace(string(data), `"payout":19.80`, `"payout":198.00`, 1)
+	if tampered == string(data) {
+		t.Fatalf("payout not found in %s", data)
+	}
+	if VerifyResultSignature(tampered, resp.ResultSignature, "signing-key") {
+		t.Error("signature verified after the payout was changed")
+	}
+	if VerifyResultSignature("not json", resp.ResultSignature, "signing-key") {
+		t.Error("signature verified for a body that is not JSON")
+	}
+}
+
+func TestMinesEngine_SignsResults(t *testing.T) {
+	engine, _ := newTestMinesEngine(t)
+	engine.signingKey = "signing-key"
+	ctx := context.Background()
+
+	resp, _ := engine.PlaceBet(ctx, MinesBetRequest{UserID: "user1", Amount: 10, MineCount: 3})
+	bet := resp.(MinesBetResponse)
+	data, _ := json.Marshal(bet)
+	if bet.ResultSignature == "" || !VerifyResultSignature(string(data), bet.ResultSignature, "signing-key") {
+		t.Errorf("bet response %s is not signed", data)
+	}
+
+	resp, _ = engine.ProcessAction(ctx, "click", MinesClickRequest{UserID: "user1", GameID: bet.GameID, TileID: 0})
+	click := resp.(MinesClickResponse)
+	data, _ = json.Marshal(click)
+	if click.ResultSignature == "" || !VerifyResultSignature(string(data), click.ResultSignature, "signing-key") {
+		t.Errorf("click response %s is not signed", data)
+	}
+}
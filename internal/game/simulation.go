@@ -0,0 +1,179 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+)
+
+const (
+	// SIMULATION_BETTING_TIME is the betting phase of a simulated round. No
+	// bets are taken, so it only has to be long enough for clients to show it.
+	SIMULATION_BETTING_TIME = 1 * time.Second
+
+	SIMULATION_MAX_CRASH_AT  = 1000.0
+	SIMULATION_MAX_DURATION  = 5 * time.Minute
+	SIMULATION_ROUND_PREFIX  = "SIM"
+	SIMULATION_BET_REJECTION = "Bets are not accepted in simulated rounds"
+)
+
+var (
+	ErrInvalidSimulation = fmt.Errorf("crash_at must be above 1 and at most %.0f, duration_seconds between 1 and %d",
+		SIMULATION_MAX_CRASH_AT, int(SIMULATION_MAX_DURATION/time.Second))
+	ErrSimulationActive = errors.New("a simulated round is already scheduled or running")
+)
+
+// CrashSimulation is a non-monetary round that crashes at a fixed point, for
+// testing the client's crash animation
+type CrashSimulation struct {
+	CrashAt  float64       `json:"crash_at"`
+	Duration time.Duration `json:"-"`
+}
+
+// SimulateCrash schedules a simulated round in place of the next real one.
+// The game loop only runs one round at a time, so the simulation never
+// overlaps a real round. Only one simulation can be scheduled at once.
+func (m *Manager) SimulateCrash(crashAt float64, duration time.Duration) (*CrashSimulation, error) {
+	if crashAt <= MIN_MULTIPLIER || crashAt > SIMULATION_MAX_CRASH_AT || duration < time.Second || duration > SIMULATION_MAX_DURATION {
+		return nil, ErrInvalidSimulation
+	}
+
+	m.simulationMu.Lock()
+	defer m.simulationMu.Unlock()
+	if m.simulation != nil {
+		return nil, ErrSimulationActive
+	}
+	m.simulation = &CrashSimulation{CrashAt: math.Round(crashAt*100) / 100, Duration: duration}
+	m.simulationPending = true
+
+	log.Printf("[SIMULATION] Scheduled a round crashing at %.2fx within %v", m.simulation.CrashAt, duration)
+	return m.simulation, nil
+}
+
+// takeSimulation returns the scheduled simulation, if any, marking it running
+func (m *Manager) takeSimulation() *CrashSimulation {
+	m.simulationMu.Lock()
+	defer m.simulationMu.Unlock()
+	if !m.simulationPending {
+		return nil
+	}
+	m.simulationPending = false
+	return m.simulation
+}
+
+func (m *Manager) endSimulation() {
+	m.simulationMu.Lock()
+	defer m.simulationMu.Unlock()
+	m.simulation = nil
+}
+
+// runSimulation plays a simulated round: the standard round messages flagged
+// simulated, with no bets, seeds or records. It ends when the multiplier
+// reaches the crash point or the duration runs out, whichever is first.
+func (m *Manager) runSimulation(sim *CrashSimulation) {
+	defer m.endSimulation()
+
+	roundID := fmt.Sprintf("%s%d", SIMULATION_ROUND_PREFIX, time.Now().UnixNano())
+	m.stateMutex.Lock()
+	m.currentRound = &RoundState{
+		RoundID:           roundID,
+		CrashMultiplier:   sim.CrashAt,
+		CurrentMultiplier: MIN_MULTIPLIER,
+		Status:            "BETTING",
+		StartTime:         time.Now(),
+		Simulated:         true,
+	}
+	m.stateMutex.Unlock()
+
+	log.Printf("\n=== SIMULATED ROUND %s (crash at %.2fx) ===", roundID, sim.CrashAt)
+	m.hub.Broadcast(map[string]interface{}{
+		"type":      "round_start",
+		"status":    "BETTING",
+		"round_id":  roundID,
+		"time_left": SIMULATION_BETTING_TIME.Seconds(),
+		"simulated": true,
+	})
+
+	// Answer anything queued so callers are not left waiting; processBet and
+	// processSideBet reject bets on a simulated round
+	bettingTimer := time.NewTimer(SIMULATION_BETTING_TIME)
+	defer bettingTimer.Stop()
+	for betting := true; betting; {
+		select {
+		case <-bettingTimer.C:
+			betting = false
+		case bet := <-m.betChannel:
+			resp, err := m.processBet(m.ctx, bet)
+			if bet.ResponseChan != nil {
+				bet.ResponseChan <- BetResult{Response: resp, Err: err}
+			}
+		case sideBet := <-m.sideBetChannel:
+			resp, err := m.processSideBet(m.ctx, sideBet)
+			if sideBet.ResponseChan != nil {
+				sideBet.ResponseChan <- SideBetResult{Response: resp, Err: err}
+			}
+		case <-m.stopChan:
+			return
+		}
+	}
+
+	m.stateMutex.Lock()
+	m.currentRound.Status = "RUNNING"
+	m.stateMutex.Unlock()
+	m.hub.Broadcast(map[string]interface{}{
+		"type":      "round_running",
+		"status":    "RUNNING",
+		"round_id":  roundID,
+		"simulated": true,
+	})
+
+	ticker := time.NewTicker(TICK_INTERVAL)
+	defer ticker.Stop()
+	startTime := time.Now()
+	for {
+		select {
+		case <-ticker.C:
+			elapsed := time.Since(startTime)
+			multiplier := math.Min(calculateMultiplier(elapsed.Seconds()), sim.CrashAt)
+			if multiplier >= sim.CrashAt || elapsed >= sim.Duration {
+				m.crashSimulation(roundID, multiplier)
+				return
+			}
+			m.stateMutex.Lock()
+			m.currentRound.CurrentMultiplier = multiplier
+			m.stateMutex.Unlock()
+			m.hub.Broadcast(map[string]interface{}{
+				"type":       "update",
+				"multiplier": multiplier,
+				"round_id":   roundID,
+				"simulated":  true,
+			})
+
+		case cashout := <-m.cashoutChannel:
+			if cashout.ResponseChan != nil {
+				cashout.ResponseChan <- CashoutResult{Response: CashoutResponse{Message: SIMULATION_BET_REJECTION}}
+			}
+
+		case <-m.stopChan:
+			return
+		}
+	}
+}
+
+func (m *Manager) crashSimulation(roundID string, multiplier float64) {
+	m.stateMutex.Lock()
+	m.currentRound.Status = "CRASHED"
+	m.currentRound.CurrentMultiplier = multiplier
+	m.currentRound.CrashTime = time.Now()
+	m.stateMutex.Unlock()
+
+	m.hub.Broadcast(map[string]interface{}{
+		"type":       "crash",
+		"multiplier": multiplier,
+		"round_id":   roundID,
+		"simulated":  true,
+	})
+	log.Printf("=== SIMULATED ROUND %s ENDED at %.2fx ===", roundID, multiplier)
+}
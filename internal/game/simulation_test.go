@@ -0,0 +1,81 @@
+package game
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestManager_SimulateCrash(t *testing.T) {
+	m, client := newTestManager(t)
+
+	for _, tt := range []struct {
+		crashAt  float64
+		duration time.Duration
+	}{
+		{1.0, 10 * time.Second},
+		{SIMULATION_MAX_CRASH_AT + 1, 10 * time.Second},
+		{2.0, 0},
+		{2.0, SIMULATION_MAX_DURATION + time.Second},
+	} {
+		if _, err := m.SimulateCrash(tt.crashAt, tt.duration); !errors.Is(err, ErrInvalidSimulation) {
+			t.Errorf("SimulateCrash(%v, %v) error = %v, want %v", tt.crashAt, tt.duration, err, ErrInvalidSimulation)
+		}
+	}
+
+	if _, err := m.SimulateCrash(2.0, 10*time.Second); err != nil {
+		t.Fatalf("SimulateCrash() error: %v", err)
+	}
+	if _, err := m.SimulateCrash(3.0, 10*time.Second); !errors.Is(err, ErrSimulationActive) {
+		t.Errorf("second SimulateCrash() error = %v, want %v", err, ErrSimulationActive)
+	}
+
+	client.Set(t.Context(), REDIS_KEY_USER_BALANCE+"user1", 100, 0)
+	m.Start()
+	t.Cleanup(m.Stop)
+
+	start := awaitBroadcast(t, m, "round_start")
+	if start["simulated"] != true {
+		t.Fatalf("round_start = %v, want a simulated round", start)
+	}
+	resp, err := m.PlaceBet(BetRequest{UserID: "user1", Amount: amountOf(10)})
+	if err != nil || resp.Success || resp.Message != SIMULATION_BET_REJECTION {
+		t.Errorf("PlaceBet() in a simulated round = %+v, %v; want it rejected", resp, err)
+	}
+	if balance, _ := client.Get(t.Context(), REDIS_KEY_USER_BALANCE+"user1").Float64(); balance != 100 {
+		t.Errorf("balance = %v, want 100 untouched", balance)
+	}
+
+	crash := awaitBroadcast(t, m, "crash")
+	if crash["simulated"] != true || crash["multiplier"] != 2.0 {
+		t.Errorf("crash = %v, want a simulated crash at 2.00x", crash)
+	}
+
+	// The real rounds resume after the simulation
+	next := awaitBroadcast(t, m, "round_start")
+	if next["simulated"] != nil {
+		t.Errorf("round after the simulation = %v, want a real round", next)
+	}
+	if _, err := m.SimulateCrash(2.0, 10*time.Second); err != nil {
+		t.Errorf("SimulateCrash() after the simulation ended error: %v", err)
+	}
+}
+
+// awaitBroadcast reads the queued broadcasts of m until one has msgType.
+// The hub is not running, so nothing else consumes them.
+func awaitBroadcast(t *testing.T, m *Manager, msgType string) map[string]interface{} {
+	t.Helper()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case message := <-m.hub.broadcast:
+			if msg, ok := message.(map[string]interface{}); ok && msg["type"] == msgType {
+				return msg
+			}
+		case <-timeout:
+			t.Fatalf("no %s message received", msgType)
+			return nil
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// Package testutil sets up game engines against an in-memory Redis for tests
+// outside the game package. Tests inside it cannot import testutil without an
+// import cycle and keep their own helpers.
+package testutil
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"aviator/internal/game"
+)
+
+// BALANCE_TOLERANCE absorbs the float rounding of balances kept with INCRBYFLOAT
+const BALANCE_TOLERANCE = 1e-6
+
+// NewTestRedis starts a miniredis server and returns a client for it, and a
+// func that closes both
+func NewTestRedis() (*redis.Client, func()) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		panic(err)
+	}
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	return client, func() {
+		client.Close()
+		mr.Close()
+	}
+}
+
+// NewTestHub returns a hub whose Run loop is already running
+func NewTestHub() *game.Hub {
+	hub := game.NewHub()
+	go hub.Run()
+	return hub
+}
+
+// newTestRedis is NewTestRedis closed when t ends
+func newTestRedis(t *testing.T) *redis.Client {
+	t.Helper()
+	client, cleanup := NewTestRedis()
+	t.Cleanup(cleanup)
+	return client
+}
+
+// NewTestMinesEngine returns a Mines engine on its own Redis, and the client
+// to seed balances with
+func NewTestMinesEngine(t *testing.T) (*game.MinesEngine, *redis.Client) {
+	t.Helper()
+	client := newTestRedis(t)
+	return game.NewMinesEngine(client, NewTestHub()), client
+}
+
+// NewTestDiceEngine returns a Dice engine on its own Redis, and the client to
+// seed balances with
+func NewTestDiceEngine(t *testing.T) (*game.DiceEngine, *redis.Client) {
+	t.Helper()
+	client := newTestRedis(t)
+	return game.NewDiceEngine(client, NewTestHub()), client
+}
+
+// NewTestPlinkoEngine returns a started Plinko engine on its own Redis, and
+// the client to seed balances with
+func NewTestPlinkoEngine(t *testing.T) (*game.PlinkoEngine, *redis.Client) {
+	t.Helper()
+	client := newTestRedis(t)
+	engine := game.NewPlinkoEngine(client, NewTestHub())
+	if err := engine.Start(context.Background()); err != nil {
+		t.Fatalf("failed to start the Plinko engine: %v", err)
+	}
+	t.Cleanup(func() { engine.Stop() })
+	return engine, client
+}
+
+// NewTestManager returns an Aviator round manager on its own Redis
+func NewTestManager(t *testing.T) (*game.Manager, *redis.Client) {
+	t.Helper()
+	client := newTestRedis(t)
+	return game.NewManager(NewTestHub(), client, nil, nil), client
+}
+
+// SetBalance sets a user's balance
+func SetBalance(client *redis.Client, userID string, amount float64) {
+	client.Set(context.Background(), game.REDIS_KEY_USER_BALANCE+userID, amount, 0)
+}
+
+// AssertBalanceEquals fails t unless the user's balance is within
+// BALANCE_TOLERANCE of expected
+func AssertBalanceEquals(t *testing.T, client *redis.Client, userID string, expected float64) {
+	t.Helper()
+	balance, err := client.Get(context.Background(), game.REDIS_KEY_USER_BALANCE+userID).Float64()
+	if err != nil {
+		t.Fatalf("failed to read the balance of %s: %v", userID, err)
+	}
+	if math.Abs(balance-expected) > BALANCE_TOLERANCE {
+		t.Errorf("balance of %s = %v, want %v", userID, balance, expected)
+	}
+}
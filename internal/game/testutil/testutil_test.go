@@ -0,0 +1,45 @@
+package testutil
+
+import (
+	"context"
+	"testing"
+
+	"aviator/internal/game"
+)
+
+func TestEngines(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("dice", func(t *testing.T) {
+		engine, client := NewTestDiceEngine(t)
+		SetBalance(client, "user1", 100)
+
+		resp, err := engine.PlaceBet(ctx, game.DiceRollRequest{UserID: "user1", Amount: 10, Target: 50, IsOver: true})
+		if err != nil || !resp.(game.DiceRollResponse).Success {
+			t.Fatalf("roll = %+v, %v; want success", resp, err)
+		}
+		AssertBalanceEquals(t, client, "user1", 90+resp.(game.DiceRollResponse).Payout.Float64())
+	})
+
+	t.Run("mines", func(t *testing.T) {
+		engine, client := NewTestMinesEngine(t)
+		SetBalance(client, "user1", 100)
+
+		resp, err := engine.PlaceBet(ctx, game.MinesBetRequest{UserID: "user1", Amount: 10, MineCount: 3})
+		if err != nil || !resp.(game.MinesBetResponse).Success {
+			t.Fatalf("bet = %+v, %v; want success", resp, err)
+		}
+		AssertBalanceEquals(t, client, "user1", 90)
+	})
+
+	t.Run("plinko", func(t *testing.T) {
+		engine, client := NewTestPlinkoEngine(t)
+		SetBalance(client, "user1", 100)
+
+		resp, err := engine.PlaceBet(ctx, game.PlinkoDropRequest{UserID: "user1", Amount: 10, Risk: game.PlinkoRiskLow, Rows: 8})
+		if err != nil || !resp.(game.PlinkoDropResponse).Success {
+			t.Fatalf("drop = %+v, %v; want success", resp, err)
+		}
+		AssertBalanceEquals(t, client, "user1", 90+resp.(game.PlinkoDropResponse).Payout.Float64())
+	})
+}
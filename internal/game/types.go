@@ -6,10 +6,18 @@ import (
 
 type BetRequest struct {
 	UserID       string  `json:"user_id"`
-	Amount       float64 `json:"amount"`
+	Amount       Amount  `json:"amount"`
 	AutoCashout  float64 `json:"auto_cashout,omitempty"`
 	RoundID      string  `json:"round_id"`
-	ResponseChan chan BetResponse `json:"-"`
+	ResponseChan chan BetResult `json:"-"`
+
+	// Delays the auto-cashout by up to this many ms (0-500) so bets on the
+	// same target do not all cash out on the same tick
+	AutoCashoutJitterMs int `json:"auto_cashout_jitter_ms,omitempty"`
+
+	// Percentage of the amount (0-5) charged up front so the bet can cash out
+	// without waiting on the game loop
+	EarlyExitFeePct float64 `json:"early_exit_fee_pct,omitempty"`
 }
 
 type BetResponse struct {
@@ -17,43 +25,81 @@ type BetResponse struct {
 	Message string  `json:"message"`
 	BetID   string  `json:"bet_id,omitempty"`
 	Balance float64 `json:"balance,omitempty"`
+
+	EarlyExitFeeCharged float64 `json:"early_exit_fee_charged,omitempty"`
+	Currency
+}
+
+// BetResult carries the outcome of a queued bet back to PlaceBet. Err is set
+// when the bet failed for a reason the player should not see.
+type BetResult struct {
+	Response BetResponse
+	Err      error
 }
 
 type CashoutRequest struct {
 	UserID       string `json:"user_id"`
 	BetID        string `json:"bet_id"`
 	RoundID      string `json:"round_id"`
-	ResponseChan chan CashoutResponse `json:"-"`
+	ResponseChan chan CashoutResult `json:"-"`
 }
 
 type CashoutResponse struct {
 	Success    bool    `json:"success"`
 	Message    string  `json:"message"`
 	Multiplier float64 `json:"multiplier,omitempty"`
-	Payout     float64 `json:"payout,omitempty"`
+	Payout     Amount  `json:"payout,omitempty"`
 	Balance    float64 `json:"balance,omitempty"`
+	Suspicious bool    `json:"suspicious,omitempty"`
+	Currency
+}
+
+// CashoutResult carries the outcome of a queued cashout back to Cashout
+type CashoutResult struct {
+	Response CashoutResponse
+	Err      error
 }
 
 type RoundState struct {
-	RoundID           string    `json:"round_id"`
-	ServerSeed        string    `json:"-"` // Never expose until reveal
-	HashCommitment    string    `json:"hash_commitment"`
-	ClientSeed        string    `json:"client_seed"`
-	CrashMultiplier   float64   `json:"-"` // Hidden until crash
-	CurrentMultiplier float64   `json:"current_multiplier"`
-	Status            string    `json:"status"` // BETTING, RUNNING, CRASHED
-	StartTime         time.Time `json:"start_time"`
-	CrashTime         time.Time `json:"crash_time,omitempty"`
-	Nonce             int       `json:"nonce"`
+	RoundID               string    `json:"round_id"`
+	ServerSeed            string    `json:"-"` // Never expose until reveal
+	HashCommitment        string    `json:"hash_commitment"`
+	CommitmentPublishedAt time.Time `json:"commitment_published_at"`
+	ClientSeed            string    `json:"client_seed"`
+	CrashMultiplier       float64   `json:"-"` // Hidden until crash; includes BonusMultiplier
+	BonusMultiplier       float64   `json:"bonus_multiplier,omitempty"` // Added by a CrashBonusEvent
+	CurrentMultiplier     float64   `json:"current_multiplier"`
+	Status                string    `json:"status"` // BETTING, RUNNING, CRASHED, ABORTED
+	StartTime             time.Time `json:"start_time"`
+	CrashTime             time.Time `json:"crash_time,omitempty"`
+	Nonce                 int       `json:"nonce"`
+
+	// Time spent in each phase. DelayDurationMs is the planned pause until
+	// the round has ended, then the measured one.
+	BettingDurationMs int64 `json:"betting_duration_ms"`
+	RunningDurationMs int64 `json:"running_duration_ms"`
+	DelayDurationMs   int64 `json:"delay_duration_ms"`
+
+	// Simulated rounds test the client with a fixed crash point and take no bets
+	Simulated bool `json:"simulated,omitempty"`
 }
 
 type ActiveBet struct {
-	BetID       string    `json:"bet_id"`
-	UserID      string    `json:"user_id"`
-	Amount      float64   `json:"amount"`
-	AutoCashout float64   `json:"auto_cashout"`
-	PlacedAt    time.Time `json:"placed_at"`
-	CashedOut   bool      `json:"cashed_out"`
+	BetID             string    `json:"bet_id"`
+	UserID            string    `json:"user_id"`
+	Amount            Amount    `json:"amount"`
+	AutoCashout       float64   `json:"auto_cashout"`
+	AutoCashoutJitter float64   `json:"auto_cashout_jitter,omitempty"` // Added to AutoCashout to trigger
+	PlacedAt          time.Time `json:"placed_at"`
+	CashedOut         bool      `json:"cashed_out"`
+	CashoutMultiplier float64   `json:"cashout_multiplier,omitempty"`
+	CashedOutAt       time.Time `json:"cashed_out_at,omitempty"`
+	EarlyExitFeePaid  bool      `json:"early_exit_fee_paid,omitempty"` // Cashouts skip the game loop queue
+}
+
+// triggerMultiplier is the multiplier at which the bet's auto-cashout triggers
+func (b ActiveBet) triggerMultiplier() float64 {
+	return b.AutoCashout + b.AutoCashoutJitter
 }
 
 type WSMessage struct {
@@ -62,14 +108,14 @@ type WSMessage struct {
 }
 
 type BetPlacedMessage struct {
-	UserID string  `json:"user_id"`
-	Amount float64 `json:"amount"`
-	BetID  string  `json:"bet_id"`
+	UserID string `json:"user_id"`
+	Amount Amount `json:"amount"`
+	BetID  string `json:"bet_id"`
 }
 
 type CashoutMessage struct {
 	UserID     string  `json:"user_id"`
 	BetID      string  `json:"bet_id"`
 	Multiplier float64 `json:"multiplier"`
-	Payout     float64 `json:"payout"`
+	Payout     Amount  `json:"payout"`
 }
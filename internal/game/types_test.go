@@ -2,6 +2,7 @@ package game
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 )
@@ -9,7 +10,7 @@ import (
 func TestBetRequest_JSON(t *testing.T) {
 	req := BetRequest{
 		UserID:      "user123",
-		Amount:      100.50,
+		Amount:      amountOf(100.50),
 		AutoCashout: 2.5,
 		RoundID:     "round_001",
 	}
@@ -20,6 +21,11 @@ func TestBetRequest_JSON(t *testing.T) {
 		t.Fatalf("Failed to marshal BetRequest: %v", err)
 	}
 
+	// Amounts stay plain JSON numbers on the wire
+	if !strings.Contains(string(data), `"amount":100.50`) {
+		t.Errorf("expected amount encoded as a number, got %s", data)
+	}
+
 	// Unmarshal back
 	var decoded BetRequest
 	if err := json.Unmarshal(data, &decoded); err != nil {
@@ -94,7 +100,7 @@ func TestCashoutResponse_JSON(t *testing.T) {
 		Success:    true,
 		Message:    "Cashed out successfully",
 		Multiplier: 2.45,
-		Payout:     245.00,
+		Payout:     amountOf(245.00),
 		Balance:    10145.00,
 	}
 
@@ -163,7 +169,7 @@ func TestActiveBet_JSON(t *testing.T) {
 	bet := ActiveBet{
 		BetID:       "bet_001",
 		UserID:      "user_001",
-		Amount:      100.00,
+		Amount:      amountOf(100.00),
 		AutoCashout: 2.0,
 		PlacedAt:    now,
 		CashedOut:   false,
@@ -217,7 +223,7 @@ func TestWSMessage_JSON(t *testing.T) {
 func TestBetPlacedMessage_JSON(t *testing.T) {
 	msg := BetPlacedMessage{
 		UserID: "user_123",
-		Amount: 500.00,
+		Amount: amountOf(500.00),
 		BetID:  "bet_456",
 	}
 
@@ -244,7 +250,7 @@ func TestCashoutMessage_JSON(t *testing.T) {
 		UserID:     "user_789",
 		BetID:      "bet_101",
 		Multiplier: 3.50,
-		Payout:     350.00,
+		Payout:     amountOf(350.00),
 	}
 
 	data, err := json.Marshal(msg)
@@ -0,0 +1,53 @@
+package game
+
+import (
+	"context"
+	"log"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// REDIS_KEY_USER_WAGERED holds the total a user has wagered across every game
+const REDIS_KEY_USER_WAGERED = "stats:wagered:"
+
+// recordWager adds a placed bet to the user's all-time wagered total
+func recordWager(ctx context.Context, client *redis.Client, userID string, amount Amount) {
+	if err := client.IncrByFloat(ctx, REDIS_KEY_USER_WAGERED+userID, amount.Float64()).Err(); err != nil {
+		log.Printf("[STATS] Failed to record the wager of %s: %v", userID, err)
+	}
+}
+
+// LoadWagered returns the all-time wagered totals of userIDs, in order, with
+// one MGET. Users who never bet have a total of 0.
+func LoadWagered(ctx context.Context, client *redis.Client, userIDs []string) ([]float64, error) {
+	return loadFloats(ctx, client, REDIS_KEY_USER_WAGERED, userIDs)
+}
+
+// LoadBalances returns the balances of userIDs, in order, with one MGET.
+// Users without a balance have a balance of 0.
+func LoadBalances(ctx context.Context, client *redis.Client, userIDs []string) ([]float64, error) {
+	return loadFloats(ctx, client, REDIS_KEY_USER_BALANCE, userIDs)
+}
+
+func loadFloats(ctx context.Context, client *redis.Client, prefix string, userIDs []string) ([]float64, error) {
+	values := make([]float64, len(userIDs))
+	if len(userIDs) == 0 {
+		return values, nil
+	}
+
+	keys := make([]string, len(userIDs))
+	for i, userID := range userIDs {
+		keys[i] = prefix + userID
+	}
+	stored, err := client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	for i, value := range stored {
+		if s, ok := value.(string); ok {
+			values[i], _ = strconv.ParseFloat(s, 64)
+		}
+	}
+	return values, nil
+}
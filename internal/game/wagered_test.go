@@ -0,0 +1,24 @@
+package game
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRecordWager(t *testing.T) {
+	client := newTestRedis(t)
+	ctx := context.Background()
+	client.Set(ctx, REDIS_KEY_USER_BALANCE+"user1", 100.0, 0)
+
+	engine := NewDiceEngine(client, nil)
+	for i := 0; i < 2; i++ {
+		if resp, err := engine.PlaceBet(ctx, DiceRollRequest{UserID: "user1", Amount: 2.5, Target: 50, IsOver: true}); err != nil || !resp.(DiceRollResponse).Success {
+			t.Fatalf("roll = %+v, %v; want success", resp, err)
+		}
+	}
+
+	wagered, err := LoadWagered(ctx, client, []string{"user1", "ghost"})
+	if err != nil || wagered[0] != 5 || wagered[1] != 0 {
+		t.Errorf("LoadWagered() = %v, %v; want [5 0]", wagered, err)
+	}
+}
@@ -0,0 +1,188 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"aviator/internal/database"
+)
+
+const (
+	REDIS_KEY_DEPOSIT_LIMIT    = "wallet:deposit_limit:"
+	REDIS_KEY_WITHDRAWAL_LIMIT = "wallet:withdrawal_limit:"
+
+	DEPOSIT_RATE_INTERVAL       = 5 * time.Second // At most one deposit per user
+	WITHDRAWAL_RATE_INTERVAL    = 5 * time.Second
+	WITHDRAWAL_MIN              = 10.0
+	DEPOSIT_TRANSACTION_TYPE    = "DEPOSIT"
+	WITHDRAWAL_TRANSACTION_TYPE = "WITHDRAWAL"
+
+	WALLET_HISTORY_DEFAULT_LIMIT = 50
+	WALLET_HISTORY_MAX_LIMIT     = 200
+)
+
+var (
+	ErrWalletAmount        = errors.New("amount must be greater than zero")
+	ErrWalletRateLimited   = errors.New("too many requests, try again shortly")
+	ErrWithdrawalBelowMin  = errors.New("amount is below the minimum withdrawal")
+	ErrInsufficientBalance = errors.New("insufficient balance")
+)
+
+// withdrawScript debits a balance only if it covers the amount.
+// KEYS: balance. ARGV: amount.
+// Returns {0, balance} when the balance is too low, otherwise {1, balance_before, balance_after}.
+var withdrawScript = redis.NewScript(`
+local balance = tonumber(redis.call('GET', KEYS[1]) or '0')
+if balance < tonumber(ARGV[1]) then
+	return {0, tostring(balance)}
+end
+
+local after = redis.call('INCRBYFLOAT', KEYS[1], -tonumber(ARGV[1]))
+return {1, tostring(balance), after}
+`)
+
+// WalletResponse reports a deposit or withdrawal
+type WalletResponse struct {
+	UserID  string  `json:"user_id"`
+	Type    string  `json:"type"`
+	Amount  Amount  `json:"amount"`
+	Balance float64 `json:"balance"`
+
+	FormattedBalance FormattedAmount `json:"formatted_balance"` // Set by the handler for display
+}
+
+// WalletService simulates deposits and withdrawals for development and demos.
+// Balances live in Redis like every other balance change; the transactions
+// table keeps the audit trail.
+type WalletService struct {
+	redisClient   *redis.Client
+	hub           *Hub
+	recorder      TransactionRecorder
+	withdrawalMin float64
+}
+
+// NewWalletService creates the service using WITHDRAWAL_MIN from the
+// environment. hub and recorder may be nil.
+func NewWalletService(redisClient *redis.Client, hub *Hub, recorder TransactionRecorder) *WalletService {
+	return &WalletService{
+		redisClient:   redisClient,
+		hub:           hub,
+		recorder:      recorder,
+		withdrawalMin: getEnvAsFloat("WITHDRAWAL_MIN", WITHDRAWAL_MIN),
+	}
+}
+
+// WithdrawalMin is the smallest amount that can be withdrawn
+func (w *WalletService) WithdrawalMin() float64 {
+	return w.withdrawalMin
+}
+
+// Deposit credits amount to a user's balance and tells their connections
+func (w *WalletService) Deposit(ctx context.Context, userID string, amount float64) (*WalletResponse, error) {
+	delta, err := walletAmount(amount)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.claimSlot(ctx, REDIS_KEY_DEPOSIT_LIMIT+userID, DEPOSIT_RATE_INTERVAL); err != nil {
+		return nil, err
+	}
+
+	balance, err := CreditBalance(ctx, w.redisClient, userID, delta.Float64())
+	if err != nil {
+		return nil, fmt.Errorf("credit deposit to %s: %w", userID, err)
+	}
+
+	if w.hub != nil {
+		message := BalanceUpdateMessage{
+			Type:    "balance_update",
+			Balance: balance,
+			Delta:   delta,
+			Reason:  BalanceReasonDeposit,
+		}
+		if err := w.hub.SendToUser(userID, message); err != nil {
+			log.Printf("[WALLET] Deposit notification for %s failed: %v", userID, err)
+		}
+	}
+	w.record(ctx, userID, DEPOSIT_TRANSACTION_TYPE, delta.Float64(), balance-delta.Float64(), balance)
+
+	log.Printf("[WALLET] Deposited %s for %s", delta, userID)
+	return &WalletResponse{UserID: userID, Type: DEPOSIT_TRANSACTION_TYPE, Amount: delta, Balance: balance}, nil
+}
+
+// Withdraw debits amount from a user's balance if it covers it
+func (w *WalletService) Withdraw(ctx context.Context, userID string, amount float64) (*WalletResponse, error) {
+	delta, err := walletAmount(amount)
+	if err != nil {
+		return nil, err
+	}
+	if amount < w.withdrawalMin {
+		return nil, fmt.Errorf("%w of %.2f", ErrWithdrawalBelowMin, w.withdrawalMin)
+	}
+	if err := w.claimSlot(ctx, REDIS_KEY_WITHDRAWAL_LIMIT+userID, WITHDRAWAL_RATE_INTERVAL); err != nil {
+		return nil, err
+	}
+
+	result, err := withdrawScript.Run(ctx, w.redisClient, []string{REDIS_KEY_USER_BALANCE + userID}, delta.Float64()).Slice()
+	if err != nil {
+		return nil, fmt.Errorf("debit withdrawal from %s: %w", userID, err)
+	}
+	if ok, _ := result[0].(int64); ok == 0 {
+		return nil, ErrInsufficientBalance
+	}
+	before, _ := strconv.ParseFloat(result[1].(string), 64)
+	balance, _ := strconv.ParseFloat(result[2].(string), 64)
+
+	w.hub.NotifyBalance(userID, balance, -delta, BalanceReasonWithdrawal)
+	w.record(ctx, userID, WITHDRAWAL_TRANSACTION_TYPE, delta.Float64(), before, balance)
+
+	log.Printf("[WALLET] Withdrew %s for %s", delta, userID)
+	return &WalletResponse{UserID: userID, Type: WITHDRAWAL_TRANSACTION_TYPE, Amount: delta, Balance: balance}, nil
+}
+
+// walletAmount validates a deposit or withdrawal amount
+func walletAmount(amount float64) (Amount, error) {
+	delta, err := NewAmount(amount)
+	if err != nil {
+		return 0, err
+	}
+	if delta <= 0 {
+		return 0, ErrWalletAmount
+	}
+	return delta, nil
+}
+
+// claimSlot allows one request per interval for key
+func (w *WalletService) claimSlot(ctx context.Context, key string, interval time.Duration) error {
+	ok, err := w.redisClient.SetNX(ctx, key, time.Now().Unix(), interval).Result()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrWalletRateLimited
+	}
+	return nil
+}
+
+func (w *WalletService) record(ctx context.Context, userID, txType string, amount, before, after float64) {
+	if w.recorder == nil {
+		return
+	}
+	tx := database.Transaction{
+		UserID:        userID,
+		Type:          txType,
+		Amount:        amount,
+		BalanceBefore: before,
+		BalanceAfter:  after,
+		Description:   "Simulated " + strings.ToLower(txType),
+	}
+	if err := w.recorder.RecordTransaction(ctx, tx); err != nil {
+		log.Printf("[WALLET] Failed to record %s for %s: %v", txType, userID, err)
+	}
+}
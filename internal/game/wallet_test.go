@@ -0,0 +1,59 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestWalletService(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	ctx := context.Background()
+
+	t.Setenv("WITHDRAWAL_MIN", "25")
+	ledger := &recordingLedger{}
+	wallet := NewWalletService(client, nil, ledger)
+
+	for _, amount := range []float64{0, -5, 1.234} {
+		if _, err := wallet.Deposit(ctx, "user1", amount); err == nil {
+			t.Errorf("Deposit(%v) succeeded, want an error", amount)
+		}
+	}
+
+	deposit, err := wallet.Deposit(ctx, "user1", 100)
+	if err != nil || deposit.Balance != 100 {
+		t.Fatalf("Deposit() = %+v, %v; want a balance of 100", deposit, err)
+	}
+	if _, err := wallet.Deposit(ctx, "user1", 100); !errors.Is(err, ErrWalletRateLimited) {
+		t.Errorf("second Deposit() error = %v, want %v", err, ErrWalletRateLimited)
+	}
+	mr.FastForward(DEPOSIT_RATE_INTERVAL)
+	if deposit, err := wallet.Deposit(ctx, "user1", 50); err != nil || deposit.Balance != 150 {
+		t.Errorf("Deposit() after the interval = %+v, %v; want a balance of 150", deposit, err)
+	}
+
+	if _, err := wallet.Withdraw(ctx, "user1", 20); !errors.Is(err, ErrWithdrawalBelowMin) {
+		t.Errorf("Withdraw() below WITHDRAWAL_MIN error = %v, want %v", err, ErrWithdrawalBelowMin)
+	}
+	if _, err := wallet.Withdraw(ctx, "user1", 150.01); !errors.Is(err, ErrInsufficientBalance) {
+		t.Errorf("Withdraw() above the balance error = %v, want %v", err, ErrInsufficientBalance)
+	}
+	mr.FastForward(WITHDRAWAL_RATE_INTERVAL)
+	withdrawal, err := wallet.Withdraw(ctx, "user1", 150)
+	if err != nil || withdrawal.Balance != 0 {
+		t.Errorf("Withdraw() of the whole balance = %+v, %v; want a balance of 0", withdrawal, err)
+	}
+
+	if len(ledger.txs) != 3 {
+		t.Fatalf("recorded %d transactions, want 2 deposits and a withdrawal", len(ledger.txs))
+	}
+	last := ledger.txs[2]
+	if last.Type != WITHDRAWAL_TRANSACTION_TYPE || last.Amount != 150 || last.BalanceBefore != 150 || last.BalanceAfter != 0 {
+		t.Errorf("withdrawal transaction = %+v, want 150 taken from 150", last)
+	}
+}
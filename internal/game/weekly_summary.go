@@ -0,0 +1,136 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"aviator/internal/database"
+	"aviator/internal/notifications"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	WEEKLY_SUMMARY_WEEKDAY = time.Monday
+	WEEKLY_SUMMARY_HOUR    = 8 // UTC
+	WEEKLY_SUMMARY_PERIOD  = 7 * 24 * time.Hour
+
+	REDIS_KEY_WEEKLY_SUMMARY_RUN = "summary:run:" // Claimed by the instance sending a week's summaries
+)
+
+// UserStatsSource aggregates users' bets over a period
+type UserStatsSource interface {
+	GetUserStats(ctx context.Context, from, to time.Time) ([]database.UserStats, error)
+}
+
+// WeeklySummaryJob sends every active user a summary of their past week
+// each Monday at 08:00 UTC
+type WeeklySummaryJob struct {
+	redisClient *redis.Client
+	stats       UserStatsSource
+	notifier    notifications.Notifier
+
+	stopChan chan struct{}
+	stopOnce sync.Once
+}
+
+func NewWeeklySummaryJob(redisClient *redis.Client, stats UserStatsSource, notifier notifications.Notifier) *WeeklySummaryJob {
+	return &WeeklySummaryJob{
+		redisClient: redisClient,
+		stats:       stats,
+		notifier:    notifier,
+		stopChan:    make(chan struct{}),
+	}
+}
+
+// Start sends the summaries at every scheduled time until Stop is called
+func (j *WeeklySummaryJob) Start() {
+	for {
+		next := nextWeeklySummaryRun(time.Now())
+		log.Printf("[SUMMARY] Next weekly summaries at %s", next.Format(time.RFC3339))
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-j.stopChan:
+			timer.Stop()
+			return
+		case <-timer.C:
+			sent, err := j.RunOnce(context.Background(), next)
+			if err != nil {
+				log.Printf("[SUMMARY] Run failed: %v", err)
+			}
+			log.Printf("[SUMMARY] Sent %d weekly summaries", sent)
+		}
+	}
+}
+
+// Stop ends the background loop
+func (j *WeeklySummaryJob) Stop() {
+	j.stopOnce.Do(func() { close(j.stopChan) })
+}
+
+// RunOnce sends a summary of the week ending at end to every user who bet
+// during it and returns how many were sent. Every instance runs the job, so
+// only the first to claim the week sends it; the others send nothing.
+func (j *WeeklySummaryJob) RunOnce(ctx context.Context, end time.Time) (int, error) {
+	claimed, err := j.redisClient.SetNX(ctx, REDIS_KEY_WEEKLY_SUMMARY_RUN+strconv.FormatInt(end.Unix(), 10), time.Now().Unix(), 2*WEEKLY_SUMMARY_PERIOD).Result()
+	if err != nil {
+		return 0, fmt.Errorf("claim weekly summary run: %w", err)
+	}
+	if !claimed {
+		return 0, nil
+	}
+
+	start := end.Add(-WEEKLY_SUMMARY_PERIOD)
+	stats, err := j.stats.GetUserStats(ctx, start, end)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, userStats := range stats {
+		if userStats.GamesPlayed == 0 {
+			continue
+		}
+		summary := buildWeeklySummary(userStats, start, end)
+		if err := j.notifier.SendWeeklySummary(ctx, userStats.UserID, summary); err != nil {
+			log.Printf("[SUMMARY] Failed to notify %s: %v", userStats.UserID, err)
+			continue
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// buildWeeklySummary turns a user's aggregated bets into the summary they are sent
+func buildWeeklySummary(stats database.UserStats, start, end time.Time) notifications.WeeklySummary {
+	return notifications.WeeklySummary{
+		TotalWagered: roundCents(stats.TotalWagered),
+		NetProfit:    roundCents(stats.TotalPayout - stats.TotalWagered),
+		GamesPlayed:  stats.GamesPlayed,
+		BiggestWin:   roundCents(stats.BiggestWin),
+		PeriodStart:  start.UTC(),
+		PeriodEnd:    end.UTC(),
+	}
+}
+
+// roundCents drops float error from sums of DECIMAL(20,2) amounts
+func roundCents(value float64) float64 {
+	return math.Round(value*AMOUNT_SCALE) / AMOUNT_SCALE
+}
+
+// nextWeeklySummaryRun returns the first Monday 08:00 UTC strictly after now
+func nextWeeklySummaryRun(now time.Time) time.Time {
+	now = now.UTC()
+	next := time.Date(now.Year(), now.Month(), now.Day(), WEEKLY_SUMMARY_HOUR, 0, 0, 0, time.UTC)
+	next = next.AddDate(0, 0, (int(WEEKLY_SUMMARY_WEEKDAY)-int(next.Weekday())+7)%7)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 7)
+	}
+	return next
+}
@@ -0,0 +1,108 @@
+package game
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"aviator/internal/database"
+	"aviator/internal/notifications"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+type fakeStatsSource []database.UserStats
+
+func (f fakeStatsSource) GetUserStats(ctx context.Context, from, to time.Time) ([]database.UserStats, error) {
+	return f, nil
+}
+
+type recordingNotifier map[string]notifications.WeeklySummary
+
+func (n recordingNotifier) SendWeeklySummary(ctx context.Context, userID string, summary notifications.WeeklySummary) error {
+	n[userID] = summary
+	return nil
+}
+
+func TestBuildWeeklySummary(t *testing.T) {
+	end := time.Date(2026, 1, 5, 8, 0, 0, 0, time.UTC)
+	start := end.Add(-WEEKLY_SUMMARY_PERIOD)
+
+	tests := []struct {
+		name  string
+		stats database.UserStats
+		want  notifications.WeeklySummary
+	}{
+		{
+			name:  "winning week",
+			stats: database.UserStats{GamesPlayed: 12, TotalWagered: 120.10, TotalPayout: 150.30, BiggestWin: 40},
+			want:  notifications.WeeklySummary{TotalWagered: 120.10, NetProfit: 30.20, GamesPlayed: 12, BiggestWin: 40},
+		},
+		{
+			name:  "losing week",
+			stats: database.UserStats{GamesPlayed: 3, TotalWagered: 30, TotalPayout: 0.1},
+			want:  notifications.WeeklySummary{TotalWagered: 30, NetProfit: -29.90, GamesPlayed: 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.want.PeriodStart, tt.want.PeriodEnd = start, end
+			if got := buildWeeklySummary(tt.stats, start, end); got != tt.want {
+				t.Errorf("buildWeeklySummary() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWeeklySummaryJob_RunOnce(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	notifier := recordingNotifier{}
+	job := NewWeeklySummaryJob(client, fakeStatsSource{
+		{UserID: "active", GamesPlayed: 2, TotalWagered: 20, TotalPayout: 25, BiggestWin: 5},
+		{UserID: "idle"},
+	}, notifier)
+
+	end := time.Date(2026, 1, 5, 8, 0, 0, 0, time.UTC)
+	sent, err := job.RunOnce(context.Background(), end)
+	if err != nil || sent != 1 {
+		t.Fatalf("RunOnce() = %d, %v; want 1 summary", sent, err)
+	}
+	summary, ok := notifier["active"]
+	if !ok || summary.NetProfit != 5 || !summary.PeriodStart.Equal(end.AddDate(0, 0, -7)) {
+		t.Errorf("summary = %+v, want a week of +5.00 for active", summary)
+	}
+	if _, ok := notifier["idle"]; ok {
+		t.Error("users without bets should not be notified")
+	}
+
+	// Another instance waking for the same week sends nothing
+	delete(notifier, "active")
+	other := NewWeeklySummaryJob(client, job.stats, notifier)
+	if sent, err := other.RunOnce(context.Background(), end); err != nil || sent != 0 {
+		t.Errorf("second RunOnce() for the week = %d, %v; want 0", sent, err)
+	}
+	if _, ok := notifier["active"]; ok {
+		t.Error("a week's summaries should be sent only once")
+	}
+}
+
+func TestNextWeeklySummaryRun(t *testing.T) {
+	tests := []struct {
+		now  time.Time
+		want time.Time
+	}{
+		{time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC), time.Date(2026, 1, 5, 8, 0, 0, 0, time.UTC)},                            // Thursday
+		{time.Date(2026, 1, 5, 7, 59, 0, 0, time.UTC), time.Date(2026, 1, 5, 8, 0, 0, 0, time.UTC)},                            // Monday morning
+		{time.Date(2026, 1, 5, 8, 0, 0, 0, time.UTC), time.Date(2026, 1, 12, 8, 0, 0, 0, time.UTC)},                            // Exactly on time
+		{time.Date(2026, 1, 4, 23, 0, 0, 0, time.FixedZone("UTC-10", -10*3600)), time.Date(2026, 1, 12, 8, 0, 0, 0, time.UTC)}, // Monday 09:00 UTC
+	}
+	for _, tt := range tests {
+		if got := nextWeeklySummaryRun(tt.now); !got.Equal(tt.want) {
+			t.Errorf("nextWeeklySummaryRun(%s) = %s, want %s", tt.now, got, tt.want)
+		}
+	}
+}
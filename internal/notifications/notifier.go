@@ -0,0 +1,48 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Notification providers selectable with NOTIFICATION_PROVIDER
+const (
+	ProviderLog = "log"
+)
+
+// WeeklySummary is a user's betting activity over one week
+type WeeklySummary struct {
+	TotalWagered float64   `json:"total_wagered"`
+	NetProfit    float64   `json:"net_profit"`
+	GamesPlayed  int       `json:"games_played"`
+	BiggestWin   float64   `json:"biggest_win"`
+	PeriodStart  time.Time `json:"period_start"`
+	PeriodEnd    time.Time `json:"period_end"`
+}
+
+// Notifier delivers messages to users
+type Notifier interface {
+	SendWeeklySummary(ctx context.Context, userID string, summary WeeklySummary) error
+}
+
+// New returns the notifier for a provider name. An empty name selects the log notifier.
+func New(provider string) (Notifier, error) {
+	switch provider {
+	case "", ProviderLog:
+		return LogNotifier{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported notification provider %q", provider)
+	}
+}
+
+// LogNotifier writes notifications to the log instead of delivering them
+type LogNotifier struct{}
+
+func (LogNotifier) SendWeeklySummary(ctx context.Context, userID string, summary WeeklySummary) error {
+	log.Printf("[NOTIFY] Weekly summary for %s (%s to %s): %d games, wagered %.2f, net %.2f, biggest win %.2f",
+		userID, summary.PeriodStart.Format(time.DateOnly), summary.PeriodEnd.Format(time.DateOnly),
+		summary.GamesPlayed, summary.TotalWagered, summary.NetProfit, summary.BiggestWin)
+	return nil
+}
@@ -0,0 +1,158 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	REDIS_KEY_OPERATOR_WEBHOOK_STATS = "operator_webhook:stats"
+
+	OPERATOR_WEBHOOK_MAX_RETRIES = 5
+	OPERATOR_WEBHOOK_RETRY_DELAY = 1 * time.Second // Doubled for each later retry
+	OPERATOR_WEBHOOK_TIMEOUT     = 10 * time.Second
+)
+
+// Settlement events sent to the operator webhook
+const (
+	EventAviatorCashout = "aviator_cashout"
+	EventAviatorCrash   = "aviator_crash" // A bet still riding when the round crashed
+	EventMinesCashout   = "mines_cashout"
+	EventMinesBust      = "mines_bust"
+	EventPlinkoDrop     = "plinko_drop"
+	EventDiceRoll       = "dice_roll"
+)
+
+// GameOutcomeEvent is one settled bet. Signature is the hex HMAC-SHA256 of
+// the event's JSON encoding without the signature field, keyed with the
+// operator's secret.
+type GameOutcomeEvent struct {
+	EventType      string    `json:"event_type"`
+	GameType       string    `json:"game_type"`
+	UserID         string    `json:"user_id"`
+	Amount         float64   `json:"amount"`
+	Payout         float64   `json:"payout"`
+	NetHouseProfit float64   `json:"net_house_profit"`
+	Timestamp      time.Time `json:"timestamp"`
+	Signature      string    `json:"signature,omitempty"`
+}
+
+// OperatorWebhookStats counts webhook deliveries. An event that failed every
+// retry counts once as failed.
+type OperatorWebhookStats struct {
+	TotalSent   int64   `json:"total_sent"`
+	Failed      int64   `json:"failed"`
+	SuccessRate float64 `json:"success_rate"` // 1 before anything is sent
+	LastError   string  `json:"last_error,omitempty"`
+}
+
+// OperatorWebhook posts every game settlement to the operator's URL
+type OperatorWebhook struct {
+	url         string
+	secret      string
+	httpClient  *http.Client
+	redisClient *redis.Client
+	retryDelay  time.Duration
+}
+
+// NewOperatorWebhook returns a webhook posting to url, or nil when url is
+// empty. Sending through a nil webhook does nothing.
+func NewOperatorWebhook(url, secret string, redisClient *redis.Client) *OperatorWebhook {
+	if url == "" {
+		return nil
+	}
+	return &OperatorWebhook{
+		url:         url,
+		secret:      secret,
+		httpClient:  &http.Client{Timeout: OPERATOR_WEBHOOK_TIMEOUT},
+		redisClient: redisClient,
+		retryDelay:  OPERATOR_WEBHOOK_RETRY_DELAY,
+	}
+}
+
+// SignOperatorEvent returns the signature of an event, ignoring any signature it already has
+func SignOperatorEvent(secret string, event GameOutcomeEvent) string {
+	event.Signature = ""
+	payload, _ := json.Marshal(event)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Send signs an event and delivers it in the background
+func (w *OperatorWebhook) Send(event GameOutcomeEvent) {
+	if w == nil {
+		return
+	}
+	go w.deliver(context.Background(), event)
+}
+
+// deliver posts an event, retrying non-2xx responses and network errors with
+// exponential backoff, and records the outcome in the delivery stats
+func (w *OperatorWebhook) deliver(ctx context.Context, event GameOutcomeEvent) error {
+	event.Signature = SignOperatorEvent(w.secret, event)
+	body, _ := json.Marshal(event)
+	w.redisClient.HIncrBy(ctx, REDIS_KEY_OPERATOR_WEBHOOK_STATS, "total_sent", 1)
+
+	var err error
+	for attempt := 0; attempt <= OPERATOR_WEBHOOK_MAX_RETRIES; attempt++ {
+		if attempt > 0 {
+			time.Sleep(w.retryDelay << (attempt - 1))
+		}
+		if err = w.post(ctx, body); err == nil {
+			return nil
+		}
+	}
+
+	log.Printf("[WEBHOOK] Giving up on %s for %s after %d retries: %v", event.EventType, event.UserID, OPERATOR_WEBHOOK_MAX_RETRIES, err)
+	pipe := w.redisClient.TxPipeline()
+	pipe.HIncrBy(ctx, REDIS_KEY_OPERATOR_WEBHOOK_STATS, "failed", 1)
+	pipe.HSet(ctx, REDIS_KEY_OPERATOR_WEBHOOK_STATS, "last_error", err.Error())
+	pipe.Exec(ctx)
+	return err
+}
+
+func (w *OperatorWebhook) post(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("operator webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// LoadOperatorWebhookStats reads the delivery stats
+func LoadOperatorWebhookStats(ctx context.Context, redisClient *redis.Client) (OperatorWebhookStats, error) {
+	values, err := redisClient.HGetAll(ctx, REDIS_KEY_OPERATOR_WEBHOOK_STATS).Result()
+	if err != nil {
+		return OperatorWebhookStats{}, err
+	}
+
+	stats := OperatorWebhookStats{SuccessRate: 1, LastError: values["last_error"]}
+	stats.TotalSent, _ = strconv.ParseInt(values["total_sent"], 10, 64)
+	stats.Failed, _ = strconv.ParseInt(values["failed"], 10, 64)
+	if stats.TotalSent > 0 {
+		stats.SuccessRate = float64(stats.TotalSent-stats.Failed) / float64(stats.TotalSent)
+	}
+	return stats, nil
+}
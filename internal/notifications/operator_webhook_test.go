@@ -0,0 +1,122 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// newTestOperatorWebhook returns a webhook posting to a server that fails the
+// first failures requests, and the times and bodies of every request
+func newTestOperatorWebhook(t *testing.T, failures int) (*OperatorWebhook, *redis.Client, func() ([]time.Time, [][]byte)) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var times []time.Time
+	var bodies [][]byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		times = append(times, time.Now())
+		bodies = append(bodies, body)
+		if len(times) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	webhook := NewOperatorWebhook(server.URL, "operator-secret", client)
+	webhook.retryDelay = 10 * time.Millisecond
+
+	return webhook, client, func() ([]time.Time, [][]byte) {
+		mu.Lock()
+		defer mu.Unlock()
+		return times, bodies
+	}
+}
+
+func testOutcomeEvent() GameOutcomeEvent {
+	return GameOutcomeEvent{
+		EventType:      EventDiceRoll,
+		GameType:       "dice",
+		UserID:         "user1",
+		Amount:         10,
+		Payout:         0,
+		NetHouseProfit: 10,
+		Timestamp:      time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestOperatorWebhook_Retries(t *testing.T) {
+	webhook, client, requests := newTestOperatorWebhook(t, 3)
+
+	if err := webhook.deliver(context.Background(), testOutcomeEvent()); err != nil {
+		t.Fatalf("deliver() error = %v, want success on the fourth attempt", err)
+	}
+
+	times, bodies := requests()
+	if len(times) != 4 {
+		t.Fatalf("got %d attempts, want 4", len(times))
+	}
+	for i := 1; i < len(times); i++ {
+		if gap, want := times[i].Sub(times[i-1]), webhook.retryDelay<<(i-1); gap < want {
+			t.Errorf("retry %d after %v, want at least %v", i, gap, want)
+		}
+	}
+
+	var received GameOutcomeEvent
+	if err := json.Unmarshal(bodies[3], &received); err != nil {
+		t.Fatalf("could not unmarshal payload: %v", err)
+	}
+	if received.Signature == "" || received.Signature != SignOperatorEvent("operator-secret", received) {
+		t.Errorf("payload signature %q does not verify", received.Signature)
+	}
+	if received.Signature == SignOperatorEvent("other-secret", received) {
+		t.Error("signature should depend on the secret")
+	}
+
+	stats, err := LoadOperatorWebhookStats(context.Background(), client)
+	if err != nil {
+		t.Fatalf("LoadOperatorWebhookStats() error = %v", err)
+	}
+	if stats.TotalSent != 1 || stats.Failed != 0 || stats.SuccessRate != 1 {
+		t.Errorf("stats = %+v, want one successful delivery", stats)
+	}
+}
+
+func TestOperatorWebhook_GivesUp(t *testing.T) {
+	webhook, client, requests := newTestOperatorWebhook(t, OPERATOR_WEBHOOK_MAX_RETRIES+1)
+
+	if err := webhook.deliver(context.Background(), testOutcomeEvent()); err == nil {
+		t.Fatal("deliver() should fail when every attempt fails")
+	}
+	if times, _ := requests(); len(times) != OPERATOR_WEBHOOK_MAX_RETRIES+1 {
+		t.Errorf("got %d attempts, want %d", len(times), OPERATOR_WEBHOOK_MAX_RETRIES+1)
+	}
+
+	stats, _ := LoadOperatorWebhookStats(context.Background(), client)
+	if stats.TotalSent != 1 || stats.Failed != 1 || stats.SuccessRate != 0 || stats.LastError == "" {
+		t.Errorf("stats = %+v, want one failed delivery with its error", stats)
+	}
+}
+
+func TestNewOperatorWebhook_Disabled(t *testing.T) {
+	webhook := NewOperatorWebhook("", "secret", nil)
+	if webhook != nil {
+		t.Fatal("a webhook without a URL should be nil")
+	}
+	webhook.Send(testOutcomeEvent()) // Must not panic
+}
@@ -0,0 +1,588 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"aviator/internal/game"
+	"aviator/internal/notifications"
+)
+
+const (
+	ADMIN_STREAM_INTERVAL = 1 * time.Second
+	LOG_STREAM_HEARTBEAT  = 15 * time.Second // Detects clients that left while no logs arrive
+
+	GOROUTINE_LEAK_THRESHOLD = 10000
+
+	// REDIS_KEY_HOUSE_PROFIT caches a house profit report, suffixed with its period
+	REDIS_KEY_HOUSE_PROFIT       = "admin:house_profit:"
+	HOUSE_PROFIT_DEFAULT_PERIOD  = "1h"
+	HOUSE_PROFIT_STREAM_INTERVAL = 5 * time.Second
+)
+
+// adminHealthHandler extends the public health report with the outcome of the
+// last balance audit run by cmd/audit
+func (s *FiberServer) adminHealthHandler(c *fiber.Ctx) error {
+	health, _ := s.healthReport(c.Context())
+
+	audit, err := game.LastBalanceAudit(c.Context(), s.cache.GetClient())
+	switch {
+	case err != nil:
+		log.Printf("[ADMIN] Failed to load the last balance audit: %v", err)
+		health["audit_status"] = "unknown"
+	case audit == nil:
+		health["last_audit_at"] = nil
+		health["audit_status"] = "never_run"
+	default:
+		health["last_audit_at"] = audit.LastAuditAt
+		health["audit_status"] = audit.AuditStatus
+		health["audit_summary"] = audit.Summary
+	}
+
+	return c.JSON(health)
+}
+
+// adminDBStatsHandler returns the statistics of the database connection pool
+// and the totals of the queries run on it
+func (s *FiberServer) adminDBStatsHandler(c *fiber.Ctx) error {
+	stats := s.db.Stats()
+	queries := s.db.QueryStats()
+	return c.JSON(fiber.Map{
+		"max_open_connections": stats.MaxOpenConnections,
+		"open_connections":     stats.OpenConnections,
+		"in_use":               stats.InUse,
+		"idle":                 stats.Idle,
+		"wait_count":           stats.WaitCount,
+		"wait_duration_ms":     stats.WaitDuration.Milliseconds(),
+		"max_idle_closed":      stats.MaxIdleClosed,
+		"max_idle_time_closed": stats.MaxIdleTimeClosed,
+		"max_lifetime_closed":  stats.MaxLifetimeClosed,
+
+		"total_queries":           queries.TotalQueries,
+		"avg_duration_ms":         queries.AvgDurationMs,
+		"max_duration_ms":         queries.MaxDurationMs,
+		"queries_above_threshold": queries.QueriesAboveThreshold,
+	})
+}
+
+// adminDBSlowQueriesHandler returns the last queries slower than the slow query threshold
+func (s *FiberServer) adminDBSlowQueriesHandler(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"queries": s.db.SlowQueries(),
+	})
+}
+
+// goroutineLeakThreshold returns GOROUTINE_LEAK_THRESHOLD from the
+// environment, or the default when it is unset or invalid
+func goroutineLeakThreshold() int {
+	threshold, err := strconv.Atoi(os.Getenv("GOROUTINE_LEAK_THRESHOLD"))
+	if err != nil || threshold < 1 {
+		return GOROUTINE_LEAK_THRESHOLD
+	}
+	return threshold
+}
+
+// adminPerformanceHandler reports runtime and queue metrics for spotting
+// goroutine and memory leaks
+func (s *FiberServer) adminPerformanceHandler(c *fiber.Ctx) error {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	goroutines := runtime.NumGoroutine()
+
+	var lastPause time.Duration
+	if mem.NumGC > 0 {
+		lastPause = time.Duration(mem.PauseNs[(mem.NumGC+255)%256])
+	}
+
+	activeMines := 0
+	if engine, exists := s.gameFactory.GetEngine(game.GameTypeMines); exists {
+		if stats, err := engine.ProcessAction(c.Context(), "active_count", nil); err == nil {
+			activeMines = stats.(game.MinesActiveStats).ActiveGames
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"goroutine_count":          goroutines,
+		"goroutine_leak_suspected": goroutines > goroutineLeakThreshold(),
+		"heap_alloc_mb":            float64(mem.HeapAlloc) / (1 << 20),
+		"heap_inuse_mb":            float64(mem.HeapInuse) / (1 << 20),
+		"gc_pause_ms_last":         float64(lastPause.Microseconds()) / 1000,
+		"num_gc":                   mem.NumGC,
+		"hub_client_count":         s.gameHub.GetClientCount(),
+		"bet_channel_len":          s.gameManager.BetChannelLen(),
+		"cashout_channel_len":      s.gameManager.CashoutChannelLen(),
+		"broadcast_channel_len":    s.gameHub.BroadcastChannelLen(),
+		"active_mines_games_count": activeMines,
+	})
+}
+
+// adminCapacityHandler reports how close each game is to its concurrent
+// session limit
+func (s *FiberServer) adminCapacityHandler(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"capacity": s.gameFactory.Capacity(c.Context()),
+	})
+}
+
+// liveSessionSorts compares two sessions by each sortable field, ascending
+var liveSessionSorts = map[string]func(a, b game.LiveSession) bool{
+	"wagered":  func(a, b game.LiveSession) bool { return a.BetAmount < b.BetAmount },
+	"exposure": func(a, b game.LiveSession) bool { return a.RiskExposure < b.RiskExposure },
+	"age":      func(a, b game.LiveSession) bool { return a.StartedAtAgoSeconds < b.StartedAtAgoSeconds },
+}
+
+// adminActiveSessionsHandler lists the Aviator bets and Mines games in
+// progress, optionally of one type, with the house exposure of all of them:
+//
+//	GET /api/v1/admin/sessions/active?type=mines&sort=wagered_desc&limit=50
+func (s *FiberServer) adminActiveSessionsHandler(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 50)
+	if limit < 1 || limit > 100 {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "limit must be between 1 and 100",
+		})
+	}
+
+	gameType := game.GameType(c.Query("type"))
+	switch gameType {
+	case "", game.GameTypeAviator, game.GameTypeMines:
+	default:
+		return c.Status(400).JSON(fiber.Map{
+			"error": "type must be aviator or mines",
+		})
+	}
+
+	field, ascending := strings.CutSuffix(c.Query("sort", "exposure_desc"), "_asc")
+	if !ascending {
+		field = strings.TrimSuffix(field, "_desc")
+	}
+	less, ok := liveSessionSorts[field]
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "sort must be wagered, exposure or age, optionally suffixed with _asc or _desc",
+		})
+	}
+
+	sessions, err := game.CollectLiveSessions(c.Context(), s.gameManager, s.gameFactory)
+	if err != nil {
+		log.Printf("[ADMIN] Failed to list live sessions: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to list active sessions",
+		})
+	}
+	if gameType != "" {
+		sessions = slices.DeleteFunc(sessions, func(session game.LiveSession) bool { return session.GameType != gameType })
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		if ascending {
+			return less(sessions[i], sessions[j])
+		}
+		return less(sessions[j], sessions[i])
+	})
+
+	return c.JSON(fiber.Map{
+		"sessions":             append([]game.LiveSession{}, sessions[:min(limit, len(sessions))]...),
+		"session_count":        len(sessions),
+		"total_house_exposure": game.TotalExposure(sessions),
+	})
+}
+
+// Round monitoring handlers
+
+func (s *FiberServer) adminActiveRoundHandler(c *fiber.Ctx) error {
+	info := s.gameManager.GetActiveRoundInfo(c.Context())
+	if info == nil {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "No active game round",
+		})
+	}
+	return c.JSON(info)
+}
+
+// adminRoundStreamHandler pushes the active round info as Server-Sent Events every second
+func (s *FiberServer) adminRoundStreamHandler(c *fiber.Ctx) error {
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		ticker := time.NewTicker(ADMIN_STREAM_INTERVAL)
+		defer ticker.Stop()
+
+		for {
+			info := s.gameManager.GetActiveRoundInfo(context.Background())
+			if info != nil {
+				data, _ := json.Marshal(info)
+				fmt.Fprintf(w, "event: round\ndata: %s\n\n", data)
+			} else {
+				fmt.Fprint(w, ": no active round\n\n")
+			}
+
+			// Flush fails once the client disconnects
+			if err := w.Flush(); err != nil {
+				return
+			}
+
+			<-ticker.C
+		}
+	})
+
+	return nil
+}
+
+// House profit handlers
+
+// adminHouseProfitHandler reports the house's profit on the bets placed within
+// ?period= (1h by default). Reports are cached for a tenth of their period.
+func (s *FiberServer) adminHouseProfitHandler(c *fiber.Ctx) error {
+	ctx := c.Context()
+	period := c.Query("period", HOUSE_PROFIT_DEFAULT_PERIOD)
+	duration, err := time.ParseDuration(period)
+	if err != nil || duration <= 0 {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "period must be a positive duration such as 1h",
+		})
+	}
+
+	client := s.cache.GetClient()
+	cacheKey := REDIS_KEY_HOUSE_PROFIT + period
+	var report game.HouseProfitReport
+	if cached, err := client.Get(ctx, cacheKey).Bytes(); err == nil && json.Unmarshal(cached, &report) == nil {
+		return c.JSON(report)
+	}
+
+	profits, err := s.db.HouseProfit(ctx, time.Now().Add(-duration))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to load house profit",
+		})
+	}
+	report = game.NewHouseProfitReport(profits)
+
+	data, _ := json.Marshal(report)
+	if err := client.Set(ctx, cacheKey, data, duration/10).Err(); err != nil {
+		log.Printf("[STATS] Failed to cache the house profit: %v", err)
+	}
+	return c.JSON(report)
+}
+
+// adminHouseProfitStreamHandler pushes the running house profit as
+// Server-Sent Events every five seconds
+func (s *FiberServer) adminHouseProfitStreamHandler(c *fiber.Ctx) error {
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+
+	client := s.cache.GetClient()
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		ticker := time.NewTicker(HOUSE_PROFIT_STREAM_INTERVAL)
+		defer ticker.Stop()
+
+		for {
+			profit, err := game.RunningHouseProfit(context.Background(), client)
+			if err == nil {
+				data, _ := json.Marshal(fiber.Map{"running_profit": profit, "at": time.Now()})
+				fmt.Fprintf(w, "event: profit\ndata: %s\n\n", data)
+			} else {
+				fmt.Fprint(w, ": profit unavailable\n\n")
+			}
+
+			// Flush fails once the client disconnects
+			if err := w.Flush(); err != nil {
+				return
+			}
+
+			<-ticker.C
+		}
+	})
+
+	return nil
+}
+
+// Notification handlers
+
+// adminTriggerWeeklySummaryHandler sends the weekly summaries for the week ending now
+func (s *FiberServer) adminTriggerWeeklySummaryHandler(c *fiber.Ctx) error {
+	end := time.Now().UTC()
+	sent, err := s.summaries.RunOnce(c.Context(), end)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to send weekly summaries",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"sent":         sent,
+		"period_start": end.Add(-game.WEEKLY_SUMMARY_PERIOD),
+		"period_end":   end,
+	})
+}
+
+// adminWebhookStatsHandler reports operator webhook deliveries
+func (s *FiberServer) adminWebhookStatsHandler(c *fiber.Ctx) error {
+	stats, err := notifications.LoadOperatorWebhookStats(c.Context(), s.cache.GetClient())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to load webhook stats",
+		})
+	}
+	return c.JSON(stats)
+}
+
+// BonusEventRequest starts a crash bonus event
+type BonusEventRequest struct {
+	BonusMultiplier float64 `json:"bonus_multiplier"`
+	DurationMinutes int     `json:"duration_minutes"`
+}
+
+// adminCrashBonusEventHandler raises the crash point of every round started in the next duration_minutes
+func (s *FiberServer) adminCrashBonusEventHandler(c *fiber.Ctx) error {
+	var req BonusEventRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	event, err := s.gameManager.StartBonusEvent(c.Context(), req.BonusMultiplier, time.Duration(req.DurationMinutes)*time.Minute)
+	if errors.Is(err, game.ErrInvalidBonusEvent) {
+		return c.Status(400).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to start bonus event",
+		})
+	}
+
+	log.Printf("[ADMIN] %s started a +%.2fx crash bonus event", adminIdentity(c), event.BonusMultiplier)
+	return c.Status(201).JSON(event)
+}
+
+// SimulationRequest schedules a simulated crash round
+type SimulationRequest struct {
+	CrashAt         float64 `json:"crash_at"`
+	DurationSeconds int     `json:"duration_seconds"`
+}
+
+// adminCrashSimulateHandler schedules a non-monetary round with a fixed crash
+// point in place of the next real round, for testing the client animation
+func (s *FiberServer) adminCrashSimulateHandler(c *fiber.Ctx) error {
+	var req SimulationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	sim, err := s.gameManager.SimulateCrash(req.CrashAt, time.Duration(req.DurationSeconds)*time.Second)
+	if errors.Is(err, game.ErrInvalidSimulation) {
+		return c.Status(400).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if errors.Is(err, game.ErrSimulationActive) {
+		return c.Status(409).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to schedule simulation",
+		})
+	}
+
+	log.Printf("[ADMIN] %s scheduled a simulated round crashing at %.2fx", adminIdentity(c), sim.CrashAt)
+	return c.Status(202).JSON(fiber.Map{
+		"crash_at":         sim.CrashAt,
+		"duration_seconds": req.DurationSeconds,
+		"message":          "Simulated round starts after the current round",
+	})
+}
+
+// Game handlers
+
+// MaintenanceRequest turns maintenance mode on or off for a game
+type MaintenanceRequest struct {
+	Enabled    bool   `json:"enabled"`
+	Message    string `json:"message"`
+	ETAMinutes int    `json:"eta_minutes"`
+}
+
+// adminGameMaintenanceHandler blocks or reopens new bets on a game. Games
+// already in progress are not interrupted.
+func (s *FiberServer) adminGameMaintenanceHandler(c *fiber.Ctx) error {
+	var req MaintenanceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+	if req.ETAMinutes < 0 {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "eta_minutes cannot be negative",
+		})
+	}
+
+	gameType := game.GameType(c.Params("type"))
+	var err error
+	if req.Enabled {
+		err = s.gameFactory.SetMaintenance(c.Context(), gameType, game.Maintenance{
+			Message:    req.Message,
+			ETAMinutes: req.ETAMinutes,
+		})
+	} else {
+		err = s.gameFactory.EndMaintenance(c.Context(), gameType)
+	}
+	if errors.Is(err, game.ErrUnknownGameType) {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Unknown game type",
+		})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to update maintenance mode",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"game_type":   gameType,
+		"maintenance": req.Enabled,
+	})
+}
+
+// Anomaly handlers
+
+func (s *FiberServer) adminAnomalyHandler(c *fiber.Ctx) error {
+	users, err := s.anomaly.FlaggedUsers(c.Context())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to load flagged users",
+		})
+	}
+	return c.JSON(users)
+}
+
+func (s *FiberServer) adminClearAnomalyHandler(c *fiber.Ctx) error {
+	userID := c.Params("userId")
+	cleared, err := s.anomaly.ClearFlags(c.Context(), userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to clear flags",
+		})
+	}
+	if !cleared {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "User has no anomaly flags",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"user_id": userID,
+		"message": "Anomaly flags cleared",
+	})
+}
+
+// Log handlers
+
+// logFilter reads the level and game_type query parameters
+func logFilter(c *fiber.Ctx) (LogFilter, error) {
+	filter := LogFilter{Level: c.Query("level"), GameType: c.Query("game_type")}
+	if filter.Level != "" {
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(filter.Level)); err != nil {
+			return filter, errors.New("level must be debug, info, warn or error")
+		}
+	}
+	return filter, nil
+}
+
+// adminLogsTailHandler returns the newest captured log entries, oldest first
+func (s *FiberServer) adminLogsTailHandler(c *fiber.Ctx) error {
+	if s.logs == nil {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "Log capture is not enabled",
+		})
+	}
+
+	limit := c.QueryInt("limit", 100)
+	if limit < 1 || limit > RING_LOG_CAPACITY {
+		return c.Status(400).JSON(fiber.Map{
+			"error": fmt.Sprintf("limit must be between 1 and %d", RING_LOG_CAPACITY),
+		})
+	}
+	filter, err := logFilter(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"entries": s.logs.Tail(filter, limit),
+		"dropped": s.logs.Dropped(),
+	})
+}
+
+// adminLogsStreamHandler pushes log entries as Server-Sent Events as they are
+// written. Entries are skipped while the client falls behind.
+func (s *FiberServer) adminLogsStreamHandler(c *fiber.Ctx) error {
+	if s.logs == nil {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "Log capture is not enabled",
+		})
+	}
+	filter, err := logFilter(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+
+	entries, unsubscribe := s.logs.Subscribe()
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer unsubscribe()
+
+		heartbeat := time.NewTicker(LOG_STREAM_HEARTBEAT)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case entry := <-entries:
+				if !filter.Matches(entry) {
+					continue
+				}
+				data, _ := json.Marshal(entry)
+				fmt.Fprintf(w, "event: log\ndata: %s\n\n", data)
+			case <-heartbeat.C:
+				fmt.Fprint(w, ": heartbeat\n\n")
+			}
+
+			// Flush fails once the client disconnects
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	})
+
+	return nil
+}
@@ -0,0 +1,658 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"aviator/internal/database"
+	"aviator/internal/game"
+	"aviator/internal/notifications"
+)
+
+func adminGet(t *testing.T, s *FiberServer, path, key string) (*http.Response, []byte) {
+	t.Helper()
+	return adminRequest(t, s, "GET", path, key)
+}
+
+func adminRequest(t *testing.T, s *FiberServer, method, path, key string) (*http.Response, []byte) {
+	t.Helper()
+
+	req, _ := http.NewRequest(method, path, nil)
+	if key != "" {
+		req.Header.Set("X-Admin-Key", key)
+	}
+
+	resp, err := s.App.Test(req)
+	if err != nil {
+		t.Fatalf("could not perform request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	return resp, body
+}
+
+func TestAdminAuth(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	t.Run("missing key", func(t *testing.T) {
+		resp, _ := adminGet(t, s, "/api/v1/admin/rounds/active", "")
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("expected status 401; got %v", resp.StatusCode)
+		}
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		resp, _ := adminGet(t, s, "/api/v1/admin/rounds/active", "wrong")
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("expected status 401; got %v", resp.StatusCode)
+		}
+	})
+
+	t.Run("admin disabled without configured key", func(t *testing.T) {
+		s.adminAPIKey = ""
+		defer func() { s.adminAPIKey = testAdminKey }()
+
+		resp, _ := adminGet(t, s, "/api/v1/admin/rounds/active", "")
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("expected status 403; got %v", resp.StatusCode)
+		}
+	})
+}
+
+func TestAdminActiveRoundHandler(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	t.Run("no active round", func(t *testing.T) {
+		resp, _ := adminGet(t, s, "/api/v1/admin/rounds/active", testAdminKey)
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("expected status 404; got %v", resp.StatusCode)
+		}
+	})
+
+	s.gameManager.Start()
+	t.Cleanup(s.gameManager.Stop)
+
+	var round *game.RoundState
+	deadline := time.Now().Add(time.Second)
+	for round == nil && time.Now().Before(deadline) {
+		round = s.gameManager.GetCurrentRound()
+		time.Sleep(5 * time.Millisecond)
+	}
+	if round == nil {
+		t.Fatal("timed out waiting for a round to start")
+	}
+
+	resp, body := adminGet(t, s, "/api/v1/admin/rounds/active", testAdminKey)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status OK; got %v", resp.StatusCode)
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(body, &result); err != nil {
+		t.Fatalf("could not unmarshal response: %v", err)
+	}
+
+	fields := []string{
+		"round_id", "status", "current_multiplier", "crash_multiplier_hash",
+		"active_bets_count", "total_wagered_this_round", "time_since_start_ms",
+		"betting_phase_remaining_ms",
+	}
+	for _, field := range fields {
+		if _, ok := result[field]; !ok {
+			t.Errorf("expected field %s in response", field)
+		}
+	}
+
+	if result["round_id"] != round.RoundID {
+		t.Errorf("expected round_id %s, got %v", round.RoundID, result["round_id"])
+	}
+
+	hash, _ := result["crash_multiplier_hash"].(string)
+	if len(hash) != 8 {
+		t.Errorf("expected 8 character hash prefix, got %q", hash)
+	}
+
+	crashPoint := fmt.Sprintf("%.2f", round.CrashMultiplier)
+	if strings.Contains(string(body), crashPoint) || strings.Contains(string(body), `"crash_multiplier"`) {
+		t.Errorf("response must not reveal the crash point %s: %s", crashPoint, body)
+	}
+}
+
+func TestAdminAnomalyHandlers(t *testing.T) {
+	s, client := newTestServer(t)
+	client.Set(context.Background(), game.REDIS_KEY_USER_BALANCE+"bot", 1000.0, 0)
+
+	// Roll fast enough to trip the frequency rule
+	engine, _ := s.gameFactory.GetEngine(game.GameTypeDice)
+	for i := 0; i <= game.ANOMALY_MAX_BETS_PER_MINUTE; i++ {
+		engine.PlaceBet(context.Background(), game.DiceRollRequest{UserID: "bot", Amount: 1, Target: 50, IsOver: true})
+	}
+
+	resp, body := adminGet(t, s, "/api/v1/admin/anomaly", testAdminKey)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status OK; got %v", resp.StatusCode)
+	}
+
+	var users []game.FlaggedUser
+	if err := json.Unmarshal(body, &users); err != nil {
+		t.Fatalf("could not unmarshal response: %v", err)
+	}
+	if len(users) != 1 || users[0].UserID != "bot" {
+		t.Fatalf("expected bot to be flagged, got %s", body)
+	}
+	found := false
+	for _, flag := range users[0].Flags {
+		if flag.Type == game.AnomalyHighFrequency && flag.Severity > 1 {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected a high_frequency flag, got %+v", users[0].Flags)
+	}
+
+	resp, _ = adminRequest(t, s, "POST", "/api/v1/admin/anomaly/bot/clear", testAdminKey)
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status OK; got %v", resp.StatusCode)
+	}
+
+	_, body = adminGet(t, s, "/api/v1/admin/anomaly", testAdminKey)
+	if string(body) != "[]" {
+		t.Errorf("expected no flagged users after clear, got %s", body)
+	}
+
+	resp, _ = adminRequest(t, s, "POST", "/api/v1/admin/anomaly/bot/clear", testAdminKey)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status 404 when nothing is flagged; got %v", resp.StatusCode)
+	}
+}
+
+func TestAdminTriggerWeeklySummaryHandler(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	resp, body := adminRequest(t, s, "POST", "/api/v1/admin/notifications/weekly/trigger", testAdminKey)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status OK; got %v", resp.StatusCode)
+	}
+
+	var result struct {
+		Sent int `json:"sent"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		t.Fatalf("could not unmarshal response: %v", err)
+	}
+	if result.Sent != 1 {
+		t.Errorf("expected one summary sent, got %s", body)
+	}
+}
+
+func TestAdminWebhookStatsHandler(t *testing.T) {
+	s, client := newTestServer(t)
+
+	_, body := adminGet(t, s, "/api/v1/admin/webhook/stats", testAdminKey)
+	var stats notifications.OperatorWebhookStats
+	if err := json.Unmarshal(body, &stats); err != nil {
+		t.Fatalf("could not unmarshal response: %v", err)
+	}
+	if stats.TotalSent != 0 || stats.SuccessRate != 1 {
+		t.Errorf("expected empty stats before any delivery, got %s", body)
+	}
+
+	client.HSet(context.Background(), notifications.REDIS_KEY_OPERATOR_WEBHOOK_STATS,
+		"total_sent", 4, "failed", 1, "last_error", "operator webhook returned 503 Service Unavailable")
+
+	resp, body := adminGet(t, s, "/api/v1/admin/webhook/stats", testAdminKey)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status OK; got %v", resp.StatusCode)
+	}
+	if err := json.Unmarshal(body, &stats); err != nil {
+		t.Fatalf("could not unmarshal response: %v", err)
+	}
+	if stats.TotalSent != 4 || stats.Failed != 1 || stats.SuccessRate != 0.75 || stats.LastError == "" {
+		t.Errorf("unexpected webhook stats: %s", body)
+	}
+}
+
+func adminJSON(t *testing.T, s *FiberServer, method, path string, body interface{}) (*http.Response, []byte) {
+	t.Helper()
+
+	data, _ := json.Marshal(body)
+	req, _ := http.NewRequest(method, path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Admin-Key", testAdminKey)
+
+	resp, err := s.App.Test(req)
+	if err != nil {
+		t.Fatalf("could not perform request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+	return resp, respBody
+}
+
+func TestAdminGameMaintenanceHandler(t *testing.T) {
+	s, client := newTestServer(t)
+	client.Set(t.Context(), game.REDIS_KEY_USER_BALANCE+"user1", 1000.0, 0)
+
+	conn := &mockConn{}
+	s.gameHub.RegisterClient(conn, "watcher")
+
+	bet := postJSON(t, s.App, "/api/v1/mines/bet", game.MinesBetRequest{UserID: "user1", Amount: 10, MineCount: 1})
+	gameID, _ := bet["game_id"].(string)
+	if gameID == "" {
+		t.Fatalf("expected game_id in bet response, got %v", bet)
+	}
+
+	resp, body := adminJSON(t, s, "PUT", "/api/v1/admin/games/mines/maintenance", MaintenanceRequest{
+		Enabled:    true,
+		Message:    "Upgrading the grid",
+		ETAMinutes: 15,
+	})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status OK; got %v: %s", resp.StatusCode, body)
+	}
+	if msg := conn.next(t); msg["type"] != "maintenance" || msg["game_type"] != "mines" || msg["eta_minutes"] != 15.0 {
+		t.Errorf("expected maintenance event for mines, got %v", msg)
+	}
+
+	t.Run("new bets are blocked", func(t *testing.T) {
+		data, _ := json.Marshal(game.MinesBetRequest{UserID: "user1", Amount: 10, MineCount: 1})
+		req, _ := http.NewRequest("POST", "/api/v1/mines/bet", bytes.NewReader(data))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := s.App.Test(req)
+		if err != nil {
+			t.Fatalf("could not perform request: %v", err)
+		}
+		if resp.StatusCode != http.StatusServiceUnavailable {
+			t.Fatalf("expected status 503; got %v", resp.StatusCode)
+		}
+		var result map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&result)
+		if result["error"] != "Game under maintenance" || result["message"] != "Upgrading the grid" || result["eta_minutes"] != 15.0 {
+			t.Errorf("unexpected maintenance response: %v", result)
+		}
+	})
+
+	t.Run("games in progress can still be played", func(t *testing.T) {
+		click := postJSON(t, s.App, "/api/v1/mines/click", game.MinesClickRequest{UserID: "user1", GameID: gameID, TileID: 0})
+		if click["success"] != true {
+			t.Errorf("expected click on an existing game to succeed, got %v", click)
+		}
+	})
+
+	t.Run("other games are unaffected", func(t *testing.T) {
+		roll := postJSON(t, s.App, "/api/v1/dice/roll", game.DiceRollRequest{UserID: "user1", Amount: 1, Target: 50, IsOver: true})
+		if roll["success"] != true {
+			t.Errorf("expected dice roll to succeed, got %v", roll)
+		}
+	})
+
+	t.Run("discovery shows maintenance", func(t *testing.T) {
+		info, _ := s.gameFactory.GameInfo(t.Context(), game.GameTypeMines)
+		if !info.Maintenance {
+			t.Errorf("expected mines under maintenance, got %+v", info)
+		}
+	})
+
+	resp, _ = adminJSON(t, s, "PUT", "/api/v1/admin/games/mines/maintenance", MaintenanceRequest{Enabled: false})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status OK; got %v", resp.StatusCode)
+	}
+	if msg := conn.next(t); msg["type"] != "maintenance_ended" || msg["game_type"] != "mines" {
+		t.Errorf("expected maintenance_ended event for mines, got %v", msg)
+	}
+	if bet := postJSON(t, s.App, "/api/v1/mines/bet", game.MinesBetRequest{UserID: "user1", Amount: 10, MineCount: 1}); bet["success"] != true {
+		t.Errorf("expected bets to reopen after maintenance, got %v", bet)
+	}
+
+	resp, _ = adminJSON(t, s, "PUT", "/api/v1/admin/games/roulette/maintenance", MaintenanceRequest{Enabled: true})
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status 404 for an unknown game; got %v", resp.StatusCode)
+	}
+}
+
+func TestAdminCrashBonusEventHandler(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	bonusEvents := func() []interface{} {
+		req, _ := http.NewRequest("GET", "/api/v1/promotions/crash", nil)
+		resp, err := s.App.Test(req)
+		if err != nil {
+			t.Fatalf("could not perform request: %v", err)
+		}
+		defer resp.Body.Close()
+		var result map[string][]interface{}
+		json.NewDecoder(resp.Body).Decode(&result)
+		return result["bonus_events"]
+	}
+
+	if events := bonusEvents(); len(events) != 0 {
+		t.Fatalf("expected no bonus events, got %v", events)
+	}
+
+	resp, body := adminJSON(t, s, "POST", "/api/v1/admin/crash/bonus-event", BonusEventRequest{BonusMultiplier: 0.5, DurationMinutes: 60})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status 201; got %v: %s", resp.StatusCode, body)
+	}
+
+	events := bonusEvents()
+	if len(events) != 1 || events[0].(map[string]interface{})["bonus_multiplier"] != 0.5 {
+		t.Errorf("expected the new bonus event, got %v", events)
+	}
+
+	resp, _ = adminJSON(t, s, "POST", "/api/v1/admin/crash/bonus-event", BonusEventRequest{BonusMultiplier: 50, DurationMinutes: 60})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("oversized bonus: expected status 400; got %v", resp.StatusCode)
+	}
+}
+
+func TestAdminCrashSimulateHandler(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	conn := &mockConn{}
+	s.gameHub.RegisterClient(conn, "spectator")
+	for deadline := time.Now().Add(time.Second); s.gameHub.GetClientCount() == 0; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out registering client")
+		}
+	}
+
+	resp, _ := adminJSON(t, s, "POST", "/api/v1/admin/crash/simulate", SimulationRequest{CrashAt: 0.5, DurationSeconds: 10})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("crash_at below 1: expected status 400; got %v", resp.StatusCode)
+	}
+
+	resp, body := adminJSON(t, s, "POST", "/api/v1/admin/crash/simulate", SimulationRequest{CrashAt: 2.0, DurationSeconds: 10})
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected status 202; got %v: %s", resp.StatusCode, body)
+	}
+	resp, _ = adminJSON(t, s, "POST", "/api/v1/admin/crash/simulate", SimulationRequest{CrashAt: 3.0, DurationSeconds: 10})
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("second simulation: expected status 409; got %v", resp.StatusCode)
+	}
+
+	// The simulation replaces the first round of the game loop
+	s.gameManager.Start()
+	t.Cleanup(s.gameManager.Stop)
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		conn.mu.Lock()
+		waiting := len(conn.messages) == 0
+		conn.mu.Unlock()
+		if waiting {
+			if time.Now().After(deadline) {
+				t.Fatal("timed out waiting for the simulated crash")
+			}
+			time.Sleep(10 * time.Millisecond)
+			continue
+		}
+
+		msg := conn.next(t)
+		if msg["simulated"] != true {
+			t.Fatalf("message %v is not flagged simulated", msg)
+		}
+		if msg["type"] == "crash" {
+			if msg["multiplier"] != 2.0 {
+				t.Errorf("crash multiplier = %v, want 2", msg["multiplier"])
+			}
+			break
+		}
+	}
+}
+
+func TestAdminHealthHandler(t *testing.T) {
+	s, client := newTestServer(t)
+
+	health := func() map[string]interface{} {
+		resp, body := adminRequest(t, s, "GET", "/api/v1/admin/health", testAdminKey)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected status OK; got %v", resp.StatusCode)
+		}
+		var result map[string]interface{}
+		if err := json.Unmarshal(body, &result); err != nil {
+			t.Fatalf("could not unmarshal response: %v", err)
+		}
+		return result
+	}
+
+	if result := health(); result["audit_status"] != "never_run" || result["last_audit_at"] != nil || result["database"] == nil {
+		t.Errorf("health before any audit = %v, want never_run", result)
+	}
+
+	auditAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	summary := game.BalanceAuditSummary{UsersChecked: 3, DiscrepanciesFound: 1, MaxDiscrepancy: 5, TotalDiscrepancy: 5}
+	if err := game.RecordBalanceAudit(context.Background(), client, summary, auditAt); err != nil {
+		t.Fatalf("RecordBalanceAudit() error: %v", err)
+	}
+	if result := health(); result["audit_status"] != game.AuditStatusDiscrepancies || result["last_audit_at"] != auditAt.Format(time.RFC3339) {
+		t.Errorf("health after an audit = %v, want its status and time", result)
+	}
+}
+
+func TestAdminDBStatsHandler(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	resp, body := adminRequest(t, s, "GET", "/api/v1/admin/db/stats", testAdminKey)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status OK; got %v", resp.StatusCode)
+	}
+	var stats map[string]float64
+	if err := json.Unmarshal(body, &stats); err != nil {
+		t.Fatalf("could not unmarshal response: %v", err)
+	}
+	if stats["max_open_connections"] != 25 || stats["open_connections"] != 2 || stats["idle"] != 2 || len(stats) != 13 {
+		t.Errorf("expected the pool stats, got %s", body)
+	}
+	if stats["total_queries"] != 4 || stats["max_duration_ms"] != 150 || stats["queries_above_threshold"] != 1 {
+		t.Errorf("expected the query stats, got %s", body)
+	}
+}
+
+func TestAdminDBSlowQueriesHandler(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	resp, body := adminRequest(t, s, "GET", "/api/v1/admin/db/slow-queries", testAdminKey)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status OK; got %v", resp.StatusCode)
+	}
+	var result struct {
+		Queries []database.SlowQuery `json:"queries"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		t.Fatalf("could not unmarshal response: %v", err)
+	}
+	if len(result.Queries) != 1 || result.Queries[0].Operation != "GetUserStats" || result.Queries[0].RowsReturned != 3 {
+		t.Errorf("expected the slow query log, got %s", body)
+	}
+}
+
+func TestAdminHouseProfitHandler(t *testing.T) {
+	s, client := newTestServer(t)
+	now := time.Now()
+	for _, settlement := range []database.Settlement{
+		{UserID: "user1", GameType: "aviator", Wager: 100, Payout: 0, SettledAt: now},
+		{UserID: "user2", GameType: "aviator", Wager: 50, Payout: 120, SettledAt: now},
+		{UserID: "user1", GameType: "dice", Wager: 40, Payout: 0, SettledAt: now},
+		{UserID: "user3", GameType: "aviator", Wager: 500, Payout: 0, SettledAt: now.Add(-2 * time.Hour)},
+	} {
+		s.db.RecordSettlement(t.Context(), settlement)
+	}
+
+	resp, body := adminRequest(t, s, "GET", "/api/v1/admin/house-profit", testAdminKey)
+	var report game.HouseProfitReport
+	json.Unmarshal(body, &report)
+	if resp.StatusCode != http.StatusOK || report.TotalWagered != 190 || report.TotalPaidOut != 120 || report.GrossProfit != 70 {
+		t.Errorf("expected 70 profit on the last hour's 190 wagered, got %d %s", resp.StatusCode, body)
+	}
+	if aviator := report.ByGameType[game.GameTypeAviator]; aviator.GrossProfitPct != 20 || len(report.ByGameType) != 4 {
+		t.Errorf("expected every game with aviator at 20%%, got %s", body)
+	}
+	if dice := report.ByGameType[game.GameTypeDice]; dice.TotalWagered != 40 || dice.GrossProfit != 40 {
+		t.Errorf("expected 40 profit on dice, got %s", body)
+	}
+	if ttl := client.TTL(t.Context(), REDIS_KEY_HOUSE_PROFIT+"1h").Val(); ttl <= 5*time.Minute || ttl > 6*time.Minute {
+		t.Errorf("expected the report cached for 6 minutes, got %v", ttl)
+	}
+
+	_, body = adminRequest(t, s, "GET", "/api/v1/admin/house-profit?period=3h", testAdminKey)
+	json.Unmarshal(body, &report)
+	if report.TotalWagered != 690 || report.GrossProfit != 570 {
+		t.Errorf("expected 570 profit over 3 hours, got %s", body)
+	}
+	if resp, _ := adminRequest(t, s, "GET", "/api/v1/admin/house-profit?period=-1h", testAdminKey); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400 for a negative period, got %d", resp.StatusCode)
+	}
+}
+
+func TestAdminCapacityHandler(t *testing.T) {
+	s, client := newTestServer(t)
+	client.Set(t.Context(), game.REDIS_KEY_USER_BALANCE+"user1", 100.0, 0)
+	client.Set(t.Context(), game.REDIS_KEY_MINES_ACTIVE_COUNT, 8500, 0)
+	client.Set(t.Context(), game.REDIS_KEY_PLINKO_ACTIVE_COUNT, game.PLINKO_MAX_CONCURRENT_DROPS, 0)
+
+	resp, body := adminGet(t, s, "/api/v1/admin/capacity", testAdminKey)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status OK; got %v", resp.StatusCode)
+	}
+	var result struct {
+		Capacity []game.CapacityInfo `json:"capacity"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		t.Fatalf("could not unmarshal response: %v", err)
+	}
+	if len(result.Capacity) != 3 {
+		t.Fatalf("expected capacity for 3 games, got %s", body)
+	}
+	if mines := result.Capacity[0]; mines.GameType != game.GameTypeMines || mines.ActiveSessions != 8500 || mines.UtilizationPct != 85 {
+		t.Errorf("expected mines at 85%%, got %+v", mines)
+	}
+
+	status, drop := postRaw(t, s, "/api/v1/plinko/drop", []byte(`{"user_id":"user1","amount":1,"risk":"low","rows":8}`))
+	if status != http.StatusServiceUnavailable || drop["error"] != "Server at capacity, try again shortly" {
+		t.Errorf("expected 503 at capacity, got %d %v", status, drop)
+	}
+}
+
+func TestAdminActiveSessionsHandler(t *testing.T) {
+	s, client := newTestServer(t)
+	client.Set(t.Context(), game.REDIS_KEY_USER_BALANCE+"user1", 100.0, 0)
+	postJSON(t, s.App, "/api/v1/mines/bet", game.MinesBetRequest{UserID: "user1", Amount: 10, MineCount: 24})
+	postJSON(t, s.App, "/api/v1/mines/bet", game.MinesBetRequest{UserID: "user1", Amount: 2, MineCount: 24})
+
+	resp, body := adminGet(t, s, "/api/v1/admin/sessions/active?type=mines&sort=wagered_asc&limit=1", testAdminKey)
+	var result struct {
+		Sessions           []game.LiveSession `json:"sessions"`
+		SessionCount       int                `json:"session_count"`
+		TotalHouseExposure float64            `json:"total_house_exposure"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("sessions = %d %s, %v", resp.StatusCode, body, err)
+	}
+	// Each game pays 24.25x for its one safe tile
+	if len(result.Sessions) != 1 || result.SessionCount != 2 || result.TotalHouseExposure != 279 {
+		t.Errorf("sessions = %s, want the smaller of 2 games and 279.00 of exposure", body)
+	}
+	if session := result.Sessions[0]; session.BetAmount.Float64() != 2 || session.RiskExposure.Float64() != 46.5 || session.UserID != "user1" {
+		t.Errorf("smallest session = %+v, want 2.00 at risk of 46.50", session)
+	}
+
+	if _, body = adminGet(t, s, "/api/v1/admin/sessions/active?type=aviator", testAdminKey); !strings.Contains(string(body), `"session_count":0`) {
+		t.Errorf("aviator sessions = %s, want none", body)
+	}
+	for _, query := range []string{"type=dice", "sort=payout_desc", "limit=0"} {
+		if resp, _ := adminGet(t, s, "/api/v1/admin/sessions/active?"+query, testAdminKey); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s = %d, want 400", query, resp.StatusCode)
+		}
+	}
+}
+
+func TestAdminLogsTailHandler(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	resp, _ := adminGet(t, s, "/api/v1/admin/logs/tail", testAdminKey)
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503 without log capture; got %v", resp.StatusCode)
+	}
+
+	s.logs = newTestRingLog(t)
+	s.logs.add(LogEntry{Level: "info", Message: "[SERVER] started"})
+	s.logs.add(LogEntry{Level: "error", Message: "[MINES] Failed", Fields: map[string]string{"game_type": "mines"}})
+	s.logs.add(LogEntry{Level: "error", Message: "[DICE] Failed", Fields: map[string]string{"game_type": "dice"}})
+
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		want   []string
+	}{
+		{name: "all", query: "", status: 200, want: []string{"[SERVER] started", "[MINES] Failed", "[DICE] Failed"}},
+		{name: "limit", query: "?limit=1", status: 200, want: []string{"[DICE] Failed"}},
+		{name: "errors in mines", query: "?level=error&game_type=mines", status: 200, want: []string{"[MINES] Failed"}},
+		{name: "bad level", query: "?level=loud", status: 400},
+		{name: "bad limit", query: "?limit=0", status: 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := adminGet(t, s, "/api/v1/admin/logs/tail"+tt.query, testAdminKey)
+			if resp.StatusCode != tt.status {
+				t.Fatalf("expected status %d; got %v: %s", tt.status, resp.StatusCode, body)
+			}
+			if tt.status != 200 {
+				return
+			}
+
+			var result struct {
+				Entries []LogEntry `json:"entries"`
+			}
+			json.Unmarshal(body, &result)
+			var messages []string
+			for _, entry := range result.Entries {
+				messages = append(messages, entry.Message)
+			}
+			if fmt.Sprint(messages) != fmt.Sprint(tt.want) {
+				t.Errorf("entries = %v, want %v", messages, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdminPerformanceHandler(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	for _, path := range []string{"/api/v1/admin/performance", "/api/v1/admin/performance/goroutines"} {
+		resp, body := adminGet(t, s, path, testAdminKey)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("%s: expected status OK; got %v", path, resp.StatusCode)
+		}
+		var metrics map[string]interface{}
+		if err := json.Unmarshal(body, &metrics); err != nil {
+			t.Fatalf("could not unmarshal response: %v", err)
+		}
+
+		for _, field := range []string{"goroutine_count", "heap_alloc_mb", "heap_inuse_mb", "gc_pause_ms_last", "num_gc",
+			"hub_client_count", "bet_channel_len", "cashout_channel_len", "broadcast_channel_len", "active_mines_games_count"} {
+			value, ok := metrics[field].(float64)
+			if !ok || value < 0 {
+				t.Errorf("%s: %s = %v, want a non-negative number", path, field, metrics[field])
+			}
+		}
+		if metrics["goroutine_count"].(float64) < 1 || metrics["goroutine_leak_suspected"] != false {
+			t.Errorf("%s: expected running goroutines without a suspected leak, got %s", path, body)
+		}
+	}
+
+	t.Setenv("GOROUTINE_LEAK_THRESHOLD", "1")
+	_, body := adminGet(t, s, "/api/v1/admin/performance", testAdminKey)
+	var metrics map[string]interface{}
+	json.Unmarshal(body, &metrics)
+	if metrics["goroutine_leak_suspected"] != true {
+		t.Errorf("expected a suspected leak above a threshold of 1, got %s", body)
+	}
+}
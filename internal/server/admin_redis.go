@@ -0,0 +1,181 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	ADMIN_REDIS_KEYS_LIMIT = 50
+
+	// Keys under this prefix coordinate the game loop and must never be deleted by hand
+	ADMIN_REDIS_PROTECTED_PREFIX = "crash:lock:"
+)
+
+// RedisKeyInfo describes one key found by the keyspace scan. TTL is in
+// seconds, -1 for keys without an expiry.
+type RedisKeyInfo struct {
+	Key       string `json:"key"`
+	Type      string `json:"type"`
+	TTL       int64  `json:"ttl"`
+	SizeBytes int64  `json:"size_bytes"`
+}
+
+// adminIdentity names the operator behind an admin request for the logs
+func adminIdentity(c *fiber.Ctx) string {
+	if user := c.Get("X-Admin-User"); user != "" {
+		return user
+	}
+	return "unknown@" + c.IP()
+}
+
+// adminRedisKeyParam returns the unescaped :key route parameter
+func adminRedisKeyParam(c *fiber.Ctx) string {
+	key, err := url.PathUnescape(c.Params("key"))
+	if err != nil {
+		return c.Params("key")
+	}
+	return key
+}
+
+// adminRedisKeysHandler lists up to 50 keys matching a pattern with their type, TTL and memory usage
+func (s *FiberServer) adminRedisKeysHandler(c *fiber.Ctx) error {
+	pattern := c.Query("pattern", "*")
+	limit := c.QueryInt("limit", ADMIN_REDIS_KEYS_LIMIT)
+	if limit < 1 {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "limit must be at least 1",
+		})
+	}
+	if limit > ADMIN_REDIS_KEYS_LIMIT {
+		limit = ADMIN_REDIS_KEYS_LIMIT
+	}
+
+	ctx := c.Context()
+	client := s.cache.GetClient()
+
+	var keys []string
+	iter := client.Scan(ctx, 0, pattern, 100).Iterator()
+	for len(keys) < limit && iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to scan keys",
+		})
+	}
+
+	pipe := client.Pipeline()
+	types := make([]*redis.StatusCmd, len(keys))
+	ttls := make([]*redis.DurationCmd, len(keys))
+	sizes := make([]*redis.IntCmd, len(keys))
+	for i, key := range keys {
+		types[i] = pipe.Type(ctx, key)
+		ttls[i] = pipe.TTL(ctx, key)
+		sizes[i] = pipe.MemoryUsage(ctx, key)
+	}
+	if len(keys) > 0 {
+		pipe.Exec(ctx) // Keys can expire mid-scan; their commands fail individually
+	}
+
+	infos := make([]RedisKeyInfo, 0, len(keys))
+	for i, key := range keys {
+		info := RedisKeyInfo{
+			Key:       key,
+			Type:      types[i].Val(),
+			TTL:       -1,
+			SizeBytes: sizes[i].Val(),
+		}
+		if ttl := ttls[i].Val(); ttl > 0 {
+			info.TTL = int64(ttl / time.Second)
+		}
+		infos = append(infos, info)
+	}
+
+	return c.JSON(infos)
+}
+
+// adminRedisKeyHandler returns the value of a key decoded according to its type
+func (s *FiberServer) adminRedisKeyHandler(c *fiber.Ctx) error {
+	ctx := c.Context()
+	client := s.cache.GetClient()
+	key := adminRedisKeyParam(c)
+
+	keyType, err := client.Type(ctx, key).Result()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to read key",
+		})
+	}
+
+	var value interface{}
+	switch keyType {
+	case "none":
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Key not found",
+		})
+	case "string":
+		data, err := client.Get(ctx, key).Result()
+		if err == nil && json.Unmarshal([]byte(data), &value) != nil {
+			value = data
+		}
+	case "hash":
+		value, err = client.HGetAll(ctx, key).Result()
+	case "list":
+		value, err = client.LRange(ctx, key, 0, -1).Result()
+	case "set":
+		value, err = client.SMembers(ctx, key).Result()
+	case "zset":
+		value, err = client.ZRangeWithScores(ctx, key, 0, -1).Result()
+	default:
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Unsupported key type " + keyType,
+		})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to read key",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"key":   key,
+		"type":  keyType,
+		"value": value,
+	})
+}
+
+// adminDeleteRedisKeyHandler deletes a key. Round locks are refused.
+func (s *FiberServer) adminDeleteRedisKeyHandler(c *fiber.Ctx) error {
+	key := adminRedisKeyParam(c)
+	if strings.HasPrefix(key, ADMIN_REDIS_PROTECTED_PREFIX) {
+		log.Printf("[ADMIN] %s was refused deletion of protected key %s", adminIdentity(c), key)
+		return c.Status(403).JSON(fiber.Map{
+			"error": "Key is protected",
+		})
+	}
+
+	deleted, err := s.cache.GetClient().Del(c.Context(), key).Result()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to delete key",
+		})
+	}
+	if deleted == 0 {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Key not found",
+		})
+	}
+
+	log.Printf("[ADMIN] %s deleted Redis key %s", adminIdentity(c), key)
+	return c.JSON(fiber.Map{
+		"key":     key,
+		"deleted": true,
+	})
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"aviator/internal/game"
+)
+
+func TestAdminRedisKeys(t *testing.T) {
+	s, client := newTestServer(t)
+	ctx := t.Context()
+
+	client.Set(ctx, game.REDIS_KEY_MINES_GAME+"G1", `{"game_id":"G1","status":"ACTIVE"}`, time.Hour)
+	client.Set(ctx, game.REDIS_KEY_MINES_GAME+"G2", "plain", 0)
+	client.HSet(ctx, game.REDIS_KEY_MINES_TILES_RECORD+"user1", "mine_count", "3")
+	client.Set(ctx, game.REDIS_KEY_ROUND_LOCK, "holder", 0)
+
+	t.Run("scan matches the pattern", func(t *testing.T) {
+		resp, body := adminGet(t, s, "/api/v1/admin/redis/keys?pattern=mines:game:*", testAdminKey)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected status OK; got %v", resp.StatusCode)
+		}
+
+		var keys []RedisKeyInfo
+		if err := json.Unmarshal(body, &keys); err != nil {
+			t.Fatalf("could not unmarshal response: %v", err)
+		}
+		if len(keys) != 2 {
+			t.Fatalf("expected 2 mines game keys, got %s", body)
+		}
+		for _, info := range keys {
+			if info.Type != "string" || info.SizeBytes <= 0 {
+				t.Errorf("unexpected key info %+v", info)
+			}
+			if info.Key == game.REDIS_KEY_MINES_GAME+"G1" && info.TTL <= 0 {
+				t.Errorf("expected a TTL on %s, got %d", info.Key, info.TTL)
+			}
+			if info.Key == game.REDIS_KEY_MINES_GAME+"G2" && info.TTL != -1 {
+				t.Errorf("expected no TTL on %s, got %d", info.Key, info.TTL)
+			}
+		}
+	})
+
+	t.Run("values are decoded by type", func(t *testing.T) {
+		_, body := adminGet(t, s, "/api/v1/admin/redis/key/mines:game:G1", testAdminKey)
+		var result struct {
+			Type  string                 `json:"type"`
+			Value map[string]interface{} `json:"value"`
+		}
+		json.Unmarshal(body, &result)
+		if result.Type != "string" || result.Value["status"] != "ACTIVE" {
+			t.Errorf("expected parsed JSON game state, got %s", body)
+		}
+
+		_, body = adminGet(t, s, "/api/v1/admin/redis/key/"+game.REDIS_KEY_MINES_TILES_RECORD+"user1", testAdminKey)
+		json.Unmarshal(body, &result)
+		if result.Type != "hash" || result.Value["mine_count"] != "3" {
+			t.Errorf("expected hash fields, got %s", body)
+		}
+
+		resp, _ := adminGet(t, s, "/api/v1/admin/redis/key/missing", testAdminKey)
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("expected status 404 for a missing key; got %v", resp.StatusCode)
+		}
+	})
+
+	t.Run("round lock cannot be deleted", func(t *testing.T) {
+		resp, _ := adminRequest(t, s, "DELETE", "/api/v1/admin/redis/key/"+game.REDIS_KEY_ROUND_LOCK, testAdminKey)
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("expected status 403; got %v", resp.StatusCode)
+		}
+		if client.Exists(ctx, game.REDIS_KEY_ROUND_LOCK).Val() != 1 {
+			t.Error("round lock should not be deleted")
+		}
+	})
+
+	t.Run("other keys can be deleted", func(t *testing.T) {
+		resp, _ := adminRequest(t, s, "DELETE", "/api/v1/admin/redis/key/mines:game:G2", testAdminKey)
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("expected status OK; got %v", resp.StatusCode)
+		}
+		if client.Exists(ctx, game.REDIS_KEY_MINES_GAME+"G2").Val() != 0 {
+			t.Error("key should be deleted")
+		}
+	})
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"crypto/subtle"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (s *FiberServer) RegisterAdminRoutes() {
+	admin := s.App.Group("/api/v1/admin", s.adminAuth)
+
+	admin.Get("/health", s.adminHealthHandler)
+	admin.Get("/db/stats", s.adminDBStatsHandler)
+	admin.Get("/db/slow-queries", s.adminDBSlowQueriesHandler)
+	admin.Get("/performance", s.adminPerformanceHandler)
+	admin.Get("/performance/goroutines", s.adminPerformanceHandler)
+	admin.Get("/capacity", s.adminCapacityHandler)
+	admin.Get("/sessions/active", s.adminActiveSessionsHandler)
+
+	// Round monitoring
+	admin.Get("/rounds/active", s.adminActiveRoundHandler)
+	admin.Get("/rounds/stream", s.adminRoundStreamHandler)
+	admin.Post("/crash/bonus-event", s.adminCrashBonusEventHandler)
+	admin.Post("/crash/simulate", s.adminCrashSimulateHandler)
+
+	// House profit
+	admin.Get("/house-profit", s.adminHouseProfitHandler)
+	admin.Get("/house-profit/stream", s.adminHouseProfitStreamHandler)
+
+	// Users
+	admin.Get("/users", s.adminUsersHandler)
+	admin.Get("/users/export", s.adminUsersExportHandler)
+
+	// Notifications
+	admin.Post("/notifications/weekly/trigger", s.adminTriggerWeeklySummaryHandler)
+	admin.Get("/webhook/stats", s.adminWebhookStatsHandler)
+
+	// Games
+	admin.Put("/games/:type/maintenance", s.adminGameMaintenanceHandler)
+	admin.Post("/games/:type/maintenance", s.adminGameMaintenanceHandler)
+
+	// Redis diagnostics
+	admin.Get("/redis/keys", s.adminRedisKeysHandler)
+	admin.Get("/redis/key/:key", s.adminRedisKeyHandler)
+	admin.Delete("/redis/key/:key", s.adminDeleteRedisKeyHandler)
+
+	// Logs
+	admin.Get("/logs/tail", s.adminLogsTailHandler)
+	admin.Get("/logs/stream", s.adminLogsStreamHandler)
+
+	// Suspicious activity
+	admin.Get("/anomaly", s.adminAnomalyHandler)
+	admin.Post("/anomaly/:userId/clear", s.adminClearAnomalyHandler)
+}
+
+// adminAuth requires the X-Admin-Key header to match ADMIN_API_KEY.
+// Admin routes are disabled entirely when no key is configured.
+func (s *FiberServer) adminAuth(c *fiber.Ctx) error {
+	if s.adminAPIKey == "" {
+		return c.Status(403).JSON(fiber.Map{
+			"error": "Admin API is disabled",
+		})
+	}
+
+	if !s.validAdminKey(c.Get("X-Admin-Key")) {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "Invalid admin key",
+		})
+	}
+
+	return c.Next()
+}
+
+// adminTokenAuth guards single admin-only routes outside the admin group.
+// They take the admin key in the X-Admin-Token header instead.
+func (s *FiberServer) adminTokenAuth(c *fiber.Ctx) error {
+	if s.adminAPIKey == "" {
+		return c.Status(403).JSON(fiber.Map{
+			"error": "Admin API is disabled",
+		})
+	}
+
+	if !s.validAdminKey(c.Get("X-Admin-Token")) {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "Invalid admin token",
+		})
+	}
+
+	return c.Next()
+}
+
+// validAdminKey reports whether key matches ADMIN_API_KEY. No key is valid
+// while admin routes are disabled.
+func (s *FiberServer) validAdminKey(key string) bool {
+	return s.adminAPIKey != "" && subtle.ConstantTimeCompare([]byte(key), []byte(s.adminAPIKey)) == 1
+}
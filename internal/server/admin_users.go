@@ -0,0 +1,262 @@
+package server
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"aviator/internal/database"
+	"aviator/internal/game"
+)
+
+// AdminUser is a user joined with the balance and stats kept in Redis
+type AdminUser struct {
+	UserID              string     `json:"user_id"`
+	Username            string     `json:"username"`
+	Status              string     `json:"status"`
+	Balance             float64    `json:"balance"`
+	LastSeenAt          *time.Time `json:"last_seen_at"`
+	TotalWageredAllTime float64    `json:"total_wagered_alltime"`
+	ActiveGamesCount    int        `json:"active_games_count"` // Mines games and Aviator bets in progress
+}
+
+// adminUserSorts compares two users by each sortable field, ascending
+var adminUserSorts = map[string]func(a, b AdminUser) bool{
+	"balance":       func(a, b AdminUser) bool { return a.Balance < b.Balance },
+	"total_wagered": func(a, b AdminUser) bool { return a.TotalWageredAllTime < b.TotalWageredAllTime },
+	"last_seen": func(a, b AdminUser) bool {
+		if a.LastSeenAt == nil || b.LastSeenAt == nil {
+			return a.LastSeenAt == nil && b.LastSeenAt != nil // Never seen counts as oldest
+		}
+		return a.LastSeenAt.Before(*b.LastSeenAt)
+	},
+}
+
+// adminUserQuery filters and orders the users an admin lists or exports
+type adminUserQuery struct {
+	status     string
+	sortBy     string // A key of adminUserSorts, or "" for newest first
+	descending bool
+	minBalance float64 // Only applied when hasMinimum is set
+	hasMinimum bool
+}
+
+// parseAdminUserQuery reads status, sort (a field, optionally suffixed with
+// _asc or _desc, descending by default) and filter_min_balance
+func parseAdminUserQuery(c *fiber.Ctx) (adminUserQuery, error) {
+	query := adminUserQuery{status: c.Query("status")}
+	switch query.status {
+	case "", database.UserStatusActive, database.UserStatusSuspended, database.UserStatusBanned:
+	default:
+		return query, errors.New("Invalid status")
+	}
+
+	if sortParam := c.Query("sort"); sortParam != "" {
+		field, ascending := strings.CutSuffix(sortParam, "_asc")
+		if !ascending {
+			field = strings.TrimSuffix(field, "_desc")
+		}
+		if _, ok := adminUserSorts[field]; !ok {
+			return query, errors.New("sort must be balance, last_seen or total_wagered, optionally suffixed with _asc or _desc")
+		}
+		query.sortBy, query.descending = field, !ascending
+	}
+
+	if minBalance := c.Query("filter_min_balance"); minBalance != "" {
+		value, err := strconv.ParseFloat(minBalance, 64)
+		if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+			return query, errors.New("filter_min_balance must be a number")
+		}
+		query.minBalance, query.hasMinimum = value, true
+	}
+	return query, nil
+}
+
+// adminUsersHandler lists a page of users with their balances. Sorting or
+// filtering by a Redis value loads every user first, since PostgreSQL can't
+// order by it.
+func (s *FiberServer) adminUsersHandler(c *fiber.Ctx) error {
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 50)
+	if page < 1 || limit < 1 || limit > 100 {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "page must be at least 1 and limit between 1 and 100",
+		})
+	}
+
+	query, err := parseAdminUserQuery(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	var users []AdminUser
+	if query.sortBy == "" && !query.hasMinimum {
+		var dbUsers []database.User
+		dbUsers, err = s.db.ListUsers(c.Context(), query.status, page, limit)
+		if err == nil {
+			users, err = s.joinAdminUsers(c.Context(), dbUsers)
+		}
+	} else {
+		users, err = s.queryAdminUsers(c.Context(), query)
+		from := min((page-1)*limit, len(users))
+		users = users[from:min(from+limit, len(users))]
+	}
+	if err == nil {
+		err = s.countActiveGames(c.Context(), users)
+	}
+	if err != nil {
+		log.Printf("[ADMIN] Failed to list users: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to list users",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"users": users,
+		"page":  page,
+		"limit": limit,
+	})
+}
+
+// adminUsersExportHandler writes every user matching the list filters as CSV
+func (s *FiberServer) adminUsersExportHandler(c *fiber.Ctx) error {
+	query, err := parseAdminUserQuery(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	users, err := s.queryAdminUsers(c.Context(), query)
+	if err == nil {
+		err = s.countActiveGames(c.Context(), users)
+	}
+	if err != nil {
+		log.Printf("[ADMIN] Failed to export users: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to export users",
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="users.csv"`)
+	w := csv.NewWriter(c)
+	w.Write([]string{"user_id", "username", "status", "balance", "last_seen_at", "total_wagered_alltime", "active_games_count"})
+	for _, user := range users {
+		lastSeen := ""
+		if user.LastSeenAt != nil {
+			lastSeen = user.LastSeenAt.UTC().Format(time.RFC3339)
+		}
+		w.Write([]string{
+			user.UserID,
+			user.Username,
+			user.Status,
+			strconv.FormatFloat(user.Balance, 'f', 2, 64),
+			lastSeen,
+			strconv.FormatFloat(user.TotalWageredAllTime, 'f', 2, 64),
+			strconv.Itoa(user.ActiveGamesCount),
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// queryAdminUsers loads every user matching query, filtered and sorted
+func (s *FiberServer) queryAdminUsers(ctx context.Context, query adminUserQuery) ([]AdminUser, error) {
+	dbUsers, err := s.db.AllUsers(ctx, query.status)
+	if err != nil {
+		return nil, err
+	}
+	users, err := s.joinAdminUsers(ctx, dbUsers)
+	if err != nil {
+		return nil, err
+	}
+
+	if query.hasMinimum {
+		kept := users[:0]
+		for _, user := range users {
+			if user.Balance >= query.minBalance {
+				kept = append(kept, user)
+			}
+		}
+		users = kept
+	}
+	if less, ok := adminUserSorts[query.sortBy]; ok {
+		sort.SliceStable(users, func(i, j int) bool {
+			if query.descending {
+				return less(users[j], users[i])
+			}
+			return less(users[i], users[j])
+		})
+	}
+	return users, nil
+}
+
+// joinAdminUsers fetches the balances and wagered totals of dbUsers with one
+// MGET each
+func (s *FiberServer) joinAdminUsers(ctx context.Context, dbUsers []database.User) ([]AdminUser, error) {
+	userIDs := make([]string, len(dbUsers))
+	for i, user := range dbUsers {
+		userIDs[i] = user.ID
+	}
+
+	client := s.cache.GetClient()
+	balances, err := game.LoadBalances(ctx, client, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	wagered, err := game.LoadWagered(ctx, client, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]AdminUser, len(dbUsers))
+	for i, user := range dbUsers {
+		users[i] = AdminUser{
+			UserID:              user.ID,
+			Username:            user.Username,
+			Status:              user.Status,
+			Balance:             balances[i],
+			LastSeenAt:          user.LastSeenAt,
+			TotalWageredAllTime: wagered[i],
+		}
+	}
+	return users, nil
+}
+
+// countActiveGames fills in the active games of users from one scan of the
+// Mines games and one read of the current round's bets
+func (s *FiberServer) countActiveGames(ctx context.Context, users []AdminUser) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	counts, err := s.gameManager.ActiveBetCounts(ctx)
+	if err != nil {
+		return err
+	}
+	if engine, exists := s.gameFactory.GetEngine(game.GameTypeMines); exists {
+		mines, err := engine.ProcessAction(ctx, "active_by_user", nil)
+		if err != nil {
+			return err
+		}
+		for userID, count := range mines.(map[string]int) {
+			counts[userID] += count
+		}
+	}
+
+	for i := range users {
+		users[i].ActiveGamesCount = counts[users[i].UserID]
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"aviator/internal/game"
+)
+
+func TestAdminUsersHandler_SortByBalance(t *testing.T) {
+	s, client := newTestServer(t)
+	ctx := t.Context()
+	for i, balance := range []float64{250, 40, 1000, 100, 75} {
+		userID := fmt.Sprintf("user%d", i+1)
+		s.db.CreateUser(ctx, userID, "player"+userID, "")
+		client.Set(ctx, game.REDIS_KEY_USER_BALANCE+userID, balance, 0)
+	}
+	postJSON(t, s.App, "/api/v1/mines/bet", game.MinesBetRequest{UserID: "user3", Amount: 10, MineCount: 3})
+
+	listUsers := func(query string) []AdminUser {
+		t.Helper()
+		resp, body := adminGet(t, s, "/api/v1/admin/users?"+query, testAdminKey)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("list %q = %d: %s", query, resp.StatusCode, body)
+		}
+		var page struct {
+			Users []AdminUser `json:"users"`
+		}
+		if err := json.Unmarshal(body, &page); err != nil {
+			t.Fatalf("could not decode users: %v", err)
+		}
+		return page.Users
+	}
+	userIDs := func(users []AdminUser) string {
+		ids := make([]string, len(users))
+		for i, user := range users {
+			ids[i] = user.UserID
+		}
+		return strings.Join(ids, ",")
+	}
+
+	users := listUsers("sort=balance_desc")
+	if got := userIDs(users); got != "user3,user1,user4,user5,user2" {
+		t.Errorf("sorted by balance_desc = %s, want user3,user1,user4,user5,user2", got)
+	}
+	if top := users[0]; top.Balance != 990 || top.TotalWageredAllTime != 10 || top.ActiveGamesCount != 1 || top.Username != "playeruser3" {
+		t.Errorf("top user = %+v, want the Mines bet counted", top)
+	}
+
+	if got := userIDs(listUsers("sort=balance_asc&filter_min_balance=100&page=2&limit=2")); got != "user3" {
+		t.Errorf("second page of balances of at least 100 = %s, want user3", got)
+	}
+
+	for _, query := range []string{"sort=age", "filter_min_balance=lots"} {
+		if resp, _ := adminGet(t, s, "/api/v1/admin/users?"+query, testAdminKey); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("list %q = %d, want 400", query, resp.StatusCode)
+		}
+	}
+
+	resp, body := adminGet(t, s, "/api/v1/admin/users/export?sort=balance_desc", testAdminKey)
+	records, err := csv.NewReader(strings.NewReader(string(body))).ReadAll()
+	if resp.StatusCode != http.StatusOK || err != nil || len(records) != 6 {
+		t.Fatalf("export = %d %q, %v; want a header and 5 users", resp.StatusCode, body, err)
+	}
+	if records[1][0] != "user3" || records[1][3] != "990.00" {
+		t.Errorf("first exported user = %v, want user3 with 990.00", records[1])
+	}
+}
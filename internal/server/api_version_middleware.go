@@ -0,0 +1,62 @@
+package server
+
+import (
+	"regexp"
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// API_VERSION_LOCAL is the c.Locals key VersionNegotiator stores the version under
+	API_VERSION_LOCAL   = "api_version"
+	API_VERSION_CURRENT = "v1"
+	API_VERSION_V2      = "v2"
+)
+
+var (
+	supportedAPIVersions  = []string{API_VERSION_CURRENT, API_VERSION_V2}
+	deprecatedAPIVersions = []string{}
+)
+
+// acceptVersionPattern matches a versioned media type such as application/vnd.aviator.v2+json
+var acceptVersionPattern = regexp.MustCompile(`application/vnd\.aviator\.(v[0-9]+)\+json`)
+
+// VersionNegotiator picks the response schema from the Accept header. URLs
+// stay under /api/v1; a client asks for v2 responses by accepting
+// application/vnd.aviator.v2+json. Missing or unknown versions get v1.
+func VersionNegotiator() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals(API_VERSION_LOCAL, negotiateAPIVersion(c.Get(fiber.HeaderAccept)))
+		c.Vary(fiber.HeaderAccept)
+		return c.Next()
+	}
+}
+
+// negotiateAPIVersion returns the first supported version an Accept header
+// asks for, or API_VERSION_CURRENT
+func negotiateAPIVersion(accept string) string {
+	for _, match := range acceptVersionPattern.FindAllStringSubmatch(accept, -1) {
+		if slices.Contains(supportedAPIVersions, match[1]) {
+			return match[1]
+		}
+	}
+	return API_VERSION_CURRENT
+}
+
+// apiVersion returns the version VersionNegotiator chose for a request
+func apiVersion(c *fiber.Ctx) string {
+	if version, ok := c.Locals(API_VERSION_LOCAL).(string); ok {
+		return version
+	}
+	return API_VERSION_CURRENT
+}
+
+// apiVersionsHandler lists the response schemas clients can ask for
+func (s *FiberServer) apiVersionsHandler(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"supported_versions": supportedAPIVersions,
+		"deprecated":         deprecatedAPIVersions,
+		"current":            API_VERSION_CURRENT,
+	})
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"aviator/internal/game"
+)
+
+// rollWithAccept rolls a dice bet for user1 with the given Accept header
+func rollWithAccept(t *testing.T, s *FiberServer, accept string, amount float64) (int, map[string]interface{}) {
+	t.Helper()
+
+	data, _ := json.Marshal(game.DiceRollRequest{UserID: "user1", Amount: amount, Target: 50, IsOver: true})
+	req, _ := http.NewRequest("POST", "/api/v1/dice/roll", bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	resp, err := s.App.Test(req)
+	if err != nil {
+		t.Fatalf("could not perform request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var result map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&result)
+	return resp.StatusCode, result
+}
+
+func TestNegotiateAPIVersion(t *testing.T) {
+	tests := map[string]string{
+		"":                                "v1",
+		"application/json":                "v1",
+		"application/vnd.aviator.v2+json": "v2",
+		"application/vnd.aviator.v1+json": "v1",
+		"application/vnd.aviator.v9+json": "v1",
+		"text/html, application/vnd.aviator.v2+json;q=0.9": "v2",
+	}
+
+	for accept, want := range tests {
+		if got := negotiateAPIVersion(accept); got != want {
+			t.Errorf("negotiateAPIVersion(%q) = %q, want %q", accept, got, want)
+		}
+	}
+}
+
+func TestVersionNegotiator_DiceRoll(t *testing.T) {
+	s, client := newTestServer(t)
+	client.Set(t.Context(), game.REDIS_KEY_USER_BALANCE+"user1", 100.0, 0)
+
+	status, v2 := rollWithAccept(t, s, "application/vnd.aviator.v2+json", 1)
+	result, _ := v2["result"].(map[string]interface{})
+	meta, _ := v2["meta"].(map[string]interface{})
+	if status != http.StatusOK || result == nil || meta == nil || v2["roll_result"] != nil {
+		t.Fatalf("v2 roll = %d %v, want result and meta in place of the flat fields", status, v2)
+	}
+	if _, ok := result["value"].(float64); !ok || meta["verified"] != true || meta["server_seed"] == "" || meta["nonce"] == nil {
+		t.Errorf("v2 roll = %v, want a verified value with its seeds", v2)
+	}
+
+	for _, accept := range []string{"", "application/json", "application/vnd.aviator.v7+json"} {
+		if status, v1 := rollWithAccept(t, s, accept, 1); status != http.StatusOK || v1["result"] != nil || v1["server_seed"] == nil {
+			t.Errorf("roll accepting %q = %d %v, want the v1 schema", accept, status, v1)
+		}
+	}
+
+	if status, rejected := rollWithAccept(t, s, "application/vnd.aviator.v2+json", 1000); status != http.StatusBadRequest || rejected["success"] != false || rejected["result"] != nil {
+		t.Errorf("rejected v2 roll = %d %v, want 400 without a result", status, rejected)
+	}
+}
+
+func TestAPIVersionsHandler(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	req, _ := http.NewRequest("GET", "/api/v1/api-versions", nil)
+	resp, err := s.App.Test(req)
+	if err != nil {
+		t.Fatalf("could not perform request: %v", err)
+	}
+	var result struct {
+		SupportedVersions []string `json:"supported_versions"`
+		Deprecated        []string `json:"deprecated"`
+		Current           string   `json:"current"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result)
+	if len(result.SupportedVersions) != 2 || result.SupportedVersions[1] != "v2" || result.Deprecated == nil || result.Current != "v1" {
+		t.Errorf("api versions = %+v, want v1 and v2 with v1 current", result)
+	}
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// routeBodyLimits caps the request body of each route group in bytes. Each
+// limit sits well above the group's largest legitimate request; routes
+// outside these groups keep Fiber's global limit.
+var routeBodyLimits = map[string]int{
+	"/api/v1/mines":   4 << 10,
+	"/api/v1/dice":    4 << 10,
+	"/api/v1/plinko":  4 << 10,
+	"/api/v1/aviator": 2 << 10,
+	"/api/v1/game":    2 << 10, // Aviator bets and cashouts
+	"/api/v1/betslip": 64 << 10,
+	"/api/v1/admin":   1 << 20,
+}
+
+const MAX_USER_ID_LENGTH = 64
+
+var userIDPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// bodyLimit rejects bodies larger than their route group's limit with a 413,
+// and JSON bodies whose user_id is too long or has unexpected characters
+// with a 400
+func bodyLimit(limits map[string]int) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		body := c.Body()
+		if limit, ok := routeBodyLimit(limits, c.Path()); ok && len(body) > limit {
+			return c.Status(413).JSON(fiber.Map{
+				"error": "Request body too large",
+				"limit": limit,
+			})
+		}
+
+		if len(body) > 0 && strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEApplicationJSON) {
+			var fields struct {
+				UserID *string `json:"user_id"`
+			}
+			// Malformed bodies are left for the handler to reject
+			if json.Unmarshal(body, &fields) == nil && fields.UserID != nil && !validUserID(*fields.UserID) {
+				return c.Status(400).JSON(fiber.Map{
+					"error": "Invalid user ID",
+				})
+			}
+		}
+
+		return c.Next()
+	}
+}
+
+// routeBodyLimit returns the limit of the route group containing path
+func routeBodyLimit(limits map[string]int, path string) (int, bool) {
+	for prefix, limit := range limits {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return limit, true
+		}
+	}
+	return 0, false
+}
+
+// validUserID reports whether a user ID from a request body is safe to use
+// in Redis keys and logs. An empty ID is left for the handler to reject.
+func validUserID(userID string) bool {
+	return userID == "" || (len(userID) <= MAX_USER_ID_LENGTH && userIDPattern.MatchString(userID))
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// paddedBody returns a JSON body of exactly size bytes
+func paddedBody(t *testing.T, size int) []byte {
+	t.Helper()
+
+	body := []byte(`{"user_id":"user1","pad":""}`)
+	if size < len(body) {
+		t.Fatalf("cannot pad a body to %d bytes", size)
+	}
+	return append(body[:len(body)-2], append(bytes.Repeat([]byte("x"), size-len(body)), `"}`...)...)
+}
+
+func postRaw(t *testing.T, s *FiberServer, path string, body []byte) (int, map[string]interface{}) {
+	t.Helper()
+
+	req, _ := http.NewRequest("POST", path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Admin-Key", testAdminKey)
+	resp, err := s.App.Test(req, -1)
+	if err != nil {
+		t.Fatalf("could not perform request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var result map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&result)
+	return resp.StatusCode, result
+}
+
+func TestBodyLimit(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	tests := []struct {
+		path  string
+		limit int
+	}{
+		{"/api/v1/mines/bet", 4096},
+		{"/api/v1/dice/roll", 4096},
+		{"/api/v1/plinko/drop", 4096},
+		{"/api/v1/aviator/side-bet", 2048},
+		{"/api/v1/game/cashout", 2048},
+		{"/api/v1/betslip", 65536},
+		{"/api/v1/admin/games/mines/maintenance", 1 << 20},
+	}
+
+	for _, tt := range tests {
+		if status, result := postRaw(t, s, tt.path, paddedBody(t, tt.limit)); status == http.StatusRequestEntityTooLarge {
+			t.Errorf("%s with a %d byte body: status 413 (%v), want it accepted", tt.path, tt.limit, result)
+		}
+		status, result := postRaw(t, s, tt.path, paddedBody(t, tt.limit+1))
+		if status != http.StatusRequestEntityTooLarge || result["limit"] != float64(tt.limit) {
+			t.Errorf("%s with a %d byte body: %d %v, want 413", tt.path, tt.limit+1, status, result)
+		}
+	}
+
+	// Routes outside the groups keep the global limit
+	if status, _ := postRaw(t, s, "/api/v1/users", paddedBody(t, 8192)); status == http.StatusRequestEntityTooLarge {
+		t.Error("/api/v1/users rejected an 8 KB body")
+	}
+}
+
+func TestBodyLimit_UserID(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	for userID, valid := range map[string]bool{
+		"user1":                     true,
+		"Player_42-b":               true,
+		strings.Repeat("a", 64):     true,
+		strings.Repeat("a", 65):     false,
+		"user 1":                    false,
+		"user1;DEL crash:balance:*": false,
+		"josé":                      false,
+		"../../admin":               false,
+	} {
+		body, _ := json.Marshal(map[string]interface{}{"user_id": userID, "amount": 1, "target": 50, "is_over": true})
+		status, result := postRaw(t, s, "/api/v1/dice/roll", body)
+		rejected := status == http.StatusBadRequest && result["error"] == "Invalid user ID"
+		if rejected == valid {
+			t.Errorf("user_id %q: %d %v, want valid %v", userID, status, result, valid)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"aviator/internal/game"
+)
+
+// seedCommitments fills the public commitment log with n crashed rounds
+func seedCommitments(tb testing.TB, client *redis.Client, n int) {
+	tb.Helper()
+
+	start := time.Now().Add(-time.Hour)
+	for i := range n {
+		publishedAt := start.Add(time.Duration(i) * 10 * time.Second)
+		crashedAt := publishedAt.Add(5 * time.Second)
+		seed := game.GenerateSeed()
+		data, _ := json.Marshal(game.RoundCommitment{
+			RoundID:         fmt.Sprintf("R-%d", i),
+			HashCommitment:  game.CommitRoundSeed(seed),
+			PublishedAt:     publishedAt,
+			ServerSeed:      seed,
+			CrashMultiplier: 1 + float64(i%50)/10,
+			BaseMultiplier:  1 + float64(i%50)/10,
+			FinalMultiplier: 1 + float64(i%50)/10,
+			CrashedAt:       &crashedAt,
+			Verified:        true,
+		})
+		client.ZAdd(context.Background(), game.REDIS_KEY_COMMITMENTS, redis.Z{Score: float64(publishedAt.UnixMilli()), Member: data})
+	}
+}
+
+// getEncoded requests path accepting encoding and returns the response with its raw body
+func getEncoded(tb testing.TB, s *FiberServer, path, encoding string) (*http.Response, []byte) {
+	tb.Helper()
+
+	req, _ := http.NewRequest("GET", path, nil)
+	if encoding != "" {
+		req.Header.Set("Accept-Encoding", encoding)
+	}
+	resp, err := s.App.Test(req)
+	if err != nil {
+		tb.Fatalf("could not perform request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		tb.Fatalf("could not read response: %v", err)
+	}
+	return resp, body
+}
+
+func TestCompression(t *testing.T) {
+	s, client := newTestServer(t)
+	seedCommitments(t, client, 100)
+
+	resp, body := getEncoded(t, s, "/api/v1/fair/commitments?limit=100", "gzip")
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Encoding") != "gzip" {
+		t.Fatalf("status = %d, Content-Encoding = %q; want 200 gzip", resp.StatusCode, resp.Header.Get("Content-Encoding"))
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("response is not gzip: %v", err)
+	}
+	var result struct {
+		Commitments []game.RoundCommitment `json:"commitments"`
+	}
+	if err := json.NewDecoder(reader).Decode(&result); err != nil {
+		t.Fatalf("could not decode decompressed response: %v", err)
+	}
+	if len(result.Commitments) != 100 || result.Commitments[0].RoundID != "R-99" {
+		t.Errorf("decompressed response has %d commitments, want 100 newest first", len(result.Commitments))
+	}
+
+	if resp, _ := getEncoded(t, s, "/api/v1/fair/commitments?limit=100", ""); resp.Header.Get("Content-Encoding") != "" {
+		t.Errorf("Content-Encoding = %q without Accept-Encoding, want none", resp.Header.Get("Content-Encoding"))
+	}
+}
+
+// BenchmarkCommitmentsCompression reports the size of a 100-round response
+// with and without compression
+func BenchmarkCommitmentsCompression(b *testing.B) {
+	s, client := newTestServer(b)
+	seedCommitments(b, client, 100)
+
+	for _, encoding := range []string{"identity", "gzip", "br"} {
+		b.Run(encoding, func(b *testing.B) {
+			var size int
+			for b.Loop() {
+				_, body := getEncoded(b, s, "/api/v1/fair/commitments?limit=100", encoding)
+				size = len(body)
+			}
+			b.ReportMetric(float64(size), "bytes/response")
+		})
+	}
+}
@@ -6,24 +6,101 @@ func (s *FiberServer) RegisterGameRoutes() {
 
 	// Aviator game routes
 	api.Get("/game/state", s.getGameStateHandler)
+	api.Get("/game/initial-state", validChatGameType, s.initialStateHandler)
+	api.Get("/game/stream", validChatGameType, s.gameStreamHandler)
+	api.Get("/game/stream/clients", s.adminTokenAuth, s.gameStreamClientsHandler)
 	api.Post("/game/bet", s.placeBetHandler)
 	api.Post("/game/cashout", s.cashoutHandler)
+	api.Post("/aviator/cancel-bet", s.cancelBetHandler)
+	api.Head("/rounds/current", s.roundHeadHandler)
+	api.Get("/rounds/current/my-bets", s.myBetsHandler)
+	api.Post("/aviator/side-bet", s.sideBetHandler)
+	api.Get("/aviator/side-bet-odds", s.sideBetOddsHandler)
+	api.Get("/aviator/stats", s.aviatorStatsHandler)
+	api.Post("/aviator/simulate", s.crashOutcomesHandler)
+	api.Get("/crashes/distribution", s.crashDistributionHandler)
+	api.Get("/aviator/auto-cashout-distribution", s.adminTokenAuth, s.autoCashoutDistributionHandler)
 
-	// User balance routes
+	api.Get("/api-versions", s.apiVersionsHandler)
+
+	// Game info routes
+	api.Get("/games", s.listGamesHandler)
+	api.Get("/games/:type", s.getGameInfoHandler)
+	api.Get("/games/:type/rtp", s.gameRTPHandler)
+	api.Get("/promotions/current", s.currentPromotionsHandler)
+	api.Get("/promotions/crash", s.crashPromotionsHandler)
+	api.Get("/leaderboard/notable", s.notableCashoutsHandler)
+
+	// Provably fair routes
+	api.Get("/fair/chain", s.fairChainHandler)
+	api.Get("/fair/commitments", s.fairCommitmentsHandler)
+	api.Get("/fair/commitment/:roundID", s.fairCommitmentHandler)
+	api.Post("/fair/commitment/verify", s.verifyCommitmentHandler)
+	api.Get("/fair/signing-key/public", s.fairSigningKeyHandler)
+	api.Post("/fair/verify/mines", s.verifyMinesHandler)
+	api.Get("/rounds/verify/:roundID", s.verifyRoundHandler)
+	api.Get("/rounds/:roundID/replay", s.roundReplayHandler)
+
+	// Bet slip routes
+	api.Post("/betslip", s.createBetSlipHandler)
+	api.Post("/betslip/:id/confirm", s.confirmBetSlipHandler)
+
+	// User routes
+	api.Post("/users", s.createUserHandler)
 	api.Get("/user/:userId/balance", s.getUserBalanceHandler)
 	api.Post("/user/:userId/balance", s.setUserBalanceHandler)
+	api.Get("/users/:userId/interest", s.getUserInterestHandler)
+	api.Get("/users/:userId/summary", s.userSummaryHandler)
+	api.Post("/users/:userId/self-exclusion", s.selfExcludeHandler)
+	api.Get("/users/:userId/preferences", s.getPreferencesHandler)
+	api.Post("/users/:userId/preferences", s.setPreferencesHandler)
+
+	// Simulated wallet routes for development and demos
+	api.Post("/users/:userId/deposit", s.adminTokenAuth, s.depositHandler)
+	api.Post("/users/:userId/withdraw", s.withdrawHandler)
+	api.Get("/users/:userId/deposits", s.walletHistoryHandler)
+
+	// Referral routes
+	api.Post("/users/register", s.registerUserHandler)
+	api.Get("/users/referral/:code", s.resolveReferralCodeHandler)
+	api.Get("/users/:userId/referrals", s.getUserReferralsHandler)
 
 	// Mines game routes
 	mines := api.Group("/mines")
 	mines.Post("/bet", s.minesBetHandler)
 	mines.Post("/click", s.minesClickHandler)
+	mines.Post("/preselect", s.minesPreselectHandler)
+	mines.Post("/multi-game", s.minesMultiGameHandler)
 	mines.Post("/cashout", s.minesCashoutHandler)
+	mines.Post("/cashout-all", s.minesCashoutAllHandler)
+	mines.Get("/game/:gameID/state", s.minesGameStateHandler)
+	mines.Get("/grid/:gameID", s.minesGridHandler)
+	mines.Delete("/game/:gameID", s.minesForfeitHandler)
+	mines.Get("/probabilities/:gameID", s.minesProbabilitiesHandler)
+	mines.Get("/odds-table", s.minesOddsTableHandler)
+	mines.Post("/auto-complete/:gameID", s.minesAutoCompleteHandler)
+	mines.Get("/leaderboard/:board", s.minesLeaderboardHandler)
+	mines.Get("/history/:userId", s.minesHistoryHandler)
+	mines.Get("/active-count", s.minesActiveCountHandler)
 
 	// Plinko game routes
 	plinko := api.Group("/plinko")
 	plinko.Post("/drop", s.plinkoDropHandler)
+	plinko.Post("/auto-drop", s.plinkoAutoDropHandler)
+	plinko.Get("/history/:userId", s.plinkoHistoryHandler)
+	plinko.Get("/stats/:userId", s.plinkoStatsHandler)
+	plinko.Get("/active-count", s.plinkoActiveCountHandler)
+	plinko.Get("/config", s.plinkoConfigHandler)
+	plinko.Get("/game/:gameID/verify", s.plinkoVerifyHandler)
+	plinko.Post("/game/:gameID/reveal", s.plinkoRevealHandler)
 
 	// Dice game routes
 	dice := api.Group("/dice")
 	dice.Post("/roll", s.diceRollHandler)
+	dice.Post("/exact", s.diceExactHandler)
+	dice.Post("/range", s.diceRangeHandler)
+	dice.Post("/parlay", s.diceParlayHandler)
+	dice.Get("/parlay/odds-preview", s.diceParlayOddsHandler)
+	dice.Get("/history/:userId", s.diceHistoryHandler)
+	dice.Get("/active-count", s.diceActiveCountHandler)
 }
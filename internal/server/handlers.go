@@ -1,28 +1,101 @@
 package server
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2"
 
+	"aviator/internal/database"
 	"aviator/internal/game"
 )
 
-// Health handler
+const (
+	// REDIS_KEY_INITIAL_STATE caches an initial state response by its ETag
+	REDIS_KEY_INITIAL_STATE = "ws:initial:"
+	INITIAL_STATE_CACHE_TTL = 500 * time.Millisecond
+
+	// REDIS_KEY_AVIATOR_STATS caches the crash statistics, suffixed with the period if one was requested
+	REDIS_KEY_AVIATOR_STATS = "aviator:stats"
+	AVIATOR_STATS_CACHE_TTL = 10 * time.Second
+
+	// READINESS_PING_TIMEOUT bounds the database and cache pings of the readiness probe
+	READINESS_PING_TIMEOUT = 1 * time.Second
+)
+
+// Health handlers
+
+// healthHandler reports every dependency and returns 503 if any game engine is unhealthy
 func (s *FiberServer) healthHandler(c *fiber.Ctx) error {
+	health, enginesHealthy := s.healthReport(c.Context())
+	if !enginesHealthy {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(health)
+	}
+	return c.JSON(health)
+}
+
+// readinessHandler returns 503 until every startup check has passed, and
+// after that unless the database, cache and all engines are up
+func (s *FiberServer) readinessHandler(c *fiber.Ctx) error {
+	if s.startup != nil && !s.startup.Ready() {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"ready":   false,
+			"pending": s.startup.Pending(),
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), READINESS_PING_TIMEOUT)
+	defer cancel()
+	dbErr := s.db.Ping(ctx)
+	cacheErr := s.cache.GetClient().Ping(ctx).Err()
+	engines, enginesHealthy := s.gameFactory.HealthCheckAll(c.Context())
+
 	health := fiber.Map{
+		"database": pingStatus(dbErr),
+		"cache":    pingStatus(cacheErr),
+		"engines":  engines,
+	}
+	if !enginesHealthy || dbErr != nil || cacheErr != nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(health)
+	}
+	return c.JSON(health)
+}
+
+// pingStatus reports a dependency as up, or down with the ping's error
+func pingStatus(err error) fiber.Map {
+	if err != nil {
+		return fiber.Map{"status": "down", "error": err.Error()}
+	}
+	return fiber.Map{"status": "up"}
+}
+
+// livenessHandler only confirms that the process is serving requests
+func (s *FiberServer) livenessHandler(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{"status": "alive"})
+}
+
+func (s *FiberServer) healthReport(ctx context.Context) (fiber.Map, bool) {
+	engines, enginesHealthy := s.gameFactory.HealthCheckAll(ctx)
+
+	return fiber.Map{
 		"database": s.db.Health(),
 		"cache":    s.cache.Health(),
 		"game": fiber.Map{
 			"status":            "running",
 			"connected_clients": s.gameHub.GetClientCount(),
 		},
-	}
-	return c.JSON(health)
+		"engines":  engines,
+		"activity": s.gameFactory.ActiveStats(ctx),
+	}, enginesHealthy
 }
 
 // Aviator game handlers
@@ -37,8 +110,1578 @@ func (s *FiberServer) getGameStateHandler(c *fiber.Ctx) error {
 	return c.JSON(state)
 }
 
-func (s *FiberServer) placeBetHandler(c *fiber.Ctx) error {
-	var req game.BetRequest
+// roundHeadHandler answers HEAD /rounds/current with the current round in
+// headers only, for clients polling for a new round. X-Round-ETag changes
+// with the round and its status, so the full state only needs fetching when
+// it does.
+func (s *FiberServer) roundHeadHandler(c *fiber.Ctx) error {
+	head, ok := s.gameManager.RoundHead(c.Context())
+	if !ok {
+		return c.SendStatus(404)
+	}
+
+	c.Set("X-Round-ID", head.RoundID)
+	c.Set("X-Round-Status", head.Status)
+	c.Set("X-Round-Multiplier", game.FormatMultiplier(head.Multiplier))
+	c.Set("X-Round-ETag", head.ETag)
+	return c.SendStatus(200)
+}
+
+func (s *FiberServer) myBetsHandler(c *fiber.Ctx) error {
+	userID := c.Query("user_id")
+	if userID == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "User ID is required",
+		})
+	}
+
+	roundID, bets, err := s.gameManager.GetUserBets(c.Context(), userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to load bets",
+		})
+	}
+	if roundID == "" {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "No active game round",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"round_id": roundID,
+		"bets":     bets,
+	})
+}
+
+func (s *FiberServer) placeBetHandler(c *fiber.Ctx) error {
+	var req game.BetRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.UserID == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "User ID is required",
+		})
+	}
+
+	resp, err := s.gameManager.PlaceBet(req)
+	if err != nil {
+		return c.Status(500).JSON(aviatorBetFailure(req.UserID, err))
+	}
+	if !resp.Success {
+		return c.Status(400).JSON(resp)
+	}
+
+	return c.JSON(resp)
+}
+
+func (s *FiberServer) cashoutHandler(c *fiber.Ctx) error {
+	var req game.CashoutRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.UserID == "" || req.BetID == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "User ID and Bet ID are required",
+		})
+	}
+
+	resp, err := s.gameManager.Cashout(req)
+	if err != nil {
+		return c.Status(500).JSON(aviatorCashoutFailure(req.UserID, err))
+	}
+	if !resp.Success {
+		return c.Status(400).JSON(resp)
+	}
+
+	return c.JSON(resp)
+}
+
+// cancelBetHandler cancels an Aviator bet shortly after it was placed and refunds it
+func (s *FiberServer) cancelBetHandler(c *fiber.Ctx) error {
+	var req game.CancelBetRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.UserID == "" || req.BetID == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "User ID and Bet ID are required",
+		})
+	}
+
+	resp, err := s.gameManager.CancelBet(c.Context(), req)
+	if err != nil {
+		log.Printf("[BET] Cancellation by %s failed: %v", req.UserID, err)
+		return c.Status(500).JSON(game.CancelBetResponse{Success: false, Message: "Failed to refund bet"})
+	}
+	if !resp.Success {
+		return c.Status(400).JSON(resp)
+	}
+
+	return c.JSON(resp)
+}
+
+// aviatorBetFailure logs an internal bet error and returns what the player sees instead
+func aviatorBetFailure(userID string, err error) game.BetResponse {
+	log.Printf("[BET] Bet by %s failed: %v", userID, err)
+	return game.BetResponse{Success: false, Message: "Transaction failed"}
+}
+
+// aviatorCashoutFailure logs an internal cashout error and returns what the player sees instead
+func aviatorCashoutFailure(userID string, err error) game.CashoutResponse {
+	log.Printf("[CASHOUT] Cashout by %s failed: %v", userID, err)
+	return game.CashoutResponse{Success: false, Message: "Failed to credit balance"}
+}
+
+// sideBetHandler places a bet on the range the current round's crash point
+// will fall in
+func (s *FiberServer) sideBetHandler(c *fiber.Ctx) error {
+	var req game.SideBetRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.UserID == "" || req.Prediction == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "User ID and prediction are required",
+		})
+	}
+
+	resp, err := s.gameManager.PlaceSideBet(req)
+	if err != nil {
+		log.Printf("[SIDE BET] Side bet by %s failed: %v", req.UserID, err)
+		return c.Status(500).JSON(game.SideBetResponse{Success: false, Message: "Transaction failed"})
+	}
+	if !resp.Success {
+		return c.Status(400).JSON(resp)
+	}
+
+	return c.JSON(resp)
+}
+
+// aviatorStatsHandler summarizes the crash points of all rounds, or of those
+// started within ?period= (such as 24h). The statistics are cached for ten
+// seconds; current_round_id is always live.
+func (s *FiberServer) aviatorStatsHandler(c *fiber.Ctx) error {
+	ctx := c.Context()
+	period := c.Query("period")
+	cacheKey := REDIS_KEY_AVIATOR_STATS
+	var since time.Time
+	if period != "" {
+		duration, err := time.ParseDuration(period)
+		if err != nil || duration <= 0 {
+			return c.Status(400).JSON(fiber.Map{
+				"error": "period must be a positive duration such as 24h",
+			})
+		}
+		since = time.Now().Add(-duration)
+		cacheKey += ":" + period
+	}
+
+	client := s.cache.GetClient()
+	var stats database.CrashStats
+	if cached, err := client.Get(ctx, cacheKey).Bytes(); err != nil || json.Unmarshal(cached, &stats) != nil {
+		loaded, err := s.db.CrashStats(ctx, since)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error": "Failed to load round statistics",
+			})
+		}
+		stats = *loaded
+
+		data, _ := json.Marshal(stats)
+		if err := client.Set(ctx, cacheKey, data, AVIATOR_STATS_CACHE_TTL).Err(); err != nil {
+			log.Printf("[STATS] Failed to cache Aviator stats: %v", err)
+		}
+	}
+
+	currentRoundID := ""
+	if round := s.gameManager.GetCurrentRound(); round != nil {
+		currentRoundID = round.RoundID
+	}
+	return c.JSON(struct {
+		CurrentRoundID string `json:"current_round_id"`
+		database.CrashStats
+	}{currentRoundID, stats})
+}
+
+// crashDistributionHandler tests the crash points of the last ?rounds= rounds
+// (1000 by default) against the distribution HashAndMapToMultiplier draws
+// from. Bonus events are left out. Analyses are cached for five minutes.
+func (s *FiberServer) crashDistributionHandler(c *fiber.Ctx) error {
+	ctx := c.Context()
+	rounds := c.QueryInt("rounds", game.CRASH_DISTRIBUTION_ROUNDS)
+	if rounds < 1 || rounds > game.CRASH_DISTRIBUTION_MAX_ROUNDS {
+		return c.Status(400).JSON(fiber.Map{
+			"error": fmt.Sprintf("rounds must be between 1 and %d", game.CRASH_DISTRIBUTION_MAX_ROUNDS),
+		})
+	}
+
+	client := s.cache.GetClient()
+	cacheKey := game.REDIS_KEY_CRASH_DISTRIBUTION + strconv.Itoa(rounds)
+	var dist game.CrashDistribution
+	if cached, err := client.Get(ctx, cacheKey).Bytes(); err == nil && json.Unmarshal(cached, &dist) == nil {
+		return c.JSON(dist)
+	}
+
+	crashPoints, err := s.db.RecentBaseCrashPoints(ctx, rounds)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to load rounds",
+		})
+	}
+	dist = game.AnalyzeCrashDistribution(crashPoints)
+
+	data, _ := json.Marshal(dist)
+	if err := client.Set(ctx, cacheKey, data, game.CRASH_DISTRIBUTION_CACHE_TTL).Err(); err != nil {
+		log.Printf("[STATS] Failed to cache the crash distribution: %v", err)
+	}
+	return c.JSON(dist)
+}
+
+// autoCashoutDistributionHandler shows how often each auto-cashout target is
+// chosen, for tuning jitter recommendations. Admin only.
+func (s *FiberServer) autoCashoutDistributionHandler(c *fiber.Ctx) error {
+	dist, err := game.GetAutoCashoutDistribution(c.Context(), s.cache.GetClient())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to load auto-cashout targets",
+		})
+	}
+	return c.JSON(dist)
+}
+
+// sideBetOddsHandler returns the odds of each side bet range over the most
+// recent crashed rounds
+func (s *FiberServer) sideBetOddsHandler(c *fiber.Ctx) error {
+	crashPoints, err := s.db.RecentCrashPoints(c.Context(), game.SIDE_BET_ODDS_SAMPLE)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to load rounds",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"sample_size": len(crashPoints),
+		"odds":        game.CalculateSideBetOdds(crashPoints),
+	})
+}
+
+// CrashOutcomesRequest asks for a number of simulated crash points
+type CrashOutcomesRequest struct {
+	Simulations int `json:"simulations"`
+}
+
+// crashOutcomesHandler summarizes simulated crash points. It takes no bets
+// and is for information only.
+func (s *FiberServer) crashOutcomesHandler(c *fiber.Ctx) error {
+	var req CrashOutcomesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+	if req.Simulations < 1 || req.Simulations > game.CRASH_OUTCOMES_MAX {
+		return c.Status(400).JSON(fiber.Map{
+			"error": game.ErrInvalidSimulationCount.Error(),
+		})
+	}
+
+	client := s.cache.GetClient()
+	cacheKey := game.REDIS_KEY_CRASH_OUTCOMES + strconv.Itoa(req.Simulations)
+	if cached, err := client.Get(c.Context(), cacheKey).Bytes(); err == nil {
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		return c.Send(cached)
+	}
+
+	stats, err := game.SimulateCrashOutcomes(c.Context(), req.Simulations)
+	if errors.Is(err, game.ErrSimulationTimeout) {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to simulate crash points",
+		})
+	}
+
+	data, _ := json.Marshal(stats)
+	if err := client.Set(c.Context(), cacheKey, data, game.CRASH_OUTCOMES_CACHE_TTL).Err(); err != nil {
+		log.Printf("[STATS] Failed to cache crash simulation: %v", err)
+	}
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(data)
+}
+
+// Game info handlers
+
+// listGamesHandler returns the metadata of every registered game engine
+func (s *FiberServer) listGamesHandler(c *fiber.Ctx) error {
+	return c.JSON(s.gameFactory.GameInfos(c.Context()))
+}
+
+func (s *FiberServer) getGameInfoHandler(c *fiber.Ctx) error {
+	info, exists := s.gameFactory.GameInfo(c.Context(), game.GameType(c.Params("type")))
+	if !exists {
+		return c.Status(404).JSON(fiber.Map{"error": "Unknown game type"})
+	}
+	return c.JSON(info)
+}
+
+func (s *FiberServer) gameRTPHandler(c *fiber.Ctx) error {
+	gameType := game.GameType(c.Params("type"))
+	cacheKey := game.REDIS_KEY_RTP + string(gameType)
+
+	if cached, err := s.cache.GetClient().Get(c.Context(), cacheKey).Bytes(); err == nil {
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		return c.Send(cached)
+	}
+
+	info, err := game.CalculateRTP(gameType)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	infoJSON, err := json.Marshal(info)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to encode RTP",
+		})
+	}
+	s.cache.GetClient().Set(c.Context(), cacheKey, infoJSON, game.RTP_CACHE_TTL)
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(infoJSON)
+}
+
+// currentPromotionsHandler returns each game's house edge and the next scheduled change
+func (s *FiberServer) currentPromotionsHandler(c *fiber.Ctx) error {
+	return c.JSON(game.CurrentPromotions())
+}
+
+// crashPromotionsHandler lists the active crash bonus events
+func (s *FiberServer) crashPromotionsHandler(c *fiber.Ctx) error {
+	event, err := s.gameManager.ActiveBonusEvent(c.Context())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to load bonus events",
+		})
+	}
+
+	events := []game.CrashBonusEvent{}
+	if event != nil {
+		events = append(events, *event)
+	}
+	return c.JSON(fiber.Map{
+		"bonus_events": events,
+	})
+}
+
+// notableCashoutsHandler lists the last big cashouts across all games
+func (s *FiberServer) notableCashoutsHandler(c *fiber.Ctx) error {
+	cashouts, err := game.NotableCashouts(c.Context(), s.cache.GetClient())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to load notable cashouts",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"cashouts": cashouts,
+	})
+}
+
+// Provably fair handlers
+
+// fairChainHandler verifies the server seed hash chain between two rounds
+func (s *FiberServer) fairChainHandler(c *fiber.Ctx) error {
+	rounds, err := s.gameManager.ChainRounds(c.Context(), c.Query("from_round"), c.Query("to_round"))
+	if errors.Is(err, game.ErrRoundNotInChain) {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Round not found in chain history",
+		})
+	}
+	if errors.Is(err, game.ErrInvalidChainRange) {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "from_round must not be after to_round",
+		})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to load chain history",
+		})
+	}
+
+	valid, brokenAt := game.VerifyHashChain(rounds)
+	brokenAtRound := ""
+	if !valid {
+		brokenAtRound = rounds[brokenAt].RoundID
+	}
+
+	return c.JSON(fiber.Map{
+		"valid":           valid,
+		"broken_at_round": brokenAtRound,
+		"chain_length":    len(rounds),
+	})
+}
+
+// fairCommitmentsHandler lists recent round commitments, newest first
+func (s *FiberServer) fairCommitmentsHandler(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", game.COMMITMENT_LOG_DEFAULT_LIMIT)
+	if limit < 1 || limit > game.COMMITMENT_LOG_SIZE {
+		return c.Status(400).JSON(fiber.Map{
+			"error": fmt.Sprintf("limit must be between 1 and %d", game.COMMITMENT_LOG_SIZE),
+		})
+	}
+
+	commitments, err := s.gameManager.RecentCommitments(c.Context(), limit)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to load commitments",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"commitments": commitments,
+	})
+}
+
+// fairCommitmentHandler returns the signed commitment published for a round
+// before its betting opened
+func (s *FiberServer) fairCommitmentHandler(c *fiber.Ctx) error {
+	commitment, err := s.gameManager.SignedCommitment(c.Context(), c.Params("roundID"))
+	if errors.Is(err, game.ErrCommitmentNotFound) {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Commitment not found",
+		})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to load commitment",
+		})
+	}
+	return c.JSON(commitment)
+}
+
+// verifyCommitmentHandler checks a commitment and signature a player kept
+// against the record published for the round
+func (s *FiberServer) verifyCommitmentHandler(c *fiber.Ctx) error {
+	var req struct {
+		RoundID    string `json:"round_id"`
+		Commitment string `json:"commitment"`
+		Signature  string `json:"signature"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+	if req.RoundID == "" || req.Commitment == "" || req.Signature == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "round_id, commitment and signature are required",
+		})
+	}
+
+	valid, publishedBeforeBets, err := s.gameManager.VerifySignedCommitment(c.Context(), req.RoundID, req.Commitment, req.Signature)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to load commitment",
+		})
+	}
+	return c.JSON(fiber.Map{
+		"valid":                 valid,
+		"published_before_bets": publishedBeforeBets,
+	})
+}
+
+// fairSigningKeyHandler identifies the key game results are signed with,
+// so clients can tell when it has been rotated
+func (s *FiberServer) fairSigningKeyHandler(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"algorithm": "HMAC-SHA256",
+		"key_hash":  game.SigningKeyHash(game.ResultSigningKey()),
+	})
+}
+
+// verifyRoundHandler recomputes a stored round's crash multiplier from its revealed seeds
+func (s *FiberServer) verifyRoundHandler(c *fiber.Ctx) error {
+	roundID := c.Params("roundID")
+	round, err := s.db.GetRound(c.Context(), roundID)
+	if errors.Is(err, database.ErrRoundNotFound) {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Round not found",
+		})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to load round",
+		})
+	}
+
+	verification, revealed := s.gameManager.VerifyStoredRound(*round)
+	if !revealed {
+		return c.JSON(fiber.Map{
+			"round_id": roundID,
+			"verified": nil,
+			"message":  "Seed not yet revealed",
+		})
+	}
+	return c.JSON(verification)
+}
+
+// verifyMinesHandler regenerates the mines of a finished game from its
+// revealed seeds and compares them with the positions a player claims
+func (s *FiberServer) verifyMinesHandler(c *fiber.Ctx) error {
+	var req struct {
+		ServerSeed       string `json:"server_seed"`
+		ClientSeed       string `json:"client_seed"`
+		Nonce            int    `json:"nonce"`
+		MineCount        int    `json:"mine_count"`
+		GridSize         int    `json:"grid_size"`
+		ClaimedPositions []int  `json:"claimed_positions"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.ServerSeed == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Server seed is required",
+		})
+	}
+	if req.GridSize == 0 {
+		req.GridSize = game.MINES_GRID_SIZE
+	}
+	if msg := game.ValidateMinesLayout(req.GridSize, req.MineCount); msg != "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": msg,
+		})
+	}
+	if req.ClaimedPositions == nil {
+		req.ClaimedPositions = []int{}
+	}
+
+	return c.JSON(game.VerifyMines(req.ServerSeed, req.ClientSeed, req.Nonce, req.MineCount, req.GridSize, req.ClaimedPositions))
+}
+
+// roundReplayHandler returns the multiplier timeline and bets of a crashed
+// round so clients can animate it again. Replays are cached for an hour.
+func (s *FiberServer) roundReplayHandler(c *fiber.Ctx) error {
+	ctx := c.Context()
+	roundID := c.Params("roundID")
+	cacheKey := game.REDIS_KEY_REPLAY + roundID
+	client := s.cache.GetClient()
+
+	var replay game.ReplayData
+	if cached, err := client.Get(ctx, cacheKey).Bytes(); err == nil && json.Unmarshal(cached, &replay) == nil {
+		c.Set("X-Total-Duration-Ms", strconv.FormatInt(replay.TotalDurationMs, 10))
+		return c.JSON(replay)
+	}
+
+	round, err := s.db.GetRound(ctx, roundID)
+	if errors.Is(err, database.ErrRoundNotFound) {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Round not found",
+		})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to load round",
+		})
+	}
+	if round.CrashedAt == nil {
+		return c.Status(409).JSON(fiber.Map{
+			"error": "Round has not crashed yet",
+		})
+	}
+
+	bets, err := s.db.GetRoundBets(ctx, roundID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to load bets",
+		})
+	}
+
+	replay = game.BuildReplay(*round, bets)
+	data, _ := json.Marshal(replay)
+	if err := client.Set(ctx, cacheKey, data, game.REPLAY_CACHE_TTL).Err(); err != nil {
+		log.Printf("[REPLAY] Failed to cache replay of %s: %v", roundID, err)
+	}
+
+	c.Set("X-Total-Duration-Ms", strconv.FormatInt(replay.TotalDurationMs, 10))
+	return c.JSON(replay)
+}
+
+// Bet slip handlers
+
+func (s *FiberServer) createBetSlipHandler(c *fiber.Ctx) error {
+	var req game.BetSlipRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.UserID == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "User ID is required",
+		})
+	}
+
+	resp, err := s.betSlips.Create(c.Context(), req)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if !resp.Success {
+		return c.Status(400).JSON(resp)
+	}
+
+	return c.JSON(resp)
+}
+
+func (s *FiberServer) confirmBetSlipHandler(c *fiber.Ctx) error {
+	resp, err := s.betSlips.Confirm(c.Context(), c.Params("id"))
+	if errors.Is(err, game.ErrBetSlipNotFound) {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Bet slip not found or expired",
+		})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if !resp.Success {
+		return c.Status(400).JSON(resp)
+	}
+
+	return c.JSON(resp)
+}
+
+// User balance handlers
+
+func (s *FiberServer) getUserBalanceHandler(c *fiber.Ctx) error {
+	userID := c.Params("userId")
+	if userID == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "User ID is required",
+		})
+	}
+
+	balanceKey := game.REDIS_KEY_USER_BALANCE + userID
+	balance, err := s.cache.GetClient().Get(c.Context(), balanceKey).Float64()
+	if err != nil {
+		balance = 0.0
+	}
+
+	resp := fiber.Map{
+		"user_id":           userID,
+		"balance":           balance,
+		"formatted_balance": game.FormatAmount(balance),
+	}
+
+	// Players without a profile still have a Redis balance
+	user, err := s.db.GetUser(c.Context(), userID)
+	if err == nil {
+		resp["username"] = user.Username
+		resp["created_at"] = user.CreatedAt
+		resp["last_seen_at"] = user.LastSeenAt
+	} else if !errors.Is(err, database.ErrUserNotFound) {
+		log.Printf("[USER] Failed to load profile for %s: %v", userID, err)
+	}
+
+	return c.JSON(resp)
+}
+
+func (s *FiberServer) createUserHandler(c *fiber.Ctx) error {
+	var body struct {
+		UserID   string `json:"user_id"`
+		Username string `json:"username"`
+		Email    string `json:"email"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if body.Username == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Username is required",
+		})
+	}
+	if body.UserID == "" {
+		body.UserID = game.GenerateSeed()[:16]
+	}
+
+	user, err := s.db.CreateUser(c.Context(), body.UserID, body.Username, body.Email)
+	if errors.Is(err, database.ErrUsernameTaken) {
+		return c.Status(409).JSON(fiber.Map{
+			"error": "Username already taken",
+		})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to create user",
+		})
+	}
+
+	return c.Status(201).JSON(user)
+}
+
+func (s *FiberServer) getUserInterestHandler(c *fiber.Ctx) error {
+	userID := c.Params("userId")
+	if userID == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "User ID is required",
+		})
+	}
+
+	info, err := s.interest.GetInterestInfo(c.Context(), userID, time.Now())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to load interest",
+		})
+	}
+
+	return c.JSON(info)
+}
+
+// selfExcludeHandler excludes a user for a number of days. Excluded users
+// earn no interest until the exclusion ends.
+func (s *FiberServer) selfExcludeHandler(c *fiber.Ctx) error {
+	userID := c.Params("userId")
+
+	var body struct {
+		Days int `json:"days"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	until, err := game.SelfExclude(c.Context(), s.cache.GetClient(), userID, body.Days, time.Now())
+	if errors.Is(err, game.ErrInvalidSelfExclusion) {
+		return c.Status(400).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		log.Printf("[USER] Failed to self-exclude %s: %v", userID, err)
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to save self-exclusion",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"user_id":        userID,
+		"excluded_until": until,
+	})
+}
+
+// getPreferencesHandler returns a user's saved game defaults, or {} if none
+func (s *FiberServer) getPreferencesHandler(c *fiber.Ctx) error {
+	userID := c.Params("userId")
+	prefs, err := game.LoadPreferences(c.Context(), s.cache.GetClient(), userID)
+	if err != nil {
+		log.Printf("[USER] Failed to load preferences of %s: %v", userID, err)
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to load preferences",
+		})
+	}
+	if prefs == nil {
+		prefs = &game.UserPreferences{}
+	}
+
+	return c.JSON(prefs)
+}
+
+// setPreferencesHandler replaces a user's saved game defaults
+func (s *FiberServer) setPreferencesHandler(c *fiber.Ctx) error {
+	userID := c.Params("userId")
+
+	var prefs game.UserPreferences
+	if err := c.BodyParser(&prefs); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	err := game.SavePreferences(c.Context(), s.cache.GetClient(), userID, prefs)
+	if errors.Is(err, game.ErrInvalidPreferences) {
+		return c.Status(400).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to save preferences",
+		})
+	}
+
+	return c.JSON(prefs)
+}
+
+func (s *FiberServer) setUserBalanceHandler(c *fiber.Ctx) error {
+	userID := c.Params("userId")
+	if userID == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "User ID is required",
+		})
+	}
+
+	var body struct {
+		Balance float64 `json:"balance"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if _, err := s.db.GetUser(c.Context(), userID); errors.Is(err, database.ErrUserNotFound) {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "User not found",
+		})
+	} else if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to load user",
+		})
+	}
+
+	balanceKey := game.REDIS_KEY_USER_BALANCE + userID
+	err := s.cache.GetClient().Set(c.Context(), balanceKey, body.Balance, 0).Err()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to set balance",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"user_id":           userID,
+		"balance":           body.Balance,
+		"formatted_balance": game.FormatAmount(body.Balance),
+		"message":           "Balance updated successfully",
+	})
+}
+
+// Wallet handlers
+
+// depositHandler credits a simulated deposit. It is only reachable with the
+// X-Admin-Token header.
+func (s *FiberServer) depositHandler(c *fiber.Ctx) error {
+	return s.walletHandler(c, s.wallet.Deposit)
+}
+
+func (s *FiberServer) withdrawHandler(c *fiber.Ctx) error {
+	return s.walletHandler(c, s.wallet.Withdraw)
+}
+
+// walletHandler parses {"amount": ...} and applies it with move
+func (s *FiberServer) walletHandler(c *fiber.Ctx, move func(ctx context.Context, userID string, amount float64) (*game.WalletResponse, error)) error {
+	userID := c.Params("userId")
+	if userID == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "User ID is required",
+		})
+	}
+
+	var body struct {
+		Amount float64 `json:"amount"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	resp, err := move(c.Context(), userID, body.Amount)
+	switch {
+	case errors.Is(err, game.ErrWalletRateLimited):
+		return c.Status(429).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, game.ErrWalletAmount), errors.Is(err, game.ErrWithdrawalBelowMin),
+		errors.Is(err, game.ErrInsufficientBalance), errors.Is(err, game.ErrAmountPrecision),
+		errors.Is(err, game.ErrAmountInvalid):
+		return c.Status(400).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case err != nil:
+		log.Printf("[WALLET] Failed to update balance of %s: %v", userID, err)
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to update balance",
+		})
+	}
+
+	resp.FormattedBalance = game.FormatAmount(resp.Balance)
+	return c.JSON(resp)
+}
+
+// walletHistoryHandler lists a user's deposits and withdrawals, newest first
+func (s *FiberServer) walletHistoryHandler(c *fiber.Ctx) error {
+	userID := c.Params("userId")
+	limit := c.QueryInt("limit", game.WALLET_HISTORY_DEFAULT_LIMIT)
+	if limit < 1 || limit > game.WALLET_HISTORY_MAX_LIMIT {
+		return c.Status(400).JSON(fiber.Map{
+			"error": fmt.Sprintf("limit must be between 1 and %d", game.WALLET_HISTORY_MAX_LIMIT),
+		})
+	}
+
+	types := []string{game.DEPOSIT_TRANSACTION_TYPE, game.WITHDRAWAL_TRANSACTION_TYPE}
+	transactions, err := s.db.UserTransactions(c.Context(), userID, types, limit)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to load deposits",
+		})
+	}
+
+	history := make([]fiber.Map, 0, len(transactions))
+	for _, tx := range transactions {
+		history = append(history, fiber.Map{
+			"id":             tx.ID,
+			"type":           tx.Type,
+			"amount":         tx.Amount,
+			"balance_before": tx.BalanceBefore,
+			"balance_after":  tx.BalanceAfter,
+			"created_at":     tx.CreatedAt,
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"user_id":      userID,
+		"transactions": history,
+	})
+}
+
+// Referral handlers
+
+// registerUserHandler is a demo registration that only records the referrer
+func (s *FiberServer) registerUserHandler(c *fiber.Ctx) error {
+	var body struct {
+		UserID     string `json:"user_id"`
+		ReferrerID string `json:"referrer_id"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if body.UserID == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "User ID is required",
+		})
+	}
+
+	if body.ReferrerID != "" {
+		err := s.referrals.Register(c.Context(), body.UserID, body.ReferrerID)
+		if errors.Is(err, game.ErrReferralSelf) || errors.Is(err, game.ErrAlreadyReferred) {
+			return c.Status(400).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error": "Failed to register referral",
+			})
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"user_id":            body.UserID,
+		"referrer_id":        body.ReferrerID,
+		"referral_link_code": s.referrals.Code(body.UserID),
+		"message":            "User registered successfully",
+	})
+}
+
+func (s *FiberServer) getUserReferralsHandler(c *fiber.Ctx) error {
+	userID := c.Params("userId")
+	if userID == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "User ID is required",
+		})
+	}
+
+	stats, err := s.referrals.Stats(c.Context(), userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to load referrals",
+		})
+	}
+
+	return c.JSON(stats)
+}
+
+func (s *FiberServer) resolveReferralCodeHandler(c *fiber.Ctx) error {
+	userID, err := s.referrals.Resolve(c.Context(), c.Params("code"))
+	if errors.Is(err, game.ErrReferralCodeNotFound) {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Referral code not found",
+		})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to resolve referral code",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"user_id": userID,
+	})
+}
+
+// betError responds to a failed bet, with 503 while the game is under maintenance
+func betError(c *fiber.Ctx, err error) error {
+	var maintenance *game.MaintenanceError
+	if errors.As(err, &maintenance) {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error":       "Game under maintenance",
+			"message":     maintenance.Message,
+			"eta_minutes": maintenance.ETAMinutes,
+		})
+	}
+	if errors.Is(err, game.ErrAtCapacity) {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.Status(500).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
+
+// Mines game handlers
+
+func (s *FiberServer) minesBetHandler(c *fiber.Ctx) error {
+	var req game.MinesBetRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.UserID == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "User ID is required",
+		})
+	}
+
+	engine, exists := s.gameFactory.GetEngine(game.GameTypeMines)
+	if !exists {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Mines game not available",
+		})
+	}
+
+	resp, err := engine.PlaceBet(c.Context(), req)
+	if err != nil {
+		return betError(c, err)
+	}
+
+	betResp, ok := resp.(game.MinesBetResponse)
+	if !ok || !betResp.Success {
+		return c.Status(400).JSON(resp)
+	}
+
+	return c.JSON(resp)
+}
+
+func (s *FiberServer) minesClickHandler(c *fiber.Ctx) error {
+	var req game.MinesClickRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.UserID == "" || req.GameID == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "User ID and Game ID are required",
+		})
+	}
+	if mode := c.Query("mode"); mode != "" {
+		req.DisplayMode = mode
+	}
+
+	engine, exists := s.gameFactory.GetEngine(game.GameTypeMines)
+	if !exists {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Mines game not available",
+		})
+	}
+
+	resp, err := engine.ProcessAction(c.Context(), "click", req)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	clickResp, ok := resp.(game.MinesClickResponse)
+	if !ok || !clickResp.Success {
+		return c.Status(400).JSON(resp)
+	}
+
+	return c.JSON(resp)
+}
+
+// minesPreselectHandler reveals several tiles of a Mines game in one request
+func (s *FiberServer) minesPreselectHandler(c *fiber.Ctx) error {
+	var req game.MinesPreselectRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.UserID == "" || req.GameID == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "User ID and Game ID are required",
+		})
+	}
+
+	engine, exists := s.gameFactory.GetEngine(game.GameTypeMines)
+	if !exists {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Mines game not available",
+		})
+	}
+
+	resp, err := engine.ProcessAction(c.Context(), "preselect", req)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	preselectResp, ok := resp.(game.MinesPreselectResponse)
+	if !ok || !preselectResp.Success {
+		return c.Status(400).JSON(resp)
+	}
+
+	return c.JSON(resp)
+}
+
+// minesMultiGameHandler plays several Mines games with server-chosen tiles in one request
+func (s *FiberServer) minesMultiGameHandler(c *fiber.Ctx) error {
+	var req game.MinesMultiGameRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.UserID == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "User ID is required",
+		})
+	}
+
+	engine, exists := s.gameFactory.GetEngine(game.GameTypeMines)
+	if !exists {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Mines game not available",
+		})
+	}
+
+	resp, err := engine.ProcessAction(c.Context(), "multi_game", req)
+	if err != nil {
+		return betError(c, err)
+	}
+
+	multiResp, ok := resp.(game.MinesMultiGameResponse)
+	if !ok || !multiResp.Success {
+		return c.Status(400).JSON(resp)
+	}
+
+	return c.JSON(resp)
+}
+
+func (s *FiberServer) minesCashoutHandler(c *fiber.Ctx) error {
+	var req game.MinesCashoutRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.UserID == "" || req.GameID == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "User ID and Game ID are required",
+		})
+	}
+
+	engine, exists := s.gameFactory.GetEngine(game.GameTypeMines)
+	if !exists {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Mines game not available",
+		})
+	}
+
+	resp, err := engine.ProcessAction(c.Context(), "cashout", req)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	cashoutResp, ok := resp.(game.MinesCashoutResponse)
+	if !ok || !cashoutResp.Success {
+		return c.Status(400).JSON(resp)
+	}
+
+	return c.JSON(resp)
+}
+
+// minesCashoutAllHandler cashes out every active game of a user. Games with
+// no revealed tile are skipped and listed in the results.
+func (s *FiberServer) minesCashoutAllHandler(c *fiber.Ctx) error {
+	var req game.MinesCashoutAllRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.UserID == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "User ID is required",
+		})
+	}
+
+	engine, exists := s.gameFactory.GetEngine(game.GameTypeMines)
+	if !exists {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Mines game not available",
+		})
+	}
+
+	resp, err := engine.ProcessAction(c.Context(), "cashout_all", req)
+	if errors.Is(err, game.ErrCashoutAllRateLimited) {
+		return c.Status(429).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(resp)
+}
+
+// minesProbabilitiesHandler returns the mine chance of each hidden tile of an active game
+func (s *FiberServer) minesProbabilitiesHandler(c *fiber.Ctx) error {
+	engine, exists := s.gameFactory.GetEngine(game.GameTypeMines)
+	if !exists {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Mines game not available",
+		})
+	}
+
+	resp, err := engine.ProcessAction(c.Context(), "probabilities", game.MinesStateRequest{GameID: c.Params("gameID")})
+	if errors.Is(err, game.ErrGameNotFound) {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Game not found",
+		})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	probResp, ok := resp.(game.MinesProbabilitiesResponse)
+	if !ok || !probResp.Success {
+		return c.Status(400).JSON(resp)
+	}
+
+	return c.JSON(resp)
+}
+
+// minesOddsTableHandler returns the payout table of one mine count, or of
+// every mine count when mine_count is omitted, under the current house edge
+func (s *FiberServer) minesOddsTableHandler(c *fiber.Ctx) error {
+	mineCounts := make([]int, 0, game.MINES_MAX_COUNT)
+	if param := c.Query("mine_count"); param != "" {
+		mineCount, err := strconv.Atoi(param)
+		if err == nil {
+			if msg := game.ValidateMinesLayout(game.MINES_GRID_SIZE, mineCount); msg != "" {
+				err = errors.New(msg)
+			}
+		}
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error": fmt.Sprintf("mine_count must be between %d and %d", game.MINES_MIN_COUNT, game.MINES_MAX_COUNT),
+			})
+		}
+		mineCounts = append(mineCounts, mineCount)
+	} else {
+		for mineCount := game.MINES_MIN_COUNT; mineCount <= game.MINES_MAX_COUNT; mineCount++ {
+			mineCounts = append(mineCounts, mineCount)
+		}
+	}
+
+	houseEdge := game.GetCurrentHouseEdge(game.GameTypeMines)
+	tables := make([]game.MinesOddsTable, len(mineCounts))
+	for i, mineCount := range mineCounts {
+		tables[i] = game.NewMinesOddsTable(mineCount, houseEdge)
+	}
+
+	return c.JSON(fiber.Map{
+		"house_edge": houseEdge,
+		"tables":     tables,
+	})
+}
+
+// minesForfeitHandler abandons a game the user has not revealed any tiles in
+func (s *FiberServer) minesForfeitHandler(c *fiber.Ctx) error {
+	req := game.MinesForfeitRequest{
+		UserID: c.Query("user_id"),
+		GameID: c.Params("gameID"),
+	}
+	if req.UserID == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "User ID is required",
+		})
+	}
+
+	engine, exists := s.gameFactory.GetEngine(game.GameTypeMines)
+	if !exists {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Mines game not available",
+		})
+	}
+
+	resp, err := engine.ProcessAction(c.Context(), "forfeit", req)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	forfeitResp, ok := resp.(game.MinesForfeitResponse)
+	if !ok || !forfeitResp.Success {
+		return c.Status(400).JSON(resp)
+	}
+
+	return c.JSON(resp)
+}
+
+func (s *FiberServer) minesGameStateHandler(c *fiber.Ctx) error {
+	gameID := c.Params("gameID")
+	if gameID == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Game ID is required",
+		})
+	}
+
+	engine, exists := s.gameFactory.GetEngine(game.GameTypeMines)
+	if !exists {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Mines game not available",
+		})
+	}
+
+	resp, err := engine.ProcessAction(c.Context(), "state", game.MinesStateRequest{GameID: gameID})
+	if errors.Is(err, game.ErrGameNotFound) {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Game not found",
+		})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(resp)
+}
+
+// minesGridHandler returns the tiles of a game in the rows of its grid shape
+func (s *FiberServer) minesGridHandler(c *fiber.Ctx) error {
+	engine, exists := s.gameFactory.GetEngine(game.GameTypeMines)
+	if !exists {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Mines game not available",
+		})
+	}
+
+	resp, err := engine.ProcessAction(c.Context(), "grid", game.MinesStateRequest{GameID: c.Params("gameID")})
+	if errors.Is(err, game.ErrGameNotFound) {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Game not found",
+		})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(resp)
+}
+
+func (s *FiberServer) minesAutoCompleteHandler(c *fiber.Ctx) error {
+	var req game.MinesAutoCompleteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+	req.GameID = c.Params("gameID")
+
+	if req.UserID == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "User ID is required",
+		})
+	}
+
+	engine, exists := s.gameFactory.GetEngine(game.GameTypeMines)
+	if !exists {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Mines game not available",
+		})
+	}
+
+	resp, err := engine.ProcessAction(c.Context(), "auto_complete", req)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	autoResp, ok := resp.(game.MinesAutoCompleteResponse)
+	if !ok || !autoResp.Success {
+		return c.Status(400).JSON(resp)
+	}
+
+	return c.JSON(resp)
+}
+
+// minesLeaderboardHandler returns the best single games by tiles revealed or multiplier
+func (s *FiberServer) minesLeaderboardHandler(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", game.MINES_LEADERBOARD_DEFAULT_LIMIT)
+	if limit < 1 || limit > game.MINES_LEADERBOARD_MAX_LIMIT {
+		return c.Status(400).JSON(fiber.Map{
+			"error": fmt.Sprintf("limit must be between 1 and %d", game.MINES_LEADERBOARD_MAX_LIMIT),
+		})
+	}
+
+	engine, exists := s.gameFactory.GetEngine(game.GameTypeMines)
+	if !exists {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Mines game not available",
+		})
+	}
+
+	resp, err := engine.ProcessAction(c.Context(), "leaderboard", game.MinesLeaderboardRequest{Board: c.Params("board"), Limit: limit})
+	if errors.Is(err, game.ErrUnknownLeaderboard) {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Leaderboard not found",
+		})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(resp)
+}
+
+func (s *FiberServer) minesActiveCountHandler(c *fiber.Ctx) error {
+	return s.activeCount(c, game.GameTypeMines)
+}
+
+func (s *FiberServer) diceActiveCountHandler(c *fiber.Ctx) error {
+	return s.activeCount(c, game.GameTypeDice)
+}
+
+func (s *FiberServer) plinkoActiveCountHandler(c *fiber.Ctx) error {
+	return s.activeCount(c, game.GameTypePlinko)
+}
+
+// activeCount returns how busy a game is right now, cached for a few seconds
+func (s *FiberServer) activeCount(c *fiber.Ctx, gameType game.GameType) error {
+	engine, exists := s.gameFactory.GetEngine(gameType)
+	if !exists {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Game not available",
+		})
+	}
+
+	stats, err := engine.ProcessAction(c.Context(), "active_count", nil)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to load activity stats",
+		})
+	}
+	return c.JSON(stats)
+}
+
+// Plinko game handlers
+
+func (s *FiberServer) plinkoDropHandler(c *fiber.Ctx) error {
+	var req game.PlinkoDropRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.UserID == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "User ID is required",
+		})
+	}
+
+	engine, exists := s.gameFactory.GetEngine(game.GameTypePlinko)
+	if !exists {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Plinko game not available",
+		})
+	}
+
+	resp, err := engine.PlaceBet(c.Context(), req)
+	if err != nil {
+		return betError(c, err)
+	}
+
+	dropResp, ok := resp.(game.PlinkoDropResponse)
+	if !ok || !dropResp.Success {
+		return c.Status(400).JSON(resp)
+	}
+
+	return c.JSON(resp)
+}
+
+func (s *FiberServer) plinkoAutoDropHandler(c *fiber.Ctx) error {
+	var req game.PlinkoAutoDropRequest
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(fiber.Map{
 			"error": "Invalid request body",
@@ -51,94 +1694,110 @@ func (s *FiberServer) placeBetHandler(c *fiber.Ctx) error {
 		})
 	}
 
-	resp := s.gameManager.PlaceBet(req)
-	if !resp.Success {
+	engine, exists := s.gameFactory.GetEngine(game.GameTypePlinko)
+	if !exists {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Plinko game not available",
+		})
+	}
+
+	resp, err := engine.ProcessAction(c.Context(), "auto_drop", req)
+	if errors.Is(err, game.ErrAutoDropInProgress) {
+		return c.Status(409).JSON(fiber.Map{
+			"error": "Auto-drop already in progress",
+		})
+	}
+	autoResp, ok := resp.(game.PlinkoAutoDropResponse)
+	if err != nil && ok && autoResp.DropsCompleted > 0 {
+		// Report the balls that already dropped along with the error
+		return c.Status(500).JSON(autoResp)
+	}
+	if err != nil {
+		return betError(c, err)
+	}
+
+	if !ok || !autoResp.Success {
 		return c.Status(400).JSON(resp)
 	}
 
 	return c.JSON(resp)
 }
 
-func (s *FiberServer) cashoutHandler(c *fiber.Ctx) error {
-	var req game.CashoutRequest
+// Dice game handlers
+
+func (s *FiberServer) diceRollHandler(c *fiber.Ctx) error {
+	var req game.DiceRollRequest
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(fiber.Map{
 			"error": "Invalid request body",
 		})
 	}
 
-	if req.UserID == "" || req.BetID == "" {
+	if req.UserID == "" {
 		return c.Status(400).JSON(fiber.Map{
-			"error": "User ID and Bet ID are required",
+			"error": "User ID is required",
 		})
 	}
 
-	resp := s.gameManager.Cashout(req)
-	if !resp.Success {
+	engine, exists := s.gameFactory.GetEngine(game.GameTypeDice)
+	if !exists {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Dice game not available",
+		})
+	}
+
+	resp, err := engine.PlaceBet(c.Context(), req)
+	if err != nil {
+		return betError(c, err)
+	}
+
+	rollResp, ok := resp.(game.DiceRollResponse)
+	if ok && apiVersion(c) == API_VERSION_V2 {
+		resp = game.NewDiceRollResponseV2(rollResp)
+	}
+	if !ok || !rollResp.Success {
 		return c.Status(400).JSON(resp)
 	}
 
 	return c.JSON(resp)
 }
 
-// User balance handlers
-
-func (s *FiberServer) getUserBalanceHandler(c *fiber.Ctx) error {
-	userID := c.Params("userId")
-	if userID == "" {
+func (s *FiberServer) diceExactHandler(c *fiber.Ctx) error {
+	var req game.DiceExactRequest
+	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(fiber.Map{
-			"error": "User ID is required",
+			"error": "Invalid request body",
 		})
 	}
 
-	balanceKey := game.REDIS_KEY_USER_BALANCE + userID
-	balance, err := s.cache.GetClient().Get(c.Context(), balanceKey).Float64()
-	if err != nil {
-		balance = 0.0
-	}
-
-	return c.JSON(fiber.Map{
-		"user_id": userID,
-		"balance": balance,
-	})
-}
-
-func (s *FiberServer) setUserBalanceHandler(c *fiber.Ctx) error {
-	userID := c.Params("userId")
-	if userID == "" {
+	if req.UserID == "" {
 		return c.Status(400).JSON(fiber.Map{
 			"error": "User ID is required",
 		})
 	}
 
-	var body struct {
-		Balance float64 `json:"balance"`
-	}
-	if err := c.BodyParser(&body); err != nil {
+	return s.diceAction(c, game.DiceModeExact, req)
+}
+
+func (s *FiberServer) diceRangeHandler(c *fiber.Ctx) error {
+	var req game.DiceRangeRequest
+	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(fiber.Map{
 			"error": "Invalid request body",
 		})
 	}
 
-	balanceKey := game.REDIS_KEY_USER_BALANCE + userID
-	err := s.cache.GetClient().Set(c.Context(), balanceKey, body.Balance, 0).Err()
-	if err != nil {
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to set balance",
+	if req.UserID == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "User ID is required",
 		})
 	}
 
-	return c.JSON(fiber.Map{
-		"user_id": userID,
-		"balance": body.Balance,
-		"message": "Balance updated successfully",
-	})
+	return s.diceAction(c, game.DiceModeRange, req)
 }
 
-// Mines game handlers
-
-func (s *FiberServer) minesBetHandler(c *fiber.Ctx) error {
-	var req game.MinesBetRequest
+func (s *FiberServer) diceParlayHandler(c *fiber.Ctx) error {
+	var req game.DiceParlayRequest
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(fiber.Map{
 			"error": "Invalid request body",
@@ -151,110 +1810,146 @@ func (s *FiberServer) minesBetHandler(c *fiber.Ctx) error {
 		})
 	}
 
-	engine, exists := s.gameFactory.GetEngine(game.GameTypeMines)
+	engine, exists := s.gameFactory.GetEngine(game.GameTypeDice)
 	if !exists {
 		return c.Status(500).JSON(fiber.Map{
-			"error": "Mines game not available",
+			"error": "Dice game not available",
 		})
 	}
 
-	resp, err := engine.PlaceBet(c.Context(), req)
+	resp, err := engine.ProcessAction(c.Context(), game.DiceModeParlay, req)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return betError(c, err)
 	}
 
-	betResp, ok := resp.(game.MinesBetResponse)
-	if !ok || !betResp.Success {
+	parlayResp, ok := resp.(game.DiceParlayResponse)
+	if !ok || !parlayResp.Success {
 		return c.Status(400).JSON(resp)
 	}
 
 	return c.JSON(resp)
 }
 
-func (s *FiberServer) minesClickHandler(c *fiber.Ctx) error {
-	var req game.MinesClickRequest
-	if err := c.BodyParser(&req); err != nil {
+// diceParlayOddsHandler previews a parlay without placing it. The legs are
+// passed as a JSON array in the legs query parameter.
+func (s *FiberServer) diceParlayOddsHandler(c *fiber.Ctx) error {
+	var legs []game.DiceParlayLeg
+	if err := json.Unmarshal([]byte(c.Query("legs")), &legs); err != nil {
 		return c.Status(400).JSON(fiber.Map{
-			"error": "Invalid request body",
+			"error": `legs must be a JSON array of {"target", "is_over"}`,
 		})
 	}
 
-	if req.UserID == "" || req.GameID == "" {
+	engine, exists := s.gameFactory.GetEngine(game.GameTypeDice)
+	if !exists {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Dice game not available",
+		})
+	}
+
+	odds, err := engine.ProcessAction(c.Context(), "parlay_odds", legs)
+	if err != nil {
 		return c.Status(400).JSON(fiber.Map{
-			"error": "User ID and Game ID are required",
+			"error": err.Error(),
 		})
 	}
 
-	engine, exists := s.gameFactory.GetEngine(game.GameTypeMines)
+	return c.JSON(odds)
+}
+
+// diceAction runs one of the dice variants handled by ProcessAction
+func (s *FiberServer) diceAction(c *fiber.Ctx, action string, req interface{}) error {
+	engine, exists := s.gameFactory.GetEngine(game.GameTypeDice)
 	if !exists {
 		return c.Status(500).JSON(fiber.Map{
-			"error": "Mines game not available",
+			"error": "Dice game not available",
 		})
 	}
 
-	resp, err := engine.ProcessAction(c.Context(), "click", req)
+	resp, err := engine.ProcessAction(c.Context(), action, req)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return betError(c, err)
 	}
 
-	clickResp, ok := resp.(game.MinesClickResponse)
-	if !ok || !clickResp.Success {
+	rollResp, ok := resp.(game.DiceRollResponse)
+	if !ok || !rollResp.Success {
 		return c.Status(400).JSON(resp)
 	}
 
 	return c.JSON(resp)
 }
 
-func (s *FiberServer) minesCashoutHandler(c *fiber.Ctx) error {
-	var req game.MinesCashoutRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "Invalid request body",
+func (s *FiberServer) diceHistoryHandler(c *fiber.Ctx) error {
+	return s.gameHistory(c, game.GameTypeDice)
+}
+
+func (s *FiberServer) minesHistoryHandler(c *fiber.Ctx) error {
+	return s.gameHistory(c, game.GameTypeMines)
+}
+
+func (s *FiberServer) plinkoHistoryHandler(c *fiber.Ctx) error {
+	return s.gameHistory(c, game.GameTypePlinko)
+}
+
+// plinkoStatsHandler summarises the drops in a user's Plinko history
+func (s *FiberServer) plinkoStatsHandler(c *fiber.Ctx) error {
+	engine, exists := s.gameFactory.GetEngine(game.GameTypePlinko)
+	if !exists {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Game not available",
 		})
 	}
 
-	if req.UserID == "" || req.GameID == "" {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "User ID and Game ID are required",
+	stats, err := engine.ProcessAction(c.Context(), "stats", game.PlinkoStatsRequest{UserID: c.Params("userId")})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to load stats",
 		})
 	}
 
-	engine, exists := s.gameFactory.GetEngine(game.GameTypeMines)
+	return c.JSON(stats)
+}
+
+// plinkoVerifyHandler replays a stored drop from its seeds. ?verbose=true adds
+// the HMAC computed for every row.
+func (s *FiberServer) plinkoVerifyHandler(c *fiber.Ctx) error {
+	engine, exists := s.gameFactory.GetEngine(game.GameTypePlinko)
 	if !exists {
 		return c.Status(500).JSON(fiber.Map{
-			"error": "Mines game not available",
+			"error": "Game not available",
 		})
 	}
 
-	resp, err := engine.ProcessAction(c.Context(), "cashout", req)
+	verification, err := engine.ProcessAction(c.Context(), "verify", game.PlinkoVerifyRequest{
+		GameID:  c.Params("gameID"),
+		Verbose: c.QueryBool("verbose"),
+	})
+	if errors.Is(err, game.ErrGameNotFound) {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Game not found",
+		})
+	}
+	if errors.Is(err, game.ErrPlinkoGameExpired) {
+		return c.Status(410).JSON(fiber.Map{
+			"error": "Drops can only be verified for an hour after they are played",
+		})
+	}
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{
-			"error": err.Error(),
+			"error": "Failed to verify game",
 		})
 	}
 
-	cashoutResp, ok := resp.(game.MinesCashoutResponse)
-	if !ok || !cashoutResp.Success {
-		return c.Status(400).JSON(resp)
-	}
-
-	return c.JSON(resp)
+	return c.JSON(verification)
 }
 
-// Plinko game handlers
-
-func (s *FiberServer) plinkoDropHandler(c *fiber.Ctx) error {
-	var req game.PlinkoDropRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+// plinkoRevealHandler returns the result of a deferred drop and pays it out
+// to the user who dropped it
+func (s *FiberServer) plinkoRevealHandler(c *fiber.Ctx) error {
+	req := game.PlinkoRevealRequest{
+		UserID: c.Query("user_id"),
+		GameID: c.Params("gameID"),
 	}
-
 	if req.UserID == "" {
 		return c.Status(400).JSON(fiber.Map{
 			"error": "User ID is required",
@@ -268,7 +1963,17 @@ func (s *FiberServer) plinkoDropHandler(c *fiber.Ctx) error {
 		})
 	}
 
-	resp, err := engine.PlaceBet(c.Context(), req)
+	resp, err := engine.ProcessAction(c.Context(), "reveal", req)
+	if errors.Is(err, game.ErrGameNotFound) {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Drop not found, expired or already revealed",
+		})
+	}
+	if errors.Is(err, game.ErrNotGameOwner) {
+		return c.Status(403).JSON(fiber.Map{
+			"error": "Drop does not belong to user",
+		})
+	}
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{
 			"error": err.Error(),
@@ -283,62 +1988,218 @@ func (s *FiberServer) plinkoDropHandler(c *fiber.Ctx) error {
 	return c.JSON(resp)
 }
 
-// Dice game handlers
+// plinkoConfigHandler returns the multiplier tables with their expected
+// value. Any configuration paying back more than it takes is listed in alerts.
+func (s *FiberServer) plinkoConfigHandler(c *fiber.Ctx) error {
+	var config []game.EVResult
+	if engine, exists := s.gameFactory.GetEngine(game.GameTypePlinko); exists {
+		if plinko, ok := engine.(*game.PlinkoEngine); ok {
+			config = plinko.Config() // Precomputed probabilities
+		}
+	}
+	if config == nil {
+		config = game.PlinkoConfig()
+	}
 
-func (s *FiberServer) diceRollHandler(c *fiber.Ctx) error {
-	var req game.DiceRollRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	alerts := make([]string, 0)
+	for _, entry := range config {
+		if entry.ExpectedValue > 1.0 {
+			alert := fmt.Sprintf("%s risk with %d rows has an expected value of %.4f, above 1.0", entry.Risk, entry.Rows, entry.ExpectedValue)
+			log.Printf("[PLINKO] %s", alert)
+			alerts = append(alerts, alert)
+		}
 	}
 
-	if req.UserID == "" {
+	return c.JSON(fiber.Map{
+		"configs": config,
+		"alerts":  alerts,
+	})
+}
+
+// gameHistory returns a page of a user's recent games of one type
+func (s *FiberServer) gameHistory(c *fiber.Ctx, gameType game.GameType) error {
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", game.GAME_HISTORY_DEFAULT_LIMIT)
+	if page < 1 || limit < 1 || limit > game.GAME_HISTORY_MAX_LIMIT {
 		return c.Status(400).JSON(fiber.Map{
-			"error": "User ID is required",
+			"error": fmt.Sprintf("page must be at least 1 and limit between 1 and %d", game.GAME_HISTORY_MAX_LIMIT),
 		})
 	}
 
-	engine, exists := s.gameFactory.GetEngine(game.GameTypeDice)
+	engine, exists := s.gameFactory.GetEngine(gameType)
 	if !exists {
 		return c.Status(500).JSON(fiber.Map{
-			"error": "Dice game not available",
+			"error": "Game not available",
 		})
 	}
 
-	resp, err := engine.PlaceBet(c.Context(), req)
+	resp, err := engine.ProcessAction(c.Context(), "history", game.GameHistoryRequest{
+		UserID: c.Params("userId"),
+		Page:   page,
+		Limit:  limit,
+	})
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{
-			"error": err.Error(),
+			"error": "Failed to load history",
 		})
 	}
 
-	rollResp, ok := resp.(game.DiceRollResponse)
-	if !ok || !rollResp.Success {
-		return c.Status(400).JSON(resp)
+	return c.JSON(fiber.Map{
+		"page":  page,
+		"limit": limit,
+		"games": resp,
+	})
+}
+
+// WebSocket handler
+
+// gameConn is the part of *websocket.Conn used when a client connects
+type gameConn interface {
+	WriteMessage(messageType int, data []byte) error
+	SetWriteDeadline(t time.Time) error
+	Close() error
+}
+
+// parseGameTypes reads a comma-separated list of game types such as "aviator,mines"
+func parseGameTypes(list string) []game.GameType {
+	var games []game.GameType
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			games = append(games, game.GameType(name))
+		}
 	}
+	return games
+}
 
-	return c.JSON(resp)
+// validChatGameType rejects a game_type parameter that is not a known game.
+// It picks the client's chat room, whose history is stored under
+// chat:history:<game_type>.
+func validChatGameType(c *fiber.Ctx) error {
+	switch game.GameType(c.Query("game_type", string(game.GameTypeAviator))) {
+	case game.GameTypeAviator, game.GameTypeMines, game.GameTypePlinko, game.GameTypeDice:
+		return c.Next()
+	}
+	return c.Status(400).JSON(fiber.Map{
+		"error": "game_type must be aviator, mines, plinko or dice",
+	})
 }
 
-// WebSocket handler
+// initialState builds the initial_state message for a user playing games,
+// with chat from gameType. It also returns the Mines games to subscribe to.
+func (s *FiberServer) initialState(ctx context.Context, userID string, gameType game.GameType, games []game.GameType) (map[string]interface{}, []string) {
+	state := map[string]interface{}{
+		"type":         "initial_state",
+		"connected_at": time.Now().UTC(),
+	}
 
-func (s *FiberServer) gameWebSocketHandler(conn *websocket.Conn) {
-	userID := conn.Query("user_id", "anonymous")
+	chatHistory, err := s.chat.History(ctx, gameType)
+	if err != nil {
+		log.Printf("[WS] Failed to load chat history: %v", err)
+	}
+	state["chat_history"] = chatHistory
+
+	if userID != "anonymous" {
+		prefs, err := game.LoadPreferences(ctx, s.cache.GetClient(), userID)
+		if err != nil {
+			log.Printf("[WS] Failed to load preferences of %s: %v", userID, err)
+		} else if prefs != nil {
+			state["preferences"] = prefs
+		}
+	}
+
+	var subscriptions []string
+	for _, g := range games {
+		switch g {
+		case game.GameTypeAviator:
+			if round := s.gameManager.GetCurrentRound(); round != nil {
+				state["crash_state"] = round
+			}
+
+		case game.GameTypeMines:
+			engine, exists := s.gameFactory.GetEngine(game.GameTypeMines)
+			if !exists {
+				continue
+			}
+			resp, err := engine.ProcessAction(ctx, "active_game", game.MinesActiveGameRequest{UserID: userID})
+			if err != nil {
+				log.Printf("[WS] Failed to load active Mines game for %s: %v", userID, err)
+				continue
+			}
+			if activeGame, ok := resp.(*game.MinesGameState); ok {
+				state["active_mines_game"] = activeGame
+				subscriptions = append(subscriptions, activeGame.GameID)
+			}
+		}
+	}
+	return state, subscriptions
+}
 
-	log.Printf("[WS] New connection from user: %s", userID)
+// connectClient registers a new connection with the hub and sends it the
+// initial state of the games it plays. A user's active Mines game is
+// subscribed to straight away.
+func (s *FiberServer) connectClient(conn gameConn, userID string, gameType game.GameType, games []game.GameType) {
+	state, subscriptions := s.initialState(context.Background(), userID, gameType, games)
+	s.gameHub.RegisterGameClient(conn, userID, gameType, subscriptions...)
 
-	s.gameHub.RegisterClient(conn, userID)
+	stateJSON, _ := json.Marshal(state)
+	conn.WriteMessage(websocket.TextMessage, stateJSON)
+}
 
-	currentState := s.gameManager.GetCurrentRound()
-	if currentState != nil {
-		stateJSON, _ := json.Marshal(map[string]interface{}{
-			"type": "initial_state",
-			"data": currentState,
-		})
-		conn.WriteMessage(websocket.TextMessage, stateJSON)
+// initialStateETag identifies the initial state of a request by its
+// parameters and the current Aviator round, including its multiplier
+func (s *FiberServer) initialStateETag(userID string, gameType game.GameType, games []game.GameType) string {
+	roundTag := "none"
+	if round := s.gameManager.GetCurrentRound(); round != nil {
+		roundTag = fmt.Sprintf("%s:%s:%.2f", round.RoundID, round.Status, round.CurrentMultiplier)
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s|%s|%v|%s", userID, gameType, games, roundTag)
+	return `"` + hex.EncodeToString(h.Sum(nil))[:16] + `"`
+}
+
+// initialStateHandler returns the WebSocket initial_state message over plain
+// HTTP, so clients can render the lobby before the WebSocket is up:
+//
+//	GET /api/v1/game/initial-state?user_id=u1&games=aviator,mines&game_type=aviator
+//
+// The parameters match the WebSocket's. The ETag changes with the Aviator
+// round and its multiplier; send it back in If-None-Match to get a 304 while
+// the round is unchanged. Mines and chat state can change without it, so a
+// client should still open the WebSocket, which sends a fresh initial_state.
+// Responses are cached in Redis for INITIAL_STATE_CACHE_TTL.
+func (s *FiberServer) initialStateHandler(c *fiber.Ctx) error {
+	userID := c.Query("user_id", "anonymous")
+	gameType := game.GameType(c.Query("game_type", string(game.GameTypeAviator)))
+	games := parseGameTypes(c.Query("games", string(gameType)))
+
+	etag := s.initialStateETag(userID, gameType, games)
+	c.Set(fiber.HeaderETag, etag)
+	if c.Get(fiber.HeaderIfNoneMatch) == etag {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	cacheKey := REDIS_KEY_INITIAL_STATE + strings.Trim(etag, `"`)
+	stateJSON, err := s.cache.GetClient().Get(c.Context(), cacheKey).Bytes()
+	if err != nil {
+		state, _ := s.initialState(c.Context(), userID, gameType, games)
+		stateJSON, _ = json.Marshal(state)
+		s.cache.GetClient().Set(c.Context(), cacheKey, stateJSON, INITIAL_STATE_CACHE_TTL)
 	}
 
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(stateJSON)
+}
+
+func (s *FiberServer) gameWebSocketHandler(conn *websocket.Conn) {
+	userID := conn.Query("user_id", "anonymous")
+	gameType := game.GameType(conn.Query("game_type", string(game.GameTypeAviator)))
+	games := parseGameTypes(conn.Query("games", string(gameType)))
+
+	log.Printf("[WS] New connection from user: %s (%s, games: %v)", userID, gameType, games)
+
+	s.connectClient(conn, userID, gameType, games)
+
 	for {
 		messageType, message, err := conn.ReadMessage()
 		if err != nil {
@@ -360,14 +2221,23 @@ func (s *FiberServer) gameWebSocketHandler(conn *websocket.Conn) {
 
 			switch msgType {
 			case "place_bet":
-				amount, _ := strconv.ParseFloat(fmt.Sprintf("%v", clientMsg["amount"]), 64)
+				value, _ := strconv.ParseFloat(fmt.Sprintf("%v", clientMsg["amount"]), 64)
 				autoCashout, _ := strconv.ParseFloat(fmt.Sprintf("%v", clientMsg["auto_cashout"]), 64)
-
-				resp := s.gameManager.PlaceBet(game.BetRequest{
-					UserID:      userID,
-					Amount:      amount,
-					AutoCashout: autoCashout,
-				})
+				jitterMs, _ := clientMsg["auto_cashout_jitter_ms"].(float64)
+				earlyExitFeePct, _ := clientMsg["early_exit_fee_pct"].(float64)
+
+				var resp game.BetResponse
+				if amount, err := game.NewAmount(value); err != nil {
+					resp = game.BetResponse{Success: false, Message: "Invalid bet amount"}
+				} else if resp, err = s.gameManager.PlaceBet(game.BetRequest{
+					UserID:              userID,
+					Amount:              amount,
+					AutoCashout:         autoCashout,
+					AutoCashoutJitterMs: int(jitterMs),
+					EarlyExitFeePct:     earlyExitFeePct,
+				}); err != nil {
+					resp = aviatorBetFailure(userID, err)
+				}
 
 				respJSON, _ := json.Marshal(resp)
 				conn.WriteMessage(websocket.TextMessage, respJSON)
@@ -375,14 +2245,71 @@ func (s *FiberServer) gameWebSocketHandler(conn *websocket.Conn) {
 			case "cashout":
 				betID := fmt.Sprintf("%v", clientMsg["bet_id"])
 
-				resp := s.gameManager.Cashout(game.CashoutRequest{
+				resp, err := s.gameManager.Cashout(game.CashoutRequest{
 					UserID: userID,
 					BetID:  betID,
 				})
+				if err != nil {
+					resp = aviatorCashoutFailure(userID, err)
+				}
 
 				respJSON, _ := json.Marshal(resp)
 				conn.WriteMessage(websocket.TextMessage, respJSON)
 
+			case "subscribe_mines":
+				gameID, _ := clientMsg["game_id"].(string)
+				if gameID == "" {
+					continue
+				}
+				if !s.gameHub.SubscribeToGame(conn, gameID) {
+					rejectJSON, _ := json.Marshal(map[string]string{"type": "mines_subscribe_rejected", "game_id": gameID, "reason": "connection is not registered"})
+					conn.WriteMessage(websocket.TextMessage, rejectJSON)
+					continue
+				}
+
+				ackJSON, _ := json.Marshal(map[string]string{"type": "mines_subscribed", "game_id": gameID})
+				conn.WriteMessage(websocket.TextMessage, ackJSON)
+
+			case "unsubscribe_mines":
+				gameID, _ := clientMsg["game_id"].(string)
+				if gameID == "" {
+					continue
+				}
+				s.gameHub.UnsubscribeFromGame(conn, gameID)
+
+				ackJSON, _ := json.Marshal(map[string]string{"type": "mines_unsubscribed", "game_id": gameID})
+				conn.WriteMessage(websocket.TextMessage, ackJSON)
+
+			case "subscribe_balance":
+				if !s.gameHub.SubscribeBalance(conn, userID) {
+					continue
+				}
+
+				ackJSON, _ := json.Marshal(map[string]string{"type": "balance_subscribed"})
+				conn.WriteMessage(websocket.TextMessage, ackJSON)
+
+			case "unsubscribe_balance":
+				s.gameHub.UnsubscribeBalance(conn)
+
+				ackJSON, _ := json.Marshal(map[string]string{"type": "balance_unsubscribed"})
+				conn.WriteMessage(websocket.TextMessage, ackJSON)
+
+			case "subscribe_admin":
+				key, _ := clientMsg["admin_key"].(string)
+				if !s.validAdminKey(key) || !s.gameHub.SubscribeAdmin(conn) {
+					continue
+				}
+
+				ackJSON, _ := json.Marshal(map[string]string{"type": "admin_subscribed"})
+				conn.WriteMessage(websocket.TextMessage, ackJSON)
+
+			case "chat":
+				text, _ := clientMsg["message"].(string)
+				if _, err := s.chat.Post(context.Background(), userID, gameType, text); err != nil {
+					rejectJSON, _ := json.Marshal(map[string]string{"type": "chat_rejected", "reason": err.Error()})
+					conn.WriteMessage(websocket.TextMessage, rejectJSON)
+				}
+
 			case "ping":
 				pongJSON, _ := json.Marshal(map[string]string{"type": "pong"})
 				conn.WriteMessage(websocket.TextMessage, pongJSON)
@@ -0,0 +1,2109 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/valyala/fasthttp"
+
+	"aviator/internal/database"
+	"aviator/internal/game"
+	"aviator/internal/game/testutil"
+	"aviator/internal/notifications"
+)
+
+// mockConn stands in for a WebSocket connection registered with the hub
+type mockConn struct {
+	mu       sync.Mutex
+	messages [][]byte
+}
+
+func (m *mockConn) WriteMessage(messageType int, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, data)
+	return nil
+}
+
+func (m *mockConn) SetWriteDeadline(t time.Time) error { return nil }
+
+func (m *mockConn) Close() error { return nil }
+
+func (m *mockConn) next(t *testing.T) map[string]interface{} {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		m.mu.Lock()
+		if len(m.messages) > 0 {
+			data := m.messages[0]
+			m.messages = m.messages[1:]
+			m.mu.Unlock()
+
+			var msg map[string]interface{}
+			if err := json.Unmarshal(data, &msg); err != nil {
+				t.Fatalf("could not unmarshal message: %v", err)
+			}
+			return msg
+		}
+		m.mu.Unlock()
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("timed out waiting for WebSocket message")
+	return nil
+}
+
+const testAdminKey = "test-admin-key"
+
+// testCache wraps a miniredis-backed client as a cache.Service
+type testCache struct {
+	client *redis.Client
+}
+
+func (tc testCache) GetClient() *redis.Client { return tc.client }
+
+func (tc testCache) Health() map[string]string { return map[string]string{"status": "up"} }
+
+func (tc testCache) Close() error { return nil }
+
+// testDB reports a fixed health status and keeps users, rounds, bets and transactions in memory in place of PostgreSQL
+type testDB struct {
+	status       string
+	users        map[string]*database.User
+	rounds       map[string]database.Round
+	bets         map[string][]database.Bet
+	transactions map[string][]database.Transaction
+	settlements  map[string][]database.Settlement // By game type
+}
+
+func (db testDB) Health() map[string]string { return map[string]string{"status": db.status} }
+
+func (db testDB) Stats() sql.DBStats {
+	return sql.DBStats{MaxOpenConnections: 25, OpenConnections: 2, Idle: 2}
+}
+
+func (db testDB) QueryStats() database.QueryStats {
+	return database.QueryStats{TotalQueries: 4, AvgDurationMs: 30, MaxDurationMs: 150, QueriesAboveThreshold: 1}
+}
+
+func (db testDB) SlowQueries() []database.SlowQuery {
+	return []database.SlowQuery{{Operation: "GetUserStats", DurationMs: 150, QueryPreview: "SELECT user_id FROM bets", RowsReturned: 3}}
+}
+
+func (db testDB) Ping(ctx context.Context) error {
+	if db.status == "down" {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func (db testDB) MigrationVersion(ctx context.Context) (uint, bool, error) { return 0, false, nil }
+
+func (db testDB) Close() error { return nil }
+
+func (db testDB) RecordTransaction(ctx context.Context, tx database.Transaction) error {
+	if db.transactions != nil {
+		tx.ID = strconv.Itoa(len(db.transactions[tx.UserID]) + 1)
+		tx.CreatedAt = time.Now()
+		db.transactions[tx.UserID] = append(db.transactions[tx.UserID], tx)
+	}
+	return nil
+}
+
+func (db testDB) UserTransactions(ctx context.Context, userID string, types []string, limit int) ([]database.Transaction, error) {
+	transactions := []database.Transaction{}
+	recorded := db.transactions[userID]
+	for i := len(recorded) - 1; i >= 0 && len(transactions) < limit; i-- {
+		if slices.Contains(types, recorded[i].Type) {
+			transactions = append(transactions, recorded[i])
+		}
+	}
+	return transactions, nil
+}
+
+func (db testDB) RecordReferral(ctx context.Context, userID, referrerID string) error { return nil }
+
+func (db testDB) CreateUser(ctx context.Context, id, username, email string) (*database.User, error) {
+	for _, user := range db.users {
+		if user.Username == username {
+			return nil, database.ErrUsernameTaken
+		}
+	}
+	user := &database.User{ID: id, Username: username, Email: email, CreatedAt: time.Now(), Status: database.UserStatusActive}
+	db.users[id] = user
+	return user, nil
+}
+
+func (db testDB) GetUser(ctx context.Context, id string) (*database.User, error) {
+	if user, ok := db.users[id]; ok {
+		return user, nil
+	}
+	return nil, database.ErrUserNotFound
+}
+
+func (db testDB) UpdateLastSeen(ctx context.Context, id string) error { return nil }
+
+func (db testDB) GetUserStats(ctx context.Context, from, to time.Time) ([]database.UserStats, error) {
+	return []database.UserStats{{UserID: "user1", GamesPlayed: 3, TotalWagered: 30, TotalPayout: 45, BiggestWin: 20}}, nil
+}
+
+func (db testDB) UserBetStats(ctx context.Context, userID string, from, to time.Time) (*database.UserStats, error) {
+	stats := &database.UserStats{UserID: userID}
+	for _, bets := range db.bets {
+		for _, bet := range bets {
+			if bet.UserID == userID && !bet.PlacedAt.Before(from) && bet.PlacedAt.Before(to) {
+				stats.GamesPlayed++
+				stats.TotalWagered += bet.Amount
+				stats.TotalPayout += bet.Payout
+			}
+		}
+	}
+	return stats, nil
+}
+
+func (db testDB) SaveRound(ctx context.Context, round database.Round) error {
+	if db.rounds != nil {
+		db.rounds[round.ID] = round
+	}
+	return nil
+}
+
+func (db testDB) GetRound(ctx context.Context, id string) (*database.Round, error) {
+	if round, ok := db.rounds[id]; ok {
+		return &round, nil
+	}
+	return nil, database.ErrRoundNotFound
+}
+
+func (db testDB) RecentCrashPoints(ctx context.Context, limit int) ([]float64, error) {
+	var crashPoints []float64
+	for _, round := range db.rounds {
+		if round.Status == "CRASHED" && len(crashPoints) < limit {
+			crashPoints = append(crashPoints, round.CrashMultiplier+round.BonusMultiplier)
+		}
+	}
+	return crashPoints, nil
+}
+
+func (db testDB) RecentBaseCrashPoints(ctx context.Context, limit int) ([]float64, error) {
+	var crashPoints []float64
+	for _, round := range db.rounds {
+		if round.Status == "CRASHED" && len(crashPoints) < limit {
+			crashPoints = append(crashPoints, round.CrashMultiplier)
+		}
+	}
+	return crashPoints, nil
+}
+
+func (db testDB) UserBalances(ctx context.Context) (map[string]float64, error) { return nil, nil }
+
+func (db testDB) LedgerBalances(ctx context.Context) (map[string]float64, error) { return nil, nil }
+
+func (db testDB) SetUserBalance(ctx context.Context, id string, balance float64) error { return nil }
+
+func (db testDB) CrashStats(ctx context.Context, since time.Time) (*database.CrashStats, error) {
+	stats := &database.CrashStats{Last10CrashPoints: []float64{}}
+	for _, round := range db.rounds {
+		if round.Status == "CRASHED" && !round.StartedAt.Before(since) {
+			stats.TotalRounds++
+			stats.HighestEver = max(stats.HighestEver, round.CrashMultiplier+round.BonusMultiplier)
+		}
+	}
+	return stats, nil
+}
+
+func (db testDB) RecordSettlement(ctx context.Context, settlement database.Settlement) error {
+	db.settlements[settlement.GameType] = append(db.settlements[settlement.GameType], settlement)
+	return nil
+}
+
+func (db testDB) HouseProfit(ctx context.Context, since time.Time) ([]database.GameProfit, error) {
+	var profits []database.GameProfit
+	for gameType, settlements := range db.settlements {
+		profit := database.GameProfit{GameType: gameType}
+		for _, settlement := range settlements {
+			if !settlement.SettledAt.Before(since) {
+				profit.Wagered += settlement.Wager
+				profit.PaidOut += settlement.Payout
+			}
+		}
+		profits = append(profits, profit)
+	}
+	return profits, nil
+}
+
+func (db testDB) SaveBets(ctx context.Context, bets []database.Bet) error {
+	for _, bet := range bets {
+		db.bets[bet.RoundID] = append(db.bets[bet.RoundID], bet)
+	}
+	return nil
+}
+
+func (db testDB) GetRoundBets(ctx context.Context, roundID string) ([]database.Bet, error) {
+	return db.bets[roundID], nil
+}
+
+func (db testDB) UserRecentBets(ctx context.Context, userID string, limit int) ([]database.Bet, error) {
+	var recent []database.Bet
+	for _, bets := range db.bets {
+		for _, bet := range bets {
+			if bet.UserID == userID {
+				recent = append(recent, bet)
+			}
+		}
+	}
+	slices.SortFunc(recent, func(a, b database.Bet) int { return b.PlacedAt.Compare(a.PlacedAt) })
+	return recent[:min(limit, len(recent))], nil
+}
+
+func (db testDB) ListUsers(ctx context.Context, status string, page, limit int) ([]database.User, error) {
+	users := []database.User{}
+	for _, user := range db.users {
+		if status == "" || user.Status == status {
+			users = append(users, *user)
+		}
+	}
+	return users, nil
+}
+
+func (db testDB) AllUsers(ctx context.Context, status string) ([]database.User, error) {
+	return db.ListUsers(ctx, status, 1, len(db.users))
+}
+
+func newTestServer(t testing.TB) (*FiberServer, *redis.Client) {
+	t.Helper()
+
+	client, cleanup := testutil.NewTestRedis()
+	t.Cleanup(cleanup)
+
+	hub := testutil.NewTestHub()
+	manager := game.NewManager(hub, client, nil, nil)
+	factory := game.NewGameFactory(client, hub)
+	factory.RegisterEngine(game.NewMinesEngine(client, hub))
+	factory.RegisterEngine(game.NewPlinkoEngine(client, hub))
+	factory.RegisterEngine(game.NewDiceEngine(client, hub))
+
+	db := testDB{status: "up", users: make(map[string]*database.User), rounds: make(map[string]database.Round),
+		bets: make(map[string][]database.Bet), transactions: make(map[string][]database.Transaction),
+		settlements: make(map[string][]database.Settlement)}
+	s := &FiberServer{
+		App:         fiber.New(),
+		db:          db,
+		cache:       testCache{client: client},
+		gameManager: manager,
+		gameHub:     hub,
+		gameFactory: factory,
+		chat:        game.NewChatService(client, hub, nil),
+		betSlips:    game.NewBetSlipService(client, manager, factory),
+		interest:    game.NewBalanceInterestJob(client, hub, nil),
+		anomaly:     game.NewAnomalyDetector(client),
+		referrals:   game.NewReferralService(client, hub, testDB{}),
+		wallet:      game.NewWalletService(client, hub, db),
+		summaries:   game.NewWeeklySummaryJob(client, testDB{}, notifications.LogNotifier{}),
+		adminAPIKey: testAdminKey,
+	}
+	s.App.Use(newCompressor())
+	s.App.Use(bodyLimit(routeBodyLimits))
+	s.App.Use(VersionNegotiator())
+	s.RegisterGameRoutes()
+	s.RegisterAdminRoutes()
+
+	return s, client
+}
+
+func postJSON(t *testing.T, app *fiber.App, path string, body interface{}) map[string]interface{} {
+	t.Helper()
+
+	data, _ := json.Marshal(body)
+	req, _ := http.NewRequest("POST", path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("could not perform request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var result map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("could not decode response: %v", err)
+	}
+	return result
+}
+
+func TestMinesSubscription_ReceivesUpdateOnClick(t *testing.T) {
+	s, client := newTestServer(t)
+	client.Set(t.Context(), game.REDIS_KEY_USER_BALANCE+"user1", 1000.0, 0)
+
+	bet := postJSON(t, s.App, "/api/v1/mines/bet", game.MinesBetRequest{
+		UserID:    "user1",
+		Amount:    10,
+		MineCount: 3,
+	})
+	gameID, _ := bet["game_id"].(string)
+	if gameID == "" {
+		t.Fatalf("expected game_id in bet response, got %v", bet)
+	}
+
+	conn := &mockConn{}
+	s.gameHub.RegisterClient(conn, "spectator")
+	deadline := time.Now().Add(time.Second)
+	for !s.gameHub.SubscribeToGame(conn, gameID) {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out subscribing client")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	postJSON(t, s.App, "/api/v1/mines/click", game.MinesClickRequest{
+		UserID: "user1",
+		GameID: gameID,
+		TileID: 0,
+	})
+
+	msg := conn.next(t)
+	if msg["type"] != "mines_update" {
+		t.Errorf("expected type mines_update, got %v", msg["type"])
+	}
+	if msg["game_id"] != gameID {
+		t.Errorf("expected game_id %s, got %v", gameID, msg["game_id"])
+	}
+	if _, ok := msg["status"]; !ok {
+		t.Error("expected status in update")
+	}
+	if _, ok := msg["revealed_tiles"]; !ok {
+		t.Error("expected revealed_tiles in update")
+	}
+}
+
+func TestConnectClient_MinesInitialState(t *testing.T) {
+	s, client := newTestServer(t)
+	client.Set(t.Context(), game.REDIS_KEY_USER_BALANCE+"user1", 1000.0, 0)
+
+	bet := postJSON(t, s.App, "/api/v1/mines/bet", game.MinesBetRequest{
+		UserID:    "user1",
+		Amount:    10,
+		MineCount: 3,
+	})
+	gameID, _ := bet["game_id"].(string)
+	if gameID == "" {
+		t.Fatalf("expected game_id in bet response, got %v", bet)
+	}
+
+	conn := &mockConn{}
+	s.connectClient(conn, "user1", game.GameTypeMines, parseGameTypes("mines"))
+
+	msg := conn.next(t)
+	if msg["type"] != "initial_state" {
+		t.Fatalf("expected type initial_state, got %v", msg["type"])
+	}
+	if _, ok := msg["crash_state"]; ok {
+		t.Error("crash_state should only be sent to aviator clients")
+	}
+	if _, ok := msg["connected_at"]; !ok {
+		t.Error("expected connected_at in initial state")
+	}
+	activeGame, ok := msg["active_mines_game"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected active_mines_game in initial state, got %v", msg)
+	}
+	if activeGame["game_id"] != gameID || activeGame["status"] != "ACTIVE" {
+		t.Errorf("active_mines_game = %v, want active game %s", activeGame, gameID)
+	}
+	if _, ok := activeGame["mine_positions"]; ok {
+		t.Error("active_mines_game should not reveal mine positions")
+	}
+
+	// The connection is subscribed to the active game without asking
+	postJSON(t, s.App, "/api/v1/mines/click", game.MinesClickRequest{
+		UserID: "user1",
+		GameID: gameID,
+		TileID: 0,
+	})
+	if msg := conn.next(t); msg["type"] != "mines_update" || msg["game_id"] != gameID {
+		t.Errorf("expected mines_update for %s, got %v", gameID, msg)
+	}
+}
+
+func TestConnectClient_NoActiveMinesGame(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	conn := &mockConn{}
+	s.connectClient(conn, "idle", game.GameTypeAviator, parseGameTypes("aviator, mines"))
+
+	msg := conn.next(t)
+	if msg["type"] != "initial_state" {
+		t.Fatalf("expected type initial_state, got %v", msg["type"])
+	}
+	if _, ok := msg["active_mines_game"]; ok {
+		t.Errorf("expected no active_mines_game for a user without one, got %v", msg["active_mines_game"])
+	}
+}
+
+func TestPreferencesHandlers(t *testing.T) {
+	s, _ := newTestServer(t)
+	body := []byte(`{"aviator":{"default_amount":100,"default_auto_cashout":2.0},"mines":{"default_mine_count":5,"default_amount":50},` +
+		`"plinko":{"default_risk":"medium","default_rows":16,"default_amount":25},"dice":{"default_target":50,"default_is_over":true}}`)
+
+	if status, result := postRaw(t, s, "/api/v1/users/user1/preferences", body); status != http.StatusOK {
+		t.Fatalf("POST preferences = %d %v, want 200", status, result)
+	}
+	status, result := postRaw(t, s, "/api/v1/users/user1/preferences", []byte(`{"aviator":{"default_auto_cashout":1.0}}`))
+	if status != http.StatusBadRequest || !strings.Contains(fmt.Sprint(result["error"]), "1.01") {
+		t.Errorf("POST invalid preferences = %d %v, want 400", status, result)
+	}
+
+	req, _ := http.NewRequest("GET", "/api/v1/users/user1/preferences", nil)
+	resp, err := s.App.Test(req)
+	if err != nil {
+		t.Fatalf("could not perform request: %v", err)
+	}
+	var got, want map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&got)
+	json.Unmarshal(body, &want)
+	if resp.StatusCode != http.StatusOK || !reflect.DeepEqual(got, want) {
+		t.Errorf("GET preferences = %d %v, want %v", resp.StatusCode, got, want)
+	}
+
+	conn := &mockConn{}
+	s.connectClient(conn, "user1", game.GameTypeAviator, parseGameTypes("aviator"))
+	msg := conn.next(t)
+	if !reflect.DeepEqual(msg["preferences"], want) {
+		t.Errorf("initial_state preferences = %v, want %v", msg["preferences"], want)
+	}
+
+	conn = &mockConn{}
+	s.connectClient(conn, "anonymous", game.GameTypeAviator, parseGameTypes("aviator"))
+	if msg := conn.next(t); msg["preferences"] != nil {
+		t.Errorf("anonymous initial_state has preferences %v", msg["preferences"])
+	}
+}
+
+func TestInitialStateHandler(t *testing.T) {
+	s, client := newTestServer(t)
+
+	fetch := func(etag string) *http.Response {
+		req, _ := http.NewRequest("GET", "/api/v1/game/initial-state?user_id=user1&games=aviator,mines", nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		resp, err := s.App.Test(req, -1)
+		if err != nil {
+			t.Fatalf("could not perform request: %v", err)
+		}
+		return resp
+	}
+
+	s.gameManager.Start()
+	t.Cleanup(s.gameManager.Stop)
+	var round *game.RoundState
+	for deadline := time.Now().Add(time.Second); round == nil && time.Now().Before(deadline); {
+		round = s.gameManager.GetCurrentRound()
+		time.Sleep(5 * time.Millisecond)
+	}
+	if round == nil {
+		t.Fatal("timed out waiting for a round to start")
+	}
+
+	resp := fetch("")
+	etag := resp.Header.Get("ETag")
+	if resp.StatusCode != http.StatusOK || etag == "" {
+		t.Fatalf("expected status OK with an ETag; got %v %q", resp.StatusCode, etag)
+	}
+	var state map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&state)
+	crashState, _ := state["crash_state"].(map[string]interface{})
+	if state["type"] != "initial_state" || crashState["round_id"] != round.RoundID {
+		t.Errorf("initial state = %v, want the crash state of %s", state, round.RoundID)
+	}
+	if client.Exists(t.Context(), REDIS_KEY_INITIAL_STATE+strings.Trim(etag, `"`)).Val() != 1 {
+		t.Error("expected the response to be cached")
+	}
+
+	if resp := fetch(etag); resp.StatusCode != http.StatusNotModified {
+		t.Errorf("unchanged round: expected status 304; got %v", resp.StatusCode)
+	}
+
+	// Wait for the round to leave the betting phase and tick
+	for deadline := time.Now().Add(game.BETTING_TIME + 2*time.Second); time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+		if current := s.gameManager.GetCurrentRound(); current.CurrentMultiplier > game.MIN_MULTIPLIER {
+			break
+		}
+	}
+
+	resp = fetch(etag)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("ETag") == etag {
+		t.Errorf("after a tick: expected status OK with a new ETag; got %v %q", resp.StatusCode, resp.Header.Get("ETag"))
+	}
+}
+
+func TestChatGameTypeValidation(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	for _, path := range []string{"/api/v1/game/initial-state", "/api/v1/game/stream"} {
+		req, _ := http.NewRequest("GET", path+"?user_id=user1&game_type=roulette", nil)
+		resp, err := s.App.Test(req)
+		if err != nil {
+			t.Fatalf("could not perform request: %v", err)
+		}
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s with an unknown game_type: expected status 400; got %v", path, resp.StatusCode)
+		}
+	}
+}
+
+func TestMinesGameStateHandler(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	req, _ := http.NewRequest("GET", "/api/v1/mines/game/MINES-missing/state", nil)
+	resp, err := s.App.Test(req)
+	if err != nil {
+		t.Fatalf("could not perform request: %v", err)
+	}
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status 404; got %v", resp.StatusCode)
+	}
+}
+
+func TestMinesLeaderboardHandler(t *testing.T) {
+	s, client := newTestServer(t)
+	client.ZAdd(t.Context(), game.REDIS_KEY_MINES_TILES_LEADERBOARD, redis.Z{Score: 12, Member: "user1"})
+	client.HSet(t.Context(), game.REDIS_KEY_MINES_TILES_RECORD+"user1", "mine_count", 3, "payout", 4210)
+
+	req, _ := http.NewRequest("GET", "/api/v1/mines/leaderboard/tiles?limit=5", nil)
+	resp, err := s.App.Test(req)
+	if err != nil {
+		t.Fatalf("could not perform request: %v", err)
+	}
+	var entries []game.MinesLeaderboardEntry
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		t.Fatalf("could not decode response: %v", err)
+	}
+	if len(entries) != 1 || entries[0].MaxTilesRevealed != 12 || entries[0].MineCount != 3 || entries[0].Payout.Float64() != 42.10 {
+		t.Errorf("leaderboard = %+v, want user1 with 12 tiles", entries)
+	}
+
+	for path, want := range map[string]int{
+		"/api/v1/mines/leaderboard/multiplier":       http.StatusOK,
+		"/api/v1/mines/leaderboard/profit":           http.StatusNotFound,
+		"/api/v1/mines/leaderboard/tiles?limit=1000": http.StatusBadRequest,
+	} {
+		req, _ := http.NewRequest("GET", path, nil)
+		resp, err := s.App.Test(req)
+		if err != nil {
+			t.Fatalf("could not perform request: %v", err)
+		}
+		if resp.StatusCode != want {
+			t.Errorf("GET %s status = %d, want %d", path, resp.StatusCode, want)
+		}
+	}
+}
+
+func TestDiceHistoryHandler(t *testing.T) {
+	s, client := newTestServer(t)
+	client.Set(t.Context(), game.REDIS_KEY_USER_BALANCE+"user1", 100.0, 0)
+
+	var gameIDs []string
+	for i := 0; i < 5; i++ {
+		result := postJSON(t, s.App, "/api/v1/dice/roll", map[string]interface{}{
+			"user_id": "user1", "amount": 1, "target": 50, "is_over": i%2 == 0,
+		})
+		gameIDs = append(gameIDs, result["game_id"].(string))
+	}
+
+	getHistory := func(path string) (int, []game.DiceHistoryEntry) {
+		req, _ := http.NewRequest("GET", path, nil)
+		resp, err := s.App.Test(req)
+		if err != nil {
+			t.Fatalf("could not perform request: %v", err)
+		}
+		var body struct {
+			Games []game.DiceHistoryEntry `json:"games"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		return resp.StatusCode, body.Games
+	}
+
+	status, games := getHistory("/api/v1/dice/history/user1")
+	if status != http.StatusOK || len(games) != 5 {
+		t.Fatalf("history = %d games (status %d), want 5", len(games), status)
+	}
+	for i, entry := range games {
+		if want := gameIDs[4-i]; entry.GameID != want {
+			t.Errorf("games[%d] = %s, want %s", i, entry.GameID, want)
+		}
+		if i > 0 && entry.CreatedAt.After(games[i-1].CreatedAt) {
+			t.Errorf("games[%d] is newer than the game before it", i)
+		}
+	}
+	if games[0].IsOver != true || games[0].Target != 50 || games[0].Mode != game.DiceModeOverUnder {
+		t.Errorf("latest game = %+v, want an over 50 roll", games[0])
+	}
+
+	if _, games := getHistory("/api/v1/dice/history/user1?page=2&limit=2"); len(games) != 2 || games[0].GameID != gameIDs[2] {
+		t.Errorf("page 2 = %+v, want games 3 and 2", games)
+	}
+	if status, _ := getHistory("/api/v1/dice/history/user1?limit=500"); status != http.StatusBadRequest {
+		t.Errorf("limit 500 status = %d, want 400", status)
+	}
+}
+
+func TestMinesHistoryHandler(t *testing.T) {
+	s, client := newTestServer(t)
+	client.Set(t.Context(), game.REDIS_KEY_USER_BALANCE+"user1", 100.0, 0)
+
+	// With 24 mines on 25 tiles, clicking in order busts within two clicks
+	busted := postJSON(t, s.App, "/api/v1/mines/bet", game.MinesBetRequest{UserID: "user1", Amount: 1, MineCount: 24})
+	for tile := 0; tile < 2; tile++ {
+		click := postJSON(t, s.App, "/api/v1/mines/click", game.MinesClickRequest{UserID: "user1", GameID: busted["game_id"].(string), TileID: tile})
+		if click["is_mine"] == true {
+			break
+		}
+	}
+	active := postJSON(t, s.App, "/api/v1/mines/bet", game.MinesBetRequest{UserID: "user1", Amount: 1, MineCount: 5, GridSize: 16})
+
+	req, _ := http.NewRequest("GET", "/api/v1/mines/history/user1", nil)
+	resp, err := s.App.Test(req)
+	if err != nil {
+		t.Fatalf("could not perform request: %v", err)
+	}
+	var body struct {
+		Games []map[string]interface{} `json:"games"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+	if resp.StatusCode != http.StatusOK || len(body.Games) != 2 {
+		t.Fatalf("history = %d games (status %d), want 2", len(body.Games), resp.StatusCode)
+	}
+
+	activeEntry, completedEntry := body.Games[0], body.Games[1]
+	if activeEntry["game_id"] != active["game_id"] || activeEntry["status"] != "ACTIVE" || activeEntry["grid_size"] != 16.0 {
+		t.Errorf("games[0] = %v, want the active game on 16 tiles", activeEntry)
+	}
+	for _, hidden := range []string{"mine_positions", "server_seed"} {
+		if _, ok := activeEntry[hidden]; ok {
+			t.Errorf("active game reveals %s", hidden)
+		}
+	}
+
+	if completedEntry["game_id"] != busted["game_id"] || completedEntry["status"] != "BUSTED" {
+		t.Errorf("games[1] = %v, want the busted game", completedEntry)
+	}
+	if positions, _ := completedEntry["mine_positions"].([]interface{}); len(positions) != 24 {
+		t.Errorf("completed game mine_positions = %v, want 24 positions", completedEntry["mine_positions"])
+	}
+	if seed, _ := completedEntry["server_seed"].(string); len(seed) != 64 {
+		t.Errorf("completed game server_seed = %q, want the revealed seed", seed)
+	}
+}
+
+func TestPlinkoHistoryHandler(t *testing.T) {
+	s, client := newTestServer(t)
+	client.Set(t.Context(), game.REDIS_KEY_USER_BALANCE+"user1", 100.0, 0)
+
+	risks := []game.PlinkoRisk{game.PlinkoRiskLow, game.PlinkoRiskHigh}
+	var gameIDs []string
+	for i := 0; i < 10; i++ {
+		result := postJSON(t, s.App, "/api/v1/plinko/drop", game.PlinkoDropRequest{
+			UserID: "user1", Amount: 1, Risk: risks[i%2], Rows: 8,
+		})
+		gameIDs = append(gameIDs, result["game_id"].(string))
+	}
+
+	req, _ := http.NewRequest("GET", "/api/v1/plinko/history/user1?limit=10", nil)
+	resp, err := s.App.Test(req)
+	if err != nil {
+		t.Fatalf("could not perform request: %v", err)
+	}
+	var body struct {
+		Games []map[string]interface{} `json:"games"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+	if resp.StatusCode != http.StatusOK || len(body.Games) != 10 {
+		t.Fatalf("history = %d games (status %d), want 10", len(body.Games), resp.StatusCode)
+	}
+	for i, entry := range body.Games {
+		if want := gameIDs[9-i]; entry["game_id"] != want {
+			t.Errorf("games[%d] = %v, want %s", i, entry["game_id"], want)
+		}
+		if entry["path_length"] != 8.0 {
+			t.Errorf("games[%d] path_length = %v, want 8", i, entry["path_length"])
+		}
+		if _, ok := entry["path"]; ok {
+			t.Errorf("games[%d] should not include the full path", i)
+		}
+	}
+
+	req, _ = http.NewRequest("GET", "/api/v1/plinko/stats/user1", nil)
+	resp, err = s.App.Test(req)
+	if err != nil {
+		t.Fatalf("could not perform request: %v", err)
+	}
+	var stats game.PlinkoStats
+	json.NewDecoder(resp.Body).Decode(&stats)
+	if stats.TotalDrops != 10 {
+		t.Errorf("total_drops = %d, want 10", stats.TotalDrops)
+	}
+	if stats.ByRisk[game.PlinkoRiskLow].TotalDrops != 5 || stats.ByRisk[game.PlinkoRiskHigh].TotalDrops != 5 {
+		t.Errorf("by_risk = %+v, want 5 low and 5 high drops", stats.ByRisk)
+	}
+	if stats.BestMultiplier < stats.AvgMultiplier || stats.WorstMultiplier > stats.AvgMultiplier {
+		t.Errorf("stats = %+v, want worst <= avg <= best", stats.PlinkoDropStats)
+	}
+}
+
+func TestGameRTPHandler(t *testing.T) {
+	s, client := newTestServer(t)
+
+	t.Run("computes and caches RTP", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/games/aviator/rtp", nil)
+		resp, err := s.App.Test(req)
+		if err != nil {
+			t.Fatalf("could not perform request: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected status OK; got %v", resp.StatusCode)
+		}
+
+		var info game.RTPInfo
+		if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+			t.Fatalf("could not decode response: %v", err)
+		}
+		if info.RTPPct != (1-game.HOUSE_EDGE)*100 {
+			t.Errorf("expected rtp_pct %v, got %v", (1-game.HOUSE_EDGE)*100, info.RTPPct)
+		}
+
+		ttl := client.TTL(t.Context(), game.REDIS_KEY_RTP+"aviator").Val()
+		if ttl <= 0 || ttl > game.RTP_CACHE_TTL {
+			t.Errorf("expected cached RTP with TTL <= %v, got %v", game.RTP_CACHE_TTL, ttl)
+		}
+	})
+
+	t.Run("unknown game type", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/games/roulette/rtp", nil)
+		resp, err := s.App.Test(req)
+		if err != nil {
+			t.Fatalf("could not perform request: %v", err)
+		}
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("expected status 404; got %v", resp.StatusCode)
+		}
+	})
+}
+
+func TestPlinkoConfigHandler(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	req, _ := http.NewRequest("GET", "/api/v1/plinko/config", nil)
+	resp, err := s.App.Test(req)
+	if err != nil {
+		t.Fatalf("could not perform request: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status OK; got %v", resp.StatusCode)
+	}
+
+	var body struct {
+		Configs []game.EVResult `json:"configs"`
+		Alerts  []string        `json:"alerts"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("could not decode response: %v", err)
+	}
+	if len(body.Configs) != 9 {
+		t.Fatalf("got %d configs, want 9", len(body.Configs))
+	}
+
+	overOne := 0
+	for _, config := range body.Configs {
+		if len(config.Multipliers) != config.Rows+1 || len(config.Probabilities) != config.Rows+1 {
+			t.Errorf("%s/%d: %d multipliers and %d probabilities, want %d", config.Risk, config.Rows,
+				len(config.Multipliers), len(config.Probabilities), config.Rows+1)
+		}
+		if config.ExpectedValue > 1.0 {
+			overOne++
+		}
+	}
+	if len(body.Alerts) != overOne {
+		t.Errorf("got %d alerts for %d configs with EV above 1: %v", len(body.Alerts), overOne, body.Alerts)
+	}
+}
+
+func TestNotableCashoutsHandler(t *testing.T) {
+	s, client := newTestServer(t)
+
+	entry, _ := json.Marshal(game.NotableCashout{UserIDMasked: "abc**xyz", Amount: 1250, Multiplier: 12.5, GameType: game.GameTypeAviator})
+	client.LPush(t.Context(), game.REDIS_KEY_NOTABLE_CASHOUTS, entry)
+
+	req, _ := http.NewRequest("GET", "/api/v1/leaderboard/notable", nil)
+	resp, err := s.App.Test(req)
+	if err != nil {
+		t.Fatalf("could not perform request: %v", err)
+	}
+	var body struct {
+		Cashouts []game.NotableCashout `json:"cashouts"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+	if resp.StatusCode != http.StatusOK || len(body.Cashouts) != 1 {
+		t.Fatalf("notable = %+v (status %d), want one cashout", body, resp.StatusCode)
+	}
+	if got := body.Cashouts[0]; got.UserIDMasked != "abc**xyz" || got.Amount != 1250 || got.GameType != game.GameTypeAviator {
+		t.Errorf("cashout = %+v", got)
+	}
+}
+
+func TestFairCommitmentsHandler(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	for path, want := range map[string]int{
+		"/api/v1/fair/commitments":            http.StatusOK,
+		"/api/v1/fair/commitments?limit=10":   http.StatusOK,
+		"/api/v1/fair/commitments?limit=0":    http.StatusBadRequest,
+		"/api/v1/fair/commitments?limit=5000": http.StatusBadRequest,
+	} {
+		req, _ := http.NewRequest("GET", path, nil)
+		resp, err := s.App.Test(req)
+		if err != nil {
+			t.Fatalf("could not perform request: %v", err)
+		}
+		if resp.StatusCode != want {
+			t.Errorf("GET %s status = %d, want %d", path, resp.StatusCode, want)
+		}
+	}
+}
+
+func TestFairCommitmentHandlers(t *testing.T) {
+	t.Setenv("OPERATOR_SECRET", "operator-secret")
+	s, client := newTestServer(t)
+
+	publishedAt := time.Now().Add(-time.Second)
+	commitment := game.HashCommitment("seed")
+	record := game.SignedCommitment{
+		RoundID:      "R-signed",
+		Commitment:   commitment,
+		Signature:    game.SignCommitment("operator-secret", commitment, "R-signed", publishedAt),
+		PublishedAt:  publishedAt,
+		BetsOpenedAt: time.Now(),
+	}
+	data, _ := json.Marshal(record)
+	client.Set(context.Background(), game.REDIS_KEY_SIGNED_COMMITMENT+"R-signed", data, 0)
+
+	req, _ := http.NewRequest("GET", "/api/v1/fair/commitment/R-signed", nil)
+	resp, err := s.App.Test(req)
+	if err != nil {
+		t.Fatalf("could not perform request: %v", err)
+	}
+	var loaded game.SignedCommitment
+	if err := json.NewDecoder(resp.Body).Decode(&loaded); err != nil {
+		t.Fatalf("could not decode response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || loaded.Signature != record.Signature || loaded.Commitment != commitment {
+		t.Errorf("GET commitment = %d %+v, want the stored record", resp.StatusCode, loaded)
+	}
+
+	req, _ = http.NewRequest("GET", "/api/v1/fair/commitment/R-missing", nil)
+	if resp, _ := s.App.Test(req); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("GET commitment of an unknown round status = %d, want 404", resp.StatusCode)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		body   map[string]interface{}
+		status int
+		valid  bool
+	}{
+		{"valid", map[string]interface{}{"round_id": "R-signed", "commitment": commitment, "signature": record.Signature}, http.StatusOK, true},
+		{"tampered commitment", map[string]interface{}{"round_id": "R-signed", "commitment": game.HashCommitment("other"), "signature": record.Signature}, http.StatusOK, false},
+		{"unknown round", map[string]interface{}{"round_id": "R-missing", "commitment": commitment, "signature": record.Signature}, http.StatusOK, false},
+		{"missing signature", map[string]interface{}{"round_id": "R-signed", "commitment": commitment}, http.StatusBadRequest, false},
+	} {
+		body, _ := json.Marshal(tt.body)
+		status, result := postRaw(t, s, "/api/v1/fair/commitment/verify", body)
+		if status != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.name, status, tt.status)
+			continue
+		}
+		if tt.status == http.StatusOK && (result["valid"] != tt.valid || result["published_before_bets"] != tt.valid) {
+			t.Errorf("%s: result = %v, want valid and published_before_bets %v", tt.name, result, tt.valid)
+		}
+	}
+}
+
+func TestPlinkoRevealHandler(t *testing.T) {
+	s, client := newTestServer(t)
+	client.Set(t.Context(), game.REDIS_KEY_USER_BALANCE+"user1", 100.0, 0)
+
+	drop := postJSON(t, s.App, "/api/v1/plinko/drop", game.PlinkoDropRequest{UserID: "user1", Amount: 1, Risk: game.PlinkoRiskMedium, Rows: 12, Mode: game.PlinkoModeDeferred})
+	gameID, _ := drop["game_id"].(string)
+	if gameID == "" || drop["server_seed_hash"] == nil || drop["path"] != nil {
+		t.Fatalf("deferred drop = %v, want a game ID and seed commitment without a path", drop)
+	}
+
+	revealStatus := func(query string) int {
+		req, _ := http.NewRequest("POST", "/api/v1/plinko/game/"+gameID+"/reveal"+query, nil)
+		resp, err := s.App.Test(req)
+		if err != nil {
+			t.Fatalf("could not perform request: %v", err)
+		}
+		return resp.StatusCode
+	}
+	if status := revealStatus(""); status != http.StatusBadRequest {
+		t.Errorf("reveal without a user: status %d, want 400", status)
+	}
+	if status := revealStatus("?user_id=user2"); status != http.StatusForbidden {
+		t.Errorf("reveal by another user: status %d, want 403", status)
+	}
+
+	reveal := postJSON(t, s.App, "/api/v1/plinko/game/"+gameID+"/reveal?user_id=user1", nil)
+	path, _ := reveal["path"].([]interface{})
+	if reveal["success"] != true || len(path) != 12 || reveal["server_seed"] == nil {
+		t.Errorf("reveal = %v, want the 12 row result", reveal)
+	}
+
+	if status := revealStatus("?user_id=user1"); status != http.StatusNotFound {
+		t.Errorf("second reveal: status %d, want 404", status)
+	}
+}
+
+func TestPlinkoVerifyHandler(t *testing.T) {
+	s, client := newTestServer(t)
+	client.Set(t.Context(), game.REDIS_KEY_USER_BALANCE+"user1", 100.0, 0)
+
+	drop := postJSON(t, s.App, "/api/v1/plinko/drop", game.PlinkoDropRequest{UserID: "user1", Amount: 1, Risk: game.PlinkoRiskMedium, Rows: 12})
+	gameID := drop["game_id"].(string)
+
+	verify := func(query string) (int, game.PlinkoVerification) {
+		t.Helper()
+		req, _ := http.NewRequest("GET", "/api/v1/plinko/game/"+gameID+"/verify"+query, nil)
+		resp, err := s.App.Test(req)
+		if err != nil {
+			t.Fatalf("could not perform request: %v", err)
+		}
+		defer resp.Body.Close()
+		var verification game.PlinkoVerification
+		json.NewDecoder(resp.Body).Decode(&verification)
+		return resp.StatusCode, verification
+	}
+
+	status, verification := verify("")
+	if status != http.StatusOK || !verification.Valid || !slices.Equal(verification.ComputedPath, verification.StoredPath) ||
+		fmt.Sprint(verification.StoredPath) != fmt.Sprint(drop["path"]) || !verification.MultiplierMatches || !verification.SignatureValid {
+		t.Errorf("verify = %d %+v, want the dropped path replayed with a valid signature", status, verification)
+	}
+	if verification.StepByStep != nil {
+		t.Errorf("verify without verbose = %+v, want no steps", verification.StepByStep)
+	}
+	if _, verbose := verify("?verbose=true"); len(verbose.StepByStep) != 12 || verbose.GameSeed == "" {
+		t.Errorf("verbose verify = %+v, want 12 steps", verbose)
+	}
+
+	// A stored game edited after the drop no longer matches its signature
+	key := game.REDIS_KEY_PLINKO_GAME + gameID
+	var stored map[string]interface{}
+	json.Unmarshal([]byte(client.Get(t.Context(), key).Val()), &stored)
+	stored["payout"] = 1000
+	edited, _ := json.Marshal(stored)
+	client.Set(t.Context(), key, edited, time.Hour)
+	if _, verification := verify(""); !verification.Valid || verification.SignatureValid {
+		t.Errorf("verify of an edited game = %+v, want its signature invalid", verification)
+	}
+
+	gameID = "PLINKO-unknown"
+	if status, _ := verify(""); status != http.StatusNotFound {
+		t.Errorf("verify of an unknown game = %d, want 404", status)
+	}
+	gameID = fmt.Sprintf("PLINKO-user1-%d", time.Now().Add(-game.PLINKO_GAME_TTL-time.Minute).UnixNano())
+	if status, _ := verify(""); status != http.StatusGone {
+		t.Errorf("verify of an expired game = %d, want 410", status)
+	}
+}
+
+func TestActiveCountHandlers(t *testing.T) {
+	s, client := newTestServer(t)
+	client.Set(t.Context(), game.REDIS_KEY_USER_BALANCE+"user1", 1000.0, 0)
+
+	for _, mineCount := range []int{1, 3, 5} {
+		postJSON(t, s.App, "/api/v1/mines/bet", game.MinesBetRequest{UserID: "user1", Amount: 10, MineCount: mineCount})
+	}
+	postJSON(t, s.App, "/api/v1/dice/roll", map[string]interface{}{"user_id": "user1", "amount": 1, "target": 50, "is_over": true})
+	postJSON(t, s.App, "/api/v1/plinko/drop", game.PlinkoDropRequest{UserID: "user1", Amount: 1, Risk: game.PlinkoRiskLow, Rows: 8})
+
+	for path, want := range map[string]map[string]float64{
+		"/api/v1/mines/active-count":  {"active_games": 3, "avg_mine_count": 3, "total_wagered_active": 30, "tiles_clicked_last_minute": 0},
+		"/api/v1/dice/active-count":   {"rolls_last_minute": 1},
+		"/api/v1/plinko/active-count": {"balls_dropped_last_minute": 1},
+	} {
+		req, _ := http.NewRequest("GET", path, nil)
+		resp, err := s.App.Test(req)
+		if err != nil {
+			t.Fatalf("could not perform request: %v", err)
+		}
+		var stats map[string]float64
+		if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+			t.Fatalf("could not decode response: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK || fmt.Sprint(stats) != fmt.Sprint(want) {
+			t.Errorf("GET %s = %d %v, want %v", path, resp.StatusCode, stats, want)
+		}
+	}
+}
+
+func TestResultSignatures(t *testing.T) {
+	s, client := newTestServer(t)
+	client.Set(t.Context(), game.REDIS_KEY_USER_BALANCE+"user1", 1000.0, 0)
+	key := game.ResultSigningKey()
+
+	for path, body := range map[string]interface{}{
+		"/api/v1/mines/bet":   game.MinesBetRequest{UserID: "user1", Amount: 10, MineCount: 3},
+		"/api/v1/dice/roll":   map[string]interface{}{"user_id": "user1", "amount": 1, "target": 50, "is_over": true},
+		"/api/v1/plinko/drop": game.PlinkoDropRequest{UserID: "user1", Amount: 1, Risk: game.PlinkoRiskLow, Rows: 8},
+	} {
+		data, _ := json.Marshal(body)
+		req, _ := http.NewRequest("POST", path, bytes.NewReader(data))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := s.App.Test(req)
+		if err != nil {
+			t.Fatalf("could not perform request: %v", err)
+		}
+		raw, _ := io.ReadAll(resp.Body)
+
+		var result struct {
+			ResultSignature string `json:"result_signature"`
+		}
+		json.Unmarshal(raw, &result)
+		if result.ResultSignature == "" || !game.VerifyResultSignature(string(raw), result.ResultSignature, key) {
+			t.Errorf("POST %s response %s does not carry a valid signature", path, raw)
+		}
+	}
+
+	req, _ := http.NewRequest("GET", "/api/v1/fair/signing-key/public", nil)
+	resp, err := s.App.Test(req)
+	if err != nil {
+		t.Fatalf("could not perform request: %v", err)
+	}
+	raw, _ := io.ReadAll(resp.Body)
+	if strings.Contains(string(raw), key) {
+		t.Fatal("signing key endpoint must not reveal the key")
+	}
+	var published map[string]string
+	json.Unmarshal(raw, &published)
+	if published["key_hash"] != game.SigningKeyHash(key) || published["algorithm"] != "HMAC-SHA256" {
+		t.Errorf("signing key = %s, want the hash of the key", raw)
+	}
+}
+
+func TestGamesDiscoveryHandlers(t *testing.T) {
+	s, client := newTestServer(t)
+
+	getGames := func(t *testing.T, path string, out interface{}) int {
+		t.Helper()
+		req, _ := http.NewRequest("GET", path, nil)
+		resp, err := s.App.Test(req)
+		if err != nil {
+			t.Fatalf("could not perform request: %v", err)
+		}
+		defer resp.Body.Close()
+		if out != nil {
+			if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+				t.Fatalf("could not decode response: %v", err)
+			}
+		}
+		return resp.StatusCode
+	}
+
+	t.Run("lists all registered engines", func(t *testing.T) {
+		var games []game.GameMetadata
+		if status := getGames(t, "/api/v1/games", &games); status != http.StatusOK {
+			t.Fatalf("expected status OK; got %v", status)
+		}
+
+		found := make(map[game.GameType]game.GameMetadata)
+		for _, info := range games {
+			found[info.GameType] = info
+		}
+		for _, gameType := range []game.GameType{game.GameTypeMines, game.GameTypeDice, game.GameTypePlinko} {
+			info, ok := found[gameType]
+			if !ok {
+				t.Errorf("expected %s in games list", gameType)
+				continue
+			}
+			if info.Maintenance || info.Status != "available" {
+				t.Errorf("expected %s to be available, got %+v", gameType, info)
+			}
+			if len(info.Endpoints) == 0 || info.HouseEdgePct <= 0 {
+				t.Errorf("expected endpoints and house edge for %s, got %+v", gameType, info)
+			}
+		}
+	})
+
+	t.Run("disabled engine shows maintenance", func(t *testing.T) {
+		client.Set(t.Context(), game.REDIS_KEY_GAME_MAINTENANCE+"dice", "1", 0)
+		defer client.Del(t.Context(), game.REDIS_KEY_GAME_MAINTENANCE+"dice")
+
+		var info game.GameMetadata
+		if status := getGames(t, "/api/v1/games/dice", &info); status != http.StatusOK {
+			t.Fatalf("expected status OK; got %v", status)
+		}
+		if !info.Maintenance || info.Status != "maintenance" {
+			t.Errorf("expected dice under maintenance, got %+v", info)
+		}
+	})
+
+	t.Run("unknown game type", func(t *testing.T) {
+		if status := getGames(t, "/api/v1/games/roulette", nil); status != http.StatusNotFound {
+			t.Errorf("expected status 404; got %v", status)
+		}
+	})
+}
+
+func TestBetSlipHandlers(t *testing.T) {
+	s, client := newTestServer(t)
+	client.Set(t.Context(), game.REDIS_KEY_USER_BALANCE+"user1", 100.0, 0)
+
+	created := postJSON(t, s.App, "/api/v1/betslip", game.BetSlipRequest{
+		UserID: "user1",
+		Bets: []game.BetSlipBet{
+			{GameType: game.GameTypeDice, Amount: 10 * game.AMOUNT_SCALE, Params: json.RawMessage(`{"target": 50, "is_over": true}`)},
+		},
+	})
+	slipID, _ := created["slip_id"].(string)
+	if slipID == "" {
+		t.Fatalf("expected slip_id in response, got %v", created)
+	}
+
+	confirmed := postJSON(t, s.App, "/api/v1/betslip/"+slipID+"/confirm", nil)
+	if confirmed["success"] != true {
+		t.Errorf("expected confirmation to succeed, got %v", confirmed)
+	}
+
+	req, _ := http.NewRequest("POST", "/api/v1/betslip/"+slipID+"/confirm", nil)
+	resp, err := s.App.Test(req)
+	if err != nil {
+		t.Fatalf("could not perform request: %v", err)
+	}
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for a confirmed slip, got %d", resp.StatusCode)
+	}
+}
+
+func TestReferralHandlers(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	registered := postJSON(t, s.App, "/api/v1/users/register", map[string]string{"user_id": "friend", "referrer_id": "referrer"})
+	if registered["referrer_id"] != "referrer" {
+		t.Fatalf("expected registration to succeed, got %v", registered)
+	}
+	if again := postJSON(t, s.App, "/api/v1/users/register", map[string]string{"user_id": "friend", "referrer_id": "other"}); again["error"] == nil {
+		t.Errorf("expected a second referral to be rejected, got %v", again)
+	}
+
+	_, body := adminGet(t, s, "/api/v1/users/referrer/referrals", "")
+	var stats game.ReferralStats
+	if err := json.Unmarshal(body, &stats); err != nil {
+		t.Fatalf("could not decode referrals: %v", err)
+	}
+	if stats.TotalReferrals != 1 || stats.ReferralLinkCode == "" {
+		t.Fatalf("expected one referral and a code, got %+v", stats)
+	}
+
+	_, body = adminGet(t, s, "/api/v1/users/referral/"+stats.ReferralLinkCode, "")
+	if !strings.Contains(string(body), `"user_id":"referrer"`) {
+		t.Errorf("expected code to resolve to referrer, got %s", body)
+	}
+
+	resp, _ := adminGet(t, s, "/api/v1/users/referral/unknown", "")
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown code, got %d", resp.StatusCode)
+	}
+}
+
+func TestUserHandlers(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	created := postJSON(t, s.App, "/api/v1/users", map[string]string{"user_id": "user1", "username": "alice"})
+	if created["id"] != "user1" || created["status"] != database.UserStatusActive {
+		t.Fatalf("expected user1 to be created, got %v", created)
+	}
+	if taken := postJSON(t, s.App, "/api/v1/users", map[string]string{"user_id": "user2", "username": "alice"}); taken["error"] == nil {
+		t.Errorf("expected a duplicate username to be rejected, got %v", taken)
+	}
+
+	if set := postJSON(t, s.App, "/api/v1/user/user1/balance", map[string]float64{"balance": 50}); set["balance"] != 50.0 {
+		t.Errorf("expected balance to be set, got %v", set)
+	}
+	if missing := postJSON(t, s.App, "/api/v1/user/ghost/balance", map[string]float64{"balance": 50}); missing["error"] != "User not found" {
+		t.Errorf("expected unknown users to be rejected, got %v", missing)
+	}
+
+	_, body := adminGet(t, s, "/api/v1/user/user1/balance", "")
+	var balance map[string]interface{}
+	if err := json.Unmarshal(body, &balance); err != nil {
+		t.Fatalf("could not decode balance: %v", err)
+	}
+	if balance["username"] != "alice" || balance["balance"] != 50.0 {
+		t.Errorf("expected balance with profile, got %v", balance)
+	}
+
+	_, body = adminGet(t, s, "/api/v1/admin/users?status=active&page=1", testAdminKey)
+	if !strings.Contains(string(body), `"username":"alice"`) {
+		t.Errorf("expected alice in the admin user list, got %s", body)
+	}
+	resp, _ := adminGet(t, s, "/api/v1/admin/users?status=unknown", testAdminKey)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown status, got %d", resp.StatusCode)
+	}
+}
+
+func TestCurrentPromotionsHandler(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	req, _ := http.NewRequest("GET", "/api/v1/promotions/current", nil)
+	resp, err := s.App.Test(req)
+	if err != nil {
+		t.Fatalf("could not perform request: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status OK; got %v", resp.StatusCode)
+	}
+
+	var promotions game.Promotions
+	if err := json.NewDecoder(resp.Body).Decode(&promotions); err != nil {
+		t.Fatalf("could not decode response: %v", err)
+	}
+	// No schedule is configured in tests
+	if got := promotions.HouseEdges[game.GameTypeDice]; got != game.DICE_HOUSE_EDGE {
+		t.Errorf("dice house edge = %v, want %v", got, game.DICE_HOUSE_EDGE)
+	}
+	if promotions.NextChange != nil {
+		t.Errorf("expected no next change, got %+v", promotions.NextChange)
+	}
+}
+
+func TestRoundReplayHandler(t *testing.T) {
+	s, client := newTestServer(t)
+	ctx := context.Background()
+
+	crashedAt := time.Now()
+	s.db.SaveRound(ctx, database.Round{ID: "R-replay", CrashMultiplier: 2.5, Status: "CRASHED", CrashedAt: &crashedAt})
+	s.db.SaveRound(ctx, database.Round{ID: "R-running", CrashMultiplier: 2.5, Status: "RUNNING"})
+	s.db.SaveBets(ctx, []database.Bet{
+		{ID: "B1", UserID: "user1", RoundID: "R-replay", Amount: 10, CashoutMultiplier: 2, Payout: 20, Result: database.BetResultWin},
+		{ID: "B2", UserID: "user2", RoundID: "R-replay", Amount: 5, Result: database.BetResultLoss},
+	})
+
+	get := func(roundID string) (*http.Response, game.ReplayData) {
+		req, _ := http.NewRequest("GET", "/api/v1/rounds/"+roundID+"/replay", nil)
+		resp, err := s.App.Test(req)
+		if err != nil {
+			t.Fatalf("could not perform request: %v", err)
+		}
+		var replay game.ReplayData
+		json.NewDecoder(resp.Body).Decode(&replay)
+		return resp, replay
+	}
+
+	resp, replay := get("R-replay")
+	if resp.StatusCode != http.StatusOK || replay.CrashAt != 2.5 || len(replay.Bets) != 2 {
+		t.Fatalf("replay = %d %+v, want the crashed round with 2 bets", resp.StatusCode, replay)
+	}
+	if got := resp.Header.Get("X-Total-Duration-Ms"); got != strconv.FormatInt(replay.TotalDurationMs, 10) || replay.TotalDurationMs == 0 {
+		t.Errorf("X-Total-Duration-Ms = %q, want %d", got, replay.TotalDurationMs)
+	}
+	if bet := replay.Bets[0]; bet.UserIDMasked != "u***1" || !bet.Win || bet.CashoutAtMs == 0 {
+		t.Errorf("bets[0] = %+v, want user1's masked win", bet)
+	}
+	if ttl := client.TTL(ctx, game.REDIS_KEY_REPLAY+"R-replay").Val(); ttl <= 0 || ttl > game.REPLAY_CACHE_TTL {
+		t.Errorf("replay cache TTL = %v, want up to %v", ttl, game.REPLAY_CACHE_TTL)
+	}
+
+	// Later requests are served from the cache
+	delete(s.db.(testDB).rounds, "R-replay")
+	if resp, cached := get("R-replay"); resp.StatusCode != http.StatusOK || len(cached.Ticks) != len(replay.Ticks) {
+		t.Errorf("cached replay = %d with %d ticks, want %d ticks", resp.StatusCode, len(cached.Ticks), len(replay.Ticks))
+	}
+
+	if resp, _ := get("R-running"); resp.StatusCode != http.StatusConflict {
+		t.Errorf("replay of a running round status = %d, want 409", resp.StatusCode)
+	}
+	if resp, _ := get("R-missing"); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("replay of an unknown round status = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestVerifyRoundHandler(t *testing.T) {
+	t.Setenv("CRASH_SEED_REVEAL_DELAY_MS", "100")
+	s, _ := newTestServer(t)
+
+	serverSeed, clientSeed := game.GenerateSeed(), game.GenerateSeed()
+	crashedAt := time.Now()
+	s.db.SaveRound(context.Background(), database.Round{
+		ID:              "R-verify",
+		ServerSeed:      serverSeed,
+		HashCommitment:  game.CommitRoundSeed(serverSeed),
+		ClientSeed:      clientSeed,
+		Nonce:           7,
+		CrashMultiplier: game.HashAndMapToMultiplier(serverSeed, clientSeed, 7),
+		Status:          "CRASHED",
+		CrashedAt:       &crashedAt,
+	})
+
+	verify := func(roundID string) (int, map[string]interface{}) {
+		req, _ := http.NewRequest("GET", "/api/v1/rounds/verify/"+roundID, nil)
+		resp, err := s.App.Test(req)
+		if err != nil {
+			t.Fatalf("could not perform request: %v", err)
+		}
+		defer resp.Body.Close()
+		var body map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&body)
+		return resp.StatusCode, body
+	}
+
+	status, body := verify("R-verify")
+	if status != http.StatusOK || body["verified"] != nil || body["message"] != "Seed not yet revealed" {
+		t.Errorf("before the reveal got %d %v, want the seed withheld", status, body)
+	}
+	if _, leaked := body["server_seed"]; leaked {
+		t.Error("server seed returned before the reveal")
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	status, body = verify("R-verify")
+	if status != http.StatusOK || body["verified"] != true || body["commitment_matches"] != true {
+		t.Fatalf("after the reveal got %d %v, want a verified round", status, body)
+	}
+	if body["server_seed"] != serverSeed || body["computed_multiplier"] != body["claimed_multiplier"] {
+		t.Errorf("verification = %v, want the seed and matching multipliers", body)
+	}
+	if steps, _ := body["verification_steps"].([]interface{}); len(steps) == 0 {
+		t.Error("expected verification steps")
+	}
+
+	if status, _ := verify("R-missing"); status != http.StatusNotFound {
+		t.Errorf("unknown round status = %d, want 404", status)
+	}
+}
+
+func TestSideBetHandlers(t *testing.T) {
+	s, _ := newTestServer(t)
+	ctx := context.Background()
+
+	if resp := postJSON(t, s.App, "/api/v1/aviator/side-bet", game.SideBetRequest{Amount: 10, Prediction: game.SideBetOver10x}); resp["error"] == nil {
+		t.Errorf("side bet without a user ID = %v, want an error", resp)
+	}
+
+	getOdds := func() (sampleSize int, odds map[string]game.SideBetOdds) {
+		req, _ := http.NewRequest("GET", "/api/v1/aviator/side-bet-odds", nil)
+		resp, err := s.App.Test(req)
+		if err != nil {
+			t.Fatalf("could not perform request: %v", err)
+		}
+		var body struct {
+			SampleSize int                `json:"sample_size"`
+			Odds       []game.SideBetOdds `json:"odds"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		odds = make(map[string]game.SideBetOdds)
+		for _, o := range body.Odds {
+			odds[o.Prediction] = o
+		}
+		return body.SampleSize, odds
+	}
+
+	if size, odds := getOdds(); size != 0 || len(odds) != 4 || odds[game.SideBetUnder2x].Probability == 0 {
+		t.Errorf("odds without history = %d rounds %+v, want the theoretical odds", size, odds)
+	}
+
+	for i, crashPoint := range []float64{1.5, 3, 7, 12} {
+		s.db.SaveRound(ctx, database.Round{ID: fmt.Sprintf("R-%d", i), CrashMultiplier: crashPoint, Status: "CRASHED"})
+	}
+	s.db.SaveRound(ctx, database.Round{ID: "R-running", CrashMultiplier: 1.5, Status: "RUNNING"})
+
+	size, odds := getOdds()
+	if size != 4 {
+		t.Errorf("sample_size = %d, want the 4 crashed rounds", size)
+	}
+	if o := odds[game.SideBetOver10x]; o.Probability != 0.25 || o.PayoutMultiplier != 9.5 || o.ExpectedReturnPct != 237.5 {
+		t.Errorf("over_10x odds = %+v, want a quarter of the rounds", o)
+	}
+}
+
+func TestVerifyMinesHandler(t *testing.T) {
+	s, client := newTestServer(t)
+	client.Set(t.Context(), game.REDIS_KEY_USER_BALANCE+"user1", 100.0, 0)
+
+	// Bust a game so its history reveals the seeds and the mines they placed
+	bet := postJSON(t, s.App, "/api/v1/mines/bet", game.MinesBetRequest{UserID: "user1", Amount: 1, MineCount: 24})
+	for tile := 0; tile < 2; tile++ {
+		click := postJSON(t, s.App, "/api/v1/mines/click", game.MinesClickRequest{UserID: "user1", GameID: bet["game_id"].(string), TileID: tile})
+		if click["is_mine"] == true {
+			break
+		}
+	}
+	req, _ := http.NewRequest("GET", "/api/v1/mines/history/user1", nil)
+	resp, err := s.App.Test(req)
+	if err != nil {
+		t.Fatalf("could not perform request: %v", err)
+	}
+	var history struct {
+		Games []game.MinesHistoryEntry `json:"games"`
+	}
+	json.NewDecoder(resp.Body).Decode(&history)
+	if len(history.Games) != 1 || len(history.Games[0].MinePositions) != 24 {
+		t.Fatalf("history = %+v, want the busted game", history)
+	}
+	played := history.Games[0]
+
+	verify := func(claimed []int) map[string]interface{} {
+		return postJSON(t, s.App, "/api/v1/fair/verify/mines", fiber.Map{
+			"server_seed":       played.ServerSeed,
+			"client_seed":       played.ClientSeed,
+			"nonce":             played.Nonce,
+			"mine_count":        played.MineCount,
+			"claimed_positions": claimed,
+		})
+	}
+
+	result := verify(played.MinePositions)
+	if result["valid"] != true || result["match"] != true {
+		t.Errorf("verification of the played positions = %v, want valid", result)
+	}
+	if steps, _ := result["hmac_inputs"].([]interface{}); len(steps) != game.MINES_GRID_SIZE {
+		t.Errorf("hmac_inputs has %d steps, want %d", len(steps), game.MINES_GRID_SIZE)
+	}
+
+	tampered := append([]int{}, played.MinePositions[1:]...)
+	if result := verify(tampered); result["valid"] != false || result["match"] != false {
+		t.Errorf("verification of 23 of the 24 mines = %v, want invalid", result)
+	}
+
+	invalid := postJSON(t, s.App, "/api/v1/fair/verify/mines", fiber.Map{"server_seed": "seed", "mine_count": 25})
+	if invalid["error"] != "Mine count must be between 1 and 24" {
+		t.Errorf("verification with 25 mines = %v, want a mine count error", invalid)
+	}
+}
+
+func TestCrashOutcomesHandler(t *testing.T) {
+	s, client := newTestServer(t)
+
+	status, first := postRaw(t, s, "/api/v1/aviator/simulate", []byte(`{"simulations":1000}`))
+	if status != http.StatusOK || first["simulations"] != 1000.0 || len(first["distribution"].([]interface{})) != 6 {
+		t.Fatalf("expected 1000 simulations in 6 buckets, got %d %v", status, first)
+	}
+	if ttl := client.TTL(t.Context(), game.REDIS_KEY_CRASH_OUTCOMES+"1000").Val(); ttl <= 0 || ttl > game.CRASH_OUTCOMES_CACHE_TTL {
+		t.Errorf("expected the result cached for %v, got TTL %v", game.CRASH_OUTCOMES_CACHE_TTL, ttl)
+	}
+	if _, second := postRaw(t, s, "/api/v1/aviator/simulate", []byte(`{"simulations":1000}`)); !reflect.DeepEqual(first, second) {
+		t.Errorf("expected the cached result, got %v then %v", first, second)
+	}
+
+	for _, body := range []string{`{"simulations":0}`, `{"simulations":1000001}`, `{`} {
+		if status, _ := postRaw(t, s, "/api/v1/aviator/simulate", []byte(body)); status != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400; got %v", body, status)
+		}
+	}
+}
+
+func TestCrashDistributionHandler(t *testing.T) {
+	s, client := newTestServer(t)
+	ctx := context.Background()
+
+	for i := 0; i < 200; i++ {
+		s.db.SaveRound(ctx, database.Round{
+			ID:              fmt.Sprintf("R-%d", i),
+			CrashMultiplier: game.HashAndMapToMultiplier("server-seed", "client-seed", i),
+			BonusMultiplier: 10000,
+			Status:          "CRASHED",
+			StartedAt:       time.Now(),
+		})
+	}
+
+	get := func(query string) (int, map[string]interface{}) {
+		req, _ := http.NewRequest("GET", "/api/v1/crashes/distribution"+query, nil)
+		resp, err := s.App.Test(req)
+		if err != nil {
+			t.Fatalf("could not perform request: %v", err)
+		}
+		var body map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&body)
+		return resp.StatusCode, body
+	}
+
+	status, body := get("?rounds=150")
+	if status != http.StatusOK || body["sample_size"] != 150.0 || body["fairness_score"] == nil {
+		t.Fatalf("distribution = %d %v, want 150 rounds analyzed", status, body)
+	}
+	if body["max"].(float64) >= 10000 {
+		t.Errorf("max = %v, want bonus multipliers left out", body["max"])
+	}
+	if ttl := client.TTL(ctx, game.REDIS_KEY_CRASH_DISTRIBUTION+"150").Val(); ttl <= 0 || ttl > game.CRASH_DISTRIBUTION_CACHE_TTL {
+		t.Errorf("distribution cache TTL = %v, want up to %v", ttl, game.CRASH_DISTRIBUTION_CACHE_TTL)
+	}
+
+	if status, _ := get("?rounds=10001"); status != http.StatusBadRequest {
+		t.Errorf("10001 rounds status = %d, want 400", status)
+	}
+}
+
+func TestAviatorStatsHandler(t *testing.T) {
+	s, client := newTestServer(t)
+	ctx := context.Background()
+
+	s.db.SaveRound(ctx, database.Round{ID: "R-old", CrashMultiplier: 40, Status: "CRASHED", StartedAt: time.Now().Add(-48 * time.Hour)})
+	s.db.SaveRound(ctx, database.Round{ID: "R-new", CrashMultiplier: 3, BonusMultiplier: 0.5, Status: "CRASHED", StartedAt: time.Now()})
+
+	get := func(query string) (int, map[string]interface{}) {
+		req, _ := http.NewRequest("GET", "/api/v1/aviator/stats"+query, nil)
+		resp, err := s.App.Test(req)
+		if err != nil {
+			t.Fatalf("could not perform request: %v", err)
+		}
+		var body map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&body)
+		return resp.StatusCode, body
+	}
+
+	if status, body := get(""); status != http.StatusOK || body["total_rounds"] != 2.0 || body["highest_ever"] != 40.0 || body["current_round_id"] != "" {
+		t.Errorf("stats = %d %v, want both rounds", status, body)
+	}
+	if status, body := get("?period=24h"); status != http.StatusOK || body["total_rounds"] != 1.0 || body["highest_ever"] != 3.5 {
+		t.Errorf("24h stats = %d %v, want the recent round", status, body)
+	}
+	if ttl := client.TTL(ctx, REDIS_KEY_AVIATOR_STATS+":24h").Val(); ttl <= 0 || ttl > AVIATOR_STATS_CACHE_TTL {
+		t.Errorf("24h stats cache TTL = %v, want up to %v", ttl, AVIATOR_STATS_CACHE_TTL)
+	}
+
+	// Cached statistics are served until they expire
+	delete(s.db.(testDB).rounds, "R-old")
+	if _, body := get(""); body["total_rounds"] != 2.0 {
+		t.Errorf("cached stats = %v, want both rounds", body)
+	}
+
+	if status, _ := get("?period=yesterday"); status != http.StatusBadRequest {
+		t.Errorf("stats with an invalid period status = %d, want 400", status)
+	}
+
+	// The current round is not cached with the statistics
+	s.gameManager.Start()
+	t.Cleanup(s.gameManager.Stop)
+	var round *game.RoundState
+	for deadline := time.Now().Add(time.Second); round == nil && time.Now().Before(deadline); {
+		round = s.gameManager.GetCurrentRound()
+		time.Sleep(5 * time.Millisecond)
+	}
+	if round == nil {
+		t.Fatal("timed out waiting for a round to start")
+	}
+	if _, body := get(""); body["current_round_id"] != round.RoundID || body["total_rounds"] != 2.0 {
+		t.Errorf("stats = %v, want the cached stats with current round %s", body, round.RoundID)
+	}
+}
+
+func TestClientSeed(t *testing.T) {
+	s, client := newTestServer(t)
+	client.Set(t.Context(), game.REDIS_KEY_USER_BALANCE+"user1", 100.0, 0)
+
+	bets := []struct {
+		path string
+		body func(clientSeed string) interface{}
+	}{
+		{"/api/v1/mines/bet", func(clientSeed string) interface{} {
+			return game.MinesBetRequest{UserID: "user1", Amount: 1, MineCount: 3, ClientSeed: clientSeed}
+		}},
+		{"/api/v1/dice/roll", func(clientSeed string) interface{} {
+			return game.DiceRollRequest{UserID: "user1", Amount: 1, Target: 50, IsOver: true, ClientSeed: clientSeed}
+		}},
+		{"/api/v1/plinko/drop", func(clientSeed string) interface{} {
+			return game.PlinkoDropRequest{UserID: "user1", Amount: 1, Risk: game.PlinkoRiskLow, Rows: 8, ClientSeed: clientSeed}
+		}},
+	}
+
+	for _, bet := range bets {
+		for _, clientSeed := range []string{"short1", "not-alpha-numeric"} {
+			data, _ := json.Marshal(bet.body(clientSeed))
+			req, _ := http.NewRequest("POST", bet.path, bytes.NewReader(data))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := s.App.Test(req)
+			if err != nil {
+				t.Fatalf("could not perform request: %v", err)
+			}
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("%s with client seed %q: expected status 400; got %v", bet.path, clientSeed, resp.StatusCode)
+			}
+		}
+
+		if result := postJSON(t, s.App, bet.path, bet.body("myLuckySeed42")); result["success"] != true || result["client_seed"] != "myLuckySeed42" {
+			t.Errorf("%s with a valid client seed = %v, want it used", bet.path, result)
+		}
+		if result := postJSON(t, s.App, bet.path, bet.body("")); result["success"] != true || len(fmt.Sprint(result["client_seed"])) != 64 {
+			t.Errorf("%s without a client seed = %v, want a generated seed", bet.path, result)
+		}
+	}
+}
+
+func TestMinesForfeitHandler(t *testing.T) {
+	s, client := newTestServer(t)
+	client.Set(t.Context(), game.REDIS_KEY_USER_BALANCE+"user1", 100.0, 0)
+
+	forfeit := func(gameID string) (int, map[string]interface{}) {
+		req, _ := http.NewRequest("DELETE", "/api/v1/mines/game/"+gameID+"?user_id=user1", nil)
+		resp, err := s.App.Test(req)
+		if err != nil {
+			t.Fatalf("could not perform request: %v", err)
+		}
+		var result map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&result)
+		return resp.StatusCode, result
+	}
+
+	bet := postJSON(t, s.App, "/api/v1/mines/bet", game.MinesBetRequest{UserID: "user1", Amount: 10, MineCount: 3})
+	status, result := forfeit(bet["game_id"].(string))
+	if status != http.StatusOK || result["forfeit_refund"] != 9.0 || result["penalty_pct"] != 10.0 || result["balance"] != 99.0 {
+		t.Errorf("forfeit = %d %v, want a refund of 9 with a 10%% penalty", status, result)
+	}
+
+	// Start games until the first reveal is safe
+	for {
+		bet = postJSON(t, s.App, "/api/v1/mines/bet", game.MinesBetRequest{UserID: "user1", Amount: 1, MineCount: 1})
+		click := postJSON(t, s.App, "/api/v1/mines/click", game.MinesClickRequest{UserID: "user1", GameID: bet["game_id"].(string), TileID: 0})
+		if click["is_mine"] == false {
+			break
+		}
+	}
+	status, result = forfeit(bet["game_id"].(string))
+	if status != http.StatusBadRequest || result["message"] != "Cannot forfeit after revealing tiles; use cashout instead" {
+		t.Errorf("forfeit after a reveal = %d %v, want 400", status, result)
+	}
+}
+
+func TestMinesPreselectHandler(t *testing.T) {
+	s, client := newTestServer(t)
+	client.Set(t.Context(), game.REDIS_KEY_USER_BALANCE+"user1", 100.0, 0)
+
+	bet := postJSON(t, s.App, "/api/v1/mines/bet", game.MinesBetRequest{UserID: "user1", Amount: 10, MineCount: 1})
+	gameID := bet["game_id"].(string)
+
+	status, result := postRaw(t, s, "/api/v1/mines/preselect", []byte(`{"user_id":"user1","game_id":"`+gameID+`","tiles":[3,3]}`))
+	if status != http.StatusBadRequest || result["message"] != "Tiles must be unique" {
+		t.Errorf("duplicate tiles = %d %v, want 400", status, result)
+	}
+
+	status, result = postRaw(t, s, "/api/v1/mines/preselect", []byte(`{"user_id":"user1","game_id":"`+gameID+`","tiles":[0,1]}`))
+	results, _ := result["results"].([]interface{})
+	if status != http.StatusOK || len(results) == 0 || result["result_signature"] == nil {
+		t.Errorf("preselect = %d %v, want the signed results", status, result)
+	}
+}
+
+func TestMinesMultiGameHandler(t *testing.T) {
+	s, client := newTestServer(t)
+	client.Set(t.Context(), game.REDIS_KEY_USER_BALANCE+"user1", 100.0, 0)
+
+	status, result := postRaw(t, s, "/api/v1/mines/multi-game", []byte(`{"user_id":"user1","games":[{"amount":1,"mine_count":3,"tiles_to_reveal":0}]}`))
+	if status != http.StatusBadRequest {
+		t.Errorf("no tiles to reveal = %d %v, want 400", status, result)
+	}
+
+	status, result = postRaw(t, s, "/api/v1/mines/multi-game", []byte(`{"user_id":"user1","games":[{"amount":1,"mine_count":3,"tiles_to_reveal":2},{"amount":2,"mine_count":5,"tiles_to_reveal":1}]}`))
+	results, _ := result["results"].([]interface{})
+	if status != http.StatusOK || len(results) != 2 || result["total_wagered"] != 3.0 || result["result_signature"] == nil {
+		t.Errorf("multi-game = %d %v, want 2 signed results", status, result)
+	}
+
+	client.Set(t.Context(), game.REDIS_KEY_MINES_ACTIVE_COUNT, game.MINES_MAX_CONCURRENT_GAMES, 0)
+	status, result = postRaw(t, s, "/api/v1/mines/multi-game", []byte(`{"user_id":"user1","games":[{"amount":1,"mine_count":3,"tiles_to_reveal":1}]}`))
+	if status != http.StatusServiceUnavailable {
+		t.Errorf("multi-game at capacity = %d %v, want 503", status, result)
+	}
+}
+
+func TestMinesCashoutAllHandler(t *testing.T) {
+	s, client := newTestServer(t)
+	client.Set(t.Context(), game.REDIS_KEY_USER_BALANCE+"user1", 100.0, 0)
+	postJSON(t, s.App, "/api/v1/mines/bet", game.MinesBetRequest{UserID: "user1", Amount: 10, MineCount: 3})
+
+	status, result := postRaw(t, s, "/api/v1/mines/cashout-all", []byte(`{"user_id":"user1"}`))
+	if status != http.StatusOK || result["games_cashed_out"] != 0.0 || len(result["results"].([]interface{})) != 1 || result["balance"] != 90.0 {
+		t.Errorf("expected the unrevealed game skipped, got %d %v", status, result)
+	}
+	if status, result := postRaw(t, s, "/api/v1/mines/cashout-all", []byte(`{"user_id":"user1"}`)); status != http.StatusTooManyRequests {
+		t.Errorf("expected status 429 within %v, got %d %v", game.MINES_CASHOUT_ALL_INTERVAL, status, result)
+	}
+	if status, _ := postRaw(t, s, "/api/v1/mines/cashout-all", []byte(`{}`)); status != http.StatusBadRequest {
+		t.Errorf("expected status 400 without a user ID, got %d", status)
+	}
+}
+
+func TestMinesOddsTableHandler(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	getTables := func(query string) (int, []game.MinesOddsTable) {
+		t.Helper()
+		req, _ := http.NewRequest("GET", "/api/v1/mines/odds-table"+query, nil)
+		resp, err := s.App.Test(req)
+		if err != nil {
+			t.Fatalf("could not perform request: %v", err)
+		}
+		defer resp.Body.Close()
+		var result struct {
+			Tables []game.MinesOddsTable `json:"tables"`
+		}
+		json.NewDecoder(resp.Body).Decode(&result)
+		return resp.StatusCode, result.Tables
+	}
+
+	status, tables := getTables("?mine_count=5")
+	if status != http.StatusOK || len(tables) != 1 || tables[0].MineCount != 5 || len(tables[0].Rows) != game.MINES_GRID_SIZE-5 {
+		t.Errorf("5 mines = %d %+v, want one table of 20 rows", status, tables)
+	}
+	if status, tables = getTables(""); status != http.StatusOK || len(tables) != game.MINES_MAX_COUNT {
+		t.Errorf("every mine count = %d, %d tables; want %d", status, len(tables), game.MINES_MAX_COUNT)
+	}
+	for _, query := range []string{"?mine_count=0", "?mine_count=25", "?mine_count=five"} {
+		if status, _ := getTables(query); status != http.StatusBadRequest {
+			t.Errorf("%s = %d, want 400", query, status)
+		}
+	}
+}
+
+func TestDiceParlayHandlers(t *testing.T) {
+	s, client := newTestServer(t)
+	client.Set(t.Context(), game.REDIS_KEY_USER_BALANCE+"user1", 100.0, 0)
+
+	getOdds := func(legs string) (int, game.DiceParlayOdds) {
+		t.Helper()
+		req, _ := http.NewRequest("GET", "/api/v1/dice/parlay/odds-preview?legs="+url.QueryEscape(legs), nil)
+		resp, err := s.App.Test(req)
+		if err != nil {
+			t.Fatalf("could not perform request: %v", err)
+		}
+		defer resp.Body.Close()
+		var odds game.DiceParlayOdds
+		json.NewDecoder(resp.Body).Decode(&odds)
+		return resp.StatusCode, odds
+	}
+
+	if status, odds := getOdds(`[{"target":50,"is_over":true},{"target":50}]`); status != http.StatusOK || odds.TotalMultiplier != 3.53 || odds.WinProbability != 0.25 {
+		t.Errorf("2-leg odds = %d %+v, want 3.53x at 25%%", status, odds)
+	}
+	for _, legs := range []string{`[{"target":50}]`, `not json`, ``} {
+		if status, _ := getOdds(legs); status != http.StatusBadRequest {
+			t.Errorf("odds of %q = %d, want 400", legs, status)
+		}
+	}
+
+	result := postJSON(t, s.App, "/api/v1/dice/parlay", game.DiceParlayRequest{
+		UserID: "user1",
+		Amount: 10,
+		Legs:   []game.DiceParlayLeg{{Target: 100}, {Target: 100}, {Target: 100}},
+	})
+	if result["all_won"] != true || len(result["legs_results"].([]interface{})) != 3 || result["game_id"] == nil {
+		t.Errorf("3-leg parlay = %v, want every leg won", result)
+	}
+}
+
+func TestMinesProbabilitiesHandler(t *testing.T) {
+	s, client := newTestServer(t)
+	client.Set(t.Context(), game.REDIS_KEY_USER_BALANCE+"user1", 100.0, 0)
+
+	bet := postJSON(t, s.App, "/api/v1/mines/bet", game.MinesBetRequest{UserID: "user1", Amount: 1, MineCount: 5, GridSize: 16})
+	gameID := bet["game_id"].(string)
+
+	req, _ := http.NewRequest("GET", "/api/v1/mines/probabilities/"+gameID, nil)
+	resp, err := s.App.Test(req)
+	if err != nil {
+		t.Fatalf("could not perform request: %v", err)
+	}
+	var result game.MinesProbabilitiesResponse
+	json.NewDecoder(resp.Body).Decode(&result)
+	if resp.StatusCode != http.StatusOK || len(result.TileProbabilities) != 16 || result.TileProbabilities[0].MineProbability != 5.0/16 {
+		t.Errorf("probabilities = %d %+v, want 16 tiles at 5/16", resp.StatusCode, result)
+	}
+
+	click := postJSON(t, s.App, "/api/v1/mines/click?mode=probability", game.MinesClickRequest{UserID: "user1", GameID: gameID, TileID: 0})
+	if click["is_mine"] == false {
+		tiles, _ := click["tile_probabilities"].([]interface{})
+		if click["display_mode"] != "probability" || len(tiles) != 15 {
+			t.Errorf("click in probability mode = %v, want 15 tile probabilities", click)
+		}
+	}
+	if click := postJSON(t, s.App, "/api/v1/mines/click", game.MinesClickRequest{UserID: "user1", GameID: gameID, TileID: 1}); click["tile_probabilities"] != nil {
+		t.Errorf("click without a mode = %v, want no tile probabilities", click)
+	}
+
+	req, _ = http.NewRequest("GET", "/api/v1/mines/probabilities/MINES-unknown", nil)
+	if resp, _ := s.App.Test(req); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("probabilities of an unknown game: status %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestMinesGridHandler(t *testing.T) {
+	s, client := newTestServer(t)
+	client.Set(t.Context(), game.REDIS_KEY_USER_BALANCE+"user1", 100.0, 0)
+
+	bet := postJSON(t, s.App, "/api/v1/mines/bet", game.MinesBetRequest{UserID: "user1", Amount: 1, MineCount: 2, GridShape: game.MinesGridShape{Rows: 2, Cols: 8}})
+	gameID, _ := bet["game_id"].(string)
+	if gameID == "" {
+		t.Fatalf("bet on a 2x8 grid = %v, want a game", bet)
+	}
+
+	req, _ := http.NewRequest("GET", "/api/v1/mines/grid/"+gameID, nil)
+	resp, err := s.App.Test(req)
+	if err != nil {
+		t.Fatalf("could not perform request: %v", err)
+	}
+	var result game.MinesGridResponse
+	json.NewDecoder(resp.Body).Decode(&result)
+	if resp.StatusCode != http.StatusOK || len(result.Grid) != 2 || len(result.Grid[1]) != 8 || result.Grid[1][0].TileID != 8 {
+		t.Errorf("grid = %d %+v, want 2 rows of 8 tiles", resp.StatusCode, result)
+	}
+
+	req, _ = http.NewRequest("GET", "/api/v1/mines/grid/MINES-unknown", nil)
+	if resp, _ := s.App.Test(req); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("grid of an unknown game: status %d, want 404", resp.StatusCode)
+	}
+}
+
+// walletRequest posts {"amount": amount} with token in X-Admin-Token if set
+func walletRequest(t *testing.T, s *FiberServer, path, token string, amount float64) (int, map[string]interface{}) {
+	t.Helper()
+
+	data, _ := json.Marshal(map[string]float64{"amount": amount})
+	req, _ := http.NewRequest("POST", path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("X-Admin-Token", token)
+	}
+	resp, err := s.App.Test(req)
+	if err != nil {
+		t.Fatalf("could not perform request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var result map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&result)
+	return resp.StatusCode, result
+}
+
+func TestWalletHandlers(t *testing.T) {
+	s, client := newTestServer(t)
+	ctx := t.Context()
+
+	conn := &mockConn{}
+	s.gameHub.RegisterClient(conn, "user1")
+	deadline := time.Now().Add(time.Second)
+	for !s.gameHub.SubscribeBalance(conn, "user1") {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out registering client")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	t.Run("deposit requires the admin token", func(t *testing.T) {
+		if status, _ := walletRequest(t, s, "/api/v1/users/user1/deposit", "", 100); status != http.StatusUnauthorized {
+			t.Errorf("deposit without a token: status %d, want 401", status)
+		}
+		if status, _ := walletRequest(t, s, "/api/v1/users/user1/deposit", "wrong", 100); status != http.StatusUnauthorized {
+			t.Errorf("deposit with a wrong token: status %d, want 401", status)
+		}
+		if balance, _ := client.Get(ctx, game.REDIS_KEY_USER_BALANCE+"user1").Float64(); balance != 0 {
+			t.Errorf("balance after rejected deposits = %v, want 0", balance)
+		}
+	})
+
+	t.Run("deposit", func(t *testing.T) {
+		status, body := walletRequest(t, s, "/api/v1/users/user1/deposit", testAdminKey, 100)
+		if status != http.StatusOK || body["balance"] != 100.0 || body["type"] != game.DEPOSIT_TRANSACTION_TYPE {
+			t.Fatalf("deposit: status %d, body %v; want a balance of 100", status, body)
+		}
+
+		msg := conn.next(t)
+		if msg["type"] != "balance_update" || msg["delta"] != 100.0 || msg["reason"] != game.BalanceReasonDeposit {
+			t.Errorf("deposit message = %v, want a balance_update of 100 for a deposit", msg)
+		}
+
+		status, _ = walletRequest(t, s, "/api/v1/users/user1/deposit", testAdminKey, 50)
+		if status != http.StatusTooManyRequests {
+			t.Errorf("second deposit within %v: status %d, want 429", game.DEPOSIT_RATE_INTERVAL, status)
+		}
+	})
+
+	t.Run("withdraw", func(t *testing.T) {
+		if status, _ := walletRequest(t, s, "/api/v1/users/user1/withdraw", "", 5); status != http.StatusBadRequest {
+			t.Errorf("withdrawal below the minimum: status %d, want 400", status)
+		}
+		if status, _ := walletRequest(t, s, "/api/v1/users/user1/withdraw", "", 500); status != http.StatusBadRequest {
+			t.Errorf("withdrawal above the balance: status %d, want 400", status)
+		}
+		if balance, _ := client.Get(ctx, game.REDIS_KEY_USER_BALANCE+"user1").Float64(); balance != 100 {
+			t.Errorf("balance after rejected withdrawals = %v, want 100", balance)
+		}
+
+		// The overdrawn attempt used up the rate limit
+		client.Del(ctx, game.REDIS_KEY_WITHDRAWAL_LIMIT+"user1")
+		status, body := walletRequest(t, s, "/api/v1/users/user1/withdraw", "", 40)
+		if status != http.StatusOK || body["balance"] != 60.0 || body["type"] != game.WITHDRAWAL_TRANSACTION_TYPE {
+			t.Fatalf("withdrawal: status %d, body %v; want a balance of 60", status, body)
+		}
+		if status, _ := walletRequest(t, s, "/api/v1/users/user1/withdraw", "", 20); status != http.StatusTooManyRequests {
+			t.Errorf("second withdrawal within %v: status %d, want 429", game.WITHDRAWAL_RATE_INTERVAL, status)
+		}
+	})
+
+	t.Run("history", func(t *testing.T) {
+		s.db.RecordTransaction(ctx, database.Transaction{UserID: "user1", Type: "BET", Amount: 10})
+
+		req, _ := http.NewRequest("GET", "/api/v1/users/user1/deposits", nil)
+		resp, err := s.App.Test(req)
+		if err != nil {
+			t.Fatalf("could not perform request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var body struct {
+			Transactions []struct {
+				Type         string  `json:"type"`
+				Amount       float64 `json:"amount"`
+				BalanceAfter float64 `json:"balance_after"`
+			} `json:"transactions"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		if len(body.Transactions) != 2 {
+			t.Fatalf("history = %+v, want the withdrawal and the deposit", body.Transactions)
+		}
+		withdrawal, deposit := body.Transactions[0], body.Transactions[1]
+		if withdrawal.Type != game.WITHDRAWAL_TRANSACTION_TYPE || withdrawal.Amount != 40 || withdrawal.BalanceAfter != 60 {
+			t.Errorf("newest transaction = %+v, want the withdrawal of 40", withdrawal)
+		}
+		if deposit.Type != game.DEPOSIT_TRANSACTION_TYPE || deposit.Amount != 100 || deposit.BalanceAfter != 100 {
+			t.Errorf("oldest transaction = %+v, want the deposit of 100", deposit)
+		}
+
+		req, _ = http.NewRequest("GET", "/api/v1/users/user1/deposits?limit=0", nil)
+		if resp, _ := s.App.Test(req); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("limit=0: status %d, want 400", resp.StatusCode)
+		}
+	})
+}
+
+// startRound starts the game loop and waits for its first round
+func startRound(tb testing.TB, s *FiberServer) *game.RoundState {
+	tb.Helper()
+
+	s.gameManager.Start()
+	tb.Cleanup(s.gameManager.Stop)
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		if round := s.gameManager.GetCurrentRound(); round != nil {
+			return round
+		}
+	}
+	tb.Fatal("timed out waiting for a round to start")
+	return nil
+}
+
+func TestRoundHeadHandler(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	head := func() (*http.Response, []byte) {
+		req, _ := http.NewRequest("HEAD", "/api/v1/rounds/current", nil)
+		resp, err := s.App.Test(req)
+		if err != nil {
+			t.Fatalf("could not perform request: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp, body
+	}
+
+	if resp, _ := head(); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("HEAD before the first round: status %d, want 404", resp.StatusCode)
+	}
+
+	round := startRound(t, s)
+	resp, body := head()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("HEAD: status %d, want 200", resp.StatusCode)
+	}
+	if len(body) != 0 {
+		t.Errorf("HEAD body = %q, want none", body)
+	}
+	want := map[string]string{
+		"X-Round-ID":         round.RoundID,
+		"X-Round-Status":     "BETTING",
+		"X-Round-Multiplier": "1.00",
+		"X-Round-ETag":       game.RoundHeadETag(round.RoundID, "BETTING"),
+	}
+	for header, value := range want {
+		if got := resp.Header.Get(header); got != value {
+			t.Errorf("%s = %q, want %q", header, got, value)
+		}
+	}
+}
+
+// BenchmarkRoundPolling compares polling the round with HEAD against fetching
+// its full state. Requests go straight to the Fiber handler so the in-memory
+// transport of App.Test does not dominate.
+func BenchmarkRoundPolling(b *testing.B) {
+	s, _ := newTestServer(b)
+	startRound(b, s)
+	handler := s.App.Handler()
+
+	for _, tt := range []struct{ method, path string }{
+		{"HEAD", "/api/v1/rounds/current"},
+		{"GET", "/api/v1/game/state"},
+	} {
+		b.Run(tt.method, func(b *testing.B) {
+			var ctx fasthttp.RequestCtx
+			for b.Loop() {
+				ctx.Request.Reset()
+				ctx.Response.Reset()
+				ctx.Request.Header.SetMethod(tt.method)
+				ctx.Request.SetRequestURI(tt.path)
+				handler(&ctx)
+				if ctx.Response.StatusCode() != http.StatusOK {
+					b.Fatalf("%s %s: status %d", tt.method, tt.path, ctx.Response.StatusCode())
+				}
+			}
+		})
+	}
+}
+
+func TestAutoCashoutDistributionHandler(t *testing.T) {
+	s, client := newTestServer(t)
+	client.HSet(t.Context(), game.REDIS_KEY_AUTO_CASHOUT_TARGETS, "2.00", 3, "1.50", 1)
+
+	get := func(token string) (int, map[string]interface{}) {
+		req, _ := http.NewRequest("GET", "/api/v1/aviator/auto-cashout-distribution", nil)
+		req.Header.Set("X-Admin-Token", token)
+		resp, err := s.App.Test(req)
+		if err != nil {
+			t.Fatalf("could not perform request: %v", err)
+		}
+		var result map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&result)
+		return resp.StatusCode, result
+	}
+
+	if status, _ := get("wrong"); status != http.StatusUnauthorized {
+		t.Errorf("expected status 401 without the admin token; got %v", status)
+	}
+	status, result := get(testAdminKey)
+	targets, _ := result["targets"].([]interface{})
+	if status != http.StatusOK || result["total"] != 4.0 || len(targets) != 2 || result["round_target_share_pct"] != 75.0 {
+		t.Errorf("distribution = %d %v, want 4 bets with 75%% on 2x", status, result)
+	}
+}
@@ -0,0 +1,164 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func getHealth(t *testing.T, s *FiberServer, path string) (int, map[string]interface{}) {
+	t.Helper()
+
+	req, _ := http.NewRequest("GET", path, nil)
+	resp, err := s.App.Test(req)
+	if err != nil {
+		t.Fatalf("could not perform request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var result map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("could not decode response: %v", err)
+	}
+	return resp.StatusCode, result
+}
+
+func newHealthTestServer(t *testing.T) *FiberServer {
+	t.Helper()
+
+	s, _ := newTestServer(t)
+	s.App.Get("/health", s.healthHandler)
+	s.App.Get("/health/ready", s.readinessHandler)
+	s.App.Get("/health/live", s.livenessHandler)
+	return s
+}
+
+func TestHealthHandler_ReportsEngines(t *testing.T) {
+	s := newHealthTestServer(t)
+
+	status, result := getHealth(t, s, "/health")
+	if status != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", status, result)
+	}
+
+	engines, ok := result["engines"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected engines in response, got %v", result)
+	}
+	for _, name := range []string{"mines", "dice", "plinko"} {
+		engine, _ := engines[name].(map[string]interface{})
+		if engine["status"] != "ok" {
+			t.Errorf("expected %s engine to be ok, got %v", name, engine)
+		}
+		if _, ok := engine["latency_ms"]; !ok {
+			t.Errorf("expected latency_ms for %s engine", name)
+		}
+	}
+
+	activity, _ := result["activity"].(map[string]interface{})
+	for name, field := range map[string]string{"mines": "active_games", "dice": "rolls_last_minute", "plinko": "balls_dropped_last_minute"} {
+		if stats, _ := activity[name].(map[string]interface{}); stats[field] != 0.0 {
+			t.Errorf("expected %s activity with %s, got %v", name, field, activity[name])
+		}
+	}
+}
+
+func TestHealthHandler_UnhealthyEngine(t *testing.T) {
+	s := newHealthTestServer(t)
+	s.cache.GetClient().Close() // Mines can no longer reach Redis
+
+	status, result := getHealth(t, s, "/health")
+	if status != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", status)
+	}
+
+	mines := result["engines"].(map[string]interface{})["mines"].(map[string]interface{})
+	if mines["status"] != "unhealthy" || mines["error"] == "" {
+		t.Errorf("expected unhealthy mines engine with an error, got %v", mines)
+	}
+
+	if status, _ := getHealth(t, s, "/health/live"); status != http.StatusOK {
+		t.Errorf("liveness should not depend on engines, got %d", status)
+	}
+}
+
+func TestReadinessHandler(t *testing.T) {
+	s := newHealthTestServer(t)
+
+	if status, result := getHealth(t, s, "/health/ready"); status != http.StatusOK {
+		t.Fatalf("expected 200 when all checks pass, got %d: %v", status, result)
+	}
+
+	s.db = testDB{status: "down"}
+	status, result := getHealth(t, s, "/health/ready")
+	if database, _ := result["database"].(map[string]interface{}); status != http.StatusServiceUnavailable || database["status"] != "down" || database["error"] == nil {
+		t.Errorf("expected 503 with the database down, got %d: %v", status, result)
+	}
+	if status, _ := getHealth(t, s, "/health"); status != http.StatusOK {
+		t.Errorf("/health should only fail on engines, got %d", status)
+	}
+}
+
+func TestReadinessHandler_StartupChecks(t *testing.T) {
+	s := newHealthTestServer(t)
+	s.cache.GetClient().Close() // Redis goes away during startup
+
+	engines := StartupCheck{Name: "engines", Run: func(ctx context.Context) error { return nil }, Timeout: time.Second}
+	s.startup = NewStartup(
+		StartupCheck{Name: "redis", Run: s.connectRedis, Timeout: time.Second},
+		StartupCheck{Name: "migrations", Run: func(ctx context.Context) error { return errors.New("schema is at version 7, want 8") }, Timeout: time.Second},
+		engines,
+	)
+
+	status, result := getHealth(t, s, "/health/ready")
+	if pending, _ := result["pending"].([]interface{}); status != http.StatusServiceUnavailable || result["ready"] != false || len(pending) != 3 {
+		t.Errorf("before startup = %d %v, want 503 with every check pending", status, result)
+	}
+
+	var startupErr *StartupError
+	if err := s.startup.Run(t.Context()); !errors.As(err, &startupErr) || len(startupErr.Failures) != 2 ||
+		startupErr.Failures[0].Check != "redis" || startupErr.Failures[1].Check != "migrations" {
+		t.Fatalf("Run() = %v, want the redis and migrations failures", err)
+	}
+
+	status, result = getHealth(t, s, "/health/ready")
+	if pending, _ := result["pending"].([]interface{}); status != http.StatusServiceUnavailable || len(pending) != 2 || pending[0] != "redis" || pending[1] != "migrations" {
+		t.Errorf("after failed startup = %d %v, want 503 with redis and migrations pending", status, result)
+	}
+	if status, _ := getHealth(t, s, "/health/live"); status != http.StatusOK {
+		t.Errorf("liveness should not depend on startup, got %d", status)
+	}
+
+	slow := StartupCheck{Name: "slow", Run: func(ctx context.Context) error { <-ctx.Done(); return nil }, Timeout: 10 * time.Millisecond}
+	s.startup = NewStartup(slow, engines)
+	if err := s.startup.Run(t.Context()); err == nil || !strings.Contains(err.Error(), "slow: timed out") {
+		t.Errorf("Run() = %v, want the slow check to time out", err)
+	}
+
+	s = newHealthTestServer(t)
+	s.startup = NewStartup(engines)
+	if err := s.startup.Run(t.Context()); err != nil || !s.startup.Ready() {
+		t.Fatalf("Run() = %v, want ready", err)
+	}
+	if status, result := getHealth(t, s, "/health/ready"); status != http.StatusOK {
+		t.Errorf("after startup = %d %v, want 200", status, result)
+	}
+}
+
+func TestHealthHandler_Compressed(t *testing.T) {
+	s := newHealthTestServer(t)
+
+	req, _ := http.NewRequest("GET", "/health", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := s.App.Test(req)
+	if err != nil {
+		t.Fatalf("could not perform request: %v", err)
+	}
+	if resp.Header.Get("Content-Encoding") != "gzip" {
+		t.Errorf("Content-Encoding = %q, want the compressor to gzip /health", resp.Header.Get("Content-Encoding"))
+	}
+}
@@ -0,0 +1,254 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"aviator/internal/game"
+)
+
+const (
+	RING_LOG_CAPACITY = 1000
+	RING_LOG_QUEUE    = 256 // Entries waiting to be stored before new ones are dropped
+	LOG_STREAM_BUFFER = 64  // Entries waiting to be sent to one stream client
+)
+
+// LogEntry is one captured log line. Lines written through the log package
+// get their level and fields from the bracketed prefix, e.g. "[MINES]".
+type LogEntry struct {
+	Timestamp time.Time         `json:"timestamp"`
+	Level     string            `json:"level"`
+	Message   string            `json:"message"`
+	Fields    map[string]string `json:"fields,omitempty"`
+}
+
+// LogFilter selects log entries. Empty fields match everything.
+type LogFilter struct {
+	Level    string // Minimum level: debug, info, warn or error
+	GameType string
+}
+
+// Matches reports whether the entry passes the filter
+func (f LogFilter) Matches(entry LogEntry) bool {
+	if f.Level != "" && levelRank(entry.Level) < levelRank(f.Level) {
+		return false
+	}
+	if f.GameType != "" && entry.Fields["game_type"] != f.GameType {
+		return false
+	}
+	return true
+}
+
+func levelRank(level string) slog.Level {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return slog.LevelInfo
+	}
+	return l
+}
+
+// RingLog keeps the most recent log entries in memory for the admin API.
+// Entries are queued without blocking and stored by a single goroutine, so a
+// slow reader never holds up the code doing the logging.
+type RingLog struct {
+	entries []LogEntry
+	head    int // Oldest entry once the buffer is full
+	mu      sync.RWMutex
+
+	queue       chan LogEntry
+	dropped     atomic.Int64
+	subscribers map[chan LogEntry]struct{}
+	done        chan struct{}
+	stopOnce    sync.Once
+}
+
+func NewRingLog(capacity int) *RingLog {
+	r := &RingLog{
+		entries:     make([]LogEntry, 0, capacity),
+		queue:       make(chan LogEntry, RING_LOG_QUEUE),
+		subscribers: make(map[chan LogEntry]struct{}),
+		done:        make(chan struct{}),
+	}
+	go r.run()
+	return r
+}
+
+func (r *RingLog) run() {
+	for {
+		select {
+		case entry := <-r.queue:
+			r.add(entry)
+		case <-r.done:
+			return
+		}
+	}
+}
+
+// Stop ends storing queued entries. Later writes are dropped.
+func (r *RingLog) Stop() {
+	r.stopOnce.Do(func() { close(r.done) })
+}
+
+// Write queues an entry and returns at once. The entry is dropped if the
+// queue is full.
+func (r *RingLog) Write(entry LogEntry) {
+	select {
+	case r.queue <- entry:
+	default:
+		r.dropped.Add(1)
+	}
+}
+
+// Dropped returns how many entries were lost to a full queue
+func (r *RingLog) Dropped() int64 {
+	return r.dropped.Load()
+}
+
+// add stores an entry, overwriting the oldest once the buffer is full, and
+// passes it on to every stream subscriber that has room for it
+func (r *RingLog) add(entry LogEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.entries) < cap(r.entries) {
+		r.entries = append(r.entries, entry)
+	} else {
+		r.entries[r.head] = entry
+		r.head = (r.head + 1) % len(r.entries)
+	}
+
+	for sub := range r.subscribers {
+		select {
+		case sub <- entry:
+		default:
+		}
+	}
+}
+
+// Tail returns up to limit of the newest entries matching filter, oldest first
+func (r *RingLog) Tail(filter LogFilter, limit int) []LogEntry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matched := make([]LogEntry, 0, min(limit, len(r.entries)))
+	for i := len(r.entries) - 1; i >= 0 && len(matched) < limit; i-- {
+		entry := r.entries[(r.head+i)%len(r.entries)]
+		if filter.Matches(entry) {
+			matched = append(matched, entry)
+		}
+	}
+
+	for i, j := 0, len(matched)-1; i < j; i, j = i+1, j-1 {
+		matched[i], matched[j] = matched[j], matched[i]
+	}
+	return matched
+}
+
+// Subscribe returns a channel receiving every entry stored from now on, and
+// a function to stop receiving them. Entries are skipped while the channel
+// is full.
+func (r *RingLog) Subscribe() (<-chan LogEntry, func()) {
+	sub := make(chan LogEntry, LOG_STREAM_BUFFER)
+
+	r.mu.Lock()
+	r.subscribers[sub] = struct{}{}
+	r.mu.Unlock()
+
+	return sub, func() {
+		r.mu.Lock()
+		delete(r.subscribers, sub)
+		r.mu.Unlock()
+	}
+}
+
+// Handler returns a slog.Handler that passes records on to next and copies
+// them into the ring
+func (r *RingLog) Handler(next slog.Handler) slog.Handler {
+	return &ringHandler{ring: r, next: next}
+}
+
+type ringHandler struct {
+	ring  *RingLog
+	next  slog.Handler
+	attrs []slog.Attr
+	group string
+}
+
+func (h *ringHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *ringHandler) Handle(ctx context.Context, record slog.Record) error {
+	entry := LogEntry{
+		Timestamp: record.Time,
+		Level:     strings.ToLower(record.Level.String()),
+		Message:   record.Message,
+		Fields:    make(map[string]string),
+	}
+	for _, attr := range h.attrs {
+		entry.Fields[attr.Key] = attr.Value.String()
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		entry.Fields[h.group+attr.Key] = attr.Value.String()
+		return true
+	})
+	if record.Level == slog.LevelInfo {
+		parsePrefix(&entry)
+	}
+	h.ring.Write(entry)
+
+	return h.next.Handle(ctx, record)
+}
+
+func (h *ringHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	withAttrs := make([]slog.Attr, len(h.attrs), len(h.attrs)+len(attrs))
+	copy(withAttrs, h.attrs)
+	for _, attr := range attrs {
+		withAttrs = append(withAttrs, slog.Attr{Key: h.group + attr.Key, Value: attr.Value})
+	}
+	return &ringHandler{ring: h.ring, next: h.next.WithAttrs(attrs), attrs: withAttrs, group: h.group}
+}
+
+func (h *ringHandler) WithGroup(name string) slog.Handler {
+	return &ringHandler{ring: h.ring, next: h.next.WithGroup(name), attrs: h.attrs, group: h.group + name + "."}
+}
+
+// componentGameTypes are the log prefixes that belong to a single game
+var componentGameTypes = map[string]game.GameType{
+	"round":   game.GameTypeAviator,
+	"bet":     game.GameTypeAviator,
+	"cashout": game.GameTypeAviator,
+	"mines":   game.GameTypeMines,
+	"plinko":  game.GameTypePlinko,
+	"dice":    game.GameTypeDice,
+}
+
+// parsePrefix fills in what a log package line like
+// "[MINES] Failed to save game: ..." says about itself: the component, the
+// game type for game components, and an error level for failures and panics.
+func parsePrefix(entry *LogEntry) {
+	if !strings.HasPrefix(entry.Message, "[") {
+		return
+	}
+	end := strings.Index(entry.Message, "]")
+	if end < 0 {
+		return
+	}
+
+	component := strings.ToLower(entry.Message[1:end])
+	entry.Fields["component"] = component
+	if gameType, ok := componentGameTypes[component]; ok {
+		if _, set := entry.Fields["game_type"]; !set {
+			entry.Fields["game_type"] = string(gameType)
+		}
+	}
+
+	message := strings.ToLower(entry.Message[end+1:])
+	if component == "panic" || strings.Contains(message, "fail") || strings.Contains(message, "error") {
+		entry.Level = "error"
+	}
+}
@@ -0,0 +1,149 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func newTestRingLog(t *testing.T) *RingLog {
+	t.Helper()
+	logs := NewRingLog(RING_LOG_CAPACITY)
+	t.Cleanup(logs.Stop)
+	return logs
+}
+
+// awaitLogs waits until the ring has stored n entries
+func awaitLogs(t *testing.T, logs *RingLog, n int) []LogEntry {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		entries := logs.Tail(LogFilter{}, RING_LOG_CAPACITY)
+		if len(entries) >= n || time.Now().After(deadline) {
+			return entries
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestRingLog_Wraps(t *testing.T) {
+	logs := newTestRingLog(t)
+	for i := 0; i < 1100; i++ {
+		logs.add(LogEntry{Level: "info", Message: fmt.Sprintf("entry %d", i)})
+	}
+
+	entries := logs.Tail(LogFilter{}, RING_LOG_CAPACITY)
+	if len(entries) != RING_LOG_CAPACITY {
+		t.Fatalf("got %d entries, want %d", len(entries), RING_LOG_CAPACITY)
+	}
+	for i, entry := range entries {
+		if want := fmt.Sprintf("entry %d", i+100); entry.Message != want {
+			t.Fatalf("entries[%d] = %q, want %q", i, entry.Message, want)
+		}
+	}
+	if logs.head != 100 {
+		t.Errorf("head = %d, want 100", logs.head)
+	}
+
+	last := logs.Tail(LogFilter{}, 2)
+	if len(last) != 2 || last[0].Message != "entry 1098" || last[1].Message != "entry 1099" {
+		t.Errorf("Tail(2) = %+v, want entries 1098 and 1099", last)
+	}
+}
+
+func TestRingLog_Filter(t *testing.T) {
+	logs := newTestRingLog(t)
+	logs.add(LogEntry{Level: "error", Message: "[MINES] Failed", Fields: map[string]string{"game_type": "mines"}})
+	logs.add(LogEntry{Level: "info", Message: "[MINES] Started", Fields: map[string]string{"game_type": "mines"}})
+	logs.add(LogEntry{Level: "error", Message: "[DICE] Failed", Fields: map[string]string{"game_type": "dice"}})
+	logs.add(LogEntry{Level: "warn", Message: "slow query"})
+
+	tests := []struct {
+		name   string
+		filter LogFilter
+		want   int
+	}{
+		{name: "all", filter: LogFilter{}, want: 4},
+		{name: "errors", filter: LogFilter{Level: "error"}, want: 2},
+		{name: "warnings and up", filter: LogFilter{Level: "warn"}, want: 3},
+		{name: "mines", filter: LogFilter{GameType: "mines"}, want: 2},
+		{name: "mines errors", filter: LogFilter{Level: "error", GameType: "mines"}, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := logs.Tail(tt.filter, 10); len(got) != tt.want {
+				t.Errorf("Tail(%+v) = %+v, want %d entries", tt.filter, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRingLog_Handler(t *testing.T) {
+	logs := newTestRingLog(t)
+	logger := slog.New(logs.Handler(slog.NewTextHandler(io.Discard, nil)))
+
+	logger.Info("[MINES] Failed to save game: timeout")
+	logger.With("game_type", "plinko").Warn("slow drop", "ms", 250)
+	logger.Info("[SERVER] Game manager started")
+
+	entries := awaitLogs(t, logs, 3)
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	if e := entries[0]; e.Level != "error" || e.Fields["component"] != "mines" || e.Fields["game_type"] != "mines" {
+		t.Errorf("log package failure = %+v, want a mines error", e)
+	}
+	if e := entries[1]; e.Level != "warn" || e.Fields["game_type"] != "plinko" || e.Fields["ms"] != "250" {
+		t.Errorf("structured entry = %+v, want a plinko warning with ms=250", e)
+	}
+	if e := entries[2]; e.Level != "info" || e.Fields["game_type"] != "" {
+		t.Errorf("server entry = %+v, want info without a game type", e)
+	}
+}
+
+func TestRingLog_WriteDoesNotBlock(t *testing.T) {
+	logs := NewRingLog(RING_LOG_CAPACITY)
+	logs.Stop() // Nothing drains the queue
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < RING_LOG_QUEUE+10; i++ {
+			logs.Write(LogEntry{Message: "queued"})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Write blocked on a full queue")
+	}
+	if logs.Dropped() != 10 {
+		t.Errorf("Dropped() = %d, want 10", logs.Dropped())
+	}
+}
+
+func TestRingLog_Subscribe(t *testing.T) {
+	logs := newTestRingLog(t)
+	entries, unsubscribe := logs.Subscribe()
+
+	logs.Write(LogEntry{Level: "info", Message: "live"})
+	select {
+	case entry := <-entries:
+		if entry.Message != "live" {
+			t.Errorf("got %+v, want the live entry", entry)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("subscriber did not receive the entry")
+	}
+
+	unsubscribe()
+	logs.add(LogEntry{Message: "after"})
+	select {
+	case entry := <-entries:
+		t.Errorf("got %+v after unsubscribing", entry)
+	default:
+	}
+}
@@ -10,14 +10,18 @@ func (s *FiberServer) RegisterFiberRoutes() {
 	s.App.Use(cors.New(cors.Config{
 		AllowOrigins:     "*",
 		AllowMethods:     "GET,POST,PUT,DELETE,OPTIONS,PATCH",
-		AllowHeaders:     "Accept,Authorization,Content-Type",
+		AllowHeaders:     "Accept,Authorization,Content-Type,Last-Event-ID,X-Admin-Key,X-Admin-Token",
+		ExposeHeaders:    "X-Round-ID,X-Round-Status,X-Round-Multiplier,X-Round-ETag",
 		AllowCredentials: false,
 		MaxAge:           300,
 	}))
 
 	s.App.Get("/health", s.healthHandler)
+	s.App.Get("/health/ready", s.readinessHandler)
+	s.App.Get("/health/live", s.livenessHandler)
 
 	s.RegisterGameRoutes()
+	s.RegisterAdminRoutes()
 
-	s.App.Get("/ws", websocket.New(s.gameWebSocketHandler))
+	s.App.Get("/ws", validChatGameType, websocket.New(s.gameWebSocketHandler))
 }
@@ -1,16 +1,24 @@
 package server
 
 import (
+	"context"
+	"errors"
 	"log"
+	"log/slog"
+	"os"
+	"sync"
 	"time"
 
+	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/compress"
 	"github.com/gofiber/fiber/v2/middleware/limiter"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 
 	"aviator/internal/cache"
 	"aviator/internal/database"
 	"aviator/internal/game"
+	"aviator/internal/notifications"
 )
 
 type FiberServer struct {
@@ -21,21 +29,91 @@ type FiberServer struct {
 	gameManager *game.Manager
 	gameHub     *game.Hub
 	gameFactory *game.GameFactory
+	broadcaster *game.RedisBroadcaster
+	chat        *game.ChatService
+	betSlips    *game.BetSlipService
+	interest    *game.BalanceInterestJob
+	anomaly     *game.AnomalyDetector
+	referrals   *game.ReferralService
+	wallet      *game.WalletService
+	summaries   *game.WeeklySummaryJob
+	exposure    *game.ExposureMonitor
+	logs        *RingLog
+	startup     *Startup
+	sseClients  sync.Map // client_id -> *sseConn
+	adminAPIKey string
 }
 
-func New() *FiberServer {
-	// Initialize database
-	db := database.New()
+// newCompressor gzip or brotli encodes responses for clients that accept it.
+// WebSocket upgrades are skipped so the handshake reaches its handler.
+func newCompressor() fiber.Handler {
+	return compress.New(compress.Config{
+		Level: compress.LevelBestSpeed,
+		Next:  websocket.IsWebSocketUpgrade,
+	})
+}
+
+// New creates the server and runs its startup checks. It returns the server
+// with a *StartupError if any check failed.
+func New() (*FiberServer, error) {
+	// Keep recent logs for the admin API. Lines from the log package go
+	// through the default slog handler too.
+	logs := NewRingLog(RING_LOG_CAPACITY)
+	slog.SetDefault(slog.New(logs.Handler(slog.NewTextHandler(os.Stderr, nil))))
+
+	server := &FiberServer{
+		App: fiber.New(fiber.Config{
+			ServerHeader:  "aviator",
+			AppName:       "aviator",
+			ReadTimeout:   10 * time.Second,
+			// Plinko auto-drop runs synchronously for up to 30 seconds
+			WriteTimeout:  game.PLINKO_AUTO_DROP_MAX_DURATION + 5*time.Second,
+			IdleTimeout:   120 * time.Second,
+			StrictRouting: false,
+		}),
+
+		logs:        logs,
+		adminAPIKey: os.Getenv("ADMIN_API_KEY"),
+	}
+
+	// Apply global middleware
+	server.App.Use(recover.New())
+	server.App.Use(limiter.New(limiter.Config{
+		Max:        100,
+		Expiration: 1 * time.Minute,
+	}))
+	server.App.Use(newCompressor())
+	server.App.Use(bodyLimit(routeBodyLimits))
+	server.App.Use(VersionNegotiator())
 
-	// Initialize Redis cache
-	redisService := cache.New()
-	if redisService == nil {
-		log.Fatal("[SERVER] Redis is required for game functionality")
+	server.startup = NewStartup(server.startupChecks()...)
+	if err := server.startup.Run(context.Background()); err != nil {
+		return server, err
 	}
 
+	log.Println("[SERVER] Game manager and all game engines started")
+
+	return server, nil
+}
+
+// startGame creates the game components on the Redis and database
+// connections and starts them. It is the last startup check.
+func (s *FiberServer) startGame(ctx context.Context) error {
+	if s.cache == nil || s.db == nil {
+		return errors.New("Redis and the database are required")
+	}
+	redisService, db := s.cache, s.db
+
 	// Initialize game components
 	hub := game.NewHub()
-	manager := game.NewManager(hub, redisService.GetClient())
+	manager := game.NewManager(hub, redisService.GetClient(), db, db)
+	manager.SetTransactionRecorder(db)
+
+	// Relay broadcasts to clients connected to other instances
+	broadcaster := game.NewRedisBroadcaster(redisService.GetClient(), hub)
+
+	// Chat between players of the same game
+	chat := game.NewChatService(redisService.GetClient(), hub, game.ParseBlockedWords(os.Getenv("CHAT_BLOCKED_WORDS")))
 
 	// Initialize game factory and register all game engines
 	factory := game.NewGameFactory(redisService.GetClient(), hub)
@@ -49,53 +127,82 @@ func New() *FiberServer {
 	factory.RegisterEngine(plinkoEngine)
 	factory.RegisterEngine(diceEngine)
 
-	server := &FiberServer{
-		App: fiber.New(fiber.Config{
-			ServerHeader:  "aviator",
-			AppName:       "aviator",
-			ReadTimeout:   10 * time.Second,
-			WriteTimeout:  10 * time.Second,
-			IdleTimeout:   120 * time.Second,
-			StrictRouting: false,
-		}),
-
-		db:          db,
-		cache:       redisService,
-		gameManager: manager,
-		gameHub:     hub,
-		gameFactory: factory,
+	// Weekly activity summaries; only logging is available so far
+	notifier, err := notifications.New(os.Getenv("NOTIFICATION_PROVIDER"))
+	if err != nil {
+		log.Printf("[SERVER] %v, falling back to log notifications", err)
+		notifier = notifications.LogNotifier{}
 	}
 
-	// Apply global middleware
-	server.App.Use(recover.New())
-	server.App.Use(limiter.New(limiter.Config{
-		Max:        100,
-		Expiration: 1 * time.Minute,
-	}))
+	// Settlement events for the operator; disabled without a URL
+	hub.SetOperatorWebhook(notifications.NewOperatorWebhook(
+		os.Getenv("OPERATOR_WEBHOOK_URL"),
+		os.Getenv("OPERATOR_WEBHOOK_SECRET"),
+		redisService.GetClient(),
+	))
+
+	// Every game's settled bets, for the house profit report
+	hub.SetSettlementRecorder(db)
+
+	s.gameManager = manager
+	s.gameHub = hub
+	s.gameFactory = factory
+	s.broadcaster = broadcaster
+	s.chat = chat
+	s.betSlips = game.NewBetSlipService(redisService.GetClient(), manager, factory)
+	s.interest = game.NewBalanceInterestJob(redisService.GetClient(), hub, db)
+	s.anomaly = game.NewAnomalyDetector(redisService.GetClient())
+	s.referrals = game.NewReferralService(redisService.GetClient(), hub, db)
+	s.wallet = game.NewWalletService(redisService.GetClient(), hub, db)
+	s.summaries = game.NewWeeklySummaryJob(redisService.GetClient(), db, notifier)
+	s.exposure = game.NewExposureMonitor(manager, factory, hub)
 
 	// Start game components
 	go hub.Run()
+	if err := broadcaster.Start(); err != nil {
+		log.Printf("[SERVER] Failed to start Redis broadcaster, broadcasting to local clients only: %v", err)
+	}
 	go manager.Start()
+	go s.interest.Start()
+	go s.summaries.Start()
+	go s.exposure.Start()
 	
 	// Start all game engines
-	if err := factory.StartAll(); err != nil {
-		log.Printf("[SERVER] Failed to start game engines: %v", err)
-	}
-
-	log.Println("[SERVER] Game manager and all game engines started")
-
-	return server
+	return factory.StartAll()
 }
 
 // Shutdown gracefully shuts down the server and game components
 func (s *FiberServer) Shutdown() error {
 	log.Println("[SERVER] Shutting down...")
 
+	// End SSE streams
+	s.closeSSEClients()
+
 	// Stop game manager
 	if s.gameManager != nil {
 		s.gameManager.Stop()
 	}
 
+	// Stop paying interest
+	if s.interest != nil {
+		s.interest.Stop()
+	}
+
+	// Stop sending weekly summaries
+	if s.summaries != nil {
+		s.summaries.Stop()
+	}
+
+	// Stop the exposure alerts
+	if s.exposure != nil {
+		s.exposure.Stop()
+	}
+
+	// Stop cross-instance broadcasting
+	if s.broadcaster != nil {
+		s.broadcaster.Stop()
+	}
+
 	// Stop all game engines
 	if s.gameFactory != nil {
 		if err := s.gameFactory.StopAll(); err != nil {
@@ -111,5 +218,9 @@ func (s *FiberServer) Shutdown() error {
 		s.db.Close()
 	}
 
+	if s.logs != nil {
+		s.logs.Stop()
+	}
+
 	return nil
 }
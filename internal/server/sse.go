@@ -0,0 +1,254 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"aviator/internal/game"
+)
+
+const (
+	SSE_HEARTBEAT_INTERVAL = 15 * time.Second // Well within SSE_BUFFER_TTL, which each heartbeat renews
+	SSE_MIN_CLIENT_ID_LEN  = 16               // Client IDs guard the buffered events, so they must be hard to guess
+)
+
+var errSSEConnClosed = errors.New("SSE connection closed")
+
+// sseConn is the hub connection of an SSE client. Messages are stored in the
+// client's EventBuffer before they are streamed, so they can be replayed
+// after a reconnect.
+type sseConn struct {
+	clientID    string
+	userID      string
+	gameType    game.GameType
+	connectedAt time.Time
+
+	buffer      *game.EventBuffer
+	events      chan game.SSEEvent
+	lastEventID atomic.Int64
+
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func newSSEConn(buffer *game.EventBuffer, clientID, userID string, gameType game.GameType) *sseConn {
+	conn := &sseConn{
+		clientID:    clientID,
+		userID:      userID,
+		gameType:    gameType,
+		connectedAt: time.Now().UTC(),
+		buffer:      buffer,
+		events:      make(chan game.SSEEvent, game.SSE_BUFFER_SIZE),
+		closed:      make(chan struct{}),
+	}
+	conn.lastEventID.Store(buffer.LastID())
+	return conn
+}
+
+// WriteMessage buffers a hub message and queues it for the stream. A client
+// too slow to keep up misses live events but can replay them by reconnecting.
+func (c *sseConn) WriteMessage(messageType int, data []byte) error {
+	select {
+	case <-c.closed:
+		return errSSEConnClosed
+	default:
+	}
+
+	event, err := c.buffer.Append(context.Background(), data)
+	if err != nil {
+		return err
+	}
+	c.lastEventID.Store(event.ID)
+
+	select {
+	case c.events <- event:
+	default:
+	}
+	return nil
+}
+
+func (c *sseConn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
+
+func (c *sseConn) Close() error {
+	c.closeOnce.Do(func() { close(c.closed) })
+	return nil
+}
+
+// writeSSEEvent writes an event with its ID, so the browser sends it back as
+// Last-Event-ID when it reconnects
+func writeSSEEvent(w *bufio.Writer, event game.SSEEvent) {
+	fmt.Fprintf(w, "id: %d\ndata: %s\n\n", event.ID, event.Data)
+}
+
+// validSSEClientID reports whether a client ID is long enough to be unguessable
+// and safe to use in a Redis key
+func validSSEClientID(clientID string) bool {
+	return len(clientID) >= SSE_MIN_CLIENT_ID_LEN && len(clientID) <= MAX_USER_ID_LENGTH && userIDPattern.MatchString(clientID)
+}
+
+// gameStreamHandler streams the WebSocket messages over Server-Sent Events,
+// for clients behind proxies that block WebSocket upgrades:
+//
+//	GET /api/v1/game/stream?client_id=<uuid>&user_id=u1&games=aviator,mines&game_type=aviator
+//
+// The first event, "connected", carries the client_id, generated when none is
+// given. A client that reconnects with the same client_id within
+// SSE_BUFFER_TTL gets the events after its Last-Event-ID header (or
+// last_event_id parameter) replayed from the last SSE_BUFFER_SIZE.
+func (s *FiberServer) gameStreamHandler(c *fiber.Ctx) error {
+	userID := c.Query("user_id", "anonymous")
+	gameType := game.GameType(c.Query("game_type", string(game.GameTypeAviator)))
+	games := parseGameTypes(c.Query("games", string(gameType)))
+
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		clientID = game.GenerateSeed()[:32]
+	} else if !validSSEClientID(clientID) {
+		return c.Status(400).JSON(fiber.Map{
+			"error": fmt.Sprintf("client_id must be %d to %d letters, digits, '-' or '_'", SSE_MIN_CLIENT_ID_LEN, MAX_USER_ID_LENGTH),
+		})
+	}
+
+	lastEventParam := c.Get("Last-Event-ID", c.Query("last_event_id"))
+	var lastEventID int64
+	if lastEventParam != "" {
+		var err error
+		if lastEventID, err = strconv.ParseInt(lastEventParam, 10, 64); err != nil || lastEventID < 0 {
+			return c.Status(400).JSON(fiber.Map{
+				"error": "Last-Event-ID must be a non-negative integer",
+			})
+		}
+	}
+
+	// A reconnecting client replaces its previous connection
+	if previous, ok := s.sseClients.Load(clientID); ok {
+		s.disconnectSSE(previous.(*sseConn))
+	}
+
+	buffer, err := game.NewEventBuffer(c.Context(), s.cache.GetClient(), clientID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to open event buffer",
+		})
+	}
+	if lastEventParam == "" {
+		lastEventID = buffer.LastID() // Only new events
+	}
+
+	conn := newSSEConn(buffer, clientID, userID, gameType)
+	s.sseClients.Store(clientID, conn)
+	log.Printf("[WS] New SSE connection %s from user: %s (%s, games: %v, last event: %d)", clientID, userID, gameType, games, lastEventID)
+	s.connectClient(conn, userID, gameType, games)
+
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer s.sseClients.CompareAndDelete(clientID, conn)
+		defer s.disconnectSSE(conn)
+
+		connected, _ := json.Marshal(fiber.Map{"client_id": clientID})
+		fmt.Fprintf(w, "event: connected\ndata: %s\n\n", connected)
+
+		// Everything missed, including the initial_state just sent
+		sent := lastEventID
+		missed, err := buffer.Since(context.Background(), lastEventID)
+		if err != nil {
+			log.Printf("[WS] Failed to replay events of SSE client %s: %v", clientID, err)
+		}
+		for _, event := range missed {
+			writeSSEEvent(w, event)
+			sent = event.ID
+		}
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		heartbeat := time.NewTicker(SSE_HEARTBEAT_INTERVAL)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case event := <-conn.events:
+				if event.ID <= sent {
+					continue // Already replayed
+				}
+				writeSSEEvent(w, event)
+				sent = event.ID
+			case <-heartbeat.C:
+				buffer.Touch(context.Background())
+				fmt.Fprint(w, ": heartbeat\n\n")
+			case <-conn.closed:
+				return
+			}
+
+			// Flush fails once the client disconnects
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	})
+
+	return nil
+}
+
+// disconnectSSE removes an SSE connection from the hub and ends its stream
+func (s *FiberServer) disconnectSSE(conn *sseConn) {
+	s.gameHub.UnregisterClient(conn)
+	conn.Close()
+}
+
+// closeSSEClients ends every SSE stream, so a graceful shutdown does not wait for them
+func (s *FiberServer) closeSSEClients() {
+	s.sseClients.Range(func(_, value interface{}) bool {
+		s.disconnectSSE(value.(*sseConn))
+		return true
+	})
+}
+
+// SSEClientInfo describes a connected SSE client
+type SSEClientInfo struct {
+	ClientID    string        `json:"client_id"`
+	UserID      string        `json:"user_id"`
+	GameType    game.GameType `json:"game_type"`
+	ConnectedAt time.Time     `json:"connected_at"`
+	LastEventID int64         `json:"last_event_id"`
+}
+
+// gameStreamClientsHandler lists the connected SSE clients, oldest first. Admin only.
+func (s *FiberServer) gameStreamClientsHandler(c *fiber.Ctx) error {
+	clients := []SSEClientInfo{}
+	s.sseClients.Range(func(_, value interface{}) bool {
+		conn := value.(*sseConn)
+		clients = append(clients, SSEClientInfo{
+			ClientID:    conn.clientID,
+			UserID:      conn.userID,
+			GameType:    conn.gameType,
+			ConnectedAt: conn.connectedAt,
+			LastEventID: conn.lastEventID.Load(),
+		})
+		return true
+	})
+	sort.Slice(clients, func(i, j int) bool {
+		return clients[i].ConnectedAt.Before(clients[j].ConnectedAt)
+	})
+
+	return c.JSON(fiber.Map{
+		"clients": clients,
+		"count":   len(clients),
+	})
+}
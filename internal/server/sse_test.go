@@ -0,0 +1,153 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sseTestClient leaves no idle connections for the server shutdown to wait on
+var sseTestClient = &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+
+type sseTestEvent struct {
+	id    int64
+	event string
+	data  map[string]interface{}
+}
+
+// openStream connects to the game stream of a listening server and returns
+// its events as they arrive
+func openStream(t *testing.T, base, query, lastEventID string) (<-chan sseTestEvent, func()) {
+	t.Helper()
+
+	req, _ := http.NewRequest("GET", base+"/api/v1/game/stream?"+query, nil)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := sseTestClient.Do(req)
+	if err != nil {
+		t.Fatalf("could not open stream: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status OK; got %v", resp.StatusCode)
+	}
+
+	events := make(chan sseTestEvent, 100)
+	go func() {
+		defer close(events)
+		scanner := bufio.NewScanner(resp.Body)
+		var event sseTestEvent
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case strings.HasPrefix(line, "id: "):
+				event.id, _ = strconv.ParseInt(strings.TrimPrefix(line, "id: "), 10, 64)
+			case strings.HasPrefix(line, "event: "):
+				event.event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event.data)
+			case line == "" && event.data != nil:
+				events <- event
+				event = sseTestEvent{}
+			}
+		}
+	}()
+	return events, func() { resp.Body.Close() }
+}
+
+func nextSSEEvent(t *testing.T, events <-chan sseTestEvent) sseTestEvent {
+	t.Helper()
+	select {
+	case event, ok := <-events:
+		if !ok {
+			t.Fatal("stream closed")
+		}
+		return event
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for an event")
+	}
+	return sseTestEvent{}
+}
+
+func TestGameStreamHandler_ReplaysMissedEvents(t *testing.T) {
+	s, _ := newTestServer(t)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("could not listen: %v", err)
+	}
+	go s.App.Listener(ln)
+	t.Cleanup(func() {
+		s.closeSSEClients()
+		s.App.Shutdown()
+	})
+	base := "http://" + ln.Addr().String()
+
+	const clientID = "0f8fad5b-d9cb-469f-a165-70867728950e"
+	events, disconnect := openStream(t, base, "client_id="+clientID+"&user_id=user1", "")
+	if connected := nextSSEEvent(t, events); connected.event != "connected" || connected.data["client_id"] != clientID {
+		t.Fatalf("first event = %+v, want connected with the client ID", connected)
+	}
+	if initial := nextSSEEvent(t, events); initial.id != 1 || initial.data["type"] != "initial_state" {
+		t.Fatalf("event = %+v, want initial_state with ID 1", initial)
+	}
+	for n := 2; n <= 5; n++ {
+		s.gameHub.Broadcast(map[string]interface{}{"type": "update", "n": n})
+		if event := nextSSEEvent(t, events); event.id != int64(n) || event.data["n"] != float64(n) {
+			t.Fatalf("event = %+v, want update %d", event, n)
+		}
+	}
+	disconnect()
+
+	events, disconnect = openStream(t, base, "client_id="+clientID+"&user_id=user1", "3")
+	defer disconnect()
+	nextSSEEvent(t, events) // connected
+	for n := 4; n <= 5; n++ {
+		if event := nextSSEEvent(t, events); event.id != int64(n) || event.data["n"] != float64(n) {
+			t.Errorf("replayed event = %+v, want update %d", event, n)
+		}
+	}
+	if initial := nextSSEEvent(t, events); initial.id != 6 || initial.data["type"] != "initial_state" {
+		t.Errorf("event = %+v, want a new initial_state with ID 6", initial)
+	}
+
+	req, _ := http.NewRequest("GET", base+"/api/v1/game/stream/clients", nil)
+	req.Header.Set("X-Admin-Token", testAdminKey)
+	resp, err := sseTestClient.Do(req)
+	if err != nil {
+		t.Fatalf("could not list clients: %v", err)
+	}
+	defer resp.Body.Close()
+	var result struct {
+		Clients []SSEClientInfo `json:"clients"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result)
+	if len(result.Clients) != 1 || result.Clients[0].ClientID != clientID || result.Clients[0].UserID != "user1" || result.Clients[0].LastEventID != 6 {
+		t.Errorf("clients = %+v, want the reconnected client at event 6", result.Clients)
+	}
+}
+
+func TestGameStreamHandler_Validation(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	for _, query := range []string{"client_id=short", "client_id=0f8fad5b-d9cb-469f-a165-70867728950e&last_event_id=x"} {
+		req, _ := http.NewRequest("GET", "/api/v1/game/stream?"+query, nil)
+		resp, err := s.App.Test(req)
+		if err != nil {
+			t.Fatalf("could not perform request: %v", err)
+		}
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400; got %v", query, resp.StatusCode)
+		}
+	}
+
+	req, _ := http.NewRequest("GET", "/api/v1/game/stream/clients", nil)
+	resp, _ := s.App.Test(req)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("clients without the admin token: expected status 401; got %v", resp.StatusCode)
+	}
+}
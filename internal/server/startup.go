@@ -0,0 +1,172 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"aviator/internal/cache"
+	"aviator/internal/database"
+)
+
+// StartupCheck is one initialization step of the server. Checks run in
+// order; a check still running after Timeout fails.
+type StartupCheck struct {
+	Name    string
+	Run     func(ctx context.Context) error
+	Timeout time.Duration
+}
+
+// StartupFailure is a check that did not pass
+type StartupFailure struct {
+	Check string
+	Err   error
+}
+
+// StartupError lists every check that failed during startup
+type StartupError struct {
+	Failures []StartupFailure
+}
+
+func (e *StartupError) Error() string {
+	failures := make([]string, len(e.Failures))
+	for i, failure := range e.Failures {
+		failures[i] = fmt.Sprintf("%s: %v", failure.Check, failure.Err)
+	}
+	return "startup failed: " + strings.Join(failures, "; ")
+}
+
+// Startup runs the startup checks and tracks which have not passed yet, for
+// the readiness probe
+type Startup struct {
+	checks []StartupCheck
+	ready  atomic.Bool
+
+	mu      sync.Mutex
+	pending []string // In check order
+}
+
+func NewStartup(checks ...StartupCheck) *Startup {
+	pending := make([]string, len(checks))
+	for i, check := range checks {
+		pending[i] = check.Name
+	}
+	return &Startup{checks: checks, pending: pending}
+}
+
+// Run runs every check, even after one fails, so the error lists all
+// failures. The server is ready once every check has passed.
+func (s *Startup) Run(ctx context.Context) error {
+	var failures []StartupFailure
+	for _, check := range s.checks {
+		start := time.Now()
+		if err := runStartupCheck(ctx, check); err != nil {
+			log.Printf("[SERVER] Startup check %s failed: %v", check.Name, err)
+			failures = append(failures, StartupFailure{Check: check.Name, Err: err})
+			continue
+		}
+		log.Printf("[SERVER] Startup check %s passed in %s", check.Name, time.Since(start).Round(time.Millisecond))
+		s.passed(check.Name)
+	}
+
+	if len(failures) > 0 {
+		return &StartupError{Failures: failures}
+	}
+	s.ready.Store(true)
+	return nil
+}
+
+func runStartupCheck(ctx context.Context, check StartupCheck) error {
+	ctx, cancel := context.WithTimeout(ctx, check.Timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- check.Run(ctx) }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("timed out after %s", check.Timeout)
+	}
+}
+
+func (s *Startup) passed(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, pending := range s.pending {
+		if pending == name {
+			s.pending = append(s.pending[:i], s.pending[i+1:]...)
+			return
+		}
+	}
+}
+
+// Ready reports whether every startup check has passed
+func (s *Startup) Ready() bool {
+	return s.ready.Load()
+}
+
+// Pending returns the checks that have not passed, in check order
+func (s *Startup) Pending() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.pending...)
+}
+
+// startupChecks are the steps New runs before the server may take traffic
+func (s *FiberServer) startupChecks() []StartupCheck {
+	return []StartupCheck{
+		{Name: "redis", Run: s.connectRedis, Timeout: 10 * time.Second},
+		{Name: "database", Run: s.connectDatabase, Timeout: 10 * time.Second},
+		{Name: "migrations", Run: s.checkMigrations, Timeout: 5 * time.Second},
+		{Name: "engines", Run: s.startGame, Timeout: 10 * time.Second},
+	}
+}
+
+// connectRedis connects to Redis, which holds all game state
+func (s *FiberServer) connectRedis(ctx context.Context) error {
+	if s.cache == nil {
+		s.cache = cache.New()
+	}
+	if s.cache == nil {
+		return errors.New("Redis is required for game functionality")
+	}
+	return s.cache.GetClient().Ping(ctx).Err()
+}
+
+// connectDatabase connects to PostgreSQL, running any pending migrations
+func (s *FiberServer) connectDatabase(ctx context.Context) error {
+	if s.db == nil {
+		s.db = database.New()
+	}
+	return s.db.Ping(ctx)
+}
+
+// checkMigrations requires the schema to be at the latest migration file's
+// version; New applies the migrations, but only warns when they fail
+func (s *FiberServer) checkMigrations(ctx context.Context) error {
+	if s.db == nil {
+		return errors.New("no database connection")
+	}
+	expected, err := database.LatestMigrationVersion(database.MigrationsPath())
+	if err != nil {
+		return err
+	}
+	version, dirty, err := s.db.MigrationVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("migration %d failed part way", version)
+	}
+	if version < expected {
+		return fmt.Errorf("schema is at version %d, want %d", version, expected)
+	}
+	return nil
+}
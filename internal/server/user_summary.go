@@ -0,0 +1,207 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/errgroup"
+
+	"aviator/internal/game"
+)
+
+const (
+	USER_SUMMARY_TIMEOUT     = 2 * time.Second
+	USER_SUMMARY_RECENT_BETS = 5
+)
+
+// UserSummary gathers what a client would otherwise load with one request
+// each: balance, active games, recent bets, today's stats and preferences
+type UserSummary struct {
+	UserID          string                  `json:"user_id"`
+	Balance         float64                 `json:"balance"`
+	ActiveGames     []UserSummaryActiveGame `json:"active_games"`
+	RecentBets      []UserSummaryBet        `json:"recent_bets"`
+	Stats           UserSummaryStats        `json:"stats"`
+	Preferences     *game.UserPreferences   `json:"preferences"`
+	PartialResponse bool                    `json:"partial_response"` // Set when a part failed and was left empty
+}
+
+type UserSummaryActiveGame struct {
+	GameType game.GameType `json:"game_type"`
+	GameID   string        `json:"game_id"`
+	Status   string        `json:"status"`
+}
+
+// UserSummaryBet is a settled Aviator bet
+type UserSummaryBet struct {
+	BetID             string    `json:"bet_id"`
+	RoundID           string    `json:"round_id"`
+	Amount            float64   `json:"amount"`
+	CashoutMultiplier float64   `json:"cashout_multiplier,omitempty"`
+	Payout            float64   `json:"payout"`
+	Result            string    `json:"result"`
+	PlacedAt          time.Time `json:"placed_at"`
+}
+
+// UserSummaryStats covers the settled Aviator bets placed since midnight UTC
+type UserSummaryStats struct {
+	TotalWageredToday float64 `json:"total_wagered_today"`
+	NetProfitToday    float64 `json:"net_profit_today"`
+}
+
+// summaryPart is one sub-query of a user summary. The summary fails if a
+// critical part fails; any other part is left empty instead.
+type summaryPart struct {
+	name     string
+	critical bool
+	load     func(ctx context.Context) error
+}
+
+// userSummaryHandler returns a user's summary, loading its parts concurrently
+func (s *FiberServer) userSummaryHandler(c *fiber.Ctx) error {
+	userID := c.Params("userId")
+	summary := &UserSummary{
+		UserID:      userID,
+		ActiveGames: []UserSummaryActiveGame{},
+		RecentBets:  []UserSummaryBet{},
+	}
+
+	partial, err := loadSummaryParts(c.Context(), s.userSummaryParts(userID, summary))
+	if err != nil {
+		log.Printf("[USER] Failed to load the summary of %s: %v", userID, err)
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to load user summary",
+		})
+	}
+	summary.PartialResponse = partial
+
+	return c.JSON(summary)
+}
+
+// userSummaryParts returns the sub-queries filling in summary. Each writes
+// only its own fields, so they can run at the same time.
+func (s *FiberServer) userSummaryParts(userID string, summary *UserSummary) []summaryPart {
+	client := s.cache.GetClient()
+	return []summaryPart{
+		{name: "balance", critical: true, load: func(ctx context.Context) error {
+			balance, err := client.Get(ctx, game.REDIS_KEY_USER_BALANCE+userID).Float64()
+			if err != nil && !errors.Is(err, redis.Nil) {
+				return err
+			}
+			summary.Balance = balance
+			return nil
+		}},
+		{name: "recent_bets", load: func(ctx context.Context) error {
+			bets, err := s.db.UserRecentBets(ctx, userID, USER_SUMMARY_RECENT_BETS)
+			if err != nil {
+				return err
+			}
+			for _, bet := range bets {
+				summary.RecentBets = append(summary.RecentBets, UserSummaryBet{
+					BetID:             bet.ID,
+					RoundID:           bet.RoundID,
+					Amount:            bet.Amount,
+					CashoutMultiplier: bet.CashoutMultiplier,
+					Payout:            bet.Payout,
+					Result:            bet.Result,
+					PlacedAt:          bet.PlacedAt,
+				})
+			}
+			return nil
+		}},
+		{name: "stats", load: func(ctx context.Context) error {
+			now := time.Now().UTC()
+			stats, err := s.db.UserBetStats(ctx, userID, now.Truncate(24*time.Hour), now)
+			if err != nil {
+				return err
+			}
+			summary.Stats = UserSummaryStats{
+				TotalWageredToday: stats.TotalWagered,
+				NetProfitToday:    stats.TotalPayout - stats.TotalWagered,
+			}
+			return nil
+		}},
+		{name: "active_games", load: func(ctx context.Context) error {
+			summary.ActiveGames = append(summary.ActiveGames, s.activeAviatorBets(ctx, userID)...)
+			engine, exists := s.gameFactory.GetEngine(game.GameTypeMines)
+			if !exists {
+				return nil
+			}
+			active, err := engine.ProcessAction(ctx, "active_game", game.MinesActiveGameRequest{UserID: userID})
+			if err != nil {
+				return err
+			}
+			if gameState, ok := active.(*game.MinesGameState); ok && gameState != nil {
+				summary.ActiveGames = append(summary.ActiveGames, UserSummaryActiveGame{
+					GameType: game.GameTypeMines,
+					GameID:   gameState.GameID,
+					Status:   gameState.Status,
+				})
+			}
+			return nil
+		}},
+		{name: "preferences", load: func(ctx context.Context) error {
+			prefs, err := game.LoadPreferences(ctx, client, userID)
+			if err != nil {
+				return err
+			}
+			if prefs == nil {
+				prefs = &game.UserPreferences{}
+			}
+			summary.Preferences = prefs
+			return nil
+		}},
+	}
+}
+
+// activeAviatorBets lists the user's bets in the current round that are
+// still riding. Failing to load them is not worth failing the part over.
+func (s *FiberServer) activeAviatorBets(ctx context.Context, userID string) []UserSummaryActiveGame {
+	roundID, bets, err := s.gameManager.GetUserBets(ctx, userID)
+	if err != nil {
+		log.Printf("[USER] Failed to load the bets of %s in round %s: %v", userID, roundID, err)
+		return nil
+	}
+
+	var active []UserSummaryActiveGame
+	for _, bet := range bets {
+		if !bet.CashedOut {
+			active = append(active, UserSummaryActiveGame{GameType: game.GameTypeAviator, GameID: bet.BetID, Status: "ACTIVE"})
+		}
+	}
+	return active
+}
+
+// loadSummaryParts runs every part at once, giving up after
+// USER_SUMMARY_TIMEOUT. It reports whether a non-critical part failed.
+func loadSummaryParts(ctx context.Context, parts []summaryPart) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, USER_SUMMARY_TIMEOUT)
+	defer cancel()
+
+	var partial atomic.Bool
+	group, ctx := errgroup.WithContext(ctx)
+	for _, part := range parts {
+		group.Go(func() error {
+			err := part.load(ctx)
+			switch {
+			case err == nil:
+				return nil
+			case part.critical:
+				return fmt.Errorf("%s: %w", part.name, err)
+			default:
+				log.Printf("[USER] Summary part %s failed: %v", part.name, err)
+				partial.Store(true)
+				return nil
+			}
+		})
+	}
+
+	err := group.Wait()
+	return partial.Load(), err
+}
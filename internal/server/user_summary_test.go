@@ -0,0 +1,102 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"aviator/internal/database"
+	"aviator/internal/game"
+)
+
+func TestUserSummaryHandler(t *testing.T) {
+	s, client := newTestServer(t)
+	ctx := t.Context()
+	client.Set(ctx, game.REDIS_KEY_USER_BALANCE+"user1", 500.0, 0)
+	game.SavePreferences(ctx, client, "user1", game.UserPreferences{Dice: &game.DicePreferences{DefaultTarget: 25}})
+
+	now := time.Now()
+	s.db.SaveBets(ctx, []database.Bet{
+		{ID: "B-old", UserID: "user1", RoundID: "R-1", Amount: 10, PlacedAt: now.Add(-48 * time.Hour), Result: database.BetResultLoss},
+		{ID: "B-win", UserID: "user1", RoundID: "R-2", Amount: 10, CashoutMultiplier: 3, Payout: 30, PlacedAt: now, Result: database.BetResultWin},
+		{ID: "B-other", UserID: "user2", RoundID: "R-2", Amount: 50, PlacedAt: now, Result: database.BetResultLoss},
+	})
+	bet := postJSON(t, s.App, "/api/v1/mines/bet", game.MinesBetRequest{UserID: "user1", Amount: 10, MineCount: 3})
+
+	getSummary := func() UserSummary {
+		t.Helper()
+		req, _ := http.NewRequest("GET", "/api/v1/users/user1/summary", nil)
+		resp, err := s.App.Test(req)
+		if err != nil {
+			t.Fatalf("could not perform request: %v", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			t.Fatalf("expected status OK; got %v: %s", resp.StatusCode, body)
+		}
+		var summary UserSummary
+		json.NewDecoder(resp.Body).Decode(&summary)
+		return summary
+	}
+
+	summary := getSummary()
+	if summary.Balance != 490 || summary.PartialResponse {
+		t.Errorf("expected a full summary with balance 490, got %+v", summary)
+	}
+	if len(summary.ActiveGames) != 1 || summary.ActiveGames[0].GameID != bet["game_id"] || summary.ActiveGames[0].GameType != game.GameTypeMines {
+		t.Errorf("expected the active Mines game, got %+v", summary.ActiveGames)
+	}
+	if len(summary.RecentBets) != 2 || summary.RecentBets[0].BetID != "B-win" {
+		t.Errorf("expected the user's bets newest first, got %+v", summary.RecentBets)
+	}
+	if summary.Stats.TotalWageredToday != 10 || summary.Stats.NetProfitToday != 20 {
+		t.Errorf("expected today's stats over B-win only, got %+v", summary.Stats)
+	}
+	if summary.Preferences == nil || summary.Preferences.Dice == nil || summary.Preferences.Dice.DefaultTarget != 25 {
+		t.Errorf("expected the saved preferences, got %+v", summary.Preferences)
+	}
+
+	client.Set(ctx, game.REDIS_KEY_PREFERENCES+"user1", "not json", 0)
+	if summary := getSummary(); !summary.PartialResponse || summary.Preferences != nil || summary.Balance != 490 {
+		t.Errorf("expected a partial summary without preferences, got %+v", summary)
+	}
+}
+
+func TestLoadSummaryParts(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	t.Run("parts run concurrently", func(t *testing.T) {
+		parts := s.userSummaryParts("user1", &UserSummary{})
+		for i, part := range parts {
+			load := part.load
+			parts[i].load = func(ctx context.Context) error {
+				time.Sleep(100 * time.Millisecond)
+				return load(ctx)
+			}
+		}
+
+		start := time.Now()
+		partial, err := loadSummaryParts(context.Background(), parts)
+		if elapsed := time.Since(start); elapsed >= 200*time.Millisecond {
+			t.Errorf("%d parts of 100ms took %v, want under 200ms", len(parts), elapsed)
+		}
+		if partial || err != nil {
+			t.Errorf("loadSummaryParts() = %v, %v; want a full summary", partial, err)
+		}
+	})
+
+	failing := func(ctx context.Context) error { return errors.New("unavailable") }
+	ok := func(ctx context.Context) error { return nil }
+
+	if partial, err := loadSummaryParts(context.Background(), []summaryPart{{name: "a", critical: true, load: ok}, {name: "b", load: failing}}); !partial || err != nil {
+		t.Errorf("non-critical failure: loadSummaryParts() = %v, %v; want a partial summary", partial, err)
+	}
+	if _, err := loadSummaryParts(context.Background(), []summaryPart{{name: "a", critical: true, load: failing}, {name: "b", load: ok}}); err == nil {
+		t.Error("critical failure: expected an error")
+	}
+}
@@ -0,0 +1,3 @@
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS valid_transaction_type;
+ALTER TABLE transactions ADD CONSTRAINT valid_transaction_type
+    CHECK (type IN ('BET', 'WIN', 'DEPOSIT', 'WITHDRAWAL', 'REFUND', 'BONUS', 'INTEREST'));
@@ -0,0 +1,8 @@
+DELETE FROM transactions WHERE type = 'referral_bonus';
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS valid_transaction_type;
+ALTER TABLE transactions ADD CONSTRAINT valid_transaction_type
+    CHECK (type IN ('BET', 'WIN', 'DEPOSIT', 'WITHDRAWAL', 'REFUND', 'BONUS', 'INTEREST'));
+
+DROP INDEX IF EXISTS idx_referrals_referrer_id;
+DROP TABLE IF EXISTS referrals;
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS referrals (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    referrer_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT no_self_referral CHECK (user_id <> referrer_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_referrals_referrer_id ON referrals(referrer_id);
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS valid_transaction_type;
+ALTER TABLE transactions ADD CONSTRAINT valid_transaction_type
+    CHECK (type IN ('BET', 'WIN', 'DEPOSIT', 'WITHDRAWAL', 'REFUND', 'BONUS', 'INTEREST', 'referral_bonus'));
+
+COMMENT ON TABLE referrals IS 'Records which user referred each registered user';
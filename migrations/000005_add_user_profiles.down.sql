@@ -0,0 +1,36 @@
+DROP INDEX IF EXISTS idx_users_status;
+
+ALTER TABLE bets DROP CONSTRAINT IF EXISTS bets_user_id_fkey;
+ALTER TABLE user_statistics DROP CONSTRAINT IF EXISTS user_statistics_user_id_fkey;
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_user_id_fkey;
+ALTER TABLE mines_games DROP CONSTRAINT IF EXISTS mines_games_user_id_fkey;
+ALTER TABLE plinko_games DROP CONSTRAINT IF EXISTS plinko_games_user_id_fkey;
+ALTER TABLE dice_games DROP CONSTRAINT IF EXISTS dice_games_user_id_fkey;
+ALTER TABLE referrals DROP CONSTRAINT IF EXISTS referrals_user_id_fkey;
+ALTER TABLE referrals DROP CONSTRAINT IF EXISTS referrals_referrer_id_fkey;
+
+ALTER TABLE bets ALTER COLUMN user_id TYPE UUID USING user_id::uuid;
+ALTER TABLE user_statistics ALTER COLUMN user_id TYPE UUID USING user_id::uuid;
+ALTER TABLE transactions ALTER COLUMN user_id TYPE UUID USING user_id::uuid;
+ALTER TABLE mines_games ALTER COLUMN user_id TYPE UUID USING user_id::uuid;
+ALTER TABLE plinko_games ALTER COLUMN user_id TYPE UUID USING user_id::uuid;
+ALTER TABLE dice_games ALTER COLUMN user_id TYPE UUID USING user_id::uuid;
+ALTER TABLE referrals ALTER COLUMN user_id TYPE UUID USING user_id::uuid;
+ALTER TABLE referrals ALTER COLUMN referrer_id TYPE UUID USING referrer_id::uuid;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS valid_user_status;
+ALTER TABLE users DROP COLUMN IF EXISTS status;
+ALTER TABLE users DROP COLUMN IF EXISTS last_seen_at;
+ALTER TABLE users ALTER COLUMN email TYPE VARCHAR(255);
+ALTER TABLE users ALTER COLUMN username TYPE VARCHAR(50);
+ALTER TABLE users ALTER COLUMN id TYPE UUID USING id::uuid;
+ALTER TABLE users ALTER COLUMN id SET DEFAULT uuid_generate_v4();
+
+ALTER TABLE bets ADD CONSTRAINT bets_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE user_statistics ADD CONSTRAINT user_statistics_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE transactions ADD CONSTRAINT transactions_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE mines_games ADD CONSTRAINT mines_games_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE plinko_games ADD CONSTRAINT plinko_games_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE dice_games ADD CONSTRAINT dice_games_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE referrals ADD CONSTRAINT referrals_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE referrals ADD CONSTRAINT referrals_referrer_id_fkey FOREIGN KEY (referrer_id) REFERENCES users(id) ON DELETE CASCADE;
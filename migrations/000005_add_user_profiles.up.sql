@@ -0,0 +1,41 @@
+-- User IDs are the same strings the game uses as Redis keys, so store them as TEXT.
+-- Foreign keys must be dropped while the referenced column changes type.
+ALTER TABLE bets DROP CONSTRAINT IF EXISTS bets_user_id_fkey;
+ALTER TABLE user_statistics DROP CONSTRAINT IF EXISTS user_statistics_user_id_fkey;
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_user_id_fkey;
+ALTER TABLE mines_games DROP CONSTRAINT IF EXISTS mines_games_user_id_fkey;
+ALTER TABLE plinko_games DROP CONSTRAINT IF EXISTS plinko_games_user_id_fkey;
+ALTER TABLE dice_games DROP CONSTRAINT IF EXISTS dice_games_user_id_fkey;
+ALTER TABLE referrals DROP CONSTRAINT IF EXISTS referrals_user_id_fkey;
+ALTER TABLE referrals DROP CONSTRAINT IF EXISTS referrals_referrer_id_fkey;
+
+ALTER TABLE users ALTER COLUMN id DROP DEFAULT;
+ALTER TABLE users ALTER COLUMN id TYPE TEXT;
+ALTER TABLE users ALTER COLUMN username TYPE TEXT;
+ALTER TABLE users ALTER COLUMN email TYPE TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'active';
+ALTER TABLE users ADD CONSTRAINT valid_user_status CHECK (status IN ('active', 'suspended', 'banned'));
+
+ALTER TABLE bets ALTER COLUMN user_id TYPE TEXT;
+ALTER TABLE user_statistics ALTER COLUMN user_id TYPE TEXT;
+ALTER TABLE transactions ALTER COLUMN user_id TYPE TEXT;
+ALTER TABLE mines_games ALTER COLUMN user_id TYPE TEXT;
+ALTER TABLE plinko_games ALTER COLUMN user_id TYPE TEXT;
+ALTER TABLE dice_games ALTER COLUMN user_id TYPE TEXT;
+ALTER TABLE referrals ALTER COLUMN user_id TYPE TEXT;
+ALTER TABLE referrals ALTER COLUMN referrer_id TYPE TEXT;
+
+ALTER TABLE bets ADD CONSTRAINT bets_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE user_statistics ADD CONSTRAINT user_statistics_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE transactions ADD CONSTRAINT transactions_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE mines_games ADD CONSTRAINT mines_games_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE plinko_games ADD CONSTRAINT plinko_games_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE dice_games ADD CONSTRAINT dice_games_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE referrals ADD CONSTRAINT referrals_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE referrals ADD CONSTRAINT referrals_referrer_id_fkey FOREIGN KEY (referrer_id) REFERENCES users(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_users_status ON users(status);
+
+COMMENT ON COLUMN users.id IS 'Player ID, shared with the Redis balance keys';
+COMMENT ON COLUMN users.status IS 'Account status: active, suspended, banned';
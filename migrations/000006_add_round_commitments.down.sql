@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_game_rounds_commitment_published_at;
+
+ALTER TABLE game_rounds DROP COLUMN IF EXISTS verified;
+ALTER TABLE game_rounds DROP COLUMN IF EXISTS commitment_published_at;
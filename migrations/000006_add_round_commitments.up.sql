@@ -0,0 +1,6 @@
+ALTER TABLE game_rounds ADD COLUMN IF NOT EXISTS commitment_published_at TIMESTAMP;
+ALTER TABLE game_rounds ADD COLUMN IF NOT EXISTS verified BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_game_rounds_commitment_published_at ON game_rounds(commitment_published_at DESC);
+
+COMMENT ON COLUMN game_rounds.verified IS 'Revealed server seed matches the commitment and reproduces the crash multiplier';
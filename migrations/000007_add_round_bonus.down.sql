@@ -0,0 +1 @@
+ALTER TABLE game_rounds DROP COLUMN IF EXISTS bonus_multiplier;
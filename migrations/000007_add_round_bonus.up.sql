@@ -0,0 +1,4 @@
+ALTER TABLE game_rounds ADD COLUMN IF NOT EXISTS bonus_multiplier DECIMAL(10,2) NOT NULL DEFAULT 0.00;
+
+COMMENT ON COLUMN game_rounds.crash_multiplier IS 'Provably fair crash point, before any bonus event';
+COMMENT ON COLUMN game_rounds.bonus_multiplier IS 'Added to crash_multiplier by a bonus event; the round crashed at their sum';
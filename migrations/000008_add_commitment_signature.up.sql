@@ -0,0 +1,5 @@
+-- Migration: add_commitment_signature
+
+ALTER TABLE game_rounds ADD COLUMN IF NOT EXISTS commitment_signature VARCHAR(64);
+
+COMMENT ON COLUMN game_rounds.commitment_signature IS 'HMAC-SHA256(OPERATOR_SECRET, hash_commitment + id + commitment_published_at), stored before betting opened';
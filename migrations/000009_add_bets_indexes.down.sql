@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_bets_user_game;
+
+DROP INDEX IF EXISTS idx_bets_game_type;
+CREATE INDEX IF NOT EXISTS idx_bets_game_type ON bets(game_type);
@@ -0,0 +1,9 @@
+-- Migration: add_bets_indexes
+
+CREATE INDEX IF NOT EXISTS idx_bets_user_id ON bets(user_id);
+CREATE INDEX IF NOT EXISTS idx_bets_placed_at ON bets(placed_at DESC);
+
+-- Replaces the single column index from add_game_types
+DROP INDEX IF EXISTS idx_bets_game_type;
+CREATE INDEX IF NOT EXISTS idx_bets_game_type ON bets(game_type, placed_at DESC);
+CREATE INDEX IF NOT EXISTS idx_bets_user_game ON bets(user_id, game_type, placed_at DESC);
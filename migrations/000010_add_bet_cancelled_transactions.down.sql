@@ -0,0 +1,5 @@
+DELETE FROM transactions WHERE type = 'bet_cancelled';
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS valid_transaction_type;
+ALTER TABLE transactions ADD CONSTRAINT valid_transaction_type
+    CHECK (type IN ('BET', 'WIN', 'DEPOSIT', 'WITHDRAWAL', 'REFUND', 'BONUS', 'INTEREST', 'referral_bonus'));
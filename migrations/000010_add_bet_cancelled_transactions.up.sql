@@ -0,0 +1,5 @@
+-- Migration: add_bet_cancelled_transactions
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS valid_transaction_type;
+ALTER TABLE transactions ADD CONSTRAINT valid_transaction_type
+    CHECK (type IN ('BET', 'WIN', 'DEPOSIT', 'WITHDRAWAL', 'REFUND', 'BONUS', 'INTEREST', 'referral_bonus', 'bet_cancelled'));
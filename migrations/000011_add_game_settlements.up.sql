@@ -0,0 +1,16 @@
+-- Migration: add_game_settlements
+
+-- Every game's settled bets; the bets table only holds Aviator rounds.
+-- Players need not be registered to play, so user_id has no foreign key.
+CREATE TABLE IF NOT EXISTS game_settlements (
+    id BIGSERIAL PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    game_type VARCHAR(20) NOT NULL,
+    wager DECIMAL(20,2) NOT NULL,
+    payout DECIMAL(20,2) NOT NULL DEFAULT 0,
+    settled_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_game_settlements_settled_at ON game_settlements(settled_at DESC, game_type);
+
+COMMENT ON TABLE game_settlements IS 'Wager and payout of every settled bet, for house profit reports';
@@ -0,0 +1,5 @@
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS valid_transaction_type;
+UPDATE transactions SET type = 'referral_bonus' WHERE type = 'REFERRAL_BONUS';
+UPDATE transactions SET type = 'bet_cancelled' WHERE type = 'BET_CANCELLED';
+ALTER TABLE transactions ADD CONSTRAINT valid_transaction_type
+    CHECK (type IN ('BET', 'WIN', 'DEPOSIT', 'WITHDRAWAL', 'REFUND', 'BONUS', 'INTEREST', 'referral_bonus', 'bet_cancelled'));
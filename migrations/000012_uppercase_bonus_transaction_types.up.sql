@@ -0,0 +1,7 @@
+-- Migration: uppercase_bonus_transaction_types
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS valid_transaction_type;
+UPDATE transactions SET type = 'REFERRAL_BONUS' WHERE type = 'referral_bonus';
+UPDATE transactions SET type = 'BET_CANCELLED' WHERE type = 'bet_cancelled';
+ALTER TABLE transactions ADD CONSTRAINT valid_transaction_type
+    CHECK (type IN ('BET', 'WIN', 'DEPOSIT', 'WITHDRAWAL', 'REFUND', 'BONUS', 'INTEREST', 'REFERRAL_BONUS', 'BET_CANCELLED'));